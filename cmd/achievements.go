@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/goals"
+	"github.com/ethan-k/pomodoro-cli/internal/notify"
+)
+
+// announceGoalAchievements records the day's summary once the pomodoro that
+// just finished, session id, is counted, and notifies the user when it is the
+// one that reached the daily or weekly goal, or a goal for one of its tags,
+// or made a new best day or longest streak
+func announceGoalAchievements(id int64, silent bool) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return
+	}
+
+	database, err := openDB()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err := database.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+		}
+	}()
+
+	session, err := database.GetSessionByID(id)
+	if err != nil || session == nil {
+		return
+	}
+	now := app.now()
+	day := session.StartTime.In(now.Location())
+	if _, err := database.RecordDailySummary(day, cfg.Goals.DailyCount); err != nil {
+		fmt.Fprintf(os.Stderr, "Error recording the daily summary: %v\n", err)
+	}
+	if !cfg.Achievements.Enabled {
+		return
+	}
+
+	status, _, err := goalStatus(database, now)
+	if err != nil {
+		return
+	}
+	var tags []string
+	if session.TagsCSV != "" {
+		tags = strings.Split(session.TagsCSV, ",")
+	}
+
+	manager := goals.NewNotificationManager(notify.NewNotifier(cfg.Notifications))
+	if err := manager.NotifyGoalProgress(status, tags, silent); err != nil {
+		fmt.Fprintf(os.Stderr, "Error sending notification: %v\n", err)
+		return
+	}
+	if session.WasBreak {
+		return
+	}
+	summaries, err := database.GetDailySummaries()
+	if err != nil {
+		return
+	}
+	off, err := goals.LoadOffDays(database)
+	if err != nil {
+		return
+	}
+	for _, a := range goals.NewRecordAchievements(summaries, day.Format("2006-01-02"), now, off) {
+		if err := manager.NotifyAchievement(a, silent); err != nil {
+			fmt.Fprintf(os.Stderr, "Error sending notification: %v\n", err)
+			return
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+// aliasCmd groups the alias commands
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manages shortcuts for longer command lines",
+	Long: `Aliases are shortcuts kept under aliases in the config file, each naming
+a command line it stands for:
+
+  aliases:
+    w: "start --duration 50m -t deep-work"
+    hb: "break 10m --wait"
+
+'pomodoro w "Write report"' then runs 'pomodoro start --duration 50m -t
+deep-work "Write report"': arguments after an alias are added to its
+command line. Aliases cannot take the name of a command, and are not
+expanded inside other aliases.`,
+}
+
+// aliasListCmd lists the aliases
+var aliasListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the aliases",
+	Args:  cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			aliases := cfg.Aliases
+			if aliases == nil {
+				aliases = map[string]string{}
+			}
+			printJSON(struct {
+				Aliases map[string]string `json:"aliases"`
+			}{aliases})
+			return
+		}
+
+		if len(cfg.Aliases) == 0 {
+			fmt.Println("No aliases. Add one with 'pomodoro alias add <name> <command line>'.")
+			return
+		}
+		names := make([]string, 0, len(cfg.Aliases))
+		for name := range cfg.Aliases {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			fmt.Printf("%-12s %s\n", name, cfg.Aliases[name])
+		}
+	},
+}
+
+// aliasAddCmd adds or replaces an alias
+var aliasAddCmd = &cobra.Command{
+	Use:   "add <name> <command line>",
+	Short: "Adds an alias, or replaces the one of that name",
+	Long: `Adds an alias to the config file. Quote the command line so its flags are
+not taken as flags of alias add.
+
+Examples:
+  pomodoro alias add w "start --duration 50m -t deep-work"
+  pomodoro alias add hb "break 10m --wait"`,
+	Args: cobra.ExactArgs(2),
+	Run: func(_ *cobra.Command, args []string) {
+		name, line := args[0], strings.TrimSpace(args[1])
+		if err := validateAlias(name, line); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		if cfg.Aliases == nil {
+			cfg.Aliases = map[string]string{}
+		}
+		_, replaced := cfg.Aliases[name]
+		cfg.Aliases[name] = line
+		if err := config.SaveConfig(cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
+			os.Exit(1)
+		}
+
+		if replaced {
+			fmt.Printf("Updated alias %s: %s\n", name, line)
+		} else {
+			fmt.Printf("Added alias %s: %s\n", name, line)
+		}
+	},
+}
+
+// aliasRemoveCmd removes an alias
+var aliasRemoveCmd = &cobra.Command{
+	Use:               "remove <name>",
+	Short:             "Removes an alias",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeAlias,
+	Run: func(_ *cobra.Command, args []string) {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		if _, ok := cfg.Aliases[args[0]]; !ok {
+			fmt.Fprintf(os.Stderr, "No alias named %s (see 'pomodoro alias list')\n", args[0])
+			os.Exit(1)
+		}
+		delete(cfg.Aliases, args[0])
+		if err := config.SaveConfig(cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Removed alias %s\n", args[0])
+	},
+}
+
+// validateAlias checks that name can be used for an alias and that line is
+// a command line it can stand for
+func validateAlias(name, line string) error {
+	if name == "" || strings.HasPrefix(name, "-") || strings.ContainsFunc(name, unicode.IsSpace) {
+		return fmt.Errorf("invalid alias name %q: use a word without spaces that does not start with -", name)
+	}
+	if isCommandName(name) {
+		return fmt.Errorf("%s is a pomodoro command, so it cannot be an alias", name)
+	}
+	words, err := utils.SplitArgs(line)
+	if err != nil {
+		return fmt.Errorf("invalid command line: %v", err)
+	}
+	i := commandArg(words)
+	if i < 0 {
+		return fmt.Errorf("invalid command line %q: it names no command", line)
+	}
+	if _, ok := durationArg(words[i]); !ok && !isCommandName(words[i]) {
+		return fmt.Errorf("invalid command line %q: %s is not a pomodoro command", line, words[i])
+	}
+	return nil
+}
+
+// isCommandName reports whether name runs a command, so an alias of that
+// name would never be used
+func isCommandName(name string) bool {
+	if name == "help" || name == "completion" || strings.HasPrefix(name, "__") {
+		return true
+	}
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandAlias replaces an alias given as the command in args with the
+// command line configured for it. A config that cannot be loaded has no
+// aliases, leaving safe mode to deal with it.
+func expandAlias(args []string) ([]string, error) {
+	// Commands are never aliases, so they run without loading the config here
+	i := commandArg(args)
+	if i < 0 || isCommandName(args[i]) {
+		return args, nil
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return args, nil
+	}
+	return aliasArgs(args, cfg.Aliases)
+}
+
+// aliasArgs replaces the command in args with the command line aliases has
+// for it, keeping the arguments around it
+func aliasArgs(args []string, aliases map[string]string) ([]string, error) {
+	i := commandArg(args)
+	if i < 0 || isCommandName(args[i]) {
+		return args, nil
+	}
+	line, ok := aliases[args[i]]
+	if !ok {
+		return args, nil
+	}
+	words, err := utils.SplitArgs(line)
+	if err != nil {
+		return nil, fmt.Errorf("alias %s: %v", args[i], err)
+	}
+	return slices.Concat(args[:i], words, args[i+1:]), nil
+}
+
+// completeAlias offers the configured aliases
+func completeAlias(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names := make([]string, 0, len(cfg.Aliases))
+	for name, line := range cfg.Aliases {
+		names = append(names, name+"\t"+line)
+	}
+	slices.Sort(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	rootCmd.AddCommand(aliasCmd)
+	aliasCmd.AddCommand(aliasListCmd)
+	aliasCmd.AddCommand(aliasAddCmd)
+	aliasCmd.AddCommand(aliasRemoveCmd)
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAliasArgs(t *testing.T) {
+	aliases := map[string]string{
+		"w":     `start --duration 50m -t deep-work`,
+		"hb":    "break 10m --wait",
+		"start": "break", // Never used, as start is a command
+		"bad":   `start "Oops`,
+	}
+	for _, tc := range []struct {
+		args, want []string
+	}{
+		{[]string{"w", "Write report"}, []string{"start", "--duration", "50m", "-t", "deep-work", "Write report"}},
+		{[]string{"-q", "hb", "--silent"}, []string{"-q", "break", "10m", "--wait", "--silent"}},
+		{[]string{"start", "x"}, []string{"start", "x"}},
+		{[]string{"history", "w"}, []string{"history", "w"}},
+		{[]string{"--db", "w", "status"}, []string{"--db", "w", "status"}},
+		{nil, nil},
+	} {
+		got, err := aliasArgs(tc.args, aliases)
+		if err != nil || !slices.Equal(got, tc.want) {
+			t.Errorf("aliasArgs(%q) = %q, %v, want %q", tc.args, got, err, tc.want)
+		}
+	}
+	if _, err := aliasArgs([]string{"bad"}, aliases); err == nil || !strings.Contains(err.Error(), "alias bad") {
+		t.Errorf("Expected an unclosed quote in an alias to be reported, got %v", err)
+	}
+}
+
+func TestAliasCommands(t *testing.T) {
+	a := newTestApp(t, &mockDB{}, time.Now())
+
+	a.run(t, "alias", "add", "w", "start --duration 50m -t deep-work")
+	a.run(t, "alias", "add", "hb", "break 10m --wait")
+	if out := a.run(t, "alias", "list"); out != "hb           break 10m --wait\nw            start --duration 50m -t deep-work\n" {
+		t.Errorf("alias list printed %q", out)
+	}
+
+	a.run(t, "alias", "remove", "hb")
+	var listed struct {
+		Aliases map[string]string `json:"aliases"`
+	}
+	if err := json.Unmarshal([]byte(a.run(t, "alias", "list", "--json")), &listed); err != nil {
+		t.Fatal(err)
+	}
+	if len(listed.Aliases) != 1 || listed.Aliases["w"] != "start --duration 50m -t deep-work" {
+		t.Errorf("Expected only w left, got %v", listed.Aliases)
+	}
+	if args, err := expandAlias([]string{"w", "Write"}); err != nil || args[0] != "start" {
+		t.Errorf("Expected the saved alias to expand, got %q, %v", args, err)
+	}
+
+	for _, tc := range []struct{ name, line, wantErr string }{
+		{"status", "start", "is a pomodoro command"},
+		{"s", "start", "is a pomodoro command"}, // start's own alias
+		{"-x", "start", "invalid alias name"},
+		{"x", "frobnicate now", "not a pomodoro command"},
+		{"x", "--quiet", "names no command"},
+		{"x", `start "Oops`, "unclosed"},
+		{"x", "-q 25m", ""},
+	} {
+		err := validateAlias(tc.name, tc.line)
+		if (err == nil) != (tc.wantErr == "") || err != nil && !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("validateAlias(%q, %q) = %v, want an error containing %q", tc.name, tc.line, err, tc.wantErr)
+		}
+	}
+}
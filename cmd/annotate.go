@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+var annotateSource string
+
+// annotateCmd represents the annotate command
+var annotateCmd = &cobra.Command{
+	Use:   "annotate <session> [text]",
+	Short: "Appends a note to a past session",
+	Long: `Appends an annotation to a session, or lists its annotations when no
+text is given.
+
+Annotations are append-only, so hooks and integrations can record what
+happened after a session (a CI result, a merged PR) without rewriting it.
+Use --source to identify who added the note.
+
+Example:
+  pomodoro annotate 42 "PR #118 merged"
+  pomodoro annotate '#a3f9c2' "CI passed" --source ci
+  pomodoro annotate 2024-06-01.3`,
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeSession,
+	Run: func(_ *cobra.Command, args []string) {
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		session, err := database.ResolveSession(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		if session == nil {
+			fmt.Fprintf(os.Stderr, "No session found for %s\n", args[0])
+			os.Exit(1)
+		}
+
+		if len(args) == 2 {
+			text := strings.TrimSpace(args[1])
+			if err := utils.ValidateAnnotation(text); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid annotation: %v\n", err)
+				os.Exit(1)
+			}
+			source := strings.TrimSpace(annotateSource)
+			if source == "" {
+				source = "cli"
+			}
+
+			id, err := database.AddAnnotation(session.ID, source, text)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+
+			if jsonOutput {
+				printJSONLine(struct {
+					ID        int64  `json:"id"`
+					SessionID int64  `json:"session_id"`
+					Source    string `json:"source"`
+					Text      string `json:"text"`
+				}{id, session.ID, source, text})
+				return
+			}
+			fmt.Printf("Annotated session %s: %s\n", session.ShortRef(), text)
+			return
+		}
+
+		annotations, err := database.GetAnnotations(session.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			if annotations == nil {
+				annotations = []db.Annotation{}
+			}
+			printJSON(annotations)
+			return
+		}
+
+		if len(annotations) == 0 {
+			fmt.Printf("No annotations for session %s.\n", session.ShortRef())
+			return
+		}
+		printAnnotations(annotations)
+	},
+}
+
+// printAnnotations prints annotations one per line with their time and source
+func printAnnotations(annotations []db.Annotation) {
+	for _, a := range annotations {
+		fmt.Printf("  %s [%s] %s\n", a.CreatedAt.Local().Format("2006-01-02 15:04"), a.Source, a.Text)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(annotateCmd)
+
+	annotateCmd.Flags().StringVar(&annotateSource, "source", "cli", "Who is adding the annotation (e.g. ci, github, hook name)")
+}
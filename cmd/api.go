@@ -0,0 +1,571 @@
+package cmd
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/hooks"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+// apiHistoryLimit is how many sessions GET /history returns by default
+const apiHistoryLimit = 20
+
+// apiMaxBody caps the size of a request body
+const apiMaxBody = 64 << 10
+
+// Clients recorded in the audit log that are not API tokens
+const (
+	apiClientShared = "(--token)" // Used the token given to serve --token
+	apiClientOpen   = "(open)"    // No token needed, since none is set up
+)
+
+// apiServer serves the REST API that controls the timer
+type apiServer struct {
+	database db.DB
+	token    string          // Bearer token every request must carry, empty for none
+	origins  map[string]bool // Web origins allowed to call the API
+	limiter  *apiLimiter     // nil when requests are not rate limited
+
+	// Held while the timer is changed, so two tools starting a session at
+	// once cannot both see none running. It is let go before hooks run and
+	// the daemon starts, so a slow hook holds up no other request.
+	mu sync.Mutex
+}
+
+// apiStatus is the JSON representation of the timer
+type apiStatus struct {
+	Active       bool         `json:"active"`
+	Paused       bool         `json:"paused"`
+	RemainingSec int64        `json:"remaining_seconds"`
+	Session      *jsonSession `json:"session,omitempty"`
+}
+
+// apiStartRequest is the body of POST /start; every field is optional
+type apiStartRequest struct {
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	Duration    string   `json:"duration"` // e.g. "50m"; from tag rules or defaults.pomodoro_duration when empty
+	Task        int64    `json:"task"`
+	Category    string   `json:"category"`
+	Override    bool     `json:"override"` // Start even when past the daily limits with limits.refuse set
+}
+
+// apiAnnotateRequest is the body of POST /sessions/{id}/annotations
+type apiAnnotateRequest struct {
+	Text   string `json:"text"`
+	Source string `json:"source"` // Who added the note, "api" when empty
+}
+
+// newAPIHandler returns the REST API for database. With a token, or once
+// API tokens have been created, requests without a valid one are refused.
+// Web pages may only call it from origins, and each client may make
+// rateLimit requests a minute, any number for 0.
+func newAPIHandler(database db.DB, token string, origins []string, rateLimit int) http.Handler {
+	s := &apiServer{database: database, token: token, origins: map[string]bool{}, limiter: newAPILimiter(rateLimit)}
+	for _, origin := range origins {
+		s.origins[strings.TrimSuffix(origin, "/")] = true
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", s.status)
+	mux.HandleFunc("POST /start", s.start)
+	mux.HandleFunc("POST /pause", s.pause)
+	mux.HandleFunc("POST /resume", s.resume)
+	mux.HandleFunc("POST /cancel", s.cancel)
+	mux.HandleFunc("GET /history", s.history)
+	mux.HandleFunc("GET /goals", s.goals)
+	mux.HandleFunc("POST /sessions/{id}/annotations", s.annotate)
+	return s.guard(mux)
+}
+
+// guard refuses requests made from web pages not on an allowed origin and,
+// when tokens are required, those that do not carry a valid one. Browsers
+// send an Origin header with cross-site requests, which tools such as curl,
+// Raycast, or Stream Deck do not, so a page cannot drive the timer from a
+// browser on the same machine. Read-only tokens may only make GET requests,
+// and clients over the rate limit are told when to retry. Every request
+// that could change something is recorded in the audit log, refused or not.
+func (s *apiServer) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if !s.origins[origin] {
+				apiError(w, http.StatusForbidden, "requests from web pages are not allowed")
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Add("Vary", "Origin")
+			// Preflight requests carry no credentials, so they are answered
+			// before any token is checked
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+				w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+				w.Header().Set("Access-Control-Max-Age", "600")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		readOnly := r.Method == http.MethodGet || r.Method == http.MethodHead
+		var audit *auditRecorder
+		if !readOnly {
+			audit = &auditRecorder{ResponseWriter: w, status: http.StatusOK}
+			w = audit
+			defer s.audit(r, audit)
+		}
+
+		client, scope, err := s.authenticate(r)
+		if audit != nil {
+			audit.client = client
+		}
+		if err != nil {
+			apiError(w, http.StatusInternalServerError, "%v", err)
+			return
+		}
+		switch {
+		case scope == "":
+			apiError(w, http.StatusUnauthorized, "missing or wrong bearer token")
+			return
+		case scope == db.ScopeRead && !readOnly:
+			apiError(w, http.StatusForbidden, "this token is read-only")
+			return
+		}
+
+		// Clients without a token are told apart by address, so behind a
+		// reverse proxy they all share the proxy's limit
+		key := client
+		if client == apiClientOpen {
+			key += "@" + remoteHost(r)
+		}
+		if ok, wait := s.limiter.allow(key, time.Now()); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			apiError(w, http.StatusTooManyRequests, "rate limit exceeded; retry in %s", wait.Round(time.Second))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticate returns who the request's bearer token identifies and the
+// scope it grants, or "" when it grants none. The --token secret grants
+// full access, as does any request from this machine while neither it nor
+// any API token is set up. A request forwarded by a reverse proxy on this
+// machine arrives from the loopback address too, so one that names who it
+// was forwarded for never gets open access.
+func (s *apiServer) authenticate(r *http.Request) (client, scope string, err error) {
+	given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if ok && s.token != "" && subtle.ConstantTimeCompare([]byte(given), []byte(s.token)) == 1 {
+		return apiClientShared, db.ScopeFull, nil
+	}
+	if ok {
+		token, err := s.database.AuthenticateAPIToken(given, app.now())
+		if err != nil {
+			return "", "", err
+		}
+		if token != nil {
+			return token.Name, token.Scope, nil
+		}
+	}
+	if s.token != "" || !loopbackAddr(r.RemoteAddr) || proxiedRequest(r) {
+		return "", "", nil
+	}
+	tokens, err := s.database.ListAPITokens(false)
+	if err != nil || len(tokens) > 0 {
+		return "", "", err
+	}
+	return apiClientOpen, db.ScopeFull, nil
+}
+
+// auditRecorder notes what an audited request did as it is answered
+type auditRecorder struct {
+	http.ResponseWriter
+	client    string
+	status    int
+	sessionID int64
+}
+
+func (a *auditRecorder) WriteHeader(code int) {
+	a.status = code
+	a.ResponseWriter.WriteHeader(code)
+}
+
+// auditSession notes that the request answered by w acted on session id
+func auditSession(w http.ResponseWriter, id int64) {
+	if a, ok := w.(*auditRecorder); ok {
+		a.sessionID = id
+	}
+}
+
+// audit records an answered request in the audit log. A failure to record
+// it is only reported, since the request has already been answered.
+func (s *apiServer) audit(r *http.Request, a *auditRecorder) {
+	entry := db.APIAuditEntry{
+		At:        app.now(),
+		Client:    a.client,
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		Status:    a.status,
+		SessionID: a.sessionID,
+		Remote:    remoteHost(r),
+	}
+	if err := s.database.RecordAPIAudit(entry); err != nil {
+		warnf("%v\n", err)
+	}
+}
+
+// remoteHost returns the address a request came from, without its port
+func remoteHost(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// proxiedRequest reports whether r was forwarded by a reverse proxy, which
+// adds headers naming the client it forwards for
+func proxiedRequest(r *http.Request) bool {
+	for _, header := range []string{"Forwarded", "X-Forwarded-For", "X-Real-Ip"} {
+		if r.Header.Get(header) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// loopbackAddr reports whether addr, a host with or without a port, is on
+// this machine only. An empty host, as in ":7070", listens everywhere.
+func loopbackAddr(addr string) bool {
+	host := addr
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		host = h
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// writeJSON writes v as the JSON response
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(append(data, '\n'))
+}
+
+// apiError writes an error as {"error": "..."}
+func apiError(w http.ResponseWriter, code int, format string, args ...any) {
+	writeJSON(w, code, map[string]string{"error": fmt.Sprintf(format, args...)})
+}
+
+// decodeBody reads a JSON request body into v. An empty body leaves v as it is.
+func decodeBody(r *http.Request, v any) error {
+	err := json.NewDecoder(io.LimitReader(r.Body, apiMaxBody)).Decode(v)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+// newAPIStatus describes the timer with session active, which may be nil
+func newAPIStatus(session *db.PomodoroSession, now time.Time) apiStatus {
+	if session == nil {
+		return apiStatus{}
+	}
+	remaining := session.EndTime.Sub(now)
+	if session.IsPaused {
+		remaining = session.RemainingAtPause()
+	}
+	js := newJSONSession(*session, nil)
+	return apiStatus{
+		Active:       true,
+		Paused:       session.IsPaused,
+		RemainingSec: int64(max(0, remaining).Seconds()),
+		Session:      &js,
+	}
+}
+
+// writeStatus responds with the timer as it is now
+func (s *apiServer) writeStatus(w http.ResponseWriter, code int) {
+	session, err := activeSession(s.database)
+	if err != nil {
+		apiError(w, http.StatusInternalServerError, "error getting active session: %v", err)
+		return
+	}
+	writeJSON(w, code, newAPIStatus(session, app.now()))
+}
+
+func (s *apiServer) status(w http.ResponseWriter, _ *http.Request) {
+	s.writeStatus(w, http.StatusOK)
+}
+
+func (s *apiServer) start(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	unlock := sync.OnceFunc(s.mu.Unlock)
+	defer unlock()
+
+	var req apiStartRequest
+	if err := decodeBody(r, &req); err != nil {
+		apiError(w, http.StatusBadRequest, "invalid request: %v", err)
+		return
+	}
+
+	description := utils.SanitizeDescription(req.Description)
+	if err := utils.ValidateDescription(description, false); err != nil {
+		apiError(w, http.StatusBadRequest, "invalid description: %v", err)
+		return
+	}
+	tags := utils.SanitizeTags(req.Tags)
+	if err := utils.ValidateTags(tags); err != nil {
+		apiError(w, http.StatusBadRequest, "invalid tags: %v", err)
+		return
+	}
+	var given *time.Duration
+	if req.Duration != "" {
+		d, err := utils.ParseHumanDuration(req.Duration)
+		if err != nil {
+			apiError(w, http.StatusBadRequest, "invalid duration: %v", err)
+			return
+		}
+		given = &d
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		cfg = nil
+	}
+	d, _ := resolveDuration(given, tags, cfg)
+	if err := utils.ValidateDuration(d); err != nil {
+		apiError(w, http.StatusBadRequest, "invalid duration: %v", err)
+		return
+	}
+	category := ""
+	if req.Category != "" {
+		var err error
+		if category, err = parseCategory(req.Category); err != nil {
+			apiError(w, http.StatusBadRequest, "invalid category: %v", err)
+			return
+		}
+	}
+	if req.Task != 0 {
+		if err := checkOpenTask(s.database, req.Task); err != nil {
+			apiError(w, http.StatusBadRequest, "%v", err)
+			return
+		}
+	}
+
+	if active, err := activeSession(s.database); err != nil {
+		apiError(w, http.StatusInternalServerError, "error getting active session: %v", err)
+		return
+	} else if active != nil {
+		apiError(w, http.StatusConflict, "session %s is already active", active.ShortRef())
+		return
+	}
+
+	now := app.now()
+	if reason := limitExceeded(s.database, now); reason != "" && cfg != nil && cfg.Limits.Refuse && !req.Override {
+		apiError(w, http.StatusForbidden, "not starting: %s (set \"override\": true to start anyway)", reason)
+		return
+	}
+	id, err := s.database.CreateSession(now, now.Add(d), description, int64(d.Seconds()), strings.Join(tags, ","), false)
+	if err != nil {
+		apiError(w, http.StatusInternalServerError, "error creating session: %v", err)
+		return
+	}
+	if req.Task != 0 {
+		if err := s.database.SetSessionTask(id, req.Task); err != nil {
+			warnf("%v\n", err)
+		}
+	}
+	if category != "" {
+		if err := s.database.SetSessionMetadata(id, db.MetaCategory, category); err != nil {
+			warnf("Error saving category: %v\n", err)
+		}
+	}
+	auditSession(w, id)
+	unlock()
+	runHooks(s.database, hooks.SessionStart, id)
+	ensureDaemon()
+
+	s.writeStatus(w, http.StatusCreated)
+}
+
+func (s *apiServer) pause(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	unlock := sync.OnceFunc(s.mu.Unlock)
+	defer unlock()
+
+	session, err := activeSession(s.database)
+	switch {
+	case err != nil:
+		apiError(w, http.StatusInternalServerError, "error getting active session: %v", err)
+		return
+	case session == nil:
+		apiError(w, http.StatusConflict, "no active session to pause")
+		return
+	}
+	auditSession(w, session.ID)
+	if !session.IsPaused {
+		if _, err := recordPause(s.database, session); err != nil {
+			apiError(w, http.StatusInternalServerError, "error pausing session: %v", err)
+			return
+		}
+		unlock()
+		runHooks(s.database, hooks.Pause, session.ID)
+	}
+	unlock()
+	s.writeStatus(w, http.StatusOK)
+}
+
+func (s *apiServer) resume(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	unlock := sync.OnceFunc(s.mu.Unlock)
+	defer unlock()
+
+	session, err := s.database.GetPausedSession()
+	switch {
+	case err != nil:
+		apiError(w, http.StatusInternalServerError, "error getting paused session: %v", err)
+		return
+	case session == nil:
+		apiError(w, http.StatusConflict, "no paused session to resume")
+		return
+	}
+	auditSession(w, session.ID)
+	_, recorded, err := recordResume(s.database, session)
+	if err != nil {
+		apiError(w, http.StatusInternalServerError, "error resuming session: %v", err)
+		return
+	}
+	unlock()
+	if recorded {
+		ensureDaemon()
+	}
+	runHooks(s.database, hooks.Resume, session.ID)
+	s.writeStatus(w, http.StatusOK)
+}
+
+func (s *apiServer) cancel(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	unlock := sync.OnceFunc(s.mu.Unlock)
+	defer unlock()
+
+	session, err := activeSession(s.database)
+	switch {
+	case err != nil:
+		apiError(w, http.StatusInternalServerError, "error getting active session: %v", err)
+		return
+	case session == nil:
+		apiError(w, http.StatusConflict, "no active session to cancel")
+		return
+	}
+	auditSession(w, session.ID)
+	if _, err := recordCancel(s.database, session); err != nil {
+		apiError(w, http.StatusInternalServerError, "error cancelling session: %v", err)
+		return
+	}
+	unlock()
+	runHooks(s.database, hooks.Cancel, session.ID)
+	ended, err := s.database.GetSessionByID(session.ID)
+	if err != nil || ended == nil {
+		ended = session
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Cancelled jsonSession `json:"cancelled"`
+	}{newJSONSession(*ended, nil)})
+}
+
+// history lists recent sessions, newest first. ?limit=N sets how many and
+// ?breaks=true includes breaks.
+func (s *apiServer) history(w http.ResponseWriter, r *http.Request) {
+	limit := apiHistoryLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			apiError(w, http.StatusBadRequest, "invalid limit: must be a positive number")
+			return
+		}
+		limit = n
+	}
+	breaks, _ := strconv.ParseBool(r.URL.Query().Get("breaks"))
+
+	sessions, err := s.database.GetRecentSessions(limit, breaks)
+	if err != nil {
+		apiError(w, http.StatusInternalServerError, "error getting history: %v", err)
+		return
+	}
+	out := make([]jsonSession, 0, len(sessions))
+	for _, session := range sessions {
+		out = append(out, newJSONSession(session, nil))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *apiServer) goals(w http.ResponseWriter, _ *http.Request) {
+	now := app.now()
+	status, carry, err := goalStatus(s.database, now)
+	if err != nil {
+		apiError(w, http.StatusInternalServerError, "error getting goal status: %v", err)
+		return
+	}
+	var counters []counterStatus
+	if cfg, err := config.LoadConfig(); err == nil {
+		if counters, err = todayCounters(s.database, cfg, now); err != nil {
+			apiError(w, http.StatusInternalServerError, "error getting counters: %v", err)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, newGoalsJSON(status, carry, counters))
+}
+
+// annotate appends a note to a session, given by ID or #ref
+func (s *apiServer) annotate(w http.ResponseWriter, r *http.Request) {
+	var req apiAnnotateRequest
+	if err := decodeBody(r, &req); err != nil {
+		apiError(w, http.StatusBadRequest, "invalid request: %v", err)
+		return
+	}
+	text := strings.TrimSpace(req.Text)
+	if err := utils.ValidateAnnotation(text); err != nil {
+		apiError(w, http.StatusBadRequest, "invalid annotation: %v", err)
+		return
+	}
+	source := strings.TrimSpace(req.Source)
+	if source == "" {
+		source = "api"
+	}
+
+	ref := r.PathValue("id")
+	session, err := s.database.ResolveSession(ref)
+	switch {
+	case err != nil:
+		apiError(w, http.StatusBadRequest, "%v", err)
+		return
+	case session == nil:
+		apiError(w, http.StatusNotFound, "no session found for %s", ref)
+		return
+	}
+	auditSession(w, session.ID)
+	id, err := s.database.AddAnnotation(session.ID, source, text)
+	if err != nil {
+		apiError(w, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"id": id, "session_id": session.ID, "source": source, "text": text,
+	})
+}
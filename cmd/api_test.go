@@ -0,0 +1,442 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/hooks"
+)
+
+func TestAPI(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	// The API would otherwise start a daemon to time the session
+	configDir := filepath.Join(home, ".config", "pomodoro")
+	if err := os.MkdirAll(configDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "config.yml"), []byte("daemon:\n  auto_start: false\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	saved := databasePath
+	databasePath = filepath.Join(t.TempDir(), "history.db")
+	defer func() { databasePath = saved }()
+	database, err := openDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = database.Close() }()
+
+	server := httptest.NewServer(newAPIHandler(database, "secret", nil, 0))
+	defer server.Close()
+
+	call := func(method, path, body string, out any) int {
+		t.Helper()
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer secret")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if out != nil {
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				t.Fatalf("%s %s: invalid response: %v", method, path, err)
+			}
+		}
+		return resp.StatusCode
+	}
+
+	var status apiStatus
+	if code := call("GET", "/status", "", &status); code != http.StatusOK || status.Active {
+		t.Fatalf("Expected no active session, got %d %+v", code, status)
+	}
+
+	code := call("POST", "/start", `{"description":"Write report","tags":["work"],"duration":"50m"}`, &status)
+	if code != http.StatusCreated || !status.Active || status.Session.Description != "Write report" ||
+		status.Session.Duration != "50m0s" || status.RemainingSec <= 0 {
+		t.Fatalf("Expected the session to start, got %d %+v", code, status)
+	}
+	id := status.Session.ID
+
+	var apiErr map[string]string
+	if code := call("POST", "/start", "", &apiErr); code != http.StatusConflict {
+		t.Errorf("Expected a second start to conflict, got %d %v", code, apiErr)
+	}
+
+	if code := call("POST", "/pause", "", &status); code != http.StatusOK || !status.Paused {
+		t.Errorf("Expected the session to pause, got %d %+v", code, status)
+	}
+	if code := call("POST", "/resume", "", &status); code != http.StatusOK || status.Paused {
+		t.Errorf("Expected the session to resume, got %d %+v", code, status)
+	}
+
+	var note map[string]any
+	if code := call("POST", "/sessions/"+strconv.FormatInt(id, 10)+"/annotations", `{"text":"CI passed","source":"ci"}`, &note); code != http.StatusCreated {
+		t.Errorf("Expected the annotation to be added, got %d %v", code, note)
+	}
+	if annotations, err := database.GetAnnotations(id); err != nil || len(annotations) != 1 || annotations[0].Source != "ci" {
+		t.Errorf("Expected the annotation to be saved, got %+v, %v", annotations, err)
+	}
+
+	var cancelled struct{ Cancelled jsonSession }
+	if code := call("POST", "/cancel", "", &cancelled); code != http.StatusOK || cancelled.Cancelled.Status != db.StatusCancelled {
+		t.Errorf("Expected the session to be cancelled, got %d %+v", code, cancelled)
+	}
+	if code := call("POST", "/pause", "", &apiErr); code != http.StatusConflict {
+		t.Errorf("Expected nothing to pause, got %d", code)
+	}
+
+	var history []jsonSession
+	if code := call("GET", "/history?limit=5", "", &history); code != http.StatusOK || len(history) != 1 || history[0].ID != id {
+		t.Errorf("Expected the session in the history, got %d %+v", code, history)
+	}
+
+	var goals goalsJSON
+	if code := call("GET", "/goals", "", &goals); code != http.StatusOK || goals.DailyGoal == 0 {
+		t.Errorf("Expected the goals, got %d %+v", code, goals)
+	}
+
+	// Without the token, or from a web page, requests are refused
+	resp, err := http.Get(server.URL + "/status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a request without the token to be refused, got %d", resp.StatusCode)
+	}
+	req, _ := http.NewRequest("POST", server.URL+"/start", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Origin", "https://example.com")
+	if resp, err = http.DefaultClient.Do(req); err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected a request from a web page to be refused, got %d", resp.StatusCode)
+	}
+}
+
+func TestAPITokens(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	database, err := db.NewDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = database.Close() }()
+
+	server := httptest.NewServer(newAPIHandler(database, "", nil, 0))
+	defer server.Close()
+
+	call := func(method, path, token string) int {
+		t.Helper()
+		req, err := http.NewRequest(method, server.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := call("GET", "/status", ""); code != http.StatusOK {
+		t.Fatalf("Expected the API to be open without tokens, got %d", code)
+	}
+
+	// Open only to this machine
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/status", nil)
+	req.RemoteAddr = "192.0.2.7:51234"
+	newAPIHandler(database, "", nil, 0).ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected a client on another machine to need a token, got %d", rec.Code)
+	}
+
+	// Nor to clients a proxy on this machine forwards
+	for header, value := range map[string]string{"X-Forwarded-For": "192.0.2.7", "Forwarded": "for=192.0.2.7", "X-Real-Ip": "192.0.2.7"} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/pause", nil)
+		req.RemoteAddr = "127.0.0.1:51234"
+		req.Header.Set(header, value)
+		newAPIHandler(database, "", nil, 0).ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected a request forwarded with %s to need a token, got %d", header, rec.Code)
+		}
+	}
+	for addr, want := range map[string]bool{"127.0.0.1:7070": true, "localhost:7070": true, "[::1]:7070": true, "0.0.0.0:7443": false, ":9090": false, "192.168.1.5:7070": false} {
+		if got := loopbackAddr(addr); got != want {
+			t.Errorf("Expected loopbackAddr(%q) to be %v", addr, want)
+		}
+	}
+
+	reader, err := database.CreateAPIToken("grafana", db.ScopeRead)
+	if err != nil {
+		t.Fatal(err)
+	}
+	full, err := database.CreateAPIToken("stream-deck", db.ScopeFull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.CreateAPIToken("grafana", db.ScopeFull); err == nil {
+		t.Error("Expected a second token with the same name to be refused")
+	}
+
+	if code := call("GET", "/status", ""); code != http.StatusUnauthorized {
+		t.Errorf("Expected requests without a token to be refused once tokens exist, got %d", code)
+	}
+	if code := call("GET", "/status", "pomo_wrong"); code != http.StatusUnauthorized {
+		t.Errorf("Expected a wrong token to be refused, got %d", code)
+	}
+	if code := call("GET", "/status", reader); code != http.StatusOK {
+		t.Errorf("Expected a read token to read the status, got %d", code)
+	}
+	if code := call("POST", "/pause", reader); code != http.StatusForbidden {
+		t.Errorf("Expected a read token to be refused control, got %d", code)
+	}
+	if code := call("POST", "/pause", full); code == http.StatusUnauthorized || code == http.StatusForbidden {
+		t.Errorf("Expected a full token to control the timer, got %d", code)
+	}
+
+	if err := database.RevokeAPIToken("grafana"); err != nil {
+		t.Fatal(err)
+	}
+	if code := call("GET", "/status", reader); code != http.StatusUnauthorized {
+		t.Errorf("Expected a revoked token to be refused, got %d", code)
+	}
+
+	tokens, err := database.ListAPITokens(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 2 || tokens[0].RevokedAt == nil || tokens[1].LastUsedAt == nil {
+		t.Errorf("Expected grafana revoked and stream-deck used, got %+v", tokens)
+	}
+}
+
+func TestAPICORS(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	database, err := db.NewDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = database.Close() }()
+	if _, err := database.CreateAPIToken("dashboard", db.ScopeRead); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(newAPIHandler(database, "", []string{"https://dash.example.com/"}, 0))
+	defer server.Close()
+
+	call := func(method, origin string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(method, server.URL+"/status", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Origin", origin)
+		if method == http.MethodOptions {
+			req.Header.Set("Access-Control-Request-Method", "GET")
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		return resp
+	}
+
+	// A preflight from an allowed origin is answered without a token
+	resp := call(http.MethodOptions, "https://dash.example.com")
+	if resp.StatusCode != http.StatusNoContent || resp.Header.Get("Access-Control-Allow-Origin") != "https://dash.example.com" ||
+		!strings.Contains(resp.Header.Get("Access-Control-Allow-Headers"), "Authorization") {
+		t.Errorf("Expected the preflight to be allowed, got %d %v", resp.StatusCode, resp.Header)
+	}
+	// The request itself still needs a token
+	if resp := call(http.MethodGet, "https://dash.example.com"); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a request without a token to be refused, got %d", resp.StatusCode)
+	}
+	// Other origins are refused outright
+	for _, method := range []string{http.MethodOptions, http.MethodGet} {
+		resp := call(method, "https://evil.example.com")
+		if resp.StatusCode != http.StatusForbidden || resp.Header.Get("Access-Control-Allow-Origin") != "" {
+			t.Errorf("Expected %s from another origin to be refused, got %d", method, resp.StatusCode)
+		}
+	}
+}
+
+func TestAPIAuditAndRateLimit(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configDir := filepath.Join(home, ".config", "pomodoro")
+	if err := os.MkdirAll(configDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "config.yml"), []byte("daemon:\n  auto_start: false\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	database, err := db.NewDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = database.Close() }()
+	deck, err := database.CreateAPIToken("stream-deck", db.ScopeFull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := database.CreateAPIToken("grafana", db.ScopeRead)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(newAPIHandler(database, "", nil, 3))
+	defer server.Close()
+
+	call := func(method, path, token string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(method, server.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		return resp
+	}
+
+	if resp := call("POST", "/start", deck); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected the session to start, got %d", resp.StatusCode)
+	}
+	call("POST", "/cancel", deck)
+	call("GET", "/status", deck)
+	// The fourth request in a minute is over the limit of 3
+	resp := call("POST", "/start", deck)
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") == "" {
+		t.Errorf("Expected the client to be rate limited, got %d %v", resp.StatusCode, resp.Header)
+	}
+	// Other tokens have allowances of their own
+	if resp := call("POST", "/pause", reader); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected a read token to be refused, not limited, got %d", resp.StatusCode)
+	}
+	call("POST", "/pause", "pomo_wrong")
+
+	entries, err := database.ListAPIAudit(db.APIAuditFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Client+" "+e.Method+" "+e.Path+" "+strconv.Itoa(e.Status))
+	}
+	want := []string{
+		" POST /pause 401",
+		"grafana POST /pause 403",
+		"stream-deck POST /start 429",
+		"stream-deck POST /cancel 200",
+		"stream-deck POST /start 201",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected audit log:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if len(entries) == len(want) && (entries[4].SessionID == 0 || entries[3].SessionID != entries[4].SessionID) {
+		t.Errorf("Expected the start and cancel to name the session, got %d and %d", entries[4].SessionID, entries[3].SessionID)
+	}
+
+	filtered, err := database.ListAPIAudit(db.APIAuditFilter{Client: "stream-deck", Limit: 1})
+	if err != nil || len(filtered) != 1 || filtered[0].Status != http.StatusTooManyRequests {
+		t.Errorf("Expected the latest stream-deck entry, got %+v %v", filtered, err)
+	}
+}
+
+func TestAPIHooksDoNotHoldLock(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks here are shell scripts")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	hooksDir := filepath.Join(home, "hooks")
+	started, proceed := filepath.Join(home, "started"), filepath.Join(home, "proceed")
+	// The start hook holds on until told to go on, for up to five seconds
+	writeFile := func(path, data string, mode os.FileMode) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(filepath.Join(hooksDir, hooks.SessionStart), "#!/bin/sh\ntouch '"+started+"'\n"+
+		"for i in $(seq 50); do [ -e '"+proceed+"' ] && exit 0; sleep 0.1; done\nexit 1\n", 0700)
+	writeFile(filepath.Join(home, ".config", "pomodoro", "config.yml"),
+		"daemon:\n  auto_start: false\nhooks:\n  enabled: true\n  path: "+hooksDir+"\n", 0600)
+
+	saved := databasePath
+	databasePath = filepath.Join(t.TempDir(), "history.db")
+	defer func() { databasePath = saved }()
+	database, err := openDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = database.Close() }()
+	server := httptest.NewServer(newAPIHandler(database, "", nil, 0))
+	defer server.Close()
+
+	startDone := make(chan int, 1)
+	go func() {
+		resp, err := http.Post(server.URL+"/start", "application/json", strings.NewReader(`{"description":"Write report"}`))
+		if err != nil {
+			startDone <- 0
+			return
+		}
+		_ = resp.Body.Close()
+		startDone <- resp.StatusCode
+	}()
+	for i := 0; ; i++ {
+		if _, err := os.Stat(started); err == nil {
+			break
+		}
+		if i == 50 {
+			t.Fatal("Expected the start hook to run")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// The session is recorded, so it can be paused while its hook runs
+	resp, err := (&http.Client{Timeout: 2 * time.Second}).Post(server.URL+"/pause", "application/json", nil)
+	if err != nil {
+		t.Fatalf("Expected pause to be answered while the start hook runs, got %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the session paused, got %d", resp.StatusCode)
+	}
+
+	writeFile(proceed, "", 0600)
+	if code := <-startDone; code != http.StatusCreated {
+		t.Errorf("Expected the session started, got %d", code)
+	}
+}
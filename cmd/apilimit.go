@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"sync"
+	"time"
+)
+
+// apiLimiterMaxIdle is how many clients the limiter tracks before it
+// forgets those that have not used the API lately
+const apiLimiterMaxIdle = 1024
+
+// apiLimiter limits how often each API client may make requests, with a
+// token bucket per client that holds a minute's worth of requests, so a
+// client may burst after being quiet but not keep up more than the limit
+type apiLimiter struct {
+	perMinute int
+
+	mu      sync.Mutex
+	buckets map[string]*apiBucket
+}
+
+// apiBucket is one client's allowance
+type apiBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newAPILimiter returns a limiter allowing perMinute requests a minute per
+// client, or nil, which allows everything, for perMinute 0
+func newAPILimiter(perMinute int) *apiLimiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &apiLimiter{perMinute: perMinute, buckets: map[string]*apiBucket{}}
+}
+
+// allow takes a request from client's allowance, reporting whether there
+// was one and, if not, how long until there is
+func (l *apiLimiter) allow(client string, now time.Time) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	capacity := float64(l.perMinute)
+	perSecond := capacity / 60
+	if len(l.buckets) >= apiLimiterMaxIdle {
+		// A full bucket is the same as no bucket, so forgetting it is free
+		for key, b := range l.buckets {
+			if b.tokens+now.Sub(b.last).Seconds()*perSecond >= capacity {
+				delete(l.buckets, key)
+			}
+		}
+	}
+
+	b := l.buckets[client]
+	if b == nil {
+		b = &apiBucket{tokens: capacity, last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = min(capacity, b.tokens+now.Sub(b.last).Seconds()*perSecond)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / perSecond * float64(time.Second))
+}
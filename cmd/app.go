@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/audio"
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/notify"
+)
+
+// appContext is what commands reach outside the process through: the
+// session database, the clock, notifications, and sounds. Commands take
+// them from app instead of building their own, so tests can run a command
+// in process against a mock database, a fixed clock, and a notifier and
+// player that record what they were given.
+type appContext struct {
+	openDB   func() (db.DB, error)
+	now      func() time.Time
+	sleep    func(time.Duration)
+	notifier func() notify.Notifier
+	player   func() audio.Player
+}
+
+// app is the context commands run in
+var app = newAppContext()
+
+// newAppContext returns the context of a normal run: the database chosen
+// for it, the wall clock, and the configured notifiers and sounds
+func newAppContext() *appContext {
+	return &appContext{
+		openDB:   func() (db.DB, error) { return openInternalDB() },
+		now:      time.Now,
+		sleep:    time.Sleep,
+		notifier: notify.DefaultNotifier,
+		player:   configuredPlayer,
+	}
+}
+
+// configuredPlayer returns a player for the audio config, or one that plays
+// nothing when the config cannot be loaded
+func configuredPlayer() audio.Player {
+	cfg, err := config.LoadConfig()
+	if err != nil || cfg.Audio == nil {
+		return &audio.NoOpPlayer{}
+	}
+	player, err := audio.NewPlayer(cfg.Audio)
+	if err != nil {
+		return &audio.NoOpPlayer{}
+	}
+	return player
+}
+
+// announce sends a notification and, unless silent, plays sound
+func (a *appContext) announce(title, message string, sound audio.SoundType, silent bool) error {
+	if err := a.notifier().Send(title, message); err != nil {
+		return err
+	}
+	if !silent {
+		audio.PlayAsync(a.player(), sound)
+	}
+	return nil
+}
+
+// notifyPomodoroComplete announces the end of a pomodoro
+func (a *appContext) notifyPomodoroComplete(description string, silent bool) error {
+	return a.announce(notify.PomodoroCompleteTitle, notify.PomodoroCompleteMessage(description), audio.PomodoroComplete, silent)
+}
+
+// notifyBreakComplete announces the end of a break
+func (a *appContext) notifyBreakComplete(silent bool) error {
+	return a.announce(notify.BreakCompleteTitle, notify.BreakCompleteMessage, audio.BreakComplete, silent)
+}
+
+// notify sends a plain notification
+func (a *appContext) notify(title, message string) error {
+	return a.notifier().Send(title, message)
+}
@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/ethan-k/pomodoro-cli/internal/audio"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/notify"
+	"github.com/ethan-k/pomodoro-cli/internal/output"
+)
+
+// testApp runs commands in process against a mock database and a clock
+// fixed at now, recording the notifications sent and sounds played
+type testApp struct {
+	database *mockDB
+	now      time.Time
+
+	mu     sync.Mutex
+	sent   []string
+	played chan audio.SoundType
+}
+
+// newTestApp points app at database and now until the test ends. HOME and
+// XDG_RUNTIME_DIR are temporary directories, so the user's config is not
+// read and no running daemon is found.
+func newTestApp(t *testing.T, database *mockDB, now time.Time) *testApp {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	t.Setenv("POMODORO_USER", "")
+
+	a := &testApp{database: database, now: now, played: make(chan audio.SoundType, 8)}
+	saved := app
+	app = &appContext{
+		openDB:   func() (db.DB, error) { return database, nil },
+		now:      func() time.Time { return a.now },
+		sleep:    func(d time.Duration) { a.now = a.now.Add(d) },
+		notifier: func() notify.Notifier { return recordingNotifier{a} },
+		player:   func() audio.Player { return recordingPlayer{a} },
+	}
+	t.Cleanup(func() { app = saved })
+	return a
+}
+
+// run executes the command line args and returns what it printed to stdout
+func (a *testApp) run(t *testing.T, args ...string) string {
+	t.Helper()
+	defer resetFlags(rootCmd)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	out := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		out <- string(data)
+	}()
+
+	rootCmd.SetArgs(args)
+	_, err = rootCmd.ExecuteC()
+	os.Stdout = stdout
+	_ = w.Close()
+	printed := <-out
+	if err != nil {
+		t.Fatalf("pomodoro %s failed: %v", strings.Join(args, " "), err)
+	}
+	return printed
+}
+
+// resetFlags returns the flags of cmd and its subcommands to their defaults,
+// as they are at the start of a run
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if s, ok := f.Value.(pflag.SliceValue); ok {
+			_ = s.Replace(nil)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
+
+// recordingNotifier records the notifications sent through it
+type recordingNotifier struct{ a *testApp }
+
+func (n recordingNotifier) Send(title, message string) error {
+	n.a.mu.Lock()
+	defer n.a.mu.Unlock()
+	n.a.sent = append(n.a.sent, title+": "+message)
+	return nil
+}
+
+func (n recordingNotifier) SendWithActions(title, message string, _ []notify.Action) (string, error) {
+	return "", n.Send(title, message)
+}
+
+// recordingPlayer records the sounds played through it
+type recordingPlayer struct{ a *testApp }
+
+func (p recordingPlayer) Play(sound audio.SoundType) error {
+	p.a.played <- sound
+	return nil
+}
+
+func (p recordingPlayer) SetVolume(float64) error { return nil }
+func (p recordingPlayer) IsEnabled() bool         { return true }
+func (p recordingPlayer) Close() error            { return nil }
+
+func TestPauseAndCancelCommands(t *testing.T) {
+	now := time.Date(2025, 3, 14, 9, 10, 0, 0, time.UTC)
+	session := &db.PomodoroSession{ID: 4, Description: `Write "report"`, StartTime: now.Add(-10 * time.Minute), EndTime: now.Add(15 * time.Minute), DurationSec: 25 * 60}
+
+	var pausedAt, endedAt time.Time
+	var status string
+	a := newTestApp(t, &mockDB{
+		GetActiveSessionFunc: func() (*db.PomodoroSession, error) { return session, nil },
+		PauseSessionFunc: func(id int64, at time.Time) error {
+			if id != session.ID {
+				t.Errorf("Paused session %d, want %d", id, session.ID)
+			}
+			pausedAt = at
+			return nil
+		},
+		EndSessionFunc: func(id int64, at time.Time, s string) error {
+			endedAt, status = at, s
+			return nil
+		},
+	}, now)
+
+	out := a.run(t, "pause", "--json")
+	var paused map[string]any
+	if err := json.Unmarshal([]byte(out), &paused); err != nil {
+		t.Fatalf("Unexpected pause output %q: %v", out, err)
+	}
+	if !pausedAt.Equal(now) || paused["status"] != "paused" || paused["paused_at"] != now.Format(time.RFC3339) ||
+		paused["description"] != session.Description || paused["schema_version"] != float64(output.SchemaVersion) {
+		t.Errorf("Unexpected pause at %s: %v", pausedAt, paused)
+	}
+
+	a.now = now.Add(5 * time.Minute)
+	out = a.run(t, "cancel")
+	if !endedAt.Equal(a.now) || status != db.StatusCancelled {
+		t.Errorf("Expected the session cancelled at %s, got %s as %q", a.now, endedAt, status)
+	}
+	if want := "Cancelled Pomodoro session: Write \"report\" (ran for 15m0s)\n"; out != want {
+		t.Errorf("cancel printed %q, want %q", out, want)
+	}
+}
+
+func TestHistoryCommand(t *testing.T) {
+	now := time.Date(2025, 3, 14, 17, 0, 0, 0, time.UTC)
+	start := now.Add(-8 * time.Hour)
+	var from, to time.Time
+	a := newTestApp(t, &mockDB{
+		GetSessionsByDateRangeFunc: func(startDate, endDate time.Time, _ ...string) ([]db.PomodoroSession, error) {
+			from, to = startDate, endDate
+			return []db.PomodoroSession{
+				{ID: 1, StartTime: start, EndTime: start.Add(25 * time.Minute), Description: "Plan", DurationSec: 25 * 60, Status: db.StatusCompleted},
+				{ID: 2, StartTime: start.Add(time.Hour), EndTime: start.Add(70 * time.Minute), Description: "Draft", DurationSec: 25 * 60, Status: db.StatusCancelled},
+			}, nil
+		},
+	}, now)
+
+	out := a.run(t, "history", "--include-cancelled", "--format", "%i %d %S")
+	if want := "#1 Plan completed\n#2 Draft cancelled\n"; out != want {
+		t.Errorf("history printed %q, want %q", out, want)
+	}
+	if today := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC); !from.Equal(today) || !to.Equal(today.AddDate(0, 0, 1)) {
+		t.Errorf("Expected today's sessions by the fixed clock, got %s to %s", from, to)
+	}
+
+	out = a.run(t, "history", "--summary", "--output", "json")
+	var report historyReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("Unexpected history output %q: %v", out, err)
+	}
+	if report.Summary.Pomodoros != 1 || report.Summary.Rated != 2 || report.Summary.Completed != 1 || report.Summary.CompletionRate != 0.5 {
+		t.Errorf("Unexpected summary %+v", report.Summary)
+	}
+}
+
+func TestJSONFlag(t *testing.T) {
+	for _, tc := range []struct {
+		cmd  *cobra.Command
+		want bool
+	}{
+		{statusCmd, true},
+		{taskListCmd, true}, // Through task
+		{configCmd, false},
+		{rootCmd, false},
+	} {
+		if got := hasJSON(tc.cmd); got != tc.want {
+			t.Errorf("hasJSON(%s) = %v, want %v", tc.cmd.CommandPath(), got, tc.want)
+		}
+	}
+
+	a := newTestApp(t, &mockDB{
+		GetSessionsByDateRangeFunc: func(time.Time, time.Time, ...string) ([]db.PomodoroSession, error) {
+			return []db.PomodoroSession{{ID: 1, Description: "Plan", Status: db.StatusCompleted}}, nil
+		},
+	}, time.Now())
+	var sessions []map[string]any
+	if out := a.run(t, "history", "--json"); json.Unmarshal([]byte(out), &sessions) != nil || len(sessions) != 1 {
+		t.Errorf("Expected history --json to print the sessions as JSON, got %q", out)
+	}
+}
+
+func TestSessionJSONShape(t *testing.T) {
+	now := time.Date(2025, 3, 14, 9, 10, 0, 0, time.UTC)
+	session := db.PomodoroSession{ID: 4, Description: "Write report", TagsCSV: "work,writing", StartTime: now.Add(-10 * time.Minute), EndTime: now.Add(15 * time.Minute)}
+	a := newTestApp(t, &mockDB{
+		GetActiveSessionFunc: func() (*db.PomodoroSession, error) { return &session, nil },
+		GetSessionsByDateRangeFunc: func(time.Time, time.Time, ...string) ([]db.PomodoroSession, error) {
+			return []db.PomodoroSession{session}, nil
+		},
+	}, now)
+
+	// status and history describe a session with the same fields
+	check := func(name string, fields map[string]any) {
+		t.Helper()
+		if tags, ok := fields["tags"].([]any); !ok || len(tags) != 2 || tags[0] != "work" || tags[1] != "writing" {
+			t.Errorf("Expected %s to list the tags, got %v", name, fields["tags"])
+		}
+		if wasBreak, ok := fields["was_break"].(bool); !ok || wasBreak {
+			t.Errorf("Expected %s to give was_break, got %v", name, fields)
+		}
+		for _, old := range []string{"is_break", "tags_csv"} {
+			if _, ok := fields[old]; ok {
+				t.Errorf("Expected %s without %s, got %v", name, old, fields)
+			}
+		}
+	}
+	var status map[string]any
+	if out := a.run(t, "status", "--json"); json.Unmarshal([]byte(out), &status) != nil {
+		t.Fatalf("Unexpected status output %q", out)
+	}
+	check("status", status)
+
+	pausedAt := now.Add(-time.Minute)
+	session.IsPaused, session.PausedAt = true, &pausedAt
+	if out := a.run(t, "status", "--json"); json.Unmarshal([]byte(out), &status) != nil || status["status"] != "paused" {
+		t.Fatalf("Unexpected paused status output %q", out)
+	}
+	check("paused status", status)
+
+	var history []map[string]any
+	if out := a.run(t, "history", "--json"); json.Unmarshal([]byte(out), &history) != nil || len(history) != 1 {
+		t.Fatalf("Unexpected history output %q", out)
+	}
+	check("history", history[0])
+}
+
+func TestAppNotifications(t *testing.T) {
+	a := newTestApp(t, &mockDB{}, time.Now())
+
+	if err := app.notifyPomodoroComplete("Write report", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.notifyBreakComplete(false); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Pomodoro Complete: Task completed: Write report", "Break Complete: Break time is over. Resume work."}
+	if strings.Join(a.sent, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Sent %q, want %q", a.sent, want)
+	}
+
+	// Only the break played a sound, the pomodoro being silent
+	select {
+	case sound := <-a.played:
+		if sound != audio.BreakComplete {
+			t.Errorf("Played %s, want %s", sound, audio.BreakComplete)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the break sound to play")
+	}
+	select {
+	case sound := <-a.played:
+		t.Errorf("Unexpected sound %s", sound)
+	default:
+	}
+}
@@ -6,114 +6,297 @@ import (
 	"os"
 	"time"
 
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 
+	"github.com/ethan-k/pomodoro-cli/internal/config"
 	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/hooks"
+	"github.com/ethan-k/pomodoro-cli/internal/idle"
 	"github.com/ethan-k/pomodoro-cli/internal/model"
-	"github.com/ethan-k/pomodoro-cli/internal/notify"
 	"github.com/ethan-k/pomodoro-cli/internal/utils"
 )
 
 var (
 	breakDuration time.Duration
 	breakWait     bool
-	breakJSON     bool
 	breakSilent   bool
+	breakLong     bool
+	breakShort    bool
+	breakBreathe  string
+	breakNoForce  bool
 )
 
+// forceBreakActions are what wellness.force_break does as a break starts
+var forceBreakActions = map[string]func() error{
+	"lock": idle.LockScreen,
+	"dim":  idle.SleepDisplay,
+}
+
 // breakCmd represents the break command
 var breakCmd = &cobra.Command{
 	Use:   "break [duration]",
 	Short: "Starts a break timer",
 	Long: `Starts a break timer.
 
-You can specify the duration for the break. If not provided, a default of 5 minutes will be used.
+You can specify the duration for the break. If not provided, the configured
+break duration (defaults.break_duration, 5 minutes by default) will be used.
+Use --long for a long break (defaults.long_break_duration).
 Use the --wait flag to keep the timer running in the terminal.
 
+After every defaults.long_break_interval completed pomodoros (4 by default)
+the break is a long one, which starts the next cycle. Use --short to take a
+short break anyway.
+
+Use --breathe to be guided through a breathing exercise for the break: box
+breathing (in, hold, out, hold for 4 seconds each) by default, or
+--breathe=4-7-8. It keeps the timer in the terminal like --wait.
+
+For those who ignore reminders, wellness.force_break makes the break start
+away from the screen: lock locks it and dim puts the display to sleep.
+Unlocking works as always, so nothing is ever locked out; in an emergency,
+--no-force starts a break that leaves the screen alone.
+
 Example:
-  pomodoro break 10m --wait`,
+  pomodoro break 10m --wait
+  pomodoro break --long --wait
+  pomodoro break --breathe
+  pomodoro break 3m --breathe=4-7-8
+  pomodoro config wellness.force_break lock`,
 	Aliases: []string{"b"},
-	Run: func(_ *cobra.Command, args []string) {
+	Run: func(cmd *cobra.Command, args []string) {
+		long := breakLong
+		if !long && !breakShort && len(args) == 0 && !cmd.Flags().Changed("duration") {
+			if due, count := longBreakDue(); due {
+				long = true
+				if !jsonOutput {
+					decorf("%s%d pomodoros done, time for a long break.\n", icon("🌴"), count)
+				}
+			}
+		}
+
 		// If duration is provided as argument, override flag
 		if len(args) > 0 {
 			var err error
-			breakDuration, err = time.ParseDuration(args[0])
+			breakDuration, err = utils.ParseHumanDuration(args[0])
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error parsing duration: %v\n", err)
 				os.Exit(1)
 			}
+		} else if !cmd.Flags().Changed("duration") {
+			breakDuration = configuredBreakDuration(long)
 		}
 
-		// Validate duration
-		if err := utils.ValidateDuration(breakDuration); err != nil {
-			fmt.Fprintf(os.Stderr, "Invalid break duration: %v\n", err)
-			os.Exit(1)
+		var breathing *model.BreathingPattern
+		if breakBreathe != "" {
+			p, err := model.ParseBreathingPattern(breakBreathe)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			breathing = &p
 		}
 
-		startTime := time.Now()
-		endTime := startTime.Add(breakDuration)
-
-		database, err := db.NewDB()
-		if err != nil {
+		if err := runBreak(breakOptions{
+			Duration:  breakDuration,
+			Long:      long,
+			Wait:      breakWait || breathing != nil,
+			JSON:      jsonOutput,
+			Silent:    breakSilent,
+			Breathing: breathing,
+			NoForce:   breakNoForce,
+		}); err != nil {
 			fmt.Fprintf(os.Stderr, "%v\n", err)
 			os.Exit(1)
 		}
-		defer func() {
-			if err := database.Close(); err != nil {
-				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
-			}
-		}()
-
-		// Create break session in database
-		id, err := database.CreateSession(
-			startTime,
-			endTime,
-			"Break",
-			int64(breakDuration.Seconds()),
-			"",
-			true, // isBreak = true
-		)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error creating break session: %v\n", err)
-			os.Exit(1)
+
+		// Nothing is left in the terminal to time the break
+		if jsonOutput || !(breakWait || breakBreathe != "") {
+			ensureDaemon()
 		}
+	},
+}
+
+// breakOptions controls how a break session is started
+type breakOptions struct {
+	Duration time.Duration
+	Long     bool // A long break, which ends the pomodoro cycle
+	Wait     bool
+	JSON     bool
+	Silent   bool
 
-		// If JSON output is requested, just print the session info and exit
-		if breakJSON {
-			fmt.Printf(`{"id":%d,"type":"break","duration":"%s","end_time":"%s"}`+"\n",
-				id, breakDuration, endTime.Format(time.RFC3339))
-			return
+	// Breathing is the exercise to guide while the break runs, if any
+	Breathing *model.BreathingPattern
+
+	// NoForce leaves the screen alone despite wellness.force_break
+	NoForce bool
+}
+
+// runBreak records a break session and, if requested, shows the progress
+// bar until it completes. It is shared by the break command and continuous mode.
+func runBreak(opts breakOptions) error {
+	// Validate duration
+	if err := utils.ValidateDuration(opts.Duration); err != nil {
+		return fmt.Errorf("invalid break duration: %v", err)
+	}
+
+	startTime := app.now()
+	endTime := startTime.Add(opts.Duration)
+
+	database, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := database.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
 		}
+	}()
 
-		// Print basic info if not waiting
-		if !breakWait {
-			fmt.Printf("Started break for %s\n", breakDuration)
-			return
+	// Create break session in database
+	id, err := database.CreateSession(
+		startTime,
+		endTime,
+		"Break",
+		int64(opts.Duration.Seconds()),
+		"",
+		true, // isBreak = true
+	)
+	if err != nil {
+		return fmt.Errorf("error creating break session: %v", err)
+	}
+	kind, label := "break", "Break Time"
+	if opts.Long {
+		kind, label = "long break", "Long Break"
+		if err := database.SetSessionMetadata(id, db.MetaBreakKind, db.BreakKindLong); err != nil {
+			fmt.Fprintf(os.Stderr, "Error marking long break: %v\n", err)
 		}
+	}
+	runHooks(database, hooks.BreakStart, id)
+	if !opts.NoForce {
+		forceBreak()
+	}
 
-		// Create and run the TUI model if waiting
-		p := model.NewPomodoroModel(id, "Break Time", startTime, breakDuration, true)
+	// If JSON output is requested, just print the session info and exit
+	if opts.JSON {
+		printJSONLine(struct {
+			ID       int64  `json:"id"`
+			Type     string `json:"type"`
+			Long     bool   `json:"long"`
+			Duration string `json:"duration"`
+			EndTime  string `json:"end_time"`
+		}{id, "break", opts.Long, opts.Duration.String(), endTime.Format(time.RFC3339)})
+		return nil
+	}
 
-		// Run the TUI program
-		if _, err := tea.NewProgram(p).Run(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error running UI: %v\n", err)
-			os.Exit(1)
+	// Print basic info if not waiting
+	if !opts.Wait {
+		fmt.Printf("Started %s for %s\n", kind, opts.Duration)
+		return nil
+	}
+
+	// Create and run the TUI model if waiting
+	p := model.NewPomodoroModel(id, label, startTime, opts.Duration, true)
+	if opts.Breathing != nil {
+		p = p.WithBreathing(*opts.Breathing)
+	}
+	if cfg, err := config.LoadConfig(); err == nil && len(cfg.Wellness.Counters) > 0 {
+		names := make([]string, len(cfg.Wellness.Counters))
+		for i, c := range cfg.Wellness.Counters {
+			names[i] = c.Name
 		}
+		p = p.WithCounters(names, func(name string) (int, error) {
+			return logCounter(database, name, 1, app.now())
+		})
+	}
+
+	// Run the TUI program
+	finished, err := runTimerUI(database, p)
+	if err != nil {
+		return fmt.Errorf("error running UI: %v", err)
+	}
+	if !finished {
+		return nil
+	}
 
-		// Send notification when complete
-		if err := notify.NotifyBreakCompleteWithOptions(breakSilent); err != nil {
-			fmt.Fprintf(os.Stderr, "Error sending notification: %v\n", err)
+	// Send notification when complete
+	markNotified(database, id)
+	runHooks(database, hooks.BreakComplete, id)
+	if err := app.notifyBreakComplete(opts.Silent); err != nil {
+		fmt.Fprintf(os.Stderr, "Error sending notification: %v\n", err)
+	}
+
+	return nil
+}
+
+// forceBreak locks the screen or puts the display to sleep as a break
+// starts, as wellness.force_break says
+func forceBreak() {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return
+	}
+	action, ok := forceBreakActions[cfg.Wellness.ForceBreak]
+	if !ok {
+		return
+	}
+	if err := action(); err != nil {
+		warnf("Could not force the break: %v\n", err)
+	}
+}
+
+// configuredBreakDuration returns the short or long break duration from the config
+func configuredBreakDuration(long bool) time.Duration {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+
+	if long {
+		return utils.ParseDurationWithDefaults(cfg.Defaults.LongBreakDuration, 15*time.Minute)
+	}
+	return utils.ParseDurationWithDefaults(cfg.Defaults.BreakDuration, 5*time.Minute)
+}
+
+// longBreakDue reports whether the pomodoros completed since the last long
+// break have reached defaults.long_break_interval, and how many there are
+func longBreakDue() (bool, int) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+	if cfg.Defaults.LongBreakInterval <= 0 {
+		return false, 0
+	}
+
+	database, err := openDB()
+	if err != nil {
+		return false, 0
+	}
+	defer func() {
+		if err := database.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
 		}
-	},
+	}()
+
+	count, err := database.GetCycleCount()
+	if err != nil {
+		warnf("%v\n", err)
+		return false, 0
+	}
+	return count >= cfg.Defaults.LongBreakInterval, count
 }
 
 func init() {
 	rootCmd.AddCommand(breakCmd)
 
 	// Define flags for the break command
-	breakCmd.Flags().DurationVarP(&breakDuration, "duration", "d", 5*time.Minute, "Duration of the break (e.g., 5m, 10m)")
+	humanDurationVarP(breakCmd.Flags(), &breakDuration, "duration", "d", 5*time.Minute, "Duration of the break (e.g., 5m, 10, \"10 min\")")
 	breakCmd.Flags().BoolVarP(&breakWait, "wait", "w", false, "Wait for the break to complete before exiting")
-	breakCmd.Flags().BoolVar(&breakJSON, "json", false, "Output in JSON format (for non-TTY usage)")
 	breakCmd.Flags().BoolVar(&breakSilent, "silent", false, "Disable audio notifications for this break")
+	breakCmd.Flags().BoolVar(&breakLong, "long", false, "Take a long break, which starts a new pomodoro cycle")
+	breakCmd.Flags().BoolVar(&breakNoForce, "no-force", false, "Leave the screen alone this break, despite wellness.force_break")
+	breakCmd.Flags().BoolVar(&breakShort, "short", false, "Take a short break even when a long break is due")
+	breakCmd.Flags().StringVar(&breakBreathe, "breathe", "", "Guide a breathing exercise during the break: box or 4-7-8")
+	breakCmd.Flags().Lookup("breathe").NoOptDefVal = "box"
+	breakCmd.MarkFlagsMutuallyExclusive("long", "short")
 }
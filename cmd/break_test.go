@@ -14,11 +14,54 @@ type mockDB struct {
 	GetActiveSessionFunc       func() (*db.PomodoroSession, error)
 	GetPausedSessionFunc       func() (*db.PomodoroSession, error)
 	GetLastSessionFunc         func() (*db.PomodoroSession, error)
+	GetSessionByIDFunc         func(id int64) (*db.PomodoroSession, error)
+	GetRecentSessionsFunc      func(limit int, includeBreaks bool) ([]db.PomodoroSession, error)
+	ResolveSessionFunc         func(ref string) (*db.PomodoroSession, error)
+	SetSessionMetadataFunc     func(id int64, key, value string) error
+	GetSessionMetadataFunc     func(id int64) (map[string]string, error)
+	GetMetadataByDateRangeFunc func(key string, startDate, endDate time.Time) (map[int64]string, error)
+	AddAnnotationFunc          func(sessionID int64, source, text string) (int64, error)
+	GetAnnotationsFunc         func(sessionID int64) ([]db.Annotation, error)
+	GetPausesFunc              func(sessionID int64) ([]db.Pause, error)
 	UpdateSessionEndTimeFunc   func(id int64, endTime time.Time) error
+	ExtendSessionFunc          func(id int64, by time.Duration) error
+	SetSessionStatusFunc       func(id int64, status string) error
+	EditSessionFunc            func(id int64, e db.SessionEdit) error
+	DeleteSessionFunc          func(id int64) error
+	EndSessionFunc             func(id int64, endedAt time.Time, status string) error
 	PauseSessionFunc           func(id int64, pausedAt time.Time) error
-	ResumeSessionFunc          func(id int64, newEndTime time.Time) error
-	GetSessionsByDateRangeFunc func(startDate, endDate time.Time) ([]db.PomodoroSession, error)
+	ResumeSessionFunc          func(id int64, resumedAt time.Time) error
+	GetSessionsByDateRangeFunc func(startDate, endDate time.Time, tags ...string) ([]db.PomodoroSession, error)
+	SearchSessionsFunc         func(opts db.SearchOptions) ([]db.PomodoroSession, error)
 	GetTodaySessionsFunc       func() ([]db.PomodoroSession, error)
+	GetSessionStatsFunc        func(startDate, endDate time.Time) (*db.SessionStats, error)
+	GetTagStatsFunc            func(startDate, endDate time.Time) ([]db.TagStats, error)
+	GetAllTagsFunc             func() ([]db.TagUse, error)
+	GetHourlyStatsFunc         func(startDate, endDate time.Time) ([]db.HourStats, error)
+	GetCycleCountFunc          func() (int, error)
+	AddTaskFunc                func(title string, estimate int) (int64, error)
+	GetTaskFunc                func(id int64) (*db.Task, error)
+	ListTasksFunc              func(includeDone bool) ([]db.Task, error)
+	CompleteTaskFunc           func(id int64, doneAt time.Time) error
+	SetTaskSourceFunc          func(id int64, source string) error
+	SetSessionTaskFunc         func(sessionID, taskID int64) error
+	GetTaskSessionsFunc        func(taskID int64) ([]db.PomodoroSession, error)
+	GetTaskStatsFunc           func(startDate, endDate time.Time) ([]db.TaskStats, error)
+	LogCounterFunc             func(name string, amount int, at time.Time) error
+	GetCounterTotalsFunc       func(startDate, endDate time.Time) (map[string]int, error)
+	RecordDailySummaryFunc     func(day time.Time, goal int) (*db.DailySummary, error)
+	GetDailySummariesFunc      func() ([]db.DailySummary, error)
+	AddVacationFunc            func(from, to time.Time) (int64, error)
+	GetVacationsFunc           func() ([]db.Vacation, error)
+	DeleteVacationFunc         func(id int64) error
+	RecordTemplateUseFunc      func(name string, at time.Time) error
+	GetTemplateUsageFunc       func() (map[string]db.TemplateUsage, error)
+	CreateAPITokenFunc         func(name, scope string) (string, error)
+	ListAPITokensFunc          func(includeRevoked bool) ([]db.APIToken, error)
+	RevokeAPITokenFunc         func(name string) error
+	AuthenticateAPITokenFunc   func(secret string, usedAt time.Time) (*db.APIToken, error)
+	RecordAPIAuditFunc         func(entry db.APIAuditEntry) error
+	ListAPIAuditFunc           func(filter db.APIAuditFilter) ([]db.APIAuditEntry, error)
 	CloseFunc                  func() error
 }
 
@@ -50,6 +93,69 @@ func (m *mockDB) GetLastSession() (*db.PomodoroSession, error) {
 	return nil, nil
 }
 
+func (m *mockDB) GetSessionByID(id int64) (*db.PomodoroSession, error) {
+	if m.GetSessionByIDFunc != nil {
+		return m.GetSessionByIDFunc(id)
+	}
+	return nil, nil
+}
+
+func (m *mockDB) GetRecentSessions(limit int, includeBreaks bool) ([]db.PomodoroSession, error) {
+	if m.GetRecentSessionsFunc != nil {
+		return m.GetRecentSessionsFunc(limit, includeBreaks)
+	}
+	return nil, nil
+}
+
+func (m *mockDB) ResolveSession(ref string) (*db.PomodoroSession, error) {
+	if m.ResolveSessionFunc != nil {
+		return m.ResolveSessionFunc(ref)
+	}
+	return nil, nil
+}
+
+func (m *mockDB) SetSessionMetadata(id int64, key, value string) error {
+	if m.SetSessionMetadataFunc != nil {
+		return m.SetSessionMetadataFunc(id, key, value)
+	}
+	return nil
+}
+
+func (m *mockDB) GetSessionMetadata(id int64) (map[string]string, error) {
+	if m.GetSessionMetadataFunc != nil {
+		return m.GetSessionMetadataFunc(id)
+	}
+	return nil, nil
+}
+
+func (m *mockDB) GetMetadataByDateRange(key string, startDate, endDate time.Time) (map[int64]string, error) {
+	if m.GetMetadataByDateRangeFunc != nil {
+		return m.GetMetadataByDateRangeFunc(key, startDate, endDate)
+	}
+	return nil, nil
+}
+
+func (m *mockDB) AddAnnotation(sessionID int64, source, text string) (int64, error) {
+	if m.AddAnnotationFunc != nil {
+		return m.AddAnnotationFunc(sessionID, source, text)
+	}
+	return 0, nil
+}
+
+func (m *mockDB) GetAnnotations(sessionID int64) ([]db.Annotation, error) {
+	if m.GetAnnotationsFunc != nil {
+		return m.GetAnnotationsFunc(sessionID)
+	}
+	return nil, nil
+}
+
+func (m *mockDB) GetPauses(sessionID int64) ([]db.Pause, error) {
+	if m.GetPausesFunc != nil {
+		return m.GetPausesFunc(sessionID)
+	}
+	return nil, nil
+}
+
 func (m *mockDB) UpdateSessionEndTime(id int64, endTime time.Time) error {
 	if m.UpdateSessionEndTimeFunc != nil {
 		return m.UpdateSessionEndTimeFunc(id, endTime)
@@ -57,6 +163,41 @@ func (m *mockDB) UpdateSessionEndTime(id int64, endTime time.Time) error {
 	return nil
 }
 
+func (m *mockDB) ExtendSession(id int64, by time.Duration) error {
+	if m.ExtendSessionFunc != nil {
+		return m.ExtendSessionFunc(id, by)
+	}
+	return nil
+}
+
+func (m *mockDB) EndSession(id int64, endedAt time.Time, status string) error {
+	if m.EndSessionFunc != nil {
+		return m.EndSessionFunc(id, endedAt, status)
+	}
+	return nil
+}
+
+func (m *mockDB) EditSession(id int64, e db.SessionEdit) error {
+	if m.EditSessionFunc != nil {
+		return m.EditSessionFunc(id, e)
+	}
+	return nil
+}
+
+func (m *mockDB) DeleteSession(id int64) error {
+	if m.DeleteSessionFunc != nil {
+		return m.DeleteSessionFunc(id)
+	}
+	return nil
+}
+
+func (m *mockDB) SetSessionStatus(id int64, status string) error {
+	if m.SetSessionStatusFunc != nil {
+		return m.SetSessionStatusFunc(id, status)
+	}
+	return nil
+}
+
 func (m *mockDB) PauseSession(id int64, pausedAt time.Time) error {
 	if m.PauseSessionFunc != nil {
 		return m.PauseSessionFunc(id, pausedAt)
@@ -64,16 +205,23 @@ func (m *mockDB) PauseSession(id int64, pausedAt time.Time) error {
 	return nil
 }
 
-func (m *mockDB) ResumeSession(id int64, newEndTime time.Time) error {
+func (m *mockDB) ResumeSession(id int64, resumedAt time.Time) error {
 	if m.ResumeSessionFunc != nil {
-		return m.ResumeSessionFunc(id, newEndTime)
+		return m.ResumeSessionFunc(id, resumedAt)
 	}
 	return nil
 }
 
-func (m *mockDB) GetSessionsByDateRange(startDate, endDate time.Time) ([]db.PomodoroSession, error) {
+func (m *mockDB) GetSessionsByDateRange(startDate, endDate time.Time, tags ...string) ([]db.PomodoroSession, error) {
 	if m.GetSessionsByDateRangeFunc != nil {
-		return m.GetSessionsByDateRangeFunc(startDate, endDate)
+		return m.GetSessionsByDateRangeFunc(startDate, endDate, tags...)
+	}
+	return nil, nil
+}
+
+func (m *mockDB) SearchSessions(opts db.SearchOptions) ([]db.PomodoroSession, error) {
+	if m.SearchSessionsFunc != nil {
+		return m.SearchSessionsFunc(opts)
 	}
 	return nil, nil
 }
@@ -85,6 +233,202 @@ func (m *mockDB) GetTodaySessions() ([]db.PomodoroSession, error) {
 	return nil, nil
 }
 
+func (m *mockDB) GetSessionStats(startDate, endDate time.Time) (*db.SessionStats, error) {
+	if m.GetSessionStatsFunc != nil {
+		return m.GetSessionStatsFunc(startDate, endDate)
+	}
+	return &db.SessionStats{}, nil
+}
+
+func (m *mockDB) GetTagStats(startDate, endDate time.Time) ([]db.TagStats, error) {
+	if m.GetTagStatsFunc != nil {
+		return m.GetTagStatsFunc(startDate, endDate)
+	}
+	return nil, nil
+}
+
+func (m *mockDB) GetAllTags() ([]db.TagUse, error) {
+	if m.GetAllTagsFunc != nil {
+		return m.GetAllTagsFunc()
+	}
+	return nil, nil
+}
+
+func (m *mockDB) GetHourlyStats(startDate, endDate time.Time) ([]db.HourStats, error) {
+	if m.GetHourlyStatsFunc != nil {
+		return m.GetHourlyStatsFunc(startDate, endDate)
+	}
+	return nil, nil
+}
+
+func (m *mockDB) GetCycleCount() (int, error) {
+	if m.GetCycleCountFunc != nil {
+		return m.GetCycleCountFunc()
+	}
+	return 0, nil
+}
+
+func (m *mockDB) AddTask(title string, estimate int) (int64, error) {
+	if m.AddTaskFunc != nil {
+		return m.AddTaskFunc(title, estimate)
+	}
+	return 1, nil
+}
+
+func (m *mockDB) GetTask(id int64) (*db.Task, error) {
+	if m.GetTaskFunc != nil {
+		return m.GetTaskFunc(id)
+	}
+	return nil, nil
+}
+
+func (m *mockDB) ListTasks(includeDone bool) ([]db.Task, error) {
+	if m.ListTasksFunc != nil {
+		return m.ListTasksFunc(includeDone)
+	}
+	return nil, nil
+}
+
+func (m *mockDB) CompleteTask(id int64, doneAt time.Time) error {
+	if m.CompleteTaskFunc != nil {
+		return m.CompleteTaskFunc(id, doneAt)
+	}
+	return nil
+}
+
+func (m *mockDB) SetTaskSource(id int64, source string) error {
+	if m.SetTaskSourceFunc != nil {
+		return m.SetTaskSourceFunc(id, source)
+	}
+	return nil
+}
+
+func (m *mockDB) SetSessionTask(sessionID, taskID int64) error {
+	if m.SetSessionTaskFunc != nil {
+		return m.SetSessionTaskFunc(sessionID, taskID)
+	}
+	return nil
+}
+
+func (m *mockDB) GetTaskSessions(taskID int64) ([]db.PomodoroSession, error) {
+	if m.GetTaskSessionsFunc != nil {
+		return m.GetTaskSessionsFunc(taskID)
+	}
+	return nil, nil
+}
+
+func (m *mockDB) GetTaskStats(startDate, endDate time.Time) ([]db.TaskStats, error) {
+	if m.GetTaskStatsFunc != nil {
+		return m.GetTaskStatsFunc(startDate, endDate)
+	}
+	return nil, nil
+}
+
+func (m *mockDB) LogCounter(name string, amount int, at time.Time) error {
+	if m.LogCounterFunc != nil {
+		return m.LogCounterFunc(name, amount, at)
+	}
+	return nil
+}
+
+func (m *mockDB) GetCounterTotals(startDate, endDate time.Time) (map[string]int, error) {
+	if m.GetCounterTotalsFunc != nil {
+		return m.GetCounterTotalsFunc(startDate, endDate)
+	}
+	return map[string]int{}, nil
+}
+
+func (m *mockDB) RecordDailySummary(day time.Time, goal int) (*db.DailySummary, error) {
+	if m.RecordDailySummaryFunc != nil {
+		return m.RecordDailySummaryFunc(day, goal)
+	}
+	return &db.DailySummary{Date: day.Format("2006-01-02")}, nil
+}
+
+func (m *mockDB) GetDailySummaries() ([]db.DailySummary, error) {
+	if m.GetDailySummariesFunc != nil {
+		return m.GetDailySummariesFunc()
+	}
+	return nil, nil
+}
+
+func (m *mockDB) AddVacation(from, to time.Time) (int64, error) {
+	if m.AddVacationFunc != nil {
+		return m.AddVacationFunc(from, to)
+	}
+	return 1, nil
+}
+
+func (m *mockDB) GetVacations() ([]db.Vacation, error) {
+	if m.GetVacationsFunc != nil {
+		return m.GetVacationsFunc()
+	}
+	return nil, nil
+}
+
+func (m *mockDB) DeleteVacation(id int64) error {
+	if m.DeleteVacationFunc != nil {
+		return m.DeleteVacationFunc(id)
+	}
+	return nil
+}
+
+func (m *mockDB) RecordTemplateUse(name string, at time.Time) error {
+	if m.RecordTemplateUseFunc != nil {
+		return m.RecordTemplateUseFunc(name, at)
+	}
+	return nil
+}
+
+func (m *mockDB) GetTemplateUsage() (map[string]db.TemplateUsage, error) {
+	if m.GetTemplateUsageFunc != nil {
+		return m.GetTemplateUsageFunc()
+	}
+	return nil, nil
+}
+
+func (m *mockDB) CreateAPIToken(name, scope string) (string, error) {
+	if m.CreateAPITokenFunc != nil {
+		return m.CreateAPITokenFunc(name, scope)
+	}
+	return "", nil
+}
+
+func (m *mockDB) ListAPITokens(includeRevoked bool) ([]db.APIToken, error) {
+	if m.ListAPITokensFunc != nil {
+		return m.ListAPITokensFunc(includeRevoked)
+	}
+	return nil, nil
+}
+
+func (m *mockDB) RevokeAPIToken(name string) error {
+	if m.RevokeAPITokenFunc != nil {
+		return m.RevokeAPITokenFunc(name)
+	}
+	return nil
+}
+
+func (m *mockDB) AuthenticateAPIToken(secret string, usedAt time.Time) (*db.APIToken, error) {
+	if m.AuthenticateAPITokenFunc != nil {
+		return m.AuthenticateAPITokenFunc(secret, usedAt)
+	}
+	return nil, nil
+}
+
+func (m *mockDB) RecordAPIAudit(entry db.APIAuditEntry) error {
+	if m.RecordAPIAuditFunc != nil {
+		return m.RecordAPIAuditFunc(entry)
+	}
+	return nil
+}
+
+func (m *mockDB) ListAPIAudit(filter db.APIAuditFilter) ([]db.APIAuditEntry, error) {
+	if m.ListAPIAuditFunc != nil {
+		return m.ListAPIAuditFunc(filter)
+	}
+	return nil, nil
+}
+
 func (m *mockDB) Close() error {
 	if m.CloseFunc != nil {
 		return m.CloseFunc()
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/stats"
+)
+
+// minBreaksForNudge is how many breaks must have been expected before a
+// skip rate says anything
+const minBreaksForNudge = 4
+
+// breakAdherence counts the breaks expected and skipped after the completed
+// pomodoros among sessions
+func breakAdherence(database db.DB, sessions []db.PomodoroSession) (stats.BreakAdherence, error) {
+	if len(sessions) == 0 {
+		return stats.BreakAdherence{}, nil
+	}
+	first, last := sessions[0].StartTime, sessions[0].StartTime
+	for _, s := range sessions {
+		if s.StartTime.Before(first) {
+			first = s.StartTime
+		}
+		if s.StartTime.After(last) {
+			last = s.StartTime
+		}
+	}
+	kinds, err := database.GetMetadataByDateRange(db.MetaBreakKind, first, last)
+	if err != nil {
+		return stats.BreakAdherence{}, err
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+	policy := stats.BreakPolicy{
+		Short:        configuredBreakDuration(false),
+		Long:         configuredBreakDuration(true),
+		LongInterval: cfg.Defaults.LongBreakInterval,
+	}
+	return stats.BreakSkips(sessions, kinds, policy), nil
+}
+
+// breakSkipNudge returns a nudge to take breaks when more of them were
+// skipped than wellness.break_skip_threshold allows, or ""
+func breakSkipNudge(a stats.BreakAdherence) string {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+	threshold := cfg.Wellness.BreakSkipThreshold
+	if threshold <= 0 || a.Expected < minBreaksForNudge || a.SkipRate() <= threshold {
+		return ""
+	}
+	return fmt.Sprintf("You skipped %d of %d breaks after pomodoros (%.0f%%). Short breaks keep focus up for the next one; try 'pomodoro break'.",
+		a.Skipped, a.Expected, a.SkipRate()*100)
+}
@@ -7,7 +7,9 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/ethan-k/pomodoro-cli/internal/daemon"
 	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/hooks"
 )
 
 // cancelCmd represents the cancel command
@@ -23,7 +25,7 @@ Example:
 	Aliases: []string{"c"},
 	Run: func(_ *cobra.Command, _ []string) {
 		// Connect to database
-		database, err := db.NewDB()
+		database, err := openDB()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "%v\n", err)
 			os.Exit(1)
@@ -34,8 +36,8 @@ Example:
 			}
 		}()
 
-		// Get active session
-		session, err := database.GetActiveSession()
+		// Get active session, from the daemon when one is running
+		session, err := activeSession(database)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error getting active session: %v\n", err)
 			os.Exit(1)
@@ -46,9 +48,8 @@ Example:
 			return
 		}
 
-		// Update session end time to now
-		now := time.Now()
-		if err := database.UpdateSessionEndTime(session.ID, now); err != nil {
+		now, err := cancelSession(database, session)
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error updating session: %v\n", err)
 			os.Exit(1)
 		}
@@ -57,8 +58,12 @@ Example:
 		actualDuration := now.Sub(session.StartTime).Round(time.Second)
 
 		if jsonOutput {
-			fmt.Printf(`{"id":%d,"description":"%s","status":"cancelled","actual_duration":"%s"}`+"\n",
-				session.ID, session.Description, actualDuration)
+			printJSONLine(struct {
+				ID             int64  `json:"id"`
+				Description    string `json:"description"`
+				Status         string `json:"status"`
+				ActualDuration string `json:"actual_duration"`
+			}{session.ID, session.Description, db.StatusCancelled, actualDuration.String()})
 			return
 		}
 
@@ -69,9 +74,36 @@ Example:
 	},
 }
 
+// cancelSession ends the active session as cancelled now, through the daemon
+// when one is running so it drops the timer, runs the cancel hooks, and
+// returns when the session ended
+func cancelSession(database db.DB, session *db.PomodoroSession) (time.Time, error) {
+	now, err := recordCancel(database, session)
+	if err != nil {
+		return now, err
+	}
+	runHooks(database, hooks.Cancel, session.ID)
+	return now, nil
+}
+
+// recordCancel cancels session as cancelSession does, without running hooks
+func recordCancel(database db.DB, session *db.PomodoroSession) (time.Time, error) {
+	now := app.now()
+	if resp, ok, err := daemonCall(daemon.ActionCancel); ok {
+		if err != nil {
+			return now, err
+		}
+		if resp.Session != nil {
+			now = resp.Session.EndTime
+		}
+	} else if err := database.EndSession(session.ID, now, db.StatusCancelled); err != nil {
+		return now, err
+	}
+	return now, nil
+}
+
 func init() {
 	rootCmd.AddCommand(cancelCmd)
 
 	// Define flags for the cancel command
-	cancelCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format (for non-TTY usage)")
 }
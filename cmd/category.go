@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/stats"
+)
+
+// taxonomy returns the configured session categories
+func taxonomy() stats.Taxonomy {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+	return stats.Taxonomy{
+		Names:    cfg.Categories.Names,
+		Deep:     cfg.Categories.Deep,
+		TagRules: cfg.Categories.Tags,
+	}
+}
+
+// parseCategory normalizes a --category value and checks it is one of the
+// configured categories
+func parseCategory(name string) (string, error) {
+	t := taxonomy()
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !t.Valid(name) {
+		return "", fmt.Errorf("unknown category %q: choose from %s (see categories.names in the config)",
+			name, strings.Join(t.Names, ", "))
+	}
+	return name, nil
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+// completionSessions is how many recent sessions are offered when a
+// session is completed
+const completionSessions = 20
+
+// configKeys are the keys 'pomodoro config' can set, offered on completion
+var configKeys = []string{
+	"goals.daily_count",
+	"goals.weekly_count",
+	"goals.monthly_count",
+	"goals.rest_days",
+	"goals.daily_minutes",
+	"goals.weekly_minutes",
+	"goals.carry_over",
+	"goals.carry_over_since",
+	"hooks.enabled",
+	"hooks.path",
+	"defaults.pomodoro_duration",
+	"defaults.break_duration",
+	"defaults.long_break_duration",
+	"defaults.long_break_interval",
+	"defaults.warmup",
+	"categories.names",
+	"categories.deep",
+	"wellness.counters",
+	"wellness.break_skip_threshold",
+	"wellness.rate_quality",
+	"wellness.force_break",
+	"limits.daily_max_pomodoros",
+	"limits.latest_start_time",
+	"limits.refuse",
+	"idle.on_sleep",
+	"serve.api",
+	"serve.metrics",
+	"serve.base_path",
+	"serve.tls_cert",
+	"serve.tls_key",
+	"serve.cors_origins",
+	"serve.rate_limit",
+	"secrets.backend",
+	"secrets.file",
+	"debug.slow_threshold",
+	"debug.slow_log",
+	"paths.database",
+	"paths.opf_export",
+}
+
+// completeConfigKeys completes the key of 'pomodoro config <key> <value>'
+func completeConfigKeys(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	switch {
+	case len(args) == 0:
+		return slices.Concat(configKeys, []string{"explain"}), cobra.ShellCompDirectiveNoFileComp
+	case len(args) == 1 && args[0] == "explain":
+		return slices.Concat(explainSettings, configKeys), cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// withCompletionDB runs fn on the session database for a completion.
+// Completion must never fail loudly, so a database that cannot be opened
+// simply offers nothing.
+func withCompletionDB(fn func(database db.DB) []string) []string {
+	database, err := openDB()
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = database.Close() }()
+	return fn(database)
+}
+
+// completeTags completes a comma-separated list of tags with the tags
+// used, most recently used first, leaving out those already in the list
+func completeTags(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	done, _ := cutLast(toComplete, ",")
+	given := map[string]bool{}
+	for _, tag := range strings.Split(done, ",") {
+		given[strings.TrimSpace(tag)] = true
+	}
+	prefix := ""
+	if done != "" {
+		prefix = done + ","
+	}
+
+	tags := withCompletionDB(func(database db.DB) []string {
+		used, err := database.GetAllTags()
+		if err != nil {
+			return nil
+		}
+		var tags []string
+		for _, tag := range used {
+			if !given[tag.Name] {
+				given[tag.Name] = true
+				tags = append(tags, prefix+tag.Name)
+			}
+		}
+		return tags
+	})
+	return tags, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveKeepOrder
+}
+
+// cutLast splits s around the last instance of sep, returning "" and s
+// when there is none
+func cutLast(s, sep string) (before, after string) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):]
+	}
+	return "", s
+}
+
+// completeSession completes a session argument with the most recent
+// sessions, described by their start and description
+func completeSession(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	refs := withCompletionDB(func(database db.DB) []string {
+		sessions, err := database.GetRecentSessions(completionSessions, true)
+		if err != nil {
+			return nil
+		}
+		refs := make([]string, 0, len(sessions))
+		for _, s := range sessions {
+			refs = append(refs, fmt.Sprintf("%d\t%s %s", s.ID, inZone(s.StartTime, s, nil).Format("2006-01-02 15:04"), s.Description))
+		}
+		return refs
+	})
+	// Keep the newest first rather than sorted as text
+	return refs, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeTask completes a task ID with the open tasks
+func completeTask(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	ids := withCompletionDB(func(database db.DB) []string {
+		tasks, err := database.ListTasks(false)
+		if err != nil {
+			return nil
+		}
+		ids := make([]string, 0, len(tasks))
+		for _, t := range tasks {
+			ids = append(ids, strconv.FormatInt(t.ID, 10)+"\t"+t.Title)
+		}
+		return ids
+	})
+	return ids, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeCounter completes the counter of 'pomodoro count'
+func completeCounter(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names := make([]string, 0, len(cfg.Wellness.Counters))
+	for _, c := range cfg.Wellness.Counters {
+		names = append(names, c.Name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeCategory completes --category with the configured categories
+func completeCategory(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return cfg.Categories.Names, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func TestCompleteTags(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "history.db")
+	database, err := db.NewDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now().Add(-3 * time.Hour)
+	for i, tags := range []string{"coding,backend", "coding", "writing"} {
+		begin := start.Add(time.Duration(i) * time.Hour)
+		if _, err := database.CreateSession(begin, begin.Add(25*time.Minute), "Work", 25*60, tags, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = database.Close()
+
+	saved := databasePath
+	databasePath = path
+	defer func() { databasePath = saved }()
+
+	for _, tc := range []struct {
+		toComplete string
+		want       []string
+	}{
+		{"", []string{"writing", "coding", "backend"}},
+		{"co", []string{"writing", "coding", "backend"}}, // The shell filters by prefix
+		{"coding,", []string{"coding,writing", "coding,backend"}},
+		{"writing,coding,ba", []string{"writing,coding,backend"}},
+	} {
+		got, _ := completeTags(nil, nil, tc.toComplete)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("completeTags(%q) = %v, want %v", tc.toComplete, got, tc.want)
+		}
+	}
+}
@@ -1,13 +1,20 @@
 package cmd
 
 import (
+	"cmp"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/goals"
+	"github.com/ethan-k/pomodoro-cli/internal/secrets"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
 )
 
 var (
@@ -24,12 +31,21 @@ var configCmd = &cobra.Command{
 	Long: `Manage pomodoro configuration.
 
 You can initialize the config file, list all settings, or set individual values.
+'config explain <key>' shows where a setting comes from: for duration, tags,
+and audio, the chain start resolves them by (flags first, as given to start);
+for a config key, the config file or the built-in default.
 
 Examples:
   pomodoro config --init
   pomodoro config --list
   pomodoro config goals.daily_count 10
-  pomodoro config defaults.pomodoro_duration 30m`,
+  pomodoro config defaults.pomodoro_duration 30m
+  pomodoro config categories.names deep,shallow,admin,learning
+  pomodoro config wellness.counters water:8,stretch:4,posture
+  pomodoro config limits.daily_max_pomodoros 10
+  pomodoro config explain duration
+  pomodoro config explain goals.daily_count`,
+	ValidArgsFunction: completeConfigKeys,
 	Run: func(_ *cobra.Command, args []string) {
 		// Initialize config file
 		if configInit {
@@ -49,6 +65,15 @@ Examples:
 			os.Exit(1)
 		}
 
+		if len(args) > 0 && args[0] == "explain" {
+			if len(args) != 2 {
+				fmt.Fprintf(os.Stderr, "Usage: pomodoro config explain <key>, where key is %s, or a config key\n", strings.Join(explainSettings, ", "))
+				os.Exit(1)
+			}
+			explainSetting(cfg, args[1])
+			return
+		}
+
 		// List all settings
 		if configList || (configKey == "" && configValue == "" && len(args) == 0) {
 			fmt.Println("Current Configuration:")
@@ -56,6 +81,32 @@ Examples:
 			fmt.Println("Goals:")
 			fmt.Printf("  Daily count: %d pomodoros\n", cfg.Goals.DailyCount)
 			fmt.Printf("  Weekly count: %d pomodoros\n", cfg.Goals.WeeklyCount)
+			if cfg.Goals.MonthlyCount > 0 {
+				fmt.Printf("  Monthly count: %d pomodoros\n", cfg.Goals.MonthlyCount)
+			} else {
+				fmt.Printf("  Monthly count: %d pomodoros this month, from the weekly count\n", cfg.Goals.MonthlyGoal(app.now()))
+			}
+			fmt.Printf("  Daily minutes: %d\n", cfg.Goals.DailyMinutes)
+			fmt.Printf("  Weekly minutes: %d\n", cfg.Goals.WeeklyMinutes)
+			fmt.Printf("  Carry over: %v", cfg.Goals.CarryOver)
+			if cfg.Goals.CarryOver {
+				fmt.Printf(" (since %s)", cfg.Goals.CarryOverSince)
+			}
+			fmt.Println()
+			if len(cfg.Goals.RestDays) > 0 {
+				fmt.Printf("  Rest days: %s\n", strings.Join(cfg.Goals.RestDays, ", "))
+			}
+			if len(cfg.Goals.Tags) > 0 {
+				tags := make([]string, 0, len(cfg.Goals.Tags))
+				for tag := range cfg.Goals.Tags {
+					tags = append(tags, tag)
+				}
+				sort.Strings(tags)
+				for _, tag := range tags {
+					goal := cfg.Goals.Tags[tag]
+					fmt.Printf("  Tag %s: %d daily, %d weekly\n", tag, goal.Daily, goal.Weekly)
+				}
+			}
 			fmt.Println("Hooks:")
 			fmt.Printf("  Enabled: %v\n", cfg.Hooks.Enabled)
 			fmt.Printf("  Path: %s\n", cfg.Hooks.Path)
@@ -63,6 +114,77 @@ Examples:
 			fmt.Printf("  Pomodoro duration: %s\n", cfg.Defaults.PomodoroDuration)
 			fmt.Printf("  Break duration: %s\n", cfg.Defaults.BreakDuration)
 			fmt.Printf("  Long break duration: %s\n", cfg.Defaults.LongBreakDuration)
+			fmt.Printf("  Long break every: %d pomodoros\n", cfg.Defaults.LongBreakInterval)
+			if warmup := configuredWarmup(cfg); warmup > 0 {
+				fmt.Printf("  Warm-up: %s\n", warmup)
+			} else {
+				fmt.Println("  Warm-up: none")
+			}
+			if len(cfg.Defaults.TagDurations) > 0 {
+				tags := make([]string, 0, len(cfg.Defaults.TagDurations))
+				for tag := range cfg.Defaults.TagDurations {
+					tags = append(tags, tag)
+				}
+				sort.Strings(tags)
+				for _, tag := range tags {
+					fmt.Printf("  Tag %s: %s\n", tag, cfg.Defaults.TagDurations[tag])
+				}
+			}
+			fmt.Println("Categories:")
+			fmt.Printf("  Names: %s\n", strings.Join(cfg.Categories.Names, ", "))
+			fmt.Printf("  Deep work: %s\n", strings.Join(cfg.Categories.Deep, ", "))
+			if len(cfg.Categories.Tags) > 0 {
+				tags := make([]string, 0, len(cfg.Categories.Tags))
+				for tag := range cfg.Categories.Tags {
+					tags = append(tags, tag)
+				}
+				sort.Strings(tags)
+				for _, tag := range tags {
+					fmt.Printf("  Tag %s: %s\n", tag, cfg.Categories.Tags[tag])
+				}
+			}
+			fmt.Println("Wellness:")
+			fmt.Printf("  Counters: %s\n", formatCounters(cfg.Wellness.Counters))
+			fmt.Printf("  Break skip threshold: %.0f%%\n", cfg.Wellness.BreakSkipThreshold*100)
+			fmt.Printf("  Rate quality: %v\n", cfg.Wellness.RateQuality)
+			fmt.Printf("  Force break: %s\n", cmp.Or(cfg.Wellness.ForceBreak, "off"))
+			fmt.Println("Limits:")
+			fmt.Printf("  Daily max pomodoros: %d\n", cfg.Limits.DailyMaxPomodoros)
+			fmt.Printf("  Latest start time: %s\n", cfg.Limits.LatestStartTime)
+			fmt.Printf("  Refuse: %v\n", cfg.Limits.Refuse)
+			fmt.Println("Nudge:")
+			fmt.Printf("  Enabled: %v\n", cfg.Nudge.Enabled)
+			fmt.Printf("  At: %s\n", cfg.Nudge.At)
+			fmt.Printf("  Days: %s\n", strings.Join(cfg.Nudge.Days, ", "))
+			fmt.Println("Location:")
+			fmt.Printf("  Record: %v\n", cfg.Location.Record)
+			if len(cfg.Location.Networks) > 0 {
+				networks := make([]string, 0, len(cfg.Location.Networks))
+				for network := range cfg.Location.Networks {
+					networks = append(networks, network)
+				}
+				sort.Strings(networks)
+				for _, network := range networks {
+					fmt.Printf("  Network %s: %s\n", network, cfg.Location.Networks[network])
+				}
+			}
+			fmt.Printf("  Unlabelled: %v\n", cfg.Location.Unlabelled)
+			fmt.Println("Idle:")
+			fmt.Printf("  On sleep: %s\n", cfg.Idle.OnSleep)
+			fmt.Println("Serve:")
+			fmt.Printf("  API: %s\n", cfg.Serve.API)
+			fmt.Printf("  Metrics: %s\n", cfg.Serve.Metrics)
+			fmt.Printf("  Base path: %s\n", cfg.Serve.BasePath)
+			fmt.Printf("  TLS certificate: %s\n", cfg.Serve.TLSCert)
+			fmt.Printf("  TLS key: %s\n", cfg.Serve.TLSKey)
+			fmt.Printf("  CORS origins: %s\n", strings.Join(cfg.Serve.CORSOrigins, ", "))
+			fmt.Printf("  Rate limit: %d requests a minute\n", cfg.Serve.RateLimit)
+			fmt.Println("Secrets:")
+			fmt.Printf("  Backend: %s\n", cfg.Secrets.Backend)
+			fmt.Printf("  File: %s\n", cfg.Secrets.File)
+			fmt.Println("Debug:")
+			fmt.Printf("  Slow threshold: %s\n", cfg.Debug.SlowThreshold)
+			fmt.Printf("  Slow log: %s\n", cfg.Debug.SlowLog)
 			fmt.Println("Paths:")
 			fmt.Printf("  Database: %s\n", cfg.DataPaths.Database)
 			fmt.Printf("  OPF export: %s\n", cfg.DataPaths.OPFExport)
@@ -91,6 +213,48 @@ Examples:
 					os.Exit(1)
 				}
 				cfg.Goals.WeeklyCount = count
+			case "goals.monthly_count":
+				count, err := strconv.Atoi(configValue)
+				if err != nil || count < 0 {
+					fmt.Fprintf(os.Stderr, "Invalid value for monthly count: must be a whole number, 0 to follow the weekly count\n")
+					os.Exit(1)
+				}
+				cfg.Goals.MonthlyCount = count
+			case "goals.daily_minutes", "goals.weekly_minutes":
+				minutes, err := strconv.Atoi(configValue)
+				if err != nil || minutes < 0 {
+					fmt.Fprintf(os.Stderr, "Invalid value for %s: must be a whole number of minutes, 0 for no goal\n", configKey)
+					os.Exit(1)
+				}
+				if configKey == "goals.daily_minutes" {
+					cfg.Goals.DailyMinutes = minutes
+				} else {
+					cfg.Goals.WeeklyMinutes = minutes
+				}
+			case "goals.carry_over":
+				enabled, err := strconv.ParseBool(configValue)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Invalid value for carry over: %v\n", err)
+					os.Exit(1)
+				}
+				// Start counting from this week when carry-over is turned on
+				if enabled && !cfg.Goals.CarryOver {
+					cfg.Goals.CarryOverSince = goals.WeekStart(app.now()).Format("2006-01-02")
+				}
+				cfg.Goals.CarryOver = enabled
+			case "goals.rest_days":
+				days := splitList(configValue)
+				if _, err := utils.ParseWeekdays(days); err != nil {
+					fmt.Fprintf(os.Stderr, "Invalid value for rest days: %v\n", err)
+					os.Exit(1)
+				}
+				cfg.Goals.RestDays = days
+			case "goals.carry_over_since":
+				if _, err := time.ParseInLocation("2006-01-02", configValue, time.Local); err != nil {
+					fmt.Fprintf(os.Stderr, "Invalid value for carry over since: must be a date (YYYY-MM-DD)\n")
+					os.Exit(1)
+				}
+				cfg.Goals.CarryOverSince = configValue
 			case "hooks.enabled":
 				enabled, err := strconv.ParseBool(configValue)
 				if err != nil {
@@ -106,6 +270,164 @@ Examples:
 				cfg.Defaults.BreakDuration = configValue
 			case "defaults.long_break_duration":
 				cfg.Defaults.LongBreakDuration = configValue
+			case "defaults.long_break_interval":
+				interval, err := strconv.Atoi(configValue)
+				if err != nil || interval < 0 {
+					fmt.Fprintf(os.Stderr, "Invalid value for long break interval: must be a whole number, 0 to turn long breaks off\n")
+					os.Exit(1)
+				}
+				cfg.Defaults.LongBreakInterval = interval
+			case "defaults.warmup":
+				if _, err := utils.ParseHumanDuration(configValue); err != nil {
+					fmt.Fprintf(os.Stderr, "Invalid value for warm-up: %v\n", err)
+					os.Exit(1)
+				}
+				cfg.Defaults.Warmup = configValue
+			case "categories.names":
+				cfg.Categories.Names = splitList(configValue)
+			case "categories.deep":
+				cfg.Categories.Deep = splitList(configValue)
+			case "wellness.counters":
+				counters, err := parseCounters(configValue)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Invalid value for wellness counters: %v\n", err)
+					os.Exit(1)
+				}
+				cfg.Wellness.Counters = counters
+			case "wellness.break_skip_threshold":
+				threshold, err := strconv.ParseFloat(configValue, 64)
+				if err != nil || threshold < 0 || threshold > 1 {
+					fmt.Fprintf(os.Stderr, "Invalid value for break skip threshold: must be a share between 0 and 1, 0 to turn the nudge off\n")
+					os.Exit(1)
+				}
+				cfg.Wellness.BreakSkipThreshold = threshold
+			case "wellness.rate_quality":
+				rate, err := strconv.ParseBool(configValue)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Invalid value for rate quality: %v\n", err)
+					os.Exit(1)
+				}
+				cfg.Wellness.RateQuality = rate
+			case "wellness.force_break":
+				if configValue == "off" {
+					configValue = ""
+				}
+				if _, ok := forceBreakActions[configValue]; !ok && configValue != "" {
+					fmt.Fprintf(os.Stderr, "Invalid value for force break: must be lock, dim, or off\n")
+					os.Exit(1)
+				}
+				cfg.Wellness.ForceBreak = configValue
+			case "limits.daily_max_pomodoros":
+				limit, err := strconv.Atoi(configValue)
+				if err != nil || limit < 0 {
+					fmt.Fprintf(os.Stderr, "Invalid value for daily max pomodoros: must be a whole number, 0 for no limit\n")
+					os.Exit(1)
+				}
+				cfg.Limits.DailyMaxPomodoros = limit
+			case "limits.latest_start_time":
+				if configValue != "" {
+					if _, err := goals.ParseTimeOfDay(configValue); err != nil {
+						fmt.Fprintf(os.Stderr, "Invalid value for latest start time: %v\n", err)
+						os.Exit(1)
+					}
+				}
+				cfg.Limits.LatestStartTime = configValue
+			case "limits.refuse":
+				refuse, err := strconv.ParseBool(configValue)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Invalid value for limits refuse: %v\n", err)
+					os.Exit(1)
+				}
+				cfg.Limits.Refuse = refuse
+			case "nudge.enabled":
+				enabled, err := strconv.ParseBool(configValue)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Invalid value for nudge enabled: %v\n", err)
+					os.Exit(1)
+				}
+				cfg.Nudge.Enabled = enabled
+			case "nudge.at":
+				if _, err := goals.ParseTimeOfDay(configValue); err != nil {
+					fmt.Fprintf(os.Stderr, "Invalid value for nudge at: %v\n", err)
+					os.Exit(1)
+				}
+				cfg.Nudge.At = configValue
+			case "nudge.days":
+				days := splitList(configValue)
+				if _, err := utils.ParseWeekdays(days); err != nil {
+					fmt.Fprintf(os.Stderr, "Invalid value for nudge days: %v\n", err)
+					os.Exit(1)
+				}
+				cfg.Nudge.Days = days
+			case "location.record":
+				record, err := strconv.ParseBool(configValue)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Invalid value for location record: %v\n", err)
+					os.Exit(1)
+				}
+				cfg.Location.Record = record
+			case "location.unlabelled":
+				unlabelled, err := strconv.ParseBool(configValue)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Invalid value for location unlabelled: %v\n", err)
+					os.Exit(1)
+				}
+				cfg.Location.Unlabelled = unlabelled
+			case "idle.on_sleep":
+				if _, ok := sleepActions[configValue]; !ok {
+					fmt.Fprintf(os.Stderr, "Invalid value for on sleep: must be pause, interrupt, or ignore\n")
+					os.Exit(1)
+				}
+				cfg.Idle.OnSleep = configValue
+			case "serve.api":
+				cfg.Serve.API = configValue
+			case "serve.metrics":
+				cfg.Serve.Metrics = configValue
+			case "serve.base_path":
+				base, err := normalizeBasePath(configValue)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Invalid value for base path: %v\n", err)
+					os.Exit(1)
+				}
+				cfg.Serve.BasePath = base
+			case "serve.tls_cert":
+				cfg.Serve.TLSCert = configValue
+			case "serve.tls_key":
+				cfg.Serve.TLSKey = configValue
+			case "serve.cors_origins":
+				origins := splitList(configValue)
+				for _, origin := range origins {
+					if err := validateOrigin(origin); err != nil {
+						fmt.Fprintf(os.Stderr, "Invalid value for CORS origins: %v\n", err)
+						os.Exit(1)
+					}
+				}
+				cfg.Serve.CORSOrigins = origins
+			case "serve.rate_limit":
+				limit, err := strconv.Atoi(configValue)
+				if err != nil || limit < 0 {
+					fmt.Fprintf(os.Stderr, "Invalid value for rate limit: must be a whole number of requests a minute, 0 for no limit\n")
+					os.Exit(1)
+				}
+				cfg.Serve.RateLimit = limit
+			case "secrets.backend":
+				if !secrets.ValidBackend(configValue) {
+					fmt.Fprintf(os.Stderr, "Invalid value for secrets backend: must be auto, keyring, or file\n")
+					os.Exit(1)
+				}
+				cfg.Secrets.Backend = configValue
+			case "secrets.file":
+				cfg.Secrets.File = configValue
+			case "debug.slow_threshold":
+				if configValue != "" {
+					if threshold, err := time.ParseDuration(configValue); err != nil || threshold <= 0 {
+						fmt.Fprintf(os.Stderr, "Invalid value for slow threshold: must be a duration such as 200ms, or empty to stop logging\n")
+						os.Exit(1)
+					}
+				}
+				cfg.Debug.SlowThreshold = configValue
+			case "debug.slow_log":
+				cfg.Debug.SlowLog = configValue
 			case "paths.database":
 				cfg.DataPaths.Database = configValue
 			case "paths.opf_export":
@@ -124,6 +446,82 @@ Examples:
 	},
 }
 
+// explainSettings are the settings start resolves from several sources
+var explainSettings = []string{"duration", "tags", "audio"}
+
+// explainSetting prints how a setting or config key is resolved
+func explainSetting(cfg *config.Config, key string) {
+	switch key {
+	case "duration":
+		_, r := resolveDuration(nil, nil, cfg)
+		r.print(os.Stdout)
+	case "tags":
+		resolveTags(nil).print(os.Stdout)
+	case "audio":
+		resolveAudio(false, cfg).print(os.Stdout)
+	default:
+		r, err := resolveConfigKey(key)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		r.print(os.Stdout)
+		return
+	}
+	decorf("\nFlags given to start come first; 'pomodoro start --explain' shows them.\n")
+}
+
+// splitList parses a comma-separated config value, lowercasing each item
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseCounters parses counters given as name[:goal], comma-separated
+func parseCounters(value string) ([]config.CounterConfig, error) {
+	counters := []config.CounterConfig{}
+	seen := map[string]bool{}
+	for _, item := range splitList(value) {
+		name, goalText, hasGoal := strings.Cut(item, ":")
+		name = strings.TrimSpace(name)
+		if name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("invalid counter name %q", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("counter %q is listed twice", name)
+		}
+		seen[name] = true
+
+		goal := 0
+		if hasGoal {
+			g, err := strconv.Atoi(strings.TrimSpace(goalText))
+			if err != nil || g < 0 {
+				return nil, fmt.Errorf("invalid goal for %s: must be a whole number, 0 for none", name)
+			}
+			goal = g
+		}
+		counters = append(counters, config.CounterConfig{Name: name, Goal: goal})
+	}
+	return counters, nil
+}
+
+// formatCounters lists counters as name[:goal], the way they are set
+func formatCounters(counters []config.CounterConfig) string {
+	items := make([]string, len(counters))
+	for i, c := range counters {
+		items[i] = c.Name
+		if c.Goal > 0 {
+			items[i] += fmt.Sprintf(":%d", c.Goal)
+		}
+	}
+	return strings.Join(items, ",")
+}
+
 func init() {
 	rootCmd.AddCommand(configCmd)
 
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+var countUndo bool
+
+// countCmd logs and shows wellness counters
+var countCmd = &cobra.Command{
+	Use:   "count [counter] [n]",
+	Short: "Counts habits like glasses of water or stretch breaks",
+	Long: `Keeps simple daily tallies next to your sessions, such as glasses of water
+or stretch breaks. Each call adds one (or n) to a counter for today; without
+arguments, today's counts are shown against their daily goals.
+
+Counters are set in the config under wellness.counters, water and stretch by
+default. During a break started with --wait, keys 1-9 count them too. Today's
+counts also show in 'pomodoro goals'.
+
+Example:
+  pomodoro count water
+  pomodoro count stretch 2
+  pomodoro count water --undo
+  pomodoro config wellness.counters water:8,stretch:4,posture
+  pomodoro count`,
+	Args:              cobra.MaximumNArgs(2),
+	ValidArgsFunction: completeCounter,
+	Run: func(_ *cobra.Command, args []string) {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		amount := 1
+		if len(args) == 2 {
+			amount, err = strconv.Atoi(args[1])
+			if err != nil || amount < 1 {
+				fmt.Fprintf(os.Stderr, "Invalid count %q: must be a whole number of at least 1\n", args[1])
+				os.Exit(1)
+			}
+		}
+		if len(args) > 0 {
+			if _, ok := cfg.Wellness.Counter(args[0]); !ok {
+				fmt.Fprintf(os.Stderr, "Unknown counter %q (configured: %s)\n", args[0], counterNames(cfg))
+				os.Exit(1)
+			}
+		} else if countUndo {
+			fmt.Fprintln(os.Stderr, "--undo needs the counter to take a count off")
+			os.Exit(1)
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		now := app.now()
+		if len(args) > 0 {
+			if countUndo {
+				amount = -amount
+			}
+			if _, err := logCounter(database, args[0], amount, now); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		counters, err := todayCounters(database, cfg, now)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			printJSON(counters)
+			return
+		}
+
+		if len(args) > 0 {
+			for _, c := range counters {
+				if c.Name == args[0] {
+					fmt.Printf("%s%s today\n", icon("✔️"), c)
+				}
+			}
+			return
+		}
+		if len(counters) == 0 {
+			fmt.Println("No counters configured; set wellness.counters in the config.")
+			return
+		}
+		fmt.Println("Today:")
+		printCounters(counters)
+	},
+}
+
+// counterStatus is a wellness counter's count for today
+type counterStatus struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+	Goal  int    `json:"goal,omitempty"`
+}
+
+func (c counterStatus) String() string {
+	if c.Goal > 0 {
+		return fmt.Sprintf("%s %d/%d", c.Name, c.Count, c.Goal)
+	}
+	return fmt.Sprintf("%s %d", c.Name, c.Count)
+}
+
+// logCounter adds amount to a counter for the day of now and returns its
+// new total. Taking off more than was counted stops at zero.
+func logCounter(database db.DB, name string, amount int, now time.Time) (int, error) {
+	totals, err := database.GetCounterTotals(now, now)
+	if err != nil {
+		return 0, err
+	}
+	amount = max(amount, -totals[name])
+	if amount == 0 {
+		return 0, nil
+	}
+	if err := database.LogCounter(name, amount, now); err != nil {
+		return 0, err
+	}
+	return totals[name] + amount, nil
+}
+
+// todayCounters returns the configured counters' counts for the day of now,
+// in config order
+func todayCounters(database db.DB, cfg *config.Config, now time.Time) ([]counterStatus, error) {
+	totals, err := database.GetCounterTotals(now, now)
+	if err != nil {
+		return nil, err
+	}
+	counters := make([]counterStatus, 0, len(cfg.Wellness.Counters))
+	for _, c := range cfg.Wellness.Counters {
+		counters = append(counters, counterStatus{Name: c.Name, Count: totals[c.Name], Goal: c.Goal})
+	}
+	return counters, nil
+}
+
+// printCounters prints counters one per line, with a bar toward each goal
+func printCounters(counters []counterStatus) {
+	width := 0
+	for _, c := range counters {
+		width = max(width, len(c.Name))
+	}
+	for _, c := range counters {
+		if c.Goal > 0 {
+			fmt.Printf("  %-*s  %3d/%-3d %s\n", width, c.Name, c.Count, c.Goal, goalBar(c.Count, c.Goal))
+		} else {
+			fmt.Printf("  %-*s  %3d\n", width, c.Name, c.Count)
+		}
+	}
+}
+
+// counterNames lists the configured counters for messages
+func counterNames(cfg *config.Config) string {
+	names := make([]string, len(cfg.Wellness.Counters))
+	for i, c := range cfg.Wellness.Counters {
+		names[i] = c.Name
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
+
+func init() {
+	rootCmd.AddCommand(countCmd)
+	countCmd.Flags().BoolVar(&countUndo, "undo", false, "Take the count off instead of adding it")
+}
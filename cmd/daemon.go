@@ -0,0 +1,512 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/daemon"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/hooks"
+	"github.com/ethan-k/pomodoro-cli/internal/idle"
+	"github.com/ethan-k/pomodoro-cli/internal/output"
+	"github.com/ethan-k/pomodoro-cli/internal/plan"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+var (
+	daemonLogFile      string
+	daemonExitWhenIdle time.Duration
+)
+
+// daemonCmd represents the daemon command
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Runs timers in the background",
+	Long: `Manages the background daemon that completes sessions and sends
+notifications even when no terminal is waiting on the timer.
+
+Sessions started without waiting start a daemon automatically when none is
+running (see daemon.auto_start). While a daemon runs, status, pause, resume,
+cancel, and extend go through it, as do the keys of a running timer.
+
+Use 'pomodoro daemon install' to start the daemon at login with launchd
+(macOS), a systemd user unit (Linux), or a scheduled task (Windows).
+
+Example:
+  pomodoro daemon install
+  pomodoro daemon status
+  pomodoro daemon events
+  pomodoro daemon uninstall
+  pomodoro daemon run --log-file -`,
+}
+
+// daemonRunCmd runs the daemon in the foreground
+var daemonRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Runs the daemon in the foreground",
+	Long: `Runs the daemon in the foreground until interrupted.
+
+This is what the installed service runs. Use --log-file - to log to stderr.
+Only one daemon runs at a time; it listens for commands on a local socket.`,
+	Run: func(_ *cobra.Command, _ []string) {
+		logOut, closeLog, err := openDaemonLog(resolveDaemonLogFile())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer closeLog()
+
+		database, err := openInternalDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		listener, err := daemon.Listen(daemonSocket())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		logger := log.New(logOut, "", log.LstdFlags)
+		d := daemon.New(database, func(session *db.PomodoroSession) {
+			notifySessionComplete(session, logger)
+		}, logger)
+
+		// Status polls are answered from memory until the database changes
+		changes, err := database.WatchChanges()
+		if err != nil {
+			logger.Printf("not caching status: %v", err)
+		} else {
+			defer func() { _ = changes.Close() }()
+		}
+
+		if cfg, err := config.LoadConfig(); err == nil {
+			applyDaemonConfig(d, cfg, changes, logger)
+		}
+		if daemonExitWhenIdle > 0 {
+			d.ExitWhenIdle(daemonExitWhenIdle)
+		}
+
+		go d.Serve(ctx, listener)
+		go watchDaemonConfig(ctx, d, changes, logger)
+		defer func() { _ = listener.Close() }()
+
+		if err := d.Run(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// daemonEventsCmd streams events from the running daemon
+var daemonEventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Streams events from the running daemon",
+	Long: `Prints events from the running daemon as they happen, one per line,
+until interrupted: config reloads (and config files that failed to load) and
+completed sessions.
+
+Example:
+  pomodoro daemon events
+  pomodoro daemon events --json | jq .type`,
+	Run: func(_ *cobra.Command, _ []string) {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		err := daemon.Subscribe(ctx, daemonSocket(), func(event daemon.Event) {
+			if jsonOutput {
+				if err := output.JSONLine(event); err != nil {
+					fmt.Fprintf(os.Stderr, "Error marshaling event: %v\n", err)
+				}
+				return
+			}
+			fmt.Println(formatDaemonEvent(event))
+		})
+		if errors.Is(err, daemon.ErrNotRunning) {
+			fmt.Fprintln(os.Stderr, "The daemon is not running.")
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// formatDaemonEvent describes an event for the events command
+func formatDaemonEvent(event daemon.Event) string {
+	at := event.Time.Local().Format("15:04:05")
+	switch event.Type {
+	case daemon.EventConfigReloaded:
+		return fmt.Sprintf("%s config reloaded", at)
+	case daemon.EventConfigError:
+		return fmt.Sprintf("%s config not reloaded: %s", at, event.Error)
+	case daemon.EventSessionCompleted:
+		return fmt.Sprintf("%s session %d completed", at, event.SessionID)
+	case daemon.EventSessionAutoPaused:
+		return fmt.Sprintf("%s session %d paused while idle", at, event.SessionID)
+	case daemon.EventSessionAutoResumed:
+		return fmt.Sprintf("%s session %d resumed on activity", at, event.SessionID)
+	case daemon.EventActivityReturned:
+		return fmt.Sprintf("%s activity returned, session %d still paused", at, event.SessionID)
+	case daemon.EventExportCompleted:
+		return fmt.Sprintf("%s scheduled export written", at)
+	case daemon.EventExportFailed:
+		return fmt.Sprintf("%s scheduled export failed: %s", at, event.Error)
+	case daemon.EventNudged:
+		return fmt.Sprintf("%s nudged to start a pomodoro", at)
+	}
+	return fmt.Sprintf("%s %s", at, event.Type)
+}
+
+// daemonInstallCmd installs the daemon as a login service
+var daemonInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Starts the daemon now and at login",
+	Run: func(_ *cobra.Command, _ []string) {
+		if daemonLogFile == "-" {
+			fmt.Fprintln(os.Stderr, "--log-file - is only supported by 'pomodoro daemon run'")
+			os.Exit(1)
+		}
+
+		service, err := daemon.NewService(resolveDaemonLogFile())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if err := service.Install(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error installing daemon: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println("Daemon installed and started.")
+		fmt.Printf("Logs: %s\n", service.LogFile)
+	},
+}
+
+// daemonUninstallCmd removes the daemon login service
+var daemonUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Stops the daemon and removes it from login",
+	Run: func(_ *cobra.Command, _ []string) {
+		service, err := daemon.NewService(resolveDaemonLogFile())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if err := service.Uninstall(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error uninstalling daemon: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println("Daemon uninstalled.")
+	},
+}
+
+// daemonStatusCmd reports the daemon service state
+var daemonStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Shows whether the daemon is installed and running",
+	Run: func(_ *cobra.Command, _ []string) {
+		service, err := daemon.NewService(resolveDaemonLogFile())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		status, err := service.Status()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting daemon status: %v\n", err)
+			os.Exit(1)
+		}
+
+		// The service may be stopped while a daemon started on demand runs
+		pid := 0
+		if resp, ok, _ := daemonCall(daemon.ActionStatus); ok && resp != nil {
+			pid = resp.PID
+		}
+
+		if jsonOutput {
+			printJSONLine(struct {
+				Installed  bool   `json:"installed"`
+				Running    bool   `json:"running"`
+				Location   string `json:"location"`
+				LogFile    string `json:"log_file"`
+				Responding bool   `json:"responding"`
+				PID        int    `json:"pid"`
+			}{status.Installed, status.Running, status.Location, status.LogFile, pid != 0, pid})
+			return
+		}
+
+		fmt.Printf("Installed: %s\n", yesNo(status.Installed))
+		fmt.Printf("Running:   %s\n", yesNo(status.Running))
+		if pid != 0 {
+			fmt.Printf("Daemon:    responding (pid %d)\n", pid)
+		} else {
+			fmt.Println("Daemon:    not responding")
+		}
+		fmt.Printf("Service:   %s\n", status.Location)
+		fmt.Printf("Logs:      %s\n", status.LogFile)
+		if !status.Installed {
+			decorf("\nRun 'pomodoro daemon install' to start the daemon at login.\n")
+		}
+	},
+}
+
+// applyDaemonConfig applies the settings the daemon holds on to: screen lock
+// breaks when idle.lock_break is set, pausing idle pomodoros when
+// idle.auto_pause is, caching status while daemon.cache_status is and
+// changes can tell when the database changed, the exports scheduled in
+// exports, and the nudge to start a pomodoro. Notification and goal
+// settings are read from the config each time they are used.
+func applyDaemonConfig(d *daemon.Daemon, cfg *config.Config, changes *db.ChangeWatcher, logger *log.Logger) {
+	applyAutoPause(d, cfg, logger)
+	applyExports(d, cfg, logger)
+	applyNudge(d, cfg, logger)
+
+	if cfg.Daemon.CacheStatus && changes != nil {
+		d.EnableStatusCache(changes)
+	} else {
+		d.EnableStatusCache(nil)
+	}
+
+	if !cfg.Idle.LockBreak {
+		d.EnableLockBreaks(nil, 0)
+		return
+	}
+
+	after, err := time.ParseDuration(cfg.Idle.LockBreakAfter)
+	if err != nil || after <= 0 {
+		logger.Printf("invalid idle.lock_break_after %q, using 5m", cfg.Idle.LockBreakAfter)
+		after = 5 * time.Minute
+	}
+
+	d.EnableLockBreaks(idle.NewLockDetector(), after)
+	logger.Printf("recording screen locks longer than %s as breaks", after)
+}
+
+// applyAutoPause turns auto-pause on or off from the idle config
+func applyAutoPause(d *daemon.Daemon, cfg *config.Config, logger *log.Logger) {
+	if !cfg.Idle.AutoPause {
+		d.EnableAutoPause(nil, 0, false, nil)
+		return
+	}
+
+	after, err := time.ParseDuration(cfg.Idle.AutoPauseAfter)
+	if err != nil || after <= 0 {
+		logger.Printf("invalid idle.auto_pause_after %q, using 5m", cfg.Idle.AutoPauseAfter)
+		after = 5 * time.Minute
+	}
+
+	d.EnableAutoPause(idle.NewActivityDetector(), after, cfg.Idle.AutoResume, func(session *db.PomodoroSession, event string) {
+		notifyIdle(session, event, logger)
+	})
+	logger.Printf("pausing pomodoros after %s without input", after)
+}
+
+// notifyIdle tells the user a pomodoro was paused while they were away, or
+// what became of it on their return, and runs the pause and resume hooks
+func notifyIdle(session *db.PomodoroSession, event string, logger *log.Logger) {
+	var title, message, hook string
+	switch event {
+	case daemon.EventSessionAutoPaused:
+		title, message, hook = "Pomodoro Paused", fmt.Sprintf("You seem to be away, so %q was paused.", session.Description), hooks.Pause
+	case daemon.EventSessionAutoResumed:
+		title, message, hook = "Welcome Back", fmt.Sprintf("Resumed %q.", session.Description), hooks.Resume
+	case daemon.EventActivityReturned:
+		title, message = "Welcome Back", fmt.Sprintf("%q is paused. Run 'pomodoro resume' to continue.", session.Description)
+	default:
+		return
+	}
+	if err := app.notify(title, message); err != nil {
+		logger.Printf("error sending notification: %v", err)
+	}
+
+	if cfg, err := config.LoadConfig(); err == nil && hook != "" {
+		if err := runHooksFor(cfg, hook, session); err != nil {
+			logger.Printf("%v", err)
+		}
+	}
+}
+
+// watchDaemonConfig reapplies the config whenever the file changes and tells
+// clients streaming events about it
+func watchDaemonConfig(ctx context.Context, d *daemon.Daemon, changes *db.ChangeWatcher, logger *log.Logger) {
+	err := config.Watch(ctx, func(cfg *config.Config, err error) {
+		if err != nil {
+			logger.Printf("config not reloaded: %v", err)
+			d.Publish(daemon.Event{Type: daemon.EventConfigError, Error: err.Error()})
+			return
+		}
+		applyDaemonConfig(d, cfg, changes, logger)
+		logger.Printf("config reloaded")
+		d.Publish(daemon.Event{Type: daemon.EventConfigReloaded})
+	})
+	if err != nil {
+		logger.Printf("not watching config: %v", err)
+	}
+}
+
+// resolveDaemonLogFile returns the log file from --log-file, the config, or the platform default
+func resolveDaemonLogFile() string {
+	if daemonLogFile != "" {
+		return daemonLogFile
+	}
+	if cfg, err := config.LoadConfig(); err == nil && cfg.Daemon.LogFile != "" {
+		return utils.ExpandPath(cfg.Daemon.LogFile)
+	}
+	return daemon.DefaultLogFile()
+}
+
+// openDaemonLog opens the daemon log for appending; "-" logs to stderr
+func openDaemonLog(path string) (io.Writer, func(), error) {
+	if path == "-" {
+		return os.Stderr, func() {}, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, nil, fmt.Errorf("error creating log directory: %v", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600) // #nosec G304 - log path comes from the user's config or flags
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening log file: %v", err)
+	}
+	return f, func() { _ = f.Close() }, nil
+}
+
+// notifySessionComplete sends the completion notification and runs the
+// completion hooks for a session the daemon timed
+func notifySessionComplete(session *db.PomodoroSession, logger *log.Logger) {
+	var err error
+	if session.WasBreak {
+		err = app.notifyBreakComplete(false)
+	} else {
+		err = app.notifyPomodoroComplete(session.Description, false)
+	}
+	if err != nil {
+		logger.Printf("error sending notification: %v", err)
+	}
+
+	if !session.WasBreak {
+		announceGoalAchievements(session.ID, false)
+		announceLimitReached()
+	}
+
+	if cfg, err := config.LoadConfig(); err == nil {
+		if err := runHooksFor(cfg, hooks.CompleteEvent(session.WasBreak), session); err != nil {
+			logger.Printf("%v", err)
+		}
+	}
+}
+
+// markNotified records that a session completed and that its completion was
+// announced, so the daemon does not notify a second time for a session timed
+// in the terminal
+func markNotified(database db.DB, id int64) {
+	if err := database.SetSessionMetadata(id, db.MetaNotified, time.Now().Format(time.RFC3339)); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+	}
+	if err := database.SetSessionStatus(id, db.StatusCompleted); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+	}
+	if err := plan.SessionCompleted(database, id, time.Now()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error checking off the planned task: %v\n", err)
+	}
+}
+
+// daemonCall sends an action to the running daemon. ok is false when no
+// daemon is running, in which case callers work on the database directly.
+func daemonCall(action string) (resp *daemon.Response, ok bool, err error) {
+	return daemonSend(daemon.Request{Action: action})
+}
+
+// daemonSend is daemonCall for requests that carry more than an action
+func daemonSend(req daemon.Request) (resp *daemon.Response, ok bool, err error) {
+	resp, err = daemon.Send(daemonSocket(), req)
+	if errors.Is(err, daemon.ErrNotRunning) {
+		return nil, false, nil
+	}
+	return resp, true, err
+}
+
+// activeSession returns the active session as the daemon sees it, reading
+// the database when no daemon is running
+func activeSession(database db.DB) (*db.PomodoroSession, error) {
+	resp, ok, err := daemonCall(daemon.ActionStatus)
+	if !ok {
+		return database.GetActiveSession()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp.Session, nil
+}
+
+// todaySessions returns today's sessions from the daemon when one is
+// running, which answers from memory until the database changes, and from
+// the database otherwise. open is only called when the database is needed.
+func todaySessions(open func() db.DB) ([]db.PomodoroSession, error) {
+	if resp, ok, err := daemonCall(daemon.ActionToday); ok && err == nil {
+		return resp.Sessions, nil
+	}
+	return open().GetTodaySessions()
+}
+
+// ensureDaemon starts a background daemon when none is running, so a session
+// completes and notifies even after the CLI exits. daemon.auto_start turns
+// this off.
+func ensureDaemon() {
+	if cfg, err := config.LoadConfig(); err == nil && !cfg.Daemon.AutoStart {
+		return
+	}
+	if _, ok, _ := daemonCall(daemon.ActionStatus); ok {
+		return
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		warnf("could not start the background daemon: %v\n", err)
+		return
+	}
+	if err := daemon.Spawn(executable, resolveDaemonLogFile(), databasePath); err != nil {
+		warnf("could not start the background daemon: %v\n", err)
+	}
+}
+
+// yesNo formats a boolean for status output
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+	daemonCmd.AddCommand(daemonRunCmd, daemonInstallCmd, daemonUninstallCmd, daemonStatusCmd, daemonEventsCmd)
+
+	daemonCmd.PersistentFlags().StringVar(&daemonLogFile, "log-file", "", "Daemon log file (default from config; - for stderr)")
+	daemonRunCmd.Flags().DurationVar(&daemonExitWhenIdle, "exit-when-idle", 0, "Exit after no session has been active for this long (0 runs until stopped)")
+}
@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/daemon"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+var (
+	seedSessions int
+	seedRandom   uint64
+	seedForce    bool
+
+	rollbackList bool
+)
+
+// dbFlag is set by the global --db flag
+var dbFlag string
+
+// userFlag is set by the global --user flag, or POMODORO_USER
+var userFlag string
+
+// databasePath is the session database commands open. It is resolved before
+// every command by resolveDatabasePath.
+var databasePath string
+
+// resolveDatabasePath picks the session database: --db, then the local
+// user's database for --user or POMODORO_USER, then paths.database from the
+// config, then the default location
+func resolveDatabasePath() (string, error) {
+	user := userFlag
+	if user == "" {
+		user = os.Getenv("POMODORO_USER")
+	}
+	if dbFlag != "" && userFlag != "" {
+		return "", fmt.Errorf("--db and --user cannot be used together")
+	}
+
+	path := dbFlag
+	if path == "" && user != "" {
+		return db.UserPath(user)
+	}
+	if path == "" {
+		path = defaultDatabasePath()
+	}
+	if path == "" {
+		return "", nil
+	}
+
+	path = utils.ExpandPath(path)
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return path, nil
+}
+
+// defaultDatabasePath returns paths.database from the config, or "" for the
+// default location
+func defaultDatabasePath() string {
+	if cfg, err := config.LoadConfig(); err == nil {
+		return cfg.DataPaths.Database
+	}
+	return ""
+}
+
+// daemonSocket returns the control socket of the daemon timing this run's
+// database. The default database keeps the socket it has always used.
+func daemonSocket() string {
+	if def, err := db.DefaultPath(); databasePath == "" || (err == nil && databasePath == def) {
+		return daemon.SocketPath("")
+	}
+	return daemon.SocketPath(databasePath)
+}
+
+// openDB opens the session database chosen for this run through app, which
+// tests point at a mock
+func openDB() (db.DB, error) {
+	return app.openDB()
+}
+
+// openInternalDB opens the session database file itself, for commands that
+// work on the file: backups, snapshots, imports, seeding, and the daemon
+func openInternalDB() (*db.InternalDB, error) {
+	return db.NewDB(databasePath)
+}
+
+// dbCmd groups database maintenance commands
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Database maintenance commands",
+}
+
+// dbSeedCmd fills the database with synthetic history
+var dbSeedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Fills the database with synthetic history for load testing",
+	Long: `Fills the database with realistic synthetic history: working days of
+pomodoros and breaks, quieter weekends, pauses, energy levels, and days spent
+in other time zones, ending yesterday.
+
+Use it to measure how history, goals, and stats perform on a large history.
+It refuses to add to a database that already has sessions unless --force is
+given, so point --db at a scratch file rather than seeding your own data.
+
+Example:
+  pomodoro --db /tmp/load-test.db db seed --sessions 100000
+  pomodoro db seed --sessions 5000 --random-seed 42 --force`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if seedSessions <= 0 {
+			fmt.Fprintln(os.Stderr, "--sessions must be positive")
+			os.Exit(1)
+		}
+
+		database, err := openInternalDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		if !seedForce {
+			last, err := database.GetLastSession()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error checking database: %v\n", err)
+				os.Exit(1)
+			}
+			if last != nil {
+				fmt.Fprintln(os.Stderr, "The database already has sessions; use --force to add synthetic ones anyway.")
+				os.Exit(1)
+			}
+		}
+
+		began := time.Now()
+		created, err := database.Seed(db.SeedOptions{Sessions: seedSessions, End: began, Seed: seedRandom})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error seeding database: %v\n", err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			printJSONLine(struct {
+				Sessions int    `json:"sessions"`
+				Elapsed  string `json:"elapsed"`
+			}{created, time.Since(began).Round(time.Millisecond).String()})
+			return
+		}
+		fmt.Printf("Created %d synthetic sessions in %s.\n", created, time.Since(began).Round(time.Millisecond))
+	},
+}
+
+// dbRollbackCmd restores the database from before its last migration
+var dbRollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Restores the database from before its last schema migration",
+	Long: `Restores the snapshot taken before the database was last migrated.
+
+Before a new version of pomodoro changes the database schema, it snapshots
+the database next to it in migration-backups/, keeping the last 3. If the
+new version misbehaves, roll back and install the previous version: opening
+the restored database with this version migrates it again. The database
+being replaced is kept alongside it as a .bak copy.
+
+Example:
+  pomodoro db rollback --list
+  pomodoro db rollback`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		path := databasePath
+		if path == "" {
+			var err error
+			if path, err = db.DefaultPath(); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		backups, err := db.MigrationBackups(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		if rollbackList {
+			if len(backups) == 0 {
+				fmt.Println("No migration backups.")
+				return
+			}
+			fmt.Printf("Current schema version: %d\n", db.SchemaVersion)
+			for _, b := range backups {
+				fmt.Printf("  %s  schema version %d  %s\n", b.CreatedAt.Format("2006-01-02 15:04:05"), b.Version, b.Path)
+			}
+			return
+		}
+		if len(backups) == 0 {
+			fmt.Fprintf(os.Stderr, "No migration backups of %s to roll back to.\n", path)
+			os.Exit(1)
+		}
+
+		if _, ok, _ := daemonCall(daemon.ActionStatus); ok {
+			fmt.Fprintln(os.Stderr, "A daemon is using the database; stop it before rolling back.")
+			os.Exit(1)
+		}
+
+		latest := backups[0]
+		kept, err := db.Rollback(path, latest)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error rolling back: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Restored the database from %s, at schema version %d.\n", latest.CreatedAt.Format("2006-01-02 15:04:05"), latest.Version)
+		fmt.Printf("The replaced database is kept at %s.\n", kept)
+		decorf("Install the previous version of pomodoro before using it; this one would migrate it again.\n")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbSeedCmd)
+	dbCmd.AddCommand(dbRollbackCmd)
+
+	dbSeedCmd.Flags().IntVar(&seedSessions, "sessions", 10000, "Number of sessions to create, pomodoros and breaks together")
+	dbSeedCmd.Flags().Uint64Var(&seedRandom, "random-seed", 1, "Random seed; the same seed gives the same history")
+	dbSeedCmd.Flags().BoolVar(&seedForce, "force", false, "Add sessions even if the database is not empty")
+
+	dbRollbackCmd.Flags().BoolVar(&rollbackList, "list", false, "List the migration backups instead of restoring one")
+}
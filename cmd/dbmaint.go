@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/trace"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+var (
+	dbBackupForce  bool
+	pruneOlderThan string
+	pruneForce     bool
+)
+
+// dbBackupCmd copies the database to a file
+var dbBackupCmd = &cobra.Command{
+	Use:   "backup <path>",
+	Short: "Copies the database to a file",
+	Long: `Writes a consistent copy of the session database to path, safe to take
+while a timer or the daemon is running. The copy is an ordinary SQLite file:
+point --db at it, or copy it over the database to restore it.
+
+Example:
+  pomodoro db backup ~/pomodoro-history.db`,
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		path := utils.ExpandPath(args[0])
+		if _, err := os.Stat(path); err == nil {
+			if !dbBackupForce {
+				fmt.Fprintf(os.Stderr, "%s already exists; use --force to replace it\n", path)
+				os.Exit(1)
+			}
+			if err := os.Remove(path); err != nil {
+				fmt.Fprintf(os.Stderr, "Error replacing %s: %v\n", path, err)
+				os.Exit(1)
+			}
+		}
+
+		database, err := openInternalDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		if err := database.Snapshot(path); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		size, err := database.Size()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			printJSONLine(struct {
+				Path  string `json:"path"`
+				Bytes int64  `json:"bytes"`
+			}{path, size})
+			return
+		}
+		fmt.Printf("Backed up the database (%s) to %s\n", formatSize(size), path)
+	},
+}
+
+// dbVacuumCmd compacts the database
+var dbVacuumCmd = &cobra.Command{
+	Use:   "vacuum",
+	Short: "Compacts the database file",
+	Long: `Rebuilds the session database so the space left by deleted sessions is
+given back, and folds the write-ahead log into it. Run it after
+'pomodoro db prune', or now and then on a long history.
+
+Example:
+  pomodoro db vacuum`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		database, err := openInternalDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		before, after, err := database.Vacuum()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			printJSONLine(struct {
+				BytesBefore int64 `json:"bytes_before"`
+				BytesAfter  int64 `json:"bytes_after"`
+			}{before, after})
+			return
+		}
+		fmt.Printf("Vacuumed the database: %s, was %s.\n", formatSize(after), formatSize(before))
+	},
+}
+
+// dbPruneCmd deletes old history
+var dbPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Deletes sessions older than a given age",
+	Long: `Deletes the sessions that started before --older-than, with their pauses,
+ratings, and annotations, and the habit counts and API audit entries logged
+before it. Tasks are kept. Stats, streaks, and goals then only reach back as
+far as the history that is left, so take a copy with 'pomodoro db backup'
+first, and run 'pomodoro db vacuum' afterwards to shrink the file.
+
+Example:
+  pomodoro db prune --older-than 2y
+  pomodoro db prune --older-than 2023-01-01 --force`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if pruneOlderThan == "" {
+			fmt.Fprintln(os.Stderr, "Give the age to prune with --older-than, e.g. --older-than 2y")
+			os.Exit(1)
+		}
+		cutoff, err := utils.ParseDate(pruneOlderThan, app.now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing --older-than: %v\n", err)
+			os.Exit(1)
+		}
+
+		database, err := openInternalDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		if !pruneForce {
+			if !isInteractive() || jsonOutput {
+				fmt.Fprintln(os.Stderr, "Use --force to prune without confirmation")
+				os.Exit(1)
+			}
+			old, err := database.GetSessionsByDateRange(time.Time{}, cutoff)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error getting sessions: %v\n", err)
+				os.Exit(1)
+			}
+			if len(old) == 0 {
+				fmt.Printf("No sessions started before %s.\n", cutoff.Format("2006-01-02"))
+				return
+			}
+			if !confirmPrune(os.Stdin, os.Stderr, len(old), cutoff) {
+				fmt.Println("Nothing deleted.")
+				return
+			}
+		}
+
+		result, err := database.Prune(cutoff)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			printJSONLine(result)
+			return
+		}
+		fmt.Printf("Deleted %d sessions and %d habit counts from before %s.\n", result.Sessions, result.CounterEvents, cutoff.Format("2006-01-02"))
+		if result.Sessions > 0 {
+			decorf("Run 'pomodoro db vacuum' to shrink the database file.\n")
+		}
+	},
+}
+
+// confirmPrune asks whether to delete count sessions from before cutoff and
+// reports the answer, which is no unless it is y or yes
+func confirmPrune(in io.Reader, out io.Writer, count int, cutoff time.Time) bool {
+	_, _ = fmt.Fprintf(out, "Delete %d sessions that started before %s? [y/N] ", count, cutoff.Format("2006-01-02"))
+	wait := trace.Begin(trace.Wait, "prompt")
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	wait()
+	if err != nil {
+		_, _ = fmt.Fprintln(out) // end the prompt line on EOF
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// formatSize formats a number of bytes for people
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	value, suffix := float64(bytes)/unit, "KB"
+	for _, next := range []string{"MB", "GB"} {
+		if value < unit {
+			break
+		}
+		value, suffix = value/unit, next
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
+
+func init() {
+	dbCmd.AddCommand(dbBackupCmd, dbVacuumCmd, dbPruneCmd)
+
+	dbBackupCmd.Flags().BoolVarP(&dbBackupForce, "force", "f", false, "Replace the file if it exists")
+	dbPruneCmd.Flags().StringVar(&pruneOlderThan, "older-than", "", "Delete sessions that started before this date or age (2y, 6mo, 2023-01-01, ...)")
+	dbPruneCmd.Flags().BoolVarP(&pruneForce, "force", "f", false, "Prune without asking for confirmation")
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/daemon"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+var (
+	migrateStatus bool
+	migrateTo     int
+)
+
+// dbMigrateCmd shows and changes the database's schema version
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Shows or applies the database schema migrations",
+	Long: `Applies the schema migrations the database does not have yet. Every
+command does this when it opens the database, so this is mostly useful with
+--status, which lists each migration and when it was applied without
+applying any.
+
+With --to, the database is brought to that schema version, undoing the
+migrations after it newest first. Undoing one drops the columns and tables it
+added, and what was in them. The database is snapshotted first, so
+'pomodoro db rollback' can restore it.
+
+Example:
+  pomodoro db migrate --status
+  pomodoro db migrate
+  pomodoro db migrate --to 21`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		database, err := db.Open(databasePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		before, err := database.Migrations()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		if migrateStatus {
+			printMigrationStatus(before)
+			return
+		}
+
+		from, err := database.AppliedVersion()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		if cmd.Flags().Changed("to") {
+			if migrateTo < from {
+				if _, ok, _ := daemonCall(daemon.ActionStatus); ok {
+					fmt.Fprintln(os.Stderr, "A daemon is using the database; stop it before undoing migrations.")
+					os.Exit(1)
+				}
+			}
+			err = database.MigrateTo(migrateTo)
+		} else {
+			err = database.Migrate()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		after, err := database.Migrations()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		to, err := database.AppliedVersion()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		applied, undone := 0, 0
+		for i := range min(len(before), len(after)) {
+			switch {
+			case before[i].Pending() && !after[i].Pending():
+				applied++
+			case !before[i].Pending() && after[i].Pending():
+				undone++
+			}
+		}
+
+		if jsonOutput {
+			printJSONLine(struct {
+				From    int `json:"from"`
+				To      int `json:"to"`
+				Applied int `json:"applied"`
+				Undone  int `json:"undone"`
+			}{from, to, applied, undone})
+			return
+		}
+		switch {
+		case applied > 0:
+			fmt.Printf("Applied %d migrations: schema version %d, was %d.\n", applied, to, from)
+		case undone > 0:
+			fmt.Printf("Undid %d migrations: schema version %d, was %d.\n", undone, to, from)
+			decorf("Install a version of pomodoro that uses it before going on; this one would migrate it again.\n")
+		default:
+			fmt.Printf("The database is at schema version %d; there was nothing to do.\n", to)
+		}
+	},
+}
+
+// printMigrationStatus lists the migrations and whether each is applied
+func printMigrationStatus(statuses []db.MigrationStatus) {
+	if jsonOutput {
+		out := make([]migrationJSON, 0, len(statuses))
+		for _, s := range statuses {
+			m := migrationJSON{Version: s.Version, Name: s.Name, Pending: s.Pending(), Unknown: s.Unknown}
+			if !s.Pending() {
+				m.AppliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+			out = append(out, m)
+		}
+		printJSON(out)
+		return
+	}
+
+	pending := 0
+	fmt.Printf("%-7s %-38s %s\n", "VERSION", "MIGRATION", "APPLIED")
+	for _, s := range statuses {
+		applied := s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+		switch {
+		case s.Pending():
+			applied = "pending"
+			pending++
+		case s.Unknown:
+			applied += " (from a newer pomodoro)"
+		}
+		fmt.Printf("%-7d %-38s %s\n", s.Version, s.Name, applied)
+	}
+	if pending == 0 {
+		fmt.Println("\nThe database is up to date.")
+		return
+	}
+	fmt.Printf("\n%d pending; they are applied the next time the database is opened, or by 'pomodoro db migrate'.\n", pending)
+}
+
+// migrationJSON is the JSON representation of a schema migration
+type migrationJSON struct {
+	Version   int    `json:"version"`
+	Name      string `json:"name"`
+	AppliedAt string `json:"applied_at,omitempty"`
+	Pending   bool   `json:"pending"`
+	Unknown   bool   `json:"unknown,omitempty"`
+}
+
+func init() {
+	dbCmd.AddCommand(dbMigrateCmd)
+
+	dbMigrateCmd.Flags().BoolVar(&migrateStatus, "status", false, "List the migrations and which are applied, changing nothing")
+	dbMigrateCmd.Flags().IntVar(&migrateTo, "to", 0, "Bring the database to this schema version, undoing later migrations")
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	slowLogLimit int
+	slowLogClear bool
+)
+
+// debugCmd groups diagnostics for reporting problems
+var debugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "Diagnostics for reporting problems",
+}
+
+// debugSlowLogCmd shows the invocations that took longer than
+// debug.slow_threshold
+var debugSlowLogCmd = &cobra.Command{
+	Use:   "slow-log",
+	Short: "Shows the invocations that were slow",
+	Long: `Lists the runs of pomodoro that took longer than debug.slow_threshold,
+newest first, with the time spent in database queries and rendering and the
+slowest queries. Time spent waiting on you, in the timer or at a prompt,
+does not count. Only the command and the names of its flags are logged, not
+their values.
+
+Slow invocations are only logged once a threshold is set. To find out why a
+status bar polling 'pomodoro status' feels sluggish, set one, use it for a
+while, and look here; for a single run, use --profile-cmd instead.
+
+Example:
+  pomodoro config debug.slow_threshold 150ms
+  pomodoro debug slow-log
+  pomodoro debug slow-log --clear`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		path, err := slowLogPath()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if slowLogClear {
+			for _, p := range []string{path, path + ".1"} {
+				if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+					fmt.Fprintf(os.Stderr, "Error clearing slow log: %v\n", err)
+					os.Exit(1)
+				}
+			}
+			fmt.Println("Cleared the slow log.")
+			return
+		}
+
+		entries, err := readSlowLog(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading slow log: %v\n", err)
+			os.Exit(1)
+		}
+		if slowLogLimit > 0 && len(entries) > slowLogLimit {
+			entries = entries[:slowLogLimit]
+		}
+
+		if jsonOutput {
+			if entries == nil {
+				entries = []slowEntry{}
+			}
+			printJSON(entries)
+			return
+		}
+
+		if len(entries) == 0 {
+			if slowThreshold == 0 {
+				fmt.Println("No slow invocations logged; set debug.slow_threshold to start logging them.")
+				return
+			}
+			fmt.Printf("No invocations have taken longer than %s.\n", slowThreshold)
+			return
+		}
+		fmt.Printf("%-19s %9s %9s %8s %9s  %s\n", "TIME", "TOTAL", "DATABASE", "QUERIES", "RENDER", "COMMAND")
+		for _, e := range entries {
+			command := e.Command
+			for _, f := range e.Flags {
+				command += " " + f
+			}
+			fmt.Printf("%-19s %9s %9s %8d %9s  %s\n", e.At.Local().Format("2006-01-02 15:04:05"),
+				msDuration(e.TotalMS), msDuration(e.QueryMS), e.Queries, msDuration(e.RenderMS), command)
+			if len(e.SlowestSQL) > 0 {
+				q := e.SlowestSQL[0]
+				fmt.Printf("%19s slowest query %s: %s\n", "", msDuration(q.MS), q.Query)
+			}
+		}
+	},
+}
+
+// msDuration turns fractional milliseconds from the slow log back into a
+// duration for printing
+func msDuration(ms float64) time.Duration {
+	return roundDuration(time.Duration(ms * float64(time.Millisecond)))
+}
+
+// readSlowLog reads the slow log at path and the one moved aside before it,
+// newest first, skipping lines it cannot read
+func readSlowLog(path string) ([]slowEntry, error) {
+	var entries []slowEntry
+	for _, p := range []string{path + ".1", path} {
+		f, err := os.Open(p) // #nosec G304 - the path comes from the config
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 1<<20)
+		for scanner.Scan() {
+			var e slowEntry
+			if json.Unmarshal(scanner.Bytes(), &e) == nil {
+				entries = append(entries, e)
+			}
+		}
+		err = scanner.Err()
+		_ = f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+func init() {
+	rootCmd.AddCommand(debugCmd)
+	debugCmd.AddCommand(debugSlowLogCmd)
+
+	debugSlowLogCmd.Flags().IntVarP(&slowLogLimit, "limit", "n", 20, "Number of invocations to show, 0 for all")
+	debugSlowLogCmd.Flags().BoolVar(&slowLogClear, "clear", false, "Delete the slow log")
+}
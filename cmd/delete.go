@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/trace"
+)
+
+var (
+	deleteLast  bool
+	deleteForce bool
+)
+
+// deleteCmd removes a recorded session
+var deleteCmd = &cobra.Command{
+	Use:   "delete [session]",
+	Short: "Removes a session recorded by mistake",
+	Long: `Deletes a session from the history, along with its metadata, annotations,
+and pauses. This cannot be undone.
+
+You are asked to confirm first; --force skips the question, and is needed
+when there is no terminal to ask in. A running session must be cancelled
+before it can be deleted.
+
+Example:
+  pomodoro delete 42
+  pomodoro delete '#a3f9c2'
+  pomodoro delete --last --force`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeSession,
+	Run: func(_ *cobra.Command, args []string) {
+		if deleteLast == (len(args) == 1) {
+			fmt.Fprintln(os.Stderr, "Give a session to delete, or --last for the most recent one")
+			os.Exit(1)
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		var session *db.PomodoroSession
+		if deleteLast {
+			session, err = database.GetLastSession()
+		} else {
+			session, err = database.ResolveSession(args[0])
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		if session == nil {
+			fmt.Fprintln(os.Stderr, "No session found to delete")
+			os.Exit(1)
+		}
+
+		if !deleteForce {
+			if !isInteractive() || jsonOutput {
+				fmt.Fprintln(os.Stderr, "Use --force to delete without confirmation")
+				os.Exit(1)
+			}
+			if !confirmDelete(os.Stdin, os.Stderr, session) {
+				fmt.Println("Nothing deleted.")
+				return
+			}
+		}
+
+		if err := database.DeleteSession(session.ID); err != nil {
+			fmt.Fprintf(os.Stderr, "Error deleting session %s: %v\n", session.ShortRef(), err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			out := struct {
+				Deleted jsonSession `json:"deleted"`
+			}{newJSONSession(*session, nil)}
+			printJSON(out)
+			return
+		}
+
+		fmt.Printf("%sDeleted session %s: %s\n", icon("🗑️"), session.ShortRef(), session.Description)
+	},
+}
+
+// confirmDelete asks whether to delete s and reports the answer, which is
+// no unless it is y or yes
+func confirmDelete(in io.Reader, out io.Writer, s *db.PomodoroSession) bool {
+	_, _ = fmt.Fprintf(out, "Delete %s %q from %s? [y/N] ",
+		s.ShortRef(), s.Description, s.StartTime.In(s.Location()).Format(editTimeLayout))
+	wait := trace.Begin(trace.Wait, "prompt")
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	wait()
+	if err != nil {
+		_, _ = fmt.Fprintln(out) // end the prompt line on EOF
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func init() {
+	rootCmd.AddCommand(deleteCmd)
+	deleteCmd.Flags().BoolVar(&deleteLast, "last", false, "Delete the most recent session")
+	deleteCmd.Flags().BoolVarP(&deleteForce, "force", "f", false, "Delete without asking for confirmation")
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/model"
+	"github.com/ethan-k/pomodoro-cli/internal/trace"
+)
+
+var demoSpeed float64
+
+// maxPlaybackSpeed bounds --speed, beyond which the countdown is a blur
+const maxPlaybackSpeed = 3600
+
+// errPlaybackStopped is returned when the user stops a playback with Ctrl+C
+var errPlaybackStopped = errors.New("stopped")
+
+// playbackStep is one session shown by demo or replay
+type playbackStep struct {
+	Start       time.Time
+	Length      time.Duration
+	Description string
+	IsBreak     bool
+}
+
+// demoSessions is the sample cycle the demo plays
+var demoSessions = []playbackStep{
+	{Length: 25 * time.Minute, Description: "Write the quarterly report"},
+	{Length: 5 * time.Minute, Description: "Break Time", IsBreak: true},
+	{Length: 25 * time.Minute, Description: "Review pull requests"},
+	{Length: 5 * time.Minute, Description: "Break Time", IsBreak: true},
+	{Length: 25 * time.Minute, Description: "Plan next sprint"},
+	{Length: 15 * time.Minute, Description: "Long Break", IsBreak: true},
+}
+
+// demoCmd plays a sample cycle of sessions at high speed
+var demoCmd = &cobra.Command{
+	Use:   "demo",
+	Short: "Play a sample pomodoro cycle at high speed",
+	Long: `Plays a sample cycle of pomodoros and breaks in the timer UI, running
+--speed times faster than real time. Nothing is read from or written to
+your session history, which makes it handy for screenshots, screencasts, and
+seeing what the timer looks like before using it.
+
+Example:
+  pomodoro demo
+  pomodoro demo --speed 300`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if err := validatePlaybackSpeed(demoSpeed); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		steps := make([]playbackStep, len(demoSessions))
+		start := time.Now()
+		for i, step := range demoSessions {
+			step.Start = start
+			steps[i] = step
+			start = start.Add(step.Length)
+		}
+
+		fmt.Printf("%sDemo: a pomodoro cycle at %g× speed. Press Ctrl+C to stop.\n", icon("🎬"), demoSpeed)
+		if err := playSessions(steps, demoSpeed); err != nil {
+			if errors.Is(err, errPlaybackStopped) {
+				return
+			}
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		decorf("%sDemo complete. Start your own with 'pomodoro start \"Task\"'.\n", icon("🎉"))
+	},
+}
+
+// playSessions shows each step in the timer UI in turn on a fast clock
+// starting at the step's start, so the countdown reads as it did (or would)
+// in real time
+func playSessions(steps []playbackStep, speed float64) error {
+	// A redraw for every couple of simulated seconds keeps the countdown smooth
+	tick := time.Duration(float64(2*time.Second) / speed)
+	if tick < 50*time.Millisecond {
+		tick = 50 * time.Millisecond
+	}
+
+	for _, step := range steps {
+		fmt.Printf("\n%s %s  %s (%s)\n", sessionIcon(step.IsBreak), step.Start.Format("15:04"),
+			step.Description, step.Length.Round(time.Second))
+
+		clock := model.NewFastClock(step.Start, speed)
+		m := model.NewPomodoroModel(0, step.Description, step.Start, step.Length, step.IsBreak).WithClock(clock, tick)
+		wait := trace.Begin(trace.Wait, "demo")
+		final, err := tea.NewProgram(m).Run()
+		wait()
+		if err != nil {
+			return fmt.Errorf("error running UI: %v", err)
+		}
+		if fm, ok := final.(model.PomodoroModel); ok && fm.Interrupted() {
+			return errPlaybackStopped
+		}
+	}
+	return nil
+}
+
+// validatePlaybackSpeed checks a --speed value
+func validatePlaybackSpeed(speed float64) error {
+	if speed < 1 || speed > maxPlaybackSpeed {
+		return fmt.Errorf("invalid speed %g: must be between 1 and %d", speed, maxPlaybackSpeed)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(demoCmd)
+	demoCmd.Flags().Float64Var(&demoSpeed, "speed", 60, "How many times faster than real time to run")
+}
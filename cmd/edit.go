@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/trace"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+var (
+	editDescription string
+	editTags        string
+	editStart       string
+	editEnd         string
+	editInteractive bool
+)
+
+// editTimeLayout is how edit shows a session's times, and one of the forms
+// it accepts back
+const editTimeLayout = "2006-01-02 15:04"
+
+// editCmd corrects a past session
+var editCmd = &cobra.Command{
+	Use:   "edit <session>",
+	Short: "Fixes the description, tags, or times of a past session",
+	Long: `Corrects a session after the fact: a typo in its description, a forgotten
+tag, or a start or end that was off.
+
+Times are HH:MM on the session's day, "YYYY-MM-DD HH:MM", or RFC 3339, in the
+zone the session was recorded in. Times can only change once the session has
+finished (use 'pomodoro extend' for a running one) and must still cover any
+pauses. --tags replaces all tags; --tags "" removes them.
+
+With --interactive, each field is asked for in turn with its current value;
+press Enter to keep it, or enter - to clear the tags.
+
+Example:
+  pomodoro edit 42 --description "Write the quarterly report"
+  pomodoro edit '#a3f9c2' --tags writing,report
+  pomodoro edit 2024-06-01.3 --start 09:05 --end 09:30
+  pomodoro edit 42 --interactive`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSession,
+	Run: func(cmd *cobra.Command, args []string) {
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		session, err := database.ResolveSession(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		if session == nil {
+			fmt.Fprintf(os.Stderr, "No session found for %s\n", args[0])
+			os.Exit(1)
+		}
+
+		changes := editChanges{}
+		flags := cmd.Flags()
+		if flags.Changed("description") {
+			changes.Description = &editDescription
+		}
+		if flags.Changed("tags") {
+			changes.Tags = &editTags
+		}
+		if flags.Changed("start") {
+			changes.Start = &editStart
+		}
+		if flags.Changed("end") {
+			changes.End = &editEnd
+		}
+		if editInteractive {
+			if changes != (editChanges{}) {
+				fmt.Fprintln(os.Stderr, "--interactive cannot be combined with --description, --tags, --start, or --end")
+				os.Exit(1)
+			}
+			if changes, err = promptEditChanges(os.Stdin, os.Stdout, session); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			if changes == (editChanges{}) {
+				fmt.Println("No changes made.")
+				return
+			}
+		}
+		if changes == (editChanges{}) {
+			fmt.Fprintln(os.Stderr, "Nothing to change; use --description, --tags, --start, --end, or --interactive.")
+			os.Exit(1)
+		}
+
+		edit, err := changes.apply(session)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		if err := database.EditSession(session.ID, edit); err != nil {
+			fmt.Fprintf(os.Stderr, "Error editing session %s: %v\n", session.ShortRef(), err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			updated, err := database.GetSessionByID(session.ID)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			printJSON(newJSONSession(*updated, nil))
+			return
+		}
+
+		fmt.Printf("%sUpdated session %s: %s\n", icon("✏️ "), session.ShortRef(), edit.Description)
+	},
+}
+
+// editChanges are the fields given to edit; nil fields keep their value
+type editChanges struct {
+	Description *string
+	Tags        *string
+	Start       *string
+	End         *string
+}
+
+// apply validates the changes and returns the session's corrected values
+func (c editChanges) apply(s *db.PomodoroSession) (db.SessionEdit, error) {
+	loc := s.Location()
+	edit := db.SessionEdit{
+		Description: s.Description,
+		TagsCSV:     s.TagsCSV,
+		StartTime:   s.StartTime,
+		EndTime:     s.EndTime,
+	}
+
+	if c.Description != nil {
+		edit.Description = utils.SanitizeDescription(*c.Description)
+		if err := utils.ValidateDescription(edit.Description, false); err != nil {
+			return edit, fmt.Errorf("invalid description: %v", err)
+		}
+	}
+	if c.Tags != nil {
+		tags := utils.SanitizeTags(strings.Split(*c.Tags, ","))
+		if err := utils.ValidateTags(tags); err != nil {
+			return edit, fmt.Errorf("invalid tags: %v", err)
+		}
+		edit.TagsCSV = strings.Join(tags, ",")
+	}
+	if c.Start != nil {
+		t, err := utils.ParseDateTime(*c.Start, s.StartTime.In(loc))
+		if err != nil {
+			return edit, fmt.Errorf("invalid start: %v", err)
+		}
+		edit.StartTime = t
+	}
+	if c.End != nil {
+		// A bare time of day is on the day the session (now) starts
+		t, err := utils.ParseDateTime(*c.End, edit.StartTime.In(loc))
+		if err != nil {
+			return edit, fmt.Errorf("invalid end: %v", err)
+		}
+		edit.EndTime = t
+	}
+	return edit, nil
+}
+
+// promptEditChanges asks for each field of s with its current value filled
+// in. An empty answer keeps the value.
+func promptEditChanges(in io.Reader, out io.Writer, s *db.PomodoroSession) (editChanges, error) {
+	reader := bufio.NewReader(in)
+	ask := func(label, current string) (*string, error) {
+		_, _ = fmt.Fprintf(out, "%s [%s]: ", label, current)
+		wait := trace.Begin(trace.Wait, "prompt")
+		answer, err := reader.ReadString('\n')
+		wait()
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("error reading answer: %v", err)
+		}
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			return nil, nil
+		}
+		return &answer, nil
+	}
+
+	var c editChanges
+	var err error
+	loc := s.Location()
+	if c.Description, err = ask("Description", s.Description); err != nil {
+		return c, err
+	}
+	if c.Tags, err = ask("Tags", s.TagsCSV); err != nil {
+		return c, err
+	}
+	if c.Tags != nil && *c.Tags == "-" {
+		*c.Tags = ""
+	}
+	if c.Start, err = ask("Start", s.StartTime.In(loc).Format(editTimeLayout)); err != nil {
+		return c, err
+	}
+	if c.End, err = ask("End", s.EndTime.In(loc).Format(editTimeLayout)); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+func init() {
+	rootCmd.AddCommand(editCmd)
+	editCmd.Flags().StringVar(&editDescription, "description", "", "New description")
+	editCmd.Flags().StringVarP(&editTags, "tags", "t", "", "New comma-separated tags, replacing the current ones")
+	_ = editCmd.RegisterFlagCompletionFunc("tags", completeTags)
+	editCmd.Flags().StringVar(&editStart, "start", "", "New start time (HH:MM or \"YYYY-MM-DD HH:MM\")")
+	editCmd.Flags().StringVar(&editEnd, "end", "", "New end time (HH:MM or \"YYYY-MM-DD HH:MM\")")
+	editCmd.Flags().BoolVarP(&editInteractive, "interactive", "i", false, "Ask for each field, showing its current value")
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func TestPromptEditChanges(t *testing.T) {
+	zone := time.FixedZone("CEST", 2*3600)
+	start := time.Date(2024, 6, 3, 9, 0, 0, 0, zone)
+	session := &db.PomodoroSession{
+		Description: "Wrte report",
+		TagsCSV:     "writing",
+		StartTime:   start.UTC(),
+		EndTime:     start.Add(25 * time.Minute).UTC(),
+		TZName:      "CEST",
+		TZOffset:    2 * 3600,
+	}
+
+	// Fix the description, clear the tags, keep the start, move the end
+	var out bytes.Buffer
+	changes, err := promptEditChanges(strings.NewReader("Write report\n-\n\n09:20\n"), &out, session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Start [2024-06-03 09:00]: ") {
+		t.Errorf("Expected the start in the session's zone as the default, got %q", out.String())
+	}
+
+	edit, err := changes.apply(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := db.SessionEdit{Description: "Write report", StartTime: session.StartTime, EndTime: start.Add(20 * time.Minute)}
+	if edit.Description != want.Description || edit.TagsCSV != "" || !edit.StartTime.Equal(want.StartTime) || !edit.EndTime.Equal(want.EndTime) {
+		t.Errorf("Expected %+v, got %+v", want, edit)
+	}
+
+	// Running out of input keeps the rest
+	changes, err = promptEditChanges(strings.NewReader(""), &out, session)
+	if err != nil || changes != (editChanges{}) {
+		t.Errorf("Expected no changes at the end of input, got %+v, %v", changes, err)
+	}
+}
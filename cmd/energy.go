@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/stats"
+)
+
+var (
+	energySession string
+	energyAtStart bool
+	energyDays    int
+)
+
+// energyCmd represents the energy command
+var energyCmd = &cobra.Command{
+	Use:   "energy <level>",
+	Short: "Logs your energy level (1-5) for a session",
+	Long: `Logs your energy level on a scale from 1 (drained) to 5 (energized).
+
+By default the level is recorded as the end-of-session energy of the most
+recent session. Use --start to record it as the starting energy instead, or
+log it when starting with 'pomodoro start --energy 4'.
+
+Use 'pomodoro energy report' to see how energy correlates with time of day.
+
+Example:
+  pomodoro energy 3
+  pomodoro energy 4 --start --session '#a3f9c2'
+  pomodoro energy report --days 30`,
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		level, ok := stats.ParseEnergy(args[0])
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Invalid energy level %q: must be between %d and %d\n", args[0], stats.MinEnergy, stats.MaxEnergy)
+			os.Exit(1)
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		var session *db.PomodoroSession
+		if energySession != "" {
+			session, err = database.ResolveSession(energySession)
+		} else {
+			session, err = database.GetLastSession()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error finding session: %v\n", err)
+			os.Exit(1)
+		}
+		if session == nil {
+			fmt.Println("No session found to log energy for.")
+			return
+		}
+
+		key := db.MetaEnergyEnd
+		if energyAtStart {
+			key = db.MetaEnergyStart
+		}
+		if err := database.SetSessionMetadata(session.ID, key, strconv.Itoa(level)); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			printJSONLine(map[string]any{"id": session.ID, key: level})
+			return
+		}
+
+		fmt.Printf("Logged energy %d/%d for %s: %s\n", level, stats.MaxEnergy, session.ShortRef(), session.Description)
+	},
+}
+
+// energyReportCmd represents the energy report command
+var energyReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Shows how your energy varies by time of day",
+	Run: func(_ *cobra.Command, _ []string) {
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		endDate := app.now()
+		startDate := endDate.AddDate(0, 0, -energyDays)
+
+		buckets, err := energyReport(database, startDate, endDate)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			printJSON(buckets)
+			return
+		}
+
+		printEnergyReport(buckets)
+	},
+}
+
+// energyReport loads sessions and energy levels for the range and groups them by time of day
+func energyReport(database db.DB, startDate, endDate time.Time) ([]stats.EnergyBucket, error) {
+	sessions, err := database.GetSessionsByDateRange(startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("error getting sessions: %v", err)
+	}
+	startEnergy, err := database.GetMetadataByDateRange(db.MetaEnergyStart, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	endEnergy, err := database.GetMetadataByDateRange(db.MetaEnergyEnd, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats.EnergyByTimeOfDay(sessions, startEnergy, endEnergy), nil
+}
+
+// printEnergyReport prints the energy buckets as a table with a scheduling tip
+func printEnergyReport(buckets []stats.EnergyBucket) {
+	fmt.Println("Energy by Time of Day:")
+	fmt.Println("----------------------")
+	fmt.Printf("%-10s %9s %12s %10s %10s\n", "", "Pomodoros", "Start energy", "End energy", "Avg focus")
+	for _, b := range buckets {
+		fmt.Printf("%-10s %9d %12s %10s %10s\n",
+			b.Label,
+			b.Pomodoros,
+			formatEnergy(b.AvgStart),
+			formatEnergy(b.AvgEnd),
+			b.AvgFocus.Round(time.Minute))
+	}
+
+	if peak := stats.PeakEnergy(buckets); peak != nil {
+		decorf("\nYour energy peaks in the %s — schedule demanding work then.\n", peak.Label)
+	} else {
+		decorf("\nNo energy levels logged yet. Try 'pomodoro start --energy 4' or 'pomodoro energy 3'.\n")
+	}
+}
+
+// formatEnergy formats an average energy level, showing "-" when none was logged
+func formatEnergy(avg float64) string {
+	if avg == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f", avg)
+}
+
+func init() {
+	rootCmd.AddCommand(energyCmd)
+	energyCmd.AddCommand(energyReportCmd)
+
+	energyCmd.Flags().StringVar(&energySession, "session", "", "Session ID or reference (default: most recent session)")
+	energyCmd.Flags().BoolVar(&energyAtStart, "start", false, "Record as the session's starting energy")
+	energyReportCmd.Flags().IntVar(&energyDays, "days", 30, "Number of days to include")
+}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+// builtinPomodoroDuration is the pomodoro length when nothing else sets one
+const builtinPomodoroDuration = 25 * time.Minute
+
+// resolution records how a setting was resolved: every source it can come
+// from, in order of precedence, and what each one gives
+type resolution struct {
+	Setting string
+	Steps   []resolutionStep
+}
+
+// resolutionStep is one source a setting can come from
+type resolutionStep struct {
+	Source string
+	Value  string // Empty when the source does not set the setting
+	Note   string // Why the source gives nothing, e.g. "not set"
+}
+
+// set adds a source that gives value
+func (r *resolution) set(source, value string) {
+	r.Steps = append(r.Steps, resolutionStep{Source: source, Value: value})
+}
+
+// skip adds a source that gives nothing, saying why
+func (r *resolution) skip(source, note string) {
+	r.Steps = append(r.Steps, resolutionStep{Source: source, Note: note})
+}
+
+// used returns the index of the source the setting comes from, the first
+// that gives a value, or -1 when none does
+func (r *resolution) used() int {
+	for i, step := range r.Steps {
+		if step.Value != "" {
+			return i
+		}
+	}
+	return -1
+}
+
+// print writes the setting's value and each source in order, marking the
+// one used
+func (r *resolution) print(w io.Writer) {
+	used := r.used()
+	value := "(none)"
+	if used >= 0 {
+		value = r.Steps[used].Value
+	}
+	_, _ = fmt.Fprintf(w, "%s: %s\n", r.Setting, value)
+	for i, step := range r.Steps {
+		shown := step.Value
+		if shown == "" {
+			shown = "(" + step.Note + ")"
+		}
+		mark := ""
+		if i == used {
+			mark = "  <- used"
+		}
+		_, _ = fmt.Fprintf(w, "  %d. %-32s %s%s\n", i+1, step.Source, shown, mark)
+	}
+}
+
+// resolveDuration resolves a pomodoro's duration. In order of precedence it
+// comes from the --duration flag (flag, nil when not given), the first of
+// tags with a rule in defaults.tag_durations, defaults.pomodoro_duration,
+// and finally the built-in 25 minutes. cfg may be nil when there is no
+// usable config.
+func resolveDuration(flag *time.Duration, tags []string, cfg *config.Config) (time.Duration, *resolution) {
+	r := &resolution{Setting: "duration"}
+	var d time.Duration
+	chosen := false
+	choose := func(source string, value time.Duration) {
+		r.set(source, value.String())
+		if !chosen {
+			d, chosen = value, true
+		}
+	}
+
+	if flag != nil {
+		choose("--duration flag", *flag)
+	} else {
+		r.skip("--duration flag", "not given")
+	}
+
+	if cfg == nil {
+		r.skip("defaults.tag_durations", "no config")
+		r.skip("defaults.pomodoro_duration", "no config")
+	} else {
+		if tag, value, ok := cfg.Defaults.TagDuration(tags); ok {
+			choose("defaults.tag_durations."+tag, value)
+		} else if len(tags) == 0 {
+			r.skip("defaults.tag_durations", "no tags given")
+		} else {
+			r.skip("defaults.tag_durations", "no rule for the tags given")
+		}
+
+		switch value, err := utils.ParseHumanDuration(cfg.Defaults.PomodoroDuration); {
+		case cfg.Defaults.PomodoroDuration == "":
+			r.skip("defaults.pomodoro_duration", "not set")
+		case err != nil || value <= 0:
+			r.skip("defaults.pomodoro_duration", fmt.Sprintf("invalid value %q", cfg.Defaults.PomodoroDuration))
+		default:
+			choose("defaults.pomodoro_duration", value)
+		}
+	}
+
+	choose("built-in", builtinPomodoroDuration)
+	return d, r
+}
+
+// resolveTags resolves a session's tags, which come only from the --tags
+// flag (flag, nil when not given)
+func resolveTags(flag []string) *resolution {
+	r := &resolution{Setting: "tags"}
+	if flag != nil {
+		r.set("--tags flag", strings.Join(flag, ","))
+	} else {
+		r.skip("--tags flag", "not given")
+	}
+	r.skip("built-in", "no tags")
+	return r
+}
+
+// resolveAudio resolves whether the completion sound plays: --silent turns
+// it off, then audio.enabled decides, and it plays when neither says
+func resolveAudio(silent bool, cfg *config.Config) *resolution {
+	r := &resolution{Setting: "audio"}
+	if silent {
+		r.set("--silent flag", "off")
+	} else {
+		r.skip("--silent flag", "not given")
+	}
+	if cfg == nil || cfg.Audio == nil {
+		r.skip("audio.enabled", "no config")
+	} else {
+		r.set("audio.enabled", onOff(cfg.Audio.Enabled))
+	}
+	r.set("built-in", "on")
+	return r
+}
+
+// onOff formats a boolean setting
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+// resolveConfigKey resolves a config key, which the config file sets or the
+// built-in defaults do
+func resolveConfigKey(key string) (*resolution, error) {
+	source, err := config.LookupKey(key)
+	if err != nil {
+		return nil, err
+	}
+	r := &resolution{Setting: key}
+	if source.InFile {
+		r.set("config file", source.File)
+	} else {
+		r.skip("config file", "not set")
+	}
+	if source.Default != "" {
+		r.set("built-in", source.Default)
+	} else {
+		r.skip("built-in", "empty")
+	}
+	return r, nil
+}
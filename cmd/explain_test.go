@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+)
+
+func TestResolveDuration(t *testing.T) {
+	cfg := &config.Config{Defaults: config.DefaultsConfig{
+		PomodoroDuration: "30m",
+		TagDurations:     map[string]string{"email": "15m", "Deep-Work": "50m", "broken": "soon"},
+	}}
+	flag := 40 * time.Minute
+
+	for _, tc := range []struct {
+		name string
+		flag *time.Duration
+		tags []string
+		cfg  *config.Config
+		want time.Duration
+	}{
+		{"flag wins", &flag, []string{"email"}, cfg, 40 * time.Minute},
+		{"first tag with a rule", nil, []string{"coding", "deep-work", "email"}, cfg, 50 * time.Minute},
+		{"invalid rule passed over", nil, []string{"broken", "email"}, cfg, 15 * time.Minute},
+		{"config default", nil, []string{"coding"}, cfg, 30 * time.Minute},
+		{"built-in", nil, nil, nil, 25 * time.Minute},
+	} {
+		got, _ := resolveDuration(tc.flag, tc.tags, tc.cfg)
+		if got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.name, got, tc.want)
+		}
+	}
+
+	_, r := resolveDuration(nil, []string{"email"}, cfg)
+	var out bytes.Buffer
+	r.print(&out)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 || lines[0] != "duration: 15m0s" ||
+		!strings.Contains(lines[1], "(not given)") ||
+		!strings.Contains(lines[2], "defaults.tag_durations.email") || !strings.HasSuffix(lines[2], "<- used") ||
+		strings.Contains(lines[3], "<- used") {
+		t.Errorf("Unexpected explanation:\n%s", out.String())
+	}
+}
+
+func TestResolveConfigKey(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, ".config", "pomodoro", "config.yml")
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("goals:\n  daily_count: 6\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := resolveConfigKey("goals.daily_count")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if used := r.used(); used != 0 || r.Steps[0].Value != "6" || r.Steps[1].Value != "8" {
+		t.Errorf("Expected the config file's 6 over the built-in 8, got %+v", r.Steps)
+	}
+
+	r, err = resolveConfigKey("goals.weekly_count")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if used := r.used(); used != 1 || r.Steps[1].Value != "40" {
+		t.Errorf("Expected the built-in weekly goal, got %+v", r.Steps)
+	}
+
+	if _, err := resolveConfigKey("goals.nope"); err == nil {
+		t.Error("Expected an unknown key to be refused")
+	}
+}
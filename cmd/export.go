@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/anonymize"
+	"github.com/ethan-k/pomodoro-cli/internal/backup"
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/opf"
+	"github.com/ethan-k/pomodoro-cli/internal/org"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+var (
+	exportFrom      string
+	exportTo        string
+	exportOutput    string
+	exportAnonymize bool
+	exportHash      bool
+	exportSalt      string
+	exportAll       bool
+	exportFile      string
+	exportScheduled bool
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Exports your session history, optionally anonymized",
+	Long: `Exports your session history as JSON, OPF, or org-mode, covering all time
+by default.
+
+The org format has a heading for each task with its pomodoros as CLOCK lines,
+split around pauses, and a clocktable block to update with C-c C-c. Paste it
+into an agenda file, or include it with #+INCLUDE, to see pomodoro time in
+org's clock reports. Breaks are left out.
+
+With --all, everything is backed up to one file for 'pomodoro import' to
+restore: a JSON document of every finished session and the config file,
+goals included, or with --output tar.gz an archive of the whole database,
+config, custom sounds, and hooks, as 'pomodoro export-all' writes. The file
+goes in paths.opf_export unless --file is given.
+
+With --scheduled, the exports configured under exports in the config file
+are written now, as the daemon writes them on their schedule, and the
+oldest past exports.keep are removed.
+
+With --anonymize, descriptions, tags, time zone names, and session IDs are
+removed while start times, durations, pauses, breaks, and UTC offsets are
+kept, so the data can be shared for research or demos. Add --hash to replace
+descriptions and tags with salted hashes instead, which keeps repeated tasks
+recognizable without revealing them. The salt is random unless --salt is
+given; reuse a salt to make hashes comparable across exports.
+
+Examples:
+  pomodoro export > sessions.json
+  pomodoro export --anonymize > shareable.json
+  pomodoro export --anonymize --hash --from 2025-01-01 --output opf
+  pomodoro export --anonymize --hash --salt my-study-2025
+  pomodoro export --output org --from monday > ~/org/pomodoros.org
+  pomodoro export --all
+  pomodoro export --all --output tar.gz --file ~/pomodoro-backup.tar.gz
+  pomodoro export --scheduled`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		if exportScheduled {
+			runScheduledExportNow(cmd)
+			return
+		}
+		if exportAll {
+			runExportAll(cmd)
+			return
+		}
+		if exportFile != "" {
+			fmt.Fprintln(os.Stderr, "--file requires --all")
+			os.Exit(1)
+		}
+		if (exportHash || cmd.Flags().Changed("salt")) && !exportAnonymize {
+			fmt.Fprintln(os.Stderr, "--hash and --salt require --anonymize")
+			os.Exit(1)
+		}
+		if exportSalt != "" && !exportHash {
+			fmt.Fprintln(os.Stderr, "--salt requires --hash")
+			os.Exit(1)
+		}
+		if exportOutput != "json" && exportOutput != "opf" && exportOutput != "org" {
+			fmt.Fprintf(os.Stderr, "Invalid output format %q: must be json, opf, or org\n", exportOutput)
+			os.Exit(1)
+		}
+
+		var startDate time.Time
+		endDate := app.now()
+		if exportFrom != "" {
+			var err error
+			startDate, err = utils.ParseDate(exportFrom, endDate)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error parsing from date: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if exportTo != "" {
+			var err error
+			endDate, err = utils.ParseDate(exportTo, endDate)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error parsing to date: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		// Connect to database
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		sessions, err := database.GetSessionsByDateRange(startDate, endDate)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting sessions: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Pauses are read by each session's real ID, before anonymizing
+		var pauses [][]db.Pause
+		if exportOutput == "org" {
+			pauses = make([][]db.Pause, len(sessions))
+			for i, s := range sessions {
+				if pauses[i], err = database.GetPauses(s.ID); err != nil {
+					fmt.Fprintf(os.Stderr, "%v\n", err)
+					os.Exit(1)
+				}
+			}
+		}
+
+		if exportAnonymize {
+			mode := anonymize.Strip
+			if exportHash {
+				mode = anonymize.Hash
+			}
+			anonymizer, err := anonymize.New(mode, []byte(exportSalt))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			sessions = anonymizer.Sessions(sessions)
+		}
+
+		var data []byte
+		switch exportOutput {
+		case "org":
+			byID := make(map[int64][]db.Pause, len(sessions))
+			for i, s := range sessions {
+				byID[s.ID] = pauses[i]
+			}
+			fmt.Print(string(org.Export(sessions, byID)))
+			return
+		case "opf":
+			data, err = opf.ExportToJSON(sessions)
+		default:
+			// Times stay in each session's own offset
+			data, err = sessionsJSON(sessions, nil)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error exporting sessions: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+	},
+}
+
+// runExportAll writes a backup of all sessions and settings for import
+// to restore
+func runExportAll(cmd *cobra.Command) {
+	for _, flag := range []string{"from", "to", "anonymize", "hash", "salt"} {
+		if cmd.Flags().Changed(flag) {
+			fmt.Fprintf(os.Stderr, "--%s cannot be used with --all, which backs up everything\n", flag)
+			os.Exit(1)
+		}
+	}
+	ext := map[string]string{"json": ".json", "tar.gz": ".tar.gz"}[exportOutput]
+	if ext == "" {
+		fmt.Fprintf(os.Stderr, "Invalid output format %q for --all: must be json or tar.gz\n", exportOutput)
+		os.Exit(1)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	path := utils.ExpandPath(exportFile)
+	if path == "" {
+		dir := utils.ExpandPath(cfg.DataPaths.OPFExport)
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating export directory: %v\n", err)
+			os.Exit(1)
+		}
+		path = filepath.Join(dir, "pomodoro-backup-"+app.now().Format("20060102-150405")+ext)
+	}
+
+	database, err := openInternalDB()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	defer func() {
+		if err := database.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+		}
+	}()
+
+	if exportOutput == "tar.gz" {
+		loc, err := stateLocations()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		manifest, err := backup.Export(path, database, loc, appVersion)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error exporting: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Exported %d files to %s\n", len(manifest.Files), path)
+		return
+	}
+
+	sessions, err := database.GetSessionsByDateRange(time.Time{}, app.now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting sessions: %v\n", err)
+		os.Exit(1)
+	}
+	configPath, err := config.Path()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	doc, err := backup.NewDocument(sessions, configPath, appVersion)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error exporting: %v\n", err)
+		os.Exit(1)
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error marshaling to JSON: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Exported %d sessions and your config to %s\n", len(doc.Sessions), path)
+}
+
+// runScheduledExportNow writes the configured scheduled exports at once
+func runScheduledExportNow(cmd *cobra.Command) {
+	for _, flag := range []string{"from", "to", "output", "anonymize", "hash", "salt", "all", "file"} {
+		if cmd.Flags().Changed(flag) {
+			fmt.Fprintf(os.Stderr, "--%s cannot be used with --scheduled, which writes what exports configures\n", flag)
+			os.Exit(1)
+		}
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	database, err := openDB()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	defer func() {
+		if err := database.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+		}
+	}()
+
+	written, err := runScheduledExport(cfg, database, app.now())
+	for _, path := range written {
+		fmt.Printf("Exported to %s\n", path)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error exporting: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringVar(&exportFrom, "from", "", "Export sessions from this date (YYYY-MM-DD, yesterday, monday, 7d, ...)")
+	exportCmd.Flags().StringVar(&exportTo, "to", "", "Export sessions up to and including this date (YYYY-MM-DD, yesterday, 7d, ...)")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "json", "Output format (json, opf, org; json or tar.gz with --all)")
+	exportCmd.Flags().BoolVar(&exportAnonymize, "anonymize", false, "Remove descriptions, tags, and identifiers while keeping timing")
+	exportCmd.Flags().BoolVar(&exportHash, "hash", false, "With --anonymize, replace descriptions and tags with salted hashes")
+	exportCmd.Flags().StringVar(&exportSalt, "salt", "", "Salt for --hash (random when omitted)")
+	exportCmd.Flags().BoolVar(&exportAll, "all", false, "Back up all sessions and settings for 'pomodoro import' to restore")
+	exportCmd.Flags().BoolVar(&exportScheduled, "scheduled", false, "Write the exports configured under exports now")
+	exportCmd.Flags().StringVar(&exportFile, "file", "", "With --all, where to write the backup (default: in paths.opf_export)")
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/backup"
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/daemon"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/exports"
+	"github.com/ethan-k/pomodoro-cli/internal/goals"
+	"github.com/ethan-k/pomodoro-cli/internal/opf"
+	"github.com/ethan-k/pomodoro-cli/internal/secrets"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+// defaultExportAt is when exports run when exports.at is invalid
+const defaultExportAt = 2 * time.Hour
+
+// applyExports turns scheduled exports on or off from the exports config
+func applyExports(d *daemon.Daemon, cfg *config.Config, logger *log.Logger) {
+	period, err := exports.Period(cfg.Exports.Schedule)
+	if err != nil {
+		logger.Printf("%v, not exporting", err)
+	}
+	if period == 0 {
+		d.EnableExports(nil, nil)
+		return
+	}
+
+	at, err := goals.ParseTimeOfDay(cfg.Exports.At)
+	if err != nil {
+		logger.Printf("invalid exports.at %q, using 02:00", cfg.Exports.At)
+		at = defaultExportAt
+	}
+	dir := utils.ExpandPath(cfg.DataPaths.OPFExport)
+
+	due := func(now time.Time) bool {
+		last, err := exports.LastRun(dir)
+		if err != nil {
+			logger.Printf("error reading %s: %v", dir, err)
+			return false
+		}
+		return exports.Due(period, at, last, now)
+	}
+	d.EnableExports(due, func(database db.DB, now time.Time) error {
+		written, err := runScheduledExport(cfg, database, now)
+		notifyExport(cfg, written, err, logger)
+		return err
+	})
+	logger.Printf("exporting %s at %s to %s", cfg.Exports.Schedule, cfg.Exports.At, dir)
+}
+
+// runScheduledExport writes the exports the config asks for and returns
+// the files written
+func runScheduledExport(cfg *config.Config, database db.DB, now time.Time) ([]string, error) {
+	job, err := exportJob(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return job.Run(database, now)
+}
+
+// exportJob builds the scheduled export from the config, reading the S3
+// credentials each time so that changing them needs no restart
+func exportJob(cfg *config.Config) (*exports.Job, error) {
+	job := &exports.Job{
+		Dir:  utils.ExpandPath(cfg.DataPaths.OPFExport),
+		Keep: cfg.Exports.Keep,
+	}
+	for _, name := range cfg.Exports.Formats {
+		f, err := exportFormat(name)
+		if err != nil {
+			return nil, err
+		}
+		job.Formats = append(job.Formats, f)
+	}
+
+	if s3 := cfg.Exports.S3; s3.Bucket != "" {
+		keyID, secret, err := s3Credentials(cfg)
+		if err != nil {
+			return nil, err
+		}
+		job.Upload = &exports.S3{
+			Bucket:          s3.Bucket,
+			Region:          s3.Region,
+			Endpoint:        s3.Endpoint,
+			Prefix:          s3.Prefix,
+			AccessKeyID:     keyID,
+			SecretAccessKey: secret,
+		}
+	}
+	return job, nil
+}
+
+// exportFormat returns the format exports.formats calls name
+func exportFormat(name string) (exports.Format, error) {
+	switch name {
+	case "opf":
+		return exports.Format{Name: name, Ext: ".opf.json", Write: func(w io.Writer, sessions []db.PomodoroSession) error {
+			data, err := opf.ExportToJSON(sessions)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(data)
+			return err
+		}}, nil
+	case "csv":
+		return exports.Format{Name: name, Ext: ".csv", Write: func(w io.Writer, sessions []db.PomodoroSession) error {
+			return writeSessionsCSV(w, sessions, time.Local)
+		}}, nil
+	case "backup":
+		return exports.Format{Name: name, Ext: ".backup.json", Write: func(w io.Writer, sessions []db.PomodoroSession) error {
+			configPath, err := config.Path()
+			if err != nil {
+				return err
+			}
+			doc, err := backup.NewDocument(sessions, configPath, appVersion)
+			if err != nil {
+				return err
+			}
+			data, err := json.MarshalIndent(doc, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(append(data, '\n'))
+			return err
+		}}, nil
+	}
+	return exports.Format{}, fmt.Errorf("invalid export format %q: must be opf, csv, or backup", name)
+}
+
+// s3Credentials returns the key ID and secret exports are uploaded with,
+// from the environment or else the secrets store
+func s3Credentials(cfg *config.Config) (string, string, error) {
+	keyID, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if keyID != "" && secret != "" {
+		return keyID, secret, nil
+	}
+
+	store, err := secrets.Open(cfg.Secrets.Backend, utils.ExpandPath(cfg.Secrets.File))
+	if err != nil {
+		return "", "", err
+	}
+	if keyID, err = store.Get("s3.access_key_id"); err == nil {
+		secret, err = store.Get("s3.secret_access_key")
+	}
+	if errors.Is(err, secrets.ErrNotFound) {
+		return "", "", fmt.Errorf("no S3 credentials: set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, or 'pomodoro secrets set s3.access_key_id' and s3.secret_access_key")
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return keyID, secret, nil
+}
+
+// notifyExport announces a scheduled export as exports.notify asks
+func notifyExport(cfg *config.Config, written []string, exportErr error, logger *log.Logger) {
+	var title, message string
+	switch {
+	case exportErr != nil && cfg.Exports.Notify != "never":
+		title, message = "Export failed", exportErr.Error()
+	case exportErr == nil && cfg.Exports.Notify == "always":
+		names := make([]string, len(written))
+		for i, path := range written {
+			names[i] = filepath.Base(path)
+		}
+		title, message = "Export written", strings.Join(names, ", ")
+	default:
+		return
+	}
+	if err := app.notify(title, message); err != nil {
+		logger.Printf("error sending notification: %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/daemon"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+// defaultExtension is how much extend adds when no duration is given
+const defaultExtension = 5 * time.Minute
+
+// extendCmd lengthens the active session
+var extendCmd = &cobra.Command{
+	Use:   "extend [duration]",
+	Short: "Adds time to the current active session",
+	Long: `Adds time to the currently active Pomodoro or break session, 5 minutes
+unless a duration is given. The session's end and its planned length both
+move, so status, the running timer, and history all see the longer session.
+
+Example:
+  pomodoro extend
+  pomodoro extend 10m`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		by := defaultExtension
+		if len(args) == 1 {
+			d, err := utils.ParseHumanDuration(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid duration: %v\n", err)
+				os.Exit(1)
+			}
+			by = d
+		}
+		if err := utils.ValidateDuration(by); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid duration: %v\n", err)
+			os.Exit(1)
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		session, err := activeSession(database)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting active session: %v\n", err)
+			os.Exit(1)
+		}
+		if session == nil {
+			fmt.Println("No active session to extend.")
+			return
+		}
+
+		total := time.Duration(session.DurationSec)*time.Second + by
+		if err := utils.ValidateDuration(total); err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot extend session to %s: %v\n", total, err)
+			os.Exit(1)
+		}
+
+		// Extend through the daemon so it moves its timer at once
+		if resp, ok, err := daemonSend(daemon.Request{Action: daemon.ActionExtend, Extend: by}); ok {
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error extending session: %v\n", err)
+				os.Exit(1)
+			}
+			if resp.Session != nil {
+				session = resp.Session
+			}
+		} else {
+			if err := database.ExtendSession(session.ID, by); err != nil {
+				fmt.Fprintf(os.Stderr, "Error extending session: %v\n", err)
+				os.Exit(1)
+			}
+			if session, err = database.GetSessionByID(session.ID); err != nil {
+				fmt.Fprintf(os.Stderr, "Error getting session: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		// A paused session's clock stopped at the pause
+		remaining := time.Until(session.EndTime)
+		if session.IsPaused && session.PausedAt != nil {
+			remaining = session.EndTime.Sub(*session.PausedAt)
+		}
+
+		if jsonOutput {
+			printJSON(struct {
+				ID           int64     `json:"id"`
+				Description  string    `json:"description"`
+				ExtendedBy   int64     `json:"extended_by_secs"`
+				EndTime      time.Time `json:"end_time"`
+				DurationSec  int64     `json:"duration_secs"`
+				RemainingSec int64     `json:"remaining_secs"`
+			}{session.ID, session.Description, int64(by / time.Second), session.EndTime, session.DurationSec, int64(remaining / time.Second)})
+			return
+		}
+
+		fmt.Printf("%sExtended session '%s' by %s\n", icon("⏩ "), session.Description, utils.FormatDurationLong(by))
+		if session.IsPaused {
+			decorf("%s left once resumed.\n", utils.FormatDurationLong(remaining))
+			return
+		}
+		decorf("Now ends at %s, %s left.\n", session.EndTime.Local().Format("15:04"), utils.FormatDurationLong(remaining))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(extendCmd)
+}
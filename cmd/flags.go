@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+// humanDuration is a duration flag that accepts what people type, such as
+// "25", "1h30", or "25 min", as well as Go durations
+type humanDuration time.Duration
+
+func (d *humanDuration) Set(s string) error {
+	v, err := utils.ParseHumanDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = humanDuration(v)
+	return nil
+}
+
+func (d *humanDuration) String() string {
+	// pflag leaves a "0" default out of the help, as it does for Go durations
+	if *d == 0 {
+		return "0"
+	}
+	return time.Duration(*d).String()
+}
+
+func (d *humanDuration) Type() string {
+	return "duration"
+}
+
+// humanDurationVarP defines a duration flag parsed with utils.ParseHumanDuration
+func humanDurationVarP(fs *pflag.FlagSet, p *time.Duration, name, shorthand string, value time.Duration, usage string) {
+	*p = value
+	fs.VarP((*humanDuration)(p), name, shorthand, usage)
+}
@@ -0,0 +1,390 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/goals"
+	"github.com/ethan-k/pomodoro-cli/internal/model"
+	"github.com/ethan-k/pomodoro-cli/internal/trace"
+)
+
+var (
+	goalsSetMonthly int
+	goalsEdit       bool
+)
+
+// goalBarWidth is the width of the progress bars in goals output
+const goalBarWidth = 20
+
+// goalsCmd shows progress toward the daily and weekly goals
+var goalsCmd = &cobra.Command{
+	Use:   "goals",
+	Short: "Shows progress toward your daily, weekly, and monthly goals",
+	Long: `Shows how many pomodoros you have done today, this week, and this month
+against your goals, and with goals.daily_minutes or goals.weekly_minutes set, how many
+minutes you have focused against those. Focus minutes leave out pauses and
+include the time cancelled pomodoros ran, so 50m and 15m sessions count for
+what they were.
+
+The monthly goal is goals.monthly_count, set with --set-monthly, or when
+that is 0 the weekly goal spread over the days of the month, so a 31-day
+month asks for more than February. Until it is reached, goals shows how
+many pomodoros a day the rest of the month needs.
+
+With goals.carry_over turned on, pomodoros missed in a week are added to the
+next week's goal as debt, and extra pomodoros are taken off it as credit, so
+the weekly goal becomes a rolling target.
+
+Goals for a tag, set under goals.tags in the config file, count only the
+pomodoros with that tag:
+
+  goals:
+    tags:
+      writing: {daily: 3, weekly: 12}
+
+--edit opens a form with the daily, weekly, and monthly pomodoro targets and
+the focus-minute targets; enter saves them to the config file and shows the
+goals with the new targets.
+
+Below the goals are your records: the current streak of days with a
+pomodoro, the longest streak, and the day with the most pomodoros. They are
+kept as days finish, so pruning old sessions does not lose them.
+
+Today's wellness counters, logged with 'pomodoro count', are listed below
+the goals.
+
+Example:
+  pomodoro goals
+  pomodoro goals --set-monthly 160
+  pomodoro goals --edit
+  pomodoro config goals.daily_minutes 180
+  pomodoro config goals.carry_over true`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		if cmd.Flags().Changed("set-monthly") {
+			setMonthlyGoal(goalsSetMonthly)
+		}
+		if goalsEdit && !editGoals() {
+			fmt.Println("Goals left unchanged.")
+			return
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		status, carry, err := goalStatus(database, app.now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting goal status: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Counters are an extra; goals still show without a config
+		var counters []counterStatus
+		if cfg, err := config.LoadConfig(); err == nil {
+			if counters, err = todayCounters(database, cfg, app.now()); err != nil {
+				fmt.Fprintf(os.Stderr, "Error getting counters: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		records, err := goals.GetRecords(database, app.now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting records: %v\n", err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			out := newGoalsJSON(status, carry, counters)
+			out.Records = newRecordsJSON(records)
+			printJSON(out)
+			return
+		}
+
+		fmt.Println("Goals:")
+		fmt.Println("------")
+		fmt.Printf("Today:      %3d/%-3d %s\n", status.DailyCompleted, status.DailyGoal, goalBar(status.DailyCompleted, status.DailyGoal))
+		fmt.Printf("This week:  %3d/%-3d %s\n", status.WeeklyCompleted, status.WeeklyGoal, goalBar(status.WeeklyCompleted, status.WeeklyGoal))
+		printMonthlyGoal(status)
+		printMinutesGoals(status)
+		printTagGoals(status.Tags)
+
+		switch {
+		case carry == nil:
+		case carry.Weeks == 0:
+			fmt.Printf("\nCarry-over counts from the week of %s; the first balance comes next week.\n", carry.Since)
+		case carry.Balance < 0:
+			fmt.Printf("\nThis week's goal is %d plus %d carried over since the week of %s.\n", carry.Goal, -carry.Balance, carry.Since)
+		case carry.Balance > 0:
+			fmt.Printf("\nThis week's goal is %d less %d credit earned since the week of %s.\n", carry.Goal, carry.Balance, carry.Since)
+		default:
+			fmt.Printf("\nYou are even with your goal since the week of %s.\n", carry.Since)
+		}
+
+		printRecords(records)
+
+		if len(counters) > 0 {
+			fmt.Println("\nWellness today:")
+			printCounters(counters)
+		}
+	},
+}
+
+// printMonthlyGoal prints progress toward the monthly goal, with the pomodoros
+// a day still needed to reach it
+func printMonthlyGoal(status *config.GoalStatus) {
+	if status.MonthlyGoal <= 0 {
+		return
+	}
+	fmt.Printf("This month: %3d/%-3d %s\n", status.MonthlyCompleted, status.MonthlyGoal, goalBar(status.MonthlyCompleted, status.MonthlyGoal))
+	if perDay := status.MonthlyPerDay(); perDay > 0 {
+		decorf("            %.1f a day to reach it in the %d day(s) left\n", perDay, status.MonthDaysLeft)
+	}
+}
+
+// printRecords prints the current and longest streaks and the best day
+func printRecords(records goals.Records) {
+	if records.BestDay.Pomodoros == 0 {
+		return
+	}
+	fmt.Println("\nRecords:")
+	fmt.Printf("Streak:     %d day(s), longest %d\n", records.Streak, records.BestStreak)
+	fmt.Printf("Best day:   %d pomodoros on %s\n", records.BestDay.Pomodoros, records.BestDay.Date)
+}
+
+// setMonthlyGoal saves the monthly goal to the config file, 0 to follow the
+// weekly goal
+func setMonthlyGoal(count int) {
+	if count < 0 {
+		fmt.Fprintln(os.Stderr, "Invalid monthly goal: must be a whole number, 0 to follow the weekly goal")
+		os.Exit(1)
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.Goals.MonthlyCount = count
+	if err := config.SaveConfig(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// editGoals opens a form to change the goal targets and saves them to the
+// config file. It returns false when the form is left without saving.
+func editGoals() bool {
+	if jsonOutput || !isInteractive() {
+		fmt.Fprintln(os.Stderr, "Editing goals needs an interactive terminal; use 'pomodoro config goals.daily_count 8' and the like instead")
+		os.Exit(1)
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	targets := []struct {
+		label string
+		value *int
+	}{
+		{"Pomodoros a day", &cfg.Goals.DailyCount},
+		{"Pomodoros a week", &cfg.Goals.WeeklyCount},
+		{"Pomodoros a month", &cfg.Goals.MonthlyCount},
+		{"Focus minutes a day", &cfg.Goals.DailyMinutes},
+		{"Focus minutes a week", &cfg.Goals.WeeklyMinutes},
+	}
+	fields := make([]model.GoalField, 0, len(targets))
+	for _, t := range targets {
+		fields = append(fields, model.GoalField{Label: t.label, Value: *t.value})
+	}
+	form := model.NewGoalFormModel(fields, func(values []int) error {
+		for i, t := range targets {
+			*t.value = values[i]
+		}
+		return config.SaveConfig(cfg)
+	})
+
+	wait := trace.Begin(trace.Wait, "goal form")
+	final, err := tea.NewProgram(form).Run()
+	wait()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error running UI: %v\n", err)
+		os.Exit(1)
+	}
+	done, ok := final.(model.GoalFormModel)
+	return ok && done.Saved()
+}
+
+// printMinutesGoals prints progress toward the minutes of focus goals that
+// are set
+func printMinutesGoals(status *config.GoalStatus) {
+	if status.DailyMinutesGoal <= 0 && status.WeeklyMinutesGoal <= 0 {
+		return
+	}
+	fmt.Println("\nFocus minutes:")
+	if goal := status.DailyMinutesGoal; goal > 0 {
+		done := int(status.DailyFocus.Minutes())
+		fmt.Printf("  Today:    %4d/%-4d %s\n", done, goal, goalBar(done, goal))
+	}
+	if goal := status.WeeklyMinutesGoal; goal > 0 {
+		done := int(status.WeeklyFocus.Minutes())
+		fmt.Printf("  This week:%4d/%-4d %s\n", done, goal, goalBar(done, goal))
+	}
+}
+
+// printTagGoals prints progress toward each tag goal, leaving out the daily
+// or weekly line of a tag without that goal
+func printTagGoals(tags []config.TagGoalStatus) {
+	for _, t := range tags {
+		fmt.Printf("\n%s:\n", t.Tag)
+		if t.DailyGoal > 0 {
+			fmt.Printf("  Today:    %3d/%-3d %s\n", t.DailyCompleted, t.DailyGoal, goalBar(t.DailyCompleted, t.DailyGoal))
+		}
+		if t.WeeklyGoal > 0 {
+			fmt.Printf("  This week:%3d/%-3d %s\n", t.WeeklyCompleted, t.WeeklyGoal, goalBar(t.WeeklyCompleted, t.WeeklyGoal))
+		}
+	}
+}
+
+// goalCarryOver is the weekly carry-over along with the week it counts from
+type goalCarryOver struct {
+	*goals.CarryOver
+	Since string
+}
+
+// goalStatus returns progress toward the goals. With carry-over turned on,
+// the weekly goal is this week's rolling target and the carry-over is
+// returned as well; otherwise it is nil.
+func goalStatus(database db.DB, now time.Time) (*config.GoalStatus, *goalCarryOver, error) {
+	status, err := config.GetCurrentGoalStatus(database)
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil || !cfg.Goals.CarryOver {
+		return status, nil, nil
+	}
+
+	// Without a valid start date, carry-over starts this week
+	since, err := time.ParseInLocation("2006-01-02", cfg.Goals.CarryOverSince, now.Location())
+	if err != nil {
+		since = now
+	}
+	carry, err := goals.ComputeCarryOver(database, now, cfg.Goals.WeeklyCount, since)
+	if err != nil {
+		return nil, nil, err
+	}
+	status.WeeklyGoal = carry.Target()
+	return status, &goalCarryOver{CarryOver: carry, Since: goals.WeekStart(since).Format("2006-01-02")}, nil
+}
+
+// carryOverJSON is the JSON representation of the weekly carry-over
+type carryOverJSON struct {
+	Since      string `json:"since"`
+	Weeks      int    `json:"weeks"`
+	Balance    int    `json:"balance"` // Positive when ahead of the goal
+	WeeklyGoal int    `json:"base_weekly_goal"`
+}
+
+// goalsJSON is the JSON representation of goal progress
+type goalsJSON struct {
+	DailyGoal       int             `json:"daily_goal"`
+	DailyCompleted  int             `json:"daily_completed"`
+	WeeklyGoal      int             `json:"weekly_goal"`
+	WeeklyCompleted int             `json:"weekly_completed"`
+	MonthlyGoal     int             `json:"monthly_goal"`
+	MonthlyDone     int             `json:"monthly_completed"`
+	MonthlyPerDay   float64         `json:"monthly_per_day"`    // Pomodoros a day still needed, today included
+	DailyMinutes    int             `json:"daily_minutes_goal"` // 0 for no goal
+	DailyFocus      float64         `json:"daily_focus_minutes"`
+	WeeklyMinutes   int             `json:"weekly_minutes_goal"` // 0 for no goal
+	WeeklyFocus     float64         `json:"weekly_focus_minutes"`
+	Tags            []tagGoalJSON   `json:"tags,omitempty"`
+	CarryOver       *carryOverJSON  `json:"carry_over,omitempty"`
+	Records         *recordsJSON    `json:"records,omitempty"`
+	Counters        []counterStatus `json:"counters,omitempty"`
+}
+
+// recordsJSON is the JSON representation of streaks and the best day
+type recordsJSON struct {
+	Streak       int    `json:"streak"`
+	BestStreak   int    `json:"best_streak"`
+	BestDay      string `json:"best_day,omitempty"`
+	BestDayCount int    `json:"best_day_pomodoros"`
+}
+
+// newRecordsJSON converts records to their JSON representation
+func newRecordsJSON(records goals.Records) *recordsJSON {
+	return &recordsJSON{
+		Streak:       records.Streak,
+		BestStreak:   records.BestStreak,
+		BestDay:      records.BestDay.Date,
+		BestDayCount: records.BestDay.Pomodoros,
+	}
+}
+
+// tagGoalJSON is the JSON representation of progress toward a tag goal
+type tagGoalJSON struct {
+	Tag             string `json:"tag"`
+	DailyGoal       int    `json:"daily_goal"`
+	DailyCompleted  int    `json:"daily_completed"`
+	WeeklyGoal      int    `json:"weekly_goal"`
+	WeeklyCompleted int    `json:"weekly_completed"`
+}
+
+// newGoalsJSON converts goal progress to its JSON representation
+func newGoalsJSON(status *config.GoalStatus, carry *goalCarryOver, counters []counterStatus) goalsJSON {
+	out := goalsJSON{
+		DailyGoal:       status.DailyGoal,
+		DailyCompleted:  status.DailyCompleted,
+		WeeklyGoal:      status.WeeklyGoal,
+		WeeklyCompleted: status.WeeklyCompleted,
+		MonthlyGoal:     status.MonthlyGoal,
+		MonthlyDone:     status.MonthlyCompleted,
+		MonthlyPerDay:   status.MonthlyPerDay(),
+		DailyMinutes:    status.DailyMinutesGoal,
+		DailyFocus:      status.DailyFocus.Minutes(),
+		WeeklyMinutes:   status.WeeklyMinutesGoal,
+		WeeklyFocus:     status.WeeklyFocus.Minutes(),
+		Counters:        counters,
+	}
+	for _, t := range status.Tags {
+		out.Tags = append(out.Tags, tagGoalJSON(t))
+	}
+	if carry != nil {
+		out.CarryOver = &carryOverJSON{Since: carry.Since, Weeks: carry.Weeks, Balance: carry.Balance, WeeklyGoal: carry.Goal}
+	}
+	return out
+}
+
+// goalBar draws progress toward a goal as a bar of blocks
+func goalBar(done, goal int) string {
+	if goal <= 0 {
+		return ""
+	}
+	filled := min(goalBarWidth, done*goalBarWidth/goal)
+	return strings.Repeat("█", filled) + strings.Repeat("░", goalBarWidth-filled)
+}
+
+func init() {
+	rootCmd.AddCommand(goalsCmd)
+
+	goalsCmd.Flags().BoolVar(&goalsEdit, "edit", false, "Change the goal targets in a form")
+	goalsCmd.Flags().IntVar(&goalsSetMonthly, "set-monthly", 0, "Save a monthly goal of this many pomodoros (0 follows the weekly goal)")
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func TestGoalsMinutes(t *testing.T) {
+	// Goal status reads the clock itself, so the sessions are relative to it
+	end := time.Now().Add(-time.Minute)
+	session := func(id int64, minutes int, status string, wasBreak bool) db.PomodoroSession {
+		start := end.Add(-time.Duration(minutes) * time.Minute)
+		return db.PomodoroSession{ID: id, StartTime: start, EndTime: end, DurationSec: int64(minutes * 60), Status: status, WasBreak: wasBreak}
+	}
+	paused := session(1, 50, db.StatusCompleted, false)
+	paused.TotalPausedDuration = 5 * 60
+	sessions := []db.PomodoroSession{
+		paused,
+		session(2, 15, db.StatusCompleted, false),
+		session(3, 10, db.StatusCancelled, false), // Counts for its minutes, not as a pomodoro
+		session(4, 5, db.StatusCompleted, true),
+	}
+	a := newTestApp(t, &mockDB{
+		GetSessionsByDateRangeFunc: func(time.Time, time.Time, ...string) ([]db.PomodoroSession, error) {
+			return sessions, nil
+		},
+	}, time.Now())
+
+	path := filepath.Join(os.Getenv("HOME"), ".config", "pomodoro", "config.yml")
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("goals:\n  daily_count: 4\n  daily_minutes: 120\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var got goalsJSON
+	if err := json.Unmarshal([]byte(a.run(t, "goals", "--json")), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.DailyCompleted != 2 || got.DailyMinutes != 120 || got.DailyFocus != 70 || got.WeeklyMinutes != 0 {
+		t.Errorf("Expected 2 pomodoros and 70 of 120 minutes, got %+v", got)
+	}
+
+	out := a.run(t, "goals")
+	if !strings.Contains(out, "Focus minutes:\n  Today:      70/120 ") || strings.Contains(out, "  This week:  70") {
+		t.Errorf("Expected only the daily minutes goal, got:\n%s", out)
+	}
+}
+
+func TestGoalsMonthly(t *testing.T) {
+	goals := config.GoalConfig{WeeklyCount: 40}
+	feb := time.Date(2026, time.February, 10, 9, 0, 0, 0, time.UTC)
+	mar := time.Date(2026, time.March, 10, 9, 0, 0, 0, time.UTC)
+	if got := goals.MonthlyGoal(feb); got != 160 {
+		t.Errorf("Expected 160 pomodoros in February, got %d", got)
+	}
+	if got := goals.MonthlyGoal(mar); got != 177 {
+		t.Errorf("Expected 177 pomodoros in March, got %d", got)
+	}
+
+	end := time.Now().Add(-time.Minute)
+	a := newTestApp(t, &mockDB{
+		GetSessionsByDateRangeFunc: func(time.Time, time.Time, ...string) ([]db.PomodoroSession, error) {
+			return []db.PomodoroSession{
+				{ID: 1, StartTime: end.Add(-25 * time.Minute), EndTime: end, Status: db.StatusCompleted},
+				{ID: 2, StartTime: end.Add(-25 * time.Minute), EndTime: end, Status: db.StatusCancelled},
+			}, nil
+		},
+	}, time.Now())
+
+	var got goalsJSON
+	if err := json.Unmarshal([]byte(a.run(t, "goals", "--set-monthly", "101", "--json")), &got); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	left := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, now.Location()).Day() - now.Day() + 1
+	if got.MonthlyGoal != 101 || got.MonthlyDone != 1 || got.MonthlyPerDay != 100/float64(left) {
+		t.Errorf("Expected 1 of 101 with 100 over %d day(s) to go, got %+v", left, got)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Goals.MonthlyCount != 101 {
+		t.Errorf("Expected the monthly goal to be saved, got %d", cfg.Goals.MonthlyCount)
+	}
+}
+
+func TestGoalsVacation(t *testing.T) {
+	var from, to time.Time
+	a := newTestApp(t, &mockDB{
+		AddVacationFunc: func(f, tt time.Time) (int64, error) {
+			from, to = f, tt
+			return 3, nil
+		},
+	}, time.Date(2026, time.July, 20, 9, 0, 0, 0, time.Local))
+
+	var got vacationJSON
+	if err := json.Unmarshal([]byte(a.run(t, "goals", "vacation", "--from", "2026-08-05", "--to", "2026-08-16", "--json")), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != (vacationJSON{ID: 3, From: "2026-08-05", To: "2026-08-16"}) {
+		t.Errorf("Expected vacation 3 from 2026-08-05 to 2026-08-16, got %+v", got)
+	}
+	if from.Format("2006-01-02") != "2026-08-05" || to.Format("2006-01-02") != "2026-08-16" {
+		t.Errorf("Expected the vacation to be stored as given, got %v to %v", from, to)
+	}
+
+	// A single day off without --to
+	a.run(t, "goals", "vacation", "--from", "2026-09-01")
+	if !from.Equal(to) || from.Format("2006-01-02") != "2026-09-01" {
+		t.Errorf("Expected a one-day vacation on 2026-09-01, got %v to %v", from, to)
+	}
+}
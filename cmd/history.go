@@ -1,16 +1,25 @@
 package cmd
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/ethan-k/pomodoro-cli/internal/db"
 	"github.com/ethan-k/pomodoro-cli/internal/opf"
+	"github.com/ethan-k/pomodoro-cli/internal/stats"
+	"github.com/ethan-k/pomodoro-cli/internal/term"
+	"github.com/ethan-k/pomodoro-cli/internal/trace"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
 )
 
 var (
@@ -22,6 +31,12 @@ var (
 	historyFormat string
 	historyOutput string
 	historyTags   []string
+	historyTZ     string
+	historyTask   int64
+	historyGroup  string
+
+	historyIncludeCancelled bool
+	historySummaryOnly      bool
 )
 
 // historyCmd represents the history command
@@ -31,18 +46,95 @@ var historyCmd = &cobra.Command{
 	Long: `Shows your Pomodoro session history.
 
 You can filter by date range, limit the number of results, and specify the output format.
+Cancelled and abandoned sessions are left out unless --include-cancelled is given.
+Dates may be YYYY-MM-DD, today, yesterday, a weekday, or a number of days or
+weeks ago such as 7d or 2w.
+
+--group-by totals the pomodoros by day, week (starting Monday), tag, or
+project (the task each counts toward): how many, the focus time, and the
+share completed. A pomodoro with several tags counts toward each. The
+completion rate counts cancelled and abandoned pomodoros whether or not they
+are listed. --summary prints only the totals, without listing every
+session; --limit only shortens the list.
 
 Examples:
   pomodoro history --today
   pomodoro history --week
   pomodoro history --from 2025-04-01 --to 2025-04-19
   pomodoro history --tags coding,writing
+  pomodoro history --week --task 3
+  pomodoro history --week --include-cancelled
   pomodoro history --output opf > pomodoros.json
-  pomodoro history --output json --limit 10`,
+  pomodoro history --week --output csv > week.csv
+  pomodoro history --output json --limit 10
+  pomodoro history --week --timezone America/New_York
+  pomodoro history --timezone session
+  pomodoro history --week --format "%s %l %d [%t]"
+  pomodoro history --week --format '{{.Start.Format "Mon 15:04"}} {{.Duration}} {{join .Tags ","}}'
+  pomodoro history --from 30d --group-by week --summary
+  pomodoro history --week --group-by tag --output json
+
+--format prints one line per session and nothing else, for scripts to read
+without parsing JSON. It takes placeholders, or a Go template when it
+contains {{.
+
+Format placeholders:
+  %i  - Session reference
+  %s  - Start date and time
+  %e  - End time
+  %d  - Description
+  %l  - Length, from start to end
+  %D  - Duration focused, excluding pauses
+  %t  - Tags
+  %b  - Type (pomodoro or break)
+  %S  - Status (completed, cancelled, or abandoned)
+  %%  - A literal %
+
+Template fields: .ID, .Ref, .Start and .End (times), .Description, .Length,
+.Duration, and .Planned (durations), .Tags (a list; join it with
+{{join .Tags ","}}), .Break, .Status, and .TaskID.`,
 	Aliases: []string{"h"},
-	Run: func(_ *cobra.Command, _ []string) {
+	Run: func(cmd *cobra.Command, _ []string) {
+		// --json is --output json, as with every other command
+		if jsonOutput {
+			if cmd.Flags().Changed("output") && historyOutput != "json" {
+				fmt.Fprintf(os.Stderr, "--json cannot be used with --output %s\n", historyOutput)
+				os.Exit(1)
+			}
+			historyOutput = "json"
+		}
+
+		loc, err := parseTimezone(historyTZ)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		if historyGroup != "" && !slices.Contains(stats.Groupings, historyGroup) {
+			fmt.Fprintf(os.Stderr, "Invalid --group-by %q: must be day, week, tag, or project\n", historyGroup)
+			os.Exit(1)
+		}
+		if historyOutput == "opf" && (historyGroup != "" || historySummaryOnly) {
+			fmt.Fprintln(os.Stderr, "--group-by and --summary cannot be used with --output opf")
+			os.Exit(1)
+		}
+		if historyOutput == "csv" && historySummaryOnly && historyGroup == "" {
+			fmt.Fprintln(os.Stderr, "--summary with --output csv needs --group-by")
+			os.Exit(1)
+		}
+		var format historyFormatter
+		if historyFormat != "" {
+			if historyOutput != "text" || historyGroup != "" || historySummaryOnly {
+				fmt.Fprintln(os.Stderr, "--format prints sessions as text; it cannot be used with --output, --group-by, or --summary")
+				os.Exit(1)
+			}
+			if format, err = newHistoryFormatter(historyFormat); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+		}
+
 		// Connect to database
-		database, err := db.NewDB()
+		database, err := openDB()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "%v\n", err)
 			os.Exit(1)
@@ -56,7 +148,7 @@ Examples:
 		var sessions []db.PomodoroSession
 
 		// Determine date range
-		now := time.Now()
+		now := app.now()
 		var startDate, endDate time.Time
 
 		if historyToday {
@@ -75,7 +167,7 @@ Examples:
 		} else if historyFrom != "" || historyTo != "" {
 			if historyFrom != "" {
 				var parseErr error
-				startDate, parseErr = time.Parse("2006-01-02", historyFrom)
+				startDate, parseErr = utils.ParseDate(historyFrom, now)
 				if parseErr != nil {
 					fmt.Fprintf(os.Stderr, "Error parsing from date: %v\n", parseErr)
 					os.Exit(1)
@@ -87,7 +179,7 @@ Examples:
 
 			if historyTo != "" {
 				var parseErr error
-				endDate, parseErr = time.Parse("2006-01-02", historyTo)
+				endDate, parseErr = utils.ParseDate(historyTo, now)
 				if parseErr != nil {
 					fmt.Fprintf(os.Stderr, "Error parsing to date: %v\n", parseErr)
 					os.Exit(1)
@@ -103,23 +195,34 @@ Examples:
 			endDate = startDate.Add(24 * time.Hour)
 		}
 
-		// Get sessions
-		sessions, err = database.GetSessionsByDateRange(startDate, endDate)
+		// Get sessions, with any of the tags if specified
+		sessions, err = database.GetSessionsByDateRange(startDate, endDate, historyTags...)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error getting sessions: %v\n", err)
 			os.Exit(1)
 		}
 
-		// Filter by tags if specified
-		if len(historyTags) > 0 {
+		// Filter by task if specified
+		if historyTask != 0 {
 			var filteredSessions []db.PomodoroSession
 			for _, session := range sessions {
-				// Check if session has any of the specified tags
-				for _, tag := range historyTags {
-					if strings.Contains(session.TagsCSV, tag) {
-						filteredSessions = append(filteredSessions, session)
-						break
-					}
+				if session.TaskID == historyTask {
+					filteredSessions = append(filteredSessions, session)
+				}
+			}
+			sessions = filteredSessions
+		}
+
+		// Cancelled and abandoned pomodoros count toward completion rates
+		// even when they are not listed
+		counted := sessions
+
+		// Leave out sessions that were cancelled or abandoned
+		if !historyIncludeCancelled {
+			var filteredSessions []db.PomodoroSession
+			for _, session := range sessions {
+				if !session.Incomplete() {
+					filteredSessions = append(filteredSessions, session)
 				}
 			}
 			sessions = filteredSessions
@@ -130,9 +233,41 @@ Examples:
 			sessions = sessions[:historyLimit]
 		}
 
+		render := trace.Begin(trace.Render, "history")
+		defer render()
+
+		var groups []stats.GroupTotal
+		if historyGroup != "" {
+			groups, err = stats.Group(counted, historyGroup, func(s db.PomodoroSession) time.Time {
+				return inZone(s.StartTime, s, loc)
+			}, now)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			groupTitles(database, groups)
+		}
+		summary := historySummary(sessions, counted, now)
+
 		// Handle different output formats
-		switch historyOutput {
-		case "opf":
+		switch {
+		case historyOutput == "json" && (historyGroup != "" || historySummaryOnly):
+			report := historyReport{GroupBy: historyGroup, Summary: summary}
+			if historyGroup != "" {
+				report.Groups = historyGroupsJSON(groups)
+			}
+			if err := writeHistoryReport(os.Stdout, report); err != nil {
+				fmt.Fprintf(os.Stderr, "Error marshaling to JSON: %v\n", err)
+				os.Exit(1)
+			}
+
+		case historyOutput == "csv" && historyGroup != "":
+			if err := writeHistoryGroupsCSV(os.Stdout, historyGroup, groups); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing CSV: %v\n", err)
+				os.Exit(1)
+			}
+
+		case historyOutput == "opf":
 			data, err := opf.ExportToJSON(sessions)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error exporting to OPF: %v\n", err)
@@ -140,96 +275,324 @@ Examples:
 			}
 			fmt.Println(string(data))
 
-		case "json":
-			// Convert sessions to a simple JSON format
-			type jsonSession struct {
-				ID          int64  `json:"id"`
-				StartTime   string `json:"start_time"`
-				EndTime     string `json:"end_time"`
-				Description string `json:"description"`
-				Duration    string `json:"duration"`
-				Tags        string `json:"tags"`
-				WasBreak    bool   `json:"was_break"`
-			}
-
-			jsonSessions := make([]jsonSession, 0, len(sessions))
-			for _, s := range sessions {
-				duration := s.EndTime.Sub(s.StartTime)
-				jsonSessions = append(jsonSessions, jsonSession{
-					ID:          s.ID,
-					StartTime:   s.StartTime.Format(time.RFC3339),
-					EndTime:     s.EndTime.Format(time.RFC3339),
-					Description: s.Description,
-					Duration:    duration.String(),
-					Tags:        s.TagsCSV,
-					WasBreak:    s.WasBreak,
-				})
-			}
-
-			data, err := json.MarshalIndent(jsonSessions, "", "  ")
+		case historyOutput == "json":
+			data, err := sessionsJSON(sessions, loc)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error marshaling to JSON: %v\n", err)
 				os.Exit(1)
 			}
 			fmt.Println(string(data))
 
+		case historyOutput == "csv":
+			if err := writeSessionsCSV(os.Stdout, sessions, loc); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing CSV: %v\n", err)
+				os.Exit(1)
+			}
+
+		case format != nil:
+			for _, s := range sessions {
+				line, err := format(s, loc)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "%v\n", err)
+					os.Exit(1)
+				}
+				fmt.Println(line)
+			}
+
 		default: // text or unspecified
-			if len(sessions) == 0 {
+			if len(sessions) == 0 && len(groups) == 0 {
 				fmt.Println("No sessions found.")
+				render()
+				last, err := database.GetLastSession()
+				suggestTutorial(err != nil || last != nil)
 				return
 			}
 
-			// Calculate statistics
-			var totalDuration time.Duration
-			pomodoroCount := 0
-			breakCount := 0
-
-			fmt.Println("Recent Pomodoro Sessions:")
-			fmt.Println("-------------------------")
-
-			for _, s := range sessions {
-				duration := s.EndTime.Sub(s.StartTime)
-				totalDuration += duration
+			if !historySummaryOnly && len(sessions) > 0 {
+				fmt.Println("Recent Pomodoro Sessions:")
+				fmt.Println("-------------------------")
 
-				if s.WasBreak {
-					breakCount++
-				} else {
-					pomodoroCount++
+				for _, s := range sessions {
+					status := ""
+					if s.Incomplete() {
+						status = " [" + s.Status + "]"
+					}
+					fmt.Printf("%s %s %s: %s (%s)%s %s\n",
+						s.ShortRef(),
+						inZone(s.StartTime, s, loc).Format("2006-01-02 15:04"),
+						sessionIcon(s.WasBreak),
+						s.Description,
+						s.EndTime.Sub(s.StartTime).Round(time.Second),
+						status,
+						term.Tags(s.TagsCSV))
 				}
+			}
 
-				sessionType := "🍅"
-				if s.WasBreak {
-					sessionType = "☕"
+			printHistoryGroups(historyGroup, groups)
+			printHistorySummary(summary)
+			render()
+			printTaskSummary(database, sessions)
+			if !historySummaryOnly {
+				printTagLegend(sessions)
+			}
+			if adherence, err := breakAdherence(database, sessions); err == nil {
+				if nudge := breakSkipNudge(adherence); nudge != "" {
+					decorf("\n%s\n", nudge)
 				}
-
-				fmt.Printf("%s %s: %s (%s) %s\n",
-					s.StartTime.Format("2006-01-02 15:04"),
-					sessionType,
-					s.Description,
-					duration.Round(time.Second),
-					s.TagsCSV)
 			}
-
-			fmt.Println("\nSummary:")
-			fmt.Printf("Total sessions: %d (%d pomodoros, %d breaks)\n",
-				len(sessions),
-				pomodoroCount,
-				breakCount)
-			fmt.Printf("Total time: %s\n", totalDuration.Round(time.Minute))
 		}
 	},
 }
 
+// historyFormatter renders a session as one line of --format output
+type historyFormatter func(s db.PomodoroSession, loc *time.Location) (string, error)
+
+// historyLine is what a --format template is given for each session
+type historyLine struct {
+	ID          int64
+	Ref         string
+	Start       time.Time
+	End         time.Time
+	Description string
+	Length      time.Duration // From start to end
+	Duration    time.Duration // Focused, excluding pauses
+	Planned     time.Duration
+	Tags        []string
+	Break       bool
+	Status      string
+	TaskID      int64
+}
+
+// newHistoryLine describes a session for a template, with times in loc
+func newHistoryLine(s db.PomodoroSession, loc *time.Location) historyLine {
+	tags := []string{}
+	for _, tag := range strings.Split(s.TagsCSV, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return historyLine{
+		ID:          s.ID,
+		Ref:         s.ShortRef(),
+		Start:       inZone(s.StartTime, s, loc),
+		End:         inZone(s.EndTime, s, loc),
+		Description: s.Description,
+		Length:      s.EndTime.Sub(s.StartTime).Round(time.Second),
+		Duration:    s.EffectiveFocus(s.EndTime).Round(time.Second),
+		Planned:     time.Duration(s.DurationSec) * time.Second,
+		Tags:        tags,
+		Break:       s.WasBreak,
+		Status:      s.Status,
+		TaskID:      s.TaskID,
+	}
+}
+
+// newHistoryFormatter returns the formatter for a --format string: a Go
+// template when it contains {{, and placeholders otherwise
+func newHistoryFormatter(format string) (historyFormatter, error) {
+	if !strings.Contains(format, "{{") {
+		return func(s db.PomodoroSession, loc *time.Location) (string, error) {
+			return formatHistoryLine(format, s, loc), nil
+		}, nil
+	}
+
+	tmpl, err := template.New("format").Funcs(template.FuncMap{"join": strings.Join}).Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --format template: %v", err)
+	}
+	return func(s db.PomodoroSession, loc *time.Location) (string, error) {
+		var b strings.Builder
+		if err := tmpl.Execute(&b, newHistoryLine(s, loc)); err != nil {
+			return "", fmt.Errorf("error formatting session %s: %v", s.ShortRef(), err)
+		}
+		return b.String(), nil
+	}, nil
+}
+
+// formatHistoryLine expands a --format string for one session
+func formatHistoryLine(format string, s db.PomodoroSession, loc *time.Location) string {
+	kind := "pomodoro"
+	if s.WasBreak {
+		kind = "break"
+	}
+	return utils.ExpandFormat(format, map[byte]string{
+		'i': s.ShortRef(),
+		's': inZone(s.StartTime, s, loc).Format("2006-01-02 15:04"),
+		'e': inZone(s.EndTime, s, loc).Format("15:04"),
+		'd': s.Description,
+		'l': s.EndTime.Sub(s.StartTime).Round(time.Second).String(),
+		'D': s.EffectiveFocus(s.EndTime).Round(time.Second).String(),
+		't': s.TagsCSV,
+		'b': kind,
+		'S': s.Status,
+	})
+}
+
+// jsonSession is the JSON representation of a session in history output
+type jsonSession struct {
+	ID          int64    `json:"id"`
+	Ref         string   `json:"ref"`
+	StartTime   string   `json:"start_time"`
+	EndTime     string   `json:"end_time"`
+	Description string   `json:"description"`
+	Duration    string   `json:"duration"`
+	Tags        []string `json:"tags"`
+	WasBreak    bool     `json:"was_break"`
+	Timezone    string   `json:"timezone"`
+	TaskID      int64    `json:"task_id,omitempty"`
+	Status      string   `json:"status,omitempty"` // completed, cancelled, or abandoned once the session ended
+}
+
+// newJSONSession converts a session to its JSON representation, showing
+// times in loc
+func newJSONSession(s db.PomodoroSession, loc *time.Location) jsonSession {
+	return jsonSession{
+		ID:          s.ID,
+		Ref:         s.ShortRef(),
+		StartTime:   inZone(s.StartTime, s, loc).Format(time.RFC3339),
+		EndTime:     inZone(s.EndTime, s, loc).Format(time.RFC3339),
+		Description: s.Description,
+		Duration:    s.EndTime.Sub(s.StartTime).String(),
+		Tags:        jsonTags(s.TagsCSV),
+		WasBreak:    s.WasBreak,
+		Timezone:    db.FormatOffset(s.TZOffset),
+		TaskID:      s.TaskID,
+		Status:      s.Status,
+	}
+}
+
+// sessionsJSON converts sessions to indented JSON, showing times in loc
+func sessionsJSON(sessions []db.PomodoroSession, loc *time.Location) ([]byte, error) {
+	jsonSessions := make([]jsonSession, 0, len(sessions))
+	for _, s := range sessions {
+		jsonSessions = append(jsonSessions, newJSONSession(s, loc))
+	}
+	return json.MarshalIndent(jsonSessions, "", "  ")
+}
+
+// csvHeader names the columns of CSV history output
+var csvHeader = []string{"id", "start", "end", "description", "duration_secs", "tags", "was_break", "paused_secs", "timezone", "task_id", "status"}
+
+// writeSessionsCSV writes sessions as RFC 4180 CSV with a header row. Times
+// are in loc without an offset, which spreadsheets read as plain date-times;
+// the timezone column holds each session's own offset.
+func writeSessionsCSV(w io.Writer, sessions []db.PomodoroSession, loc *time.Location) error {
+	cw := csv.NewWriter(w)
+	cw.UseCRLF = true
+
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, s := range sessions {
+		record := []string{
+			strconv.FormatInt(s.ID, 10),
+			inZone(s.StartTime, s, loc).Format("2006-01-02 15:04:05"),
+			inZone(s.EndTime, s, loc).Format("2006-01-02 15:04:05"),
+			csvText(s.Description),
+			strconv.FormatInt(int64(s.EndTime.Sub(s.StartTime).Round(time.Second).Seconds()), 10),
+			csvText(s.TagsCSV),
+			strconv.FormatBool(s.WasBreak),
+			strconv.FormatInt(s.TotalPausedDuration, 10),
+			db.FormatOffset(s.TZOffset),
+			csvTaskID(s.TaskID),
+			s.Status,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvTaskID leaves the task column empty for sessions without a task
+func csvTaskID(id int64) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatInt(id, 10)
+}
+
+// csvText keeps free text from being run as a formula when the CSV is opened
+// in a spreadsheet, by prefixing a quote to text that starts like one
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// printTaskSummary counts the pomodoros in sessions by linked task
+func printTaskSummary(database db.DB, sessions []db.PomodoroSession) {
+	counts := make(map[int64]int)
+	var order []int64
+	for _, s := range sessions {
+		if s.WasBreak || s.TaskID == 0 {
+			continue
+		}
+		if counts[s.TaskID] == 0 {
+			order = append(order, s.TaskID)
+		}
+		counts[s.TaskID]++
+	}
+	if len(order) == 0 {
+		return
+	}
+
+	fmt.Println("Tasks:")
+	for _, id := range order {
+		title := fmt.Sprintf("task %d", id)
+		if task, err := database.GetTask(id); err == nil && task != nil {
+			title = task.Title
+		}
+		fmt.Printf("  %3d  %-40s %d\n", id, title, counts[id])
+	}
+}
+
+// printTagLegend lists the tags in sessions with their colors. Without
+// color the legend adds nothing, so it is skipped.
+func printTagLegend(sessions []db.PomodoroSession) {
+	if !term.ColorEnabled() {
+		return
+	}
+
+	seen := make(map[string]bool)
+	var swatches []string
+	for _, s := range sessions {
+		for _, tag := range strings.Split(s.TagsCSV, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "" || seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			swatches = append(swatches, term.TagSwatch(tag))
+		}
+	}
+
+	if len(swatches) > 0 {
+		fmt.Printf("Tags: %s\n", strings.Join(swatches, "  "))
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(historyCmd)
 
 	// Define flags for the history command
 	historyCmd.Flags().BoolVar(&historyToday, "today", false, "Show sessions from today")
 	historyCmd.Flags().BoolVar(&historyWeek, "week", false, "Show sessions from this week")
-	historyCmd.Flags().StringVar(&historyFrom, "from", "", "Start date (YYYY-MM-DD)")
-	historyCmd.Flags().StringVar(&historyTo, "to", "", "End date (YYYY-MM-DD)")
+	historyCmd.Flags().StringVar(&historyFrom, "from", "", "Start date (YYYY-MM-DD, yesterday, monday, 7d, ...)")
+	historyCmd.Flags().StringVar(&historyTo, "to", "", "End date (YYYY-MM-DD, yesterday, monday, 7d, ...)")
 	historyCmd.Flags().IntVar(&historyLimit, "limit", 0, "Limit number of results")
-	historyCmd.Flags().StringVar(&historyFormat, "format", "", "Format string for session output")
-	historyCmd.Flags().StringVar(&historyOutput, "output", "text", "Output format (text, json, opf)")
-	historyCmd.Flags().StringSliceVarP(&historyTags, "tags", "t", []string{}, "Filter by tags")
+	historyCmd.Flags().StringVar(&historyFormat, "format", "", "Print each session on a line of its own in this format (placeholders or a Go template)")
+	historyCmd.Flags().BoolVar(&historyIncludeCancelled, "include-cancelled", false, "Include cancelled and abandoned sessions")
+	historyCmd.Flags().StringVar(&historyOutput, "output", "text", "Output format (text, json, csv, opf)")
+	historyCmd.Flags().StringVar(&historyTZ, "timezone", "", "Show times in this zone (IANA name, local, or session for each session's own zone)")
+	historyCmd.Flags().Int64Var(&historyTask, "task", 0, "Filter by task ID")
+	_ = historyCmd.RegisterFlagCompletionFunc("task", completeTask)
+	historyCmd.Flags().StringSliceVarP(&historyTags, "tags", "t", []string{}, "Only sessions with any of these tags")
+	_ = historyCmd.RegisterFlagCompletionFunc("tags", completeTags)
+	historyCmd.Flags().StringVar(&historyGroup, "group-by", "", "Total pomodoros by day, week, tag, or project")
+	_ = historyCmd.RegisterFlagCompletionFunc("group-by", cobra.FixedCompletions(stats.Groupings, cobra.ShellCompDirectiveNoFileComp))
+	historyCmd.Flags().BoolVar(&historySummaryOnly, "summary", false, "Print only the totals, without listing every session")
 }
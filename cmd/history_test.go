@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func TestWriteSessionsCSV(t *testing.T) {
+	start := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+	sessions := []db.PomodoroSession{
+		{
+			ID:                  7,
+			StartTime:           start,
+			EndTime:             start.Add(30 * time.Minute),
+			Description:         `Fix "quoted", comma bug`,
+			TagsCSV:             "work,bugs",
+			TotalPausedDuration: 300,
+			TaskID:              3,
+			Status:              db.StatusCancelled,
+		},
+		{
+			ID:          8,
+			StartTime:   start.Add(30 * time.Minute),
+			EndTime:     start.Add(35 * time.Minute),
+			Description: "=HYPERLINK(\"x\")",
+			WasBreak:    true,
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeSessionsCSV(&buf, sessions, time.UTC); err != nil {
+		t.Fatalf("writeSessionsCSV failed: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("\r\n")) {
+		t.Error("Expected CRLF line endings")
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid CSV: %v", err)
+	}
+	want := [][]string{
+		csvHeader,
+		{"7", "2025-03-14 09:00:00", "2025-03-14 09:30:00", `Fix "quoted", comma bug`, "1800", "work,bugs", "false", "300", "UTC+00:00", "3", "cancelled"},
+		{"8", "2025-03-14 09:30:00", "2025-03-14 09:35:00", `'=HYPERLINK("x")`, "300", "", "true", "0", "UTC+00:00", "", ""},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("Expected %d records, got %d: %v", len(want), len(records), records)
+	}
+	for i := range want {
+		for j := range want[i] {
+			if records[i][j] != want[i][j] {
+				t.Errorf("Record %d field %d: expected %q, got %q", i, j, want[i][j], records[i][j])
+			}
+		}
+	}
+}
+
+func TestHistoryFormatter(t *testing.T) {
+	start := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+	session := db.PomodoroSession{
+		ID:                  7,
+		UID:                 "3f2a9c000000",
+		StartTime:           start,
+		EndTime:             start.Add(30 * time.Minute),
+		Description:         "Fix 100% of bugs",
+		DurationSec:         25 * 60,
+		TagsCSV:             "work, bugs",
+		TotalPausedDuration: 300,
+		Status:              db.StatusCompleted,
+	}
+
+	for _, tc := range []struct {
+		format string
+		want   string
+	}{
+		{"%i %s-%e %d (%l, %D) [%t] %b %S %x", "#3f2a9c 2025-03-14 09:00-09:30 Fix 100% of bugs (30m0s, 25m0s) [work, bugs] pomodoro completed %x"},
+		{`{{.Start.Format "15:04"}} {{.Description}} {{.Duration}}/{{.Planned}} {{join .Tags ";"}}`, "09:00 Fix 100% of bugs 25m0s/25m0s work;bugs"},
+	} {
+		format, err := newHistoryFormatter(tc.format)
+		if err != nil {
+			t.Fatalf("newHistoryFormatter(%q) failed: %v", tc.format, err)
+		}
+		got, err := format(session, time.UTC)
+		if err != nil {
+			t.Fatalf("Formatting with %q failed: %v", tc.format, err)
+		}
+		if got != tc.want {
+			t.Errorf("Format %q: expected %q, got %q", tc.format, tc.want, got)
+		}
+	}
+
+	if _, err := newHistoryFormatter("{{.Start"); err == nil {
+		t.Error("Expected an invalid template to be refused")
+	}
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/output"
+	"github.com/ethan-k/pomodoro-cli/internal/stats"
+)
+
+// groupHeadings label the group column of history --group-by
+var groupHeadings = map[string]string{
+	stats.GroupDay:     "DAY",
+	stats.GroupWeek:    "WEEK OF",
+	stats.GroupTag:     "TAG",
+	stats.GroupProject: "PROJECT",
+}
+
+// historyGroupJSON is a group in history --group-by JSON output
+type historyGroupJSON struct {
+	Group          string  `json:"group"`
+	TaskID         int64   `json:"task_id,omitempty"`
+	Pomodoros      int     `json:"pomodoros"`
+	Completed      int     `json:"completed"`
+	CompletionRate float64 `json:"completion_rate"`
+	FocusMinutes   float64 `json:"focus_minutes"`
+}
+
+// historySummaryJSON is the aggregate block of history in JSON output
+type historySummaryJSON struct {
+	Sessions       int     `json:"sessions"`
+	Pomodoros      int     `json:"pomodoros"`
+	Breaks         int     `json:"breaks"`
+	Rated          int     `json:"rated"` // Finished pomodoros the completion rate covers, cancelled ones included
+	Completed      int     `json:"completed"`
+	CompletionRate float64 `json:"completion_rate"`
+	FocusMinutes   float64 `json:"focus_minutes"`
+	TotalMinutes   float64 `json:"total_minutes"`
+}
+
+// historyReport is history's JSON output with --group-by or --summary
+type historyReport struct {
+	GroupBy string             `json:"group_by,omitempty"`
+	Groups  []historyGroupJSON `json:"groups,omitempty"`
+	Summary historySummaryJSON `json:"summary"`
+}
+
+// historySummary sums up the sessions history lists, with the completion
+// rate and focus time of counted, which keeps the cancelled pomodoros that
+// lower the rate
+func historySummary(sessions, counted []db.PomodoroSession, now time.Time) historySummaryJSON {
+	var summary historySummaryJSON
+	var total time.Duration
+	for _, s := range sessions {
+		total += s.EndTime.Sub(s.StartTime)
+		if s.WasBreak {
+			summary.Breaks++
+		} else {
+			summary.Pomodoros++
+		}
+	}
+	summary.Sessions = len(sessions)
+	summary.TotalMinutes = total.Minutes()
+
+	focus := stats.Total(counted, now)
+	summary.Rated = focus.Pomodoros
+	summary.Completed = focus.Completed
+	summary.CompletionRate = focus.CompletionRate()
+	summary.FocusMinutes = focus.Focus.Minutes()
+	return summary
+}
+
+// printHistorySummary prints the aggregate block of text history output
+func printHistorySummary(summary historySummaryJSON) {
+	fmt.Println("\nSummary:")
+	fmt.Printf("Total sessions: %d (%d pomodoros, %d breaks)\n", summary.Sessions, summary.Pomodoros, summary.Breaks)
+	fmt.Printf("Total time: %s\n", minutes(summary.TotalMinutes))
+	if summary.Rated > 0 {
+		fmt.Printf("Focus time: %s\n", minutes(summary.FocusMinutes))
+		fmt.Printf("Completion rate: %.0f%% (%d of %d ran their full length)\n", summary.CompletionRate*100, summary.Completed, summary.Rated)
+	}
+}
+
+// minutes rounds a number of minutes to a whole-minute duration
+func minutes(m float64) time.Duration {
+	return (time.Duration(m * float64(time.Minute))).Round(time.Minute)
+}
+
+// groupTitles names project groups after their tasks
+func groupTitles(database db.DB, groups []stats.GroupTotal) {
+	for i, g := range groups {
+		if g.TaskID == 0 {
+			continue
+		}
+		if task, err := database.GetTask(g.TaskID); err == nil && task != nil {
+			groups[i].Key = task.Title
+		}
+	}
+}
+
+// historyGroupsJSON converts groups to their JSON representation
+func historyGroupsJSON(groups []stats.GroupTotal) []historyGroupJSON {
+	out := make([]historyGroupJSON, 0, len(groups))
+	for _, g := range groups {
+		out = append(out, historyGroupJSON{
+			Group:          g.Key,
+			TaskID:         g.TaskID,
+			Pomodoros:      g.Pomodoros,
+			Completed:      g.Completed,
+			CompletionRate: g.CompletionRate(),
+			FocusMinutes:   g.Focus.Minutes(),
+		})
+	}
+	return out
+}
+
+// writeHistoryReport writes history's JSON output with --group-by or
+// --summary
+func writeHistoryReport(w io.Writer, report historyReport) error {
+	return output.Write(w, report, true)
+}
+
+// printHistoryGroups prints the per-group totals of text history output
+func printHistoryGroups(by string, groups []stats.GroupTotal) {
+	if len(groups) == 0 {
+		return
+	}
+	width := len(groupHeadings[by])
+	for _, g := range groups {
+		width = max(width, len(g.Key))
+	}
+
+	fmt.Printf("\nBy %s:\n", by)
+	fmt.Printf("  %-*s  %9s  %9s  %s\n", width, groupHeadings[by], "POMODOROS", "FOCUS", "COMPLETED")
+	for _, g := range groups {
+		fmt.Printf("  %-*s  %9d  %9s  %8.0f%%\n", width, g.Key, g.Pomodoros, g.Focus.Round(time.Minute), g.CompletionRate()*100)
+	}
+}
+
+// writeHistoryGroupsCSV writes per-group totals as CSV with a header row
+func writeHistoryGroupsCSV(w io.Writer, by string, groups []stats.GroupTotal) error {
+	cw := csv.NewWriter(w)
+	cw.UseCRLF = true
+
+	if err := cw.Write([]string{by, "task_id", "pomodoros", "completed", "completion_rate", "focus_secs"}); err != nil {
+		return err
+	}
+	for _, g := range groups {
+		record := []string{
+			csvText(g.Key),
+			csvTaskID(g.TaskID),
+			strconv.Itoa(g.Pomodoros),
+			strconv.Itoa(g.Completed),
+			strconv.FormatFloat(g.CompletionRate(), 'f', 4, 64),
+			strconv.FormatInt(int64(g.Focus.Round(time.Second).Seconds()), 10),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/hooks"
+)
+
+// hookPermissions is the permission a hook must be granted in
+// hooks.permissions to run each command. Commands not listed only read,
+// and any hook may run them.
+var hookPermissions = map[*cobra.Command]string{
+	startCmd:         hooks.PermModifySessions,
+	breakCmd:         hooks.PermModifySessions,
+	pauseCmd:         hooks.PermModifySessions,
+	resumeCmd:        hooks.PermModifySessions,
+	cancelCmd:        hooks.PermModifySessions,
+	extendCmd:        hooks.PermModifySessions,
+	repeatCmd:        hooks.PermModifySessions,
+	templateStartCmd: hooks.PermModifySessions,
+	logCmd:           hooks.PermModifySessions,
+	editCmd:          hooks.PermModifySessions,
+	deleteCmd:        hooks.PermModifySessions,
+	annotateCmd:      hooks.PermModifySessions,
+	rateCmd:          hooks.PermModifySessions,
+	energyCmd:        hooks.PermModifySessions,
+	importCmd:        hooks.PermModifySessions,
+	importAllCmd:     hooks.PermModifySessions,
+	dbSeedCmd:        hooks.PermModifySessions,
+	dbRollbackCmd:    hooks.PermModifySessions,
+	dbPruneCmd:       hooks.PermModifySessions,
+	dbMigrateCmd:     hooks.PermModifySessions,
+	serveCmd:         hooks.PermNetwork,
+}
+
+// guardHookPermissions refuses to run a command when pomodoro was started by
+// a hook that was not granted what the command needs
+func guardHookPermissions(cmd *cobra.Command, _ []string) {
+	perm, ok := hookPermissions[cmd]
+	if !ok {
+		return
+	}
+	if err := hooks.Allowed(perm); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
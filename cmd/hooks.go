@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/hooks"
+	"github.com/ethan-k/pomodoro-cli/internal/notify"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+// runHooks runs the user's hooks and configured commands for an event on a
+// session, warning about any that fail. Hooks run only when hooks.enabled is
+// set; on_start and on_complete commands run whenever they are configured.
+// With notifications.focus_mode set, macOS Focus follows the pomodoro too.
+func runHooks(database db.DB, event string, id int64) {
+	cfg, err := config.LoadConfig()
+	if err != nil || (!cfg.Hooks.Enabled && len(cfg.Commands(event)) == 0 && !cfg.Notifications.FocusMode) {
+		return
+	}
+
+	session, err := database.GetSessionByID(id)
+	if err != nil || session == nil {
+		warnf("Hooks not run for %s: session %d not found\n", event, id)
+		return
+	}
+	if err := runHooksFor(cfg, event, session); err != nil {
+		warnf("%v\n", err)
+	}
+}
+
+// runHooksFor switches Focus, then runs the configured commands and the
+// hooks, for an event on a session already loaded
+func runHooksFor(cfg *config.Config, event string, session *db.PomodoroSession) error {
+	var errs []error
+	if on, ok := focusFor(event, session.WasBreak); ok {
+		errs = append(errs, notify.SetFocus(cfg.Notifications, on))
+	}
+
+	commands := cfg.Commands(event)
+	if !cfg.Hooks.Enabled && len(commands) == 0 {
+		return errors.Join(errs...)
+	}
+
+	timeout, err := time.ParseDuration(cfg.Hooks.Timeout)
+	if err != nil {
+		timeout = hooks.DefaultTimeout
+	}
+
+	payload := hooks.NewPayload(event, session, app.now())
+	policy := hookPolicy(cfg)
+	if len(commands) > 0 {
+		errs = append(errs, hooks.RunCommands(context.Background(), commandsName(event), commands, payload, timeout, policy))
+	}
+	if cfg.Hooks.Enabled {
+		runner := hooks.New(utils.ExpandPath(cfg.Hooks.Path), timeout)
+		runner.Policy = policy
+		errs = append(errs, runner.Run(context.Background(), payload))
+	}
+	return errors.Join(errs...)
+}
+
+// hookPolicy returns what hooks.permissions lets each hook do
+func hookPolicy(cfg *config.Config) *hooks.Policy {
+	return &hooks.Policy{Grants: cfg.Hooks.Permissions, Require: cfg.Hooks.RequirePermissions}
+}
+
+// commandsName returns the config key of the commands run for an event,
+// which hooks.permissions refers to them by
+func commandsName(event string) string {
+	if event == hooks.SessionStart {
+		return "on_start"
+	}
+	return "on_complete"
+}
+
+// focusFor returns whether Focus should be on after an event, which holds
+// only while a pomodoro is running; ok is false for events that leave it be
+func focusFor(event string, wasBreak bool) (on, ok bool) {
+	switch event {
+	case hooks.SessionStart:
+		return true, true
+	case hooks.Resume:
+		return !wasBreak, !wasBreak
+	case hooks.SessionComplete, hooks.Pause, hooks.Cancel:
+		return false, !wasBreak
+	}
+	return false, false
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/hooks"
+)
+
+func TestRunHooksForExpandsPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks here are shell scripts")
+	}
+	now := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	newTestApp(t, &mockDB{}, now)
+	home, _ := os.UserHomeDir()
+	dir := filepath.Join(home, "hooks")
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	ran := filepath.Join(home, "ran")
+	script := "#!/bin/sh\ntouch '" + ran + "'\n"
+	if err := os.WriteFile(filepath.Join(dir, hooks.SessionStart), []byte(script), 0700); err != nil { // #nosec G306 - hooks must be executable
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Hooks.Enabled = true
+	cfg.Hooks.Path = "~/hooks"
+	session := &db.PomodoroSession{ID: 1, StartTime: now, EndTime: now.Add(25 * time.Minute)}
+	if err := runHooksFor(cfg, hooks.SessionStart, session); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(ran); err != nil {
+		t.Errorf("Expected the hook under ~/hooks to run, got %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/hooks"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+// hooksAudit is set by 'hooks list --audit'
+var hooksAudit bool
+
+// hooksCmd groups the hook commands
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Shows the hooks and commands run on session events",
+}
+
+// hooksListCmd lists hooks and, with --audit, what each may do
+var hooksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the hooks and configured commands for each event",
+	Long: `Lists the hooks in hooks.path and the on_start and on_complete commands,
+in the order they run for each event.
+
+With --audit, shows what each may do as granted in hooks.permissions, and
+flags hooks that may do anything because they have no entry there, hooks
+that will be refused under hooks.require_permissions or because they lack
+network where it cannot be taken from them, permissions that do not exist,
+and entries for hooks that are gone.
+
+Examples:
+  pomodoro hooks list
+  pomodoro hooks list --audit
+  pomodoro hooks list --audit --json`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		entries, stale, err := auditHooks(cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			printJSON(struct {
+				Hooks []hookEntry `json:"hooks"`
+				Stale []string    `json:"stale_permissions"`
+			}{entries, stale})
+			return
+		}
+
+		if len(entries) == 0 {
+			fmt.Printf("No hooks in %s and no on_start or on_complete commands.\n", cfg.Hooks.Path)
+		} else if hooksAudit {
+			fmt.Printf("%-18s %-32s %-30s %s\n", "EVENT", "HOOK", "PERMISSIONS", "NOTES")
+			for _, e := range entries {
+				perms := "any"
+				if e.Declared {
+					perms = strings.Join(e.Permissions, ",")
+					if perms == "" {
+						perms = "none"
+					}
+				}
+				fmt.Printf("%-18s %-32s %-30s %s\n", e.Event, e.Name, perms, strings.Join(e.Notes, "; "))
+			}
+		} else {
+			fmt.Printf("%-18s %-32s %s\n", "EVENT", "HOOK", "NOTES")
+			for _, e := range entries {
+				fmt.Printf("%-18s %-32s %s\n", e.Event, e.Name, strings.Join(e.Notes, "; "))
+			}
+		}
+
+		if hooksAudit {
+			for _, name := range stale {
+				warnf("hooks.permissions has an entry for %s, which is not a hook\n", name)
+			}
+		}
+		if !cfg.Hooks.Enabled && len(entries) > 0 {
+			decorf("\nHooks in %s only run with hooks.enabled set.\n", cfg.Hooks.Path)
+		}
+	},
+}
+
+// hookEntry is a hook, or a list of configured commands, as 'hooks list'
+// shows it
+type hookEntry struct {
+	Event       string   `json:"event"`
+	Name        string   `json:"name"` // Path within hooks.path, or on_start or on_complete
+	Path        string   `json:"path,omitempty"`
+	Commands    []string `json:"commands,omitempty"`
+	Declared    bool     `json:"declared"`    // Has an entry in hooks.permissions
+	Permissions []string `json:"permissions"` // Granted, when declared
+	Runs        bool     `json:"runs"`        // Runs on its event, as things stand
+	Notes       []string `json:"notes"`
+}
+
+// auditHooks returns every hook and command list with what it may do, and
+// the entries of hooks.permissions that name neither
+func auditHooks(cfg *config.Config) ([]hookEntry, []string, error) {
+	timeout, err := time.ParseDuration(cfg.Hooks.Timeout)
+	if err != nil {
+		timeout = hooks.DefaultTimeout
+	}
+	runner := hooks.New(utils.ExpandPath(cfg.Hooks.Path), timeout)
+	found, err := runner.All()
+	if err != nil {
+		return nil, nil, err
+	}
+	policy := hookPolicy(cfg)
+
+	var entries []hookEntry
+	known := map[string]bool{}
+	add := func(e hookEntry) {
+		known[e.Name] = true
+		e.Permissions, e.Declared = policy.Lookup(e.Name)
+		if e.Permissions == nil {
+			e.Permissions = []string{}
+		}
+		if e.Notes == nil {
+			e.Notes = []string{}
+		}
+		if e.Runs && !e.Declared {
+			if policy.Require {
+				e.Runs = false
+				e.Notes = append(e.Notes, "refused: no entry in hooks.permissions")
+			} else if hooksAudit {
+				e.Notes = append(e.Notes, "unrestricted: no entry in hooks.permissions")
+			}
+		}
+		if e.Runs && e.Declared && !hooks.NetworkIsolation && !slices.Contains(e.Permissions, hooks.PermNetwork) {
+			e.Runs = false
+			e.Notes = append(e.Notes, "refused: cannot be kept off the network on this system")
+		}
+		for _, perm := range policy.Check(e.Name) {
+			e.Notes = append(e.Notes, fmt.Sprintf("unknown permission %q", perm))
+		}
+		entries = append(entries, e)
+	}
+
+	for _, event := range hooks.Events {
+		if commands := cfg.Commands(event); len(commands) > 0 {
+			add(hookEntry{Event: event, Name: commandsName(event), Commands: commands, Runs: true})
+		}
+		for _, h := range found {
+			if h.Event != event {
+				continue
+			}
+			e := hookEntry{Event: event, Name: h.Name, Path: h.Path, Runs: h.Executable && cfg.Hooks.Enabled}
+			if !h.Executable {
+				e.Notes = append(e.Notes, "not executable, skipped")
+			}
+			add(e)
+		}
+	}
+
+	stale := []string{}
+	for name := range cfg.Hooks.Permissions {
+		if !known[name] && name != "on_start" && name != "on_complete" {
+			stale = append(stale, name)
+		}
+	}
+	sort.Strings(stale)
+	return entries, stale, nil
+}
+
+func init() {
+	rootCmd.AddCommand(hooksCmd)
+	hooksCmd.AddCommand(hooksListCmd)
+
+	hooksListCmd.Flags().BoolVar(&hooksAudit, "audit", false, "Show what each hook may do and flag unrestricted ones")
+}
@@ -0,0 +1,347 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/backup"
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/csvimport"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/opf"
+	"github.com/ethan-k/pomodoro-cli/internal/term"
+)
+
+var (
+	importFormat  string
+	importColumns string
+	importDryRun  bool
+)
+
+// importCmd adds history exported by another tool
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Imports session history from another tool or a backup",
+	Long: `Imports pomodoros and breaks from an Open Pomodoro Format (OPF) JSON file,
+such as one exported by another OPF-compatible tool, or from a CSV time
+log, as finished sessions. Give - as the file to read standard input.
+
+A CSV file (--format csv, or a file ending in .csv) starts with a header
+naming its columns; columns are separated by commas, semicolons, or tabs.
+These columns are read, and any others ignored:
+
+  date         YYYY-MM-DD the session started on
+  start        HH:MM with a date column, else "YYYY-MM-DD HH:MM" or RFC 3339
+  end          like start; an end before the start is on the next day
+  duration     e.g. 25m or 1h30, or a number of minutes; needed without end
+  description  optional
+  tags         separated by commas, semicolons, or spaces
+  type         pomodoro (the default) or break
+  status       completed (the default), cancelled, or abandoned
+
+--columns maps other headers to them, as in --columns
+"Task=description,Minutes=duration". Times are in the local time zone.
+Every row is checked first, and each invalid one is reported by line.
+
+A session starting in the same second and running the same whole minutes as
+one already recorded is skipped, so importing a file twice, or importing an
+export of this history, adds nothing. The file is checked in full first: if
+any entry is invalid, nothing is imported.
+
+A backup written by 'pomodoro export --all' is restored too, and is told
+from an OPF file by its contents. A JSON backup's sessions are merged in the
+same way, and its config file restored if there is none yet. A tar.gz
+backup replaces the database and config as 'pomodoro import-all' does. With
+--force, an existing config or database is replaced, keeping a .bak copy.
+
+Examples:
+  pomodoro import --format opf sessions-opf.json
+  pomodoro import timelog.csv --columns "Task=description" --dry-run
+  other-tool export | pomodoro import -
+  pomodoro import ~/.local/share/pomodoro/exports/pomodoro-backup-20250601-090000.json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		format := importFormat
+		if format != "" && format != "opf" && format != "backup" && format != "csv" {
+			fmt.Fprintf(os.Stderr, "Invalid format %q: must be opf, backup, or csv\n", format)
+			os.Exit(1)
+		}
+		if format == "" && strings.EqualFold(filepath.Ext(args[0]), ".csv") {
+			format = "csv"
+		}
+		if importColumns != "" && format != "csv" {
+			fmt.Fprintln(os.Stderr, "--columns only applies to CSV files")
+			os.Exit(1)
+		}
+
+		if format != "opf" && format != "csv" && isArchive(args[0]) {
+			if importDryRun {
+				fmt.Fprintln(os.Stderr, "--dry-run cannot be used when restoring a backup")
+				os.Exit(1)
+			}
+			importArchive(args[0])
+			return
+		}
+
+		data, err := readImportFile(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", args[0], err)
+			os.Exit(1)
+		}
+		if format == "csv" {
+			importCSV(data)
+			return
+		}
+		if format != "opf" {
+			doc, ok, err := backup.ParseDocument(data)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			if ok {
+				if importDryRun {
+					fmt.Fprintln(os.Stderr, "--dry-run cannot be used when restoring a backup")
+					os.Exit(1)
+				}
+				importDocument(doc)
+				return
+			}
+			if format == "backup" {
+				fmt.Fprintf(os.Stderr, "%s is not a pomodoro backup\n", args[0])
+				os.Exit(1)
+			}
+		}
+		export, err := opf.Parse(data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		sessions := make([]db.ImportedSession, 0, len(export.Pomodoros))
+		for _, p := range export.Pomodoros {
+			sessions = append(sessions, db.ImportedSession{
+				Start:       p.StartTime(),
+				Duration:    time.Duration(p.Duration) * time.Minute,
+				Description: p.Description,
+				TagsCSV:     strings.Join(p.Tags, ","),
+				WasBreak:    p.Type == opf.TypeBreak,
+				Status:      p.Status,
+			})
+		}
+
+		importSessions(sessions)
+	},
+}
+
+// importCSV imports the sessions in a CSV time log, reporting every invalid
+// row when there are any
+func importCSV(data []byte) {
+	columns, err := csvimport.ParseColumns(importColumns)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid --columns: %v\n", err)
+		os.Exit(1)
+	}
+	now := app.now()
+	sessions, err := csvimport.Parse(data, columns, now.Location(), now)
+	var rows csvimport.Errors
+	if errors.As(err, &rows) {
+		for _, row := range rows {
+			fmt.Fprintf(os.Stderr, "%v\n", row)
+		}
+		fmt.Fprintf(os.Stderr, "Nothing imported: %d invalid rows.\n", len(rows))
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	importSessions(sessions)
+}
+
+// importSessions adds sessions read from a file to the database, skipping
+// those already recorded, or with --dry-run shows what would be added
+func importSessions(sessions []db.ImportedSession) {
+	database, err := openInternalDB()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	defer func() {
+		if err := database.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+		}
+	}()
+
+	run := database.ImportSessions
+	if importDryRun {
+		run = database.PreviewImport
+	}
+	imported, skipped, err := run(sessions)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error importing sessions: %v\n", err)
+		os.Exit(1)
+	}
+
+	if jsonOutput {
+		printJSONLine(struct {
+			Imported int  `json:"imported"`
+			Skipped  int  `json:"skipped"`
+			DryRun   bool `json:"dry_run,omitempty"`
+		}{imported, skipped, importDryRun})
+		return
+	}
+
+	if importDryRun {
+		for _, s := range sessions {
+			kind := "pomodoro"
+			if s.WasBreak {
+				kind = "break"
+			}
+			fmt.Printf("%s  %-8s %8s  %s", s.Start.Local().Format("2006-01-02 15:04"), kind, s.Duration, s.Description)
+			if s.TagsCSV != "" {
+				fmt.Printf("  %s", term.Tags(s.TagsCSV))
+			}
+			fmt.Println()
+		}
+		fmt.Printf("Would import %d sessions", imported)
+	} else {
+		fmt.Printf("Imported %d sessions", imported)
+	}
+	if skipped > 0 {
+		fmt.Printf(", skipped %d already recorded", skipped)
+	}
+	fmt.Println(".")
+}
+
+// gzipMagic starts every gzip file, and so every backup archive
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// isArchive reports whether path is a gzip file, as a tar.gz backup is
+func isArchive(path string) bool {
+	if path == "-" {
+		return false
+	}
+	f, err := os.Open(path) // #nosec G304 - the user names the file to import
+	if err != nil {
+		return false
+	}
+	defer func() { _ = f.Close() }()
+	header := make([]byte, len(gzipMagic))
+	if _, err := io.ReadFull(f, header); err != nil {
+		return false
+	}
+	return bytes.Equal(header, gzipMagic)
+}
+
+// importArchive restores a tar.gz backup
+func importArchive(path string) {
+	loc, err := stateLocations()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	manifest, err := backup.Import(path, loc, importForce)
+	if errors.Is(err, backup.ErrExists) {
+		fmt.Fprintf(os.Stderr, "%v\nUse --force to replace it (a backup copy is kept).\n", err)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error importing: %v\n", err)
+		os.Exit(1)
+	}
+
+	if jsonOutput {
+		printJSONLine(struct {
+			Files int `json:"files"`
+		}{len(manifest.Files)})
+		return
+	}
+	fmt.Printf("Imported %d files exported by pomodoro %s on %s\n",
+		len(manifest.Files), manifest.AppVersion, manifest.CreatedAt.Local().Format("2006-01-02 15:04"))
+}
+
+// importDocument merges a JSON backup's sessions into the database and
+// restores its config file
+func importDocument(doc *backup.Document) {
+	database, err := openInternalDB()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	defer func() {
+		if err := database.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+		}
+	}()
+
+	imported, skipped, err := database.ImportSessions(doc.ImportedSessions())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error importing sessions: %v\n", err)
+		os.Exit(1)
+	}
+
+	configPath, err := config.Path()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	restored, err := doc.RestoreConfig(configPath, importForce)
+	kept := errors.Is(err, backup.ErrExists)
+	if err != nil && !kept {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	if jsonOutput {
+		printJSONLine(struct {
+			Imported       int  `json:"imported"`
+			Skipped        int  `json:"skipped"`
+			ConfigRestored bool `json:"config_restored"`
+		}{imported, skipped, restored})
+		return
+	}
+	fmt.Printf("Imported %d sessions", imported)
+	if skipped > 0 {
+		fmt.Printf(", skipped %d already recorded", skipped)
+	}
+	fmt.Println(".")
+	if restored {
+		fmt.Printf("Restored the config to %s\n", configPath)
+	}
+	if kept {
+		decorf("Kept your config; use --force to replace it with the backup's.\n")
+	}
+}
+
+// readImportFile reads the file to import, or standard input for "-",
+// refusing anything larger than an OPF document may be
+func readImportFile(path string) ([]byte, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path) // #nosec G304 - the user names the file to import
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = f.Close() }()
+		r = f
+	}
+	// One byte over the limit is enough for Parse to refuse it
+	return io.ReadAll(io.LimitReader(r, opf.MaxImportSize+1))
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().StringVar(&importFormat, "format", "", "Format of the file: opf, backup, or csv (default: detected)")
+	importCmd.Flags().StringVar(&importColumns, "columns", "", "For CSV, map headers to columns, e.g. \"Task=description,Minutes=duration\"")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Check the file and show what would be imported without importing it")
+	importCmd.Flags().BoolVar(&importForce, "force", false, "Replace the existing config, or database for a tar.gz backup")
+}
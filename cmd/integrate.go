@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/githook"
+)
+
+var (
+	integrateGitUninstall bool
+	integrateGitForce     bool
+)
+
+// integrateCmd groups the integrations with other tools
+var integrateCmd = &cobra.Command{
+	Use:   "integrate",
+	Short: "Connects pomodoro to other tools",
+}
+
+// integrateGitCmd installs the git hook that names the running pomodoro in
+// commit messages
+var integrateGitCmd = &cobra.Command{
+	Use:   "git",
+	Short: "Names the running pomodoro in the commits of this repository",
+	Long: `Installs a prepare-commit-msg hook in the current git repository that adds
+a trailer naming the running pomodoro to commits made during it:
+
+  Pomodoro: #a3f9c2 Write the release notes
+
+Commits made outside a pomodoro, or during a break, are left alone, and the
+hook never stops a commit. 'pomodoro stats --commits', run in the
+repository, then counts the commits made in each pomodoro.
+
+The hook is only installed where you run this, and a prepare-commit-msg
+hook of your own is not replaced unless you pass --force.
+
+Example:
+  pomodoro integrate git
+  pomodoro integrate git --uninstall`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		path, err := githook.HookPath("")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if integrateGitUninstall {
+			removed, err := githook.Uninstall(path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			if !removed {
+				fmt.Println("The git hook is not installed in this repository.")
+				return
+			}
+			fmt.Println("Git hook removed.")
+			return
+		}
+
+		exe, err := os.Executable()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error locating pomodoro executable: %v\n", err)
+			os.Exit(1)
+		}
+		if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+			exe = resolved
+		}
+
+		if err := githook.Install(path, exe, integrateGitForce); err != nil {
+			if errors.Is(err, githook.ErrForeignHook) {
+				fmt.Fprintf(os.Stderr, "%v: %s\nPass --force to replace it.\n", err, path)
+			} else {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+			}
+			os.Exit(1)
+		}
+		fmt.Printf("Git hook installed: %s\n", path)
+		decorf("Commits made during a pomodoro now name it in a %s trailer.\n", githook.TrailerKey)
+	},
+}
+
+// integrateGitTrailerCmd adds the trailer to a commit message; the hook runs it
+var integrateGitTrailerCmd = &cobra.Command{
+	Use:    "trailer <message-file> [source]",
+	Short:  "Adds the running pomodoro to a commit message",
+	Hidden: true,
+	Args:   cobra.RangeArgs(1, 2),
+	Run: func(_ *cobra.Command, args []string) {
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		session, err := database.GetActiveSession()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		if session == nil || session.WasBreak {
+			return
+		}
+
+		if err := githook.AddTrailer(args[0], githook.Trailer(session.ShortRef(), session.Description)); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(integrateCmd)
+	integrateCmd.AddCommand(integrateGitCmd)
+	integrateGitCmd.AddCommand(integrateGitTrailerCmd)
+
+	integrateGitCmd.Flags().BoolVar(&integrateGitUninstall, "uninstall", false, "Remove the hook from this repository")
+	integrateGitCmd.Flags().BoolVar(&integrateGitForce, "force", false, "Replace a prepare-commit-msg hook of your own")
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethan-k/pomodoro-cli/internal/output"
+)
+
+// printJSON prints v for --json as indented JSON, exiting if it cannot be
+// marshaled
+func printJSON(v any) {
+	if err := output.JSON(v); err != nil {
+		fmt.Fprintf(os.Stderr, "Error marshaling to JSON: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// printJSONLine prints v for --json as JSON on a single line, for the
+// commands whose output scripts read a line at a time
+func printJSONLine(v any) {
+	if err := output.JSONLine(v); err != nil {
+		fmt.Fprintf(os.Stderr, "Error marshaling to JSON: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// jsonTags returns a session's tags as JSON lists them, [] rather than null
+// when it has none
+func jsonTags(tagsCSV string) []string {
+	tags := []string{}
+	for _, tag := range strings.Split(tagsCSV, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/goals"
+)
+
+// configuredLimits returns the daily limits from the config. A latest start
+// time that cannot be parsed is left out, as config set refuses those.
+func configuredLimits(cfg *config.Config) goals.Limits {
+	limits := goals.Limits{DailyMax: cfg.Limits.DailyMaxPomodoros}
+	if cfg.Limits.LatestStartTime != "" {
+		if latest, err := goals.ParseTimeOfDay(cfg.Limits.LatestStartTime); err == nil {
+			limits.LatestStart, limits.HasLatest = latest, true
+		}
+	}
+	return limits
+}
+
+// limitExceeded returns why starting a pomodoro now goes past the daily
+// limits, or "" when it does not or they cannot be checked
+func limitExceeded(database db.DB, now time.Time) string {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return ""
+	}
+	limits := configuredLimits(cfg)
+	if limits.DailyMax == 0 && !limits.HasLatest {
+		return ""
+	}
+	today, err := database.GetSessionStats(now, now)
+	if err != nil {
+		return ""
+	}
+	return limits.Exceeded(today.Completed, now)
+}
+
+// checkLimits warns when starting a pomodoro goes past the daily limits and,
+// with limits.refuse set, returns an error unless override is set
+func checkLimits(database db.DB, override bool) error {
+	reason := limitExceeded(database, app.now())
+	if reason == "" {
+		return nil
+	}
+	cfg, err := config.LoadConfig()
+	if err == nil && cfg.Limits.Refuse && !override {
+		return fmt.Errorf("not starting: %s (use --override to start anyway)", reason)
+	}
+	warnf("Daily limit reached: %s. Time to stop?\n", reason)
+	return nil
+}
+
+// announceLimitReached tells the user to stop for the day when the pomodoro
+// that just finished reached the daily limits
+func announceLimitReached() {
+	database, err := openDB()
+	if err != nil {
+		return
+	}
+	defer func() { _ = database.Close() }()
+
+	if reason := limitExceeded(database, app.now()); reason != "" {
+		if err := app.notify("Time to stop", "Daily limit reached: "+reason+"."); err != nil {
+			warnf("Error sending notification: %v\n", err)
+		}
+	}
+}
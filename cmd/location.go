@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/location"
+)
+
+// locationCmd shows the location pomodoros started now would record
+var locationCmd = &cobra.Command{
+	Use:   "location",
+	Short: "Shows the Wi-Fi network and the location recorded for it",
+	Long: `Shows the Wi-Fi network the machine is on and the location pomodoros
+started now would record, for filling in location.networks.
+
+Recording locations is off until you turn it on. The network name is read
+with the platform's own tools and only ever stored in your database:
+
+  location:
+    record: true
+    networks:
+      CorpWiFi: office
+      Home-5G: home
+    unlabelled: false  # true also records the names of other networks
+
+'pomodoro stats' then breaks focus time down by location.
+
+Example:
+  pomodoro location
+  pomodoro config location.record true`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		ssid, err := location.SSID()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		label := location.Label(ssid, cfg.Location.Networks, cfg.Location.Unlabelled)
+
+		if jsonOutput {
+			printJSON(struct {
+				Record   bool   `json:"record"`
+				Network  string `json:"network"`
+				Location string `json:"location"`
+			}{cfg.Location.Record, ssid, label})
+			return
+		}
+
+		if ssid == "" {
+			fmt.Println("Network:  none")
+		} else {
+			fmt.Printf("Network:  %s\n", ssid)
+		}
+		switch {
+		case label != "":
+			fmt.Printf("Location: %s\n", label)
+		case ssid != "":
+			fmt.Println("Location: none (add the network to location.networks to label it)")
+		default:
+			fmt.Println("Location: none")
+		}
+		if !cfg.Location.Record {
+			decorf("\nLocations are not recorded; turn that on with 'pomodoro config location.record true'.\n")
+		}
+	},
+}
+
+// recordLocation stores where pomodoro id is running when the config asks
+// for locations. Failing to tell is not worth interrupting a start for, so
+// errors are only reported.
+func recordLocation(database db.DB, id int64) {
+	cfg, err := config.LoadConfig()
+	if err != nil || !cfg.Location.Record {
+		return
+	}
+	ssid, err := location.SSID()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Not recording location: %v\n", err)
+		return
+	}
+	label := location.Label(ssid, cfg.Location.Networks, cfg.Location.Unlabelled)
+	if label == "" {
+		return
+	}
+	if err := database.SetSessionMetadata(id, db.MetaLocation, label); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving location: %v\n", err)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(locationCmd)
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+var (
+	logFrom     string
+	logTo       string
+	logDuration time.Duration
+	logAgo      time.Duration
+	logTags     []string
+)
+
+// logCmd records a pomodoro that was not timed
+var logCmd = &cobra.Command{
+	Use:   "log [description]",
+	Short: "Records a past pomodoro that was not timed",
+	Long: `Records a completed pomodoro for a focused block done without the timer.
+
+Give two of when it started (--from, or --ago for how long ago), when it
+ended (--to), and how long it ran (--duration). --duration alone means it
+ended just now. Times are HH:MM today, "YYYY-MM-DD HH:MM", or RFC 3339.
+
+The session must have ended already and must not overlap another session.
+It counts toward goals like a timed one, and reaching a goal with a session
+that ended today is announced.
+
+Examples:
+  pomodoro log "Code review" --from 14:00 --to 14:30 --tags review
+  pomodoro log "Code review" --duration 30m --ago 1h
+  pomodoro log "Reading" --from "2024-06-01 09:00" --duration 45m`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		desc := ""
+		if len(args) > 0 {
+			desc = args[0]
+		}
+		desc = utils.SanitizeDescription(desc)
+		if err := utils.ValidateDescription(desc, false); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid description: %v\n", err)
+			os.Exit(1)
+		}
+		sessionTags := utils.SanitizeTags(logTags)
+		if err := utils.ValidateTags(sessionTags); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid tags: %v\n", err)
+			os.Exit(1)
+		}
+
+		now := app.now()
+		var span logTimes
+		flags := cmd.Flags()
+		if flags.Changed("from") && flags.Changed("ago") {
+			fmt.Fprintln(os.Stderr, "--from and --ago both give the start; use one")
+			os.Exit(1)
+		}
+		if flags.Changed("from") {
+			from, err := utils.ParseDateTime(logFrom, now)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error parsing --from: %v\n", err)
+				os.Exit(1)
+			}
+			span.Start = &from
+		}
+		if flags.Changed("ago") {
+			from := now.Add(-logAgo)
+			span.Start = &from
+		}
+		if flags.Changed("to") {
+			to, err := utils.ParseDateTime(logTo, now)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error parsing --to: %v\n", err)
+				os.Exit(1)
+			}
+			span.End = &to
+		}
+		if flags.Changed("duration") {
+			span.Duration = &logDuration
+		}
+		start, end, err := span.resolve(now)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid times: %v\n", err)
+			os.Exit(1)
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		overlap, err := overlappingSession(database, start, end)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		if overlap != nil {
+			fmt.Fprintf(os.Stderr, "Overlaps session %s (%s, %s to %s); fix that one with 'pomodoro edit' instead\n",
+				overlap.ShortRef(), overlap.Description, overlap.StartTime.Local().Format(editTimeLayout), overlap.EndTime.Local().Format("15:04"))
+			os.Exit(1)
+		}
+
+		length := end.Sub(start)
+		id, err := database.CreateSession(start, end, desc, int64(length.Seconds()), strings.Join(sessionTags, ","), false)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating session: %v\n", err)
+			os.Exit(1)
+		}
+		if err := database.SetSessionMetadata(id, db.MetaLogged, now.Format(time.RFC3339)); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+		// Completed already, so the daemon has nothing to announce
+		markNotified(database, id)
+
+		session, err := database.GetSessionByID(id)
+		if err != nil || session == nil {
+			fmt.Fprintf(os.Stderr, "Error reading back session %d: %v\n", id, err)
+			os.Exit(1)
+		}
+		if sameDay(end, now) {
+			announceGoalAchievements(id, false)
+		}
+
+		if jsonOutput {
+			printJSON(newJSONSession(*session, nil))
+			return
+		}
+		fmt.Printf("%sLogged session %s: %s, %s to %s (%s)\n", icon("📝 "), session.ShortRef(), desc,
+			start.Format(editTimeLayout), end.Format("15:04"), length)
+	},
+}
+
+// logTimes are the times given to log; nil fields were not given
+type logTimes struct {
+	Start    *time.Time
+	End      *time.Time
+	Duration *time.Duration
+}
+
+// resolve works out when a logged session ran from two of its start, end,
+// and duration, or from its duration alone as ending at now
+func (t logTimes) resolve(now time.Time) (start, end time.Time, err error) {
+	switch {
+	case t.Start != nil && t.End != nil && t.Duration != nil:
+		return start, end, errors.New("give two of the start (--from or --ago), --to, and --duration, not all three")
+	case t.Start != nil && t.End != nil:
+		start, end = *t.Start, *t.End
+	case t.Start != nil && t.Duration != nil:
+		start, end = *t.Start, t.Start.Add(*t.Duration)
+	case t.End != nil && t.Duration != nil:
+		start, end = t.End.Add(-*t.Duration), *t.End
+	case t.Duration != nil && t.Start == nil && t.End == nil:
+		start, end = now.Add(-*t.Duration), now
+	default:
+		return start, end, errors.New("give --from and --to, or --duration with --from, --ago, or --to")
+	}
+
+	if !end.After(start) {
+		return start, end, fmt.Errorf("the session ends at %s, before it starts at %s", end.Format(editTimeLayout), start.Format(editTimeLayout))
+	}
+	if end.After(now) {
+		return start, end, fmt.Errorf("the session ends at %s, which has not come yet; use 'pomodoro start' to time one", end.Format(editTimeLayout))
+	}
+	if err := utils.ValidateDuration(end.Sub(start)); err != nil {
+		return start, end, fmt.Errorf("invalid duration: %v", err)
+	}
+	return start, end, nil
+}
+
+// overlappingSession returns a session that ran at some point between start
+// and end, or nil when there is none
+func overlappingSession(database db.DB, start, end time.Time) (*db.PomodoroSession, error) {
+	// A day earlier for sessions that started the day before and ran past midnight
+	sessions, err := database.GetSessionsByDateRange(start.AddDate(0, 0, -1), end)
+	if err != nil {
+		return nil, fmt.Errorf("error getting sessions: %v", err)
+	}
+	for i, s := range sessions {
+		if s.StartTime.Before(end) && s.EndTime.After(start) {
+			return &sessions[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// sameDay reports whether a and b fall on the same day where b is
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.In(b.Location()).Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+func init() {
+	rootCmd.AddCommand(logCmd)
+
+	logCmd.Flags().StringVar(&logFrom, "from", "", "When the session started (HH:MM today, \"YYYY-MM-DD HH:MM\", or RFC 3339)")
+	logCmd.Flags().StringVar(&logTo, "to", "", "When the session ended (HH:MM today, \"YYYY-MM-DD HH:MM\", or RFC 3339)")
+	humanDurationVarP(logCmd.Flags(), &logDuration, "duration", "d", 0, "How long the session ran (e.g., 30m, 1h30)")
+	humanDurationVarP(logCmd.Flags(), &logAgo, "ago", "", 0, "How long ago the session started (e.g., 1h), in place of --from")
+	logCmd.Flags().StringSliceVarP(&logTags, "tags", "t", []string{}, "Comma-separated tags for the session")
+	_ = logCmd.RegisterFlagCompletionFunc("tags", completeTags)
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func TestLogTimesResolve(t *testing.T) {
+	now := time.Date(2025, 3, 14, 15, 0, 0, 0, time.UTC)
+	at := func(hour, minute int) *time.Time {
+		t := time.Date(2025, 3, 14, hour, minute, 0, 0, time.UTC)
+		return &t
+	}
+	length := func(d time.Duration) *time.Duration { return &d }
+
+	for _, tc := range []struct {
+		name       string
+		times      logTimes
+		start, end *time.Time
+		wantErr    string
+	}{
+		{"from and to", logTimes{Start: at(14, 0), End: at(14, 30)}, at(14, 0), at(14, 30), ""},
+		{"from and duration", logTimes{Start: at(13, 0), Duration: length(45 * time.Minute)}, at(13, 0), at(13, 45), ""},
+		{"to and duration", logTimes{End: at(14, 30), Duration: length(30 * time.Minute)}, at(14, 0), at(14, 30), ""},
+		{"duration alone ends now", logTimes{Duration: length(25 * time.Minute)}, at(14, 35), at(15, 0), ""},
+		{"all three", logTimes{Start: at(14, 0), End: at(14, 30), Duration: length(30 * time.Minute)}, nil, nil, "not all three"},
+		{"start alone", logTimes{Start: at(14, 0)}, nil, nil, "give --from and --to"},
+		{"backwards", logTimes{Start: at(14, 30), End: at(14, 0)}, nil, nil, "before it starts"},
+		{"in the future", logTimes{Start: at(14, 45), Duration: length(25 * time.Minute)}, nil, nil, "has not come yet"},
+	} {
+		start, end, err := tc.times.resolve(now)
+		if tc.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("%s: got error %v, want one containing %q", tc.name, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil || !start.Equal(*tc.start) || !end.Equal(*tc.end) {
+			t.Errorf("%s: got %s to %s (%v), want %s to %s", tc.name, start, end, err, tc.start, tc.end)
+		}
+	}
+}
+
+func TestLogCommand(t *testing.T) {
+	now := time.Date(2025, 3, 14, 15, 0, 0, 0, time.Local)
+	earlier := db.PomodoroSession{ID: 1, Description: "Plan", StartTime: now.Add(-5 * time.Hour), EndTime: now.Add(-5*time.Hour + 25*time.Minute)}
+
+	var created db.PomodoroSession
+	metadata := map[string]string{}
+	database := &mockDB{
+		GetSessionsByDateRangeFunc: func(time.Time, time.Time, ...string) ([]db.PomodoroSession, error) {
+			return []db.PomodoroSession{earlier}, nil
+		},
+		CreateSessionFunc: func(start, end time.Time, description string, durationSec int64, tagsCSV string, wasBreak bool) (int64, error) {
+			created = db.PomodoroSession{ID: 2, StartTime: start, EndTime: end, Description: description, DurationSec: durationSec, TagsCSV: tagsCSV, WasBreak: wasBreak}
+			return 2, nil
+		},
+		SetSessionMetadataFunc: func(_ int64, key, value string) error {
+			metadata[key] = value
+			return nil
+		},
+		SetSessionStatusFunc: func(_ int64, status string) error {
+			created.Status = status
+			return nil
+		},
+		GetSessionByIDFunc: func(int64) (*db.PomodoroSession, error) { return &created, nil },
+	}
+	a := newTestApp(t, database, now)
+
+	a.run(t, "log", "Code review", "--from", "14:00", "--to", "14:30", "--tags", "review")
+	if !created.StartTime.Equal(now.Add(-time.Hour)) || created.DurationSec != 30*60 || created.TagsCSV != "review" ||
+		created.Status != db.StatusCompleted || metadata[db.MetaLogged] == "" || metadata[db.MetaNotified] == "" {
+		t.Errorf("Unexpected session logged: %+v with %v", created, metadata)
+	}
+
+	overlap, err := overlappingSession(database, earlier.StartTime.Add(10*time.Minute), now.Add(-4*time.Hour))
+	if err != nil || overlap == nil || overlap.ID != earlier.ID {
+		t.Errorf("Expected session %d to overlap, got %v (%v)", earlier.ID, overlap, err)
+	}
+	if overlap, _ := overlappingSession(database, earlier.EndTime, now); overlap != nil {
+		t.Errorf("Expected a session starting as another ends not to overlap, got %+v", overlap)
+	}
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/daemon"
+	"github.com/ethan-k/pomodoro-cli/internal/goals"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+// defaultNudgeAt is when the nudge comes when nudge.at is invalid
+const defaultNudgeAt = 10 * time.Hour
+
+// nudgeCmd groups the commands for the nudge to start the day's first pomodoro
+var nudgeCmd = &cobra.Command{
+	Use:   "nudge",
+	Short: "Manages the reminder to start a pomodoro on days without one",
+	Long: `The daemon nudges you with a notification when no pomodoro has been
+started by nudge.at (10:00 by default) on the days in nudge.days (Monday to
+Friday by default), so a slow morning does not cost you your streak.
+
+Snooze the nudge for a while, or for the rest of the day, or turn it off:
+
+  pomodoro nudge snooze 30m
+  pomodoro nudge snooze today
+  pomodoro nudge off
+
+The nudge needs a daemon running at the time, as 'pomodoro daemon install'
+sets up; a daemon started on demand exits when no session is running.
+
+Example:
+  pomodoro config nudge.at 09:30
+  pomodoro config nudge.days mon,tue,wed,thu`,
+}
+
+// nudgeSnoozeCmd holds off the nudge
+var nudgeSnoozeCmd = &cobra.Command{
+	Use:   "snooze [duration|today]",
+	Short: "Holds off the nudge for a while (1h by default) or for today",
+	Long: `Holds off the nudge for the given time, an hour by default, after which it
+comes again if no pomodoro has been started. With today, it does not come
+again until tomorrow. A snooze lasts until the daemon restarts.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		now := app.now()
+		until := now.Add(time.Hour)
+		if len(args) > 0 {
+			var err error
+			if until, err = snoozeUntil(args[0], now); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		resp, ok, err := daemonSend(daemon.Request{Action: daemon.ActionSnooze, Until: until})
+		if !ok {
+			fmt.Fprintln(os.Stderr, "The daemon is not running, so there is no nudge to snooze.")
+			os.Exit(1)
+		}
+		if err == nil && resp.Error != "" {
+			err = fmt.Errorf("%s", resp.Error)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error snoozing the nudge: %v\n", err)
+			os.Exit(1)
+		}
+
+		if sameDay(until, now) {
+			fmt.Printf("Nudge snoozed until %s.\n", until.Format("15:04"))
+		} else {
+			fmt.Println("Nudge snoozed until tomorrow.")
+		}
+	},
+}
+
+// nudgeOffCmd turns the nudge off
+var nudgeOffCmd = &cobra.Command{
+	Use:   "off",
+	Short: "Turns the nudge off",
+	Args:  cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		setNudge(false)
+		fmt.Println("Nudge turned off. Turn it back on with 'pomodoro nudge on'.")
+	},
+}
+
+// nudgeOnCmd turns the nudge on
+var nudgeOnCmd = &cobra.Command{
+	Use:   "on",
+	Short: "Turns the nudge on",
+	Args:  cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		cfg := setNudge(true)
+		days := "every day"
+		if len(cfg.Nudge.Days) > 0 {
+			days = strings.Join(cfg.Nudge.Days, ", ")
+		}
+		fmt.Printf("Nudge turned on: at %s on %s when no pomodoro has been started.\n", cfg.Nudge.At, days)
+	},
+}
+
+// setNudge turns the nudge on or off in the config file, which a running
+// daemon picks up, and returns the config saved
+func setNudge(enabled bool) *config.Config {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.Nudge.Enabled = enabled
+	if err := config.SaveConfig(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
+		os.Exit(1)
+	}
+	return cfg
+}
+
+// snoozeUntil returns when a snooze given as a duration or "today" ends
+func snoozeUntil(arg string, now time.Time) (time.Time, error) {
+	if strings.EqualFold(arg, "today") {
+		return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location()), nil
+	}
+	d, err := utils.ParseHumanDuration(arg)
+	if err != nil || d <= 0 {
+		return time.Time{}, fmt.Errorf("invalid snooze %q: use a duration such as 30m, or today", arg)
+	}
+	return now.Add(d), nil
+}
+
+// applyNudge turns the nudge on or off from the nudge config
+func applyNudge(d *daemon.Daemon, cfg *config.Config, logger *log.Logger) {
+	if !cfg.Nudge.Enabled {
+		d.EnableNudge(nil, nil)
+		return
+	}
+
+	at, err := goals.ParseTimeOfDay(cfg.Nudge.At)
+	if err != nil {
+		logger.Printf("invalid nudge.at %q, using 10:00", cfg.Nudge.At)
+		at = defaultNudgeAt
+	}
+	days, err := utils.ParseWeekdays(cfg.Nudge.Days)
+	if err != nil {
+		logger.Printf("%v, not nudging", err)
+		d.EnableNudge(nil, nil)
+		return
+	}
+
+	d.EnableNudge(&daemon.Nudge{At: at, Days: days}, func() {
+		if err := app.notify("No pomodoro yet today — start one?", "Run 'pomodoro start', or 'pomodoro nudge snooze' to be reminded later."); err != nil {
+			logger.Printf("error sending notification: %v", err)
+		}
+	})
+	logger.Printf("nudging at %s when no pomodoro has been started", time.Time{}.Add(at).Format("15:04"))
+}
+
+func init() {
+	rootCmd.AddCommand(nudgeCmd)
+	nudgeCmd.AddCommand(nudgeSnoozeCmd)
+	nudgeCmd.AddCommand(nudgeOffCmd)
+	nudgeCmd.AddCommand(nudgeOnCmd)
+}
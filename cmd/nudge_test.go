@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnoozeUntil(t *testing.T) {
+	now := time.Date(2024, 6, 10, 10, 15, 0, 0, time.Local)
+	for _, tc := range []struct {
+		arg  string
+		want time.Time
+	}{
+		{"30m", now.Add(30 * time.Minute)},
+		{"1h30", now.Add(90 * time.Minute)},
+		{"Today", time.Date(2024, 6, 11, 0, 0, 0, 0, time.Local)},
+	} {
+		got, err := snoozeUntil(tc.arg, now)
+		if err != nil || !got.Equal(tc.want) {
+			t.Errorf("snoozeUntil(%q) = %v, %v; want %v", tc.arg, got, err, tc.want)
+		}
+	}
+	for _, arg := range []string{"later", "0m"} {
+		if _, err := snoozeUntil(arg, now); err == nil {
+			t.Errorf("Expected %q to be refused", arg)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/term"
+)
+
+// quietMode suppresses decorative output when set by the global --quiet flag
+var quietMode bool
+
+// jsonOutput is set by the global --json flag
+var jsonOutput bool
+
+// jsonCommands are the commands that print JSON with --json, along with
+// every subcommand of those listed
+var jsonCommands = map[*cobra.Command]bool{
+	aliasListCmd:       true,
+	annotateCmd:        true,
+	breakCmd:           true,
+	cancelCmd:          true,
+	countCmd:           true,
+	daemonStatusCmd:    true,
+	daemonEventsCmd:    true,
+	dbSeedCmd:          true,
+	dbBackupCmd:        true,
+	dbVacuumCmd:        true,
+	dbPruneCmd:         true,
+	dbMigrateCmd:       true,
+	debugSlowLogCmd:    true,
+	deleteCmd:          true,
+	editCmd:            true,
+	energyCmd:          true,
+	extendCmd:          true,
+	goalsCmd:           true,
+	historyCmd:         true,
+	hooksListCmd:       true,
+	importCmd:          true,
+	locationCmd:        true,
+	logCmd:             true,
+	pauseCmd:           true,
+	planCmd:            true,
+	rateCmd:            true,
+	repeatCmd:          true,
+	resumeCmd:          true,
+	searchCmd:          true,
+	serveAuditCmd:      true,
+	serveTokenCmd:      true,
+	showCmd:            true,
+	startCmd:           true,
+	statsCmd:           true,
+	statsQualityCmd:    true,
+	statsTagsCmd:       true,
+	statusCmd:          true,
+	taskCmd:            true,
+	teamLocalReportCmd: true,
+	templateListCmd:    true,
+	templatePruneCmd:   true,
+	templateStartCmd:   true,
+}
+
+// hasJSON reports whether cmd prints JSON with --json
+func hasJSON(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if jsonCommands[c] {
+			return true
+		}
+	}
+	return false
+}
+
+// checkJSON refuses --json for commands without JSON output, so scripts are
+// never handed text they would fail to parse
+func checkJSON(cmd *cobra.Command, _ []string) {
+	if jsonOutput && !hasJSON(cmd) {
+		fmt.Fprintf(os.Stderr, "'%s' has no JSON output\n", cmd.CommandPath())
+		os.Exit(1)
+	}
+}
+
+// Output streams:
+//   - essential results (and all JSON) are written to stdout
+//   - decorative output goes to stdout on a terminal, and to stderr when
+//     stdout is piped or --json is set so it never pollutes data consumed
+//     by scripts
+//   - warnings and errors always go to stderr
+
+// applyDisplayConfig prepares the terminal and applies the display.color
+// setting before any command runs.
+// Config errors are left for the command itself to report.
+func applyDisplayConfig() {
+	term.EnableVirtualTerminal()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return
+	}
+	applyDisplay(cfg)
+}
+
+// applyDisplay applies the display settings from cfg
+func applyDisplay(cfg *config.Config) {
+	if err := term.SetColorMode(cfg.Display.Color); err != nil {
+		warnf("%v; using auto\n", err)
+	}
+	term.SetTagColors(cfg.Display.TagColors)
+}
+
+// decorOut returns the stream for decorative output
+func decorOut() io.Writer {
+	if !jsonOutput && term.IsTerminal(os.Stdout) {
+		return os.Stdout
+	}
+	return os.Stderr
+}
+
+// decorf prints decorative output such as hints and celebrations.
+// It is suppressed by --quiet so scripts only see essential results.
+func decorf(format string, a ...any) {
+	if quietMode {
+		return
+	}
+	fmt.Fprintf(decorOut(), format, a...)
+}
+
+// warnf prints a warning to stderr unless --quiet is set
+func warnf(format string, a ...any) {
+	if quietMode {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "⚠️  "+format, a...)
+}
+
+// emojiEnabled reports whether emoji should be included in output
+func emojiEnabled() bool {
+	return !quietMode && term.EmojiEnabled()
+}
+
+// icon returns the emoji followed by a space, or "" when emoji are disabled
+func icon(emoji string) string {
+	if !emojiEnabled() {
+		return ""
+	}
+	return emoji + " "
+}
+
+// sessionLabel names a session by its description, or as a pomodoro or
+// break when it has none
+func sessionLabel(session *db.PomodoroSession) string {
+	switch {
+	case session.Description != "":
+		return session.Description
+	case session.WasBreak:
+		return "Break"
+	default:
+		return "Pomodoro"
+	}
+}
+
+// sessionIcon returns the marker used for pomodoros and breaks in listings.
+// Plain words replace the emoji when emoji are disabled.
+func sessionIcon(wasBreak bool) string {
+	switch {
+	case !emojiEnabled() && wasBreak:
+		return "break"
+	case !emojiEnabled():
+		return "pomodoro"
+	case wasBreak:
+		return "☕"
+	default:
+		return "🍅"
+	}
+}
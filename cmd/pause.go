@@ -7,7 +7,9 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/ethan-k/pomodoro-cli/internal/daemon"
 	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/hooks"
 )
 
 // pauseCmd represents the pause command
@@ -22,7 +24,7 @@ The paused time will not count toward the session duration.
 Example:
   pomodoro pause`,
 	Run: func(_ *cobra.Command, _ []string) {
-		database, err := db.NewDB()
+		database, err := openDB()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "%v\n", err)
 			os.Exit(1)
@@ -33,8 +35,8 @@ Example:
 			}
 		}()
 
-		// Get active session
-		session, err := database.GetActiveSession()
+		// Get active session, from the daemon when one is running
+		session, err := activeSession(database)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error getting active session: %v\n", err)
 			os.Exit(1)
@@ -50,25 +52,55 @@ Example:
 			return
 		}
 
-		// Pause the session
-		now := time.Now()
-		if err := database.PauseSession(session.ID, now); err != nil {
+		now, err := pauseSession(database, session)
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error pausing session: %v\n", err)
 			os.Exit(1)
 		}
 
 		if jsonOutput {
-			fmt.Printf(`{"id":%d,"description":"%s","status":"paused","paused_at":"%s"}`+"\n",
-				session.ID, session.Description, now.Format(time.RFC3339))
+			printJSONLine(struct {
+				ID          int64  `json:"id"`
+				Description string `json:"description"`
+				Status      string `json:"status"`
+				PausedAt    string `json:"paused_at"`
+			}{session.ID, session.Description, "paused", now.Format(time.RFC3339)})
 			return
 		}
 
-		fmt.Printf("⏸️  Paused session: %s\n", session.Description)
-		fmt.Println("Use 'pomodoro resume' to continue.")
+		fmt.Printf("%sPaused session: %s\n", icon("⏸️ "), session.Description)
+		decorf("Use 'pomodoro resume' to continue.\n")
 	},
 }
 
+// pauseSession pauses the active session, through the daemon when one is
+// running so it stops the timer at once, runs the pause hooks, and returns
+// when the session was paused
+func pauseSession(database db.DB, session *db.PomodoroSession) (time.Time, error) {
+	now, err := recordPause(database, session)
+	if err != nil {
+		return now, err
+	}
+	runHooks(database, hooks.Pause, session.ID)
+	return now, nil
+}
+
+// recordPause pauses session as pauseSession does, without running hooks
+func recordPause(database db.DB, session *db.PomodoroSession) (time.Time, error) {
+	now := app.now()
+	if resp, ok, err := daemonCall(daemon.ActionPause); ok {
+		if err != nil {
+			return now, err
+		}
+		if resp.Session != nil && resp.Session.PausedAt != nil {
+			now = *resp.Session.PausedAt
+		}
+	} else if err := database.PauseSession(session.ID, now); err != nil {
+		return now, err
+	}
+	return now, nil
+}
+
 func init() {
 	rootCmd.AddCommand(pauseCmd)
-	pauseCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
 }
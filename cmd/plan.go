@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/plan"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+// planCmd groups the commands that plan work from outside the timer
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Plan pomodoros from a task list",
+}
+
+// planFromFileCmd queues the open items of a Markdown checklist as tasks
+var planFromFileCmd = &cobra.Command{
+	Use:   "from-file <file>",
+	Short: "Queues the open items of a Markdown checklist as tasks",
+	Long: `Reads the checklist items of a Markdown file, such as a TODO.md, and adds
+each open "- [ ]" item as a task, in the order they appear. Items already
+ticked "- [x]" are skipped. An item can end with an estimate in pomodoros,
+like "- [ ] Write report (3)".
+
+Link pomodoros to the tasks with 'pomodoro start --task <id>'. Once a task
+has all its estimated pomodoros (one without an estimate), or is marked with
+'pomodoro task done', its item is ticked off in the file. When the pomodoros
+the open items still need, on top of today's, are well above your recent
+average, a capacity warning is shown, as when starting one.
+
+Run it again after editing the file: new items are added, items you ticked
+by hand close their tasks, and items already queued are left alone.
+
+Example:
+  pomodoro plan from-file TODO.md
+  pomodoro start --task 12
+  pomodoro plan from-file notes/week.md --json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		path, err := filepath.Abs(utils.ExpandPath(args[0]))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error resolving %s: %v\n", args[0], err)
+			os.Exit(1)
+		}
+		items, err := plan.ParseFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		result, err := planFromFile(database, path, items, app.now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		warning := capacityWarning(database, plannedPomodoros(result.Queue))
+		if jsonOutput {
+			out := struct {
+				File            string     `json:"file"`
+				Added           int        `json:"added"`
+				Closed          int        `json:"closed"`
+				Tasks           []taskJSON `json:"tasks"`
+				CapacityWarning string     `json:"capacity_warning,omitempty"`
+			}{File: path, Added: result.Added, Closed: result.Closed, Tasks: []taskJSON{}, CapacityWarning: warning}
+			for _, t := range result.Queue {
+				out.Tasks = append(out.Tasks, newTaskJSON(t))
+			}
+			printJSON(out)
+			return
+		}
+
+		fmt.Printf("Planned from %s: %d added, %d closed, %d open.\n", args[0], result.Added, result.Closed, len(result.Queue))
+		if len(result.Queue) == 0 {
+			fmt.Println("No open items to work on.")
+			return
+		}
+		for _, t := range result.Queue {
+			fmt.Printf("[ ] %3d  %-40s %s\n", t.ID, t.Title, taskProgress(t.Pomodoros, t.Estimate))
+		}
+		if warning != "" {
+			warnf("%s\n", warning)
+		}
+		decorf("\n%sStart the first with: pomodoro start --task %d\n", icon("💡"), result.Queue[0].ID)
+	},
+}
+
+// plannedPomodoros counts the pomodoros the queued tasks still need: what is
+// left of each estimate, or one for a task without an estimate
+func plannedPomodoros(queue []db.Task) int {
+	planned := 0
+	for _, t := range queue {
+		planned += max(t.Estimate-t.Pomodoros, 1)
+	}
+	return planned
+}
+
+// planResult is what planning from a file changed, and the open tasks it
+// leaves in the file's order
+type planResult struct {
+	Added  int
+	Closed int
+	Queue  []db.Task
+}
+
+// planFromFile brings the tasks planned from path in line with its items:
+// open items without a task get one, and open tasks whose item was ticked
+// are closed. Items with the same title are matched to tasks in order.
+func planFromFile(database db.DB, path string, items []plan.Item, now time.Time) (*planResult, error) {
+	tasks, err := database.ListTasks(false)
+	if err != nil {
+		return nil, err
+	}
+	open := map[string][]db.Task{}
+	for _, t := range tasks {
+		if t.Source == path {
+			open[t.Title] = append(open[t.Title], t)
+		}
+	}
+
+	result := &planResult{}
+	for _, item := range items {
+		var task *db.Task
+		if queued := open[item.Title]; len(queued) > 0 {
+			task, open[item.Title] = &queued[0], queued[1:]
+		}
+
+		switch {
+		case item.Done && task != nil:
+			if err := database.CompleteTask(task.ID, now); err != nil {
+				return nil, err
+			}
+			result.Closed++
+		case item.Done:
+		case task != nil:
+			result.Queue = append(result.Queue, *task)
+		default:
+			if err := utils.ValidateDescription(item.Title, true); err != nil {
+				warnf("skipping line %d: %v\n", item.Line, err)
+				continue
+			}
+			estimate := min(item.Estimate, maxTaskEstimate)
+			id, err := database.AddTask(item.Title, estimate)
+			if err != nil {
+				return nil, err
+			}
+			if err := database.SetTaskSource(id, path); err != nil {
+				return nil, err
+			}
+			result.Added++
+			result.Queue = append(result.Queue, db.Task{ID: id, Title: item.Title, Estimate: estimate, CreatedAt: now, Source: path})
+		}
+	}
+	return result, nil
+}
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+	planCmd.AddCommand(planFromFileCmd)
+}
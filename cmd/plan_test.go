@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func TestPlanCapacityWarning(t *testing.T) {
+	now := time.Date(2024, 6, 10, 9, 0, 0, 0, time.Local)
+	ids := int64(0)
+	a := newTestApp(t, &mockDB{
+		AddTaskFunc: func(string, int) (int64, error) {
+			ids++
+			return ids, nil
+		},
+		// A pomodoro a day over the last week
+		GetSessionsByDateRangeFunc: func(time.Time, time.Time, ...string) ([]db.PomodoroSession, error) {
+			var sessions []db.PomodoroSession
+			for i := 1; i <= 7; i++ {
+				start := now.AddDate(0, 0, -i)
+				sessions = append(sessions, db.PomodoroSession{ID: int64(i), StartTime: start, EndTime: start.Add(25 * time.Minute)})
+			}
+			return sessions, nil
+		},
+	}, now)
+
+	path := filepath.Join(t.TempDir(), "TODO.md")
+	if err := os.WriteFile(path, []byte("- [ ] Write report (3)\n- [ ] Review PR\n- [x] Send invoice\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Added           int    `json:"added"`
+		CapacityWarning string `json:"capacity_warning"`
+	}
+	if err := json.Unmarshal([]byte(a.run(t, "plan", "from-file", path, "--json")), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Added != 2 || !strings.Contains(got.CapacityWarning, "scheduling 4 pomodoros today") {
+		t.Errorf("Expected 2 tasks needing 4 pomodoros to warn, got %+v", got)
+	}
+}
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/trace"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+// invoked is when the process started, near enough, so profiles include
+// loading the config and opening the database
+var invoked = time.Now()
+
+// profileCmd is set by the global --profile-cmd flag: "-" to print a
+// profile on stderr, or a file to write a trace to
+var profileCmd string
+
+// slowThreshold is debug.slow_threshold for this run, 0 when slow
+// invocations are not logged
+var slowThreshold time.Duration
+
+// maxSlowLogSize is how large the slow log grows before it is moved aside
+// to <log>.1, replacing the one there
+const maxSlowLogSize = 1 << 20
+
+// startTrace starts timing the run when it is profiled or slow ones are
+// logged
+func startTrace() {
+	if cfg, err := config.LoadConfig(); err == nil && cfg.Debug.SlowThreshold != "" {
+		slowThreshold, _ = time.ParseDuration(cfg.Debug.SlowThreshold)
+	}
+	if profileCmd != "" || slowThreshold > 0 {
+		trace.Start(invoked)
+	}
+}
+
+// finishTrace writes the profile asked for with --profile-cmd and logs the
+// run when it was slow. Time spent waiting on the user does not count
+// towards the slow log's threshold.
+func finishTrace(cmd *cobra.Command) {
+	if !trace.Enabled() || cmd == nil {
+		return
+	}
+	total := time.Since(invoked)
+
+	switch profileCmd {
+	case "":
+	case "-":
+		printProfile(cmd, total)
+	default:
+		if err := writeTrace(cmd, total); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing trace: %v\n", err)
+		}
+	}
+
+	if slowThreshold > 0 && total-trace.Total(trace.Wait).Duration >= slowThreshold {
+		if err := logSlow(cmd, total); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing slow log: %v\n", err)
+		}
+	}
+}
+
+// printProfile prints where the run's time went on stderr
+func printProfile(cmd *cobra.Command, total time.Duration) {
+	queries := trace.Total(trace.Query)
+	render := trace.Total(trace.Render)
+	wait := trace.Total(trace.Wait)
+	other := max(total-queries.Duration-render.Duration-wait.Duration, 0)
+
+	fmt.Fprintf(os.Stderr, "\nProfile of %s: %s\n", cmd.CommandPath(), roundDuration(total))
+	fmt.Fprintf(os.Stderr, "  Database  %s in %d queries\n", roundDuration(queries.Duration), queries.Count)
+	fmt.Fprintf(os.Stderr, "  Render    %s\n", roundDuration(render.Duration))
+	if wait.Count > 0 {
+		fmt.Fprintf(os.Stderr, "  Waiting   %s\n", roundDuration(wait.Duration))
+	}
+	fmt.Fprintf(os.Stderr, "  Other     %s (startup, config, daemon, hooks)\n", roundDuration(other))
+	if slowest := trace.Slowest(trace.Query, 5); len(slowest) > 0 {
+		fmt.Fprintln(os.Stderr, "Slowest queries:")
+		for _, s := range slowest {
+			fmt.Fprintf(os.Stderr, "  %9s  %s\n", roundDuration(s.Duration), s.Name)
+		}
+	}
+}
+
+// writeTrace writes the run's spans to the --profile-cmd file
+func writeTrace(cmd *cobra.Command, total time.Duration) error {
+	path := utils.ExpandPath(profileCmd)
+	f, err := os.Create(path) // #nosec G304 - the user names the trace file
+	if err != nil {
+		return err
+	}
+	if err := trace.WriteChrome(f, cmd.CommandPath(), total); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Wrote a trace of %s (%s) to %s; open it in chrome://tracing or ui.perfetto.dev\n",
+		cmd.CommandPath(), roundDuration(total), path)
+	return nil
+}
+
+// roundDuration rounds d for reading, to the microsecond below a
+// millisecond and to a tenth of one above
+func roundDuration(d time.Duration) time.Duration {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond)
+	}
+	return d.Round(100 * time.Microsecond)
+}
+
+// slowEntry is a slow invocation in the slow log, one JSON object a line
+type slowEntry struct {
+	At         time.Time   `json:"at"`
+	Command    string      `json:"command"`
+	Flags      []string    `json:"flags,omitempty"` // Names only, as values may be private
+	Version    string      `json:"version"`
+	TotalMS    float64     `json:"total_ms"`
+	WaitMS     float64     `json:"wait_ms,omitempty"`
+	QueryMS    float64     `json:"query_ms"`
+	Queries    int         `json:"queries"`
+	RenderMS   float64     `json:"render_ms"`
+	SlowestSQL []slowQuery `json:"slowest_queries,omitempty"`
+}
+
+// slowQuery is one of the slowest queries of a slow invocation
+type slowQuery struct {
+	Query string  `json:"query"`
+	MS    float64 `json:"ms"`
+}
+
+// ms converts d to fractional milliseconds
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// logSlow appends the run to the slow log
+func logSlow(cmd *cobra.Command, total time.Duration) error {
+	var flags []string
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		flags = append(flags, "--"+f.Name)
+	})
+	queries := trace.Total(trace.Query)
+	entry := slowEntry{
+		At:       time.Now(),
+		Command:  cmd.CommandPath(),
+		Flags:    flags,
+		Version:  appVersion,
+		TotalMS:  ms(total),
+		WaitMS:   ms(trace.Total(trace.Wait).Duration),
+		QueryMS:  ms(queries.Duration),
+		Queries:  queries.Count,
+		RenderMS: ms(trace.Total(trace.Render).Duration),
+	}
+	for _, s := range trace.Slowest(trace.Query, 3) {
+		entry.SlowestSQL = append(entry.SlowestSQL, slowQuery{Query: s.Name, MS: ms(s.Duration)})
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	path, err := slowLogPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() > maxSlowLogSize {
+		_ = os.Rename(path, path+".1")
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600) // #nosec G304 - the path comes from the config
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// slowLogPath returns the slow log's path from the config
+func slowLogPath() (string, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(cfg.Debug.SlowLog) == "" {
+		return "", fmt.Errorf("debug.slow_log is not set")
+	}
+	return utils.ExpandPath(cfg.Debug.SlowLog), nil
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&profileCmd, "profile-cmd", "", "Time database queries and rendering and print where the time went, or write a trace to the file given (--profile-cmd=trace.json)")
+	rootCmd.PersistentFlags().Lookup("profile-cmd").NoOptDefVal = "-"
+}
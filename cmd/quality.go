@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/stats"
+	"github.com/ethan-k/pomodoro-cli/internal/trace"
+)
+
+var (
+	rateSession     string
+	qualityDays     int
+	qualityMinRated int
+)
+
+// qualityBarWidth is how many characters a rating of MaxQuality fills
+const qualityBarWidth = 20
+
+// rateCmd rates the focus quality of a pomodoro
+var rateCmd = &cobra.Command{
+	Use:   "rate <rating>",
+	Short: "Rates how focused a pomodoro was (1-5)",
+	Long: `Rates the focus quality of a pomodoro from 1 (scattered) to 5 (deep focus),
+the most recent pomodoro unless --session is given. Rating again replaces the
+rating.
+
+With wellness.rate_quality set, the timer asks for a rating when a pomodoro
+finishes; this command rates one that was skipped or finished without a
+timer. 'pomodoro stats quality' compares the ratings by time of day, session
+length, and tag.
+
+Example:
+  pomodoro rate 4
+  pomodoro rate 2 --session '#a3f9c2'`,
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		rating, ok := stats.ParseQuality(args[0])
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Invalid rating %q: must be between %d and %d\n", args[0], stats.MinQuality, stats.MaxQuality)
+			os.Exit(1)
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		session, err := lastPomodoro(database, rateSession)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error finding session: %v\n", err)
+			os.Exit(1)
+		}
+		if session == nil {
+			fmt.Println("No pomodoro found to rate.")
+			return
+		}
+		if session.WasBreak {
+			fmt.Fprintf(os.Stderr, "Session %s is a break; only pomodoros are rated\n", session.ShortRef())
+			os.Exit(1)
+		}
+
+		if err := database.SetSessionMetadata(session.ID, db.MetaQuality, strconv.Itoa(rating)); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			printJSONLine(map[string]any{"id": session.ID, db.MetaQuality: rating})
+			return
+		}
+		fmt.Printf("Rated %s %d/%d: %s\n", session.ShortRef(), rating, stats.MaxQuality, session.Description)
+	},
+}
+
+// lastPomodoro returns the session ref names, or without one the most recent
+// pomodoro, skipping breaks taken since
+func lastPomodoro(database db.DB, ref string) (*db.PomodoroSession, error) {
+	if ref != "" {
+		return database.ResolveSession(ref)
+	}
+	sessions, err := database.GetRecentSessions(1, false)
+	if err != nil || len(sessions) == 0 {
+		return nil, err
+	}
+	return &sessions[0], nil
+}
+
+// askQuality asks for a focus quality rating of a pomodoro that just
+// finished in the timer, when wellness.rate_quality is set and there is
+// someone at the terminal to answer. An empty answer skips the rating.
+func askQuality(database db.DB, id int64) {
+	cfg, err := config.LoadConfig()
+	if err != nil || !cfg.Wellness.RateQuality || !isInteractive() || jsonOutput {
+		return
+	}
+	rating, ok := promptQuality(os.Stdin, os.Stdout)
+	if !ok {
+		return
+	}
+	if err := database.SetSessionMetadata(id, db.MetaQuality, strconv.Itoa(rating)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving rating: %v\n", err)
+	}
+}
+
+// promptQuality asks for a rating until it gets a valid one or an empty
+// answer, reporting whether one was given
+func promptQuality(in io.Reader, out io.Writer) (int, bool) {
+	reader := bufio.NewReader(in)
+	for {
+		_, _ = fmt.Fprintf(out, "How focused were you, %d-%d? (Enter to skip) ", stats.MinQuality, stats.MaxQuality)
+		wait := trace.Begin(trace.Wait, "prompt")
+		answer, err := reader.ReadString('\n')
+		wait()
+		if strings.TrimSpace(answer) == "" {
+			if err != nil {
+				_, _ = fmt.Fprintln(out) // end the prompt line on EOF
+			}
+			return 0, false
+		}
+		if rating, ok := stats.ParseQuality(answer); ok {
+			return rating, true
+		}
+		if err != nil {
+			_, _ = fmt.Fprintln(out)
+			return 0, false
+		}
+	}
+}
+
+// statsQualityCmd compares focus quality ratings
+var statsQualityCmd = &cobra.Command{
+	Use:   "quality",
+	Short: "Compares focus quality ratings by time of day, length, tag, and location",
+	Long: `Compares the focus quality you rated pomodoros, by the time of day they
+ran, how long they were planned to be, their tags, and where they ran when
+location.record is on, to find when, for how long, and where you focus
+best. Rate pomodoros as they finish by setting
+wellness.rate_quality, or afterwards with 'pomodoro rate'.
+
+Example:
+  pomodoro stats quality
+  pomodoro stats quality --days 180 --json`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if qualityDays < 1 {
+			fmt.Fprintln(os.Stderr, "--days must be at least 1")
+			os.Exit(1)
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		endDate := app.now()
+		startDate := endDate.AddDate(0, 0, -qualityDays)
+		sessions, err := database.GetSessionsByDateRange(startDate, endDate)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting sessions: %v\n", err)
+			os.Exit(1)
+		}
+		ratings, err := database.GetMetadataByDateRange(db.MetaQuality, startDate, endDate)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		locations, err := database.GetMetadataByDateRange(db.MetaLocation, startDate, endDate)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		report := stats.FocusQuality(sessions, ratings, locations)
+
+		if jsonOutput {
+			printJSON(report)
+			return
+		}
+
+		printQualityReport(report)
+	},
+}
+
+// printQualityReport charts the average rating of each group and names the
+// session length rated best
+func printQualityReport(r stats.QualityReport) {
+	if r.Rated == 0 {
+		fmt.Println("No pomodoros rated yet. Rate one with 'pomodoro rate 4', or set wellness.rate_quality to be asked as they finish.")
+		return
+	}
+
+	fmt.Printf("Focus quality: %.1f/%d over %d rated pomodoros\n", r.Average, stats.MaxQuality, r.Rated)
+	printQualityGroups("By time of day", r.ByTimeOfDay)
+	printQualityGroups("By session length", r.ByLength)
+	printQualityGroups("By tag", r.ByTag)
+	if len(r.ByLocation) > 0 {
+		printQualityGroups("By location", r.ByLocation)
+	}
+
+	if best := r.BestLength(qualityMinRated); best != nil {
+		decorf("\nYou rate %s pomodoros highest; try making that your usual length.\n", best.Label)
+	} else {
+		decorf("\nRate at least %d pomodoros each of two lengths to find the one you focus best for.\n", qualityMinRated)
+	}
+}
+
+// printQualityGroups prints one chart of average ratings, leaving out
+// groups with none
+func printQualityGroups(title string, groups []stats.QualityGroup) {
+	fmt.Printf("\n%s:\n", title)
+	for _, g := range groups {
+		if g.Rated == 0 {
+			continue
+		}
+		fmt.Printf("  %-14s %s %.1f  (%d)\n", g.Label, qualityBar(g.Average), g.Average, g.Rated)
+	}
+}
+
+// qualityBar draws an average rating as a bar of blocks
+func qualityBar(average float64) string {
+	filled := int(math.Round(average * qualityBarWidth / stats.MaxQuality))
+	filled = max(0, min(qualityBarWidth, filled))
+	return strings.Repeat("█", filled) + strings.Repeat("░", qualityBarWidth-filled)
+}
+
+func init() {
+	rootCmd.AddCommand(rateCmd)
+	statsCmd.AddCommand(statsQualityCmd)
+
+	rateCmd.Flags().StringVar(&rateSession, "session", "", "Session ID or reference (default: most recent pomodoro)")
+	statsQualityCmd.Flags().IntVar(&qualityDays, "days", 90, "Number of days to include")
+	statsQualityCmd.Flags().IntVar(&qualityMinRated, "min-rated", 3, "Ratings a session length needs to be compared")
+}
@@ -3,35 +3,71 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 
 	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/hooks"
 	"github.com/ethan-k/pomodoro-cli/internal/model"
-	"github.com/ethan-k/pomodoro-cli/internal/notify"
+	"github.com/ethan-k/pomodoro-cli/internal/term"
+	"github.com/ethan-k/pomodoro-cli/internal/trace"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
 )
 
 var (
-	repeatWait bool
+	repeatWait     bool
+	repeatID       string
+	repeatLastWork bool
+	repeatPick     bool
+	repeatDuration time.Duration
+	repeatTags     []string
+	repeatOverride bool
 )
 
+// repeatPickerSize is the number of recent sessions offered by the picker
+const repeatPickerSize = 10
+
 // repeatCmd represents the repeat command
 var repeatCmd = &cobra.Command{
-	Use:   "repeat",
+	Use:   "repeat [n]",
 	Short: "Repeats the last Pomodoro session",
 	Long: `Repeats the most recently completed Pomodoro session with the same parameters.
 
 This is useful when you want to continue working on the same task.
+Pass a number to repeat the Nth-last session instead (1 is the most recent).
+Use --last-work to skip breaks, --id to repeat a specific session, or --pick
+to choose from the last 10 sessions interactively.
 Use the --wait flag to keep the timer running in the terminal.
 
 Example:
-  pomodoro repeat --wait`,
+  pomodoro repeat --wait
+  pomodoro repeat 3 --last-work
+  pomodoro repeat --id 42 --duration 50m
+  pomodoro repeat --id '#a3f9c2'
+  pomodoro repeat --pick --tags coding`,
 	Aliases: []string{"r"},
-	Run: func(_ *cobra.Command, _ []string) {
+	Args:    cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		nth := 1
+		if len(args) > 0 {
+			var err error
+			nth, err = strconv.Atoi(args[0])
+			if err != nil || nth < 1 {
+				fmt.Fprintf(os.Stderr, "Invalid session position %q: must be a positive number\n", args[0])
+				os.Exit(1)
+			}
+		}
+
+		if repeatID != "" && (repeatPick || len(args) > 0) {
+			fmt.Fprintln(os.Stderr, "--id cannot be combined with --pick or a session position")
+			os.Exit(1)
+		}
+
 		// Connect to database
-		database, err := db.NewDB()
+		database, err := openDB()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "%v\n", err)
 			os.Exit(1)
@@ -42,10 +78,9 @@ Example:
 			}
 		}()
 
-		// Get last session
-		lastSession, err := database.GetLastSession()
+		lastSession, err := findSessionToRepeat(database, nth)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error getting last session: %v\n", err)
+			fmt.Fprintf(os.Stderr, "%v\n", err)
 			os.Exit(1)
 		}
 
@@ -54,9 +89,35 @@ Example:
 			return
 		}
 
-		// Start a new session with the same parameters
+		// Start a new session with the same parameters, applying overrides
 		duration := time.Duration(lastSession.DurationSec) * time.Second
-		startTime := time.Now()
+		if cmd.Flags().Changed("duration") {
+			if err := utils.ValidateDuration(repeatDuration); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid duration: %v\n", err)
+				os.Exit(1)
+			}
+			duration = repeatDuration
+		}
+
+		tagsCSV := lastSession.TagsCSV
+		if cmd.Flags().Changed("tags") {
+			overrideTags := utils.SanitizeTags(repeatTags)
+			if err := utils.ValidateTags(overrideTags); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid tags: %v\n", err)
+				os.Exit(1)
+			}
+			tagsCSV = strings.Join(overrideTags, ",")
+		}
+
+		// Breaks are what the limits are for, so only pomodoros are held back
+		if !lastSession.WasBreak {
+			if err := checkLimits(database, repeatOverride); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		startTime := app.now()
 		endTime := startTime.Add(duration)
 
 		// Create session in database
@@ -64,19 +125,45 @@ Example:
 			startTime,
 			endTime,
 			lastSession.Description,
-			lastSession.DurationSec,
-			lastSession.TagsCSV,
+			int64(duration.Seconds()),
+			tagsCSV,
 			lastSession.WasBreak,
 		)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error creating session: %v\n", err)
 			os.Exit(1)
 		}
+		// Keep counting toward the same task while it is open
+		if lastSession.TaskID != 0 && checkOpenTask(database, lastSession.TaskID) == nil {
+			if err := database.SetSessionTask(id, lastSession.TaskID); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+			}
+		}
+		if metadata, err := database.GetSessionMetadata(lastSession.ID); err == nil && metadata[db.MetaCategory] != "" {
+			if err := database.SetSessionMetadata(id, db.MetaCategory, metadata[db.MetaCategory]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error saving category: %v\n", err)
+			}
+		}
+		if !lastSession.WasBreak {
+			recordLocation(database, id)
+		}
+		runHooks(database, hooks.StartEvent(lastSession.WasBreak), id)
+
+		// Without a terminal timer, a daemon completes the session
+		if jsonOutput || !repeatWait {
+			ensureDaemon()
+		}
 
 		// If JSON output is requested, just print the session info and exit
 		if jsonOutput {
-			fmt.Printf(`{"id":%d,"description":"%s","duration":"%s","end_time":"%s","repeated":true}`+"\n",
-				id, lastSession.Description, duration, endTime.Format(time.RFC3339))
+			printJSONLine(struct {
+				ID           int64  `json:"id"`
+				Description  string `json:"description"`
+				Duration     string `json:"duration"`
+				EndTime      string `json:"end_time"`
+				Repeated     bool   `json:"repeated"`
+				RepeatedFrom int64  `json:"repeated_from"`
+			}{id, lastSession.Description, duration.String(), endTime.Format(time.RFC3339), true, lastSession.ID})
 			return
 		}
 
@@ -97,28 +184,115 @@ Example:
 		)
 
 		// Run the TUI program
-		if _, err := tea.NewProgram(p).Run(); err != nil {
+		finished, err := runTimerUI(database, p)
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error running UI: %v\n", err)
 			os.Exit(1)
 		}
+		if !finished {
+			return
+		}
 
 		// Send notification when complete
+		markNotified(database, id)
+		runHooks(database, hooks.CompleteEvent(lastSession.WasBreak), id)
 		if lastSession.WasBreak {
-			if err := notify.NotifyBreakComplete(); err != nil {
+			if err := app.notifyBreakComplete(false); err != nil {
 				fmt.Fprintf(os.Stderr, "Error sending notification: %v\n", err)
 			}
 		} else {
-			if err := notify.NotifyPomodoroComplete(lastSession.Description); err != nil {
+			if err := app.notifyPomodoroComplete(lastSession.Description, false); err != nil {
 				fmt.Fprintf(os.Stderr, "Error sending notification: %v\n", err)
 			}
+			announceGoalAchievements(id, false)
+			announceLimitReached()
+			askQuality(database, id)
 		}
 	},
 }
 
+// findSessionToRepeat selects the session to repeat based on the flags:
+// a specific ID, an interactive pick, or the Nth-last session
+func findSessionToRepeat(database db.DB, nth int) (*db.PomodoroSession, error) {
+	if repeatID != "" {
+		session, err := database.ResolveSession(repeatID)
+		if err != nil {
+			return nil, err
+		}
+		if session == nil {
+			return nil, fmt.Errorf("no session found for %s", repeatID)
+		}
+		return session, nil
+	}
+
+	limit := nth
+	if repeatPick {
+		limit = repeatPickerSize
+	}
+
+	sessions, err := database.GetRecentSessions(limit, !repeatLastWork)
+	if err != nil {
+		return nil, fmt.Errorf("error getting recent sessions: %v", err)
+	}
+
+	if repeatPick {
+		return pickSession(sessions)
+	}
+
+	if len(sessions) < nth {
+		return nil, nil
+	}
+	return &sessions[nth-1], nil
+}
+
+// pickSession lets the user choose one of the given sessions interactively
+func pickSession(sessions []db.PomodoroSession) (*db.PomodoroSession, error) {
+	if len(sessions) == 0 {
+		return nil, nil
+	}
+	if !isInteractive() {
+		return nil, fmt.Errorf("--pick requires an interactive terminal")
+	}
+
+	fmt.Println("Recent sessions:")
+	for i, s := range sessions {
+		fmt.Printf("%2d. %s %s %s %s (%s) %s\n",
+			i+1,
+			s.ShortRef(),
+			s.StartTime.Format("2006-01-02 15:04"),
+			sessionIcon(s.WasBreak),
+			s.Description,
+			time.Duration(s.DurationSec)*time.Second,
+			term.Tags(s.TagsCSV))
+	}
+	fmt.Print("\nChoose a session to repeat: ")
+
+	var choice string
+	wait := trace.Begin(trace.Wait, "prompt")
+	_, err := fmt.Scanln(&choice)
+	wait()
+	if err != nil {
+		return nil, fmt.Errorf("error reading input: %v", err)
+	}
+
+	index, err := strconv.Atoi(strings.TrimSpace(choice))
+	if err != nil || index < 1 || index > len(sessions) {
+		return nil, fmt.Errorf("invalid choice %q", choice)
+	}
+
+	return &sessions[index-1], nil
+}
+
 func init() {
 	rootCmd.AddCommand(repeatCmd)
 
 	// Define flags for the repeat command
 	repeatCmd.Flags().BoolVarP(&repeatWait, "wait", "w", false, "Wait for the Pomodoro session to complete before exiting")
-	repeatCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format (for non-TTY usage)")
+	repeatCmd.Flags().StringVar(&repeatID, "id", "", "Repeat the session with this ID or reference (42, #a3f, 2024-06-01.3)")
+	repeatCmd.Flags().BoolVar(&repeatLastWork, "last-work", false, "Skip breaks and repeat the last work session")
+	repeatCmd.Flags().BoolVarP(&repeatPick, "pick", "p", false, "Choose from the last 10 sessions interactively")
+	humanDurationVarP(repeatCmd.Flags(), &repeatDuration, "duration", "d", 0, "Override the duration of the repeated session")
+	repeatCmd.Flags().StringSliceVarP(&repeatTags, "tags", "t", []string{}, "Override the tags of the repeated session")
+	repeatCmd.Flags().BoolVar(&repeatOverride, "override", false, "Repeat a pomodoro even when past the daily limits with limits.refuse set")
+	_ = repeatCmd.RegisterFlagCompletionFunc("tags", completeTags)
 }
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func TestFindSessionToRepeat(t *testing.T) {
+	recent := []db.PomodoroSession{
+		{ID: 3, Description: "Write docs"},
+		{ID: 2, Description: "Fix bug"},
+	}
+
+	var gotIncludeBreaks bool
+	mock := &mockDB{
+		GetRecentSessionsFunc: func(limit int, includeBreaks bool) ([]db.PomodoroSession, error) {
+			gotIncludeBreaks = includeBreaks
+			if limit < len(recent) {
+				return recent[:limit], nil
+			}
+			return recent, nil
+		},
+		ResolveSessionFunc: func(ref string) (*db.PomodoroSession, error) {
+			if ref == "42" {
+				return &db.PomodoroSession{ID: 42, Description: "Review"}, nil
+			}
+			return nil, nil
+		},
+	}
+
+	defer func() {
+		repeatID = ""
+		repeatLastWork = false
+	}()
+
+	t.Run("Nth-last session", func(t *testing.T) {
+		session, err := findSessionToRepeat(mock, 2)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if session == nil || session.ID != 2 {
+			t.Errorf("Expected session 2, got: %+v", session)
+		}
+		if !gotIncludeBreaks {
+			t.Error("Expected breaks to be included by default")
+		}
+	})
+
+	t.Run("Position beyond history", func(t *testing.T) {
+		session, err := findSessionToRepeat(mock, 5)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if session != nil {
+			t.Errorf("Expected no session, got: %+v", session)
+		}
+	})
+
+	t.Run("Last work session", func(t *testing.T) {
+		repeatLastWork = true
+		if _, err := findSessionToRepeat(mock, 1); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if gotIncludeBreaks {
+			t.Error("Expected breaks to be excluded with --last-work")
+		}
+		repeatLastWork = false
+	})
+
+	t.Run("By ID", func(t *testing.T) {
+		repeatID = "42"
+		session, err := findSessionToRepeat(mock, 1)
+		if err != nil || session == nil || session.ID != 42 {
+			t.Errorf("Expected session 42, got: %+v (err: %v)", session, err)
+		}
+
+		repeatID = "7"
+		if _, err := findSessionToRepeat(mock, 1); err == nil {
+			t.Error("Expected error for unknown session ID")
+		}
+		repeatID = ""
+	})
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+var replaySpeed float64
+
+// replayCmd animates a past day's sessions
+var replayCmd = &cobra.Command{
+	Use:   "replay [date]",
+	Short: "Replay a day's sessions as an animation",
+	Long: `Replays the sessions of a day in the timer UI, one after another,
+running --speed times faster than real time. Gaps between sessions are
+skipped and sessions still running are left out.
+
+The date can be YYYY-MM-DD, today, yesterday, a weekday, or e.g. 3d; it
+defaults to today.
+
+Example:
+  pomodoro replay yesterday
+  pomodoro replay 2025-03-14 --speed 600`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		if err := validatePlaybackSpeed(replaySpeed); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		dateArg := "today"
+		if len(args) > 0 {
+			dateArg = args[0]
+		}
+		now := app.now()
+		day, err := utils.ParseDate(dateArg, now)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		sessions, err := database.GetSessionsByDateRange(day, day)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting sessions: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Sessions come newest first
+		var steps []playbackStep
+		var focus time.Duration
+		pomodoros := 0
+		for i := len(sessions) - 1; i >= 0; i-- {
+			s := sessions[i]
+			if s.IsPaused || s.EndTime.After(now) || !s.EndTime.After(s.StartTime) {
+				continue
+			}
+			steps = append(steps, playbackStep{
+				Start:       s.StartTime,
+				Length:      s.EndTime.Sub(s.StartTime),
+				Description: s.Description,
+				IsBreak:     s.WasBreak,
+			})
+			if !s.WasBreak {
+				pomodoros++
+				focus += s.EffectiveFocus(now)
+			}
+		}
+
+		if len(steps) == 0 {
+			fmt.Printf("No finished sessions on %s.\n", day.Format("2006-01-02"))
+			return
+		}
+
+		fmt.Printf("%sReplaying %s: %d sessions at %g× speed. Press Ctrl+C to stop.\n",
+			icon("🎬"), day.Format("Monday, 2006-01-02"), len(steps), replaySpeed)
+		if err := playSessions(steps, replaySpeed); err != nil {
+			if errors.Is(err, errPlaybackStopped) {
+				return
+			}
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("\nPomodoros: %d, focus: %s\n", pomodoros, utils.FormatDurationLong(focus))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+	replayCmd.Flags().Float64Var(&replaySpeed, "speed", 300, "How many times faster than real time to run")
+}
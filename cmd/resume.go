@@ -5,12 +5,12 @@ import (
 	"os"
 	"time"
 
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 
+	"github.com/ethan-k/pomodoro-cli/internal/daemon"
 	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/hooks"
 	"github.com/ethan-k/pomodoro-cli/internal/model"
-	"github.com/ethan-k/pomodoro-cli/internal/notify"
 )
 
 var (
@@ -30,7 +30,7 @@ Example:
   pomodoro resume
   pomodoro resume --wait`,
 	Run: func(_ *cobra.Command, _ []string) {
-		database, err := db.NewDB()
+		database, err := openDB()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "%v\n", err)
 			os.Exit(1)
@@ -53,56 +53,101 @@ Example:
 			return
 		}
 
-		// Calculate new end time
-		now := time.Now()
+		now := app.now()
 
 		// Original duration minus already elapsed time when paused
-		originalDuration := time.Duration(session.DurationSec) * time.Second
-		elapsedWhenPaused := session.PausedAt.Sub(session.StartTime)
-		remainingDuration := originalDuration - elapsedWhenPaused
+		remainingDuration := session.RemainingAtPause()
 
-		newEndTime := now.Add(remainingDuration)
-
-		// Resume the session
-		if err := database.ResumeSession(session.ID, newEndTime); err != nil {
+		newEndTime, err := resumeSession(database, session, jsonOutput || !resumeWait)
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error resuming session: %v\n", err)
 			os.Exit(1)
 		}
 
 		if jsonOutput {
-			fmt.Printf(`{"id":%d,"description":"%s","status":"resumed","new_end_time":"%s","remaining_duration":"%s"}`+"\n",
-				session.ID, session.Description, newEndTime.Format(time.RFC3339), remainingDuration)
+			printJSONLine(struct {
+				ID                int64  `json:"id"`
+				Description       string `json:"description"`
+				Status            string `json:"status"`
+				NewEndTime        string `json:"new_end_time"`
+				RemainingDuration string `json:"remaining_duration"`
+			}{session.ID, session.Description, "resumed", newEndTime.Format(time.RFC3339), remainingDuration.String()})
 			return
 		}
 
-		fmt.Printf("▶️  Resumed session: %s\n", session.Description)
+		fmt.Printf("%sResumed session: %s\n", icon("▶️ "), session.Description)
 		fmt.Printf("Time remaining: %s\n", remainingDuration.Round(time.Second))
 
 		// If wait flag is set, show the progress bar
 		if resumeWait {
 			p := model.NewPomodoroModel(session.ID, session.Description, now, remainingDuration, session.WasBreak)
 
-			if _, err := tea.NewProgram(p).Run(); err != nil {
+			finished, err := runTimerUI(database, p)
+			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error running UI: %v\n", err)
 				os.Exit(1)
 			}
+			if !finished {
+				return
+			}
 
 			// Send completion notification
+			markNotified(database, session.ID)
+			runHooks(database, hooks.CompleteEvent(session.WasBreak), session.ID)
 			if session.WasBreak {
-				if err := notify.NotifyBreakComplete(); err != nil {
+				if err := app.notifyBreakComplete(false); err != nil {
 					fmt.Fprintf(os.Stderr, "Error sending notification: %v\n", err)
 				}
 			} else {
-				if err := notify.NotifyPomodoroComplete(session.Description); err != nil {
+				if err := app.notifyPomodoroComplete(session.Description, false); err != nil {
 					fmt.Fprintf(os.Stderr, "Error sending notification: %v\n", err)
 				}
+				announceGoalAchievements(session.ID, false)
+				announceLimitReached()
+				askQuality(database, session.ID)
 			}
 		}
 	},
 }
 
+// resumeSession resumes a paused session, through the daemon when one is
+// running, runs the resume hooks, and returns the session's new end time.
+// With background set, a daemon is started to time the rest of the session
+// when none is running.
+func resumeSession(database db.DB, session *db.PomodoroSession, background bool) (time.Time, error) {
+	newEndTime, recorded, err := recordResume(database, session)
+	if err != nil {
+		return newEndTime, err
+	}
+	if recorded && background {
+		ensureDaemon()
+	}
+	runHooks(database, hooks.Resume, session.ID)
+	return newEndTime, nil
+}
+
+// recordResume resumes session as resumeSession does, without running hooks
+// or starting the daemon. It reports whether it recorded the resume itself,
+// rather than the daemon already running doing so.
+func recordResume(database db.DB, session *db.PomodoroSession) (time.Time, bool, error) {
+	now := app.now()
+	newEndTime := now.Add(session.RemainingAtPause())
+	if resp, ok, err := daemonCall(daemon.ActionResume); ok {
+		if err != nil {
+			return newEndTime, false, err
+		}
+		if resp.Session != nil {
+			newEndTime = resp.Session.EndTime
+		}
+		return newEndTime, false, nil
+	}
+	if err := database.ResumeSession(session.ID, now); err != nil {
+		return newEndTime, false, err
+	}
+	return newEndTime, true, nil
+}
+
 func init() {
 	rootCmd.AddCommand(resumeCmd)
 	resumeCmd.Flags().BoolVarP(&resumeWait, "wait", "w", false, "Wait and show progress bar after resuming")
-	resumeCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
 }
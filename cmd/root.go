@@ -3,8 +3,12 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
 )
 
 var (
@@ -12,14 +16,44 @@ var (
 	appBuildDate = "unknown"
 )
 
+// debugMode is set by the global --debug flag
+var debugMode bool
+
 var rootCmd = &cobra.Command{
 	Use:   "pomodoro",
 	Short: "A minimalist macOS CLI Pomodoro timer",
 	Long: `pomodoro is a friction-free terminal tool that starts a Pomodoro timer,
 shows progress, saves sessions, and sends notifications.
 
-It aims to be fast, scriptable, and visually informative.`,
-	Version: appVersion,
+It aims to be fast, scriptable, and visually informative.
+
+'pomodoro 25m' is short for 'pomodoro start 25m', and takes the same flags.`,
+	Version:          appVersion,
+	PersistentPreRun: preRun,
+}
+
+// preRun checks a command may run as invoked before it does
+func preRun(cmd *cobra.Command, args []string) {
+	checkJSON(cmd, args)
+	guardHookPermissions(cmd, args)
+	checkDatabase(cmd, args)
+}
+
+func init() {
+	cobra.OnInitialize(startTrace, applyDebugMode, checkState, applyDisplayConfig)
+	rootCmd.PersistentFlags().BoolVarP(&quietMode, "quiet", "q", false, "Suppress decorative output (emoji, hints, celebrations)")
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Output in JSON format, for commands that have it")
+	rootCmd.PersistentFlags().BoolVar(&safeMode, "safe-mode", false, "Back up a broken config file or database and continue with defaults")
+	rootCmd.PersistentFlags().StringVar(&dbFlag, "db", "", "Session database to use instead of paths.database from the config")
+	rootCmd.PersistentFlags().StringVar(&userFlag, "user", "", "Local user whose sessions to use, each kept in a data directory of its own (default $POMODORO_USER)")
+	rootCmd.PersistentFlags().BoolVar(&debugMode, "debug", false, "Check session time accounting after every change and fail when it is inconsistent")
+}
+
+// applyDebugMode turns on the database's accounting checks for --debug
+func applyDebugMode() {
+	if debugMode {
+		db.EnableInvariantChecks()
+	}
 }
 
 // SetVersionInfo sets the version information for the application
@@ -29,9 +63,56 @@ func SetVersionInfo(version, buildDate string) {
 	rootCmd.Version = fmt.Sprintf("%s (built on %s)", version, appBuildDate)
 }
 
+// commandArg returns the index in args of the command name, after any global
+// flags, or -1 when there is none
+func commandArg(args []string) int {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return -1
+		}
+		if !strings.HasPrefix(arg, "-") {
+			return i
+		}
+		// The value of a global flag not given with = is the next argument
+		name := strings.TrimLeft(arg, "-")
+		if strings.Contains(name, "=") {
+			continue
+		}
+		flag := rootCmd.PersistentFlags().Lookup(name)
+		if !strings.HasPrefix(arg, "--") && len(name) == 1 {
+			flag = rootCmd.PersistentFlags().ShorthandLookup(name)
+		}
+		if flag != nil && flag.NoOptDefVal == "" {
+			i++
+		}
+	}
+	return -1
+}
+
+// shortcutArgs turns "pomodoro 25m ..." into "pomodoro start 25m ...". Global
+// flags may come before the duration.
+func shortcutArgs(args []string) []string {
+	i := commandArg(args)
+	if i < 0 {
+		return args
+	}
+	if _, ok := durationArg(args[i]); ok {
+		return slices.Concat(args[:i], []string{startCmd.Name()}, args[i:])
+	}
+	return args
+}
+
 // Execute runs the root command of the CLI application
 func Execute() {
-	err := rootCmd.Execute()
+	args, err := expandAlias(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	rootCmd.SetArgs(shortcutArgs(args))
+	cmd, err := rootCmd.ExecuteC()
+	finishTrace(cmd)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 		os.Exit(1)
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/trace"
+)
+
+// safeMode is set by the global --safe-mode flag. It repairs a broken config
+// file or database without asking.
+var safeMode bool
+
+// checkState runs before every command and picks the database to use. When
+// the config file cannot be loaded it offers safe mode: the broken file is
+// moved aside and the command continues with defaults. Without safe mode the
+// problem is left for the command to report as before.
+func checkState() {
+	if _, err := config.LoadConfig(); err != nil {
+		recoverConfig(err)
+	}
+	path, err := resolveDatabasePath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	databasePath = path
+}
+
+// checkDatabase runs before a command once it is known, and offers safe mode
+// when the database cannot be opened: the broken file is moved aside, or an
+// in-memory database is used when a fresh one cannot be created either.
+// Commands run as a shell opens or completes a word must stay quick, and
+// open the database themselves if at all, so they skip it.
+func checkDatabase(cmd *cobra.Command, _ []string) {
+	if skipsDatabaseCheck(cmd) {
+		return
+	}
+
+	// Opened without migrating, so 'db migrate --status' sees the schema as
+	// it was; commands migrate it when they open it themselves
+	database, err := db.Open(databasePath)
+	if err != nil {
+		recoverDatabase(err)
+		return
+	}
+	if err := database.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+	}
+}
+
+// skipsDatabaseCheck reports whether cmd runs without checkDatabase
+func skipsDatabaseCheck(cmd *cobra.Command) bool {
+	if cmd == shellInitCmd {
+		return true
+	}
+	for c := cmd; c != nil; c = c.Parent() {
+		switch c.Name() {
+		case "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+			return true
+		}
+	}
+	return false
+}
+
+// recoverConfig backs up an unreadable config file so defaults are used
+func recoverConfig(loadErr error) {
+	path, err := config.Path()
+	if err != nil {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "⚠️  The config file %s could not be loaded: %v\n", path, loadErr)
+	if !confirmSafeMode("Back it up and continue with default settings?") {
+		return
+	}
+
+	backup, err := backupBroken(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error backing up config file: %v\n", err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Safe mode: moved the broken config to %s and using default settings.\n", backup)
+}
+
+// recoverDatabase backs up an unusable database and starts a new one,
+// falling back to in-memory storage when that fails too
+func recoverDatabase(openErr error) {
+	fmt.Fprintf(os.Stderr, "⚠️  The session database could not be opened: %v\n", openErr)
+	if !confirmSafeMode("Back it up and continue with a new database?") {
+		return
+	}
+
+	if fileExists(databasePath) {
+		backup, err := backupBroken(databasePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error backing up database: %v\n", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Safe mode: moved the broken database to %s.\n", backup)
+		}
+	}
+
+	database, err := openDB()
+	if err == nil {
+		if err := database.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+		}
+		fmt.Fprintln(os.Stderr, "Safe mode: started a new, empty session database.")
+		return
+	}
+
+	db.UseInMemory()
+	fmt.Fprintf(os.Stderr, "Safe mode: a new database could not be created either (%v).\n", err)
+	fmt.Fprintln(os.Stderr, "Safe mode: using temporary in-memory storage; sessions will not be saved.")
+}
+
+// confirmSafeMode reports whether to repair a broken file: always with
+// --safe-mode, after asking in an interactive terminal, and never otherwise
+func confirmSafeMode(question string) bool {
+	if safeMode {
+		return true
+	}
+	if isInteractive() && !jsonOutput {
+		fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+		wait := trace.Begin(trace.Wait, "prompt")
+		answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		wait()
+		if err != nil {
+			fmt.Fprintln(os.Stderr) // end the prompt line on EOF
+		}
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer == "y" || answer == "yes" {
+			return true
+		}
+	}
+
+	warnf("run with --safe-mode to back up the broken file and continue\n")
+	return false
+}
+
+// backupBroken renames a broken file, together with any SQLite -wal and
+// -shm companions, to a timestamped .broken copy and returns its path
+func backupBroken(path string) (string, error) {
+	suffix := ".broken-" + time.Now().Format("20060102-150405")
+	for _, ext := range []string{"-wal", "-shm"} {
+		if fileExists(path + ext) {
+			if err := os.Rename(path+ext, path+suffix+ext); err != nil {
+				return "", fmt.Errorf("error moving %s: %v", path+ext, err)
+			}
+		}
+	}
+	if err := os.Rename(path, path+suffix); err != nil {
+		return "", fmt.Errorf("error moving %s: %v", path, err)
+	}
+	return path + suffix, nil
+}
+
+// fileExists reports whether path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
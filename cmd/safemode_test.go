@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestBackupBrokenMovesCompanionFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "history.db")
+	for _, p := range []string{path, path + "-wal"} {
+		if err := os.WriteFile(p, []byte("garbage"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backup, err := backupBroken(path)
+	if err != nil {
+		t.Fatalf("backupBroken failed: %v", err)
+	}
+
+	if !strings.HasPrefix(backup, path+".broken-") {
+		t.Errorf("Expected a timestamped .broken path, got %s", backup)
+	}
+	if fileExists(path) || fileExists(path+"-wal") {
+		t.Error("Expected the broken files to be moved away")
+	}
+	if !fileExists(backup) || !fileExists(backup+"-wal") {
+		t.Error("Expected the database and its WAL file to be kept as backups")
+	}
+}
+
+func TestSkipsDatabaseCheck(t *testing.T) {
+	complete := &cobra.Command{Use: cobra.ShellCompRequestCmd}
+	completion := &cobra.Command{Use: "completion"}
+	bash := &cobra.Command{Use: "bash"}
+	completion.AddCommand(bash)
+	for cmd, want := range map[*cobra.Command]bool{
+		shellInitCmd: true,
+		complete:     true,
+		bash:         true,
+		startCmd:     false,
+		goalsCmd:     false,
+	} {
+		if got := skipsDatabaseCheck(cmd); got != want {
+			t.Errorf("Expected skipsDatabaseCheck(%q) to be %v", cmd.CommandPath(), want)
+		}
+	}
+}
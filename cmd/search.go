@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/term"
+	"github.com/ethan-k/pomodoro-cli/internal/trace"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+var (
+	searchTags             []string
+	searchFrom             string
+	searchTo               string
+	searchLimit            int
+	searchIncludeCancelled bool
+)
+
+// searchMatch highlights the words searched for in descriptions
+var searchMatch = lipgloss.NewStyle().Bold(true).Underline(true)
+
+// searchCmd finds sessions by the words in their descriptions
+var searchCmd = &cobra.Command{
+	Use:   "search <words>",
+	Short: "Finds sessions by the words in their descriptions",
+	Long: `Finds the sessions whose descriptions contain all the words given, in any
+case, newest first, across your whole history. Quote words to have them
+appear together: '"api refactor" auth'. Narrow the search with --tags and
+a date range; dates are given as for history.
+
+Cancelled and abandoned sessions are left out unless --include-cancelled is
+given.
+
+Examples:
+  pomodoro search "api refactor"
+  pomodoro search api refactor --tags backend --from 2025-01-01
+  pomodoro search review --from 30d --limit 0
+  pomodoro search migration --json`,
+	Run: func(_ *cobra.Command, args []string) {
+		query := strings.Join(args, " ")
+		if len(db.SearchTerms(query)) == 0 && len(searchTags) == 0 {
+			fmt.Fprintln(os.Stderr, "Give words to search for, or --tags")
+			os.Exit(1)
+		}
+
+		opts := db.SearchOptions{
+			Query:             query,
+			Tags:              searchTags,
+			IncludeIncomplete: searchIncludeCancelled,
+			Limit:             searchLimit,
+		}
+		now := app.now()
+		var err error
+		if searchFrom != "" {
+			if opts.From, err = utils.ParseDate(searchFrom, now); err != nil {
+				fmt.Fprintf(os.Stderr, "Error parsing from date: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if searchTo != "" {
+			if opts.To, err = utils.ParseDate(searchTo, now); err != nil {
+				fmt.Fprintf(os.Stderr, "Error parsing to date: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		sessions, err := database.SearchSessions(opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		render := trace.Begin(trace.Render, "search")
+		defer render()
+
+		if jsonOutput {
+			data, err := sessionsJSON(sessions, time.Local)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error marshaling to JSON: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+			return
+		}
+
+		if len(sessions) == 0 {
+			fmt.Println("No sessions found.")
+			return
+		}
+
+		terms := db.SearchTerms(query)
+		var focus time.Duration
+		for _, s := range sessions {
+			duration := s.EndTime.Sub(s.StartTime)
+			if !s.WasBreak {
+				focus += duration
+			}
+			status := ""
+			if s.Incomplete() {
+				status = " [" + s.Status + "]"
+			}
+			fmt.Printf("%s %s %s: %s (%s)%s %s\n",
+				s.ShortRef(),
+				inZone(s.StartTime, s, time.Local).Format("2006-01-02 15:04"),
+				sessionIcon(s.WasBreak),
+				highlightTerms(s.Description, terms),
+				duration.Round(time.Second),
+				status,
+				term.Tags(s.TagsCSV))
+		}
+
+		fmt.Printf("\nSessions found: %d\n", len(sessions))
+		fmt.Printf("Focus time: %s\n", focus.Round(time.Minute))
+		if searchLimit > 0 && len(sessions) == searchLimit {
+			decorf("Showing the newest %d; use --limit 0 to see them all.\n", searchLimit)
+		}
+	},
+}
+
+// highlightTerms marks where the terms searched for appear in s, when
+// color is on
+func highlightTerms(s string, terms []string) string {
+	if !term.ColorEnabled() || len(terms) == 0 {
+		return s
+	}
+
+	// Mark the bytes of s covered by any term, ignoring ASCII case as the
+	// search does
+	lower := asciiLower(s)
+	marked := make([]bool, len(s))
+	for _, t := range terms {
+		t = asciiLower(t)
+		for from := 0; from < len(lower); {
+			i := strings.Index(lower[from:], t)
+			if i < 0 {
+				break
+			}
+			for j := from + i; j < from+i+len(t); j++ {
+				marked[j] = true
+			}
+			from += i + len(t)
+		}
+	}
+
+	var out strings.Builder
+	for start := 0; start < len(s); {
+		end := start
+		for end < len(s) && marked[end] == marked[start] {
+			end++
+		}
+		if marked[start] {
+			out.WriteString(searchMatch.Render(s[start:end]))
+		} else {
+			out.WriteString(s[start:end])
+		}
+		start = end
+	}
+	return out.String()
+}
+
+// asciiLower lowers the case of ASCII letters only, keeping every byte
+// where it was
+func asciiLower(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if 'A' <= c && c <= 'Z' {
+			b[i] = c + 'a' - 'A'
+		}
+	}
+	return string(b)
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+
+	searchCmd.Flags().StringSliceVarP(&searchTags, "tags", "t", []string{}, "Only sessions with any of these tags")
+	_ = searchCmd.RegisterFlagCompletionFunc("tags", completeTags)
+	searchCmd.Flags().StringVar(&searchFrom, "from", "", "Start date (YYYY-MM-DD, yesterday, monday, 7d, ...)")
+	searchCmd.Flags().StringVar(&searchTo, "to", "", "End date (YYYY-MM-DD, yesterday, monday, 7d, ...)")
+	searchCmd.Flags().IntVar(&searchLimit, "limit", 50, "Most sessions to show, 0 for all")
+	searchCmd.Flags().BoolVar(&searchIncludeCancelled, "include-cancelled", false, "Include cancelled and abandoned sessions")
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/secrets"
+	"github.com/ethan-k/pomodoro-cli/internal/term"
+	"github.com/ethan-k/pomodoro-cli/internal/trace"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+// secretsCmd groups the secret storage commands
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Stores integration credentials outside the config file",
+	Long: `Stores credentials for integrations, such as Slack, Toggl, Jira, or
+calendar API tokens, so they never sit in plaintext in config.yml.
+
+Secrets go in the OS keychain (the macOS Keychain, the Windows Credential
+Manager, or the Secret Service on Linux desktops). Where there is none, as
+on a headless server, they go in an AES-encrypted file, secrets.file, whose
+key is kept beside it readable only by you, or derived from the
+` + secrets.PassphraseEnv + ` environment variable when it is set.
+Set secrets.backend to keyring or file to use only one of them.`,
+}
+
+// secretsSetCmd stores a secret
+var secretsSetCmd = &cobra.Command{
+	Use:   "set <name> [value]",
+	Short: "Stores a secret, read from standard input unless given",
+	Long: `Stores a secret under name, replacing any before it. Without a value the
+secret is read from standard input, without echoing it at a terminal, which
+keeps it out of your shell history.
+
+Example:
+  pomodoro secrets set slack.token
+  pass show toggl | pomodoro secrets set toggl.token`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(_ *cobra.Command, args []string) {
+		name := args[0]
+		if err := secrets.ValidateName(name); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		store := openSecrets()
+
+		var value string
+		if len(args) == 2 {
+			value = args[1]
+		} else {
+			var err error
+			if value, err = readSecret(name); err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading secret: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if value == "" {
+			fmt.Fprintln(os.Stderr, "The secret is empty; use 'pomodoro secrets delete' to remove one")
+			os.Exit(1)
+		}
+
+		if err := store.Set(name, value); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Stored %s in the %s.\n", name, backendName(store))
+	},
+}
+
+// secretsGetCmd prints a secret
+var secretsGetCmd = &cobra.Command{
+	Use:   "get <name>",
+	Short: "Prints a secret",
+	Long: `Prints the secret stored under name, for scripts and hooks to use.
+
+Example:
+  curl -H "Authorization: Bearer $(pomodoro secrets get toggl.token)" ...`,
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		value, err := openSecrets().Get(args[0])
+		if errors.Is(err, secrets.ErrNotFound) {
+			fmt.Fprintf(os.Stderr, "No secret named %s\n", args[0])
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(value)
+	},
+}
+
+// secretsDeleteCmd removes a secret
+var secretsDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Removes a secret",
+	Args:  cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		store := openSecrets()
+		err := store.Delete(args[0])
+		if errors.Is(err, secrets.ErrNotFound) {
+			fmt.Fprintf(os.Stderr, "No secret named %s\n", args[0])
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Deleted %s from the %s.\n", args[0], backendName(store))
+	},
+}
+
+// openSecrets opens the secret store the config names, exiting on failure
+func openSecrets() secrets.Store {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	store, err := secrets.Open(cfg.Secrets.Backend, utils.ExpandPath(cfg.Secrets.File))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	return store
+}
+
+// backendName describes where store keeps secrets
+func backendName(store secrets.Store) string {
+	if store.Backend() == secrets.BackendKeyring {
+		return "OS keychain"
+	}
+	return "encrypted secrets file"
+}
+
+// readSecret reads a secret from standard input: without echo after a
+// prompt at a terminal, or as the first line of piped input
+func readSecret(name string) (string, error) {
+	if isInteractive() {
+		fmt.Fprintf(os.Stderr, "Value for %s: ", name)
+		wait := trace.Begin(trace.Wait, "prompt")
+		value, err := term.ReadPassword(os.Stdin)
+		wait()
+		fmt.Fprintln(os.Stderr)
+		return strings.TrimSpace(value), err
+	}
+	wait := trace.Begin(trace.Wait, "prompt")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	wait()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func init() {
+	rootCmd.AddCommand(secretsCmd)
+	secretsCmd.AddCommand(secretsSetCmd, secretsGetCmd, secretsDeleteCmd)
+}
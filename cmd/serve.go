@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/metrics"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+var (
+	serveMetrics     string
+	serveAPI         string
+	serveToken       string
+	serveBasePath    string
+	serveTLSCert     string
+	serveTLSKey      string
+	serveCORSOrigins []string
+	serveRateLimit   int
+)
+
+// serveShutdownTimeout is how long requests in flight get to finish when
+// serve is stopped
+const serveShutdownTimeout = 5 * time.Second
+
+// serveCmd serves session data over HTTP
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serves a REST API and session metrics over HTTP",
+	Long: `Runs an HTTP server until interrupted.
+
+With --api ADDR, a REST API on ADDR lets other tools (Raycast, Stream Deck,
+scripts) control the timer without running the CLI for every action:
+
+  GET  /status                      The active session and time left
+  POST /start                       Start a pomodoro; the JSON body may set
+                                    description, tags, duration, task, category
+  POST /pause, /resume, /cancel     Act on the active session
+  GET  /history?limit=20&breaks=true  Recent sessions, newest first
+  GET  /goals                       Progress toward the goals
+  POST /sessions/{id}/annotations   Add a note: {"text": "...", "source": "ci"}
+
+Responses are JSON; errors are {"error": "..."}. Requests from web pages
+(with an Origin header) are refused unless the page's origin is given with
+--cors-origin, and with --token every request must carry
+"Authorization: Bearer TOKEN". To give each client a token of its own,
+read-only or with full control, use 'pomodoro serve token'; once any exists,
+requests must carry one. Listen on 127.0.0.1 unless other machines should
+reach the API; on any other address, or with --base-path, serve refuses to
+start until --token is given or a token is created. Without either, only
+requests made directly from this machine are answered; those a proxy
+forwards, with a Forwarded or X-Forwarded-For header, are refused.
+
+Each client, a token or the address of a client without one, may make
+--rate-limit requests a minute (120 by default, 0 for no limit); past it,
+requests get 429 with a Retry-After header. Every request that could change
+something is recorded, with who made it and what came of it, for
+'pomodoro serve audit' to show. Behind a reverse proxy, every client without
+a token comes from the proxy's address and so shares one limit; give each
+client a token to limit them apart.
+
+With --metrics ADDR, Prometheus metrics are served at /metrics on ADDR:
+whether a session is active and the seconds it has left, pomodoros completed
+today against the daily goal, the current streak in days, and counters of
+completed pomodoros and focus seconds, so focus time can be graphed in
+Grafana. Metrics are read from the database on every scrape.
+
+The API and metrics may share an address. Behind a reverse proxy such as
+Caddy or nginx that forwards a path like /pomodoro/, --base-path serves
+everything under it; every client then needs a token, as the proxy makes
+them all look local. To reach the API from other machines without a proxy,
+serve HTTPS with --tls-cert and --tls-key.
+
+Each flag defaults to its key under serve: in the config file (serve.api,
+serve.metrics, serve.base_path, serve.tls_cert, serve.tls_key,
+serve.cors_origins, serve.rate_limit), so 'pomodoro serve' alone can start a configured server.
+
+Example:
+  pomodoro serve --api 127.0.0.1:7070
+  pomodoro serve --api 127.0.0.1:7070 --metrics 127.0.0.1:7070
+  pomodoro serve --metrics :9090
+  pomodoro serve token create dashboard --scope read
+  pomodoro serve --api 127.0.0.1:7070 --base-path /pomodoro
+  pomodoro serve --api 0.0.0.0:7443 --tls-cert cert.pem --tls-key key.pem \
+    --cors-origin https://dash.example.com`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		applyServeConfig(cmd)
+		if serveMetrics == "" && serveAPI == "" {
+			fmt.Fprintln(os.Stderr, "Nothing to serve; use --api ADDR or --metrics ADDR")
+			os.Exit(1)
+		}
+		if serveRateLimit < 0 {
+			fmt.Fprintln(os.Stderr, "--rate-limit must not be negative")
+			os.Exit(1)
+		}
+		base, err := normalizeBasePath(serveBasePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid base path: %v\n", err)
+			os.Exit(1)
+		}
+		for _, origin := range serveCORSOrigins {
+			if err := validateOrigin(origin); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid CORS origin: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		var tlsConfig *tls.Config
+		if serveTLSCert != "" || serveTLSKey != "" {
+			if serveTLSCert == "" || serveTLSKey == "" {
+				fmt.Fprintln(os.Stderr, "Serving HTTPS needs both --tls-cert and --tls-key")
+				os.Exit(1)
+			}
+			cert, err := tls.LoadX509KeyPair(utils.ExpandPath(serveTLSCert), utils.ExpandPath(serveTLSKey))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error loading TLS certificate: %v\n", err)
+				os.Exit(1)
+			}
+			tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+		}
+		scheme := "http"
+		if tlsConfig != nil {
+			scheme = "https"
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		// Behind a proxy on this machine every client arrives from the
+		// loopback address, so going without a token is only safe when the
+		// API listens there and is not proxied
+		if serveAPI != "" && serveToken == "" && (!loopbackAddr(serveAPI) || base != "") {
+			tokens, err := database.ListAPITokens(false)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing API tokens: %v\n", err)
+				os.Exit(1)
+			}
+			if len(tokens) == 0 {
+				if base != "" {
+					fmt.Fprintf(os.Stderr, "Refusing to serve the API under %s without a token, as anyone who can reach the proxy could control the timer.\n", base)
+					fmt.Fprintln(os.Stderr, "Pass --token, or create one with 'pomodoro serve token create NAME'.")
+				} else {
+					fmt.Fprintf(os.Stderr, "Refusing to serve the API on %s without a token, as anyone who can reach it could control the timer.\n", serveAPI)
+					fmt.Fprintln(os.Stderr, "Pass --token, create one with 'pomodoro serve token create NAME', or listen on 127.0.0.1.")
+				}
+				os.Exit(1)
+			}
+		}
+
+		// One mux per address, so the API and metrics can share a port
+		muxes := map[string]*http.ServeMux{}
+		muxFor := func(addr string) *http.ServeMux {
+			if muxes[addr] == nil {
+				muxes[addr] = http.NewServeMux()
+			}
+			return muxes[addr]
+		}
+		if serveAPI != "" {
+			muxFor(serveAPI).Handle(base+"/", http.StripPrefix(base, newAPIHandler(database, serveToken, serveCORSOrigins, serveRateLimit)))
+			fmt.Printf("Serving the API at %s://%s%s/\n", scheme, serveAPI, base)
+		}
+		if serveMetrics != "" {
+			muxFor(serveMetrics).Handle(base+"/metrics", metrics.Handler(database, func() int {
+				cfg, err := config.LoadConfig()
+				if err != nil {
+					return 0
+				}
+				return cfg.Goals.DailyCount
+			}))
+			fmt.Printf("Serving metrics at %s://%s%s/metrics\n", scheme, serveMetrics, base)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		errs := make(chan error, len(muxes))
+		var servers []*http.Server
+		for addr, mux := range muxes {
+			server := &http.Server{Addr: addr, Handler: mux, TLSConfig: tlsConfig, ReadHeaderTimeout: 10 * time.Second}
+			servers = append(servers, server)
+			go func() {
+				var err error
+				if tlsConfig != nil {
+					err = server.ListenAndServeTLS("", "")
+				} else {
+					err = server.ListenAndServe()
+				}
+				if err != nil && !errors.Is(err, http.ErrServerClosed) {
+					errs <- err
+				}
+			}()
+		}
+
+		var serveErr error
+		select {
+		case <-ctx.Done():
+		case serveErr = <-errs:
+		}
+		shutdown, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+		defer cancel()
+		for _, server := range servers {
+			_ = server.Shutdown(shutdown)
+		}
+		if serveErr != nil {
+			fmt.Fprintf(os.Stderr, "Error serving: %v\n", serveErr)
+			os.Exit(1)
+		}
+	},
+}
+
+// applyServeConfig fills in the serve flags that were not given from the
+// serve section of the config file
+func applyServeConfig(cmd *cobra.Command) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		warnf("could not load config: %v\n", err)
+		return
+	}
+	flags := cmd.Flags()
+	if !flags.Changed("api") {
+		serveAPI = cfg.Serve.API
+	}
+	if !flags.Changed("metrics") {
+		serveMetrics = cfg.Serve.Metrics
+	}
+	if !flags.Changed("base-path") {
+		serveBasePath = cfg.Serve.BasePath
+	}
+	if !flags.Changed("tls-cert") {
+		serveTLSCert = cfg.Serve.TLSCert
+	}
+	if !flags.Changed("tls-key") {
+		serveTLSKey = cfg.Serve.TLSKey
+	}
+	if !flags.Changed("cors-origin") {
+		serveCORSOrigins = cfg.Serve.CORSOrigins
+	}
+	if !flags.Changed("rate-limit") {
+		serveRateLimit = cfg.Serve.RateLimit
+	}
+}
+
+// normalizeBasePath returns path with one leading slash and no trailing
+// one, or "" for the root
+func normalizeBasePath(path string) (string, error) {
+	path = strings.Trim(strings.TrimSpace(path), "/")
+	if path == "" {
+		return "", nil
+	}
+	if strings.ContainsAny(path, "?#") || strings.Contains(path, "//") {
+		return "", fmt.Errorf("%q is not a plain path like /pomodoro", path)
+	}
+	return "/" + path, nil
+}
+
+// validateOrigin checks that origin is a web origin: a scheme and host,
+// with no path. Origins are matched exactly, so "*" is not accepted.
+func validateOrigin(origin string) error {
+	u, err := url.Parse(strings.TrimSuffix(origin, "/"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" || u.RawQuery != "" {
+		return fmt.Errorf("%q is not an origin like https://dash.example.com", origin)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVar(&serveAPI, "api", "", "Serve the REST API on this address (e.g. 127.0.0.1:7070)")
+	serveCmd.Flags().StringVar(&serveToken, "token", "", "Require this bearer token on API requests")
+	serveCmd.Flags().StringVar(&serveMetrics, "metrics", "", "Serve Prometheus metrics at /metrics on this address (e.g. :9090)")
+	serveCmd.Flags().StringVar(&serveBasePath, "base-path", "", "Serve everything under this path, as forwarded by a reverse proxy (e.g. /pomodoro)")
+	serveCmd.Flags().StringVar(&serveTLSCert, "tls-cert", "", "Serve HTTPS with this certificate file")
+	serveCmd.Flags().StringVar(&serveTLSKey, "tls-key", "", "Key file of the --tls-cert certificate")
+	serveCmd.Flags().StringSliceVar(&serveCORSOrigins, "cors-origin", nil, "Allow web pages from this origin to call the API (repeatable)")
+	serveCmd.Flags().IntVar(&serveRateLimit, "rate-limit", config.DefaultServeRateLimit, "API requests each client may make a minute, 0 for no limit")
+}
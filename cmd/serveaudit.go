@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+var (
+	auditLimit  int
+	auditClient string
+	auditSince  string
+)
+
+// serveAuditCmd shows the API audit log
+var serveAuditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Shows who changed what through the API",
+	Long: `Lists the API requests that could change something, newest first: when
+each was made, by which token, what it asked for, how it was answered, and
+the session it acted on. Refused requests are listed too, so a client with
+a wrong token or over the rate limit shows up here.
+
+Requests made with the serve --token secret are listed as (--token), and
+those made while no token was needed as (open).
+
+Example:
+  pomodoro serve audit
+  pomodoro serve audit --token stream-deck --since 7d`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		filter := db.APIAuditFilter{Client: auditClient, Limit: auditLimit}
+		if auditSince != "" {
+			since, err := utils.ParseDate(auditSince, app.now())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error parsing since date: %v\n", err)
+				os.Exit(1)
+			}
+			filter.Since = since
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		entries, err := database.ListAPIAudit(filter)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			out := make([]auditJSON, 0, len(entries))
+			for _, e := range entries {
+				out = append(out, newAuditJSON(e))
+			}
+			printJSON(out)
+			return
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("No API changes recorded.")
+			return
+		}
+		fmt.Printf("%-19s %-20s %-32s %-6s %-8s %s\n", "TIME", "CLIENT", "REQUEST", "STATUS", "SESSION", "FROM")
+		for _, e := range entries {
+			client := e.Client
+			if client == "" {
+				client = "-"
+			}
+			session := "-"
+			if e.SessionID != 0 {
+				session = strconv.FormatInt(e.SessionID, 10)
+			}
+			fmt.Printf("%-19s %-20s %-32s %-6d %-8s %s\n", e.At.Local().Format("2006-01-02 15:04:05"), client,
+				e.Method+" "+e.Path, e.Status, session, e.Remote)
+		}
+	},
+}
+
+// auditJSON is the JSON representation of an API audit entry
+type auditJSON struct {
+	At        string `json:"at"`
+	Client    string `json:"client"`
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Status    int    `json:"status"`
+	SessionID int64  `json:"session_id,omitempty"`
+	Remote    string `json:"remote"`
+}
+
+func newAuditJSON(e db.APIAuditEntry) auditJSON {
+	return auditJSON{
+		At:        e.At.Format(time.RFC3339),
+		Client:    e.Client,
+		Method:    e.Method,
+		Path:      e.Path,
+		Status:    e.Status,
+		SessionID: e.SessionID,
+		Remote:    e.Remote,
+	}
+}
+
+func init() {
+	serveCmd.AddCommand(serveAuditCmd)
+
+	serveAuditCmd.Flags().IntVarP(&auditLimit, "limit", "n", 50, "Number of entries to show, 0 for all")
+	serveAuditCmd.Flags().StringVar(&auditClient, "token", "", "Only show requests made with this token")
+	serveAuditCmd.Flags().StringVar(&auditSince, "since", "", "Only show requests from this date on (YYYY-MM-DD, 7d, ...)")
+}
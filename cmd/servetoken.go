@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+var (
+	tokenScope   string
+	tokenListAll bool
+)
+
+// serveTokenCmd groups the API token commands
+var serveTokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Manages the tokens clients use to reach the API",
+	Long: `Manages API tokens, so each client of 'pomodoro serve --api' can be given
+access of its own and have it revoked without affecting the others.
+
+Once any token exists, every API request must carry one as
+"Authorization: Bearer TOKEN". A read token may only read the status,
+history, and goals; a full token may also start, pause, resume, and cancel
+sessions and add annotations. Only a hash of each token is stored, so a
+token is shown once, when it is created. Changes apply to a running server
+at once.`,
+}
+
+// serveTokenCreateCmd creates an API token
+var serveTokenCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Creates an API token and prints it once",
+	Long: `Creates an API token named after the client that will use it and prints
+the token. Store it in the client now; it cannot be shown again.
+
+Example:
+  pomodoro serve token create stream-deck
+  pomodoro serve token create grafana --scope read`,
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		secret, err := database.CreateAPIToken(args[0], tokenScope)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			printJSONLine(map[string]string{"name": args[0], "scope": tokenScope, "token": secret})
+			return
+		}
+		fmt.Printf("Created %s token %q:\n\n  %s\n\n", tokenScope, args[0], secret)
+		decorf("Copy it now; it will not be shown again.\n")
+	},
+}
+
+// serveTokenListCmd lists API tokens
+var serveTokenListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists API tokens and when each was last used",
+	Args:  cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		tokens, err := database.ListAPITokens(tokenListAll)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			out := make([]tokenJSON, 0, len(tokens))
+			for _, t := range tokens {
+				out = append(out, newTokenJSON(t))
+			}
+			printJSON(out)
+			return
+		}
+
+		if len(tokens) == 0 {
+			fmt.Println("No API tokens. Create one with 'pomodoro serve token create NAME'.")
+			return
+		}
+		fmt.Printf("%-24s %-5s %-16s %s\n", "NAME", "SCOPE", "CREATED", "LAST USED")
+		for _, t := range tokens {
+			lastUsed := "never"
+			if t.LastUsedAt != nil {
+				lastUsed = t.LastUsedAt.Local().Format("2006-01-02 15:04")
+			}
+			if t.RevokedAt != nil {
+				lastUsed += "  (revoked " + t.RevokedAt.Local().Format("2006-01-02") + ")"
+			}
+			fmt.Printf("%-24s %-5s %-16s %s\n", t.Name, t.Scope, t.CreatedAt.Local().Format("2006-01-02 15:04"), lastUsed)
+		}
+	},
+}
+
+// serveTokenRevokeCmd revokes an API token
+var serveTokenRevokeCmd = &cobra.Command{
+	Use:   "revoke <name>",
+	Short: "Revokes an API token",
+	Args:  cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		if err := database.RevokeAPIToken(args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Revoked token %q.\n", args[0])
+	},
+}
+
+// tokenJSON is the JSON representation of an API token, without its secret
+type tokenJSON struct {
+	Name       string `json:"name"`
+	Scope      string `json:"scope"`
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at,omitempty"`
+	RevokedAt  string `json:"revoked_at,omitempty"`
+}
+
+func newTokenJSON(t db.APIToken) tokenJSON {
+	out := tokenJSON{Name: t.Name, Scope: t.Scope, CreatedAt: t.CreatedAt.Format(time.RFC3339)}
+	if t.LastUsedAt != nil {
+		out.LastUsedAt = t.LastUsedAt.Format(time.RFC3339)
+	}
+	if t.RevokedAt != nil {
+		out.RevokedAt = t.RevokedAt.Format(time.RFC3339)
+	}
+	return out
+}
+
+func init() {
+	serveCmd.AddCommand(serveTokenCmd)
+	serveTokenCmd.AddCommand(serveTokenCreateCmd, serveTokenListCmd, serveTokenRevokeCmd)
+
+	serveTokenCreateCmd.Flags().StringVar(&tokenScope, "scope", db.ScopeFull, "What the token may do: read or full")
+	serveTokenListCmd.Flags().BoolVarP(&tokenListAll, "all", "a", false, "Include revoked tokens")
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/goals"
+	"github.com/ethan-k/pomodoro-cli/internal/shellinit"
+)
+
+// shellInitSummary is set by --summary
+var shellInitSummary bool
+
+// shellInitCmd prints the snippet that shows the day's summary in new shells
+var shellInitCmd = &cobra.Command{
+	Use:   "shell-init bash|zsh|fish",
+	Short: "Prints a snippet that shows today's pomodoros when a shell opens",
+	Long: `Prints a snippet for your shell's startup file. Each new interactive shell
+then prints a one-line summary of the day:
+
+  🍅 3/8 pomodoros today · 5-day streak · Write report until 14:25
+
+It also defines shorthands for the commands used most: pstart, pbreak,
+pstatus, ppause, presume, and pcancel, which take the same arguments as
+start, break, status, pause, resume, and cancel.
+
+The line is kept in a state file in your cache directory until the day
+turns, the session it names ends, or the database or config file changes,
+so most shells open without reading the database. --summary prints it, as
+the snippet does.
+
+Example:
+  echo 'eval "$(pomodoro shell-init bash)"' >> ~/.bashrc
+  echo 'eval "$(pomodoro shell-init zsh)"' >> ~/.zshrc
+  echo 'pomodoro shell-init fish | source' >> ~/.config/fish/config.fish`,
+	ValidArgs: shellinit.Shells,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if shellInitSummary {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs)(cmd, args)
+	},
+	Run: func(_ *cobra.Command, args []string) {
+		if shellInitSummary {
+			printShellSummary()
+			return
+		}
+
+		exe, err := os.Executable()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error locating pomodoro executable: %v\n", err)
+			os.Exit(1)
+		}
+		if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+			exe = resolved
+		}
+		script, err := shellinit.Script(args[0], exe)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(script)
+	},
+}
+
+// printShellSummary prints the day's summary line, from the state file
+// while it still holds and worked out and saved there otherwise
+func printShellSummary() {
+	now := app.now()
+	path := databasePath
+	if path == "" {
+		path, _ = db.DefaultPath()
+	}
+	cache, cacheErr := shellinit.CachePath()
+	if cacheErr == nil {
+		if s, err := shellinit.Load(cache); err == nil && s.Fresh(now, path, shellSummaryModified(path)) {
+			fmt.Println(s.Line)
+			return
+		}
+	}
+
+	summary, err := shellSummary(now)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(summary.Line)
+	if cacheErr != nil {
+		return
+	}
+
+	// Read once the database is closed, as closing it may write to it
+	summary.Database = path
+	summary.Modified = shellSummaryModified(path)
+	if err := shellinit.Save(cache, summary); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving the summary: %v\n", err)
+	}
+}
+
+// shellSummaryModified returns when the database at path, or the config
+// file, was last changed
+func shellSummaryModified(path string) time.Time {
+	files := []string{path, path + "-wal"}
+	if cfg, err := config.Path(); err == nil {
+		files = append(files, cfg)
+	}
+	return shellinit.Modified(files...)
+}
+
+// shellSummary works out the summary line: today's pomodoros, against the
+// daily goal when there is one, the streak, and the active session
+func shellSummary(now time.Time) (shellinit.Summary, error) {
+	database, err := openDB()
+	if err != nil {
+		return shellinit.Summary{}, err
+	}
+	defer func() {
+		if err := database.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+		}
+	}()
+
+	sessions, err := todaySessions(func() db.DB { return database })
+	if err != nil {
+		return shellinit.Summary{}, fmt.Errorf("error getting today's sessions: %v", err)
+	}
+	streak, err := goals.Streak(database, now)
+	if err != nil {
+		return shellinit.Summary{}, fmt.Errorf("error getting streak: %v", err)
+	}
+	session, err := activeSession(database)
+	if err != nil {
+		return shellinit.Summary{}, fmt.Errorf("error getting active session: %v", err)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+	summary := shellinit.Summary{Day: now.Format("2006-01-02")}
+	summary.Line, summary.Until = shellSummaryLine(completedPomodoros(sessions, now), cfg.Goals.DailyCount, streak, session)
+	return summary, nil
+}
+
+// shellSummaryLine renders the summary line, and returns until when the
+// session it names runs
+func shellSummaryLine(done, goal, streak int, session *db.PomodoroSession) (string, time.Time) {
+	count := fmt.Sprintf("%d pomodoro(s) today", done)
+	if goal > 0 {
+		count = fmt.Sprintf("%d/%d pomodoros today", done, goal)
+	}
+	parts := []string{count}
+	if streak > 0 {
+		parts = append(parts, fmt.Sprintf("%d-day streak", streak))
+	}
+
+	var until time.Time
+	if session != nil {
+		label := sessionLabel(session)
+		if session.IsPaused {
+			parts = append(parts, label+" paused")
+		} else {
+			parts = append(parts, label+" until "+session.EndTime.Format("15:04"))
+			until = session.EndTime
+		}
+	}
+	return icon("🍅") + strings.Join(parts, " · "), until
+}
+
+func init() {
+	rootCmd.AddCommand(shellInitCmd)
+
+	shellInitCmd.Flags().BoolVar(&shellInitSummary, "summary", false, "Print the summary line the snippet shows")
+}
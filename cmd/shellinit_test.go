@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func TestShellSummary(t *testing.T) {
+	now := time.Date(2026, time.March, 4, 14, 0, 0, 0, time.Local)
+	end := now.Add(-time.Hour)
+	active := &db.PomodoroSession{ID: 3, StartTime: now.Add(-5 * time.Minute), EndTime: now.Add(20 * time.Minute), Description: "Write report"}
+	database := &mockDB{
+		GetTodaySessionsFunc: func() ([]db.PomodoroSession, error) {
+			return []db.PomodoroSession{
+				{ID: 1, StartTime: end.Add(-25 * time.Minute), EndTime: end, Status: db.StatusCompleted},
+				{ID: 2, StartTime: end, EndTime: end.Add(5 * time.Minute), Status: db.StatusCompleted, WasBreak: true},
+				*active,
+			}, nil
+		},
+		GetDailySummariesFunc: func() ([]db.DailySummary, error) {
+			return []db.DailySummary{{Date: "2026-03-03", Pomodoros: 6}, {Date: "2026-03-04", Pomodoros: 1}}, nil
+		},
+		GetActiveSessionFunc: func() (*db.PomodoroSession, error) { return active, nil },
+	}
+	a := newTestApp(t, database, now)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	want := "1/8 pomodoros today · 2-day streak · Write report until 14:20\n"
+	if got := a.run(t, "shell-init", "--summary"); !strings.HasSuffix(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	// The line is read back from the state file until the session ends
+	database.GetTodaySessionsFunc = nil
+	a.now = now.Add(10 * time.Minute)
+	if got := a.run(t, "shell-init", "--summary"); !strings.HasSuffix(got, want) {
+		t.Errorf("Expected the cached %q, got %q", want, got)
+	}
+	a.now = now.Add(30 * time.Minute)
+	active = nil
+	want = "0/8 pomodoros today · 2-day streak\n"
+	if got := a.run(t, "shell-init", "--summary"); !strings.HasSuffix(got, want) {
+		t.Errorf("Expected %q once the session ended, got %q", want, got)
+	}
+}
@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/term"
+)
+
+// sessionDetail is everything known about one session
+type sessionDetail struct {
+	Session     *db.PomodoroSession
+	Status      string
+	Focus       time.Duration
+	Pauses      []db.Pause
+	Metadata    map[string]string
+	Annotations []db.Annotation
+	Task        *db.Task // nil when the session is not linked to a task
+}
+
+// showCmd represents the show command
+var showCmd = &cobra.Command{
+	Use:   "show <session>",
+	Short: "Shows full details of a session",
+	Long: `Shows everything recorded for a session: start and end times, effective
+focus time, pauses, tags, the linked task, metadata such as energy levels, and annotations.
+
+Times are shown in the time zone the session was recorded in.
+
+Example:
+  pomodoro show 42
+  pomodoro show '#a3f9c2'
+  pomodoro show 2024-06-01.3 --json`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSession,
+	Run: func(_ *cobra.Command, args []string) {
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		session, err := database.ResolveSession(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		if session == nil {
+			fmt.Fprintf(os.Stderr, "No session found for %s\n", args[0])
+			os.Exit(1)
+		}
+
+		detail, err := loadSessionDetail(database, session, app.now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			printJSON(detail.toJSON())
+			return
+		}
+
+		detail.print()
+	},
+}
+
+// loadSessionDetail gathers a session's pauses, metadata, and annotations
+func loadSessionDetail(database db.DB, session *db.PomodoroSession, now time.Time) (*sessionDetail, error) {
+	pauses, err := database.GetPauses(session.ID)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := database.GetSessionMetadata(session.ID)
+	if err != nil {
+		return nil, err
+	}
+	annotations, err := database.GetAnnotations(session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var task *db.Task
+	if session.TaskID != 0 {
+		if task, err = database.GetTask(session.TaskID); err != nil {
+			return nil, err
+		}
+	}
+
+	status := "finished"
+	switch {
+	case session.IsPaused:
+		status = "paused"
+	case session.EndTime.After(now):
+		status = "active"
+	case session.Status != "":
+		status = session.Status
+	}
+
+	return &sessionDetail{
+		Session:     session,
+		Status:      status,
+		Focus:       session.EffectiveFocus(now),
+		Pauses:      pauses,
+		Metadata:    metadata,
+		Annotations: annotations,
+		Task:        task,
+	}, nil
+}
+
+// print writes the detail view as text
+func (d *sessionDetail) print() {
+	s := d.Session
+	loc := s.Location()
+	kind := "Pomodoro"
+	if s.WasBreak {
+		kind = "Break"
+	}
+
+	fmt.Printf("%s%s %s (ID %d)\n", icon(sessionIcon(s.WasBreak)), kind, s.ShortRef(), s.ID)
+	fmt.Printf("  Description: %s\n", s.Description)
+	fmt.Printf("  Status:      %s\n", d.Status)
+	fmt.Printf("  Started:     %s\n", s.StartTime.In(loc).Format("2006-01-02 15:04:05"))
+	fmt.Printf("  Ends:        %s\n", s.EndTime.In(loc).Format("2006-01-02 15:04:05"))
+	fmt.Printf("  Time zone:   %s\n", s.ZoneLabel())
+	fmt.Printf("  Planned:     %s\n", time.Duration(s.DurationSec)*time.Second)
+	fmt.Printf("  Focus:       %s\n", d.Focus.Round(time.Second))
+	if s.TotalPausedDuration > 0 || s.IsPaused {
+		fmt.Printf("  Paused:      %s\n", (time.Duration(s.TotalPausedDuration) * time.Second).String())
+	}
+	if s.TagsCSV != "" {
+		fmt.Printf("  Tags:        %s\n", strings.ReplaceAll(term.Tags(s.TagsCSV), ",", ", "))
+	}
+	if d.Task != nil {
+		fmt.Printf("  Task:        %s (task %d, %s)\n", d.Task.Title, d.Task.ID, taskProgress(d.Task.Pomodoros, d.Task.Estimate))
+	}
+
+	if len(d.Pauses) > 0 {
+		fmt.Println("\nPauses:")
+		for _, p := range d.Pauses {
+			resumed := "still paused"
+			if p.ResumedAt != nil {
+				resumed = p.ResumedAt.In(loc).Format("15:04:05")
+			}
+			fmt.Printf("  %s → %s (%s)\n",
+				p.PausedAt.In(loc).Format("15:04:05"), resumed, p.Duration(app.now()).Round(time.Second))
+		}
+	}
+
+	if len(d.Metadata) > 0 {
+		fmt.Println("\nMetadata:")
+		keys := make([]string, 0, len(d.Metadata))
+		for key := range d.Metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Printf("  %s: %s\n", key, d.Metadata[key])
+		}
+	}
+
+	if len(d.Annotations) > 0 {
+		fmt.Println("\nAnnotations:")
+		printAnnotations(d.Annotations)
+	}
+}
+
+// toJSON converts the detail view to its JSON representation
+func (d *sessionDetail) toJSON() any {
+	s := d.Session
+	loc := s.Location()
+
+	type jsonPause struct {
+		PausedAt  string `json:"paused_at"`
+		ResumedAt string `json:"resumed_at,omitempty"`
+	}
+	pauses := make([]jsonPause, 0, len(d.Pauses))
+	for _, p := range d.Pauses {
+		jp := jsonPause{PausedAt: p.PausedAt.In(loc).Format(time.RFC3339)}
+		if p.ResumedAt != nil {
+			jp.ResumedAt = p.ResumedAt.In(loc).Format(time.RFC3339)
+		}
+		pauses = append(pauses, jp)
+	}
+
+	tags := jsonTags(s.TagsCSV)
+	metadata := d.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	annotations := d.Annotations
+	if annotations == nil {
+		annotations = []db.Annotation{}
+	}
+	var task *taskJSON
+	if d.Task != nil {
+		t := newTaskJSON(*d.Task)
+		task = &t
+	}
+
+	return struct {
+		ID          int64             `json:"id"`
+		Ref         string            `json:"ref"`
+		Description string            `json:"description"`
+		WasBreak    bool              `json:"was_break"`
+		Status      string            `json:"status"`
+		StartTime   string            `json:"start_time"`
+		EndTime     string            `json:"end_time"`
+		Timezone    string            `json:"timezone"`
+		Planned     string            `json:"planned_duration"`
+		Focus       string            `json:"effective_focus"`
+		PausedTotal string            `json:"paused_duration"`
+		Pauses      []jsonPause       `json:"pauses"`
+		Tags        []string          `json:"tags"`
+		Metadata    map[string]string `json:"metadata"`
+		Annotations []db.Annotation   `json:"annotations"`
+		Task        *taskJSON         `json:"task,omitempty"`
+	}{
+		ID:          s.ID,
+		Ref:         s.ShortRef(),
+		Description: s.Description,
+		WasBreak:    s.WasBreak,
+		Status:      d.Status,
+		StartTime:   s.StartTime.In(loc).Format(time.RFC3339),
+		EndTime:     s.EndTime.In(loc).Format(time.RFC3339),
+		Timezone:    db.FormatOffset(s.TZOffset),
+		Planned:     (time.Duration(s.DurationSec) * time.Second).String(),
+		Focus:       d.Focus.Round(time.Second).String(),
+		PausedTotal: (time.Duration(s.TotalPausedDuration) * time.Second).String(),
+		Pauses:      pauses,
+		Tags:        tags,
+		Metadata:    metadata,
+		Annotations: annotations,
+		Task:        task,
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(showCmd)
+}
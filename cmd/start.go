@@ -3,15 +3,21 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 
+	"github.com/ethan-k/pomodoro-cli/internal/config"
 	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/hooks"
 	"github.com/ethan-k/pomodoro-cli/internal/model"
-	"github.com/ethan-k/pomodoro-cli/internal/notify"
+	"github.com/ethan-k/pomodoro-cli/internal/stats"
+	"github.com/ethan-k/pomodoro-cli/internal/term"
+	"github.com/ethan-k/pomodoro-cli/internal/trace"
 	"github.com/ethan-k/pomodoro-cli/internal/utils"
 )
 
@@ -21,24 +27,67 @@ var (
 	duration         time.Duration
 	noWait           bool
 	ago              time.Duration
-	jsonOutput       bool
 	silentMode       bool
 	continuousMode   bool
 	noContinuousMode bool
+	startEnergy      int
+	startTask        int64
+	startCategory    string
+	startWarmup      time.Duration
+	startExplain     bool
+	startOverride    bool
+	startUI          string
 )
 
 var startCmd = &cobra.Command{
-	Use:   "start [description]",
+	Use:   "start [duration] [description]",
 	Short: "Starts a new Pomodoro session",
 	Long: `Starts a new Pomodoro timer.
 
 You can optionally provide a description for the session.
 Use flags to specify tags, duration, or if the timer should block.
 
+A duration may come before the description, as in 'pomodoro start 50m
+"Write report"', in place of --duration; it needs a unit, so a description
+such as "2024 planning" is left alone. 'pomodoro 50m' on its own is short
+for 'pomodoro start 50m'.
+
+Without a duration, the first tag with a rule in defaults.tag_durations
+sets the length, then defaults.pomodoro_duration, then 25 minutes.
+--explain shows how the duration, tags, and audio were chosen, listing each
+source in order of precedence, without starting anything.
+
+Past the daily limits (limits.daily_max_pomodoros, limits.latest_start_time)
+start warns, or with limits.refuse set refuses unless --override is given.
+
+With --json, --continuous runs the cycle without the terminal: once the
+pomodoro ends it starts the configured break, a long one when due, and
+prints each as it starts, one JSON object per line.
+
+--ui full shows, below the progress bar, today's finished sessions, progress
+toward your goals, and the open tasks queued next, refreshed as the session
+runs.
+
 Example:
-  pomodoro start "Refactor API" -t coding,backend --duration 50m`,
+  pomodoro start "Refactor API" -t coding,backend --duration 50m
+  pomodoro start 50m "Write report"
+  pomodoro start "Outline" --task 3
+  pomodoro start "Design review" --category deep
+  pomodoro start "Write report" --warmup 2m
+  pomodoro start "One more" --override
+  pomodoro start "Deep work" --ui full
+  pomodoro start "Deep work" --continuous --json
+  pomodoro start "Inbox zero" -t email --explain`,
 	Aliases: []string{"s"},
-	Run: func(_ *cobra.Command, args []string) {
+	Args:    startArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 0 {
+			if _, ok := durationArg(args[0]); ok {
+				// Marks the duration as given, as --duration would
+				_ = cmd.Flags().Set("duration", args[0])
+				args = args[1:]
+			}
+		}
 		if len(args) > 0 {
 			description = args[0]
 		}
@@ -50,8 +99,13 @@ Example:
 			os.Exit(1)
 		}
 
-		if err := utils.ValidateDuration(duration); err != nil {
-			fmt.Fprintf(os.Stderr, "Invalid duration: %v\n", err)
+		if !slices.Contains(timerUIs, startUI) {
+			fmt.Fprintf(os.Stderr, "Invalid UI %q: must be one of %s\n", startUI, strings.Join(timerUIs, ", "))
+			os.Exit(1)
+		}
+
+		if startEnergy != 0 && !stats.ValidEnergy(startEnergy) {
+			fmt.Fprintf(os.Stderr, "Invalid energy level: must be between %d and %d\n", stats.MinEnergy, stats.MaxEnergy)
 			os.Exit(1)
 		}
 
@@ -60,10 +114,64 @@ Example:
 			fmt.Fprintf(os.Stderr, "Invalid tags: %v\n", err)
 			os.Exit(1)
 		}
-		startTime := time.Now().Add(-ago)
+
+		// Without a usable config, only the flag and built-in defaults apply
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			cfg = nil
+		}
+		var flagDuration *time.Duration
+		if cmd.Flags().Changed("duration") {
+			flagDuration = &duration
+		}
+		var explained *resolution
+		duration, explained = resolveDuration(flagDuration, tags, cfg)
+		if startExplain {
+			var flagTags []string
+			if cmd.Flags().Changed("tags") {
+				flagTags = tags
+			}
+			explained.print(os.Stdout)
+			fmt.Println()
+			resolveTags(flagTags).print(os.Stdout)
+			fmt.Println()
+			resolveAudio(silentMode, cfg).print(os.Stdout)
+			return
+		}
+		if err := utils.ValidateDuration(duration); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid duration: %v\n", err)
+			os.Exit(1)
+		}
+
+		if startCategory != "" {
+			category, err := parseCategory(startCategory)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid category: %v\n", err)
+				os.Exit(1)
+			}
+			startCategory = category
+		}
+
+		// A warm-up is counted down by the terminal timer, so it is only
+		// taken when the pomodoro is timed there from now
+		timed := !jsonOutput && !noWait && ago == 0
+		if cmd.Flags().Changed("warmup") {
+			if !timed && startWarmup > 0 {
+				fmt.Fprintln(os.Stderr, "--warmup cannot be used with --json, --no-wait, or --ago")
+				os.Exit(1)
+			}
+		} else if cfg != nil && timed {
+			startWarmup = configuredWarmup(cfg)
+		}
+		if startWarmup < 0 {
+			fmt.Fprintln(os.Stderr, "Invalid warm-up: must not be negative")
+			os.Exit(1)
+		}
+
+		startTime := app.now().Add(-ago)
 		endTime := startTime.Add(duration)
 
-		database, err := db.NewDB()
+		database, err := openDB()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "%v\n", err)
 			os.Exit(1)
@@ -74,6 +182,41 @@ Example:
 			}
 		}()
 
+		if startTask != 0 {
+			if err := checkOpenTask(database, startTask); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		if err := checkLimits(database, startOverride); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if !jsonOutput {
+			noteTimezoneChange(database, startTime)
+			warnIfOverCapacity(database)
+		}
+
+		// The pomodoro starts once the warm-up is over, leaving the warm-up
+		// out of its focus time
+		var warmupTaken time.Duration
+		if startWarmup > 0 && timed {
+			taken, ok, err := runWarmup(description, startWarmup)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error running UI: %v\n", err)
+				os.Exit(1)
+			}
+			if !ok {
+				fmt.Println("No pomodoro started.")
+				return
+			}
+			warmupTaken = taken
+			startTime = app.now()
+			endTime = startTime.Add(duration)
+		}
+
 		tagsCSV := strings.Join(tags, ",")
 		id, err := database.CreateSession(
 			startTime,
@@ -88,9 +231,45 @@ Example:
 			os.Exit(1)
 		}
 
+		if startTask != 0 {
+			if err := database.SetSessionTask(id, startTask); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+			}
+		}
+		if startCategory != "" {
+			if err := database.SetSessionMetadata(id, db.MetaCategory, startCategory); err != nil {
+				fmt.Fprintf(os.Stderr, "Error saving category: %v\n", err)
+			}
+		}
+		recordLocation(database, id)
+		if startEnergy != 0 {
+			if err := database.SetSessionMetadata(id, db.MetaEnergyStart, strconv.Itoa(startEnergy)); err != nil {
+				fmt.Fprintf(os.Stderr, "Error saving energy level: %v\n", err)
+			}
+		}
+		if warmupTaken > 0 {
+			if err := database.SetSessionMetadata(id, db.MetaWarmup, strconv.FormatInt(int64(warmupTaken.Seconds()), 10)); err != nil {
+				fmt.Fprintf(os.Stderr, "Error saving warm-up: %v\n", err)
+			}
+		}
+		runHooks(database, hooks.SessionStart, id)
+
+		// Without a terminal timer, a daemon completes the session
+		if jsonOutput || noWait {
+			ensureDaemon()
+		}
+
 		if jsonOutput {
-			fmt.Printf(`{"id":%d,"description":"%s","duration":"%s","end_time":"%s"}`+"\n",
-				id, description, duration, endTime.Format(time.RFC3339))
+			printJSONLine(struct {
+				ID          int64  `json:"id"`
+				Type        string `json:"type"`
+				Description string `json:"description"`
+				Duration    string `json:"duration"`
+				EndTime     string `json:"end_time"`
+			}{id, "pomodoro", description, duration.String(), endTime.Format(time.RFC3339)})
+			if continuousMode {
+				continueScripted(database, id)
+			}
 			return
 		}
 
@@ -100,15 +279,27 @@ Example:
 		}
 
 		p := model.NewPomodoroModel(id, description, startTime, duration, false)
+		if startUI == "full" {
+			p = p.WithDashboard(timerDashboard(database))
+		}
 
-		if _, err := tea.NewProgram(p).Run(); err != nil {
+		finished, err := runTimerUI(database, p)
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error running UI: %v\n", err)
 			os.Exit(1)
 		}
+		if !finished {
+			return
+		}
 
-		if err := notify.NotifyPomodoroCompleteWithOptions(description, silentMode); err != nil {
+		markNotified(database, id)
+		runHooks(database, hooks.SessionComplete, id)
+		if err := app.notifyPomodoroComplete(description, silentMode); err != nil {
 			fmt.Fprintf(os.Stderr, "Error sending notification: %v\n", err)
 		}
+		announceGoalAchievements(id, silentMode)
+		announceLimitReached()
+		askQuality(database, id)
 
 		// Continuous mode: prompt for next action
 		// Enable continuous mode by default when not in JSON mode, not no-wait, and not explicitly disabled
@@ -118,46 +309,90 @@ Example:
 	},
 }
 
+// durationArg parses arg as a duration given before the description. Only a
+// number with a unit counts, so descriptions such as "42" or "2024 planning"
+// are not taken for durations.
+func durationArg(arg string) (time.Duration, bool) {
+	if arg == "" || arg[0] < '0' || arg[0] > '9' ||
+		strings.ContainsFunc(arg, unicode.IsSpace) || !strings.ContainsFunc(arg, unicode.IsLetter) {
+		return 0, false
+	}
+	d, err := utils.ParseHumanDuration(arg)
+	return d, err == nil
+}
+
+// startArgs accepts an optional duration followed by an optional description
+func startArgs(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		if _, ok := durationArg(args[0]); ok {
+			if cmd.Flags().Changed("duration") {
+				return fmt.Errorf("duration given both as %s and with --duration; give one", args[0])
+			}
+			args = args[1:]
+		}
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("expected one description, got %d arguments; quote a description with spaces, e.g. pomodoro start 50m \"Write report\"", len(args))
+	}
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(startCmd)
 
 	startCmd.Flags().StringSliceVarP(&tags, "tags", "t", []string{}, "Comma-separated tags for the session (e.g., coding,backend)")
-	startCmd.Flags().DurationVarP(&duration, "duration", "d", 25*time.Minute, "Duration of the Pomodoro session (e.g., 25m, 1h)")
+	_ = startCmd.RegisterFlagCompletionFunc("tags", completeTags)
+	humanDurationVarP(startCmd.Flags(), &duration, "duration", "d", 0, "Duration of the Pomodoro session (e.g., 25m, 1h30, \"50 min\"); default from tag rules or defaults.pomodoro_duration")
 	startCmd.Flags().BoolVar(&noWait, "no-wait", false, "Run in background without showing progress bar")
-	startCmd.Flags().DurationVar(&ago, "ago", 0, "Start the Pomodoro as if it began some time ago (e.g., 5m)")
-	startCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format (for non-TTY usage)")
+	humanDurationVarP(startCmd.Flags(), &ago, "ago", "", 0, "Start the Pomodoro as if it began some time ago (e.g., 5m)")
 	startCmd.Flags().BoolVar(&silentMode, "silent", false, "Disable audio notifications for this session")
 	startCmd.Flags().BoolVar(&continuousMode, "continuous", false, "Force continuous mode (default: auto-detect based on environment)")
 	startCmd.Flags().BoolVar(&noContinuousMode, "no-continuous", false, "Disable continuous mode and exit after session")
+	startCmd.Flags().Int64Var(&startTask, "task", 0, "Count the session toward this task (see 'pomodoro task list')")
+	_ = startCmd.RegisterFlagCompletionFunc("task", completeTask)
+	startCmd.Flags().StringVarP(&startCategory, "category", "c", "", "Category of work (deep, shallow, admin by default); inferred from tags when omitted")
+	_ = startCmd.RegisterFlagCompletionFunc("category", completeCategory)
+	humanDurationVarP(startCmd.Flags(), &startWarmup, "warmup", "", 0, "Count down a warm-up before the pomodoro starts, kept out of its focus time (default from defaults.warmup; 0 for none)")
+	startCmd.Flags().IntVar(&startEnergy, "energy", 0, "Log your current energy level (1-5) with the session")
+	startCmd.Flags().StringVar(&startUI, "ui", "bar", "Timer view: bar, or full for today's sessions, goals, and queue below the bar")
+	_ = startCmd.RegisterFlagCompletionFunc("ui", cobra.FixedCompletions(timerUIs, cobra.ShellCompDirectiveNoFileComp))
+	startCmd.Flags().BoolVar(&startOverride, "override", false, "Start even when past limits.daily_max_pomodoros or limits.latest_start_time with limits.refuse set")
+	startCmd.Flags().BoolVar(&startExplain, "explain", false, "Show how the duration, tags, and audio were chosen and exit without starting")
 }
 
 // handleContinuousMode prompts user for next action after session completion
 func handleContinuousMode() {
 	// Check if we're in an interactive environment
 	if !isInteractive() {
-		fmt.Println("🍅 Session completed!")
+		decorf("%sSession completed!\n", icon("🍅"))
 		return
 	}
 
 	for {
 		fmt.Println("\n🍅 Session completed! What would you like to do next?")
-		fmt.Println("1. Start a break (b)")
+		if due, count := longBreakDue(); due {
+			fmt.Printf("1. Start a long break, %d pomodoros done (b)\n", count)
+		} else {
+			fmt.Println("1. Start a break (b)")
+		}
 		fmt.Println("2. Start another pomodoro (p)")
 		fmt.Println("3. View status (s)")
 		fmt.Println("4. Quit (q)")
 		fmt.Print("\nChoose an option: ")
 
 		var choice string
-		if _, err := fmt.Scanln(&choice); err != nil {
+		wait := trace.Begin(trace.Wait, "prompt")
+		_, err := fmt.Scanln(&choice)
+		wait()
+		if err != nil {
 			fmt.Println("Error reading input. Goodbye! 👋")
 			return
 		}
 
 		switch strings.ToLower(strings.TrimSpace(choice)) {
 		case "1", "b", "break":
-			fmt.Println("Starting break...")
-			runBreakSession(5*time.Minute, true) // Always wait for breaks in continuous mode
-			continue                             // Continue the loop after break
+			runBreakSession()
+			continue // Continue the loop after break
 		case "2", "p", "pomodoro":
 			fmt.Println("Starting another pomodoro...")
 			runPomodoroSession()
@@ -177,63 +412,69 @@ func handleContinuousMode() {
 
 // isInteractive checks if we're running in an interactive terminal
 func isInteractive() bool {
-	// Simple check - in a real terminal, we can read from stdin
-	stat, err := os.Stdin.Stat()
-	if err != nil {
-		return false
-	}
-	return (stat.Mode() & os.ModeCharDevice) != 0
+	return term.IsTerminal(os.Stdin)
 }
 
-// runBreakSession runs a break session with specified duration
-func runBreakSession(duration time.Duration, wait bool) {
-	startTime := time.Now()
-	endTime := startTime.Add(duration)
-
-	database, err := db.NewDB()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "%v\n", err)
-		return
-	}
-	defer func() {
-		if err := database.Close(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
-		}
-	}()
-
-	id, err := database.CreateSession(startTime, endTime, "Break", int64(duration.Seconds()), "", true)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating break session: %v\n", err)
-		return
-	}
-
-	if !wait {
-		fmt.Printf("Started break for %s\n", duration)
-		return
+// runBreakSession runs a break in continuous mode, using the configured
+// break durations and switching to a long break when one is due
+func runBreakSession() {
+	long, _ := longBreakDue()
+	switch {
+	case jsonOutput:
+	case long:
+		fmt.Println("Starting long break...")
+	default:
+		fmt.Println("Starting break...")
 	}
 
-	p := model.NewPomodoroModel(id, "Break Time", startTime, duration, true)
-	if _, err := tea.NewProgram(p).Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error running UI: %v\n", err)
-		return
+	// Breaks are waited for in the terminal, and left to the daemon when
+	// scripted
+	if err := runBreak(breakOptions{
+		Duration: configuredBreakDuration(long),
+		Long:     long,
+		Wait:     !jsonOutput,
+		JSON:     jsonOutput,
+		Silent:   silentMode || breakSilent,
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
 	}
+}
 
-	if err := notify.NotifyBreakCompleteWithOptions(silentMode); err != nil {
-		fmt.Fprintf(os.Stderr, "Error sending notification: %v\n", err)
-	}
+// scriptedPollInterval is how often a scripted continuous run checks on the
+// pomodoro it waits for, to see it paused, resumed, or stopped
+const scriptedPollInterval = 5 * time.Second
 
-	// Continue the continuous mode loop
-	if continuousMode {
-		handleContinuousMode()
+// continueScripted waits for pomodoro id to end, then starts the break that
+// follows it as runBreakSession does. Nothing follows a pomodoro that was
+// cancelled or abandoned.
+func continueScripted(database db.DB, id int64) {
+	for {
+		session, err := database.GetSessionByID(id)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting session: %v\n", err)
+			return
+		}
+		if session == nil || session.Incomplete() {
+			return
+		}
+		remaining := session.EndTime.Sub(app.now())
+		if session.Status == db.StatusCompleted || (!session.IsPaused && remaining <= 0) {
+			break
+		}
+		if session.IsPaused || remaining > scriptedPollInterval {
+			remaining = scriptedPollInterval
+		}
+		app.sleep(remaining)
 	}
+	runBreakSession()
 }
 
 // runPomodoroSession runs another pomodoro with the same settings
 func runPomodoroSession() {
-	startTime := time.Now().Add(-ago)
+	startTime := app.now().Add(-ago)
 	endTime := startTime.Add(duration)
 
-	database, err := db.NewDB()
+	database, err := openDB()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		return
@@ -244,32 +485,57 @@ func runPomodoroSession() {
 		}
 	}()
 
+	warnIfOverCapacity(database)
+	if err := checkLimits(database, startOverride); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return
+	}
+
 	tagsCSV := strings.Join(tags, ",")
 	id, err := database.CreateSession(startTime, endTime, description, int64(duration.Seconds()), tagsCSV, false)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating session: %v\n", err)
 		return
 	}
+	if startTask != 0 {
+		if err := database.SetSessionTask(id, startTask); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+	}
+	if startCategory != "" {
+		if err := database.SetSessionMetadata(id, db.MetaCategory, startCategory); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving category: %v\n", err)
+		}
+	}
+	recordLocation(database, id)
+	runHooks(database, hooks.SessionStart, id)
 
 	p := model.NewPomodoroModel(id, description, startTime, duration, false)
-	if _, err := tea.NewProgram(p).Run(); err != nil {
+	if startUI == "full" {
+		p = p.WithDashboard(timerDashboard(database))
+	}
+	finished, err := runTimerUI(database, p)
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error running UI: %v\n", err)
 		return
 	}
-
-	if err := notify.NotifyPomodoroCompleteWithOptions(description, silentMode); err != nil {
-		fmt.Fprintf(os.Stderr, "Error sending notification: %v\n", err)
+	if !finished {
+		return
 	}
 
-	// Continue the continuous mode loop
-	if continuousMode {
-		handleContinuousMode()
+	markNotified(database, id)
+	runHooks(database, hooks.SessionComplete, id)
+	if err := app.notifyPomodoroComplete(description, silentMode); err != nil {
+		fmt.Fprintf(os.Stderr, "Error sending notification: %v\n", err)
 	}
+	announceGoalAchievements(id, silentMode)
+	announceLimitReached()
+	askQuality(database, id)
 }
 
 // showQuickStatus shows a quick overview of today's progress
 func showQuickStatus() {
-	database, err := db.NewDB()
+	database, err := openDB()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		return
@@ -300,6 +566,9 @@ func showQuickStatus() {
 	fmt.Printf("🍅 Pomodoros: %d\n", pomodoroCount)
 	fmt.Printf("☕ Breaks: %d\n", breakCount)
 	fmt.Printf("📈 Total sessions: %d\n", len(sessions))
+	if warning := capacityWarning(database, 0); warning != "" {
+		warnf("%s\n", warning)
+	}
 
 	// Add a pause to let user read the status
 	time.Sleep(1 * time.Second)
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func TestDurationArg(t *testing.T) {
+	for _, tc := range []struct {
+		arg  string
+		want time.Duration
+		ok   bool
+	}{
+		{"50m", 50 * time.Minute, true},
+		{"1h30", 90 * time.Minute, true},
+		{"1.5h", 90 * time.Minute, true},
+		{"25", 0, false}, // No unit
+		{"2024 planning", 0, false},
+		{"5 min", 0, false},
+		{"Write report", 0, false},
+		{"3rd draft", 0, false},
+		{"", 0, false},
+	} {
+		if got, ok := durationArg(tc.arg); got != tc.want || ok != tc.ok {
+			t.Errorf("durationArg(%q) = %s, %v, want %s, %v", tc.arg, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestShortcutArgs(t *testing.T) {
+	for _, tc := range []struct {
+		args, want []string
+	}{
+		{[]string{"25m"}, []string{"start", "25m"}},
+		{[]string{"50m", "Write report", "-t", "docs"}, []string{"start", "50m", "Write report", "-t", "docs"}},
+		{[]string{"-q", "--db", "/tmp/p.db", "25m"}, []string{"-q", "--db", "/tmp/p.db", "start", "25m"}},
+		{[]string{"--db=/tmp/p.db", "25m"}, []string{"--db=/tmp/p.db", "start", "25m"}},
+		{[]string{"--db", "25m"}, []string{"--db", "25m"}}, // 25m is the database
+		{[]string{"start", "25m"}, []string{"start", "25m"}},
+		{[]string{"history", "--since", "3d"}, []string{"history", "--since", "3d"}},
+		{[]string{"--", "25m"}, []string{"--", "25m"}},
+		{nil, nil},
+	} {
+		if got := shortcutArgs(tc.args); !slices.Equal(got, tc.want) {
+			t.Errorf("shortcutArgs(%q) = %q, want %q", tc.args, got, tc.want)
+		}
+	}
+}
+
+func TestStartArgs(t *testing.T) {
+	defer resetFlags(startCmd)
+
+	for _, tc := range []struct {
+		args    []string
+		wantErr string
+	}{
+		{[]string{"50m", "Write report"}, ""},
+		{[]string{"Write report"}, ""},
+		{[]string{"50m"}, ""},
+		{nil, ""},
+		{[]string{"Write", "report"}, "quote a description"},
+		{[]string{"50m", "Write", "report"}, "quote a description"},
+	} {
+		err := startArgs(startCmd, tc.args)
+		if (err == nil) != (tc.wantErr == "") || err != nil && !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("startArgs(%q) = %v, want an error containing %q", tc.args, err, tc.wantErr)
+		}
+	}
+
+	if err := startCmd.Flags().Set("duration", "25m"); err != nil {
+		t.Fatal(err)
+	}
+	if err := startArgs(startCmd, []string{"50m", "Write report"}); err == nil || !strings.Contains(err.Error(), "--duration") {
+		t.Errorf("Expected a duration given twice to be refused, got %v", err)
+	}
+	if err := startArgs(startCmd, []string{"Write report"}); err != nil {
+		t.Errorf("Expected --duration with a description alone to be accepted, got %v", err)
+	}
+}
+
+func TestStartDurationArg(t *testing.T) {
+	a := newTestApp(t, &mockDB{}, time.Now())
+	out := a.run(t, "start", "50m", "Write report", "--explain")
+	if !strings.HasPrefix(out, "duration: 50m0s\n") {
+		t.Errorf("Expected the leading 50m to set the duration, got:\n%s", out)
+	}
+}
+
+func TestStartContinuousJSON(t *testing.T) {
+	now := time.Date(2026, time.March, 4, 9, 0, 0, 0, time.Local)
+	for _, tc := range []struct {
+		name   string
+		status string // How the pomodoro ends
+		want   []string
+	}{
+		{"completed", db.StatusCompleted, []string{"pomodoro", "break"}},
+		{"cancelled", db.StatusCancelled, []string{"pomodoro"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var created []db.PomodoroSession
+			var a *testApp
+			a = newTestApp(t, &mockDB{
+				CreateSessionFunc: func(start, end time.Time, description string, _ int64, _ string, wasBreak bool) (int64, error) {
+					created = append(created, db.PomodoroSession{StartTime: start, EndTime: end, Description: description, WasBreak: wasBreak})
+					return int64(len(created)), nil
+				},
+				GetSessionByIDFunc: func(id int64) (*db.PomodoroSession, error) {
+					session := created[id-1]
+					// Paused for its first ten minutes, then runs out
+					session.IsPaused = a.now.Before(now.Add(10 * time.Minute))
+					if !session.IsPaused {
+						session.EndTime = session.EndTime.Add(10 * time.Minute)
+					}
+					if !a.now.Before(session.EndTime) {
+						session.Status = tc.status
+					}
+					return &session, nil
+				},
+			}, now)
+			path := filepath.Join(os.Getenv("HOME"), ".config", "pomodoro", "config.yml")
+			if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte("defaults:\n  break_duration: 10m\ndaemon:\n  auto_start: false\n"), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			var phases []string
+			for _, line := range strings.Split(strings.TrimSpace(a.run(t, "start", "Write report", "--continuous", "--json")), "\n") {
+				var phase struct {
+					Type     string `json:"type"`
+					Duration string `json:"duration"`
+				}
+				if err := json.Unmarshal([]byte(line), &phase); err != nil {
+					t.Fatalf("Expected one JSON object per line, got %q: %v", line, err)
+				}
+				phases = append(phases, phase.Type)
+				if phase.Type == "break" && phase.Duration != "10m0s" {
+					t.Errorf("Expected the configured break, got %s", phase.Duration)
+				}
+			}
+			if !slices.Equal(phases, tc.want) {
+				t.Errorf("Expected %v, got %v", tc.want, phases)
+			}
+			if len(created) == 2 && !created[1].StartTime.Equal(now.Add(35*time.Minute)) {
+				t.Errorf("Expected the break to start once the paused pomodoro ended, at %v", created[1].StartTime)
+			}
+		})
+	}
+}
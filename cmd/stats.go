@@ -0,0 +1,487 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/githook"
+	"github.com/ethan-k/pomodoro-cli/internal/stats"
+	"github.com/ethan-k/pomodoro-cli/internal/term"
+	"github.com/ethan-k/pomodoro-cli/internal/trace"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+var (
+	statsWeek    bool
+	statsMonth   bool
+	statsFrom    string
+	statsTo      string
+	statsCommits bool
+)
+
+// statsTopHours is how many of the most productive hours the text output lists
+const statsTopHours = 3
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Shows aggregate statistics for a period",
+	Long: `Shows total focus time, completion rate, average session length,
+pomodoros per tag, and your most productive hours for a period, along with
+where you worked when location.record is on.
+
+A pomodoro counts as completed when it ran its full length; cancelled ones
+count toward focus time but lower the completion rate. Sessions still
+running or paused are left out. The period defaults to this week.
+
+A break counts as skipped when a completed pomodoro is followed by another
+one sooner than the break the cycle expected (short, or long when due);
+past wellness.break_skip_threshold you get a nudge to take them.
+
+With --commits, run in a git repository where 'pomodoro integrate git' is
+installed, it also counts the commits made during the period's pomodoros.
+
+Example:
+  pomodoro stats
+  pomodoro stats --month
+  pomodoro stats --from 2025-01-01 --to 2025-03-31 --json
+  pomodoro stats --commits`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		startDate, endDate, err := statsRange(app.now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		report, err := loadStats(database, startDate, endDate)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if statsCommits {
+			if err := addCommits(report, database, startDate, endDate); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		// Today's load only matters while the period is still running
+		if !endDate.Before(today()) {
+			report.CapacityWarning = capacityWarning(database, 0)
+		}
+
+		if jsonOutput {
+			printJSON(report)
+			return
+		}
+
+		printStats(report)
+	},
+}
+
+// statsReport is everything the stats command shows, in its JSON form
+type statsReport struct {
+	From            string          `json:"from"`
+	To              string          `json:"to"`
+	Pomodoros       int             `json:"pomodoros"`
+	Completed       int             `json:"completed"`
+	CompletionRate  float64         `json:"completion_rate"`
+	FocusMinutes    float64         `json:"focus_minutes"`
+	AverageMinutes  float64         `json:"average_minutes"`
+	Breaks          int             `json:"breaks"`
+	BreakMinutes    float64         `json:"break_minutes"`
+	BreaksExpected  int             `json:"breaks_expected"` // Completed pomodoros followed by more work
+	BreaksSkipped   int             `json:"breaks_skipped"`
+	BreakSkipRate   float64         `json:"break_skip_rate"`
+	BreakNudge      string          `json:"break_nudge,omitempty"`
+	ActiveDays      int             `json:"active_days"`
+	Tags            []tagStatsJSON  `json:"tags"`
+	Tasks           []taskStatsJSON `json:"tasks"`
+	Categories      []categoryJSON  `json:"categories"`
+	DeepWork        []deepWorkJSON  `json:"deep_work"`
+	Locations       []locationJSON  `json:"locations"`
+	Hours           []hourStatsJSON `json:"hours"`
+	Commits         *commitsJSON    `json:"commits,omitempty"`     // Only with --commits
+	PeakEnergy      string          `json:"peak_energy,omitempty"` // Time of day with the highest average energy
+	CapacityWarning string          `json:"capacity_warning,omitempty"`
+}
+
+type tagStatsJSON struct {
+	Tag          string  `json:"tag"`
+	Pomodoros    int     `json:"pomodoros"`
+	FocusMinutes float64 `json:"focus_minutes"`
+}
+
+type taskStatsJSON struct {
+	TaskID       int64   `json:"task_id"`
+	Title        string  `json:"title"`
+	Estimate     int     `json:"estimate"`
+	Pomodoros    int     `json:"pomodoros"`
+	FocusMinutes float64 `json:"focus_minutes"`
+}
+
+type categoryJSON struct {
+	Category     string  `json:"category"`
+	Deep         bool    `json:"deep"`
+	Pomodoros    int     `json:"pomodoros"`
+	FocusMinutes float64 `json:"focus_minutes"`
+}
+
+type locationJSON struct {
+	Location       string  `json:"location"`
+	Pomodoros      int     `json:"pomodoros"`
+	FocusMinutes   float64 `json:"focus_minutes"`
+	CompletionRate float64 `json:"completion_rate"` // Share not cancelled or abandoned
+}
+
+type commitsJSON struct {
+	Commits     int     `json:"commits"`   // Commits naming one of the period's pomodoros
+	Pomodoros   int     `json:"pomodoros"` // Pomodoros with at least one commit
+	PerPomodoro float64 `json:"per_pomodoro"`
+}
+
+type deepWorkJSON struct {
+	Week                 string  `json:"week"` // Monday the week starts on
+	DeepMinutes          float64 `json:"deep_minutes"`
+	ShallowMinutes       float64 `json:"shallow_minutes"`
+	UncategorizedMinutes float64 `json:"uncategorized_minutes"`
+	DeepRatio            float64 `json:"deep_ratio"` // Share of categorized focus time that was deep work
+}
+
+type hourStatsJSON struct {
+	Hour         int     `json:"hour"`
+	Pomodoros    int     `json:"pomodoros"`
+	FocusMinutes float64 `json:"focus_minutes"`
+}
+
+// statsRange returns the first and last day covered by the stats flags
+func statsRange(now time.Time) (startDate, endDate time.Time, err error) {
+	if statsWeek && statsMonth {
+		return startDate, endDate, fmt.Errorf("--week and --month cannot be used together")
+	}
+	if (statsWeek || statsMonth) && (statsFrom != "" || statsTo != "") {
+		return startDate, endDate, fmt.Errorf("--from and --to cannot be combined with --week or --month")
+	}
+
+	endDate = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch {
+	case statsMonth:
+		startDate = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	case statsFrom != "" || statsTo != "":
+		if statsTo != "" {
+			if endDate, err = utils.ParseDate(statsTo, now); err != nil {
+				return startDate, endDate, fmt.Errorf("error parsing to date: %v", err)
+			}
+		}
+		startDate = endDate.AddDate(0, 0, -30)
+		if statsFrom != "" {
+			if startDate, err = utils.ParseDate(statsFrom, now); err != nil {
+				return startDate, endDate, fmt.Errorf("error parsing from date: %v", err)
+			}
+		}
+		if startDate.After(endDate) {
+			return startDate, endDate, fmt.Errorf("--from must not be after --to")
+		}
+	default:
+		// This week, starting on Monday
+		daysToMonday := (int(now.Weekday()) + 6) % 7
+		startDate = endDate.AddDate(0, 0, -daysToMonday)
+	}
+
+	return startDate, endDate, nil
+}
+
+// today returns the start of the current day
+func today() time.Time {
+	now := app.now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
+
+// loadStats runs the aggregate queries for the range and collects the report
+func loadStats(database db.DB, startDate, endDate time.Time) (*statsReport, error) {
+	totals, err := database.GetSessionStats(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := database.GetTagStats(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := database.GetTaskStats(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	hours, err := database.GetHourlyStats(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &statsReport{
+		From:           startDate.Format("2006-01-02"),
+		To:             endDate.Format("2006-01-02"),
+		Pomodoros:      totals.Pomodoros,
+		Completed:      totals.Completed,
+		CompletionRate: totals.CompletionRate(),
+		FocusMinutes:   float64(totals.FocusSec) / 60,
+		AverageMinutes: totals.AverageFocus().Minutes(),
+		Breaks:         totals.Breaks,
+		BreakMinutes:   float64(totals.BreakSec) / 60,
+		ActiveDays:     totals.Days,
+		Tags:           []tagStatsJSON{},
+		Tasks:          []taskStatsJSON{},
+		Categories:     []categoryJSON{},
+		DeepWork:       []deepWorkJSON{},
+		Locations:      []locationJSON{},
+		Hours:          []hourStatsJSON{},
+	}
+	for _, t := range tags {
+		report.Tags = append(report.Tags, tagStatsJSON{Tag: t.Tag, Pomodoros: t.Pomodoros, FocusMinutes: float64(t.FocusSec) / 60})
+	}
+	for _, t := range tasks {
+		report.Tasks = append(report.Tasks, taskStatsJSON{
+			TaskID: t.TaskID, Title: t.Title, Estimate: t.Estimate, Pomodoros: t.Pomodoros, FocusMinutes: float64(t.FocusSec) / 60,
+		})
+	}
+	for _, h := range hours {
+		report.Hours = append(report.Hours, hourStatsJSON{Hour: h.Hour, Pomodoros: h.Pomodoros, FocusMinutes: float64(h.FocusSec) / 60})
+	}
+
+	if err := addCategories(report, database, startDate, endDate); err != nil {
+		return nil, err
+	}
+	if err := addBreakSkips(report, database, startDate, endDate); err != nil {
+		return nil, err
+	}
+	if err := addLocations(report, database, startDate, endDate); err != nil {
+		return nil, err
+	}
+
+	// Energy is optional; the rest of the report stands without it
+	if buckets, err := energyReport(database, startDate, endDate); err == nil {
+		if peak := stats.PeakEnergy(buckets); peak != nil {
+			report.PeakEnergy = peak.Label
+		}
+	}
+
+	return report, nil
+}
+
+// addBreakSkips adds how many of the breaks expected after pomodoros were
+// skipped, with a nudge when that is too many
+func addBreakSkips(report *statsReport, database db.DB, startDate, endDate time.Time) error {
+	sessions, err := database.GetSessionsByDateRange(startDate, endDate)
+	if err != nil {
+		return fmt.Errorf("error getting sessions: %v", err)
+	}
+	adherence, err := breakAdherence(database, sessions)
+	if err != nil {
+		return err
+	}
+	report.BreaksExpected = adherence.Expected
+	report.BreaksSkipped = adherence.Skipped
+	report.BreakSkipRate = adherence.SkipRate()
+	report.BreakNudge = breakSkipNudge(adherence)
+	return nil
+}
+
+// addCategories adds the category breakdown and the weekly deep work split
+// to the report
+func addCategories(report *statsReport, database db.DB, startDate, endDate time.Time) error {
+	sessions, err := database.GetSessionsByDateRange(startDate, endDate)
+	if err != nil {
+		return fmt.Errorf("error getting sessions: %v", err)
+	}
+	given, err := database.GetMetadataByDateRange(db.MetaCategory, startDate, endDate)
+	if err != nil {
+		return err
+	}
+
+	categories, weeks := stats.ByCategory(sessions, given, taxonomy(), app.now())
+	for _, c := range categories {
+		report.Categories = append(report.Categories, categoryJSON{
+			Category: c.Category, Deep: c.Deep, Pomodoros: c.Pomodoros, FocusMinutes: c.Focus.Minutes(),
+		})
+	}
+	for _, w := range weeks {
+		report.DeepWork = append(report.DeepWork, deepWorkJSON{
+			Week:                 w.Start.Format("2006-01-02"),
+			DeepMinutes:          w.Deep.Minutes(),
+			ShallowMinutes:       w.Shallow.Minutes(),
+			UncategorizedMinutes: w.Uncategorized.Minutes(),
+			DeepRatio:            w.Ratio(),
+		})
+	}
+	return nil
+}
+
+// addLocations adds the focus time spent at each location recorded with
+// location.record to the report
+func addLocations(report *statsReport, database db.DB, startDate, endDate time.Time) error {
+	locations, err := database.GetMetadataByDateRange(db.MetaLocation, startDate, endDate)
+	if err != nil || len(locations) == 0 {
+		return err
+	}
+	sessions, err := database.GetSessionsByDateRange(startDate, endDate)
+	if err != nil {
+		return fmt.Errorf("error getting sessions: %v", err)
+	}
+	for _, l := range stats.ByLocation(sessions, locations, app.now()) {
+		report.Locations = append(report.Locations, locationJSON{
+			Location: l.Location, Pomodoros: l.Pomodoros, FocusMinutes: l.Focus.Minutes(), CompletionRate: l.CompletionRate(),
+		})
+	}
+	return nil
+}
+
+// addCommits adds the commits made in the current git repository during the
+// period's pomodoros, as named by the trailer 'pomodoro integrate git' adds
+func addCommits(report *statsReport, database db.DB, startDate, endDate time.Time) error {
+	counts, err := githook.CommitCounts("", startDate, endDate.AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+	sessions, err := database.GetSessionsByDateRange(startDate, endDate)
+	if err != nil {
+		return fmt.Errorf("error getting sessions: %v", err)
+	}
+
+	// Like the rest of the report, pomodoros still running are left out
+	now := app.now()
+	report.Commits = &commitsJSON{}
+	for _, s := range sessions {
+		if s.WasBreak || s.IsPaused || s.EndTime.After(now) {
+			continue
+		}
+		if n := counts[s.ShortRef()]; n > 0 {
+			report.Commits.Commits += n
+			report.Commits.Pomodoros++
+		}
+	}
+	if report.Pomodoros > 0 {
+		report.Commits.PerPomodoro = float64(report.Commits.Commits) / float64(report.Pomodoros)
+	}
+	return nil
+}
+
+// printStats prints the report as text
+func printStats(r *statsReport) {
+	defer trace.Begin(trace.Render, "stats")()
+	minutes := func(m float64) string {
+		return utils.FormatDurationLong(time.Duration(m * float64(time.Minute)).Round(time.Second))
+	}
+
+	fmt.Printf("Stats from %s to %s:\n", r.From, r.To)
+	fmt.Println("------------------------------")
+	if r.Pomodoros == 0 {
+		fmt.Println("No pomodoros in this period.")
+		return
+	}
+
+	fmt.Printf("Pomodoros:        %d on %d day(s)\n", r.Pomodoros, r.ActiveDays)
+	fmt.Printf("Focus time:       %s\n", minutes(r.FocusMinutes))
+	fmt.Printf("Completion rate:  %.0f%% (%d of %d ran their full length)\n", r.CompletionRate*100, r.Completed, r.Pomodoros)
+	fmt.Printf("Average length:   %s\n", minutes(r.AverageMinutes))
+	fmt.Printf("Breaks:           %d (%s)\n", r.Breaks, minutes(r.BreakMinutes))
+	if r.BreaksExpected > 0 {
+		fmt.Printf("Breaks skipped:   %d of %d (%.0f%%)\n", r.BreaksSkipped, r.BreaksExpected, r.BreakSkipRate*100)
+	}
+	if r.Commits != nil {
+		fmt.Printf("Commits:          %d in %d pomodoro(s) (%.1f per pomodoro)\n", r.Commits.Commits, r.Commits.Pomodoros, r.Commits.PerPomodoro)
+	}
+
+	if len(r.Tags) > 0 {
+		width := 0
+		for _, t := range r.Tags {
+			width = max(width, len(t.Tag))
+		}
+		fmt.Println("\nBy tag:")
+		for _, t := range r.Tags {
+			// Pad by the plain tag, since the swatch adds escape codes
+			fmt.Printf("  %s%s %4d  %s\n", term.TagSwatch(t.Tag), strings.Repeat(" ", width-len(t.Tag)), t.Pomodoros, minutes(t.FocusMinutes))
+		}
+	}
+
+	if len(r.Tasks) > 0 {
+		fmt.Println("\nBy task:")
+		for _, t := range r.Tasks {
+			fmt.Printf("  %3d  %-30s %4d  %s\n", t.TaskID, t.Title, t.Pomodoros, minutes(t.FocusMinutes))
+		}
+	}
+
+	if len(r.Categories) > 0 {
+		fmt.Println("\nBy category:")
+		for _, c := range r.Categories {
+			kind := "shallow"
+			if c.Deep {
+				kind = "deep work"
+			}
+			fmt.Printf("  %-12s %4d  %-10s %s\n", c.Category, c.Pomodoros, minutes(c.FocusMinutes), kind)
+		}
+
+		fmt.Println("\nDeep work:")
+		for _, w := range r.DeepWork {
+			if w.DeepMinutes+w.ShallowMinutes == 0 {
+				continue
+			}
+			fmt.Printf("  Week of %s  %3.0f%% deep (%s deep, %s shallow)\n",
+				w.Week, w.DeepRatio*100, minutes(w.DeepMinutes), minutes(w.ShallowMinutes))
+		}
+	}
+
+	if len(r.Locations) > 0 {
+		fmt.Println("\nBy location:")
+		for _, l := range r.Locations {
+			fmt.Printf("  %-12s %4d  %-10s %3.0f%% completed\n", l.Location, l.Pomodoros, minutes(l.FocusMinutes), l.CompletionRate*100)
+		}
+	}
+
+	hours := append([]hourStatsJSON(nil), r.Hours...)
+	sort.SliceStable(hours, func(i, j int) bool { return hours[i].FocusMinutes > hours[j].FocusMinutes })
+	if len(hours) > statsTopHours {
+		hours = hours[:statsTopHours]
+	}
+	fmt.Println("\nMost productive hours:")
+	for _, h := range hours {
+		fmt.Printf("  %02d:00-%02d:00 %4d  %s\n", h.Hour, (h.Hour+1)%24, h.Pomodoros, minutes(h.FocusMinutes))
+	}
+
+	if r.PeakEnergy != "" {
+		decorf("\nYour energy peaks in the %s.\n", r.PeakEnergy)
+	}
+	if r.BreakNudge != "" {
+		decorf("\n%s\n", r.BreakNudge)
+	}
+	if r.CapacityWarning != "" {
+		fmt.Println()
+		warnf("%s\n", r.CapacityWarning)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().BoolVar(&statsWeek, "week", false, "Show this week (the default)")
+	statsCmd.Flags().BoolVar(&statsMonth, "month", false, "Show this month")
+	statsCmd.Flags().StringVar(&statsFrom, "from", "", "Start date (YYYY-MM-DD, yesterday, monday, 7d, ...)")
+	statsCmd.Flags().StringVar(&statsTo, "to", "", "End date, inclusive (YYYY-MM-DD, yesterday, monday, 7d, ...)")
+	statsCmd.Flags().BoolVar(&statsCommits, "commits", false, "Count the commits made during pomodoros in this git repository")
+}
@@ -2,15 +2,18 @@ package cmd
 
 import (
 	"fmt"
+	"math"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 
+	"github.com/ethan-k/pomodoro-cli/internal/daemon"
 	"github.com/ethan-k/pomodoro-cli/internal/db"
 	"github.com/ethan-k/pomodoro-cli/internal/model"
+	"github.com/ethan-k/pomodoro-cli/internal/trace"
 	"github.com/ethan-k/pomodoro-cli/internal/utils"
 )
 
@@ -31,35 +34,62 @@ You can use the --format flag to customize the output using placeholders:
   %p  - Progress percentage
   %t  - Tags
   %e  - End time
+  %n  - Pomodoros completed today
+  %%  - A literal %
 
 Example:
   pomodoro status --format "%r remaining for %d"
+  pomodoro status --format "%r (%n today)"
   pomodoro status --wait (to show a live progress bar)`,
 	Run: func(_ *cobra.Command, _ []string) {
-		// Connect to database
-		database, err := db.NewDB()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "%v\n", err)
-			os.Exit(1)
+		// A running daemon answers from memory, so the database is only
+		// opened when needed and status bars polling every second do not
+		// query it each time
+		var database db.DB
+		openStatusDB := func() db.DB {
+			if database == nil {
+				var err error
+				if database, err = openDB(); err != nil {
+					fmt.Fprintf(os.Stderr, "%v\n", err)
+					os.Exit(1)
+				}
+			}
+			return database
 		}
 		defer func() {
+			if database == nil {
+				return
+			}
 			if err := database.Close(); err != nil {
 				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
 			}
 		}()
 
-		// Get active session
-		session, err := database.GetActiveSession()
+		// Get active session, from the daemon when one is running
+		var session *db.PomodoroSession
+		resp, ok, err := daemonCall(daemon.ActionStatus)
+		if !ok {
+			session, err = openStatusDB().GetActiveSession()
+		} else if err == nil {
+			session = resp.Session
+		}
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error getting active session: %v\n", err)
 			os.Exit(1)
 		}
+		render := trace.Begin(trace.Render, "status")
+		defer render()
 
 		if session == nil {
 			if jsonOutput {
-				fmt.Println(`{"active":false}`)
+				printJSONLine(struct {
+					Active bool `json:"active"`
+				}{false})
 			} else {
 				fmt.Println("No active Pomodoro session.")
+				render()
+				last, err := openStatusDB().GetLastSession()
+				suggestTutorial(err != nil || last != nil)
 			}
 			return
 		}
@@ -67,27 +97,36 @@ Example:
 		// Handle paused sessions
 		if session.IsPaused {
 			if jsonOutput {
-				pausedDuration := time.Since(*session.PausedAt).Round(time.Second)
-				fmt.Printf(`{"active":true,"status":"paused","id":%d,"description":"%s","paused_at":"%s","paused_for":"%s","is_break":%t}`+"\n",
+				printJSONLine(struct {
+					Active      bool     `json:"active"`
+					Status      string   `json:"status"`
+					ID          int64    `json:"id"`
+					Description string   `json:"description"`
+					PausedAt    string   `json:"paused_at"`
+					PausedFor   string   `json:"paused_for"`
+					Tags        []string `json:"tags"`
+					WasBreak    bool     `json:"was_break"`
+				}{
+					true,
+					"paused",
 					session.ID,
 					session.Description,
 					session.PausedAt.Format(time.RFC3339),
-					pausedDuration,
-					session.WasBreak)
+					app.now().Sub(*session.PausedAt).Round(time.Second).String(),
+					jsonTags(session.TagsCSV),
+					session.WasBreak,
+				})
 			} else {
 				pausedDuration := time.Since(*session.PausedAt).Round(time.Second)
-				emoji := "🍅"
-				if session.WasBreak {
-					emoji = "☕"
-				}
-				fmt.Printf("⏸️  %s %s (paused for %s)\n", emoji, session.Description, pausedDuration)
-				fmt.Println("Use 'pomodoro resume' to continue.")
+				fmt.Printf("%s%s%s (paused for %s)\n", icon("⏸️ "), icon(sessionIcon(session.WasBreak)), session.Description, pausedDuration)
+				decorf("Use 'pomodoro resume' to continue.\n")
 			}
 			return
 		}
 
 		// If waiting, show progress bar
 		if statusWait {
+			render()
 			duration := session.EndTime.Sub(session.StartTime)
 			elapsed := time.Since(session.StartTime)
 			remaining := duration - elapsed
@@ -105,7 +144,7 @@ Example:
 				session.WasBreak,
 			)
 
-			if _, err := tea.NewProgram(p).Run(); err != nil {
+			if _, err := runTimerUI(openStatusDB(), p); err != nil {
 				fmt.Fprintf(os.Stderr, "Error running UI: %v\n", err)
 				os.Exit(1)
 			}
@@ -114,44 +153,74 @@ Example:
 
 		// JSON output
 		if jsonOutput {
-			now := time.Now()
+			now := app.now()
 			remaining := session.EndTime.Sub(now).Round(time.Second)
 			totalDuration := session.EndTime.Sub(session.StartTime)
-			progress := float64(time.Since(session.StartTime)) / float64(totalDuration) * 100
-
-			fmt.Printf(`{"active":true,"id":%d,"description":"%s","remaining":"%s","progress":%.1f,"end_time":"%s","tags_csv":"%s","is_break":%t}`+"\n",
+			progress := float64(now.Sub(session.StartTime)) / float64(totalDuration) * 100
+
+			printJSONLine(struct {
+				Active      bool     `json:"active"`
+				ID          int64    `json:"id"`
+				Description string   `json:"description"`
+				Remaining   string   `json:"remaining"`
+				Progress    float64  `json:"progress"`
+				EndTime     string   `json:"end_time"`
+				Tags        []string `json:"tags"`
+				WasBreak    bool     `json:"was_break"`
+			}{
+				true,
 				session.ID,
 				session.Description,
-				remaining,
-				progress,
+				remaining.String(),
+				math.Round(progress*10) / 10,
 				session.EndTime.Format(time.RFC3339),
-				session.TagsCSV,
-				session.WasBreak)
+				jsonTags(session.TagsCSV),
+				session.WasBreak,
+			})
 			return
 		}
 
 		// Format output
-		now := time.Now()
+		now := app.now()
 		remaining := session.EndTime.Sub(now).Round(time.Second)
 		totalDuration := session.EndTime.Sub(session.StartTime)
 		progress := float64(time.Since(session.StartTime)) / float64(totalDuration) * 100
 
-		output := statusFormat
-		output = strings.ReplaceAll(output, "%d", session.Description)
-		output = strings.ReplaceAll(output, "%r", utils.FormatDuration(remaining))
-		output = strings.ReplaceAll(output, "%p", fmt.Sprintf("%.1f%%", progress))
-		output = strings.ReplaceAll(output, "%t", session.TagsCSV)
-		output = strings.ReplaceAll(output, "%e", session.EndTime.Format("15:04:05"))
-
-		fmt.Println(output)
+		fields := map[byte]string{
+			'd': session.Description,
+			'r': utils.FormatDuration(remaining),
+			'p': fmt.Sprintf("%.1f%%", progress),
+			't': session.TagsCSV,
+			'e': session.EndTime.Format("15:04:05"),
+		}
+		if strings.Contains(statusFormat, "%n") {
+			sessions, err := todaySessions(openStatusDB)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error getting today's sessions: %v\n", err)
+				os.Exit(1)
+			}
+			fields['n'] = strconv.Itoa(completedPomodoros(sessions, now))
+		}
+		fmt.Println(utils.ExpandFormat(statusFormat, fields))
 	},
 }
 
+// completedPomodoros counts the pomodoros among sessions that ran to their
+// end by now, leaving out the one running
+func completedPomodoros(sessions []db.PomodoroSession, now time.Time) int {
+	completed := 0
+	for _, s := range sessions {
+		if !s.WasBreak && !s.Incomplete() && !s.EndTime.After(now) {
+			completed++
+		}
+	}
+	return completed
+}
+
 func init() {
 	rootCmd.AddCommand(statusCmd)
 
 	// Define flags for the status command
 	statusCmd.Flags().StringVarP(&statusFormat, "format", "f", "%r remaining for %d", "Format string for status output")
 	statusCmd.Flags().BoolVarP(&statusWait, "wait", "w", false, "Wait and show live progress")
-	statusCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format (for non-TTY usage)")
 }
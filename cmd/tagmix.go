@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/stats"
+	"github.com/ethan-k/pomodoro-cli/internal/term"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+var (
+	tagMixMonths int
+	tagMixPairs  int
+)
+
+// Layout of the tag mix chart
+const (
+	tagMixWidth    = 50 // Characters in each month's bar
+	tagMixTopTags  = 6  // Tags shown in the bars; the rest are "other"
+	tagMixOther    = "other"
+	tagMixNotables = 3 // Shifts described below the chart
+)
+
+// statsTagsCmd shows which tags go together and how the tag mix changes
+var statsTagsCmd = &cobra.Command{
+	Use:   "tags",
+	Short: "Shows which tags go together and how the tag mix shifts by month",
+	Long: `Shows the pairs of tags most often used on the same pomodoros, and each
+month's focus time split by tag as a stacked bar, so you can see, say,
+maintenance work crowding out project work.
+
+A pomodoro with several tags counts its focus time evenly toward each. The
+bars show the most used tags of the period; the rest are grouped as "other".
+
+Example:
+  pomodoro stats tags
+  pomodoro stats tags --months 12 --pairs 5
+  pomodoro stats tags --json`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if tagMixMonths < 1 {
+			fmt.Fprintln(os.Stderr, "--months must be at least 1")
+			os.Exit(1)
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		now := app.now()
+		startDate := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local).AddDate(0, 1-tagMixMonths, 0)
+		sessions, err := database.GetSessionsByDateRange(startDate, now)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting sessions: %v\n", err)
+			os.Exit(1)
+		}
+
+		pairs := stats.TagPairs(sessions)
+		if len(pairs) > tagMixPairs {
+			pairs = pairs[:tagMixPairs]
+		}
+		months := stats.TagMixByMonth(sessions, now)
+
+		if jsonOutput {
+			printJSON(newTagMixJSON(pairs, months))
+			return
+		}
+
+		printTagMix(pairs, months)
+	},
+}
+
+// tagMixJSON is the JSON form of the tags report
+type tagMixJSON struct {
+	Pairs  []tagPairJSON  `json:"pairs"`
+	Months []tagMonthJSON `json:"months"`
+	Shifts []tagShiftJSON `json:"shifts"`
+}
+
+type tagPairJSON struct {
+	Tags      [2]string `json:"tags"`
+	Pomodoros int       `json:"pomodoros"`
+	Share     float64   `json:"share"` // Of the pomodoros with either tag, the share with both
+}
+
+type tagMonthJSON struct {
+	Month        string             `json:"month"`
+	FocusMinutes float64            `json:"focus_minutes"`
+	Shares       map[string]float64 `json:"shares"`
+}
+
+type tagShiftJSON struct {
+	Tag  string  `json:"tag"`
+	From float64 `json:"from"`
+	To   float64 `json:"to"`
+}
+
+// newTagMixJSON converts the tags report to its JSON form
+func newTagMixJSON(pairs []stats.TagPair, months []stats.TagMonth) tagMixJSON {
+	out := tagMixJSON{Pairs: []tagPairJSON{}, Months: []tagMonthJSON{}, Shifts: []tagShiftJSON{}}
+	for _, p := range pairs {
+		out.Pairs = append(out.Pairs, tagPairJSON{Tags: p.Tags, Pomodoros: p.Pomodoros, Share: p.Share})
+	}
+	for _, m := range months {
+		shares := make(map[string]float64, len(m.Focus))
+		for tag := range m.Focus {
+			shares[tag] = m.Share(tag)
+		}
+		out.Months = append(out.Months, tagMonthJSON{Month: m.Start.Format("2006-01"), FocusMinutes: m.Total.Minutes(), Shares: shares})
+	}
+	for _, s := range stats.TagDrift(months) {
+		out.Shifts = append(out.Shifts, tagShiftJSON{Tag: s.Tag, From: s.From, To: s.To})
+	}
+	return out
+}
+
+// printTagMix prints the tag pairs, a stacked bar of each month's tag mix,
+// and the biggest shifts between the first and last month
+func printTagMix(pairs []stats.TagPair, months []stats.TagMonth) {
+	fmt.Println("Tags used together:")
+	if len(pairs) == 0 {
+		fmt.Println("  No pomodoros with more than one tag.")
+	}
+	for _, p := range pairs {
+		fmt.Printf("  %-30s %4d  %3.0f%% of either\n", p.Tags[0]+" + "+p.Tags[1], p.Pomodoros, p.Share*100)
+	}
+
+	fmt.Println("\nTag mix by month:")
+	if len(months) == 0 {
+		fmt.Println("  No pomodoros in this period.")
+		return
+	}
+	shown := topTags(months)
+	fills := tagFills(shown)
+	for _, m := range months {
+		fmt.Printf("  %s  %s  %s\n", m.Start.Format("Jan 2006"), tagMixBar(m, shown, fills), utils.FormatDurationLong(m.Total.Round(time.Minute)))
+	}
+	fmt.Print("  ")
+	for _, tag := range slices.Concat(shown, []string{tagMixOther}) {
+		fmt.Printf(" %s %s", fills[tag], tag)
+	}
+	fmt.Println()
+
+	shifts := stats.TagDrift(months)
+	if len(shifts) > tagMixNotables {
+		shifts = shifts[:tagMixNotables]
+	}
+	if len(shifts) > 0 {
+		fmt.Printf("\nSince %s:\n", months[0].Start.Format("January 2006"))
+		for _, s := range shifts {
+			change := "grew"
+			if s.To < s.From {
+				change = "shrank"
+			}
+			fmt.Printf("  %-20s %s from %.0f%% to %.0f%% of focus time\n", s.Tag, change, s.From*100, s.To*100)
+		}
+	}
+}
+
+// topTags returns the tags with the most focus time across months, most
+// first, up to tagMixTopTags
+func topTags(months []stats.TagMonth) []string {
+	totals := make(map[string]time.Duration)
+	for _, m := range months {
+		for tag, focus := range m.Focus {
+			totals[tag] += focus
+		}
+	}
+	tags := make([]string, 0, len(totals))
+	for tag := range totals {
+		tags = append(tags, tag)
+	}
+	slices.SortFunc(tags, func(a, b string) int {
+		return cmp.Or(cmp.Compare(totals[b], totals[a]), strings.Compare(a, b))
+	})
+	if len(tags) > tagMixTopTags {
+		tags = tags[:tagMixTopTags]
+	}
+	return tags
+}
+
+// tagFills returns the block each tag is drawn with: the tag's color when
+// color is on, and otherwise a letter of its own
+func tagFills(tags []string) map[string]string {
+	fills := make(map[string]string, len(tags)+1)
+	for i, tag := range tags {
+		if term.ColorEnabled() {
+			fills[tag] = lipgloss.NewStyle().Foreground(term.TagColor(tag)).Render("█")
+		} else {
+			fills[tag] = string(rune('a' + i))
+		}
+	}
+	fills[tagMixOther] = "░"
+	if !term.ColorEnabled() {
+		fills[tagMixOther] = "."
+	}
+	return fills
+}
+
+// tagMixBar draws a month's tag mix as a bar of tagMixWidth characters, the
+// shown tags in order and the rest as other. Each tag's segment ends where
+// the running share does, so rounding never changes the bar's width.
+func tagMixBar(m stats.TagMonth, shown []string, fills map[string]string) string {
+	var b strings.Builder
+	cumulative, drawn := 0.0, 0
+	for _, tag := range slices.Concat(shown, []string{tagMixOther}) {
+		if tag == tagMixOther {
+			cumulative = 1
+		} else {
+			cumulative += m.Share(tag)
+		}
+		end := int(math.Round(cumulative * tagMixWidth))
+		b.WriteString(strings.Repeat(fills[tag], max(end-drawn, 0)))
+		drawn = max(drawn, end)
+	}
+	return b.String()
+}
+
+func init() {
+	statsCmd.AddCommand(statsTagsCmd)
+
+	statsTagsCmd.Flags().IntVar(&tagMixMonths, "months", 6, "Number of months to cover, this one included")
+	statsTagsCmd.Flags().IntVar(&tagMixPairs, "pairs", 10, "Number of tag pairs to list")
+}
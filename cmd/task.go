@@ -0,0 +1,318 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/plan"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+var (
+	taskEstimate int
+	taskListAll  bool
+)
+
+// maxTaskEstimate bounds --estimate
+const maxTaskEstimate = 1000
+
+// taskCmd groups the task commands
+var taskCmd = &cobra.Command{
+	Use:   "task",
+	Short: "Manage tasks that pomodoros count toward",
+	Long: `Tasks are pieces of work you spend pomodoros on. Link a pomodoro to a
+task with 'pomodoro start --task <id>', then compare the pomodoros a task
+took with your estimate.
+
+Example:
+  pomodoro task add "Write design doc" --estimate 4
+  pomodoro start "Outline" --task 1
+  pomodoro task list
+  pomodoro task show 1
+  pomodoro task done 1`,
+}
+
+// taskAddCmd creates a task
+var taskAddCmd = &cobra.Command{
+	Use:   "add <title>",
+	Short: "Adds a task",
+	Args:  cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		title := utils.SanitizeDescription(args[0])
+		if err := utils.ValidateDescription(title, true); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid title: %v\n", err)
+			os.Exit(1)
+		}
+		if taskEstimate < 0 || taskEstimate > maxTaskEstimate {
+			fmt.Fprintf(os.Stderr, "Invalid estimate: must be between 0 and %d pomodoros\n", maxTaskEstimate)
+			os.Exit(1)
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		id, err := database.AddTask(title, taskEstimate)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			printJSONLine(struct {
+				ID       int64  `json:"id"`
+				Title    string `json:"title"`
+				Estimate int    `json:"estimate"`
+			}{id, title, taskEstimate})
+			return
+		}
+		fmt.Printf("Added task %d: %s\n", id, title)
+	},
+}
+
+// taskListCmd lists tasks with their progress
+var taskListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists open tasks with pomodoros spent against the estimate",
+	Args:  cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		tasks, err := database.ListTasks(taskListAll)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			out := make([]taskJSON, 0, len(tasks))
+			for _, t := range tasks {
+				out = append(out, newTaskJSON(t))
+			}
+			printJSON(out)
+			return
+		}
+
+		if len(tasks) == 0 {
+			fmt.Println("No tasks. Add one with 'pomodoro task add \"Title\" --estimate 4'.")
+			return
+		}
+		for _, t := range tasks {
+			check := " "
+			if t.DoneAt != nil {
+				check = "x"
+			}
+			fmt.Printf("[%s] %3d  %-40s %s\n", check, t.ID, t.Title, taskProgress(t.Pomodoros, t.Estimate))
+		}
+	},
+}
+
+// taskDoneCmd marks a task done
+var taskDoneCmd = &cobra.Command{
+	Use:               "done <id>",
+	Short:             "Marks a task done",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeTask,
+	Run: func(_ *cobra.Command, args []string) {
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		task, err := resolveTask(database, args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		if err := database.CompleteTask(task.ID, app.now()); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		if task.Source != "" {
+			if _, err := plan.CheckOff(task.Source, task.Title); err != nil {
+				fmt.Fprintf(os.Stderr, "Error checking off %s: %v\n", task.Source, err)
+			}
+		}
+
+		if jsonOutput {
+			printJSONLine(struct {
+				ID        int64  `json:"id"`
+				Title     string `json:"title"`
+				Status    string `json:"status"`
+				Pomodoros int    `json:"pomodoros"`
+				Estimate  int    `json:"estimate"`
+			}{task.ID, task.Title, "done", task.Pomodoros, task.Estimate})
+			return
+		}
+		fmt.Printf("%sDone: %s, %s\n", icon("✅"), task.Title, taskProgress(task.Pomodoros, task.Estimate))
+	},
+}
+
+// taskShowCmd shows a task and its sessions
+var taskShowCmd = &cobra.Command{
+	Use:               "show <id>",
+	Short:             "Shows a task and the sessions linked to it",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeTask,
+	Run: func(_ *cobra.Command, args []string) {
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		task, err := resolveTask(database, args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		sessions, err := database.GetTaskSessions(task.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			out := struct {
+				taskJSON
+				Sessions []jsonSession `json:"sessions"`
+			}{taskJSON: newTaskJSON(*task), Sessions: []jsonSession{}}
+			for _, s := range sessions {
+				out.Sessions = append(out.Sessions, newJSONSession(s, nil))
+			}
+			printJSON(out)
+			return
+		}
+
+		status := "open"
+		if task.DoneAt != nil {
+			status = "done " + task.DoneAt.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Printf("Task %d: %s\n", task.ID, task.Title)
+		fmt.Printf("  Status:    %s\n", status)
+		fmt.Printf("  Added:     %s\n", task.CreatedAt.Local().Format("2006-01-02 15:04"))
+		fmt.Printf("  Pomodoros: %s\n", taskProgress(task.Pomodoros, task.Estimate))
+		fmt.Printf("  Focus:     %s\n", time.Duration(task.FocusSec)*time.Second)
+
+		if len(sessions) > 0 {
+			fmt.Println("\nSessions:")
+			for _, s := range sessions {
+				fmt.Printf("  %s %s %s (%s)\n", s.ShortRef(), inZone(s.StartTime, s, nil).Format("2006-01-02 15:04"),
+					s.Description, s.EndTime.Sub(s.StartTime).Round(time.Second))
+			}
+		}
+	},
+}
+
+// taskJSON is the JSON representation of a task
+type taskJSON struct {
+	ID        int64  `json:"id"`
+	Title     string `json:"title"`
+	Estimate  int    `json:"estimate"`
+	Pomodoros int    `json:"pomodoros"`
+	FocusSec  int64  `json:"focus_seconds"`
+	CreatedAt string `json:"created_at"`
+	DoneAt    string `json:"done_at,omitempty"`
+	Source    string `json:"source,omitempty"`
+}
+
+func newTaskJSON(t db.Task) taskJSON {
+	out := taskJSON{
+		ID:        t.ID,
+		Title:     t.Title,
+		Estimate:  t.Estimate,
+		Pomodoros: t.Pomodoros,
+		FocusSec:  t.FocusSec,
+		CreatedAt: t.CreatedAt.Format(time.RFC3339),
+		Source:    t.Source,
+	}
+	if t.DoneAt != nil {
+		out.DoneAt = t.DoneAt.Format(time.RFC3339)
+	}
+	return out
+}
+
+// resolveTask looks up a task by its ID, with or without a leading #
+func resolveTask(database db.DB, arg string) (*db.Task, error) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(arg, "#"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid task ID %q", arg)
+	}
+	task, err := database.GetTask(id)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, fmt.Errorf("no task with ID %d", id)
+	}
+	return task, nil
+}
+
+// checkOpenTask checks that a task exists and is not done, so a session can
+// be linked to it
+func checkOpenTask(database db.DB, id int64) error {
+	task, err := database.GetTask(id)
+	if err != nil {
+		return err
+	}
+	if task == nil {
+		return fmt.Errorf("no task with ID %d", id)
+	}
+	if task.DoneAt != nil {
+		return fmt.Errorf("task %d is already done", id)
+	}
+	return nil
+}
+
+// taskProgress describes the pomodoros spent on a task against its estimate
+func taskProgress(pomodoros, estimate int) string {
+	if estimate == 0 {
+		return fmt.Sprintf("%d pomodoros", pomodoros)
+	}
+	progress := fmt.Sprintf("%d/%d pomodoros", pomodoros, estimate)
+	if pomodoros > estimate {
+		progress += fmt.Sprintf(" (%d over)", pomodoros-estimate)
+	}
+	return progress
+}
+
+func init() {
+	rootCmd.AddCommand(taskCmd)
+	taskCmd.AddCommand(taskAddCmd, taskListCmd, taskDoneCmd, taskShowCmd)
+
+	taskAddCmd.Flags().IntVarP(&taskEstimate, "estimate", "e", 0, "Estimated number of pomodoros")
+	taskListCmd.Flags().BoolVarP(&taskListAll, "all", "a", false, "Include done tasks")
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+// defaultUserName labels the database used without --user in team reports
+const defaultUserName = "(default)"
+
+// teamCmd groups commands that look across several people's sessions
+var teamCmd = &cobra.Command{
+	Use:   "team",
+	Short: "Commands across the people sharing this machine",
+}
+
+// teamLocalReportCmd compares the local users on this machine
+var teamLocalReportCmd = &cobra.Command{
+	Use:   "local-report",
+	Short: "Compares the stats of every local user on this machine",
+	Long: `Shows pomodoros, focus time, and completion rate for every local user on
+this machine side by side, with totals. Local users are created by running
+any command with --user NAME (or POMODORO_USER set); each keeps its sessions
+in a data directory of its own. Sessions recorded without --user are listed
+as (default).
+
+The period defaults to this week, like 'pomodoro stats'.
+
+Example:
+  pomodoro --user alice start "Homework"
+  pomodoro team local-report
+  pomodoro team local-report --month --json`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		startDate, endDate, err := statsRange(app.now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		report, err := localReport(startDate, endDate)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			printJSON(report)
+			return
+		}
+
+		printLocalReport(report)
+	},
+}
+
+// teamReport is the local report in its JSON form
+type teamReport struct {
+	From  string           `json:"from"`
+	To    string           `json:"to"`
+	Users []teamUserReport `json:"users"`
+	Total teamUserReport   `json:"total"`
+}
+
+type teamUserReport struct {
+	User           string  `json:"user"`
+	Pomodoros      int     `json:"pomodoros"`
+	Completed      int     `json:"completed"`
+	CompletionRate float64 `json:"completion_rate"`
+	FocusMinutes   float64 `json:"focus_minutes"`
+	ActiveDays     int     `json:"active_days,omitempty"` // Left out of the total
+}
+
+// localReport loads the stats of the default database and every local user
+// for a period. Users are read one database at a time, so a large history
+// never holds more than one open.
+func localReport(startDate, endDate time.Time) (*teamReport, error) {
+	users, err := db.LocalUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	type source struct{ name, path string }
+	var sources []source
+	def := utils.ExpandPath(defaultDatabasePath())
+	if def == "" {
+		if def, err = db.DefaultPath(); err != nil {
+			return nil, err
+		}
+	}
+	if fileExists(def) {
+		sources = append(sources, source{defaultUserName, def})
+	}
+	for _, name := range users {
+		path, err := db.UserPath(name)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source{name, path})
+	}
+
+	report := &teamReport{
+		From:  startDate.Format("2006-01-02"),
+		To:    endDate.Format("2006-01-02"),
+		Users: []teamUserReport{},
+		Total: teamUserReport{User: "total"},
+	}
+	for _, src := range sources {
+		database, err := db.NewDB(src.path)
+		if err != nil {
+			return nil, fmt.Errorf("error opening %s's sessions: %v", src.name, err)
+		}
+		stats, err := loadStats(database, startDate, endDate)
+		if closeErr := database.Close(); closeErr != nil {
+			fmt.Fprintf(os.Stderr, "Error closing database: %v\n", closeErr)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error getting %s's stats: %v", src.name, err)
+		}
+
+		report.Users = append(report.Users, teamUserReport{
+			User:           src.name,
+			Pomodoros:      stats.Pomodoros,
+			Completed:      stats.Completed,
+			CompletionRate: stats.CompletionRate,
+			FocusMinutes:   stats.FocusMinutes,
+			ActiveDays:     stats.ActiveDays,
+		})
+		report.Total.Pomodoros += stats.Pomodoros
+		report.Total.Completed += stats.Completed
+		report.Total.FocusMinutes += stats.FocusMinutes
+	}
+	if report.Total.Pomodoros > 0 {
+		report.Total.CompletionRate = float64(report.Total.Completed) / float64(report.Total.Pomodoros)
+	}
+	return report, nil
+}
+
+// printLocalReport prints the local report as a table
+func printLocalReport(r *teamReport) {
+	fmt.Printf("Local users from %s to %s:\n", r.From, r.To)
+	fmt.Println("------------------------------")
+	if len(r.Users) == 0 {
+		fmt.Println("No sessions on this machine yet.")
+		return
+	}
+
+	width := len("total")
+	for _, u := range r.Users {
+		width = max(width, len(u.User))
+	}
+	row := func(u teamUserReport) {
+		focus := utils.FormatDurationLong(time.Duration(u.FocusMinutes * float64(time.Minute)).Round(time.Second))
+		fmt.Printf("%-*s  %4d pomodoros  %12s  %3.0f%% completed\n", width, u.User, u.Pomodoros, focus, u.CompletionRate*100)
+	}
+	for _, u := range r.Users {
+		row(u)
+	}
+	if len(r.Users) > 1 {
+		fmt.Println()
+		row(r.Total)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(teamCmd)
+	teamCmd.AddCommand(teamLocalReportCmd)
+
+	teamLocalReportCmd.Flags().BoolVar(&statsWeek, "week", false, "Show this week (the default)")
+	teamLocalReportCmd.Flags().BoolVar(&statsMonth, "month", false, "Show this month")
+	teamLocalReportCmd.Flags().StringVar(&statsFrom, "from", "", "Start date (YYYY-MM-DD, yesterday, monday, 7d, ...)")
+	teamLocalReportCmd.Flags().StringVar(&statsTo, "to", "", "End date, inclusive (YYYY-MM-DD, yesterday, monday, 7d, ...)")
+}
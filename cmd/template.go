@@ -0,0 +1,414 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/model"
+	"github.com/ethan-k/pomodoro-cli/internal/term"
+	"github.com/ethan-k/pomodoro-cli/internal/trace"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+var (
+	templateStats  bool
+	pruneUnusedFor string
+)
+
+// templateCmd groups the template commands
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Starts pomodoros from the setups in the templates config",
+	Long: `Templates are pomodoro setups kept under templates in the config file,
+each with a description, a duration, and tags:
+
+  templates:
+    review:
+      description: "Code review"
+      duration: "30m"
+      tags: [review]
+
+Each start of a template is counted, and the templates used most come first.
+'template list --stats' shows the counts, and 'template prune' suggests the
+templates that have gone unused.`,
+}
+
+// templateListCmd lists the templates
+var templateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the templates, most used first",
+	Long: `Lists the templates, most used first. With --stats, shows how often
+each was started and when it was last used instead of its setup.
+
+Examples:
+  pomodoro template list
+  pomodoro template list --stats`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		choices, err := templateChoices(cfg, database)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			printJSON(choices)
+			return
+		}
+
+		if len(choices) == 0 {
+			fmt.Println("No templates. Add some under templates in the config file.")
+			return
+		}
+		if !templateStats {
+			for _, c := range choices {
+				fmt.Printf("%-16s %s\n", c.Name, c.setup())
+			}
+			return
+		}
+		now := app.now()
+		fmt.Printf("%-16s %5s  %s\n", "NAME", "USES", "LAST USED")
+		for _, c := range choices {
+			last := "never"
+			if c.LastUsed != nil {
+				last = fmt.Sprintf("%s (%s)", c.LastUsed.Local().Format("2006-01-02"), daysAgo(*c.LastUsed, now))
+			}
+			fmt.Printf("%-16s %5d  %s\n", c.Name, c.Uses, last)
+		}
+	},
+}
+
+// templatePruneCmd suggests templates to remove
+var templatePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Suggests removing templates that have gone unused",
+	Long: `Lists the templates not started within --unused-for, including those never
+started, as candidates to remove from templates in the config file. The
+config file is left as it is.
+
+Examples:
+  pomodoro template prune
+  pomodoro template prune --unused-for 6mo`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		now := app.now()
+		cutoff, err := utils.ParseDate(pruneUnusedFor, now)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing --unused-for: %v\n", err)
+			os.Exit(1)
+		}
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		choices, err := templateChoices(cfg, database)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		stale := staleTemplates(choices, cutoff)
+
+		if jsonOutput {
+			printJSON(struct {
+				UnusedSince time.Time        `json:"unused_since"`
+				Templates   []templateChoice `json:"templates"`
+			}{cutoff, stale})
+			return
+		}
+
+		if len(stale) == 0 {
+			fmt.Printf("Every template has been used since %s.\n", cutoff.Format("2006-01-02"))
+			return
+		}
+		fmt.Printf("Not used since %s:\n", cutoff.Format("2006-01-02"))
+		for _, c := range stale {
+			last := "never used"
+			if c.LastUsed != nil {
+				last = fmt.Sprintf("last used %s (%s)", c.LastUsed.Local().Format("2006-01-02"), daysAgo(*c.LastUsed, now))
+			}
+			fmt.Printf("  %-16s %s\n", c.Name, last)
+		}
+		fmt.Println("Remove them from templates in the config file to prune them.")
+	},
+}
+
+// templateStartCmd starts a pomodoro from a template
+var templateStartCmd = &cobra.Command{
+	Use:   "start [name]",
+	Short: "Starts a pomodoro from a template",
+	Long: `Starts a pomodoro with a template's description, duration, and tags.
+
+Without a name, a picker lists the templates, most used first, with their
+duration, tags, and when they were last used. Typing narrows the list by
+fuzzy search; Enter starts the template under the cursor.
+
+Examples:
+  pomodoro template start review
+  pomodoro template start
+  pomodoro template start deep --no-wait`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeTemplate,
+	Run: func(_ *cobra.Command, args []string) {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		if len(cfg.Templates) == 0 {
+			fmt.Fprintln(os.Stderr, "No templates. Add some under templates in the config file.")
+			os.Exit(1)
+		}
+
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		chosen, err := chooseTemplate(cfg, database, args)
+		if err == nil && chosen != nil {
+			// Counted when chosen, as start may not return
+			err = database.RecordTemplateUse(chosen.Name, app.now())
+		}
+		if closeErr := database.Close(); closeErr != nil {
+			fmt.Fprintf(os.Stderr, "Error closing database: %v\n", closeErr)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		if chosen == nil {
+			fmt.Println("No template chosen.")
+			return
+		}
+
+		tags = chosen.Tags
+		if chosen.template.Duration != "" {
+			if err := startCmd.Flags().Set("duration", chosen.template.Duration); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid duration in template %s: %v\n", chosen.Name, err)
+				os.Exit(1)
+			}
+		}
+		startCmd.Run(startCmd, []string{chosen.Description})
+	},
+}
+
+// templateChoice is a template as list and the picker show it
+type templateChoice struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Duration    string     `json:"duration"`
+	Tags        []string   `json:"tags"`
+	Uses        int        `json:"uses"`
+	LastUsed    *time.Time `json:"last_used,omitempty"`
+
+	template config.Template
+}
+
+// setup describes what the template starts
+func (c templateChoice) setup() string {
+	parts := []string{c.Description, c.Duration}
+	if len(c.Tags) > 0 {
+		parts = append(parts, term.Tags(strings.Join(c.Tags, ",")))
+	}
+	return strings.Join(parts, "  ")
+}
+
+// detail describes the template after its name in the picker
+func (c templateChoice) detail() string {
+	if c.LastUsed == nil {
+		return c.setup() + "  never used"
+	}
+	return fmt.Sprintf("%s  used %d×, last %s", c.setup(), c.Uses, c.LastUsed.Local().Format("2006-01-02"))
+}
+
+// staleTemplates returns the choices not started since cutoff, those never
+// started first, then the longest unused
+func staleTemplates(choices []templateChoice, cutoff time.Time) []templateChoice {
+	stale := []templateChoice{}
+	for _, c := range choices {
+		if c.LastUsed == nil || c.LastUsed.Before(cutoff) {
+			stale = append(stale, c)
+		}
+	}
+	slices.SortStableFunc(stale, func(a, b templateChoice) int {
+		return lastUsed(a).Compare(lastUsed(b))
+	})
+	return stale
+}
+
+// daysAgo describes how many calendar days before now t was
+func daysAgo(t, now time.Time) string {
+	date := func(t time.Time) time.Time {
+		y, m, d := t.In(now.Location()).Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	switch days := int(date(now).Sub(date(t)).Hours() / 24); days {
+	case 0:
+		return "today"
+	case 1:
+		return "1 day ago"
+	default:
+		return fmt.Sprintf("%d days ago", days)
+	}
+}
+
+// templateChoices returns the configured templates, the most used first
+// and then the most recently used, with the rest by name
+func templateChoices(cfg *config.Config, database db.DB) ([]templateChoice, error) {
+	usage, err := database.GetTemplateUsage()
+	if err != nil {
+		return nil, err
+	}
+
+	choices := make([]templateChoice, 0, len(cfg.Templates))
+	for name, t := range cfg.Templates {
+		c := templateChoice{Name: name, Description: t.Description, Tags: t.Tags, template: t}
+		if c.Description == "" {
+			c.Description = name
+		}
+		if c.Tags == nil {
+			c.Tags = []string{}
+		}
+		if d, err := utils.ParseHumanDuration(t.Duration); t.Duration != "" && err == nil {
+			c.Duration = d.String()
+		} else {
+			d, _ := resolveDuration(nil, t.Tags, cfg)
+			c.Duration = d.String()
+		}
+		if u, ok := usage[name]; ok {
+			c.Uses = u.Uses
+			c.LastUsed = &u.LastUsed
+		}
+		choices = append(choices, c)
+	}
+
+	slices.SortFunc(choices, func(a, b templateChoice) int {
+		return cmp.Or(cmp.Compare(b.Uses, a.Uses), lastUsed(b).Compare(lastUsed(a)), cmp.Compare(a.Name, b.Name))
+	})
+	return choices, nil
+}
+
+// lastUsed returns when a template was last started, zero if never
+func lastUsed(c templateChoice) time.Time {
+	if c.LastUsed == nil {
+		return time.Time{}
+	}
+	return *c.LastUsed
+}
+
+// chooseTemplate returns the template named in args, or the one picked
+// interactively without a name. It returns nil when the picker is left
+// without a choice.
+func chooseTemplate(cfg *config.Config, database db.DB, args []string) (*templateChoice, error) {
+	choices, err := templateChoices(cfg, database)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) > 0 {
+		for i, c := range choices {
+			if strings.EqualFold(c.Name, args[0]) {
+				return &choices[i], nil
+			}
+		}
+		return nil, fmt.Errorf("no template named %q (see 'pomodoro template list')", args[0])
+	}
+
+	if jsonOutput || !isInteractive() {
+		return nil, fmt.Errorf("give a template name, as the picker needs an interactive terminal")
+	}
+	items := make([]model.PickerItem, 0, len(choices))
+	for _, c := range choices {
+		items = append(items, model.PickerItem{Name: c.Name, Detail: c.detail()})
+	}
+	wait := trace.Begin(trace.Wait, "template picker")
+	final, err := tea.NewProgram(model.NewPickerModel("Start a template", items)).Run()
+	wait()
+	if err != nil {
+		return nil, fmt.Errorf("error running UI: %v", err)
+	}
+	picker, ok := final.(model.PickerModel)
+	if !ok {
+		return nil, nil
+	}
+	if i, ok := picker.Chosen(); ok {
+		return &choices[i], nil
+	}
+	return nil, nil
+}
+
+// completeTemplate offers the configured templates, most used first
+func completeTemplate(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names := withCompletionDB(func(database db.DB) []string {
+		choices, err := templateChoices(cfg, database)
+		if err != nil {
+			return nil
+		}
+		names := make([]string, 0, len(choices))
+		for _, c := range choices {
+			names = append(names, c.Name+"\t"+c.Description)
+		}
+		return names
+	})
+	return names, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+func init() {
+	rootCmd.AddCommand(templateCmd)
+	templateCmd.AddCommand(templateListCmd)
+	templateCmd.AddCommand(templateStartCmd)
+	templateCmd.AddCommand(templatePruneCmd)
+
+	templateListCmd.Flags().BoolVar(&templateStats, "stats", false, "Show how often and when each template was used")
+	templatePruneCmd.Flags().StringVar(&pruneUnusedFor, "unused-for", "90d", "Suggest templates not started for this long (e.g. 90d, 6mo, 1y)")
+
+	templateStartCmd.Flags().BoolVar(&noWait, "no-wait", false, "Run in background without showing progress bar")
+	templateStartCmd.Flags().BoolVar(&silentMode, "silent", false, "Disable audio notifications for this session")
+	templateStartCmd.Flags().Int64Var(&startTask, "task", 0, "Count the session toward this task (see 'pomodoro task list')")
+	_ = templateStartCmd.RegisterFlagCompletionFunc("task", completeTask)
+	templateStartCmd.Flags().BoolVar(&startOverride, "override", false, "Start even when past the daily limits with limits.refuse set")
+}
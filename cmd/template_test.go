@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+// writeTemplateConfig writes a config with the templates review, inbox, and
+// deep to the test's HOME
+func writeTemplateConfig(t *testing.T) {
+	t.Helper()
+	path := filepath.Join(os.Getenv("HOME"), ".config", "pomodoro", "config.yml")
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatal(err)
+	}
+	config := `defaults:
+  tag_durations:
+    email: 15m
+templates:
+  review: {description: Code review, duration: 30m, tags: [review]}
+  inbox: {tags: [email]}
+  deep: {description: Deep work, duration: 50 min}
+`
+	if err := os.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTemplateList(t *testing.T) {
+	now := time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local)
+	a := newTestApp(t, &mockDB{
+		GetTemplateUsageFunc: func() (map[string]db.TemplateUsage, error) {
+			return map[string]db.TemplateUsage{
+				"review": {Uses: 3, LastUsed: now.Add(-48 * time.Hour)},
+				"inbox":  {Uses: 3, LastUsed: now.Add(-time.Hour)},
+			}, nil
+		},
+	}, now)
+
+	writeTemplateConfig(t)
+
+	var choices []templateChoice
+	if err := json.Unmarshal([]byte(a.run(t, "template", "list", "--json")), &choices); err != nil {
+		t.Fatal(err)
+	}
+	if len(choices) != 3 {
+		t.Fatalf("Expected 3 templates, got %+v", choices)
+	}
+	// Most used first, then the most recently used, then never used
+	for i, want := range []struct {
+		name, description, duration string
+		uses                        int
+	}{
+		{"inbox", "inbox", "15m0s", 3},
+		{"review", "Code review", "30m0s", 3},
+		{"deep", "Deep work", "50m0s", 0},
+	} {
+		c := choices[i]
+		if c.Name != want.name || c.Description != want.description || c.Duration != want.duration || c.Uses != want.uses {
+			t.Errorf("Template %d = %+v, want %+v", i, c, want)
+		}
+	}
+	if choices[2].LastUsed != nil {
+		t.Errorf("Expected no last use for a template never started, got %s", choices[2].LastUsed)
+	}
+
+	out := a.run(t, "template", "list", "--stats")
+	want := `NAME              USES  LAST USED
+inbox                3  2025-03-14 (today)
+review               3  2025-03-12 (2 days ago)
+deep                 0  never
+`
+	if out != want {
+		t.Errorf("template list --stats printed %q, want %q", out, want)
+	}
+}
+
+func TestTemplatePrune(t *testing.T) {
+	now := time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local)
+	a := newTestApp(t, &mockDB{
+		GetTemplateUsageFunc: func() (map[string]db.TemplateUsage, error) {
+			return map[string]db.TemplateUsage{
+				"review": {Uses: 40, LastUsed: now.AddDate(0, 0, -120)},
+				"inbox":  {Uses: 2, LastUsed: now.AddDate(0, 0, -10)},
+			}, nil
+		},
+	}, now)
+	writeTemplateConfig(t)
+
+	out := a.run(t, "template", "prune", "--unused-for", "90d")
+	want := `Not used since 2024-12-14:
+  deep             never used
+  review           last used 2024-11-14 (120 days ago)
+Remove them from templates in the config file to prune them.
+`
+	if out != want {
+		t.Errorf("template prune printed %q, want %q", out, want)
+	}
+
+	var pruned struct {
+		Templates []templateChoice `json:"templates"`
+	}
+	if err := json.Unmarshal([]byte(a.run(t, "template", "prune", "--unused-for", "1y", "--json")), &pruned); err != nil {
+		t.Fatal(err)
+	}
+	if len(pruned.Templates) != 1 || pruned.Templates[0].Name != "deep" {
+		t.Errorf("Expected only the template never used within a year, got %+v", pruned.Templates)
+	}
+}
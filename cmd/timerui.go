@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/daemon"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/hooks"
+	"github.com/ethan-k/pomodoro-cli/internal/model"
+	"github.com/ethan-k/pomodoro-cli/internal/trace"
+)
+
+// timerActionHooks maps the timer keys to the hook events of the commands
+// that do the same thing. Finishing early is left to the caller, which runs
+// the completion hooks as for a session that ran its full length.
+var timerActionHooks = map[model.Action]string{
+	model.ActionPause:  hooks.Pause,
+	model.ActionResume: hooks.Resume,
+	model.ActionCancel: hooks.Cancel,
+}
+
+// sleepActions are the values idle.on_sleep takes
+var sleepActions = map[string]model.SleepAction{
+	"pause":     model.SleepPause,
+	"interrupt": model.SleepInterrupt,
+	"ignore":    model.SleepIgnore,
+}
+
+// configuredSleep returns what the timer does when the machine sleeps during
+// a pomodoro, pausing it unless the config says otherwise
+func configuredSleep() model.SleepAction {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return model.SleepPause
+	}
+	if action, ok := sleepActions[cfg.Idle.OnSleep]; ok {
+		return action
+	}
+	return model.SleepPause
+}
+
+// timerUIs are the values start --ui takes: the progress bar alone, or the
+// bar with today's sessions, goals, and task queue below it
+var timerUIs = []string{"bar", "full"}
+
+// timerDashboard returns what the full timer view shows, read from database
+func timerDashboard(database db.DB) model.DashboardLoader {
+	return func() (model.Dashboard, error) {
+		now := app.now()
+		sessions, err := database.GetSessionsByDateRange(now, now)
+		if err != nil {
+			return model.Dashboard{}, err
+		}
+		status, _, err := goalStatus(database, now)
+		if err != nil {
+			return model.Dashboard{}, err
+		}
+		queue, err := database.ListTasks(false)
+		if err != nil {
+			return model.Dashboard{}, err
+		}
+
+		dashboard := model.Dashboard{Sessions: sessions, Queue: queue}
+		for _, g := range []model.DashboardGoal{
+			{Label: "Today", Done: status.DailyCompleted, Goal: status.DailyGoal},
+			{Label: "This week", Done: status.WeeklyCompleted, Goal: status.WeeklyGoal},
+			{Label: "This month", Done: status.MonthlyCompleted, Goal: status.MonthlyGoal},
+		} {
+			if g.Goal > 0 {
+				dashboard.Goals = append(dashboard.Goals, g)
+			}
+		}
+		return dashboard, nil
+	}
+}
+
+// daemonStore is the store the timer keys change the session in. While a
+// daemon runs, pausing, resuming, extending, and cancelling go through it so
+// it moves its own timer at once rather than on its next check; otherwise
+// the database is changed directly.
+type daemonStore struct {
+	db.DB
+}
+
+// PauseSession pauses the session, through the daemon when one is running.
+// The daemon pauses as it is asked, so a pause back-dated over time the
+// machine slept is saved directly and the daemon picks it up on its next
+// check.
+func (s daemonStore) PauseSession(id int64, pausedAt time.Time) error {
+	if time.Since(pausedAt) < time.Second {
+		if _, ok, err := daemonCall(daemon.ActionPause); ok {
+			return err
+		}
+	}
+	return s.DB.PauseSession(id, pausedAt)
+}
+
+// ResumeSession resumes the session, through the daemon when one is running
+func (s daemonStore) ResumeSession(id int64, resumedAt time.Time) error {
+	if _, ok, err := daemonCall(daemon.ActionResume); ok {
+		return err
+	}
+	return s.DB.ResumeSession(id, resumedAt)
+}
+
+// ExtendSession lengthens or, with a negative by, shortens the session,
+// through the daemon when one is running
+func (s daemonStore) ExtendSession(id int64, by time.Duration) error {
+	if _, ok, err := daemonSend(daemon.Request{Action: daemon.ActionExtend, Extend: by}); ok {
+		return err
+	}
+	return s.DB.ExtendSession(id, by)
+}
+
+// EndSession ends the session. Cancelling goes through the daemon when one
+// is running; a session finished early is ended in the database, and the
+// daemon, seeing its end move earlier, leaves announcing it to the timer.
+func (s daemonStore) EndSession(id int64, endedAt time.Time, status string) error {
+	if status == db.StatusCancelled {
+		if _, ok, err := daemonCall(daemon.ActionCancel); ok {
+			return err
+		}
+	}
+	return s.DB.EndSession(id, endedAt, status)
+}
+
+// runTimerUI runs a timer UI until it exits, with keys that pause, resume,
+// extend, shorten, finish, or cancel the session in database. While it
+// runs, edits to the config file are sent to the UI, which reapplies the
+// display settings and redraws. Notification and goal settings
+// need no reload, as they are read from the config when used.
+//
+// It reports whether the session finished, by running its full length or
+// from the finish key. A session cancelled from the keyboard has no
+// completion to announce, nor has one ended because the machine slept
+// through it, and one left running with Ctrl+C is handed to the daemon to
+// announce.
+func runTimerUI(database db.DB, m model.PomodoroModel) (finished bool, err error) {
+	// Hooks run alongside the UI so a slow one does not freeze the timer
+	var running sync.WaitGroup
+	defer running.Wait()
+	m = m.WithSleep(configuredSleep()).WithControls(daemonStore{database}, func(action model.Action) {
+		if event, ok := timerActionHooks[action]; ok {
+			running.Add(1)
+			go func() {
+				defer running.Done()
+				runHooks(database, event, m.ID)
+			}()
+		}
+	})
+
+	program := tea.NewProgram(m)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		// Without a watcher the UI simply keeps its current settings
+		_ = config.Watch(ctx, func(cfg *config.Config, err error) {
+			program.Send(model.ConfigReloadedMsg{Config: cfg, Err: err})
+		})
+	}()
+
+	wait := trace.Begin(trace.Wait, "timer")
+	final, err := program.Run()
+	wait()
+	if err != nil {
+		return false, err
+	}
+	fm, ok := final.(model.PomodoroModel)
+	if !ok {
+		return true, nil
+	}
+	if fm.Interrupted() {
+		ensureDaemon()
+	}
+	if fm.Abandoned() {
+		runHooks(database, hooks.Cancel, fm.ID)
+	}
+	return !fm.Interrupted() && !fm.Cancelled() && !fm.Abandoned(), nil
+}
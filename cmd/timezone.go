@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata" // Zone names for --timezone on systems without a zoneinfo database
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+// Special values accepted by history --timezone
+const (
+	timezoneLocal   = "local"   // The current system time zone
+	timezoneSession = "session" // Each session's own recorded time zone
+)
+
+// noteTimezoneChange tells the user when the time zone differs from the one
+// the previous session was recorded in, e.g. after travelling
+func noteTimezoneChange(database db.DB, now time.Time) {
+	last, err := database.GetLastSession()
+	if err != nil || last == nil {
+		return
+	}
+
+	name, offset := now.Zone()
+	if offset == last.TZOffset {
+		return
+	}
+
+	current := db.PomodoroSession{TZName: name, TZOffset: offset}
+	decorf("%sTime zone changed since your last session (%s → %s). Each session keeps the day it had where it was recorded.\n",
+		icon("🌍"), last.ZoneLabel(), current.ZoneLabel())
+}
+
+// parseTimezone resolves a --timezone value. A nil location with no error
+// means each session is shown in its own recorded zone.
+func parseTimezone(name string) (*time.Location, error) {
+	switch name {
+	case "", timezoneLocal:
+		return time.Local, nil
+	case timezoneSession:
+		return nil, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q: use an IANA name like Europe/Berlin, %q, or %q", name, timezoneLocal, timezoneSession)
+	}
+	return loc, nil
+}
+
+// inZone converts t to loc, or to the session's recorded zone when loc is nil
+func inZone(t time.Time, s db.PomodoroSession, loc *time.Location) time.Time {
+	if loc == nil {
+		return t.In(s.Location())
+	}
+	return t.In(loc)
+}
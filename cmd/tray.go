@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/tray"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+// trayRefresh is how often the tray's countdown is redrawn
+const trayRefresh = time.Second
+
+// Tray menu entries
+const (
+	trayStart  = "start"
+	trayPause  = "pause"
+	trayResume = "resume"
+	trayCancel = "cancel"
+	trayQuit   = "quit"
+)
+
+// Icons the tray shows, from the freedesktop icon naming spec
+const (
+	trayIconIdle    = "appointment-new"
+	trayIconRunning = "appointment-soon"
+	trayIconPaused  = "media-playback-pause"
+)
+
+// trayCmd shows the timer in the system tray
+var trayCmd = &cobra.Command{
+	Use:   "tray",
+	Short: "Shows the timer in the system tray",
+	Long: `Puts an icon in the system tray until interrupted or quit from its menu.
+Its tooltip shows the active session and the time it has left, and its menu
+starts a pomodoro, as 'pomodoro start --no-wait' would, or pauses, resumes,
+or cancels the active one.
+
+On Linux the icon is a StatusNotifierItem, shown by KDE Plasma, GNOME with
+the AppIndicator extension, and panels such as waybar; add the command to
+your desktop's autostart to keep it there. Other platforms are not supported
+yet.
+
+Example:
+  pomodoro tray &`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		icon, err := tray.New("pomodoro")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error showing the tray icon: %v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := icon.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error removing the tray icon: %v\n", err)
+			}
+		}()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		ticker := time.NewTicker(trayRefresh)
+		defer ticker.Stop()
+		for {
+			session, err := activeSession(database)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error getting active session: %v\n", err)
+			}
+			if err := icon.Update(trayState(session, app.now())); err != nil {
+				fmt.Fprintf(os.Stderr, "Error updating the tray icon: %v\n", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case action := <-icon.Clicked():
+				if action == trayQuit {
+					return
+				}
+				if err := trayAction(database, action); err != nil {
+					fmt.Fprintf(os.Stderr, "%v\n", err)
+				}
+			}
+		}
+	},
+}
+
+// trayState is what the tray shows for the active session, nil for none
+func trayState(session *db.PomodoroSession, now time.Time) tray.State {
+	if session == nil {
+		return tray.State{
+			Icon:    trayIconIdle,
+			Tooltip: "Pomodoro",
+			Detail:  "No session running",
+			Menu:    trayMenu(false, false),
+		}
+	}
+
+	state := tray.State{Icon: trayIconRunning, Tooltip: sessionLabel(session), Menu: trayMenu(true, session.IsPaused)}
+	remaining := session.EndTime.Sub(now)
+	if session.IsPaused {
+		remaining = session.RemainingAtPause()
+		state.Icon = trayIconPaused
+	}
+	state.Detail = utils.FormatDuration(max(0, remaining).Round(time.Second)) + " left"
+	if session.IsPaused {
+		state.Detail = "Paused, " + state.Detail
+	}
+	return state
+}
+
+// trayMenu returns the tray menu for an active session, which may be
+// paused, or for none
+func trayMenu(active, paused bool) []tray.MenuItem {
+	toggle := tray.MenuItem{ID: trayPause, Label: "Pause", Enabled: active && !paused}
+	if paused {
+		toggle = tray.MenuItem{ID: trayResume, Label: "Resume", Enabled: true}
+	}
+	return []tray.MenuItem{
+		{ID: trayStart, Label: "Start pomodoro", Enabled: !active},
+		toggle,
+		{ID: trayCancel, Label: "Cancel", Enabled: active},
+		{ID: trayQuit, Label: "Quit tray", Enabled: true},
+	}
+}
+
+// trayAction carries out a tray menu entry. A pomodoro is started by running
+// 'start --no-wait', so it is set up as from the command line.
+func trayAction(database db.DB, action string) error {
+	if action == trayStart {
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("error finding the pomodoro executable: %v", err)
+		}
+		if out, err := exec.Command(exe, "start", "--no-wait").CombinedOutput(); err != nil {
+			return fmt.Errorf("error starting a pomodoro: %v: %s", err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+
+	if action == trayResume {
+		session, err := database.GetPausedSession()
+		if err != nil || session == nil {
+			return err
+		}
+		if _, err := resumeSession(database, session, true); err != nil {
+			return fmt.Errorf("error resuming session: %v", err)
+		}
+		return nil
+	}
+
+	session, err := activeSession(database)
+	if err != nil {
+		return fmt.Errorf("error getting active session: %v", err)
+	}
+	if session == nil {
+		return nil
+	}
+	switch action {
+	case trayPause:
+		if !session.IsPaused {
+			if _, err := pauseSession(database, session); err != nil {
+				return fmt.Errorf("error pausing session: %v", err)
+			}
+		}
+	case trayCancel:
+		if _, err := cancelSession(database, session); err != nil {
+			return fmt.Errorf("error cancelling session: %v", err)
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(trayCmd)
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func TestTrayState(t *testing.T) {
+	now := time.Date(2024, 6, 3, 9, 10, 0, 0, time.UTC)
+	idle := trayState(nil, now)
+	if idle.Detail != "No session running" || !idle.Menu[0].Enabled || idle.Menu[1].Enabled || idle.Menu[2].Enabled {
+		t.Errorf("Expected only start and quit without a session, got %+v", idle)
+	}
+
+	session := &db.PomodoroSession{StartTime: now.Add(-10 * time.Minute), EndTime: now.Add(15*time.Minute + 30*time.Second), Description: "Write report"}
+	running := trayState(session, now)
+	if running.Tooltip != "Write report" || running.Detail != "15:30 left" || running.Icon != trayIconRunning {
+		t.Errorf("Expected the time left in the tooltip, got %+v", running)
+	}
+	if running.Menu[0].Enabled || running.Menu[1].ID != trayPause || !running.Menu[1].Enabled || !running.Menu[2].Enabled {
+		t.Errorf("Expected pause and cancel while running, got %+v", running.Menu)
+	}
+
+	pausedAt := now.Add(-5 * time.Minute)
+	session.IsPaused, session.PausedAt = true, &pausedAt
+	paused := trayState(session, now)
+	if paused.Detail != "Paused, 20:30 left" || paused.Icon != trayIconPaused || paused.Menu[1].ID != trayResume {
+		t.Errorf("Expected the time left at the pause and resume, got %+v", paused)
+	}
+}
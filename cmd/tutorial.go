@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/model"
+	"github.com/ethan-k/pomodoro-cli/internal/trace"
+)
+
+// tutorialCmd walks new users through the basics
+var tutorialCmd = &cobra.Command{
+	Use:   "tutorial",
+	Short: "A guided walkthrough for new users",
+	Long: `Walks you through a 10-second practice session and a practice break,
+then asks for your daily and weekly goals and saves them to the config.
+Practice timers are not saved to your history.
+
+Example:
+  pomodoro tutorial`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if !isInteractive() {
+			fmt.Fprintln(os.Stderr, "The tutorial needs an interactive terminal.")
+			os.Exit(1)
+		}
+
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		wait := trace.Begin(trace.Wait, "tutorial")
+		final, err := tea.NewProgram(model.NewTutorialModel(cfg.Goals.DailyCount, cfg.Goals.WeeklyCount)).Run()
+		wait()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error running tutorial: %v\n", err)
+			os.Exit(1)
+		}
+		tutorial, ok := final.(model.TutorialModel)
+		if !ok || !tutorial.Completed() {
+			fmt.Println("Tutorial stopped. Run 'pomodoro tutorial' to start again.")
+			return
+		}
+
+		cfg.Goals.DailyCount, cfg.Goals.WeeklyCount = tutorial.Goals()
+		cfg.Tutorial.Completed = true
+		cfg.Tutorial.Suggested = true
+		if err := config.SaveConfig(cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
+			os.Exit(1)
+		}
+		decorf("%sGoals saved. Happy focusing!\n", icon("🎉"))
+	},
+}
+
+// suggestTutorial points a new user, one with no sessions yet, to the
+// tutorial. It is suggested once; the config remembers that it was.
+func suggestTutorial(hasSessions bool) {
+	if hasSessions || quietMode || !isInteractive() {
+		return
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil || cfg.Tutorial.Completed || cfg.Tutorial.Suggested {
+		return
+	}
+
+	fmt.Printf("%sNew here? 'pomodoro tutorial' walks you through the basics in a minute.\n", icon("👋"))
+	cfg.Tutorial.Suggested = true
+	if err := config.SaveConfig(cfg); err != nil {
+		warnf("could not save config: %v\n", err)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(tutorialCmd)
+}
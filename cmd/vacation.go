@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+var (
+	vacationFrom   string
+	vacationTo     string
+	vacationRemove int64
+)
+
+// goalsVacationCmd logs days off that do not break the streak
+var goalsVacationCmd = &cobra.Command{
+	Use:   "vacation",
+	Short: "Logs a vacation, days off that do not break your streak",
+	Long: `Logs the days from --from to --to, inclusive, as a vacation. Days in a
+vacation without pomodoros do not break your streak, as rest days of the week
+set with goals.rest_days do not:
+
+  goals:
+    rest_days: [saturday, sunday]
+
+Without --from, the vacations logged are listed. --remove takes one back by
+the number the list shows.
+
+Example:
+  pomodoro goals vacation --from 2024-08-05 --to 2024-08-16
+  pomodoro goals vacation
+  pomodoro goals vacation --remove 2`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		database, err := openDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := database.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
+			}
+		}()
+
+		switch {
+		case cmd.Flags().Changed("remove"):
+			if err := database.DeleteVacation(vacationRemove); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			if jsonOutput {
+				printJSON(struct {
+					Removed int64 `json:"removed"`
+				}{vacationRemove})
+				return
+			}
+			fmt.Printf("Removed vacation %d.\n", vacationRemove)
+		case vacationFrom != "":
+			addVacation(database)
+		case vacationTo != "":
+			fmt.Fprintln(os.Stderr, "Give the first day off with --from")
+			os.Exit(1)
+		default:
+			listVacations(database)
+		}
+	},
+}
+
+// addVacation logs the vacation given by --from and --to, a single day
+// without --to
+func addVacation(database db.DB) {
+	now := app.now()
+	from, err := utils.ParseDate(vacationFrom, now)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing from date: %v\n", err)
+		os.Exit(1)
+	}
+	to := from
+	if vacationTo != "" {
+		if to, err = utils.ParseDate(vacationTo, now); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing to date: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if to.Before(from) {
+		fmt.Fprintln(os.Stderr, "Invalid vacation: --to is before --from")
+		os.Exit(1)
+	}
+
+	id, err := database.AddVacation(from, to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if jsonOutput {
+		printJSON(vacationJSON{ID: id, From: from.Format("2006-01-02"), To: to.Format("2006-01-02")})
+		return
+	}
+	fmt.Printf("Logged vacation %d: %s to %s. Your streak carries on over it.\n", id, from.Format("2006-01-02"), to.Format("2006-01-02"))
+}
+
+// listVacations prints the vacations logged
+func listVacations(database db.DB) {
+	vacations, err := database.GetVacations()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting vacations: %v\n", err)
+		os.Exit(1)
+	}
+	if jsonOutput {
+		out := make([]vacationJSON, 0, len(vacations))
+		for _, v := range vacations {
+			out = append(out, vacationJSON(v))
+		}
+		printJSON(out)
+		return
+	}
+	if len(vacations) == 0 {
+		fmt.Println("No vacations logged. Log one with 'pomodoro goals vacation --from 2024-08-05 --to 2024-08-16'.")
+		return
+	}
+	for _, v := range vacations {
+		fmt.Printf("%3d  %s to %s\n", v.ID, v.From, v.To)
+	}
+}
+
+// vacationJSON is the JSON representation of a vacation
+type vacationJSON struct {
+	ID   int64  `json:"id"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func init() {
+	goalsCmd.AddCommand(goalsVacationCmd)
+
+	goalsVacationCmd.Flags().StringVar(&vacationFrom, "from", "", "First day off (YYYY-MM-DD)")
+	goalsVacationCmd.Flags().StringVar(&vacationTo, "to", "", "Last day off (YYYY-MM-DD); the same day as --from when left out")
+	goalsVacationCmd.Flags().Int64Var(&vacationRemove, "remove", 0, "Remove the vacation with this number")
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/model"
+	"github.com/ethan-k/pomodoro-cli/internal/trace"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+// configuredWarmup returns the warm-up before each pomodoro from the config
+func configuredWarmup(cfg *config.Config) time.Duration {
+	return utils.ParseDurationWithDefaults(cfg.Defaults.Warmup, 0)
+}
+
+// runWarmup counts down a warm-up of d before the pomodoro described. It
+// returns the time the warm-up took and whether the pomodoro should start,
+// which it should not when the warm-up was abandoned with Ctrl+C.
+func runWarmup(description string, d time.Duration) (time.Duration, bool, error) {
+	started := time.Now()
+	m := model.NewPomodoroModel(0, description, started, d, false).WithWarmup()
+	wait := trace.Begin(trace.Wait, "warm-up")
+	final, err := tea.NewProgram(m).Run()
+	wait()
+	if err != nil {
+		return 0, false, err
+	}
+	taken := min(time.Since(started), d).Round(time.Second)
+	if fm, ok := final.(model.PomodoroModel); ok && fm.Interrupted() {
+		return taken, false, nil
+	}
+	return taken, true, nil
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/goals"
+)
+
+// capacityWarning returns a warning if adding planned pomodoros to today's
+// count would exceed the configured share of the 7-day average
+func capacityWarning(database db.DB, planned int) string {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+
+	workload, err := goals.ComputeWorkload(database, app.now(), cfg.Goals.CapacityThreshold)
+	if err != nil {
+		return ""
+	}
+
+	return workload.Warning(workload.Today + planned)
+}
+
+// warnIfOverCapacity prints a capacity warning before starting another pomodoro
+func warnIfOverCapacity(database db.DB) {
+	if warning := capacityWarning(database, 1); warning != "" {
+		warnf("%s\n", warning)
+	}
+}
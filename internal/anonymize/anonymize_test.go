@@ -0,0 +1,60 @@
+package anonymize
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func sampleSessions() []db.PomodoroSession {
+	start := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	// Newest first, as returned by the database
+	return []db.PomodoroSession{
+		{ID: 12, UID: "ffff00", StartTime: start.Add(time.Hour), EndTime: start.Add(85 * time.Minute), Description: "Write report", TagsCSV: "work,writing", DurationSec: 1500, TZName: "CEST", TZOffset: 7200},
+		{ID: 11, UID: "aaaa00", StartTime: start, EndTime: start.Add(25 * time.Minute), Description: "Write report", TagsCSV: "work", DurationSec: 1500},
+	}
+}
+
+func TestStripKeepsTiming(t *testing.T) {
+	a, err := New(Strip, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := sampleSessions()
+	out := a.Sessions(in)
+
+	for i, s := range out {
+		if s.Description != "" || s.TagsCSV != "" || s.UID != "" || s.TZName != "" {
+			t.Errorf("Expected personal fields to be removed, got %+v", s)
+		}
+		if !s.StartTime.Equal(in[i].StartTime) || s.DurationSec != in[i].DurationSec || s.TZOffset != in[i].TZOffset {
+			t.Errorf("Expected timing to be preserved, got %+v", s)
+		}
+	}
+	if out[0].ID != 2 || out[1].ID != 1 {
+		t.Errorf("Expected chronological renumbering, got %d and %d", out[0].ID, out[1].ID)
+	}
+	if in[0].Description != "Write report" {
+		t.Error("Expected the input sessions to be left unchanged")
+	}
+}
+
+func TestHashKeepsEqualValuesEqual(t *testing.T) {
+	a, err := New(Hash, []byte("salt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := a.Sessions(sampleSessions())
+
+	if out[0].Description != out[1].Description || !strings.HasPrefix(out[0].Description, "d_") {
+		t.Errorf("Expected equal hashed descriptions, got %q and %q", out[0].Description, out[1].Description)
+	}
+	if strings.Contains(out[0].TagsCSV, "work") || len(strings.Split(out[0].TagsCSV, ",")) != 2 {
+		t.Errorf("Expected two hashed tags, got %q", out[0].TagsCSV)
+	}
+	if !strings.HasPrefix(out[1].TagsCSV, strings.Split(out[0].TagsCSV, ",")[0]) {
+		t.Errorf("Expected the shared tag to hash identically, got %q and %q", out[0].TagsCSV, out[1].TagsCSV)
+	}
+}
@@ -17,8 +17,19 @@ const (
 	BreakComplete SoundType = "break_complete"
 	// SessionStart represents the sound played when starting a session
 	SessionStart SoundType = "session_start"
+	// GoalAchieved represents the default sound played when an achievement is unlocked
+	GoalAchieved SoundType = "goal_achieved"
 )
 
+// achievementPrefix namespaces per-achievement sound types
+const achievementPrefix = "achievement_"
+
+// AchievementSound returns the sound type for a specific achievement.
+// Achievements without a configured sound fall back to GoalAchieved.
+func AchievementSound(id string) SoundType {
+	return SoundType(achievementPrefix + id)
+}
+
 // Player interface for audio playback
 type Player interface {
 	Play(soundType SoundType) error
@@ -49,6 +60,7 @@ func DefaultConfig() *Config {
 			string(PomodoroComplete): "pomodoro_complete.wav",
 			string(BreakComplete):    "break_complete.wav",
 			string(SessionStart):     "session_start.wav",
+			string(GoalAchieved):     "session_start.wav",
 		},
 		CustomSoundsDir: filepath.Join(home, ".config", "pomodoro", "sounds"),
 	}
@@ -68,7 +80,7 @@ func NewPlayer(config *Config) (Player, error) {
 	player, err := newSystemPlayer(config)
 	if err != nil {
 		// Fallback to no-op player if audio system unavailable
-		fmt.Printf("Warning: Audio unavailable, continuing without sound: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Warning: Audio unavailable, continuing without sound: %v\n", err)
 		return &NoOpPlayer{}, nil
 	}
 
@@ -0,0 +1,45 @@
+package audio
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// builtinSounds holds the default notification sounds shipped with the binary
+//
+//go:embed sounds/*.wav
+var builtinSounds embed.FS
+
+// builtinSoundPath returns a playable file path for an embedded sound.
+// External players need a real file, so the sound is extracted to the
+// user cache directory the first time it is requested.
+func builtinSoundPath(filename string) (string, error) {
+	data, err := builtinSounds.ReadFile("sounds/" + filename)
+	if err != nil {
+		return "", fmt.Errorf("no built-in sound %s: %w", filename, err)
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+	dir := filepath.Join(cacheDir, "pomodoro", "sounds")
+	path := filepath.Join(dir, filename)
+
+	// Reuse a previously extracted copy if it is identical
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, data) { // #nosec G304 - path is built from the cache dir and an embedded filename
+		return path, nil
+	}
+
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return "", fmt.Errorf("error creating sound cache dir: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("error extracting built-in sound: %w", err)
+	}
+
+	return path, nil
+}
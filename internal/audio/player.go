@@ -7,8 +7,11 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
 
 	"github.com/gen2brain/beeep"
+
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
 )
 
 // SystemPlayer implements Player using system audio capabilities
@@ -34,36 +37,26 @@ func newSystemPlayer(config *Config) (*SystemPlayer, error) {
 
 // resolveSoundPaths finds the actual file paths for configured sounds
 func (p *SystemPlayer) resolveSoundPaths() error {
+	customDir := utils.ExpandPath(p.config.CustomSoundsDir)
+
 	for soundTypeStr, filename := range p.config.Sounds {
 		soundType := SoundType(soundTypeStr)
 
 		// Try custom sounds directory first
-		customPath := filepath.Join(p.config.CustomSoundsDir, filename)
+		customPath := filepath.Join(customDir, filename)
 		if _, err := os.Stat(customPath); err == nil {
 			p.soundPaths[soundType] = customPath
 			continue
 		}
 
-		// Try to find built-in sounds directory (relative to executable or source)
-		possiblePaths := []string{
-			filepath.Join("internal", "audio", "sounds", filename),
-			filepath.Join("audio", "sounds", filename),
-			filepath.Join("sounds", filename),
-		}
-
-		found := false
-		for _, builtinPath := range possiblePaths {
-			if _, err := os.Stat(builtinPath); err == nil {
-				p.soundPaths[soundType] = builtinPath
-				found = true
-				break
-			}
+		// Fall back to the sounds embedded in the binary
+		if builtinPath, err := builtinSoundPath(filename); err == nil {
+			p.soundPaths[soundType] = builtinPath
+			continue
 		}
 
-		if !found {
-			// Use system beep as fallback
-			p.soundPaths[soundType] = ""
-		}
+		// Use system beep as fallback
+		p.soundPaths[soundType] = ""
 	}
 
 	return nil
@@ -76,6 +69,9 @@ func (p *SystemPlayer) Play(soundType SoundType) error {
 	}
 
 	soundPath, exists := p.soundPaths[soundType]
+	if !exists && strings.HasPrefix(string(soundType), achievementPrefix) {
+		soundPath, exists = p.soundPaths[GoalAchieved]
+	}
 	if !exists {
 		return fmt.Errorf("sound type %s not configured", soundType)
 	}
@@ -0,0 +1,343 @@
+// Package backup bundles the database, configuration, and customizations
+// into a single archive for moving them to another machine
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+// FormatVersion is the archive layout version written by Export. Import
+// accepts archives up to this version.
+const FormatVersion = 1
+
+// Archive entry names
+const (
+	manifestName = "manifest.json"
+	databaseName = "history.db"
+	configName   = "config.yml"
+	soundsDir    = "sounds"
+	hooksDir     = "hooks"
+)
+
+// ErrExists is returned by Import when it would overwrite existing state
+var ErrExists = errors.New("existing data found")
+
+// Manifest describes an archive's contents and the version that wrote it
+type Manifest struct {
+	FormatVersion int       `json:"format_version"`
+	AppVersion    string    `json:"app_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	Files         []string  `json:"files"`
+}
+
+// Locations are where application state lives on this machine
+type Locations struct {
+	Database  string
+	Config    string
+	SoundsDir string
+	HooksDir  string
+}
+
+// Export writes the database, config, custom sounds, and hooks to a
+// gzip-compressed tar archive
+func Export(archivePath string, database *db.InternalDB, loc Locations, appVersion string) (*Manifest, error) {
+	tmpDir, err := os.MkdirTemp("", "pomodoro-export-")
+	if err != nil {
+		return nil, fmt.Errorf("error creating temp dir: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	// Snapshot the database so a running timer cannot leave it half-written
+	snapshot := filepath.Join(tmpDir, databaseName)
+	if err := database.Snapshot(snapshot); err != nil {
+		return nil, err
+	}
+
+	// Collect archive entries: name in archive -> file on disk
+	entries := [][2]string{{databaseName, snapshot}}
+	if fileExists(loc.Config) {
+		entries = append(entries, [2]string{configName, loc.Config})
+	}
+	for _, dir := range []struct{ name, path string }{{soundsDir, loc.SoundsDir}, {hooksDir, loc.HooksDir}} {
+		files, err := regularFiles(dir.path)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			entries = append(entries, [2]string{path.Join(dir.name, f), filepath.Join(dir.path, f)})
+		}
+	}
+
+	manifest := &Manifest{
+		FormatVersion: FormatVersion,
+		AppVersion:    appVersion,
+		CreatedAt:     time.Now(),
+	}
+	for _, e := range entries {
+		manifest.Files = append(manifest.Files, e[0])
+	}
+
+	out, err := os.OpenFile(archivePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600) // #nosec G304 - archive path is chosen by the user
+	if err != nil {
+		return nil, fmt.Errorf("error creating archive: %v", err)
+	}
+	defer func() { _ = out.Close() }()
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling manifest: %v", err)
+	}
+	if err := writeEntry(tw, manifestName, 0644, data); err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if err := addFile(tw, e[0], e[1]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("error writing archive: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("error writing archive: %v", err)
+	}
+	if err := out.Close(); err != nil {
+		return nil, fmt.Errorf("error writing archive: %v", err)
+	}
+
+	return manifest, nil
+}
+
+// Import restores an archive written by Export. Existing files are only
+// replaced when force is set, and are kept alongside as .bak copies. Sounds
+// and hooks go to the directories named in the imported config, if any.
+func Import(archivePath string, loc Locations, force bool) (*Manifest, error) {
+	manifest, files, err := readArchive(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if !force {
+		for _, p := range []string{loc.Database, loc.Config} {
+			if fileExists(p) {
+				return nil, fmt.Errorf("%w at %s", ErrExists, p)
+			}
+		}
+	}
+
+	if data, ok := files[configName]; ok {
+		loc = withConfiguredDirs(loc, data)
+	}
+
+	suffix := ".bak-" + time.Now().Format("20060102-150405")
+	for name, data := range files {
+		var target string
+		var mode os.FileMode = 0600
+		switch {
+		case name == databaseName:
+			target = loc.Database
+			// Stale WAL files from the old database would corrupt the new one
+			for _, ext := range []string{"-wal", "-shm"} {
+				_ = os.Remove(loc.Database + ext)
+			}
+		case name == configName:
+			target = loc.Config
+		case strings.HasPrefix(name, soundsDir+"/"):
+			target = filepath.Join(loc.SoundsDir, path.Base(name))
+		case strings.HasPrefix(name, hooksDir+"/"):
+			target = filepath.Join(loc.HooksDir, path.Base(name))
+			mode = 0700 // hooks are executables
+		default:
+			continue
+		}
+
+		if fileExists(target) {
+			if err := os.Rename(target, target+suffix); err != nil {
+				return nil, fmt.Errorf("error backing up %s: %v", target, err)
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+			return nil, fmt.Errorf("error creating directory for %s: %v", target, err)
+		}
+		if err := os.WriteFile(target, data, mode); err != nil {
+			return nil, fmt.Errorf("error restoring %s: %v", target, err)
+		}
+	}
+
+	return manifest, nil
+}
+
+// readArchive reads and validates an archive, returning its manifest and files
+func readArchive(archivePath string) (*Manifest, map[string][]byte, error) {
+	f, err := os.Open(archivePath) // #nosec G304 - archive path is chosen by the user
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening archive: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("not a pomodoro archive: %v", err)
+	}
+	tr := tar.NewReader(gz)
+
+	var manifest *Manifest
+	files := make(map[string][]byte)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading archive: %v", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Clean(hdr.Name)
+		if !validEntryName(name) {
+			return nil, nil, fmt.Errorf("archive contains unexpected file %q", hdr.Name)
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading %s from archive: %v", name, err)
+		}
+
+		if name == manifestName {
+			manifest = &Manifest{}
+			if err := json.Unmarshal(data, manifest); err != nil {
+				return nil, nil, fmt.Errorf("invalid archive manifest: %v", err)
+			}
+			continue
+		}
+		files[name] = data
+	}
+
+	if manifest == nil {
+		return nil, nil, fmt.Errorf("not a pomodoro archive: missing %s", manifestName)
+	}
+	if manifest.FormatVersion > FormatVersion {
+		return nil, nil, fmt.Errorf("archive format %d was created by pomodoro %s and is newer than this version supports (%d); upgrade pomodoro to import it",
+			manifest.FormatVersion, manifest.AppVersion, FormatVersion)
+	}
+	if _, ok := files[databaseName]; !ok {
+		return nil, nil, fmt.Errorf("archive is missing %s", databaseName)
+	}
+
+	return manifest, files, nil
+}
+
+// validEntryName reports whether an archive entry is one Export writes.
+// This also rejects absolute paths and ../ traversal.
+func validEntryName(name string) bool {
+	switch name {
+	case manifestName, databaseName, configName:
+		return true
+	}
+	dir, file := path.Split(name)
+	return (dir == soundsDir+"/" || dir == hooksDir+"/") && file != "" && file != ".."
+}
+
+// withConfiguredDirs points the sounds and hooks locations at the
+// directories named in the imported config
+func withConfiguredDirs(loc Locations, configData []byte) Locations {
+	var cfg struct {
+		Audio struct {
+			CustomSoundsDir string `yaml:"custom_sounds_dir"`
+		} `yaml:"audio"`
+		Hooks struct {
+			Path string `yaml:"path"`
+		} `yaml:"hooks"`
+	}
+	if err := yaml.Unmarshal(configData, &cfg); err != nil {
+		return loc
+	}
+
+	if cfg.Audio.CustomSoundsDir != "" {
+		loc.SoundsDir = utils.ExpandPath(cfg.Audio.CustomSoundsDir)
+	}
+	if cfg.Hooks.Path != "" {
+		loc.HooksDir = utils.ExpandPath(cfg.Hooks.Path)
+	}
+	return loc
+}
+
+// addFile copies a file from disk into the archive
+func addFile(tw *tar.Writer, name, src string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %v", src, err)
+	}
+	data, err := os.ReadFile(src) // #nosec G304 - files come from the user's own data directories
+	if err != nil {
+		return fmt.Errorf("error reading %s: %v", src, err)
+	}
+	return writeEntry(tw, name, int64(info.Mode().Perm()), data)
+}
+
+// writeEntry writes one regular file to the archive
+func writeEntry(tw *tar.Writer, name string, mode int64, data []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    mode,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("error writing %s to archive: %v", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("error writing %s to archive: %v", name, err)
+	}
+	return nil
+}
+
+// regularFiles lists the regular files directly inside dir; a missing dir has none
+func regularFiles(dir string) ([]string, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", dir, err)
+	}
+
+	var files []string
+	for _, e := range entries {
+		if e.Type().IsRegular() {
+			files = append(files, e.Name())
+		}
+	}
+	return files, nil
+}
+
+// fileExists reports whether path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,159 @@
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	database, err := db.NewDB("")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	start := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	if _, err := database.CreateSession(start, start.Add(25*time.Minute), "Exported", 1500, "", false); err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+
+	src := t.TempDir()
+	loc := Locations{
+		Database:  filepath.Join(src, "history.db"),
+		Config:    filepath.Join(src, "config.yml"),
+		SoundsDir: filepath.Join(src, "sounds"),
+		HooksDir:  filepath.Join(src, "hooks"),
+	}
+	if err := os.WriteFile(loc.Config, []byte("goals:\n  daily_count: 6\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(loc.SoundsDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(loc.SoundsDir, "bell.wav"), []byte("RIFF"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	archive := filepath.Join(t.TempDir(), "state.tar.gz")
+	manifest, err := Export(archive, database, loc, "1.2.3")
+	_ = database.Close()
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if len(manifest.Files) != 3 {
+		t.Errorf("Expected 3 files in manifest, got %v", manifest.Files)
+	}
+
+	dst := t.TempDir()
+	target := Locations{
+		Database:  filepath.Join(dst, "data", "history.db"),
+		Config:    filepath.Join(dst, "config.yml"),
+		SoundsDir: filepath.Join(dst, "sounds"),
+		HooksDir:  filepath.Join(dst, "hooks"),
+	}
+	if _, err := Import(archive, target, false); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(target.SoundsDir, "bell.wav")); err != nil {
+		t.Errorf("Expected sound to be restored: %v", err)
+	}
+
+	// A second import must not silently overwrite
+	if _, err := Import(archive, target, false); !errors.Is(err, ErrExists) {
+		t.Errorf("Expected ErrExists, got %v", err)
+	}
+	if _, err := Import(archive, target, true); err != nil {
+		t.Errorf("Forced import failed: %v", err)
+	}
+}
+
+func TestValidEntryName(t *testing.T) {
+	valid := []string{"manifest.json", "history.db", "sounds/bell.wav", "hooks/session_start"}
+	invalid := []string{"../etc/passwd", "/etc/passwd", "sounds/../../x", "sounds/", "other/file"}
+
+	for _, name := range valid {
+		if !validEntryName(name) {
+			t.Errorf("Expected %q to be valid", name)
+		}
+	}
+	for _, name := range invalid {
+		if validEntryName(path.Clean(name)) {
+			t.Errorf("Expected %q to be rejected", name)
+		}
+	}
+}
+
+func TestDocumentRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	src := t.TempDir()
+	configPath := filepath.Join(src, "config.yml")
+	if err := os.WriteFile(configPath, []byte("goals:\n  daily_count: 6\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	sessions := []db.PomodoroSession{
+		{StartTime: start, EndTime: start.Add(25 * time.Minute), DurationSec: 1500, Description: "Write", TagsCSV: "work,writing", Status: db.StatusCompleted},
+		{StartTime: start.Add(25 * time.Minute), EndTime: start.Add(30 * time.Minute), DurationSec: 300, WasBreak: true, Status: db.StatusCompleted},
+		{StartTime: start.Add(time.Hour), EndTime: start.Add(85 * time.Minute), DurationSec: 1500, Description: "Running"},
+	}
+	doc, err := NewDocument(sessions, configPath, "1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Sessions) != 2 {
+		t.Errorf("Expected the running session to be left out, got %d sessions", len(doc.Sessions))
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, ok, err := ParseDocument(data)
+	if err != nil || !ok {
+		t.Fatalf("Expected the backup to parse, got %v %v", ok, err)
+	}
+	if _, ok, err := ParseDocument([]byte(`{"pomodoros":[]}`)); ok || err != nil {
+		t.Errorf("Expected an OPF document not to be taken for a backup, got %v %v", ok, err)
+	}
+
+	database, err := db.NewDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = database.Close() }()
+	for range 2 {
+		imported, skipped, err := database.ImportSessions(parsed.ImportedSessions())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if imported+skipped != 2 {
+			t.Errorf("Expected 2 sessions, got %d imported and %d skipped", imported, skipped)
+		}
+	}
+	restored, err := database.GetSessionsByDateRange(start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored) != 2 || !restored[0].WasBreak || restored[1].TagsCSV != "work,writing" {
+		t.Errorf("Expected the sessions restored once, got %+v", restored)
+	}
+
+	target := filepath.Join(t.TempDir(), "pomodoro", "config.yml")
+	if ok, err := parsed.RestoreConfig(target, false); !ok || err != nil {
+		t.Fatalf("Expected the config to be restored, got %v %v", ok, err)
+	}
+	if _, err := parsed.RestoreConfig(target, false); !errors.Is(err, ErrExists) {
+		t.Errorf("Expected an existing config to be kept without force, got %v", err)
+	}
+	if got, _ := os.ReadFile(target); string(got) != "goals:\n  daily_count: 6\n" {
+		t.Errorf("Unexpected restored config %q", got)
+	}
+}
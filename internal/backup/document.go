@@ -0,0 +1,145 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+// documentKind marks a JSON backup, so import can tell one from an OPF file
+const documentKind = "pomodoro-backup"
+
+// Document is a backup in plain JSON: every finished session and the config
+// file. Unlike an archive it leaves out pauses, annotations, tasks, sounds,
+// and hooks, but it can be read, diffed, and merged into another history.
+type Document struct {
+	Kind          string            `json:"kind"`
+	FormatVersion int               `json:"format_version"`
+	AppVersion    string            `json:"app_version"`
+	CreatedAt     time.Time         `json:"created_at"`
+	Config        string            `json:"config,omitempty"` // The config file as written, goals included
+	Sessions      []DocumentSession `json:"sessions"`
+}
+
+// DocumentSession is a finished session in a Document
+type DocumentSession struct {
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+	Description  string    `json:"description"`
+	DurationSecs int64     `json:"duration_secs"`
+	Tags         []string  `json:"tags,omitempty"`
+	WasBreak     bool      `json:"was_break"`
+	Status       string    `json:"status"`
+}
+
+// NewDocument builds a backup of sessions and the config file at
+// configPath, which may not exist. Sessions that have not finished are
+// left out.
+func NewDocument(sessions []db.PomodoroSession, configPath, appVersion string) (*Document, error) {
+	doc := &Document{
+		Kind:          documentKind,
+		FormatVersion: FormatVersion,
+		AppVersion:    appVersion,
+		CreatedAt:     time.Now(),
+		Sessions:      []DocumentSession{},
+	}
+	if fileExists(configPath) {
+		data, err := os.ReadFile(configPath) // #nosec G304 - the user's own config file
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", configPath, err)
+		}
+		doc.Config = string(data)
+	}
+
+	for _, s := range sessions {
+		if s.Status == "" {
+			continue
+		}
+		var tags []string
+		if s.TagsCSV != "" {
+			tags = strings.Split(s.TagsCSV, ",")
+		}
+		doc.Sessions = append(doc.Sessions, DocumentSession{
+			Start:        s.StartTime,
+			End:          s.EndTime,
+			Description:  s.Description,
+			DurationSecs: s.DurationSec,
+			Tags:         tags,
+			WasBreak:     s.WasBreak,
+			Status:       s.Status,
+		})
+	}
+	return doc, nil
+}
+
+// ParseDocument reads a JSON backup. It reports false, without an error,
+// when data is not a backup at all, so the caller can try other formats.
+func ParseDocument(data []byte) (*Document, bool, error) {
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil || doc.Kind != documentKind {
+		return nil, false, nil
+	}
+	if doc.FormatVersion > FormatVersion {
+		return nil, true, fmt.Errorf("backup format %d was created by pomodoro %s and is newer than this version supports (%d); upgrade pomodoro to import it",
+			doc.FormatVersion, doc.AppVersion, FormatVersion)
+	}
+	if doc.Config != "" {
+		var check map[string]any
+		if err := yaml.Unmarshal([]byte(doc.Config), &check); err != nil {
+			return nil, true, fmt.Errorf("backup config is not valid YAML: %v", err)
+		}
+	}
+	for i, s := range doc.Sessions {
+		if s.Start.IsZero() || s.DurationSecs <= 0 {
+			return nil, true, fmt.Errorf("backup session %d has no start or duration", i+1)
+		}
+	}
+	return &doc, true, nil
+}
+
+// ImportedSessions returns the document's sessions for db.ImportSessions
+func (d *Document) ImportedSessions() []db.ImportedSession {
+	sessions := make([]db.ImportedSession, 0, len(d.Sessions))
+	for _, s := range d.Sessions {
+		sessions = append(sessions, db.ImportedSession{
+			Start:       s.Start,
+			Duration:    time.Duration(s.DurationSecs) * time.Second,
+			Description: s.Description,
+			TagsCSV:     strings.Join(s.Tags, ","),
+			WasBreak:    s.WasBreak,
+			Status:      s.Status,
+		})
+	}
+	return sessions
+}
+
+// RestoreConfig writes the document's config file to configPath. An
+// existing config is only replaced when force is set, and is kept alongside
+// as a .bak copy. It reports whether the config was written.
+func (d *Document) RestoreConfig(configPath string, force bool) (bool, error) {
+	if d.Config == "" {
+		return false, nil
+	}
+	if fileExists(configPath) {
+		if !force {
+			return false, fmt.Errorf("%w at %s", ErrExists, configPath)
+		}
+		if err := os.Rename(configPath, configPath+".bak-"+time.Now().Format("20060102-150405")); err != nil {
+			return false, fmt.Errorf("error backing up %s: %v", configPath, err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0750); err != nil {
+		return false, fmt.Errorf("error creating directory for %s: %v", configPath, err)
+	}
+	if err := os.WriteFile(configPath, []byte(d.Config), 0600); err != nil {
+		return false, fmt.Errorf("error restoring %s: %v", configPath, err)
+	}
+	return true, nil
+}
@@ -2,35 +2,273 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/ethan-k/pomodoro-cli/internal/audio"
+	"github.com/ethan-k/pomodoro-cli/internal/daemon"
 	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/hooks"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
 	"gopkg.in/yaml.v3"
 )
 
 // Config represents the application configuration
 type Config struct {
-	Goals     GoalConfig     `yaml:"goals"`
-	Hooks     HooksConfig    `yaml:"hooks"`
-	Defaults  DefaultsConfig `yaml:"defaults"`
-	DataPaths DataPaths      `yaml:"paths"`
-	Audio     *audio.Config  `yaml:"audio"`
+	Goals         GoalConfig          `yaml:"goals"`
+	Hooks         HooksConfig         `yaml:"hooks"`
+	Defaults      DefaultsConfig      `yaml:"defaults"`
+	DataPaths     DataPaths           `yaml:"paths"`
+	Audio         *audio.Config       `yaml:"audio"`
+	Achievements  AchievementsConfig  `yaml:"achievements"`
+	Notifications NotificationsConfig `yaml:"notifications"`
+	Display       DisplayConfig       `yaml:"display"`
+	Daemon        DaemonConfig        `yaml:"daemon"`
+	Exports       ExportsConfig       `yaml:"exports"`
+	Idle          IdleConfig          `yaml:"idle"`
+	Tutorial      TutorialConfig      `yaml:"tutorial"`
+	Categories    CategoriesConfig    `yaml:"categories"`
+	Wellness      WellnessConfig      `yaml:"wellness"`
+	Limits        LimitsConfig        `yaml:"limits"`
+	Nudge         NudgeConfig         `yaml:"nudge"`
+	Location      LocationConfig      `yaml:"location"`
+	Serve         ServeConfig         `yaml:"serve"`
+	Secrets       SecretsConfig       `yaml:"secrets"`
+	Debug         DebugConfig         `yaml:"debug"`
+	Templates     map[string]Template `yaml:"templates"`   // Session setups started by name with 'pomodoro template start'
+	Aliases       map[string]string   `yaml:"aliases"`     // Shortcuts for command lines, e.g. w: "start -d 50m -t deep-work"
+	OnStart       []string            `yaml:"on_start"`    // Shell commands run when a pomodoro starts
+	OnComplete    []string            `yaml:"on_complete"` // Shell commands run when a pomodoro runs to its end
+}
+
+// Commands returns the shell commands configured for a hook event, which
+// only the start and completion of a pomodoro have
+func (c *Config) Commands(event string) []string {
+	switch event {
+	case hooks.SessionStart:
+		return c.OnStart
+	case hooks.SessionComplete:
+		return c.OnComplete
+	}
+	return nil
 }
 
 // GoalConfig represents the goals configuration
 type GoalConfig struct {
 	DailyCount  int `yaml:"daily_count"`  // Target number of Pomodoros per day
 	WeeklyCount int `yaml:"weekly_count"` // Target number of Pomodoros per week
+
+	// MonthlyCount is the target number of pomodoros per month; 0 takes the
+	// weekly target over the days the month has
+	MonthlyCount int `yaml:"monthly_count"`
+
+	// Targets for minutes of focus, for sessions of differing lengths; 0 for none
+	DailyMinutes  int `yaml:"daily_minutes"`
+	WeeklyMinutes int `yaml:"weekly_minutes"`
+
+	// CapacityThreshold warns when today's count exceeds this multiple of the
+	// 7-day average (e.g. 1.5 for 150%); 0 disables the warning
+	CapacityThreshold float64 `yaml:"capacity_threshold"`
+
+	// CarryOver moves pomodoros missed in a week into the next week's goal,
+	// and extra pomodoros off it, counting weeks from CarryOverSince
+	// (YYYY-MM-DD)
+	CarryOver      bool   `yaml:"carry_over"`
+	CarryOverSince string `yaml:"carry_over_since"`
+
+	// Tag → targets for pomodoros with that tag, counted alongside the
+	// overall goals, e.g. writing: {daily: 3}
+	Tags map[string]TagGoal `yaml:"tags"`
+
+	// Days of the week off, e.g. [saturday, sunday], that do not break a
+	// streak
+	RestDays []string `yaml:"rest_days"`
+}
+
+// TagGoal is the target number of pomodoros with a tag; 0 for no target
+type TagGoal struct {
+	Daily  int `yaml:"daily"`
+	Weekly int `yaml:"weekly"`
+}
+
+// AchievementsConfig represents the achievement notification configuration
+type AchievementsConfig struct {
+	Enabled bool              `yaml:"enabled"`
+	Audio   bool              `yaml:"audio"`  // Play a sound when an achievement is unlocked
+	Sounds  map[string]string `yaml:"sounds"` // Per-achievement sound files, keyed by achievement ID
+}
+
+// NotificationsConfig represents where notifications are delivered
+type NotificationsConfig struct {
+	Desktop    bool   `yaml:"desktop"`     // Native desktop notifications
+	Terminal   bool   `yaml:"terminal"`    // Bell and message on the terminal
+	WebhookURL string `yaml:"webhook_url"` // POST notifications as JSON to this URL
+	FocusMode  bool   `yaml:"focus_mode"`  // Turn macOS Focus on during pomodoros
+}
+
+// DisplayConfig represents terminal display preferences
+type DisplayConfig struct {
+	Color     string            `yaml:"color"`      // auto, always, never, 16, 256, or truecolor
+	TagColors map[string]string `yaml:"tag_colors"` // Per-tag color overrides (hex or ANSI number)
+}
+
+// DaemonConfig represents the background daemon configuration
+type DaemonConfig struct {
+	LogFile     string `yaml:"log_file"`     // Where the daemon writes its log
+	AutoStart   bool   `yaml:"auto_start"`   // Start a daemon when a session begins and none is running
+	CacheStatus bool   `yaml:"cache_status"` // Answer status polls from memory until the database changes
+}
+
+// ExportsConfig represents the exports of the session history the daemon
+// writes to paths.opf_export on a schedule
+type ExportsConfig struct {
+	Schedule string   `yaml:"schedule"` // off, daily, or weekly
+	At       string   `yaml:"at"`       // Time of day exports run, as HH:MM
+	Formats  []string `yaml:"formats"`  // opf, csv, or backup, which 'pomodoro import' restores
+	Keep     int      `yaml:"keep"`     // Exports of each format kept, 0 for all
+	Notify   string   `yaml:"notify"`   // Notify of exports: always, failure, or never
+	S3       S3Config `yaml:"s3"`
+}
+
+// S3Config represents an S3 bucket, or S3-compatible storage, exports are
+// copied to. The credentials are the s3.access_key_id and
+// s3.secret_access_key secrets, or the AWS_ACCESS_KEY_ID and
+// AWS_SECRET_ACCESS_KEY environment variables.
+type S3Config struct {
+	Bucket   string `yaml:"bucket"`   // Empty to keep exports on this machine only
+	Region   string `yaml:"region"`   // e.g. eu-central-1
+	Endpoint string `yaml:"endpoint"` // For S3-compatible storage, e.g. https://minio.example.com:9000
+	Prefix   string `yaml:"prefix"`   // Prepended to the exports' names, e.g. pomodoro/
+}
+
+// ServeConfig represents the defaults of 'pomodoro serve', which its flags
+// override
+type ServeConfig struct {
+	API         string   `yaml:"api"`          // Address to serve the REST API on, e.g. 127.0.0.1:7070
+	Metrics     string   `yaml:"metrics"`      // Address to serve Prometheus metrics on
+	BasePath    string   `yaml:"base_path"`    // Path prefix to serve under behind a reverse proxy, e.g. /pomodoro
+	TLSCert     string   `yaml:"tls_cert"`     // Certificate file to serve HTTPS with
+	TLSKey      string   `yaml:"tls_key"`      // Key file of the certificate
+	CORSOrigins []string `yaml:"cors_origins"` // Web origins allowed to call the API, e.g. https://dash.example.com
+	RateLimit   int      `yaml:"rate_limit"`   // API requests each client may make a minute, 0 for no limit
+}
+
+// SecretsConfig represents where 'pomodoro secrets' keeps integration
+// credentials
+type SecretsConfig struct {
+	Backend string `yaml:"backend"` // auto, keyring, or file
+	File    string `yaml:"file"`    // Encrypted file used without a keychain
+}
+
+// DebugConfig represents the diagnostics kept for reporting problems
+type DebugConfig struct {
+	SlowThreshold string `yaml:"slow_threshold"` // Log invocations taking longer than this, e.g. 200ms; empty for none
+	SlowLog       string `yaml:"slow_log"`       // Where slow invocations are logged
+}
+
+// DefaultServeRateLimit is how many API requests a minute each client may
+// make unless serve.rate_limit says otherwise
+const DefaultServeRateLimit = 120
+
+// IdleConfig represents how time away from the machine is recorded
+type IdleConfig struct {
+	LockBreak      bool   `yaml:"lock_break"`       // Record screen locks as breaks (requires the daemon)
+	LockBreakAfter string `yaml:"lock_break_after"` // Minimum lock duration recorded as a break
+	AutoPause      bool   `yaml:"auto_pause"`       // Pause a pomodoro when there is no input for a while (requires the daemon)
+	AutoPauseAfter string `yaml:"auto_pause_after"` // How long without input pauses the pomodoro
+	AutoResume     bool   `yaml:"auto_resume"`      // Resume when input returns, rather than ask
+	OnSleep        string `yaml:"on_sleep"`         // What the timer does with a pomodoro the machine slept through: pause, interrupt, or ignore
+}
+
+// TutorialConfig records the tutorial's progress, so it is suggested once
+type TutorialConfig struct {
+	Completed bool `yaml:"completed"` // The tutorial was finished
+	Suggested bool `yaml:"suggested"` // A new user was pointed to the tutorial
+}
+
+// CategoriesConfig represents the session category taxonomy
+type CategoriesConfig struct {
+	Names []string          `yaml:"names"` // Categories a session can belong to
+	Deep  []string          `yaml:"deep"`  // Categories that count as deep work
+	Tags  map[string]string `yaml:"tags"`  // Tag → category, for sessions started without --category
+}
+
+// WellnessConfig represents the habit counters tracked alongside sessions
+type WellnessConfig struct {
+	Counters []CounterConfig `yaml:"counters"` // In the order break keys 1-9 log them
+	// Share of expected breaks skipped above which history and stats nudge
+	// you to take them; 0 turns the nudge off
+	BreakSkipThreshold float64 `yaml:"break_skip_threshold"`
+	// Ask for a 1-5 focus quality rating when a pomodoro finishes in the
+	// timer
+	RateQuality bool `yaml:"rate_quality"`
+	// What a break does to the screen as it starts, for those who ignore
+	// reminders: lock, dim (put the display to sleep), or empty for nothing
+	ForceBreak string `yaml:"force_break"`
+}
+
+// CounterConfig is a habit counted through the day, like glasses of water
+type CounterConfig struct {
+	Name string `yaml:"name"`
+	Goal int    `yaml:"goal"` // Daily target, 0 for none
+}
+
+// Counter returns the counter with a name, if configured
+func (w WellnessConfig) Counter(name string) (CounterConfig, bool) {
+	for _, c := range w.Counters {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return CounterConfig{}, false
+}
+
+// Template is a named pomodoro setup
+type Template struct {
+	Description string   `yaml:"description"` // Defaults to the template's name
+	Duration    string   `yaml:"duration"`    // e.g. 50m; empty for the length start would choose
+	Tags        []string `yaml:"tags"`
+}
+
+// LimitsConfig caps a day's work
+type LimitsConfig struct {
+	DailyMaxPomodoros int    `yaml:"daily_max_pomodoros"` // 0 for no cap
+	LatestStartTime   string `yaml:"latest_start_time"`   // HH:MM after which no pomodoro starts; empty for none
+	Refuse            bool   `yaml:"refuse"`              // Refuse to start past the limits, rather than warn
+}
+
+// NudgeConfig reminds you to start a pomodoro on days with none yet
+type NudgeConfig struct {
+	Enabled bool     `yaml:"enabled"` // Nudge when no pomodoro has started by At (requires the daemon)
+	At      string   `yaml:"at"`      // HH:MM by which the day's first pomodoro should have started
+	Days    []string `yaml:"days"`    // Days to nudge on, e.g. mon or monday; empty for every day
+}
+
+// LocationConfig records where pomodoros run, from the Wi-Fi network
+type LocationConfig struct {
+	Record     bool              `yaml:"record"`     // Record a location with each pomodoro started
+	Networks   map[string]string `yaml:"networks"`   // Wi-Fi network name → location label, e.g. CorpWiFi: office
+	Unlabelled bool              `yaml:"unlabelled"` // Record the names of networks without a label too
 }
 
 // HooksConfig represents the hooks configuration
 type HooksConfig struct {
 	Enabled bool   `yaml:"enabled"`
-	Path    string `yaml:"path"` // Path to hooks directory
+	Path    string `yaml:"path"`    // Path to hooks directory
+	Timeout string `yaml:"timeout"` // How long a hook may run before it is killed
+
+	// Permissions holds each hook to what it may do: network, notify, and
+	// modify_sessions, keyed by its path within Path (session_start.sh,
+	// session_complete.d/10-sync) or by on_start and on_complete. Hooks
+	// without an entry may do anything unless RequirePermissions is set.
+	Permissions        map[string][]string `yaml:"permissions"`
+	RequirePermissions bool                `yaml:"require_permissions"` // Refuse to run hooks without an entry in Permissions
 }
 
 // DefaultsConfig represents default values
@@ -38,6 +276,27 @@ type DefaultsConfig struct {
 	PomodoroDuration  string `yaml:"pomodoro_duration"`
 	BreakDuration     string `yaml:"break_duration"`
 	LongBreakDuration string `yaml:"long_break_duration"`
+	LongBreakInterval int    `yaml:"long_break_interval"` // Completed pomodoros before a long break; 0 turns automatic long breaks off
+	Warmup            string `yaml:"warmup"`              // Countdown before each pomodoro started with a timer; empty or 0 for none
+
+	// Tag → pomodoro duration, used when start is given a tag but no --duration
+	TagDurations map[string]string `yaml:"tag_durations"`
+}
+
+// TagDuration returns the pomodoro duration set for the first of tags that
+// has one in TagDurations. Durations that cannot be parsed are passed over.
+func (d DefaultsConfig) TagDuration(tags []string) (tag string, duration time.Duration, ok bool) {
+	for _, tag := range tags {
+		for rule, value := range d.TagDurations {
+			if !strings.EqualFold(rule, tag) {
+				continue
+			}
+			if duration, err := utils.ParseHumanDuration(value); err == nil && duration > 0 {
+				return tag, duration, true
+			}
+		}
+	}
+	return "", 0, false
 }
 
 // DataPaths represents paths for data storage
@@ -55,34 +314,105 @@ func DefaultConfig() *Config {
 
 	return &Config{
 		Goals: GoalConfig{
-			DailyCount:  8,
-			WeeklyCount: 40,
+			DailyCount:        8,
+			WeeklyCount:       40,
+			CapacityThreshold: 1.5,
 		},
 		Hooks: HooksConfig{
 			Enabled: false,
 			Path:    filepath.Join(home, ".config", "pomodoro", "hooks"),
+			Timeout: "10s",
 		},
 		Defaults: DefaultsConfig{
 			PomodoroDuration:  "25m",
 			BreakDuration:     "5m",
 			LongBreakDuration: "15m",
+			LongBreakInterval: 4,
 		},
 		DataPaths: DataPaths{
 			Database:  filepath.Join(home, ".local", "share", "pomodoro", "history.db"),
 			OPFExport: filepath.Join(home, ".local", "share", "pomodoro", "exports"),
 		},
 		Audio: audio.DefaultConfig(),
+		Achievements: AchievementsConfig{
+			Enabled: true,
+			Audio:   true,
+			Sounds:  map[string]string{},
+		},
+		Notifications: NotificationsConfig{
+			Desktop: true,
+		},
+		Display: DisplayConfig{
+			Color:     "auto",
+			TagColors: map[string]string{},
+		},
+		Daemon: DaemonConfig{
+			LogFile:     daemon.DefaultLogFile(),
+			AutoStart:   true,
+			CacheStatus: true,
+		},
+		Idle: IdleConfig{
+			LockBreak:      false,
+			LockBreakAfter: "5m",
+			AutoPauseAfter: "5m",
+			OnSleep:        "pause",
+		},
+		Nudge: NudgeConfig{
+			Enabled: true,
+			At:      "10:00",
+			Days:    []string{"mon", "tue", "wed", "thu", "fri"},
+		},
+		Location: LocationConfig{
+			Networks: map[string]string{},
+		},
+		Serve: ServeConfig{
+			RateLimit: DefaultServeRateLimit,
+		},
+		Exports: ExportsConfig{
+			Schedule: "off",
+			At:       "02:00",
+			Formats:  []string{"opf"},
+			Keep:     14,
+			Notify:   "failure",
+			S3:       S3Config{Region: "us-east-1"},
+		},
+		Secrets: SecretsConfig{
+			Backend: "auto",
+			File:    filepath.Join(home, ".local", "share", "pomodoro", "secrets.enc"),
+		},
+		Debug: DebugConfig{
+			SlowLog: filepath.Join(home, ".local", "share", "pomodoro", "slow.log"),
+		},
+		Categories: CategoriesConfig{
+			Names: []string{"deep", "shallow", "admin"},
+			Deep:  []string{"deep"},
+			Tags:  map[string]string{},
+		},
+		Wellness: WellnessConfig{
+			Counters: []CounterConfig{
+				{Name: "water", Goal: 8},
+				{Name: "stretch", Goal: 4},
+			},
+			BreakSkipThreshold: 0.5,
+		},
 	}
 }
 
-// LoadConfig loads the configuration from the default path
-func LoadConfig() (*Config, error) {
+// Path returns the location of the config file
+func Path() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
-		return nil, fmt.Errorf("error getting home dir: %v", err)
+		return "", fmt.Errorf("error getting home dir: %v", err)
 	}
+	return filepath.Join(home, ".config", "pomodoro", "config.yml"), nil
+}
 
-	configPath := filepath.Join(home, ".config", "pomodoro", "config.yml")
+// LoadConfig loads the configuration from the default path
+func LoadConfig() (*Config, error) {
+	configPath, err := Path()
+	if err != nil {
+		return nil, err
+	}
 
 	// If config file doesn't exist, return default config
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
@@ -111,18 +441,14 @@ func LoadConfig() (*Config, error) {
 
 // SaveConfig saves the configuration to the default path
 func SaveConfig(config *Config) error {
-	home, err := os.UserHomeDir()
+	configPath, err := Path()
 	if err != nil {
-		return fmt.Errorf("error getting home dir: %v", err)
+		return err
 	}
-
-	configDir := filepath.Join(home, ".config", "pomodoro")
-	if err := os.MkdirAll(configDir, 0750); err != nil {
+	if err := os.MkdirAll(filepath.Dir(configPath), 0750); err != nil {
 		return fmt.Errorf("error creating config directory: %v", err)
 	}
 
-	configPath := filepath.Join(configDir, "config.yml")
-
 	// Marshal config to YAML
 	data, err := yaml.Marshal(config)
 	if err != nil {
@@ -137,23 +463,13 @@ func SaveConfig(config *Config) error {
 	return nil
 }
 
-// GetCurrentGoalStatus returns the current goal status
-func GetCurrentGoalStatus() (*GoalStatus, error) {
+// GetCurrentGoalStatus returns the current goal status for the sessions in database
+func GetCurrentGoalStatus(database db.DB) (*GoalStatus, error) {
 	config, err := LoadConfig()
 	if err != nil {
 		return nil, err
 	}
 
-	database, err := db.NewDB()
-	if err != nil {
-		return nil, err
-	}
-	defer func() {
-		if err := database.Close(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error closing database: %v\n", err)
-		}
-	}()
-
 	// Get today's sessions
 	today := time.Now().Truncate(24 * time.Hour)
 	tomorrow := today.Add(24 * time.Hour)
@@ -176,27 +492,102 @@ func GetCurrentGoalStatus() (*GoalStatus, error) {
 	if err != nil {
 		return nil, err
 	}
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	monthSessions, err := database.GetSessionsByDateRange(monthStart, now)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &GoalStatus{
+		DailyMinutesGoal:  config.Goals.DailyMinutes,
+		DailyFocus:        focusTime(todaySessions, now),
+		WeeklyMinutesGoal: config.Goals.WeeklyMinutes,
+		WeeklyFocus:       focusTime(weekSessions, now),
+	}
+
+	// Count pomodoros that were not cancelled or abandoned
+	todaySessions = countedSessions(todaySessions)
+	weekSessions = countedSessions(weekSessions)
+	status.DailyGoal, status.DailyCompleted = config.Goals.DailyCount, len(todaySessions)
+	status.WeeklyGoal, status.WeeklyCompleted = config.Goals.WeeklyCount, len(weekSessions)
+	status.MonthlyGoal, status.MonthlyCompleted = config.Goals.MonthlyGoal(now), len(countedSessions(monthSessions))
+	status.MonthDaysLeft = daysInMonth(now) - now.Day() + 1
+	status.Tags = GetTagGoalProgress(config.Goals.Tags, todaySessions, weekSessions)
+	return status, nil
+}
+
+// MonthlyGoal returns the target number of pomodoros for the month of t:
+// MonthlyCount when set, otherwise the weekly target spread over the days
+// of that month, so a 31-day month asks for more than a 28-day one
+func (g GoalConfig) MonthlyGoal(t time.Time) int {
+	if g.MonthlyCount > 0 {
+		return g.MonthlyCount
+	}
+	return int(math.Round(float64(max(g.WeeklyCount, 0)) * float64(daysInMonth(t)) / 7))
+}
+
+// daysInMonth returns the number of days in the month of t
+func daysInMonth(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+}
 
-	// Count non-break sessions
-	dailyCount := 0
-	weeklyCount := 0
-	for _, session := range todaySessions {
+// focusTime totals the time spent focused in pomodoros up to now, less
+// pauses. Cancelled pomodoros and the one running count for the time they
+// ran, since minutes goals measure effort.
+func focusTime(sessions []db.PomodoroSession, now time.Time) time.Duration {
+	var focus time.Duration
+	for _, session := range sessions {
 		if !session.WasBreak {
-			dailyCount++
+			focus += session.EffectiveFocus(now)
 		}
 	}
-	for _, session := range weekSessions {
-		if !session.WasBreak {
-			weeklyCount++
+	return focus
+}
+
+// countedSessions returns the sessions that count toward goals: pomodoros
+// that were not cancelled or abandoned
+func countedSessions(sessions []db.PomodoroSession) []db.PomodoroSession {
+	var counted []db.PomodoroSession
+	for _, session := range sessions {
+		if !session.WasBreak && !session.Incomplete() {
+			counted = append(counted, session)
 		}
 	}
+	return counted
+}
 
-	return &GoalStatus{
-		DailyGoal:       config.Goals.DailyCount,
-		DailyCompleted:  dailyCount,
-		WeeklyGoal:      config.Goals.WeeklyCount,
-		WeeklyCompleted: weeklyCount,
-	}, nil
+// GetTagGoalProgress counts today's and this week's pomodoros toward each
+// tag goal, in order of tag. Tags are matched ignoring case, and goals
+// without a target are left out.
+func GetTagGoalProgress(goals map[string]TagGoal, today, week []db.PomodoroSession) []TagGoalStatus {
+	var progress []TagGoalStatus
+	for tag, goal := range goals {
+		if goal.Daily <= 0 && goal.Weekly <= 0 {
+			continue
+		}
+		progress = append(progress, TagGoalStatus{
+			Tag:             strings.ToLower(tag),
+			DailyGoal:       max(goal.Daily, 0),
+			DailyCompleted:  countTagged(today, tag),
+			WeeklyGoal:      max(goal.Weekly, 0),
+			WeeklyCompleted: countTagged(week, tag),
+		})
+	}
+	slices.SortFunc(progress, func(a, b TagGoalStatus) int { return strings.Compare(a.Tag, b.Tag) })
+	return progress
+}
+
+// countTagged counts the sessions that have tag
+func countTagged(sessions []db.PomodoroSession, tag string) int {
+	count := 0
+	for _, session := range sessions {
+		if slices.ContainsFunc(strings.Split(session.TagsCSV, ","), func(t string) bool {
+			return strings.EqualFold(strings.TrimSpace(t), tag)
+		}) {
+			count++
+		}
+	}
+	return count
 }
 
 // GoalStatus represents the current goal status
@@ -205,4 +596,116 @@ type GoalStatus struct {
 	DailyCompleted  int
 	WeeklyGoal      int
 	WeeklyCompleted int
+	Tags            []TagGoalStatus // Progress toward each tag goal, in order of tag
+
+	MonthlyGoal      int
+	MonthlyCompleted int
+	MonthDaysLeft    int // Days left in the month, counting today
+
+	// Minutes of focus aimed for, 0 for no goal, and the focus time so far
+	DailyMinutesGoal  int
+	DailyFocus        time.Duration
+	WeeklyMinutesGoal int
+	WeeklyFocus       time.Duration
+}
+
+// MonthlyPerDay returns how many pomodoros a day, today included, are still
+// needed to reach the monthly goal; 0 once it is reached
+func (s GoalStatus) MonthlyPerDay() float64 {
+	left := s.MonthlyGoal - s.MonthlyCompleted
+	if left <= 0 || s.MonthDaysLeft <= 0 {
+		return 0
+	}
+	return float64(left) / float64(s.MonthDaysLeft)
+}
+
+// TagGoalStatus is the progress toward the goals for one tag
+type TagGoalStatus struct {
+	Tag             string
+	DailyGoal       int
+	DailyCompleted  int
+	WeeklyGoal      int
+	WeeklyCompleted int
+}
+
+// KeySource is where a config key's value comes from
+type KeySource struct {
+	File    string // Value in the config file, when InFile
+	InFile  bool   // The config file sets the key
+	Default string // Built-in value, empty when there is none
+}
+
+// LookupKey reports the value a dotted config key (e.g. goals.daily_count)
+// has in the config file, if the file sets it, and by default. Lists and
+// maps are given as JSON.
+func LookupKey(key string) (KeySource, error) {
+	defaults, err := toMap(DefaultConfig())
+	if err != nil {
+		return KeySource{}, err
+	}
+	builtin, known := lookupPath(defaults, key)
+	if !known {
+		return KeySource{}, fmt.Errorf("unknown configuration key: %s", key)
+	}
+	source := KeySource{Default: formatValue(builtin)}
+
+	path, err := Path()
+	if err != nil {
+		return source, err
+	}
+	data, err := os.ReadFile(path) // #nosec G304 - path is constructed from trusted sources
+	if os.IsNotExist(err) {
+		return source, nil
+	}
+	if err != nil {
+		return source, fmt.Errorf("error reading config file: %v", err)
+	}
+	var file map[string]any
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return source, fmt.Errorf("error parsing config file: %v", err)
+	}
+	if value, ok := lookupPath(file, key); ok {
+		source.File, source.InFile = formatValue(value), true
+	}
+	return source, nil
+}
+
+// toMap converts a value to the generic form YAML decodes into
+func toMap(v any) (map[string]any, error) {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	return m, yaml.Unmarshal(data, &m)
+}
+
+// lookupPath finds a dotted key in decoded YAML
+func lookupPath(m map[string]any, key string) (any, bool) {
+	var value any = m
+	for _, part := range strings.Split(key, ".") {
+		node, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = node[part]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// formatValue formats a decoded YAML value, giving lists and maps as JSON
+func formatValue(v any) string {
+	switch v.(type) {
+	case nil:
+		return ""
+	case map[string]any, []any:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+	return fmt.Sprint(v)
 }
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay lets a burst of writes settle before the config is reloaded.
+// Editors often save by writing a temporary file and renaming it over the
+// original, which shows up as several events.
+const reloadDelay = 200 * time.Millisecond
+
+// Watch calls onChange with the reloaded config each time the config file
+// changes, until ctx is cancelled. A file that fails to parse is reported
+// through err so callers can keep their current settings.
+func Watch(ctx context.Context, onChange func(cfg *Config, err error)) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("error creating config watcher: %v", err)
+	}
+	defer func() { _ = watcher.Close() }()
+
+	// Watch the directory, since renaming a new file into place replaces
+	// the one a file watch would follow
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("error creating config directory: %v", err)
+	}
+	if err := watcher.Add(dir); err != nil {
+		return fmt.Errorf("error watching config directory: %v", err)
+	}
+
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) == path && !event.Has(fsnotify.Chmod) {
+				timer.Reset(reloadDelay)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			onChange(nil, fmt.Errorf("error watching config file: %v", err))
+		case <-timer.C:
+			onChange(LoadConfig())
+		}
+	}
+}
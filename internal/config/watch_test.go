@@ -0,0 +1,63 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchReloadsChangedConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, ".config", "pomodoro", "config.yml")
+
+	type result struct {
+		cfg *Config
+		err error
+	}
+	results := make(chan result, 4)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = Watch(ctx, func(cfg *Config, err error) { results <- result{cfg, err} })
+	}()
+
+	// Rewrite until the watcher, which starts asynchronously, sees a change
+	write := func(content string) result {
+		t.Helper()
+		for attempt := 0; attempt < 20; attempt++ {
+			if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			select {
+			case r := <-results:
+				return r
+			case <-time.After(500 * time.Millisecond):
+			}
+		}
+		t.Fatal("Timed out waiting for a reload")
+		return result{}
+	}
+
+	r := write("goals:\n  daily_count: 12\n")
+	if r.err != nil || r.cfg.Goals.DailyCount != 12 {
+		t.Errorf("Expected the new daily goal to be loaded, got %+v, %v", r.cfg, r.err)
+	}
+
+	// Drop reloads left over from retried writes
+	time.Sleep(2 * reloadDelay)
+	for len(results) > 0 {
+		<-results
+	}
+
+	if r := write("goals: [broken"); r.err == nil {
+		t.Error("Expected a parse error for a broken config file")
+	}
+}
@@ -0,0 +1,308 @@
+// Package csvimport reads sessions from a CSV time log, such as one kept in
+// a spreadsheet, for import
+package csvimport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+// MaxImportSize bounds the CSV files Parse accepts
+const MaxImportSize = 64 << 20
+
+// Fields a column can hold. The header names each column's field; other
+// columns are ignored.
+const (
+	FieldDate        = "date"        // YYYY-MM-DD the session started on
+	FieldStart       = "start"       // HH:MM with a date column, else "YYYY-MM-DD HH:MM" or RFC 3339
+	FieldEnd         = "end"         // Like start; an end before the start is taken as the next day
+	FieldDuration    = "duration"    // e.g. 25m, 1h30, or a bare number of minutes
+	FieldDescription = "description" // Optional
+	FieldTags        = "tags"        // Separated by commas, semicolons, or spaces
+	FieldType        = "type"        // pomodoro (the default) or break
+	FieldStatus      = "status"      // completed (the default), cancelled, or abandoned
+)
+
+// Fields lists every field, in the order they are documented
+var Fields = []string{FieldDate, FieldStart, FieldEnd, FieldDuration, FieldDescription, FieldTags, FieldType, FieldStatus}
+
+// RowError is why a row of the file could not be imported
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Errors are the rows of a file that could not be imported
+type Errors []RowError
+
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more invalid rows)", e[0].Error(), len(e)-1)
+}
+
+// ParseColumns parses a column mapping given as "Header=field" pairs
+// separated by commas, as in "Task=description,Minutes=duration". Headers
+// are matched ignoring case.
+func ParseColumns(s string) (map[string]string, error) {
+	columns := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		header, field, ok := strings.Cut(pair, "=")
+		header = strings.ToLower(strings.TrimSpace(header))
+		field = strings.ToLower(strings.TrimSpace(field))
+		if !ok || header == "" {
+			return nil, fmt.Errorf("invalid column mapping %q: use Header=field", pair)
+		}
+		if !slices.Contains(Fields, field) {
+			return nil, fmt.Errorf("invalid column mapping %q: field must be one of %s", pair, strings.Join(Fields, ", "))
+		}
+		columns[header] = field
+	}
+	return columns, nil
+}
+
+// Parse reads the sessions in a CSV file whose first row names the columns.
+// A header is read as the field columns maps it to, or else as the field of
+// that name, ignoring case. The file needs a start column, and an end or
+// duration column. Times without an offset are in loc, and no session may
+// start after now.
+//
+// Every row is checked: when any is invalid, Parse returns Errors listing
+// each one, and no sessions. Descriptions and tags are checked like those
+// typed on the command line.
+func Parse(data []byte, columns map[string]string, loc *time.Location, now time.Time) ([]db.ImportedSession, error) {
+	if len(data) > MaxImportSize {
+		return nil, fmt.Errorf("CSV file is larger than %d MB", MaxImportSize>>20)
+	}
+	data = bytes.TrimPrefix(data, []byte("\ufeff")) // Spreadsheets may start with a byte order mark
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = delimiter(data)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("CSV file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV header: %v", err)
+	}
+	index := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		field, ok := columns[name]
+		if !ok && slices.Contains(Fields, name) {
+			field = name
+		}
+		if field == "" {
+			continue
+		}
+		if _, dup := index[field]; dup {
+			return nil, fmt.Errorf("more than one column holds the %s", field)
+		}
+		index[field] = i
+	}
+	_, hasEnd := index[FieldEnd]
+	_, hasDuration := index[FieldDuration]
+	if _, ok := index[FieldStart]; !ok || !hasEnd && !hasDuration {
+		return nil, fmt.Errorf("the header needs a %s column and an %s or %s column (see --columns to map others)", FieldStart, FieldEnd, FieldDuration)
+	}
+
+	var sessions []db.ImportedSession
+	var invalid Errors
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, append(invalid, RowError{parseErr.Line, parseErr.Err})
+			}
+			return nil, fmt.Errorf("error reading CSV file: %v", err)
+		}
+		line, _ := r.FieldPos(0)
+
+		row := row{record: record, index: index}
+		if row.blank() {
+			continue
+		}
+		session, err := row.session(loc, now)
+		if err != nil {
+			invalid = append(invalid, RowError{line, err})
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	if len(invalid) > 0 {
+		return nil, invalid
+	}
+	return sessions, nil
+}
+
+// delimiter guesses the separator from the header: a comma unless it has
+// more semicolons or tabs, as some spreadsheets write
+func delimiter(data []byte) rune {
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+	best, count := ',', bytes.Count(header, []byte(","))
+	for _, r := range []rune{';', '\t'} {
+		if n := bytes.Count(header, []byte(string(r))); n > count {
+			best, count = r, n
+		}
+	}
+	return best
+}
+
+// row is a record of the file with the index of each field's column
+type row struct {
+	record []string
+	index  map[string]int
+}
+
+// get returns the value of field in the row, "" when there is none
+func (r row) get(field string) string {
+	i, ok := r.index[field]
+	if !ok || i >= len(r.record) {
+		return ""
+	}
+	return strings.TrimSpace(r.record[i])
+}
+
+// blank reports whether the row has no values, as spreadsheets leave rows
+func (r row) blank() bool {
+	for _, v := range r.record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// session validates the row and returns the session it records
+func (r row) session(loc *time.Location, now time.Time) (db.ImportedSession, error) {
+	if r.get(FieldEnd) == "" && r.get(FieldDuration) == "" {
+		return db.ImportedSession{}, fmt.Errorf("no %s or %s", FieldEnd, FieldDuration)
+	}
+	start, err := r.time(FieldStart, time.Time{}, loc)
+	if err != nil {
+		return db.ImportedSession{}, err
+	}
+	if start.After(now) {
+		return db.ImportedSession{}, fmt.Errorf("start %s is in the future", start.Format("2006-01-02 15:04"))
+	}
+
+	var length time.Duration
+	if end := r.get(FieldEnd); end != "" {
+		endTime, err := r.time(FieldEnd, start, loc)
+		if err != nil {
+			return db.ImportedSession{}, err
+		}
+		length = endTime.Sub(start)
+	}
+	if d := r.get(FieldDuration); d != "" {
+		given, err := utils.ParseHumanDuration(d)
+		if err != nil {
+			return db.ImportedSession{}, err
+		}
+		if length != 0 && (given-length).Abs() >= time.Minute {
+			return db.ImportedSession{}, fmt.Errorf("duration %s does not match the %s from start to end", given, length)
+		}
+		length = given
+	}
+	if err := utils.ValidateDuration(length); err != nil {
+		return db.ImportedSession{}, fmt.Errorf("invalid duration %s: %v", length, err)
+	}
+
+	session := db.ImportedSession{Start: start, Duration: length}
+	switch kind := strings.ToLower(r.get(FieldType)); kind {
+	case "", "pomodoro":
+	case "break":
+		session.WasBreak = true
+	default:
+		return db.ImportedSession{}, fmt.Errorf("invalid type %.20q: must be pomodoro or break", kind)
+	}
+	switch status := strings.ToLower(r.get(FieldStatus)); status {
+	case "", db.StatusCompleted, db.StatusCancelled, db.StatusAbandoned:
+		session.Status = status
+	default:
+		return db.ImportedSession{}, fmt.Errorf("invalid status %.20q: must be %s, %s, or %s", status, db.StatusCompleted, db.StatusCancelled, db.StatusAbandoned)
+	}
+
+	session.Description = utils.SanitizeDescription(r.get(FieldDescription))
+	if err := utils.ValidateDescription(session.Description, false); err != nil {
+		return db.ImportedSession{}, err
+	}
+	tags := utils.SanitizeTags(strings.FieldsFunc(r.get(FieldTags), func(r rune) bool {
+		return r == ',' || r == ';' || unicode.IsSpace(r)
+	}))
+	if err := utils.ValidateTags(tags); err != nil {
+		return db.ImportedSession{}, err
+	}
+	session.TagsCSV = strings.Join(tags, ",")
+	return session, nil
+}
+
+// time parses the start, or the end when start is given. A time of day alone
+// is on the day in the date column for the start, and on the start's day
+// for the end, or the next day when the end would otherwise come first.
+func (r row) time(field string, start time.Time, loc *time.Location) (time.Time, error) {
+	value := r.get(field)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("no %s", field)
+	}
+
+	day := start
+	if start.IsZero() {
+		if date := r.get(FieldDate); date != "" {
+			parsed, err := time.ParseInLocation("2006-01-02", date, loc)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid date %.20q: use YYYY-MM-DD", date)
+			}
+			day = parsed
+		} else if clockTime(value) {
+			return time.Time{}, fmt.Errorf("%s %.20q has no date: add a date column or give YYYY-MM-DD HH:MM", field, value)
+		} else {
+			// Only the location matters for a time with a date
+			day = time.Date(2000, 1, 1, 0, 0, 0, 0, loc)
+		}
+	}
+
+	t, err := utils.ParseDateTime(value, day)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !start.IsZero() && clockTime(value) && !t.After(start) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// clockTime reports whether s is a time of day without a date
+func clockTime(s string) bool {
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
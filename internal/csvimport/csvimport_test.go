@@ -0,0 +1,106 @@
+package csvimport
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func TestParse(t *testing.T) {
+	loc := time.FixedZone("CEST", 2*3600)
+	now := time.Date(2024, 7, 1, 12, 0, 0, 0, loc)
+	data := "\ufeffDate,Start,End,Duration,Task,Tags,Type,Notes\n" +
+		"2024-06-01,09:00,09:25,,\"Write  report\",\"Work; writing\",,first\n" +
+		"2024-06-01,09:25,,5,,,break,\n" +
+		",,,,,,,\n" +
+		"2024-06-01,23:50,00:15,25m,Late,,,\n" +
+		"2024-06-02,2024-06-02T08:00:00Z,,1h30,Early,,,\n"
+	sessions, err := Parse([]byte(data), map[string]string{"task": "description"}, loc, now)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	want := []db.ImportedSession{
+		{Start: time.Date(2024, 6, 1, 9, 0, 0, 0, loc), Duration: 25 * time.Minute, Description: "Write report", TagsCSV: "work,writing"},
+		{Start: time.Date(2024, 6, 1, 9, 25, 0, 0, loc), Duration: 5 * time.Minute, WasBreak: true},
+		{Start: time.Date(2024, 6, 1, 23, 50, 0, 0, loc), Duration: 25 * time.Minute, Description: "Late"},
+		{Start: time.Date(2024, 6, 2, 8, 0, 0, 0, time.UTC), Duration: 90 * time.Minute, Description: "Early"},
+	}
+	if len(sessions) != len(want) {
+		t.Fatalf("Expected %d sessions, got %+v", len(want), sessions)
+	}
+	for i, w := range want {
+		s := sessions[i]
+		if !s.Start.Equal(w.Start) || s.Duration != w.Duration || s.Description != w.Description || s.TagsCSV != w.TagsCSV || s.WasBreak != w.WasBreak {
+			t.Errorf("Session %d = %+v, want %+v", i, s, w)
+		}
+	}
+
+	semicolons := "start;duration;description\n2024-06-01 10:00;25;Plan\n"
+	if sessions, err := Parse([]byte(semicolons), nil, loc, now); err != nil || len(sessions) != 1 || sessions[0].Description != "Plan" {
+		t.Errorf("Expected a semicolon-separated file to be read, got %+v, %v", sessions, err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	loc := time.UTC
+	now := time.Date(2024, 7, 1, 12, 0, 0, 0, loc)
+
+	for _, data := range []string{
+		"",
+		"date,description\n2024-06-01,Plan\n",
+		"start,end,end\n",
+	} {
+		if _, err := Parse([]byte(data), nil, loc, now); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", data)
+		}
+	}
+
+	data := "date,start,end,duration,type\n" +
+		"2024-06-01,09:00,09:25,,\n" + // Line 2 is valid
+		"2024-06-01,9 sharp,,25m,\n" +
+		",09:00,,25m,\n" +
+		"2024-06-01,09:00,09:25,50m,\n" +
+		"2024-06-01,09:00,,25m,nap\n" +
+		"2024-08-01,09:00,,25m,\n" +
+		"2024-06-01,09:00,,,\n"
+	sessions, err := Parse([]byte(data), nil, loc, now)
+	var rows Errors
+	if !errors.As(err, &rows) || sessions != nil {
+		t.Fatalf("Expected row errors and no sessions, got %+v, %v", sessions, err)
+	}
+	want := []struct {
+		line int
+		msg  string
+	}{
+		{3, "invalid time"},
+		{4, "has no date"},
+		{5, "does not match"},
+		{6, "invalid type"},
+		{7, "in the future"},
+		{8, "no end or duration"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("Expected %d row errors, got %v", len(want), rows)
+	}
+	for i, w := range want {
+		if rows[i].Line != w.line || !strings.Contains(rows[i].Err.Error(), w.msg) {
+			t.Errorf("Row error %d = %v, want line %d: %s", i, rows[i], w.line, w.msg)
+		}
+	}
+}
+
+func TestParseColumns(t *testing.T) {
+	columns, err := ParseColumns("Task=description, Minutes = Duration")
+	if err != nil || len(columns) != 2 || columns["task"] != FieldDescription || columns["minutes"] != FieldDuration {
+		t.Errorf("Unexpected columns %v, %v", columns, err)
+	}
+	for _, s := range []string{"Task", "=description", "Task=project"} {
+		if _, err := ParseColumns(s); err == nil {
+			t.Errorf("ParseColumns(%q) succeeded, want an error", s)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+// Changes tells whether the database has changed since it last looked.
+// *db.ChangeWatcher is one.
+type Changes interface {
+	Changed() (bool, error)
+}
+
+// statusCache holds the answers to the queries status bars poll for, until
+// the database changes. Status bars polling every second then cost a check
+// of the database's change counter instead of a query.
+type statusCache struct {
+	changes Changes
+
+	active      *db.PomodoroSession
+	activeValid bool
+
+	today    []db.PomodoroSession
+	todayDay string // Local date today was read on, "" when not cached
+}
+
+// EnableStatusCache answers status and today requests from memory until
+// changes reports that the database changed, whoever changed it. A nil
+// changes turns the cache off. It may be called while Run is running.
+func (d *Daemon) EnableStatusCache(changes Changes) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if changes == nil {
+		d.cache = nil
+		return
+	}
+	d.cache = &statusCache{changes: changes}
+}
+
+// fresh drops what the cache holds when the database has changed, and
+// reports whether it may be used
+func (c *statusCache) fresh() bool {
+	changed, err := c.changes.Changed()
+	if err != nil {
+		return false
+	}
+	if changed {
+		c.active, c.activeValid = nil, false
+		c.today, c.todayDay = nil, ""
+	}
+	return true
+}
+
+// activeSession is GetActiveSession, from the cache when it is on. A cached
+// session that has since run to its end is read again, as the session
+// becoming inactive is not a change to the database.
+func (d *Daemon) activeSession() (*db.PomodoroSession, error) {
+	c := d.cache
+	if c == nil || !c.fresh() {
+		return d.db.GetActiveSession()
+	}
+	if c.activeValid && (c.active == nil || c.active.IsPaused || c.active.EndTime.After(d.now())) {
+		return copySession(c.active), nil
+	}
+
+	active, err := d.db.GetActiveSession()
+	if err != nil {
+		return nil, err
+	}
+	c.active, c.activeValid = copySession(active), true
+	return active, nil
+}
+
+// todaySessions is GetTodaySessions, from the cache when it is on
+func (d *Daemon) todaySessions() ([]db.PomodoroSession, error) {
+	c := d.cache
+	if c == nil || !c.fresh() {
+		return d.db.GetTodaySessions()
+	}
+	day := d.now().Format(time.DateOnly)
+	if c.todayDay == day {
+		return copySessions(c.today), nil
+	}
+
+	sessions, err := d.db.GetTodaySessions()
+	if err != nil {
+		return nil, err
+	}
+	c.today, c.todayDay = copySessions(sessions), day
+	return sessions, nil
+}
+
+// copySession copies a session, so callers may change what they are given
+// without changing the cache
+func copySession(s *db.PomodoroSession) *db.PomodoroSession {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	if s.PausedAt != nil {
+		pausedAt := *s.PausedAt
+		c.PausedAt = &pausedAt
+	}
+	c.Tags = append([]string(nil), s.Tags...)
+	return &c
+}
+
+// copySessions copies a list of sessions like copySession
+func copySessions(sessions []db.PomodoroSession) []db.PomodoroSession {
+	if sessions == nil {
+		return nil
+	}
+	out := make([]db.PomodoroSession, len(sessions))
+	for i := range sessions {
+		out[i] = *copySession(&sessions[i])
+	}
+	return out
+}
@@ -0,0 +1,366 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+// Actions accepted on the control socket
+const (
+	ActionStatus = "status"
+	ActionPause  = "pause"
+	ActionResume = "resume"
+	ActionCancel = "cancel"
+	ActionExtend = "extend" // Lengthens the active session by Request.Extend
+	ActionEvents = "events" // Keeps the connection open and streams events
+	ActionToday  = "today"  // Returns today's sessions in Response.Sessions
+	ActionSnooze = "snooze" // Holds off the nudge to start a pomodoro until Request.Until
+)
+
+// Event types sent to clients streaming events
+const (
+	EventConfigReloaded   = "config_reloaded"
+	EventConfigError      = "config_error"
+	EventSessionCompleted = "session_completed"
+	EventSessionExtended  = "session_extended"
+
+	EventSessionAutoPaused  = "session_auto_paused"  // The user went idle during a pomodoro
+	EventSessionAutoResumed = "session_auto_resumed" // The user came back and the pomodoro resumed
+	EventActivityReturned   = "activity_returned"    // The user came back to a pomodoro left paused
+
+	EventExportCompleted = "export_completed" // A scheduled export was written
+	EventExportFailed    = "export_failed"    // A scheduled export failed; Error says why
+
+	EventNudged = "nudged" // No pomodoro had been started by the nudge's time
+)
+
+// eventBuffer is how many events a slow client may fall behind before
+// further events are dropped for it
+const eventBuffer = 16
+
+// callTimeout bounds a whole request to the daemon, so a hung daemon never
+// blocks the CLI for long
+const callTimeout = 2 * time.Second
+
+// ErrNotRunning is returned by Call when no daemon is listening
+var ErrNotRunning = errors.New("daemon is not running")
+
+// Request is sent to the daemon, one per connection
+type Request struct {
+	Action string        `json:"action"`
+	Extend time.Duration `json:"extend,omitempty"` // For ActionExtend
+	Until  time.Time     `json:"until,omitzero"`   // For ActionSnooze
+}
+
+// Response is the daemon's reply. Session is the active session after the
+// action, or the session the action ended for cancel.
+type Response struct {
+	PID      int                  `json:"pid"`
+	Session  *db.PomodoroSession  `json:"session,omitempty"`
+	Sessions []db.PomodoroSession `json:"sessions,omitempty"` // For ActionToday
+	Error    string               `json:"error,omitempty"`
+}
+
+// Event is something that happened in the daemon, sent as one JSON line to
+// every client streaming events
+type Event struct {
+	Type      string    `json:"type"`
+	Time      time.Time `json:"time"`
+	SessionID int64     `json:"session_id,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// SocketPath returns the control socket of the daemon for a database, in
+// XDG_RUNTIME_DIR when set and next to the default log file otherwise. The
+// default database is given as ""; every other database gets a socket of
+// its own, so daemons for separate databases run side by side.
+func SocketPath(database string) string {
+	dir, name := os.Getenv("XDG_RUNTIME_DIR"), "pomodoro"
+	if dir == "" {
+		dir, name = filepath.Dir(DefaultLogFile()), "daemon"
+	}
+	if database != "" {
+		sum := sha256.Sum256([]byte(database))
+		name += "-" + hex.EncodeToString(sum[:4])
+	}
+	return filepath.Join(dir, name+".sock")
+}
+
+// Call sends an action to the daemon listening on socket and returns its
+// response. It returns ErrNotRunning when no daemon answers.
+func Call(socket, action string) (*Response, error) {
+	return Send(socket, Request{Action: action})
+}
+
+// Send is Call for requests that carry more than an action
+func Send(socket string, req Request) (*Response, error) {
+	conn, err := net.DialTimeout("unix", socket, callTimeout)
+	if err != nil {
+		return nil, ErrNotRunning
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(callTimeout))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, fmt.Errorf("error sending request to daemon: %v", err)
+	}
+
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("error reading daemon response: %v", err)
+	}
+	if resp.Error != "" {
+		return &resp, errors.New(resp.Error)
+	}
+	return &resp, nil
+}
+
+// Subscribe streams events from the daemon listening on socket to handle
+// until the connection closes or ctx is cancelled. It returns ErrNotRunning
+// when no daemon answers.
+func Subscribe(ctx context.Context, socket string, handle func(Event)) error {
+	conn, err := net.DialTimeout("unix", socket, callTimeout)
+	if err != nil {
+		return ErrNotRunning
+	}
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+
+	if err := json.NewEncoder(conn).Encode(Request{Action: ActionEvents}); err != nil {
+		return fmt.Errorf("error sending request to daemon: %v", err)
+	}
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return fmt.Errorf("error reading daemon event: %v", err)
+		}
+		handle(event)
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return scanner.Err()
+}
+
+// Publish sends an event to every client streaming events. Clients that
+// have fallen too far behind miss it rather than slowing the daemon down.
+func (d *Daemon) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = d.now()
+	}
+
+	d.subsMu.Lock()
+	defer d.subsMu.Unlock()
+	for ch := range d.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// streamEvents writes published events to conn until the client goes away
+func (d *Daemon) streamEvents(conn net.Conn) {
+	ch := make(chan Event, eventBuffer)
+	d.subsMu.Lock()
+	if d.subscribers == nil {
+		d.subscribers = make(map[chan Event]struct{})
+	}
+	d.subscribers[ch] = struct{}{}
+	d.subsMu.Unlock()
+
+	defer func() {
+		d.subsMu.Lock()
+		delete(d.subscribers, ch)
+		d.subsMu.Unlock()
+	}()
+
+	// Notice a client hanging up even when no events arrive
+	closed := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(io.Discard, conn)
+		close(closed)
+	}()
+
+	enc := json.NewEncoder(conn)
+	for {
+		select {
+		case <-closed:
+			return
+		case event := <-ch:
+			if err := enc.Encode(event); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Listen opens the control socket. A socket left behind by a daemon that
+// has exited is replaced; a live daemon makes Listen fail.
+func Listen(socket string) (net.Listener, error) {
+	if _, err := Call(socket, ActionStatus); err == nil {
+		return nil, fmt.Errorf("another daemon is already running on %s", socket)
+	}
+	_ = os.Remove(socket)
+
+	if err := os.MkdirAll(filepath.Dir(socket), 0700); err != nil {
+		return nil, fmt.Errorf("error creating socket directory: %v", err)
+	}
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, fmt.Errorf("error opening control socket: %v", err)
+	}
+	return l, nil
+}
+
+// Serve answers control requests on l until ctx is cancelled
+func (d *Daemon) Serve(ctx context.Context, l net.Listener) {
+	go func() {
+		<-ctx.Done()
+		_ = l.Close()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() == nil && !errors.Is(err, net.ErrClosed) {
+				d.logger.Printf("error accepting control connection: %v", err)
+			}
+			return
+		}
+		go d.serveConn(conn)
+	}
+}
+
+// serveConn handles the single request sent on a connection
+func (d *Daemon) serveConn(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(callTimeout))
+
+	var req Request
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		return
+	}
+
+	if req.Action == ActionEvents {
+		_ = conn.SetDeadline(time.Time{})
+		d.streamEvents(conn)
+		return
+	}
+
+	resp := Response{PID: os.Getpid()}
+	var err error
+	if req.Action == ActionToday {
+		resp.Sessions, err = d.today()
+	} else {
+		resp.Session, err = d.handle(req)
+	}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+
+	if err := json.NewEncoder(conn).Encode(resp); err != nil {
+		d.logger.Printf("error answering %s request: %v", req.Action, err)
+	}
+}
+
+// handle performs a control request and returns the session it concerns
+func (d *Daemon) handle(req Request) (*db.PomodoroSession, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	switch req.Action {
+	case ActionStatus:
+		return d.activeSession()
+
+	case ActionPause:
+		session, err := d.db.GetActiveSession()
+		if err != nil || session == nil {
+			return nil, err
+		}
+		if session.IsPaused {
+			return session, nil
+		}
+		if err := d.db.PauseSession(session.ID, now); err != nil {
+			return nil, err
+		}
+		d.logger.Printf("session %d paused", session.ID)
+		return d.rewatch(session.ID)
+
+	case ActionResume:
+		session, err := d.db.GetPausedSession()
+		if err != nil || session == nil {
+			return nil, err
+		}
+		if err := d.db.ResumeSession(session.ID, now); err != nil {
+			return nil, err
+		}
+		d.logger.Printf("session %d resumed", session.ID)
+		return d.rewatch(session.ID)
+
+	case ActionCancel:
+		session, err := d.db.GetActiveSession()
+		if err != nil || session == nil {
+			return nil, err
+		}
+		if err := d.db.EndSession(session.ID, now, db.StatusCancelled); err != nil {
+			return nil, err
+		}
+		d.logger.Printf("session %d was cancelled", session.ID)
+		d.watching = nil
+		session.EndTime = now
+		return session, nil
+
+	case ActionExtend:
+		session, err := d.db.GetActiveSession()
+		if err != nil || session == nil {
+			return nil, err
+		}
+		if err := d.db.ExtendSession(session.ID, req.Extend); err != nil {
+			return nil, err
+		}
+		d.logger.Printf("session %d extended by %s", session.ID, req.Extend)
+		d.Publish(Event{Type: EventSessionExtended, Time: now, SessionID: session.ID})
+		return d.rewatch(session.ID)
+
+	case ActionSnooze:
+		d.snoozeNudge(req.Until)
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("unknown daemon action %q", req.Action)
+}
+
+// today returns today's sessions for a today request
+func (d *Daemon) today() ([]db.PomodoroSession, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.todaySessions()
+}
+
+// rewatch reloads a session the daemon just changed and watches it
+func (d *Daemon) rewatch(id int64) (*db.PomodoroSession, error) {
+	session, err := d.db.GetSessionByID(id)
+	if err != nil {
+		return nil, err
+	}
+	d.watching = session
+	return session, nil
+}
@@ -0,0 +1,545 @@
+// Package daemon runs Pomodoro timers in the background so sessions complete,
+// and notifications fire, even when no terminal is attached
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/idle"
+	"github.com/ethan-k/pomodoro-cli/internal/plan"
+)
+
+// DefaultInterval is how often the daemon checks the active session
+const DefaultInterval = 2 * time.Second
+
+// notifyGrace gives a timer UI attached to the session time to announce
+// completion itself before the daemon does
+const notifyGrace = 3 * time.Second
+
+// wakeGap is how late a check may run before the daemon takes it that the
+// machine slept. Just after waking, completion waits out notifyGrace again so
+// a timer UI can first take the slept time off the pomodoro.
+const wakeGap = 30 * time.Second
+
+// lockCheckInterval is how often the screen lock state is polled
+const lockCheckInterval = 10 * time.Second
+
+// activityCheckInterval is how often the user's idle time is polled
+const activityCheckInterval = 10 * time.Second
+
+// exportCheckInterval is how often the daemon asks whether an export is due
+const exportCheckInterval = time.Minute
+
+// exportRetry is how long the daemon waits after a failed export before
+// trying again, so a full disk or an unreachable bucket is not retried, and
+// reported, every minute
+const exportRetry = time.Hour
+
+// lockBreakDescription describes breaks recorded while the screen was locked
+const lockBreakDescription = "Away (screen locked)"
+
+// ExportFunc writes a scheduled export of the daemon's database
+type ExportFunc func(database db.DB, now time.Time) error
+
+// CompleteFunc is called once when a session runs to its end time
+type CompleteFunc func(session *db.PomodoroSession)
+
+// IdleFunc is called when a session is paused because the user went idle,
+// and when they come back, with EventSessionAutoPaused,
+// EventSessionAutoResumed, or EventActivityReturned
+type IdleFunc func(session *db.PomodoroSession, event string)
+
+// idleEvent is an IdleFunc call waiting for the lock to be released
+type idleEvent struct {
+	session *db.PomodoroSession
+	event   string
+}
+
+// Daemon watches the active session and reports its completion
+type Daemon struct {
+	db         db.DB
+	onComplete CompleteFunc
+	logger     *log.Logger
+	interval   time.Duration
+	now        func() time.Time
+
+	// mu serializes the timer loop and control requests
+	mu sync.Mutex
+
+	// watching is the session being timed, as last read from the database
+	watching *db.PomodoroSession
+
+	// wokeAt is when the daemon last noticed the machine had slept
+	wokeAt time.Time
+
+	// Screen locks longer than lockBreakAfter are recorded as breaks
+	lock           idle.LockDetector
+	lockBreakAfter time.Duration
+	lockCheckedAt  time.Time
+	lockedSince    time.Time
+
+	// Pomodoros are paused once the user has been idle for autoPauseAfter,
+	// and resumed on their return when autoResume is set
+	activity          idle.ActivityDetector
+	autoPauseAfter    time.Duration
+	autoResume        bool
+	onIdle            IdleFunc
+	activityCheckedAt time.Time
+	autoPaused        int64 // Session paused for idleness, 0 for none
+	idleEvents        []idleEvent
+
+	// cache answers status polls from memory, nil when turned off
+	cache *statusCache
+
+	// export runs whenever exportDue says one is due
+	export          ExportFunc
+	exportDue       func(now time.Time) bool
+	exportCheckedAt time.Time
+	exportFailedAt  time.Time
+	exporting       bool
+
+	// onNudge is called when no pomodoro has been started by the nudge's
+	// time, once a day unless snoozed
+	nudge             *Nudge
+	onNudge           NudgeFunc
+	nudgeCheckedAt    time.Time
+	nudgedDay         string // YYYY-MM-DD of the last nudge, or of the day it was found unneeded
+	nudgeSnoozedUntil time.Time
+
+	// A daemon with exitWhenIdle set stops once no session has been active
+	// for that long
+	exitWhenIdle time.Duration
+	idleSince    time.Time
+
+	// subscribers receive published events; guarded by subsMu
+	subsMu      sync.Mutex
+	subscribers map[chan Event]struct{}
+}
+
+// New creates a daemon that calls onComplete for every session that runs to
+// its end time while the daemon is running
+func New(database db.DB, onComplete CompleteFunc, logger *log.Logger) *Daemon {
+	return &Daemon{
+		db:         database,
+		onComplete: onComplete,
+		logger:     logger,
+		interval:   DefaultInterval,
+		now:        time.Now,
+	}
+}
+
+// EnableLockBreaks records a break whenever the screen stays locked for at
+// least after. A pomodoro running when the screen locked ends at the lock.
+// A nil detector turns lock breaks off. It may be called while Run is running.
+func (d *Daemon) EnableLockBreaks(detector idle.LockDetector, after time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.lock = detector
+	d.lockBreakAfter = after
+	if detector == nil {
+		d.lockedSince = time.Time{}
+	}
+}
+
+// EnableAutoPause pauses a running pomodoro once the user has had no input
+// for after, counting the pause from their last input. When input returns,
+// the pomodoro is resumed if resume is set; either way onIdle is told. A nil
+// detector turns auto-pause off. It may be called while Run is running.
+func (d *Daemon) EnableAutoPause(detector idle.ActivityDetector, after time.Duration, resume bool, onIdle IdleFunc) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.activity = detector
+	d.autoPauseAfter = after
+	d.autoResume = resume
+	d.onIdle = onIdle
+	if detector == nil {
+		d.autoPaused = 0
+	}
+}
+
+// EnableExports runs export whenever due reports that one is due, in the
+// background so sessions keep being timed meanwhile. A nil export turns
+// exports off. It may be called while Run is running.
+func (d *Daemon) EnableExports(due func(now time.Time) bool, export ExportFunc) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.export = export
+	d.exportDue = due
+	d.exportCheckedAt = time.Time{}
+	d.exportFailedAt = time.Time{}
+}
+
+// ExitWhenIdle makes Run return once no session has been active for after.
+// Daemons spawned on demand use it so they do not linger.
+func (d *Daemon) ExitWhenIdle(after time.Duration) {
+	d.exitWhenIdle = after
+}
+
+// Run checks the active session every interval until ctx is cancelled, or
+// until the daemon has been idle for too long when ExitWhenIdle is set
+func (d *Daemon) Run(ctx context.Context) error {
+	d.logger.Printf("daemon started (pid %d)", os.Getpid())
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	// Ticks are compared by the wall clock, as the monotonic clock stops
+	// during sleep on some systems
+	last := time.Now().Round(0)
+	for {
+		now := time.Now().Round(0)
+		if now.Sub(last) > d.interval+wakeGap {
+			d.logger.Printf("woke after about %s asleep", (now.Sub(last) - d.interval).Round(time.Second))
+			d.mu.Lock()
+			d.wokeAt = d.now()
+			d.mu.Unlock()
+		}
+		last = now
+
+		if err := d.check(); err != nil {
+			d.logger.Printf("%v", err)
+		}
+		d.checkExport()
+		d.checkNudge()
+		if d.idleTooLong() {
+			d.logger.Printf("no active session for %s, exiting", d.exitWhenIdle)
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			d.logger.Printf("daemon stopped")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// check advances the watched session and picks up a newly started one.
+// Completion is reported after releasing the lock, since notifications may
+// take a while and control requests should not wait for them.
+func (d *Daemon) check() error {
+	d.mu.Lock()
+	finished, err := d.advance()
+	idleEvents, onIdle := d.idleEvents, d.onIdle
+	d.idleEvents = nil
+	d.mu.Unlock()
+
+	if finished != nil {
+		d.onComplete(finished)
+		d.Publish(Event{Type: EventSessionCompleted, SessionID: finished.ID})
+	}
+	for _, e := range idleEvents {
+		if onIdle != nil {
+			onIdle(e.session, e.event)
+		}
+		d.Publish(Event{Type: e.event, SessionID: e.session.ID})
+	}
+	return err
+}
+
+// advance does the work of check while holding the lock and returns a
+// session whose completion must be reported
+func (d *Daemon) advance() (*db.PomodoroSession, error) {
+	if d.lock != nil {
+		if err := d.checkLock(); err != nil {
+			d.logger.Printf("%v", err)
+		}
+	}
+
+	// Hold completion while the screen is locked; the lock may yet turn out
+	// to be a break that cuts the pomodoro short
+	if !d.lockedSince.IsZero() {
+		return nil, nil
+	}
+
+	if d.activity != nil {
+		if err := d.checkActivity(); err != nil {
+			d.logger.Printf("%v", err)
+		}
+	}
+
+	var finished *db.PomodoroSession
+	if d.watching != nil {
+		var err error
+		if finished, err = d.checkWatched(); err != nil {
+			return nil, err
+		}
+	}
+
+	if d.watching == nil {
+		active, err := d.activeSession()
+		if err != nil {
+			return finished, err
+		}
+		if active != nil {
+			d.logger.Printf("watching session %d (%s), ends %s",
+				active.ID, active.Description, active.EndTime.Format(time.RFC3339))
+			d.watching = active
+		}
+	}
+
+	return finished, nil
+}
+
+// checkExport starts a scheduled export when one is due and none is
+// running, and returns a channel closed once it has finished, nil when none
+// was started
+func (d *Daemon) checkExport() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	if d.export == nil || d.exporting || now.Sub(d.exportCheckedAt) < exportCheckInterval {
+		return nil
+	}
+	d.exportCheckedAt = now
+	if !d.exportFailedAt.IsZero() && now.Sub(d.exportFailedAt) < exportRetry {
+		return nil
+	}
+	if !d.exportDue(now) {
+		return nil
+	}
+
+	d.exporting = true
+	export, database := d.export, d.db
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		err := export(database, now)
+
+		d.mu.Lock()
+		d.exporting = false
+		if err != nil {
+			d.exportFailedAt = d.now()
+		} else {
+			d.exportFailedAt = time.Time{}
+		}
+		d.mu.Unlock()
+
+		if err != nil {
+			d.logger.Printf("scheduled export failed: %v", err)
+			d.Publish(Event{Type: EventExportFailed, Error: err.Error()})
+			return
+		}
+		d.logger.Printf("scheduled export written")
+		d.Publish(Event{Type: EventExportCompleted})
+	}()
+	return done
+}
+
+// idleTooLong reports whether an ExitWhenIdle limit has been reached
+func (d *Daemon) idleTooLong() bool {
+	if d.exitWhenIdle <= 0 {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	if d.watching != nil || !d.lockedSince.IsZero() || d.exporting {
+		d.idleSince = time.Time{}
+		return false
+	}
+	if d.idleSince.IsZero() {
+		d.idleSince = now
+	}
+	return now.Sub(d.idleSince) >= d.exitWhenIdle
+}
+
+// checkWatched re-reads the watched session and completes it once its end
+// time has passed. Cancelling moves the end time earlier, to the moment of
+// cancellation, so such sessions are dropped without a notification.
+// It returns the session if its completion should be reported.
+func (d *Daemon) checkWatched() (*db.PomodoroSession, error) {
+	now := d.now()
+
+	current, err := d.db.GetSessionByID(d.watching.ID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		d.watching = nil
+		return nil, nil
+	}
+
+	if !current.IsPaused && current.EndTime.Before(d.watching.EndTime) && !now.Before(current.EndTime) {
+		d.logger.Printf("session %d was cancelled", current.ID)
+		d.watching = nil
+		return nil, nil
+	}
+
+	d.watching = current
+	if current.IsPaused || now.Before(current.EndTime.Add(notifyGrace)) || now.Before(d.wokeAt.Add(notifyGrace)) {
+		return nil, nil
+	}
+
+	d.watching = nil
+	return d.complete(current)
+}
+
+// complete marks a finished session notified and returns it, unless it was
+// already announced
+func (d *Daemon) complete(session *db.PomodoroSession) (*db.PomodoroSession, error) {
+	metadata, err := d.db.GetSessionMetadata(session.ID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := metadata[db.MetaNotified]; ok {
+		d.logger.Printf("session %d completed (already notified)", session.ID)
+		return nil, nil
+	}
+
+	if err := d.db.SetSessionMetadata(session.ID, db.MetaNotified, d.now().Format(time.RFC3339)); err != nil {
+		return nil, fmt.Errorf("error marking session %d notified: %v", session.ID, err)
+	}
+	if err := d.db.SetSessionStatus(session.ID, db.StatusCompleted); err != nil {
+		return nil, err
+	}
+	if err := plan.SessionCompleted(d.db, session.ID, d.now()); err != nil {
+		d.logger.Printf("error checking off the planned task of session %d: %v", session.ID, err)
+	}
+
+	d.logger.Printf("session %d completed", session.ID)
+	return session, nil
+}
+
+// checkLock polls the lock state and records a break when a long lock ends
+func (d *Daemon) checkLock() error {
+	now := d.now()
+	if now.Sub(d.lockCheckedAt) < lockCheckInterval {
+		return nil
+	}
+	d.lockCheckedAt = now
+
+	locked, err := d.lock.Locked()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case locked && d.lockedSince.IsZero():
+		d.lockedSince = now
+		d.logger.Printf("screen locked")
+	case !locked && !d.lockedSince.IsZero():
+		since := d.lockedSince
+		d.lockedSince = time.Time{}
+		d.logger.Printf("screen unlocked after %s", now.Sub(since).Round(time.Second))
+		if now.Sub(since) >= d.lockBreakAfter {
+			return d.recordLockBreak(since, now)
+		}
+	}
+	return nil
+}
+
+// checkActivity polls the user's idle time, pausing the watched pomodoro
+// when they have been away too long and acting on their return
+func (d *Daemon) checkActivity() error {
+	now := d.now()
+	if now.Sub(d.activityCheckedAt) < activityCheckInterval {
+		return nil
+	}
+	d.activityCheckedAt = now
+
+	idleFor, err := d.activity.IdleTime()
+	if err != nil {
+		return err
+	}
+
+	if d.autoPaused == 0 {
+		session := d.watching
+		if idleFor < d.autoPauseAfter || session == nil || session.WasBreak || session.IsPaused || !now.Before(session.EndTime) {
+			return nil
+		}
+		pausedAt := now.Add(-idleFor)
+		if pausedAt.Before(session.StartTime) {
+			pausedAt = session.StartTime
+		}
+		if err := d.db.PauseSession(session.ID, pausedAt); err != nil {
+			return fmt.Errorf("error pausing session %d while idle: %v", session.ID, err)
+		}
+		d.logger.Printf("session %d paused after %s idle", session.ID, idleFor.Round(time.Second))
+		d.autoPaused = session.ID
+		paused, err := d.rewatch(session.ID)
+		if err != nil {
+			return err
+		}
+		d.idleEvents = append(d.idleEvents, idleEvent{paused, EventSessionAutoPaused})
+		return nil
+	}
+
+	// Input since the last check means the user is back
+	if idleFor >= activityCheckInterval {
+		return nil
+	}
+	id := d.autoPaused
+	d.autoPaused = 0
+	session, err := d.db.GetSessionByID(id)
+	if err != nil {
+		return err
+	}
+	// Resumed or ended by hand while the user was away
+	if session == nil || !session.IsPaused {
+		return nil
+	}
+	if !d.autoResume {
+		d.logger.Printf("activity returned, session %d left paused", id)
+		d.idleEvents = append(d.idleEvents, idleEvent{session, EventActivityReturned})
+		return nil
+	}
+	if err := d.db.ResumeSession(id, now); err != nil {
+		return fmt.Errorf("error resuming session %d after idle: %v", id, err)
+	}
+	d.logger.Printf("session %d resumed on activity", id)
+	if session, err = d.rewatch(id); err != nil {
+		return err
+	}
+	d.idleEvents = append(d.idleEvents, idleEvent{session, EventSessionAutoResumed})
+	return nil
+}
+
+// recordLockBreak records the time the screen was locked as a break and
+// ends a pomodoro that was running when the screen locked. A lock during a
+// break, such as wellness.force_break makes, is part of that break, so only
+// the time locked past its end may be recorded.
+func (d *Daemon) recordLockBreak(start, end time.Time) error {
+	if w := d.watching; w != nil && w.WasBreak && !w.StartTime.After(start) && w.EndTime.After(start) {
+		if end.Sub(w.EndTime) < d.lockBreakAfter {
+			d.logger.Printf("screen lock during break %d not recorded", w.ID)
+			return nil
+		}
+		start = w.EndTime
+	}
+	if d.watching != nil && !d.watching.WasBreak && d.watching.StartTime.Before(start) && d.watching.EndTime.After(start) {
+		if err := d.db.EndSession(d.watching.ID, start, db.StatusAbandoned); err != nil {
+			return fmt.Errorf("error ending session %d at screen lock: %v", d.watching.ID, err)
+		}
+		d.logger.Printf("session %d ended at screen lock", d.watching.ID)
+		d.watching = nil
+	}
+
+	id, err := d.db.CreateSession(start, end, lockBreakDescription, int64(end.Sub(start).Seconds()), "", true)
+	if err != nil {
+		return fmt.Errorf("error recording screen lock break: %v", err)
+	}
+	if err := d.db.SetSessionMetadata(id, db.MetaAutoBreak, "screen_lock"); err != nil {
+		return err
+	}
+	// The break is already over, so there is nothing to announce
+	if err := d.db.SetSessionMetadata(id, db.MetaNotified, end.Format(time.RFC3339)); err != nil {
+		return err
+	}
+
+	d.logger.Printf("recorded %s screen lock break (session %d)", end.Sub(start).Round(time.Second), id)
+	return nil
+}
@@ -0,0 +1,637 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+// sessionDB holds a single session and its metadata in memory
+type sessionDB struct {
+	db.DB
+	session  *db.PomodoroSession
+	metadata map[string]string
+	now      *time.Time
+	created  []db.PomodoroSession
+}
+
+func (s *sessionDB) CreateSession(startTime, endTime time.Time, description string, durationSec int64, _ string, wasBreak bool) (int64, error) {
+	s.created = append(s.created, db.PomodoroSession{
+		StartTime:   startTime,
+		EndTime:     endTime,
+		Description: description,
+		DurationSec: durationSec,
+		WasBreak:    wasBreak,
+	})
+	return int64(len(s.created) + 1), nil
+}
+
+func (s *sessionDB) EndSession(_ int64, endedAt time.Time, status string) error {
+	s.session.EndTime = endedAt
+	s.session.Status = status
+	s.session.IsPaused = false
+	s.session.PausedAt = nil
+	return nil
+}
+
+func (s *sessionDB) ExtendSession(_ int64, by time.Duration) error {
+	s.session.EndTime = s.session.EndTime.Add(by)
+	s.session.DurationSec += int64(by / time.Second)
+	return nil
+}
+
+func (s *sessionDB) SetSessionStatus(_ int64, status string) error {
+	s.session.Status = status
+	return nil
+}
+
+func (s *sessionDB) PauseSession(_ int64, pausedAt time.Time) error {
+	s.session.IsPaused = true
+	s.session.PausedAt = &pausedAt
+	return nil
+}
+
+func (s *sessionDB) ResumeSession(_ int64, resumedAt time.Time) error {
+	s.session.EndTime = s.session.EndTime.Add(resumedAt.Sub(*s.session.PausedAt))
+	s.session.IsPaused = false
+	s.session.PausedAt = nil
+	return nil
+}
+
+// fakeActivity reports a fixed idle time
+type fakeActivity struct {
+	idle time.Duration
+}
+
+func (f *fakeActivity) IdleTime() (time.Duration, error) {
+	return f.idle, nil
+}
+
+// fakeLock reports a fixed lock state
+type fakeLock struct {
+	locked bool
+}
+
+func (f *fakeLock) Locked() (bool, error) {
+	return f.locked, nil
+}
+
+func (s *sessionDB) GetActiveSession() (*db.PomodoroSession, error) {
+	if s.session == nil || (!s.session.IsPaused && !s.session.EndTime.After(*s.now)) {
+		return nil, nil
+	}
+	copied := *s.session
+	return &copied, nil
+}
+
+func (s *sessionDB) GetSessionByID(_ int64) (*db.PomodoroSession, error) {
+	copied := *s.session
+	return &copied, nil
+}
+
+func (s *sessionDB) GetTodaySessions() ([]db.PomodoroSession, error) {
+	if s.session == nil {
+		return nil, nil
+	}
+	return []db.PomodoroSession{*s.session}, nil
+}
+
+func (s *sessionDB) GetSessionMetadata(_ int64) (map[string]string, error) {
+	return s.metadata, nil
+}
+
+func (s *sessionDB) SetSessionMetadata(_ int64, key, value string) error {
+	s.metadata[key] = value
+	return nil
+}
+
+// newTestDaemon returns a daemon on a fake clock and a counter of completions
+func newTestDaemon(start time.Time) (*Daemon, *sessionDB, *time.Time, *int) {
+	now := start
+	database := &sessionDB{
+		session: &db.PomodoroSession{
+			ID:          1,
+			StartTime:   start,
+			EndTime:     start.Add(25 * time.Minute),
+			DurationSec: 1500,
+		},
+		metadata: map[string]string{},
+		now:      &now,
+	}
+
+	completed := 0
+	d := New(database, func(_ *db.PomodoroSession) { completed++ }, log.New(io.Discard, "", 0))
+	d.now = func() time.Time { return now }
+	return d, database, &now, &completed
+}
+
+func TestDaemonCompletesSessionOnce(t *testing.T) {
+	start := time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC)
+	d, database, now, completed := newTestDaemon(start)
+
+	steps := []time.Duration{0, 10 * time.Minute, 25 * time.Minute, 25*time.Minute + notifyGrace, 30 * time.Minute}
+	for _, step := range steps {
+		*now = start.Add(step)
+		if err := d.check(); err != nil {
+			t.Fatalf("check at %s: %v", step, err)
+		}
+	}
+
+	if *completed != 1 {
+		t.Errorf("Expected 1 completion, got %d", *completed)
+	}
+	if _, ok := database.metadata[db.MetaNotified]; !ok {
+		t.Error("Expected session to be marked notified")
+	}
+}
+
+func TestDaemonSkipsCancelledAndNotifiedSessions(t *testing.T) {
+	start := time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC)
+
+	t.Run("cancelled", func(t *testing.T) {
+		d, database, now, completed := newTestDaemon(start)
+		if err := d.check(); err != nil {
+			t.Fatal(err)
+		}
+
+		// Cancelling moves the end time to the moment of cancellation
+		*now = start.Add(10 * time.Minute)
+		database.session.EndTime = *now
+		for _, step := range []time.Duration{10 * time.Minute, 40 * time.Minute} {
+			*now = start.Add(step)
+			if err := d.check(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if *completed != 0 {
+			t.Errorf("Expected no completion for a cancelled session, got %d", *completed)
+		}
+	})
+
+	t.Run("already notified", func(t *testing.T) {
+		d, database, now, completed := newTestDaemon(start)
+		if err := d.check(); err != nil {
+			t.Fatal(err)
+		}
+
+		database.metadata[db.MetaNotified] = "2024-06-10T09:25:00Z"
+		*now = start.Add(30 * time.Minute)
+		if err := d.check(); err != nil {
+			t.Fatal(err)
+		}
+
+		if *completed != 0 {
+			t.Errorf("Expected no completion for a notified session, got %d", *completed)
+		}
+	})
+}
+
+func TestDaemonRecordsLockBreak(t *testing.T) {
+	start := time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC)
+	d, database, now, completed := newTestDaemon(start)
+	lock := &fakeLock{}
+	d.EnableLockBreaks(lock, 5*time.Minute)
+
+	// Lock ten minutes into the pomodoro and come back after twenty
+	timeline := []struct {
+		at     time.Duration
+		locked bool
+	}{
+		{0, false},
+		{10 * time.Minute, true},
+		{25 * time.Minute, true},
+		{30 * time.Minute, false},
+		{35 * time.Minute, false},
+	}
+	for _, step := range timeline {
+		*now = start.Add(step.at)
+		lock.locked = step.locked
+		if err := d.check(); err != nil {
+			t.Fatalf("check at %s: %v", step.at, err)
+		}
+	}
+
+	if *completed != 0 {
+		t.Errorf("Expected the interrupted pomodoro not to complete, got %d completions", *completed)
+	}
+	if want := start.Add(10 * time.Minute); !database.session.EndTime.Equal(want) {
+		t.Errorf("Expected pomodoro to end at the lock (%s), got %s", want, database.session.EndTime)
+	}
+	if len(database.created) != 1 {
+		t.Fatalf("Expected 1 break recorded, got %d", len(database.created))
+	}
+	if b := database.created[0]; !b.WasBreak || b.DurationSec != 20*60 {
+		t.Errorf("Expected a 20m break, got %+v", b)
+	}
+}
+
+func TestDaemonLockDuringBreak(t *testing.T) {
+	for _, tc := range []struct {
+		unlock time.Duration
+		want   time.Duration // Break recorded for the lock, 0 for none
+	}{
+		{4 * time.Minute, 0},
+		{8 * time.Minute, 0},
+		{20 * time.Minute, 15 * time.Minute},
+	} {
+		start := time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC)
+		d, database, now, _ := newTestDaemon(start)
+		database.session.WasBreak = true
+		database.session.EndTime = start.Add(5 * time.Minute)
+		lock := &fakeLock{}
+		d.EnableLockBreaks(lock, 5*time.Minute)
+
+		// Locked as the break starts, as wellness.force_break does
+		for _, step := range []struct {
+			at     time.Duration
+			locked bool
+		}{{0, false}, {30 * time.Second, true}, {tc.unlock, false}} {
+			*now = start.Add(step.at)
+			lock.locked = step.locked
+			if err := d.check(); err != nil {
+				t.Fatalf("check at %s: %v", step.at, err)
+			}
+		}
+
+		switch {
+		case tc.want == 0 && len(database.created) != 0:
+			t.Errorf("Unlocked at %s: expected no break recorded, got %+v", tc.unlock, database.created)
+		case tc.want > 0 && (len(database.created) != 1 || database.created[0].DurationSec != int64(tc.want.Seconds())):
+			t.Errorf("Unlocked at %s: expected a %s break after the break, got %+v", tc.unlock, tc.want, database.created)
+		}
+	}
+}
+
+func TestDaemonAutoPausesWhenIdle(t *testing.T) {
+	for _, resume := range []bool{true, false} {
+		start := time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC)
+		d, database, now, _ := newTestDaemon(start)
+		activity := &fakeActivity{}
+		var events []string
+		d.EnableAutoPause(activity, 5*time.Minute, resume, func(_ *db.PomodoroSession, event string) {
+			events = append(events, event)
+		})
+
+		// Step away five minutes in and come back at twenty
+		timeline := []struct {
+			at   time.Duration
+			idle time.Duration
+		}{
+			{0, 0},
+			{4 * time.Minute, 0},
+			{8 * time.Minute, 3 * time.Minute},
+			{12 * time.Minute, 7 * time.Minute},
+			{18 * time.Minute, 13 * time.Minute},
+			{20 * time.Minute, 2 * time.Second},
+		}
+		for _, step := range timeline {
+			*now = start.Add(step.at)
+			activity.idle = step.idle
+			if err := d.check(); err != nil {
+				t.Fatalf("check at %s: %v", step.at, err)
+			}
+		}
+
+		if resume {
+			if len(events) != 2 || events[0] != EventSessionAutoPaused || events[1] != EventSessionAutoResumed {
+				t.Errorf("Expected the pomodoro to pause and resume, got %v", events)
+			}
+			// The fifteen minutes away do not count
+			if want := start.Add(40 * time.Minute); database.session.IsPaused || !database.session.EndTime.Equal(want) {
+				t.Errorf("Expected the pomodoro to run until %s, got %+v", want, database.session)
+			}
+		} else {
+			if len(events) != 2 || events[0] != EventSessionAutoPaused || events[1] != EventActivityReturned {
+				t.Errorf("Expected the pomodoro to pause and the return to be announced, got %v", events)
+			}
+			if want := start.Add(5 * time.Minute); !database.session.IsPaused || !database.session.PausedAt.Equal(want) {
+				t.Errorf("Expected the pomodoro paused from %s, got %+v", want, database.session)
+			}
+		}
+	}
+}
+
+func TestControlSocket(t *testing.T) {
+	start := time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC)
+	d, database, now, _ := newTestDaemon(start)
+	*now = start.Add(10 * time.Minute)
+
+	socket := filepath.Join(t.TempDir(), "d.sock")
+	if _, err := Call(socket, ActionStatus); err != ErrNotRunning {
+		t.Fatalf("Expected ErrNotRunning before listening, got %v", err)
+	}
+
+	l, err := Listen(socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Serve(ctx, l)
+
+	if _, err := Listen(socket); err == nil {
+		t.Error("Expected a second daemon to be refused")
+	}
+
+	end := database.session.EndTime
+	resp, err := Send(socket, Request{Action: ActionExtend, Extend: 10 * time.Minute})
+	if err != nil {
+		t.Fatalf("extend failed: %v", err)
+	}
+	if !resp.Session.EndTime.Equal(end.Add(10*time.Minute)) || !d.watching.EndTime.Equal(resp.Session.EndTime) {
+		t.Errorf("Expected the daemon to watch the extended session, got end %s", resp.Session.EndTime)
+	}
+
+	resp, err = Call(socket, ActionPause)
+	if err != nil {
+		t.Fatalf("pause failed: %v", err)
+	}
+	if resp.Session == nil || !resp.Session.IsPaused || !database.session.IsPaused {
+		t.Errorf("Expected the session to be paused, got %+v", resp.Session)
+	}
+
+	resp, err = Call(socket, ActionCancel)
+	if err != nil {
+		t.Fatalf("cancel failed: %v", err)
+	}
+	if !database.session.EndTime.Equal(*now) || d.watching != nil {
+		t.Errorf("Expected the session to end now and stop being watched, got end %s", database.session.EndTime)
+	}
+	if resp.PID == 0 {
+		t.Error("Expected the daemon pid in the response")
+	}
+
+	if _, err := Call(socket, "reboot"); err == nil {
+		t.Error("Expected an unknown action to fail")
+	}
+}
+
+func TestEventsReachSubscribers(t *testing.T) {
+	d, _, _, _ := newTestDaemon(time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC))
+
+	socket := filepath.Join(t.TempDir(), "d.sock")
+	l, err := Listen(socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Serve(ctx, l)
+
+	events := make(chan Event, 1)
+	go func() {
+		_ = Subscribe(ctx, socket, func(e Event) { events <- e })
+	}()
+
+	// Publish until the subscription, which connects asynchronously, is in place
+	deadline := time.After(5 * time.Second)
+	for {
+		d.Publish(Event{Type: EventConfigReloaded})
+		select {
+		case e := <-events:
+			if e.Type != EventConfigReloaded || e.Time.IsZero() {
+				t.Errorf("Unexpected event %+v", e)
+			}
+			return
+		case <-time.After(50 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("Timed out waiting for an event")
+		}
+	}
+}
+
+func TestServiceDefinitions(t *testing.T) {
+	s := &Service{Executable: "/opt/my tools/pomodoro", LogFile: "/tmp/pomodoro.log"}
+
+	unit := s.systemdUnit()
+	if !strings.Contains(unit, `ExecStart="/opt/my tools/pomodoro" daemon run --log-file /tmp/pomodoro.log`) {
+		t.Errorf("Unexpected systemd unit:\n%s", unit)
+	}
+
+	plist, err := s.launchdPlist()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"<string>" + launchdLabel + "</string>", "<string>/opt/my tools/pomodoro</string>", "<string>run</string>"} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("Expected plist to contain %q:\n%s", want, plist)
+		}
+	}
+
+	if got := s.taskCommand(); got != `"/opt/my tools/pomodoro" daemon run --log-file /tmp/pomodoro.log` {
+		t.Errorf("Unexpected task command: %s", got)
+	}
+}
+
+// fakeChanges reports a change once each time one is made
+type fakeChanges struct {
+	changed bool
+}
+
+func (f *fakeChanges) Changed() (bool, error) {
+	changed := f.changed
+	f.changed = false
+	return changed, nil
+}
+
+// countingDB counts the status queries that reach the database
+type countingDB struct {
+	*sessionDB
+	activeReads int
+	todayReads  int
+}
+
+func (c *countingDB) GetActiveSession() (*db.PomodoroSession, error) {
+	c.activeReads++
+	return c.sessionDB.GetActiveSession()
+}
+
+func (c *countingDB) GetTodaySessions() ([]db.PomodoroSession, error) {
+	c.todayReads++
+	return []db.PomodoroSession{*c.session}, nil
+}
+
+func TestStatusCache(t *testing.T) {
+	start := time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC)
+	d, database, now, _ := newTestDaemon(start)
+	counting := &countingDB{sessionDB: database}
+	d.db = counting
+	changes := &fakeChanges{}
+	d.EnableStatusCache(changes)
+
+	status := func() *db.PomodoroSession {
+		t.Helper()
+		session, err := d.handle(Request{Action: ActionStatus})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return session
+	}
+
+	status().Description = "Changed by the caller"
+	if got := status(); got == nil || got.Description != "" {
+		t.Errorf("Expected the cached session unchanged, got %+v", got)
+	}
+	if counting.activeReads != 1 {
+		t.Errorf("Expected 1 read of the active session, got %d", counting.activeReads)
+	}
+
+	changes.changed = true
+	database.session.Description = "Renamed"
+	if got := status(); got == nil || got.Description != "Renamed" {
+		t.Errorf("Expected the session read again after a change, got %+v", got)
+	}
+
+	// Running to its end changes nothing in the database
+	*now = start.Add(25 * time.Minute)
+	if got := status(); got != nil {
+		t.Errorf("Expected no active session once it ended, got %+v", got)
+	}
+	if counting.activeReads != 3 {
+		t.Errorf("Expected 3 reads of the active session, got %d", counting.activeReads)
+	}
+
+	for range 2 {
+		if _, err := d.today(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	*now = start.AddDate(0, 0, 1)
+	if _, err := d.today(); err != nil {
+		t.Fatal(err)
+	}
+	if counting.todayReads != 2 {
+		t.Errorf("Expected today's sessions read once a day, got %d reads", counting.todayReads)
+	}
+
+	d.EnableStatusCache(nil)
+	status()
+	status()
+	if counting.activeReads != 5 {
+		t.Errorf("Expected every status read from the database with the cache off, got %d reads", counting.activeReads)
+	}
+}
+
+func TestDaemonRunsExports(t *testing.T) {
+	start := time.Date(2024, 6, 10, 2, 0, 0, 0, time.UTC)
+	d, _, now, _ := newTestDaemon(start)
+	events := make(chan Event, 4)
+	d.subscribers = map[chan Event]struct{}{events: {}}
+
+	due := true
+	var fail error
+	runs := 0
+	d.EnableExports(func(time.Time) bool { return due }, func(db.DB, time.Time) error {
+		runs++
+		return fail
+	})
+
+	wait := func(done <-chan struct{}) {
+		t.Helper()
+		if done == nil {
+			return
+		}
+		<-done
+	}
+
+	wait(d.checkExport())
+	if runs != 1 {
+		t.Fatalf("Expected a due export to run, got %d runs", runs)
+	}
+	if e := <-events; e.Type != EventExportCompleted {
+		t.Errorf("Expected %s, got %+v", EventExportCompleted, e)
+	}
+
+	// Asked again only after a minute, then only while due
+	due = false
+	wait(d.checkExport())
+	*now = start.Add(exportCheckInterval)
+	wait(d.checkExport())
+	if runs != 1 {
+		t.Errorf("Expected no export until due, got %d runs", runs)
+	}
+
+	due, fail = true, errors.New("disk full")
+	*now = start.Add(2 * exportCheckInterval)
+	wait(d.checkExport())
+	if e := <-events; e.Type != EventExportFailed || e.Error != "disk full" {
+		t.Errorf("Expected %s, got %+v", EventExportFailed, e)
+	}
+
+	// A failed export is retried after exportRetry, not every minute
+	fail = nil
+	*now = start.Add(3 * exportCheckInterval)
+	wait(d.checkExport())
+	if runs != 2 {
+		t.Errorf("Expected no retry within the hour, got %d runs", runs)
+	}
+	*now = start.Add(2*exportCheckInterval + exportRetry)
+	wait(d.checkExport())
+	if runs != 3 {
+		t.Errorf("Expected a retry after an hour, got %d runs", runs)
+	}
+}
+
+func TestDaemonNudges(t *testing.T) {
+	monday := time.Date(2024, 6, 10, 9, 30, 0, 0, time.UTC)
+	d, database, now, _ := newTestDaemon(monday)
+	database.session = nil
+	events := make(chan Event, 4)
+	d.subscribers = map[chan Event]struct{}{events: {}}
+
+	nudges := 0
+	weekdays := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	d.EnableNudge(&Nudge{At: 10 * time.Hour, Days: weekdays}, func() { nudges++ })
+
+	at := func(t time.Time) bool {
+		*now = t
+		return d.checkNudge()
+	}
+	if at(monday) {
+		t.Error("Expected no nudge before 10:00")
+	}
+	if !at(monday.Add(30*time.Minute)) || nudges != 1 {
+		t.Fatalf("Expected a nudge at 10:00, got %d", nudges)
+	}
+	if e := <-events; e.Type != EventNudged {
+		t.Errorf("Expected %s, got %+v", EventNudged, e)
+	}
+	if at(monday.Add(time.Hour)) {
+		t.Error("Expected one nudge a day")
+	}
+
+	// A snooze nudges again once it is over
+	if _, err := d.handle(Request{Action: ActionSnooze, Until: monday.Add(2 * time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if at(monday.Add(90 * time.Minute)) {
+		t.Error("Expected no nudge while snoozed")
+	}
+	if !at(monday.Add(2 * time.Hour)) {
+		t.Error("Expected a nudge when the snooze ran out")
+	}
+
+	// Not on a day with a pomodoro, nor on the weekend
+	tuesday := monday.AddDate(0, 0, 1)
+	database.session = &db.PomodoroSession{ID: 2, StartTime: tuesday, EndTime: tuesday.Add(25 * time.Minute)}
+	if at(tuesday.Add(time.Hour)) {
+		t.Error("Expected no nudge after a pomodoro was started")
+	}
+	database.session = nil
+	if at(monday.AddDate(0, 0, 5).Add(time.Hour)) {
+		t.Error("Expected no nudge on Saturday")
+	}
+
+	d.EnableNudge(nil, nil)
+	if at(monday.AddDate(0, 0, 7).Add(time.Hour)) {
+		t.Error("Expected no nudge once turned off")
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package daemon
+
+import "syscall"
+
+// detachAttr starts the daemon in its own session, away from the terminal's
+// signals
+func detachAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,17 @@
+//go:build windows
+
+package daemon
+
+import "syscall"
+
+// detachedProcess starts a process without a console
+const detachedProcess = 0x00000008
+
+// detachAttr starts the daemon without a console window and outside the
+// console's Ctrl+C group
+func detachAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess,
+		HideWindow:    true,
+	}
+}
@@ -0,0 +1,91 @@
+package daemon
+
+import (
+	"slices"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+// nudgeCheckInterval is how often the daemon asks whether to nudge
+const nudgeCheckInterval = time.Minute
+
+// NudgeFunc reminds the user that no pomodoro has been started today
+type NudgeFunc func()
+
+// Nudge is when the daemon reminds the user to start the day's first
+// pomodoro
+type Nudge struct {
+	At   time.Duration  // Time of day, since midnight, by which one should have started
+	Days []time.Weekday // Days to nudge on; empty for every day
+}
+
+// due reports whether now is on a nudge day and past its time
+func (n Nudge) due(now time.Time) bool {
+	if len(n.Days) > 0 && !slices.Contains(n.Days, now.Weekday()) {
+		return false
+	}
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return now.Sub(midnight) >= n.At
+}
+
+// EnableNudge calls onNudge once a day when no pomodoro has been started by
+// the nudge's time. A nil nudge turns nudging off. It may be called while
+// Run is running.
+func (d *Daemon) EnableNudge(nudge *Nudge, onNudge NudgeFunc) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.nudge = nudge
+	d.onNudge = onNudge
+	d.nudgeCheckedAt = time.Time{}
+}
+
+// snoozeNudge holds off nudging until until, nudging again then if no
+// pomodoro has been started by that time even if the day's nudge was sent
+func (d *Daemon) snoozeNudge(until time.Time) {
+	d.nudgeSnoozedUntil = until
+	d.nudgedDay = ""
+	d.nudgeCheckedAt = time.Time{}
+	d.logger.Printf("nudge snoozed until %s", until.Format("2006-01-02 15:04"))
+}
+
+// checkNudge nudges the user when it is past the nudge's time on a nudge day,
+// no pomodoro has been started today, and the nudge is neither snoozed nor
+// already sent today. It reports whether it nudged.
+func (d *Daemon) checkNudge() bool {
+	d.mu.Lock()
+	now := d.now()
+	day := now.Format(time.DateOnly)
+	if d.nudge == nil || now.Sub(d.nudgeCheckedAt) < nudgeCheckInterval || d.nudgedDay == day ||
+		now.Before(d.nudgeSnoozedUntil) || !d.nudge.due(now) {
+		d.mu.Unlock()
+		return false
+	}
+	d.nudgeCheckedAt = now
+
+	started := d.watching != nil && !d.watching.WasBreak
+	if !started {
+		sessions, err := d.todaySessions()
+		if err != nil {
+			d.mu.Unlock()
+			d.logger.Printf("not nudging: %v", err)
+			return false
+		}
+		started = slices.ContainsFunc(sessions, func(s db.PomodoroSession) bool { return !s.WasBreak })
+	}
+	// Either way there is nothing more to do today
+	d.nudgedDay = day
+	onNudge := d.onNudge
+	d.mu.Unlock()
+
+	if started {
+		return false
+	}
+	d.logger.Printf("no pomodoro started by %s, nudging", now.Format("15:04"))
+	if onNudge != nil {
+		onNudge()
+	}
+	d.Publish(Event{Type: EventNudged})
+	return true
+}
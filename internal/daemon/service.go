@@ -0,0 +1,198 @@
+package daemon
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+)
+
+// Names the daemon is registered under with each platform's service manager
+const (
+	launchdLabel    = "io.github.ethan-k.pomodoro"
+	systemdUnitName = "pomodoro.service"
+	windowsTaskName = "Pomodoro Daemon"
+)
+
+// Service describes how the daemon is launched at login
+type Service struct {
+	Executable string // Absolute path to the pomodoro binary
+	LogFile    string // File the daemon writes its log to
+}
+
+// ServiceStatus reports the daemon service state from the service manager
+type ServiceStatus struct {
+	Installed bool
+	Running   bool
+	Location  string // Service definition file, or task name on Windows
+	LogFile   string
+}
+
+// NewService creates a service definition for the running pomodoro binary
+func NewService(logFile string) (*Service, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("error locating pomodoro executable: %v", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	return &Service{
+		Executable: exe,
+		LogFile:    logFile,
+	}, nil
+}
+
+// DefaultLogFile returns the platform's conventional location for the daemon log
+func DefaultLogFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(home, "Library", "Logs", "pomodoro", "daemon.log")
+	case "windows":
+		if dir, err := os.UserCacheDir(); err == nil { // %LOCALAPPDATA%
+			return filepath.Join(dir, "pomodoro", "logs", "daemon.log")
+		}
+		return filepath.Join(home, "AppData", "Local", "pomodoro", "logs", "daemon.log")
+	default:
+		if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+			return filepath.Join(dir, "pomodoro", "daemon.log")
+		}
+		return filepath.Join(home, ".local", "state", "pomodoro", "daemon.log")
+	}
+}
+
+// args returns the command line the service manager runs
+func (s *Service) args() []string {
+	return []string{s.Executable, "daemon", "run", "--log-file", s.LogFile}
+}
+
+var launchdTemplate = template.Must(template.New("plist").Funcs(template.FuncMap{"xml": xmlEscape}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>{{xml .Label}}</string>
+	<key>ProgramArguments</key>
+	<array>
+{{- range .Args}}
+		<string>{{xml .}}</string>
+{{- end}}
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<true/>
+	<key>StandardOutPath</key>
+	<string>{{xml .LogFile}}</string>
+	<key>StandardErrorPath</key>
+	<string>{{xml .LogFile}}</string>
+</dict>
+</plist>
+`))
+
+// launchdPlist renders the launchd agent definition
+func (s *Service) launchdPlist() (string, error) {
+	var buf bytes.Buffer
+	err := launchdTemplate.Execute(&buf, struct {
+		Label   string
+		Args    []string
+		LogFile string
+	}{launchdLabel, s.args(), s.LogFile})
+	if err != nil {
+		return "", fmt.Errorf("error rendering launchd plist: %v", err)
+	}
+	return buf.String(), nil
+}
+
+// xmlEscape escapes text for use inside a plist string element
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// systemdUnit renders the systemd user unit
+func (s *Service) systemdUnit() string {
+	quoted := make([]string, 0, len(s.args()))
+	for _, arg := range s.args() {
+		quoted = append(quoted, systemdQuote(arg))
+	}
+
+	return fmt.Sprintf(`[Unit]
+Description=Pomodoro timer daemon
+
+[Service]
+ExecStart=%s
+Restart=on-failure
+RestartSec=5
+
+[Install]
+WantedBy=default.target
+`, strings.Join(quoted, " "))
+}
+
+// systemdQuote quotes a command line argument for an ExecStart line
+func systemdQuote(arg string) string {
+	if !strings.ContainsAny(arg, " \t\"'\\") {
+		return arg
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+}
+
+// taskCommand renders the command line for a Windows scheduled task
+func (s *Service) taskCommand() string {
+	quoted := make([]string, 0, len(s.args()))
+	for _, arg := range s.args() {
+		if strings.ContainsAny(arg, " \t") {
+			arg = `"` + arg + `"`
+		}
+		quoted = append(quoted, arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// writeServiceFile writes a service definition, creating its directory
+func writeServiceFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("error creating service directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		return fmt.Errorf("error writing service file: %v", err)
+	}
+	return nil
+}
+
+// ensureLogDir creates the directory holding the daemon log
+func (s *Service) ensureLogDir() error {
+	if err := os.MkdirAll(filepath.Dir(s.LogFile), 0750); err != nil {
+		return fmt.Errorf("error creating log directory: %v", err)
+	}
+	return nil
+}
+
+// runCommand runs a service manager command, including its output in errors
+func runCommand(name string, args ...string) (string, error) {
+	out, err := exec.Command(name, args...).CombinedOutput() // #nosec G204 - service manager commands with fixed names
+	if err != nil {
+		return string(out), fmt.Errorf("%s %s failed: %v: %s",
+			name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+// fileExists reports whether path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,81 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// servicePath returns the launchd agent plist location
+func servicePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error getting home dir: %v", err)
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", launchdLabel+".plist"), nil
+}
+
+// Install writes the launchd agent and loads it so the daemon starts now and at login
+func (s *Service) Install() error {
+	path, err := servicePath()
+	if err != nil {
+		return err
+	}
+	if err := s.ensureLogDir(); err != nil {
+		return err
+	}
+
+	plist, err := s.launchdPlist()
+	if err != nil {
+		return err
+	}
+
+	// Unload any previous version so launchd picks up the new definition
+	if fileExists(path) {
+		_, _ = runCommand("launchctl", "unload", path)
+	}
+	if err := writeServiceFile(path, plist); err != nil {
+		return err
+	}
+
+	_, err = runCommand("launchctl", "load", "-w", path)
+	return err
+}
+
+// Uninstall unloads the launchd agent and removes its plist
+func (s *Service) Uninstall() error {
+	path, err := servicePath()
+	if err != nil {
+		return err
+	}
+	if !fileExists(path) {
+		return nil
+	}
+
+	if _, err := runCommand("launchctl", "unload", "-w", path); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("error removing service file: %v", err)
+	}
+	return nil
+}
+
+// Status reports whether the launchd agent is installed and running
+func (s *Service) Status() (*ServiceStatus, error) {
+	path, err := servicePath()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &ServiceStatus{
+		Installed: fileExists(path),
+		Location:  path,
+		LogFile:   s.LogFile,
+	}
+	if out, err := runCommand("launchctl", "list", launchdLabel); err == nil {
+		status.Running = strings.Contains(out, `"PID"`)
+	}
+	return status, nil
+}
@@ -0,0 +1,79 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// servicePath returns the systemd user unit location
+func servicePath() (string, error) {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("error getting home dir: %v", err)
+		}
+		configDir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configDir, "systemd", "user", systemdUnitName), nil
+}
+
+// Install writes the systemd user unit and enables it so the daemon starts now and at login
+func (s *Service) Install() error {
+	path, err := servicePath()
+	if err != nil {
+		return err
+	}
+	if err := s.ensureLogDir(); err != nil {
+		return err
+	}
+	if err := writeServiceFile(path, s.systemdUnit()); err != nil {
+		return err
+	}
+
+	if _, err := runCommand("systemctl", "--user", "daemon-reload"); err != nil {
+		return err
+	}
+	_, err = runCommand("systemctl", "--user", "enable", "--now", systemdUnitName)
+	return err
+}
+
+// Uninstall stops and disables the systemd user unit and removes it
+func (s *Service) Uninstall() error {
+	path, err := servicePath()
+	if err != nil {
+		return err
+	}
+	if !fileExists(path) {
+		return nil
+	}
+
+	if _, err := runCommand("systemctl", "--user", "disable", "--now", systemdUnitName); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("error removing service file: %v", err)
+	}
+	_, err = runCommand("systemctl", "--user", "daemon-reload")
+	return err
+}
+
+// Status reports whether the systemd user unit is installed and active
+func (s *Service) Status() (*ServiceStatus, error) {
+	path, err := servicePath()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &ServiceStatus{
+		Installed: fileExists(path),
+		Location:  path,
+		LogFile:   s.LogFile,
+	}
+	// is-active exits non-zero for inactive units, so only the output matters
+	out, _ := runCommand("systemctl", "--user", "is-active", systemdUnitName)
+	status.Running = strings.TrimSpace(out) == "active"
+	return status, nil
+}
@@ -0,0 +1,26 @@
+//go:build !darwin && !linux && !windows
+
+package daemon
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// errUnsupported is returned on platforms without a supported service manager
+var errUnsupported = fmt.Errorf("daemon service installation is not supported on %s; run `pomodoro daemon run` from your login scripts instead", runtime.GOOS)
+
+// Install is not supported on this platform
+func (s *Service) Install() error {
+	return errUnsupported
+}
+
+// Uninstall is not supported on this platform
+func (s *Service) Uninstall() error {
+	return errUnsupported
+}
+
+// Status is not supported on this platform
+func (s *Service) Status() (*ServiceStatus, error) {
+	return nil, errUnsupported
+}
@@ -0,0 +1,50 @@
+package daemon
+
+import (
+	"strings"
+)
+
+// Install registers a scheduled task that starts the daemon at logon, and starts it now
+func (s *Service) Install() error {
+	if err := s.ensureLogDir(); err != nil {
+		return err
+	}
+
+	if _, err := runCommand("schtasks", "/Create", "/F",
+		"/SC", "ONLOGON",
+		"/RL", "LIMITED",
+		"/TN", windowsTaskName,
+		"/TR", s.taskCommand()); err != nil {
+		return err
+	}
+	_, err := runCommand("schtasks", "/Run", "/TN", windowsTaskName)
+	return err
+}
+
+// Uninstall stops the daemon and deletes its scheduled task
+func (s *Service) Uninstall() error {
+	if _, err := runCommand("schtasks", "/Query", "/TN", windowsTaskName); err != nil {
+		return nil // not installed
+	}
+
+	// Ending a task that is not running fails harmlessly
+	_, _ = runCommand("schtasks", "/End", "/TN", windowsTaskName)
+	_, err := runCommand("schtasks", "/Delete", "/F", "/TN", windowsTaskName)
+	return err
+}
+
+// Status reports whether the scheduled task exists and is running
+func (s *Service) Status() (*ServiceStatus, error) {
+	status := &ServiceStatus{
+		Location: windowsTaskName,
+		LogFile:  s.LogFile,
+	}
+
+	out, err := runCommand("schtasks", "/Query", "/TN", windowsTaskName, "/FO", "LIST")
+	if err != nil {
+		return status, nil
+	}
+	status.Installed = true
+	status.Running = strings.Contains(out, "Running")
+	return status, nil
+}
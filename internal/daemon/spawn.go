@@ -0,0 +1,29 @@
+package daemon
+
+import (
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// SpawnIdleExit is how long a daemon started on demand waits for another
+// session before exiting
+const SpawnIdleExit = 5 * time.Minute
+
+// Spawn starts "pomodoro daemon run" detached from the terminal, so timers
+// keep running after the CLI exits. The spawned daemon watches database, or
+// the configured one when it is "", logs to logFile, and exits once it has
+// been idle for SpawnIdleExit.
+func Spawn(executable, logFile, database string) error {
+	args := []string{"daemon", "run", "--log-file", logFile, "--exit-when-idle", SpawnIdleExit.String()}
+	if database != "" {
+		args = append(args, "--db", database)
+	}
+	cmd := exec.Command(executable, args...) // #nosec G204 - runs this program's own executable
+	cmd.SysProcAttr = detachAttr()
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("error starting daemon: %v", err)
+	}
+	return cmd.Process.Release()
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Annotation is a note appended to a session after the fact, for example by
+// a hook recording a CI result. Annotations are append-only.
+type Annotation struct {
+	ID        int64     `json:"id"`
+	SessionID int64     `json:"session_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Source    string    `json:"source"`
+	Text      string    `json:"text"`
+}
+
+// AddAnnotation appends an annotation to a session
+func (d *InternalDB) AddAnnotation(sessionID int64, source, text string) (int64, error) {
+	res, err := d.db.Exec(
+		`INSERT INTO session_annotations(session_id, created_at, source, text) VALUES(?, ?, ?, ?)`,
+		sessionID, time.Now(), source, text,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error saving annotation: %v", err)
+	}
+	return res.LastInsertId()
+}
+
+// GetAnnotations retrieves a session's annotations, oldest first
+func (d *InternalDB) GetAnnotations(sessionID int64) ([]Annotation, error) {
+	rows, err := d.db.Query(
+		`SELECT id, session_id, created_at, source, text
+		FROM session_annotations
+		WHERE session_id = ?
+		ORDER BY id ASC`,
+		sessionID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying annotations: %v", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing rows: %v\n", err)
+		}
+	}()
+
+	var annotations []Annotation
+	for rows.Next() {
+		var a Annotation
+		if err := rows.Scan(&a.ID, &a.SessionID, &a.CreatedAt, &a.Source, &a.Text); err != nil {
+			return nil, fmt.Errorf("error scanning annotation: %v", err)
+		}
+		annotations = append(annotations, a)
+	}
+
+	return annotations, rows.Err()
+}
@@ -0,0 +1,34 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAnnotationsAreAppendOnly(t *testing.T) {
+	database := newTestDB(t)
+
+	start := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	sessionID, err := database.CreateSession(start, start.Add(25*time.Minute), "Task", 1500, "", false)
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+
+	for _, text := range []string{"CI passed", "PR merged"} {
+		if _, err := database.AddAnnotation(sessionID, "ci", text); err != nil {
+			t.Fatalf("Failed to add annotation: %v", err)
+		}
+	}
+
+	annotations, err := database.GetAnnotations(sessionID)
+	if err != nil {
+		t.Fatalf("Failed to get annotations: %v", err)
+	}
+	if len(annotations) != 2 || annotations[0].Text != "CI passed" || annotations[1].Source != "ci" {
+		t.Errorf("Unexpected annotations: %+v", annotations)
+	}
+
+	if _, err := database.db.Exec(`UPDATE session_annotations SET text = 'edited'`); err == nil {
+		t.Error("Expected updating an annotation to fail")
+	}
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+)
+
+// APIAuditEntry records one request that tried to change something through
+// the REST API
+type APIAuditEntry struct {
+	ID        int64
+	At        time.Time
+	Client    string // Name of the token used, or how the client was let in
+	Method    string
+	Path      string
+	Status    int   // HTTP status of the response
+	SessionID int64 // Session the request acted on, 0 for none
+	Remote    string
+}
+
+// APIAuditFilter selects audit entries; zero fields match everything
+type APIAuditFilter struct {
+	Client string
+	Since  time.Time
+	Limit  int
+}
+
+// RecordAPIAudit adds an entry to the API audit log
+func (d *InternalDB) RecordAPIAudit(entry APIAuditEntry) error {
+	var sessionID sql.NullInt64
+	if entry.SessionID != 0 {
+		sessionID = sql.NullInt64{Int64: entry.SessionID, Valid: true}
+	}
+	if _, err := d.db.Exec(
+		`INSERT INTO api_audit(at, client, method, path, status, session_id, remote) VALUES(?, ?, ?, ?, ?, ?, ?)`,
+		entry.At, entry.Client, entry.Method, entry.Path, entry.Status, sessionID, entry.Remote,
+	); err != nil {
+		return fmt.Errorf("error recording API audit entry: %v", err)
+	}
+	return nil
+}
+
+// ListAPIAudit retrieves audit entries matching filter, newest first
+func (d *InternalDB) ListAPIAudit(filter APIAuditFilter) ([]APIAuditEntry, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1 // No limit
+	}
+	rows, err := d.db.Query(
+		`SELECT id, at, client, method, path, status, session_id, remote FROM api_audit
+		WHERE (? = '' OR client = ?) AND julianday(at) >= julianday(?)
+		ORDER BY at DESC, id DESC LIMIT ?`,
+		filter.Client, filter.Client, filter.Since, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying API audit log: %v", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing rows: %v\n", err)
+		}
+	}()
+
+	var entries []APIAuditEntry
+	for rows.Next() {
+		var e APIAuditEntry
+		var sessionID sql.NullInt64
+		if err := rows.Scan(&e.ID, &e.At, &e.Client, &e.Method, &e.Path, &e.Status, &sessionID, &e.Remote); err != nil {
+			return nil, fmt.Errorf("error scanning API audit entry: %v", err)
+		}
+		e.SessionID = sessionID.Int64
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// benchSessions is the size of the seeded history, overridable with
+// POMODORO_BENCH_SESSIONS for load testing (make bench-large)
+func benchSessions(b *testing.B) int {
+	if s := os.Getenv("POMODORO_BENCH_SESSIONS"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			b.Fatalf("Invalid POMODORO_BENCH_SESSIONS %q", s)
+		}
+		return n
+	}
+	return 10000
+}
+
+// newBenchDB opens a fresh database seeded with synthetic history ending today
+func newBenchDB(b *testing.B) *InternalDB {
+	b.Helper()
+	b.Setenv("HOME", b.TempDir())
+
+	database, err := NewDB("")
+	if err != nil {
+		b.Fatalf("Failed to open database: %v", err)
+	}
+	b.Cleanup(func() { _ = database.Close() })
+
+	if _, err := database.Seed(SeedOptions{Sessions: benchSessions(b), End: time.Now().AddDate(0, 0, 1), Seed: 1}); err != nil {
+		b.Fatalf("Failed to seed database: %v", err)
+	}
+	b.ResetTimer()
+	return database
+}
+
+func BenchmarkCreateSession(b *testing.B) {
+	database := newBenchDB(b)
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		if _, err := database.CreateSession(start, start.Add(25*time.Minute), "Bench", 1500, "work", false); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetSessionsByDateRange(b *testing.B) {
+	database := newBenchDB(b)
+	now := time.Now()
+	for _, r := range []struct {
+		name string
+		days int
+	}{{"day", 0}, {"week", 7}, {"month", 30}, {"year", 365}} {
+		b.Run(r.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := database.GetSessionsByDateRange(now.AddDate(0, 0, -r.days), now); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGetActiveSession(b *testing.B) {
+	database := newBenchDB(b)
+	for i := 0; i < b.N; i++ {
+		if _, err := database.GetActiveSession(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetRecentSessions(b *testing.B) {
+	database := newBenchDB(b)
+	for i := 0; i < b.N; i++ {
+		if _, err := database.GetRecentSessions(10, false); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetMetadataByDateRange(b *testing.B) {
+	database := newBenchDB(b)
+	now := time.Now()
+	for i := 0; i < b.N; i++ {
+		if _, err := database.GetMetadataByDateRange(MetaEnergyStart, now.AddDate(0, 0, -90), now); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkResolveSession(b *testing.B) {
+	database := newBenchDB(b)
+	recent, err := database.GetRecentSessions(1, true)
+	if err != nil || len(recent) == 0 {
+		b.Fatalf("Failed to load a session: %v", err)
+	}
+	s := recent[0]
+	day := s.StartTime.In(s.Location()).Format("2006-01-02")
+
+	for _, ref := range []string{strconv.FormatInt(s.ID, 10), "#" + s.UID[:6], day + ".1"} {
+		b.Run(ref, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := database.ResolveSession(ref); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGetSessionStats(b *testing.B) {
+	database := newBenchDB(b)
+	now := time.Now()
+	for i := 0; i < b.N; i++ {
+		if _, err := database.GetSessionStats(now.AddDate(0, 0, -30), now); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := database.GetTagStats(now.AddDate(0, 0, -30), now); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := database.GetHourlyStats(now.AddDate(0, 0, -30), now); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
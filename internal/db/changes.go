@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ChangeWatcher tells whether the database has changed since it last
+// looked, whether by this process or another, without reading any tables.
+// It keeps a connection of its own, since SQLite only counts the changes
+// made by other connections than the one asking.
+type ChangeWatcher struct {
+	conn    *sql.Conn
+	version int64
+}
+
+// WatchChanges starts watching the database for changes. The watcher must
+// be closed before the database is.
+func (d *InternalDB) WatchChanges() (*ChangeWatcher, error) {
+	if d.path == "" {
+		return nil, errors.New("an in-memory database cannot be watched for changes")
+	}
+	conn, err := d.db.Conn(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("error opening connection to watch for changes: %v", err)
+	}
+	w := &ChangeWatcher{conn: conn}
+	if w.version, err = w.dataVersion(); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Changed reports whether anything was committed to the database since the
+// watcher was started or last reported a change
+func (w *ChangeWatcher) Changed() (bool, error) {
+	version, err := w.dataVersion()
+	if err != nil {
+		return false, err
+	}
+	if version == w.version {
+		return false, nil
+	}
+	w.version = version
+	return true, nil
+}
+
+// dataVersion returns SQLite's count of changes committed by other
+// connections, which lives in shared memory and costs no disk reads
+func (w *ChangeWatcher) dataVersion() (int64, error) {
+	var version int64
+	if err := w.conn.QueryRowContext(context.Background(), `PRAGMA data_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("error checking for database changes: %v", err)
+	}
+	return version, nil
+}
+
+// Close releases the watcher's connection
+func (w *ChangeWatcher) Close() error {
+	return w.conn.Close()
+}
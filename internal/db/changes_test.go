@@ -0,0 +1,58 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChangeWatcher(t *testing.T) {
+	database := newTestDB(t)
+	watcher, err := database.WatchChanges()
+	if err != nil {
+		t.Fatalf("WatchChanges failed: %v", err)
+	}
+	defer func() { _ = watcher.Close() }()
+
+	changed := func() bool {
+		t.Helper()
+		changed, err := watcher.Changed()
+		if err != nil {
+			t.Fatalf("Changed failed: %v", err)
+		}
+		return changed
+	}
+
+	if changed() {
+		t.Error("Changed reported a change before any")
+	}
+	if _, err := database.GetActiveSession(); err != nil {
+		t.Fatal(err)
+	}
+	if changed() {
+		t.Error("Changed reported a read as a change")
+	}
+
+	start := time.Now()
+	if _, err := database.CreateSession(start, start.Add(25*time.Minute), "Work", 25*60, "", false); err != nil {
+		t.Fatal(err)
+	}
+	if !changed() {
+		t.Error("Changed missed a new session")
+	}
+	if changed() {
+		t.Error("Changed reported the same change twice")
+	}
+
+	// Another process writing, as the CLI does while the daemon runs
+	other, err := NewDB(database.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = other.Close() }()
+	if _, err := other.CreateSession(start, start.Add(25*time.Minute), "Work", 25*60, "", false); err != nil {
+		t.Fatal(err)
+	}
+	if !changed() {
+		t.Error("Changed missed a session added by another connection")
+	}
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// LogCounter adds amount to a wellness counter, such as glasses of water,
+// on the day of at. A negative amount takes back an earlier count.
+func (d *InternalDB) LogCounter(name string, amount int, at time.Time) error {
+	if _, err := d.db.Exec(
+		`INSERT INTO counter_log(name, amount, logged_at, day) VALUES(?, ?, ?, ?)`,
+		name, amount, at, dayParam(at),
+	); err != nil {
+		return fmt.Errorf("error logging %s: %v", name, err)
+	}
+	return nil
+}
+
+// GetCounterTotals returns each counter's total from startDate to endDate,
+// inclusive, by the local day the counts were logged on. Totals never go
+// below zero.
+func (d *InternalDB) GetCounterTotals(startDate, endDate time.Time) (map[string]int, error) {
+	rows, err := d.db.Query(
+		`SELECT name, SUM(amount) FROM counter_log
+		WHERE day >= ? AND day <= ?
+		GROUP BY name`,
+		dayParam(startDate), dayParam(endDate),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying counters: %v", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing rows: %v\n", err)
+		}
+	}()
+
+	totals := map[string]int{}
+	for rows.Next() {
+		var name string
+		var total int
+		if err := rows.Scan(&name, &total); err != nil {
+			return nil, fmt.Errorf("error scanning counter: %v", err)
+		}
+		totals[name] = max(0, total)
+	}
+	return totals, rows.Err()
+}
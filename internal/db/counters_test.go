@@ -0,0 +1,49 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCounters(t *testing.T) {
+	database := newTestDB(t)
+	monday := time.Date(2024, 6, 3, 9, 0, 0, 0, time.Local)
+	tuesday := monday.AddDate(0, 0, 1)
+
+	log := func(name string, amount int, at time.Time) {
+		t.Helper()
+		if err := database.LogCounter(name, amount, at); err != nil {
+			t.Fatalf("LogCounter failed: %v", err)
+		}
+	}
+	log("water", 1, monday)
+	log("water", 2, monday.Add(3*time.Hour))
+	log("water", -1, monday.Add(4*time.Hour)) // Taken back
+	log("stretch", 1, monday)
+	log("stretch", -2, monday.Add(time.Hour)) // More than counted
+	log("water", 5, tuesday)
+
+	totals, err := database.GetCounterTotals(monday, monday)
+	if err != nil {
+		t.Fatalf("GetCounterTotals failed: %v", err)
+	}
+	if totals["water"] != 2 || totals["stretch"] != 0 || len(totals) != 2 {
+		t.Errorf("Monday's totals = %v, want water 2 and stretch 0", totals)
+	}
+
+	totals, err = database.GetCounterTotals(monday, tuesday)
+	if err != nil {
+		t.Fatalf("GetCounterTotals failed: %v", err)
+	}
+	if totals["water"] != 7 {
+		t.Errorf("water over both days = %d, want 7", totals["water"])
+	}
+
+	totals, err = database.GetCounterTotals(tuesday.AddDate(0, 0, 1), tuesday.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("GetCounterTotals failed: %v", err)
+	}
+	if len(totals) != 0 {
+		t.Errorf("totals on a day without counts = %v, want none", totals)
+	}
+}
@@ -16,6 +16,11 @@ var _ DB = (*InternalDB)(nil)
 // InternalDB implements the DB interface using SQLite
 type InternalDB struct {
 	db *sql.DB
+	// path is the database file, empty when it is in memory
+	path string
+	// existed is set when the file had a database in it before being
+	// opened, so migrating it snapshots it first if it holds anything
+	existed bool
 }
 
 // DB defines the interface for database operations
@@ -24,11 +29,54 @@ type DB interface {
 	GetActiveSession() (*PomodoroSession, error)
 	GetPausedSession() (*PomodoroSession, error)
 	GetLastSession() (*PomodoroSession, error)
+	GetSessionByID(id int64) (*PomodoroSession, error)
+	GetRecentSessions(limit int, includeBreaks bool) ([]PomodoroSession, error)
+	ResolveSession(ref string) (*PomodoroSession, error)
+	SetSessionMetadata(id int64, key, value string) error
+	GetSessionMetadata(id int64) (map[string]string, error)
+	GetMetadataByDateRange(key string, startDate, endDate time.Time) (map[int64]string, error)
+	AddAnnotation(sessionID int64, source, text string) (int64, error)
+	GetAnnotations(sessionID int64) ([]Annotation, error)
+	GetPauses(sessionID int64) ([]Pause, error)
 	UpdateSessionEndTime(id int64, endTime time.Time) error
+	ExtendSession(id int64, by time.Duration) error
+	EndSession(id int64, endedAt time.Time, status string) error
+	SetSessionStatus(id int64, status string) error
+	EditSession(id int64, e SessionEdit) error
+	DeleteSession(id int64) error
 	PauseSession(id int64, pausedAt time.Time) error
-	ResumeSession(id int64, newEndTime time.Time) error
-	GetSessionsByDateRange(startDate, endDate time.Time) ([]PomodoroSession, error)
+	ResumeSession(id int64, resumedAt time.Time) error
+	GetSessionsByDateRange(startDate, endDate time.Time, tags ...string) ([]PomodoroSession, error)
+	SearchSessions(opts SearchOptions) ([]PomodoroSession, error)
 	GetTodaySessions() ([]PomodoroSession, error)
+	GetSessionStats(startDate, endDate time.Time) (*SessionStats, error)
+	GetTagStats(startDate, endDate time.Time) ([]TagStats, error)
+	GetAllTags() ([]TagUse, error)
+	GetHourlyStats(startDate, endDate time.Time) ([]HourStats, error)
+	GetCycleCount() (int, error)
+	AddTask(title string, estimate int) (int64, error)
+	GetTask(id int64) (*Task, error)
+	ListTasks(includeDone bool) ([]Task, error)
+	CompleteTask(id int64, doneAt time.Time) error
+	SetTaskSource(id int64, source string) error
+	SetSessionTask(sessionID, taskID int64) error
+	GetTaskSessions(taskID int64) ([]PomodoroSession, error)
+	GetTaskStats(startDate, endDate time.Time) ([]TaskStats, error)
+	LogCounter(name string, amount int, at time.Time) error
+	GetCounterTotals(startDate, endDate time.Time) (map[string]int, error)
+	RecordDailySummary(day time.Time, goal int) (*DailySummary, error)
+	GetDailySummaries() ([]DailySummary, error)
+	AddVacation(from, to time.Time) (int64, error)
+	GetVacations() ([]Vacation, error)
+	DeleteVacation(id int64) error
+	RecordTemplateUse(name string, at time.Time) error
+	GetTemplateUsage() (map[string]TemplateUsage, error)
+	CreateAPIToken(name, scope string) (string, error)
+	ListAPITokens(includeRevoked bool) ([]APIToken, error)
+	RevokeAPIToken(name string) error
+	AuthenticateAPIToken(secret string, usedAt time.Time) (*APIToken, error)
+	RecordAPIAudit(entry APIAuditEntry) error
+	ListAPIAudit(filter APIAuditFilter) ([]APIAuditEntry, error)
 	Close() error
 }
 
@@ -45,24 +93,137 @@ type PomodoroSession struct {
 	PausedAt            *time.Time
 	TotalPausedDuration int64
 	IsPaused            bool
+	UID                 string
+	TZOffset            int    // Seconds east of UTC where the session was recorded
+	TZName              string // Zone abbreviation where the session was recorded, e.g. "CEST"
+	TaskID              int64  // Linked task, 0 when the session has none
+	Status              string // How the session ended, "" while it runs
 }
 
-// NewDB creates a new database connection and initializes the schema
-func NewDB() (*InternalDB, error) {
-	home, err := os.UserHomeDir()
+// Ways a session can end
+const (
+	StatusCompleted = "completed" // Ran its full length, or was finished early on purpose
+	StatusCancelled = "cancelled" // Stopped with cancel
+	StatusAbandoned = "abandoned" // Cut short without anyone stopping it, as by a screen lock
+)
+
+// Incomplete reports whether the session was cancelled or abandoned, so it
+// does not count toward goals
+func (s PomodoroSession) Incomplete() bool {
+	return s.Status == StatusCancelled || s.Status == StatusAbandoned
+}
+
+// sessionColumns lists the pomodoros columns read into a PomodoroSession
+const sessionColumns = `id, start_time, end_time, description, duration_secs, tags_csv, was_break,
+	paused_at, total_paused_duration, is_paused, COALESCE(uid, ''),
+	COALESCE(tz_offset, 0), COALESCE(tz_name, ''), COALESCE(task_id, 0), COALESCE(status, '')`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanSession reads a row selected with sessionColumns into a PomodoroSession
+func scanSession(row rowScanner) (*PomodoroSession, error) {
+	var session PomodoroSession
+	err := row.Scan(
+		&session.ID,
+		&session.StartTime,
+		&session.EndTime,
+		&session.Description,
+		&session.DurationSec,
+		&session.TagsCSV,
+		&session.WasBreak,
+		&session.PausedAt,
+		&session.TotalPausedDuration,
+		&session.IsPaused,
+		&session.UID,
+		&session.TZOffset,
+		&session.TZName,
+		&session.TaskID,
+		&session.Status,
+	)
 	if err != nil {
-		return nil, fmt.Errorf("error getting home dir: %v", err)
+		return nil, err
 	}
+	return &session, nil
+}
 
-	dbPath := filepath.Join(home, ".local", "share", "pomodoro", "history.db")
-	if err := os.MkdirAll(filepath.Dir(dbPath), 0750); err != nil {
-		return nil, fmt.Errorf("error creating DB dir: %v", err)
+// DefaultPath returns the location of the session database
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error getting home dir: %v", err)
 	}
+	return filepath.Join(home, ".local", "share", "pomodoro", "history.db"), nil
+}
 
-	db, err := sql.Open("sqlite3", dbPath+"?_journal_mode=WAL")
+// inMemory makes NewDB use a throwaway in-memory database, set by safe mode
+// when the database file cannot be used
+var inMemory bool
+
+// UseInMemory makes later NewDB calls open an empty in-memory database.
+// Nothing written to it outlives the process.
+func UseInMemory() {
+	inMemory = true
+}
+
+// NewDB opens the database at path, creating it and its directory when
+// needed, migrates the schema, and settles sessions that ran out while
+// nothing was watching. An empty path opens DefaultPath.
+func NewDB(path string) (*InternalDB, error) {
+	d, err := Open(path)
 	if err != nil {
-		return nil, fmt.Errorf("error opening DB: %v", err)
+		return nil, err
+	}
+	err = d.Migrate()
+	if err == nil {
+		err = d.settleSessions()
+	}
+	if err != nil {
+		if closeErr := d.Close(); closeErr != nil {
+			return nil, fmt.Errorf("%v (failed to close: %v)", err, closeErr)
+		}
+		return nil, err
 	}
+	return d, nil
+}
+
+// Open opens the database at path like NewDB, but leaves migrating it to
+// Migrate or MigrateTo, so its schema can be looked at first
+func Open(path string) (*InternalDB, error) {
+	d := &InternalDB{}
+	var db *sql.DB
+	if inMemory {
+		var err error
+		db, err = sql.Open(driverName(), ":memory:")
+		if err != nil {
+			return nil, fmt.Errorf("error opening DB: %v", err)
+		}
+		// Every connection to :memory: is a separate database
+		db.SetMaxOpenConns(1)
+	} else {
+		var err error
+		dbPath := path
+		if dbPath == "" {
+			if dbPath, err = DefaultPath(); err != nil {
+				return nil, err
+			}
+		}
+		d.path = dbPath
+		if info, err := os.Stat(dbPath); err == nil && info.Size() > 0 {
+			d.existed = true
+		}
+		if err := os.MkdirAll(filepath.Dir(dbPath), 0750); err != nil {
+			return nil, fmt.Errorf("error creating DB dir: %v", err)
+		}
+
+		db, err = sql.Open(driverName(), dbPath+"?_journal_mode=WAL")
+		if err != nil {
+			return nil, fmt.Errorf("error opening DB: %v", err)
+		}
+	}
+	d.db = db
 
 	// Create base table
 	ddl := `CREATE TABLE IF NOT EXISTS pomodoros (
@@ -83,20 +244,14 @@ func NewDB() (*InternalDB, error) {
 		return nil, fmt.Errorf("error creating base table: %v", err)
 	}
 
-	// Add new columns if they don't exist (for database migration)
-	migrations := []string{
-		`ALTER TABLE pomodoros ADD COLUMN paused_at TIMESTAMP;`,
-		`ALTER TABLE pomodoros ADD COLUMN total_paused_duration INTEGER DEFAULT 0;`,
-		`ALTER TABLE pomodoros ADD COLUMN is_paused BOOLEAN DEFAULT 0;`,
-		`CREATE INDEX IF NOT EXISTS idx_pomodoros_active ON pomodoros(is_paused, end_time);`,
-	}
-
-	for _, migration := range migrations {
-		// Ignore errors for columns that already exist
-		_, _ = db.Exec(migration) // Ignore errors for columns that already exist
+	if err := startLog(db); err != nil {
+		if closeErr := db.Close(); closeErr != nil {
+			return nil, fmt.Errorf("%v (failed to close: %v)", err, closeErr)
+		}
+		return nil, err
 	}
 
-	return &InternalDB{db: db}, nil
+	return d, nil
 }
 
 // Close closes the database connection
@@ -106,41 +261,54 @@ func (d *InternalDB) Close() error {
 
 // CreateSession creates a new session record in the database
 func (d *InternalDB) CreateSession(startTime, endTime time.Time, description string, durationSec int64, tagsCSV string, wasBreak bool) (int64, error) {
-	res, err := d.db.Exec(
-		`INSERT INTO pomodoros(start_time, end_time, description, duration_secs, tags_csv, was_break) VALUES(?, ?, ?, ?, ?, ?)`,
-		startTime, endTime, description, durationSec, tagsCSV, wasBreak,
+	uid, err := newUID()
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	tzName, tzOffset := startTime.Zone()
+	res, err := tx.Exec(
+		`INSERT INTO pomodoros(start_time, end_time, description, duration_secs, tags_csv, was_break, uid, tz_offset, tz_name)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		startTime, endTime, description, durationSec, tagsCSV, wasBreak, uid, tzOffset, tzName,
 	)
 	if err != nil {
 		return 0, fmt.Errorf("error inserting record: %v", err)
 	}
-
-	return res.LastInsertId()
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("error inserting record: %v", err)
+	}
+	if err := setSessionTags(tx, id, tagsCSV); err != nil {
+		return 0, err
+	}
+	// A session logged after the fact counts toward its day at once
+	if err := refreshSessionSummary(tx, id); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error inserting record: %v", err)
+	}
+	return id, nil
 }
 
 // GetActiveSession retrieves the currently active session if one exists
 func (d *InternalDB) GetActiveSession() (*PomodoroSession, error) {
 	now := time.Now()
 
-	var session PomodoroSession
-	err := d.db.QueryRow(
-		`SELECT id, start_time, end_time, description, duration_secs, tags_csv, was_break, 
-		        paused_at, total_paused_duration, is_paused 
+	session, err := scanSession(d.db.QueryRow(
+		`SELECT `+sessionColumns+`
 		FROM pomodoros 
-		WHERE (end_time > ? AND is_paused = 0) OR is_paused = 1
-		ORDER BY start_time DESC LIMIT 1`,
+		WHERE (julianday(end_time) > julianday(?) AND is_paused = 0) OR is_paused = 1
+		ORDER BY julianday(start_time) DESC LIMIT 1`,
 		now,
-	).Scan(
-		&session.ID,
-		&session.StartTime,
-		&session.EndTime,
-		&session.Description,
-		&session.DurationSec,
-		&session.TagsCSV,
-		&session.WasBreak,
-		&session.PausedAt,
-		&session.TotalPausedDuration,
-		&session.IsPaused,
-	)
+	))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -149,30 +317,17 @@ func (d *InternalDB) GetActiveSession() (*PomodoroSession, error) {
 		return nil, fmt.Errorf("error querying active session: %v", err)
 	}
 
-	return &session, nil
+	return session, nil
 }
 
 // GetPausedSession retrieves the most recently paused session
 func (d *InternalDB) GetPausedSession() (*PomodoroSession, error) {
-	var session PomodoroSession
-	err := d.db.QueryRow(
-		`SELECT id, start_time, end_time, description, duration_secs, tags_csv, was_break, 
-		        paused_at, total_paused_duration, is_paused 
+	session, err := scanSession(d.db.QueryRow(
+		`SELECT ` + sessionColumns + `
 		FROM pomodoros 
 		WHERE is_paused = 1
-		ORDER BY start_time DESC LIMIT 1`,
-	).Scan(
-		&session.ID,
-		&session.StartTime,
-		&session.EndTime,
-		&session.Description,
-		&session.DurationSec,
-		&session.TagsCSV,
-		&session.WasBreak,
-		&session.PausedAt,
-		&session.TotalPausedDuration,
-		&session.IsPaused,
-	)
+		ORDER BY julianday(start_time) DESC LIMIT 1`,
+	))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -181,29 +336,16 @@ func (d *InternalDB) GetPausedSession() (*PomodoroSession, error) {
 		return nil, fmt.Errorf("error querying paused session: %v", err)
 	}
 
-	return &session, nil
+	return session, nil
 }
 
 // GetLastSession retrieves the most recent session regardless of status
 func (d *InternalDB) GetLastSession() (*PomodoroSession, error) {
-	var session PomodoroSession
-	err := d.db.QueryRow(
-		`SELECT id, start_time, end_time, description, duration_secs, tags_csv, was_break,
-		        paused_at, total_paused_duration, is_paused
+	session, err := scanSession(d.db.QueryRow(
+		`SELECT ` + sessionColumns + `
 		FROM pomodoros 
-		ORDER BY start_time DESC LIMIT 1`,
-	).Scan(
-		&session.ID,
-		&session.StartTime,
-		&session.EndTime,
-		&session.Description,
-		&session.DurationSec,
-		&session.TagsCSV,
-		&session.WasBreak,
-		&session.PausedAt,
-		&session.TotalPausedDuration,
-		&session.IsPaused,
-	)
+		ORDER BY julianday(start_time) DESC LIMIT 1`,
+	))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -212,16 +354,176 @@ func (d *InternalDB) GetLastSession() (*PomodoroSession, error) {
 		return nil, fmt.Errorf("error querying last session: %v", err)
 	}
 
-	return &session, nil
+	return session, nil
 }
 
-// UpdateSessionEndTime updates the end time of a session
+// GetSessionByID retrieves a single session by its ID
+func (d *InternalDB) GetSessionByID(id int64) (*PomodoroSession, error) {
+	session, err := scanSession(d.db.QueryRow(
+		`SELECT `+sessionColumns+`
+		FROM pomodoros
+		WHERE id = ?`,
+		id,
+	))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying session %d: %v", id, err)
+	}
+
+	return session, nil
+}
+
+// GetRecentSessions retrieves the most recent sessions, newest first
+func (d *InternalDB) GetRecentSessions(limit int, includeBreaks bool) ([]PomodoroSession, error) {
+	rows, err := d.db.Query(
+		`SELECT `+sessionColumns+`
+		FROM pomodoros
+		WHERE was_break = 0 OR ?
+		ORDER BY julianday(start_time) DESC LIMIT ?`,
+		includeBreaks, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying recent sessions: %v", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing rows: %v\n", err)
+		}
+	}()
+
+	var sessions []PomodoroSession
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning session: %v", err)
+		}
+		sessions = append(sessions, *session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// UpdateSessionEndTime moves the end of a session, for example to extend it.
+// Use EndSession to stop a session early.
 func (d *InternalDB) UpdateSessionEndTime(id int64, endTime time.Time) error {
 	_, err := d.db.Exec(
 		`UPDATE pomodoros SET end_time = ? WHERE id = ?`,
 		endTime, id,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	if err := refreshSessionSummary(d.db, id); err != nil {
+		return err
+	}
+	return d.checkInvariants(id, endTime)
+}
+
+// ExtendSession lengthens a session by moving its end and its planned
+// duration together. A negative by shortens it.
+func (d *InternalDB) ExtendSession(id int64, by time.Duration) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var endTime time.Time
+	var durationSec int64
+	err = tx.QueryRow(`SELECT end_time, duration_secs FROM pomodoros WHERE id = ?`, id).Scan(&endTime, &durationSec)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("no session with ID %d", id)
+	}
+	if err != nil {
+		return fmt.Errorf("error getting session %d: %v", id, err)
+	}
+
+	endTime = endTime.Add(by)
+	durationSec += int64(by / time.Second)
+	if durationSec <= 0 {
+		return fmt.Errorf("session %d would have no time left", id)
+	}
+	if _, err := tx.Exec(
+		`UPDATE pomodoros SET end_time = ?, duration_secs = ? WHERE id = ?`,
+		endTime, durationSec, id,
+	); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error extending session: %v", err)
+	}
+	return d.checkInvariants(id, endTime)
+}
+
+// EndSession stops a session at endedAt and records how it ended. A paused
+// session's open pause is closed there too, so the time it spent paused
+// never counts as focus.
+func (d *InternalDB) EndSession(id int64, endedAt time.Time, status string) error {
+	if err := validateStatus(status); err != nil {
+		return err
+	}
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	endTime := endedAt
+	var pausedAt *time.Time
+	var totalPausedDuration int64
+	err = tx.QueryRow(
+		`SELECT paused_at, total_paused_duration FROM pomodoros WHERE id = ? AND is_paused = 1`,
+		id,
+	).Scan(&pausedAt, &totalPausedDuration)
+	switch {
+	case err == sql.ErrNoRows:
+		// Not paused, so only the end moves
+	case err != nil:
+		return fmt.Errorf("error getting paused session data: %v", err)
+	case pausedAt != nil:
+		paused := pauseSeconds(*pausedAt, endedAt)
+		if err := closePause(tx, id, totalPausedDuration+paused, endedAt); err != nil {
+			return err
+		}
+		// End the recorded length of the pause after it started, so focus
+		// time stays what it was at the pause
+		endTime = pausedAt.Add(time.Duration(paused) * time.Second)
+	}
+
+	if _, err := tx.Exec(`UPDATE pomodoros SET end_time = ?, status = ? WHERE id = ?`, endTime, status, id); err != nil {
+		return err
+	}
+	if err := refreshSessionSummary(tx, id); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error ending session: %v", err)
+	}
+	return d.checkInvariants(id, endedAt)
+}
+
+// SetSessionStatus records how a session ended, for sessions that ran out
+// on their own
+func (d *InternalDB) SetSessionStatus(id int64, status string) error {
+	if err := validateStatus(status); err != nil {
+		return err
+	}
+	if _, err := d.db.Exec(`UPDATE pomodoros SET status = ? WHERE id = ?`, status, id); err != nil {
+		return fmt.Errorf("error setting status of session %d: %v", id, err)
+	}
+	return refreshSessionSummary(d.db, id)
+}
+
+// validateStatus checks a session status before it is stored
+func validateStatus(status string) error {
+	switch status {
+	case StatusCompleted, StatusCancelled, StatusAbandoned:
+		return nil
+	}
+	return fmt.Errorf("unknown session status %q", status)
 }
 
 // PauseSession marks a session as paused at the specified time
@@ -230,51 +532,103 @@ func (d *InternalDB) PauseSession(id int64, pausedAt time.Time) error {
 		`UPDATE pomodoros SET paused_at = ?, is_paused = 1 WHERE id = ?`,
 		pausedAt, id,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	_, err = d.db.Exec(
+		`INSERT INTO session_pauses(session_id, paused_at) VALUES(?, ?)`,
+		id, pausedAt,
+	)
+	if err != nil {
+		return err
+	}
+	return d.checkInvariants(id, pausedAt)
 }
 
-// ResumeSession resumes a paused session with a new end time
-func (d *InternalDB) ResumeSession(id int64, newEndTime time.Time) error {
-	// First, get the current paused duration
-	var currentPausedAt time.Time
-	var totalPausedDuration int64
+// ResumeSession resumes a paused session at resumedAt. The end time moves
+// back by the length of the pause, so the session still runs for its full
+// planned focus time.
+func (d *InternalDB) ResumeSession(id int64, resumedAt time.Time) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
 
-	err := d.db.QueryRow(
-		`SELECT paused_at, total_paused_duration FROM pomodoros WHERE id = ?`,
+	var pausedAt, endTime time.Time
+	var totalPausedDuration int64
+	err = tx.QueryRow(
+		`SELECT paused_at, end_time, total_paused_duration FROM pomodoros WHERE id = ? AND is_paused = 1`,
 		id,
-	).Scan(&currentPausedAt, &totalPausedDuration)
-
+	).Scan(&pausedAt, &endTime, &totalPausedDuration)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("session %d is not paused", id)
+	}
 	if err != nil {
 		return fmt.Errorf("error getting paused session data: %v", err)
 	}
 
-	// Calculate additional paused time
-	now := time.Now()
-	additionalPausedTime := now.Sub(currentPausedAt)
-	newTotalPausedDuration := totalPausedDuration + int64(additionalPausedTime.Seconds())
+	paused := pauseSeconds(pausedAt, resumedAt)
+	if err := closePause(tx, id, totalPausedDuration+paused, resumedAt); err != nil {
+		return err
+	}
+	newEndTime := endTime.Add(time.Duration(paused) * time.Second)
+	if _, err := tx.Exec(`UPDATE pomodoros SET end_time = ? WHERE id = ?`, newEndTime, id); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error resuming session: %v", err)
+	}
+	return d.checkInvariants(id, resumedAt)
+}
 
-	// Update the session
-	_, err = d.db.Exec(
-		`UPDATE pomodoros SET 
-			end_time = ?, 
-			paused_at = NULL, 
-			total_paused_duration = ?, 
-			is_paused = 0 
-		WHERE id = ?`,
-		newEndTime, newTotalPausedDuration, id,
+// closePause ends a session's open pause at at and records the session's new
+// total paused time
+func closePause(tx *sql.Tx, id, totalPausedDuration int64, at time.Time) error {
+	_, err := tx.Exec(
+		`UPDATE pomodoros SET paused_at = NULL, total_paused_duration = ?, is_paused = 0 WHERE id = ?`,
+		totalPausedDuration, id,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE session_pauses SET resumed_at = ? WHERE session_id = ? AND resumed_at IS NULL`,
+		at, id,
 	)
 	return err
 }
 
-// GetSessionsByDateRange retrieves sessions within the specified date range
-func (d *InternalDB) GetSessionsByDateRange(startDate, endDate time.Time) ([]PomodoroSession, error) {
+// pauseSeconds returns the length of a pause in whole seconds, the unit
+// total_paused_duration is kept in. Partial seconds are dropped so paused
+// time never exceeds the time that passed, and a clock that went backwards
+// counts as no pause at all.
+func pauseSeconds(pausedAt, resumedAt time.Time) int64 {
+	d := resumedAt.Sub(pausedAt)
+	if d < 0 {
+		return 0
+	}
+	return int64(d / time.Second)
+}
+
+// GetSessionsByDateRange retrieves sessions within the specified date range,
+// newest first. Given tags, only sessions with any of them are retrieved.
+func (d *InternalDB) GetSessionsByDateRange(startDate, endDate time.Time, tags ...string) ([]PomodoroSession, error) {
+	where := localDay + ` >= ? AND ` + localDay + ` <= ?`
+	params := []any{dayParam(startDate), dayParam(endDate)}
+	if len(tags) > 0 {
+		tagged, tagParams := taggedWith(tags)
+		where += ` AND ` + tagged
+		params = append(params, tagParams...)
+	}
 	rows, err := d.db.Query(
-		`SELECT id, start_time, end_time, description, duration_secs, tags_csv, was_break,
-		        paused_at, total_paused_duration, is_paused
+		`SELECT `+sessionColumns+`
 		FROM pomodoros 
-		WHERE date(start_time) >= date(?) AND date(start_time) <= date(?)
-		ORDER BY start_time DESC`,
-		startDate, endDate,
+		WHERE `+where+`
+		ORDER BY julianday(start_time) DESC`,
+		params...,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error querying sessions: %v", err)
@@ -287,22 +641,11 @@ func (d *InternalDB) GetSessionsByDateRange(startDate, endDate time.Time) ([]Pom
 
 	var sessions []PomodoroSession
 	for rows.Next() {
-		var session PomodoroSession
-		if err := rows.Scan(
-			&session.ID,
-			&session.StartTime,
-			&session.EndTime,
-			&session.Description,
-			&session.DurationSec,
-			&session.TagsCSV,
-			&session.WasBreak,
-			&session.PausedAt,
-			&session.TotalPausedDuration,
-			&session.IsPaused,
-		); err != nil {
+		session, err := scanSession(rows)
+		if err != nil {
 			return nil, fmt.Errorf("error scanning session: %v", err)
 		}
-		sessions = append(sessions, session)
+		sessions = append(sessions, *session)
 	}
 
 	return sessions, nil
@@ -310,7 +653,14 @@ func (d *InternalDB) GetSessionsByDateRange(startDate, endDate time.Time) ([]Pom
 
 // GetTodaySessions retrieves all sessions from today
 func (d *InternalDB) GetTodaySessions() ([]PomodoroSession, error) {
-	today := time.Now().Truncate(24 * time.Hour)
-	tomorrow := today.Add(24 * time.Hour)
-	return d.GetSessionsByDateRange(today, tomorrow)
+	today := time.Now()
+	return d.GetSessionsByDateRange(today, today)
+}
+
+// Snapshot writes a consistent copy of the database to path, which must not exist
+func (d *InternalDB) Snapshot(path string) error {
+	if _, err := d.db.Exec(`VACUUM INTO ?`, path); err != nil {
+		return fmt.Errorf("error writing database snapshot: %v", err)
+	}
+	return nil
 }
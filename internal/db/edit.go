@@ -0,0 +1,123 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SessionEdit holds the corrected values of a session. Fields are written
+// as given, so callers start from the session's current values.
+type SessionEdit struct {
+	Description string
+	TagsCSV     string
+	StartTime   time.Time
+	EndTime     time.Time
+}
+
+// EditSession corrects a session's description, tags, and times after the
+// fact. Times can only change once the session has finished, and must
+// still hold every pause the session recorded.
+func (d *InternalDB) EditSession(id int64, e SessionEdit) error {
+	session, err := d.GetSessionByID(id)
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		return fmt.Errorf("no session with ID %d", id)
+	}
+
+	if !e.StartTime.Equal(session.StartTime) || !e.EndTime.Equal(session.EndTime) {
+		if err := d.checkEditedTimes(session, e.StartTime, e.EndTime, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.Exec(
+		`UPDATE pomodoros SET description = ?, tags_csv = ?, start_time = ?, end_time = ? WHERE id = ?`,
+		e.Description, e.TagsCSV, e.StartTime, e.EndTime, id,
+	); err != nil {
+		return fmt.Errorf("error updating session %d: %v", id, err)
+	}
+	if err := setSessionTags(tx, id, e.TagsCSV); err != nil {
+		return err
+	}
+	// New times may move the session to another day
+	if err := refreshSummaries(tx, dayParam(session.StartTime.In(session.Location()))); err != nil {
+		return err
+	}
+	if err := refreshSessionSummary(tx, id); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error updating session %d: %v", id, err)
+	}
+	return d.checkInvariants(id, e.EndTime)
+}
+
+// checkEditedTimes validates new start and end times for a session
+func (d *InternalDB) checkEditedTimes(session *PomodoroSession, start, end, now time.Time) error {
+	switch {
+	case session.IsPaused || session.EndTime.After(now):
+		return errors.New("the session is still running; use extend to change when it ends")
+	case !end.After(start):
+		return errors.New("the end must be after the start")
+	case end.After(now):
+		return errors.New("the end cannot be in the future")
+	case end.Sub(start) < time.Duration(session.TotalPausedDuration)*time.Second:
+		return fmt.Errorf("the session was paused for %s, longer than %s", time.Duration(session.TotalPausedDuration)*time.Second, end.Sub(start))
+	}
+
+	pauses, err := d.GetPauses(session.ID)
+	if err != nil {
+		return err
+	}
+	for _, p := range pauses {
+		if p.PausedAt.Before(start) || (p.ResumedAt != nil && p.ResumedAt.After(end)) {
+			return fmt.Errorf("the session was paused at %s, outside the new times", p.PausedAt.In(session.Location()).Format("15:04"))
+		}
+	}
+	return nil
+}
+
+// DeleteSession removes a session together with its metadata, annotations,
+// and pauses. A session that is still running must be cancelled first.
+func (d *InternalDB) DeleteSession(id int64) error {
+	session, err := d.GetSessionByID(id)
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		return fmt.Errorf("no session with ID %d", id)
+	}
+	if session.IsPaused || session.EndTime.After(time.Now()) {
+		return errors.New("the session is still running; cancel it first")
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, table := range []string{"session_metadata", "session_annotations", "session_pauses", "session_tags"} {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE session_id = ?`, id); err != nil {
+			return fmt.Errorf("error deleting session %d: %v", id, err)
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM pomodoros WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("error deleting session %d: %v", id, err)
+	}
+	if err := refreshSummaries(tx, dayParam(session.StartTime.In(session.Location()))); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error deleting session %d: %v", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEditSession(t *testing.T) {
+	database := newTestDB(t)
+	start := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	id, err := database.CreateSession(start, start.Add(25*time.Minute), "Wrte report", 25*60, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.PauseSession(id, start.Add(10*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.ResumeSession(id, start.Add(15*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	session, err := database.GetSessionByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edit := SessionEdit{
+		Description: "Write report",
+		TagsCSV:     "writing",
+		StartTime:   start.Add(5 * time.Minute),
+		EndTime:     session.EndTime.Add(-5 * time.Minute),
+	}
+	if err := database.EditSession(id, edit); err != nil {
+		t.Fatalf("EditSession failed: %v", err)
+	}
+	edited, err := database.GetSessionByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edited.Description != "Write report" || edited.TagsCSV != "writing" || !edited.StartTime.Equal(edit.StartTime) || !edited.EndTime.Equal(edit.EndTime) {
+		t.Errorf("Expected the edit to be saved, got %+v", edited)
+	}
+
+	bad := map[string]SessionEdit{
+		"end before start":    {StartTime: edit.EndTime, EndTime: edit.StartTime},
+		"pause before start":  {StartTime: start.Add(12 * time.Minute), EndTime: edit.EndTime},
+		"shorter than paused": {StartTime: start.Add(9 * time.Minute), EndTime: start.Add(13 * time.Minute)},
+		"end in the future":   {StartTime: edit.StartTime, EndTime: time.Now().Add(time.Hour)},
+	}
+	for name, e := range bad {
+		if err := database.EditSession(id, e); err == nil {
+			t.Errorf("Expected an edit with %s to be refused", name)
+		}
+	}
+	if err := database.EditSession(id+1, edit); err == nil {
+		t.Error("Expected editing a missing session to fail")
+	}
+}
+
+func TestDeleteSession(t *testing.T) {
+	database := newTestDB(t)
+	start := time.Now().Add(-time.Hour)
+
+	id, err := database.CreateSession(start, start.Add(25*time.Minute), "Oops", 25*60, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keep, err := database.CreateSession(start.Add(30*time.Minute), start.Add(55*time.Minute), "Keep", 25*60, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.SetSessionMetadata(id, MetaEnergyEnd, "3"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.AddAnnotation(id, "ci", "passed"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := database.DeleteSession(id); err != nil {
+		t.Fatalf("DeleteSession failed: %v", err)
+	}
+	if s, err := database.GetSessionByID(id); err != nil || s != nil {
+		t.Errorf("Expected the session to be gone, got %v, %v", s, err)
+	}
+	if meta, _ := database.GetSessionMetadata(id); len(meta) != 0 {
+		t.Errorf("Expected its metadata to be gone, got %v", meta)
+	}
+	if notes, _ := database.GetAnnotations(id); len(notes) != 0 {
+		t.Errorf("Expected its annotations to be gone, got %v", notes)
+	}
+	if s, _ := database.GetSessionByID(keep); s == nil {
+		t.Error("Expected the other session to be kept")
+	}
+	if err := database.DeleteSession(id); err == nil {
+		t.Error("Expected an error deleting a session twice")
+	}
+
+	running, err := database.CreateSession(time.Now(), time.Now().Add(25*time.Minute), "Running", 25*60, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.DeleteSession(running); err == nil {
+		t.Error("Expected a running session to be refused")
+	}
+}
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ImportedSession is a finished session brought in from another tool
+type ImportedSession struct {
+	Start       time.Time
+	Duration    time.Duration
+	Description string
+	TagsCSV     string
+	WasBreak    bool
+	Status      string // How the session ended, completed when empty
+}
+
+// ImportSessions records finished sessions from another tool. A session
+// that starts within the same second and runs the same whole minutes as one
+// already recorded, or earlier in sessions, is taken for a duplicate and
+// skipped, so importing the same history twice, or an export of this one,
+// adds nothing. Minutes are compared because that is all formats like OPF
+// keep. It returns how many sessions were added and skipped.
+func (d *InternalDB) ImportSessions(sessions []ImportedSession) (imported, skipped int, err error) {
+	return d.importSessions(sessions, true)
+}
+
+// PreviewImport returns how many sessions ImportSessions would add and skip,
+// leaving the database as it is
+func (d *InternalDB) PreviewImport(sessions []ImportedSession) (imported, skipped int, err error) {
+	return d.importSessions(sessions, false)
+}
+
+// importSessions imports sessions in a transaction, committed only with commit
+func (d *InternalDB) importSessions(sessions []ImportedSession, commit bool) (imported, skipped int, err error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, 0, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	before, err := maxSessionID(tx)
+	if err != nil {
+		return 0, 0, err
+	}
+	exists, err := tx.Prepare(`SELECT COUNT(*) FROM pomodoros
+		WHERE abs(julianday(start_time) - julianday(?)) * 86400 < 1 AND duration_secs / 60 = ?`)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error preparing query: %v", err)
+	}
+	insert, err := tx.Prepare(`INSERT INTO pomodoros(start_time, end_time, description, duration_secs, tags_csv, was_break,
+		total_paused_duration, is_paused, uid, tz_offset, tz_name, status)
+		VALUES(?, ?, ?, ?, ?, ?, 0, 0, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error preparing insert: %v", err)
+	}
+
+	for _, s := range sessions {
+		var count int
+		if err := exists.QueryRow(s.Start, int64(s.Duration/time.Minute)).Scan(&count); err != nil {
+			return 0, 0, fmt.Errorf("error checking for duplicates: %v", err)
+		}
+		if count > 0 {
+			skipped++
+			continue
+		}
+
+		uid, err := newUID()
+		if err != nil {
+			return 0, 0, err
+		}
+		status := s.Status
+		if status == "" {
+			status = StatusCompleted
+		}
+		if err := validateStatus(status); err != nil {
+			return 0, 0, err
+		}
+		tzName, tzOffset := s.Start.Zone()
+		if _, err := insert.Exec(s.Start, s.Start.Add(s.Duration), s.Description, int64(s.Duration/time.Second), s.TagsCSV, s.WasBreak,
+			uid, tzOffset, tzName, status); err != nil {
+			return 0, 0, fmt.Errorf("error inserting record: %v", err)
+		}
+		imported++
+	}
+
+	if !commit {
+		return imported, skipped, nil
+	}
+	if _, err := tx.Exec(fillSessionTags("id > " + strconv.FormatInt(before, 10))); err != nil {
+		return 0, 0, fmt.Errorf("error adding tags: %v", err)
+	}
+	if _, err := tx.Exec(summarizeDays("id > " + strconv.FormatInt(before, 10))); err != nil {
+		return 0, 0, fmt.Errorf("error updating daily summaries: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("error committing import: %v", err)
+	}
+	return imported, skipped, nil
+}
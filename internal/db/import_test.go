@@ -0,0 +1,75 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestImportSessionsSkipsDuplicates(t *testing.T) {
+	database := newTestDB(t)
+	start := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+
+	// Recorded here, 25m30s long, which exports as 25 minutes
+	recorded, err := database.CreateSession(start, start.Add(25*time.Minute+30*time.Second), "Write", 25*60+30, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cest := time.FixedZone("CEST", 2*3600)
+	imported, skipped, err := database.ImportSessions([]ImportedSession{
+		{Start: start.In(cest), Duration: 25 * time.Minute, Description: "Write"},                  // The session above
+		{Start: start.Add(25 * time.Minute), Duration: 5 * time.Minute, WasBreak: true},            // New
+		{Start: start.Add(30 * time.Minute), Duration: 50 * time.Minute, TagsCSV: "coding,review"}, // New
+		{Start: start.Add(30 * time.Minute), Duration: 50 * time.Minute},                           // Repeated in the file
+		{Start: start.Add(30 * time.Minute), Duration: 25 * time.Minute},                           // Same start, other length
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported != 3 || skipped != 2 {
+		t.Errorf("Expected 3 imported and 2 skipped, got %d and %d", imported, skipped)
+	}
+
+	sessions, err := database.GetSessionsByDateRange(start, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 4 {
+		t.Fatalf("Expected 4 sessions, got %d", len(sessions))
+	}
+	for _, s := range sessions {
+		if s.ID != recorded && s.Status != StatusCompleted {
+			t.Errorf("Expected session %d to be completed, got %q", s.ID, s.Status)
+		}
+	}
+
+	if imported, skipped, err = database.ImportSessions([]ImportedSession{{Start: start.Add(25 * time.Minute), Duration: 5 * time.Minute, WasBreak: true}}); err != nil || imported != 0 || skipped != 1 {
+		t.Errorf("Expected importing again to add nothing, got %d imported, %d skipped, %v", imported, skipped, err)
+	}
+}
+
+func TestPreviewImport(t *testing.T) {
+	database := newTestDB(t)
+	start := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	if _, err := database.CreateSession(start, start.Add(25*time.Minute), "Write", 25*60, "", false); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions := []ImportedSession{
+		{Start: start, Duration: 25 * time.Minute},                                     // Recorded
+		{Start: start.Add(30 * time.Minute), Duration: 25 * time.Minute, TagsCSV: "x"}, // New
+		{Start: start.Add(30 * time.Minute), Duration: 25 * time.Minute},               // Repeated in the file
+	}
+	imported, skipped, err := database.PreviewImport(sessions)
+	if err != nil || imported != 1 || skipped != 2 {
+		t.Errorf("Expected 1 to import and 2 to skip, got %d and %d, %v", imported, skipped, err)
+	}
+
+	recorded, err := database.GetSessionsByDateRange(start, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recorded) != 1 {
+		t.Errorf("Expected the preview to leave the database as it was, got %d sessions", len(recorded))
+	}
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// invariantChecks makes every pause, resume, and end time change verify the
+// session's accounting afterwards, set by the global --debug flag
+var invariantChecks bool
+
+// EnableInvariantChecks makes later changes to a session's timing fail when
+// they leave its accounting inconsistent
+func EnableInvariantChecks() {
+	invariantChecks = true
+}
+
+// CheckInvariants verifies a session's time accounting against its pauses:
+// it never ends before it starts, focus time plus paused time equals its
+// wall time with neither negative, a paused session has exactly one open
+// pause inside the session, and logged pauses add up to no more than the
+// recorded total. Sessions paused before pauses were logged have a total
+// without pauses, so the total may be larger.
+func (s PomodoroSession) CheckInvariants(pauses []Pause) error {
+	paused := time.Duration(s.TotalPausedDuration) * time.Second
+	switch {
+	case s.EndTime.Before(s.StartTime):
+		return fmt.Errorf("ends at %s, before it starts at %s", s.EndTime.Format(time.RFC3339), s.StartTime.Format(time.RFC3339))
+	case paused < 0:
+		return fmt.Errorf("negative paused time %s", paused)
+	case s.IsPaused != (s.PausedAt != nil):
+		return errors.New("paused flag and pause time disagree")
+	case s.IsPaused && (s.PausedAt.Before(s.StartTime) || s.PausedAt.After(s.EndTime)):
+		return fmt.Errorf("paused at %s, outside the session", s.PausedAt.Format(time.RFC3339))
+	}
+
+	// A paused session's clock stopped at the pause
+	end := s.EndTime
+	if s.IsPaused {
+		end = *s.PausedAt
+	}
+	if wall := end.Sub(s.StartTime); s.EffectiveFocus(end)+paused != wall {
+		return fmt.Errorf("paused for %s, longer than its wall time of %s", paused, wall)
+	}
+
+	var logged time.Duration
+	for i, p := range pauses {
+		if p.ResumedAt == nil {
+			if !s.IsPaused || i != len(pauses)-1 {
+				return fmt.Errorf("pause %d is still open", i+1)
+			}
+			continue
+		}
+		if p.ResumedAt.Before(p.PausedAt) {
+			return fmt.Errorf("pause %d resumes before it starts", i+1)
+		}
+		logged += p.ResumedAt.Sub(p.PausedAt).Truncate(time.Second)
+	}
+	if s.IsPaused && (len(pauses) == 0 || pauses[len(pauses)-1].ResumedAt != nil) {
+		return errors.New("paused without an open pause")
+	}
+	if logged > paused {
+		return fmt.Errorf("logged pauses add up to %s, more than the recorded %s", logged, paused)
+	}
+	return nil
+}
+
+// checkInvariants verifies a session after a change when invariant checks
+// are enabled
+func (d *InternalDB) checkInvariants(id int64, at time.Time) error {
+	if !invariantChecks {
+		return nil
+	}
+
+	session, err := d.GetSessionByID(id)
+	if err != nil || session == nil {
+		return fmt.Errorf("error loading session %d to check it: %v", id, err)
+	}
+	pauses, err := d.GetPauses(id)
+	if err != nil {
+		return err
+	}
+	if err := session.CheckInvariants(pauses); err != nil {
+		return fmt.Errorf("session %d is inconsistent after a change at %s: %v", id, at.Format(time.RFC3339), err)
+	}
+	return nil
+}
@@ -0,0 +1,268 @@
+package db
+
+import (
+	"math/rand/v2"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Operations an accountingModel step can perform
+const (
+	opStart = iota
+	opPause
+	opResume
+	opExtend
+	opEnd
+	opAdvance
+	opCount
+)
+
+// accountingModel drives sessions through start, pause, resume, extend,
+// and end on a fake clock, tracking the focus time each step should leave
+// behind so the database can be checked against it
+type accountingModel struct {
+	t        *testing.T
+	database *InternalDB
+	now      time.Time
+
+	id      int64         // current session, 0 before the first start
+	planned time.Duration // planned focus time, including extensions
+	focus   time.Duration // focus so far
+	paused  bool
+	ended   bool
+	pauses  int
+}
+
+func newAccountingModel(t *testing.T, database *InternalDB) *accountingModel {
+	invariantChecks = true
+	t.Cleanup(func() { invariantChecks = false })
+	return &accountingModel{
+		t:        t,
+		database: database,
+		now:      time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+// live reports whether the current session is still running or paused
+func (m *accountingModel) live() bool {
+	return m.id != 0 && !m.ended
+}
+
+// step performs one operation; arg picks a length where one is needed, and
+// operations that do not apply to the current state do nothing
+func (m *accountingModel) step(op, arg int) {
+	t := m.t
+	t.Helper()
+
+	switch op {
+	case opStart:
+		if m.live() {
+			return
+		}
+		m.planned = time.Duration(arg%60+1) * time.Minute
+		id, err := m.database.CreateSession(m.now, m.now.Add(m.planned), "Task", int64(m.planned.Seconds()), "", false)
+		if err != nil {
+			t.Fatalf("CreateSession failed: %v", err)
+		}
+		m.id, m.focus, m.paused, m.ended, m.pauses = id, 0, false, false, 0
+
+	case opPause:
+		if !m.live() || m.paused {
+			return
+		}
+		if err := m.database.PauseSession(m.id, m.now); err != nil {
+			t.Fatalf("PauseSession failed: %v", err)
+		}
+		m.paused = true
+		m.pauses++
+
+	case opResume:
+		if !m.live() || !m.paused {
+			return
+		}
+		if err := m.database.ResumeSession(m.id, m.now); err != nil {
+			t.Fatalf("ResumeSession failed: %v", err)
+		}
+		m.paused = false
+
+	case opExtend:
+		if !m.live() || m.paused {
+			return
+		}
+		session := m.session()
+		extra := time.Duration(arg%10+1) * time.Minute
+		if arg%2 == 0 {
+			if err := m.database.UpdateSessionEndTime(m.id, session.EndTime.Add(extra)); err != nil {
+				t.Fatalf("UpdateSessionEndTime failed: %v", err)
+			}
+		} else {
+			if err := m.database.ExtendSession(m.id, extra); err != nil {
+				t.Fatalf("ExtendSession failed: %v", err)
+			}
+			if got := m.session().DurationSec; got != session.DurationSec+int64(extra/time.Second) {
+				t.Fatalf("ExtendSession left a planned length of %ds", got)
+			}
+		}
+		m.planned += extra
+
+	case opEnd:
+		if !m.live() {
+			return
+		}
+		if err := m.database.EndSession(m.id, m.now, StatusCancelled); err != nil {
+			t.Fatalf("EndSession failed: %v", err)
+		}
+		m.ended, m.paused, m.planned = true, false, m.focus
+
+	case opAdvance:
+		m.advance(time.Duration(arg)*30*time.Second + time.Duration(arg%7)*150*time.Millisecond)
+		return
+	}
+
+	m.check()
+}
+
+// advance moves the clock forward, completing a running session whose
+// remaining time runs out
+func (m *accountingModel) advance(elapsed time.Duration) {
+	m.t.Helper()
+	if m.live() && !m.paused {
+		if remaining := m.planned - m.focus; elapsed >= remaining {
+			m.focus, m.ended = m.planned, true
+		} else {
+			m.focus += elapsed
+		}
+	}
+	m.now = m.now.Add(elapsed)
+	m.check()
+}
+
+// session loads the current session
+func (m *accountingModel) session() *PomodoroSession {
+	m.t.Helper()
+	session, err := m.database.GetSessionByID(m.id)
+	if err != nil || session == nil {
+		m.t.Fatalf("Failed to load session %d: %v", m.id, err)
+	}
+	return session
+}
+
+// check compares the current session with the model. Pauses are recorded in
+// whole seconds, so each may count up to a second less.
+func (m *accountingModel) check() {
+	t := m.t
+	t.Helper()
+	if m.id == 0 {
+		return
+	}
+
+	session := m.session()
+	pauses, err := m.database.GetPauses(m.id)
+	if err != nil {
+		t.Fatalf("GetPauses failed: %v", err)
+	}
+	if err := session.CheckInvariants(pauses); err != nil {
+		t.Fatalf("Session %d: %v", m.id, err)
+	}
+	if len(pauses) != m.pauses {
+		t.Fatalf("Session %d has %d pauses, want %d", m.id, len(pauses), m.pauses)
+	}
+	if session.IsPaused != m.paused {
+		t.Fatalf("Session %d paused = %v, want %v", m.id, session.IsPaused, m.paused)
+	}
+
+	tolerance := time.Duration(m.pauses) * time.Second
+	near := func(got, want time.Duration) bool {
+		diff := got - want
+		return diff <= tolerance && diff >= -tolerance
+	}
+
+	if focus := session.EffectiveFocus(m.now); !near(focus, m.focus) {
+		t.Fatalf("Session %d focus = %s, want %s", m.id, focus, m.focus)
+	}
+
+	var remaining time.Duration
+	switch {
+	case m.paused:
+		remaining = session.RemainingAtPause()
+	case !m.ended:
+		remaining = session.EndTime.Sub(m.now)
+	default:
+		return
+	}
+	if remaining < 0 || !near(remaining, m.planned-m.focus) {
+		t.Fatalf("Session %d remaining = %s, want %s", m.id, remaining, m.planned-m.focus)
+	}
+}
+
+func TestSessionAccountingInvariants(t *testing.T) {
+	database := newTestDB(t)
+	m := newAccountingModel(t, database)
+
+	for seed := uint64(1); seed <= 50; seed++ {
+		r := rand.New(rand.NewPCG(seed, seed)) // #nosec G404 - test data
+		for i := 0; i < 200; i++ {
+			m.step(r.IntN(opCount), r.IntN(43))
+		}
+	}
+}
+
+func TestResumeAfterSeveralPauses(t *testing.T) {
+	database := newTestDB(t)
+	m := newAccountingModel(t, database)
+
+	m.step(opStart, 24) // 25 minutes
+	for i := 0; i < 3; i++ {
+		m.advance(5 * time.Minute)
+		m.step(opPause, 0)
+		m.advance(2 * time.Minute)
+		m.step(opResume, 0)
+	}
+
+	session := m.session()
+	if remaining := session.EndTime.Sub(m.now); remaining != 10*time.Minute {
+		t.Errorf("Expected 10m remaining after 15m of focus, got %s", remaining)
+	}
+	if session.TotalPausedDuration != 360 {
+		t.Errorf("Expected 360s paused, got %d", session.TotalPausedDuration)
+	}
+}
+
+func TestEndPausedSession(t *testing.T) {
+	database := newTestDB(t)
+	m := newAccountingModel(t, database)
+
+	m.step(opStart, 24)
+	m.advance(10 * time.Minute)
+	m.step(opPause, 0)
+	m.advance(3 * time.Minute)
+	m.step(opEnd, 0)
+
+	session := m.session()
+	if session.IsPaused {
+		t.Error("Expected an ended session to no longer be paused")
+	}
+	if focus := session.EffectiveFocus(m.now.Add(time.Hour)); focus != 10*time.Minute {
+		t.Errorf("Expected 10m of focus, got %s", focus)
+	}
+}
+
+func FuzzSessionAccounting(f *testing.F) {
+	f.Add([]byte{0, 60, 1, 30, 2, 60, 1, 30, 2, 255})
+	f.Add([]byte{0, 30, 3, 1, 60, 2, 60, 4, 0, 255})
+	f.Add([]byte{0, 1, 30, 4, 30, 0, 60, 3, 1})
+
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		database, err := NewDB(filepath.Join(t.TempDir(), "history.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = database.Close() }()
+
+		m := newAccountingModel(t, database)
+		for _, b := range ops {
+			m.step(int(b)%opCount, int(b)/opCount)
+		}
+	})
+}
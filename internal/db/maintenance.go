@@ -0,0 +1,90 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// Size returns how many bytes the database's pages take, free pages included
+func (d *InternalDB) Size() (int64, error) {
+	var pages, pageSize int64
+	if err := d.db.QueryRow(`PRAGMA page_count`).Scan(&pages); err != nil {
+		return 0, fmt.Errorf("error reading database size: %v", err)
+	}
+	if err := d.db.QueryRow(`PRAGMA page_size`).Scan(&pageSize); err != nil {
+		return 0, fmt.Errorf("error reading database size: %v", err)
+	}
+	return pages * pageSize, nil
+}
+
+// Vacuum rebuilds the database to give the space left by deleted rows back
+// to the file system, and folds the write-ahead log into it. It returns the
+// size before and after.
+func (d *InternalDB) Vacuum() (before, after int64, err error) {
+	if before, err = d.Size(); err != nil {
+		return 0, 0, err
+	}
+	if _, err := d.db.Exec(`VACUUM`); err != nil {
+		return 0, 0, fmt.Errorf("error vacuuming database: %v", err)
+	}
+	if _, err := d.db.Exec(`PRAGMA wal_checkpoint(TRUNCATE)`); err != nil {
+		return 0, 0, fmt.Errorf("error checkpointing database: %v", err)
+	}
+	if after, err = d.Size(); err != nil {
+		return 0, 0, err
+	}
+	return before, after, nil
+}
+
+// PruneResult counts what Prune deleted
+type PruneResult struct {
+	Sessions      int64 `json:"sessions"`
+	CounterEvents int64 `json:"counter_events"`
+	AuditEntries  int64 `json:"audit_entries"`
+}
+
+// Prune deletes the finished sessions that started before cutoff, with
+// their pauses, metadata, annotations, and tags, and the counter events and API
+// audit entries logged before it. Tasks are kept, since later sessions may still refer to them.
+func (d *InternalDB) Prune(cutoff time.Time) (PruneResult, error) {
+	var result PruneResult
+	tx, err := d.db.Begin()
+	if err != nil {
+		return result, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	old := `SELECT id FROM pomodoros WHERE julianday(start_time) < julianday(?) AND is_paused = 0
+		AND julianday(end_time) <= julianday('now')`
+	for _, table := range []string{"session_metadata", "session_annotations", "session_pauses", "session_tags"} {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE session_id IN (`+old+`)`, cutoff); err != nil {
+			return result, fmt.Errorf("error pruning %s: %v", table, err)
+		}
+	}
+	res, err := tx.Exec(`DELETE FROM pomodoros WHERE id IN (`+old+`)`, cutoff)
+	if err != nil {
+		return result, fmt.Errorf("error pruning sessions: %v", err)
+	}
+	if result.Sessions, err = res.RowsAffected(); err != nil {
+		return result, fmt.Errorf("error pruning sessions: %v", err)
+	}
+	res, err = tx.Exec(`DELETE FROM counter_log WHERE julianday(logged_at) < julianday(?)`, cutoff)
+	if err != nil {
+		return result, fmt.Errorf("error pruning counters: %v", err)
+	}
+	if result.CounterEvents, err = res.RowsAffected(); err != nil {
+		return result, fmt.Errorf("error pruning counters: %v", err)
+	}
+	res, err = tx.Exec(`DELETE FROM api_audit WHERE julianday(at) < julianday(?)`, cutoff)
+	if err != nil {
+		return result, fmt.Errorf("error pruning the API audit log: %v", err)
+	}
+	if result.AuditEntries, err = res.RowsAffected(); err != nil {
+		return result, fmt.Errorf("error pruning the API audit log: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return PruneResult{}, fmt.Errorf("error committing prune: %v", err)
+	}
+	return result, nil
+}
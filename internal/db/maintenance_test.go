@@ -0,0 +1,79 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPruneAndVacuum(t *testing.T) {
+	database, err := NewDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = database.Close() }()
+
+	cutoff := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var oldID int64
+	for i := range 200 {
+		start := cutoff.AddDate(0, 0, i-100)
+		id, err := database.CreateSession(start, start.Add(25*time.Minute), "Write report", 25*60, "work", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			oldID = id
+		}
+		if err := database.SetSessionMetadata(id, MetaQuality, "4"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := database.AddAnnotation(oldID, "ci", "Build passed"); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.LogCounter("water", 1, cutoff.AddDate(0, 0, -1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.LogCounter("water", 1, cutoff.AddDate(0, 0, 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := database.Prune(cutoff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Sessions != 100 || result.CounterEvents != 1 {
+		t.Errorf("Expected 100 sessions and 1 counter event pruned, got %+v", result)
+	}
+	left, err := database.GetSessionsByDateRange(time.Time{}, cutoff.AddDate(1, 0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 100 || left[len(left)-1].StartTime.Before(cutoff) {
+		t.Errorf("Expected only the 100 sessions from the cutoff on to be left, got %d", len(left))
+	}
+	if meta, err := database.GetSessionMetadata(oldID); err != nil || len(meta) != 0 {
+		t.Errorf("Expected the pruned session's metadata to go with it, got %v %v", meta, err)
+	}
+
+	before, after, err := database.Vacuum()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after <= 0 || after > before {
+		t.Errorf("Expected vacuuming not to grow the database, got %d from %d", after, before)
+	}
+
+	backup := filepath.Join(t.TempDir(), "copy.db")
+	if err := database.Snapshot(backup); err != nil {
+		t.Fatal(err)
+	}
+	copied, err := NewDB(backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = copied.Close() }()
+	if sessions, err := copied.GetSessionsByDateRange(time.Time{}, cutoff.AddDate(1, 0, 0)); err != nil || len(sessions) != 100 {
+		t.Errorf("Expected the backup to hold the 100 sessions, got %d %v", len(sessions), err)
+	}
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Metadata keys used by built-in features
+const (
+	MetaEnergyStart = "energy_start" // Energy level (1-5) logged when the session started
+	MetaEnergyEnd   = "energy_end"   // Energy level (1-5) logged when the session ended
+	MetaNotified    = "notified"     // Time the completion notification was sent
+	MetaAutoBreak   = "auto_break"   // Why a break was recorded automatically (e.g. "screen_lock")
+	MetaBreakKind   = "break_kind"   // "long" for a long break, which ends a pomodoro cycle
+	MetaCategory    = "category"     // Category given with --category (deep, shallow, ...)
+	MetaWarmup      = "warmup"       // Seconds of warm-up taken before the session started
+	MetaQuality     = "quality"      // Focus quality (1-5) rated when the pomodoro finished
+	MetaLogged      = "logged"       // Time a session was recorded with log instead of timed
+	MetaLocation    = "location"     // Where the pomodoro ran, from the Wi-Fi network
+)
+
+// BreakKindLong marks a long break in MetaBreakKind
+const BreakKindLong = "long"
+
+// SetSessionMetadata stores a metadata value for a session, replacing any previous value
+func (d *InternalDB) SetSessionMetadata(id int64, key, value string) error {
+	_, err := d.db.Exec(
+		`INSERT INTO session_metadata(session_id, key, value) VALUES(?, ?, ?)
+		ON CONFLICT(session_id, key) DO UPDATE SET value = excluded.value`,
+		id, key, value,
+	)
+	if err != nil {
+		return fmt.Errorf("error saving session metadata: %v", err)
+	}
+	return nil
+}
+
+// GetSessionMetadata retrieves all metadata stored for a session
+func (d *InternalDB) GetSessionMetadata(id int64) (map[string]string, error) {
+	rows, err := d.db.Query(
+		`SELECT key, value FROM session_metadata WHERE session_id = ? ORDER BY key`,
+		id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying session metadata: %v", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing rows: %v\n", err)
+		}
+	}()
+
+	metadata := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("error scanning session metadata: %v", err)
+		}
+		metadata[key] = value
+	}
+
+	return metadata, rows.Err()
+}
+
+// GetMetadataByDateRange retrieves one metadata key for every session in the
+// date range that has it, keyed by session ID
+func (d *InternalDB) GetMetadataByDateRange(key string, startDate, endDate time.Time) (map[int64]string, error) {
+	rows, err := d.db.Query(
+		`SELECT m.session_id, m.value
+		FROM session_metadata m
+		JOIN pomodoros p ON p.id = m.session_id
+		WHERE m.key = ? AND `+localDay+` >= ? AND `+localDay+` <= ?`,
+		key, dayParam(startDate), dayParam(endDate),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying session metadata: %v", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing rows: %v\n", err)
+		}
+	}()
+
+	values := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var value string
+		if err := rows.Scan(&id, &value); err != nil {
+			return nil, fmt.Errorf("error scanning session metadata: %v", err)
+		}
+		values[id] = value
+	}
+
+	return values, rows.Err()
+}
@@ -0,0 +1,588 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migration is one step in the schema's history
+type migration struct {
+	name string
+	up   string
+	// down undoes up; it is empty for steps that only fill in data, which
+	// the down step of the column they filled removes
+	down string
+}
+
+// migrations bring the schema up to date, in order; a migration's version
+// is its position, counting from 1. Each one applied is logged in
+// schema_migrations, so append new ones and never reorder or remove them.
+var migrations = []migration{
+	{"add pomodoros.paused_at",
+		`ALTER TABLE pomodoros ADD COLUMN paused_at TIMESTAMP;`,
+		`ALTER TABLE pomodoros DROP COLUMN paused_at;`},
+	{"add pomodoros.total_paused_duration",
+		`ALTER TABLE pomodoros ADD COLUMN total_paused_duration INTEGER DEFAULT 0;`,
+		`ALTER TABLE pomodoros DROP COLUMN total_paused_duration;`},
+	{"add pomodoros.is_paused",
+		`ALTER TABLE pomodoros ADD COLUMN is_paused BOOLEAN DEFAULT 0;`,
+		`ALTER TABLE pomodoros DROP COLUMN is_paused;`},
+	{"index active sessions",
+		`CREATE INDEX IF NOT EXISTS idx_pomodoros_active ON pomodoros(is_paused, end_time);`,
+		`DROP INDEX IF EXISTS idx_pomodoros_active;`},
+	{"add pomodoros.uid",
+		`ALTER TABLE pomodoros ADD COLUMN uid TEXT;`,
+		`ALTER TABLE pomodoros DROP COLUMN uid;`},
+	{"fill in session uids",
+		`UPDATE pomodoros SET uid = lower(hex(randomblob(16))) WHERE uid IS NULL;`,
+		``},
+	{"index session uids",
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_pomodoros_uid ON pomodoros(uid);`,
+		`DROP INDEX IF EXISTS idx_pomodoros_uid;`},
+	{"create session_metadata",
+		`CREATE TABLE IF NOT EXISTS session_metadata (
+			session_id INTEGER NOT NULL REFERENCES pomodoros(id) ON DELETE CASCADE,
+			key TEXT NOT NULL,
+			value TEXT NOT NULL,
+			PRIMARY KEY (session_id, key)
+		);`,
+		`DROP TABLE IF EXISTS session_metadata;`},
+	{"add pomodoros.tz_offset",
+		`ALTER TABLE pomodoros ADD COLUMN tz_offset INTEGER;`,
+		`ALTER TABLE pomodoros DROP COLUMN tz_offset;`},
+	{"add pomodoros.tz_name",
+		`ALTER TABLE pomodoros ADD COLUMN tz_name TEXT;`,
+		`ALTER TABLE pomodoros DROP COLUMN tz_name;`},
+	// Recover the offset from the stored timestamp: its wall-clock part
+	// minus the same instant in UTC
+	{"fill in session time zone offsets",
+		`UPDATE pomodoros SET tz_offset = CAST(round((julianday(substr(start_time, 1, 19)) - julianday(start_time)) * 86400) AS INTEGER)
+			WHERE tz_offset IS NULL;`,
+		``},
+	{"index sessions by local day",
+		`CREATE INDEX IF NOT EXISTS idx_pomodoros_local_day ON pomodoros(` + localDay + `);`,
+		`DROP INDEX IF EXISTS idx_pomodoros_local_day;`},
+	{"create session_annotations",
+		`CREATE TABLE IF NOT EXISTS session_annotations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			session_id INTEGER NOT NULL REFERENCES pomodoros(id) ON DELETE CASCADE,
+			created_at TIMESTAMP NOT NULL,
+			source TEXT NOT NULL,
+			text TEXT NOT NULL
+		);`,
+		`DROP TABLE IF EXISTS session_annotations;`},
+	{"index annotations by session",
+		`CREATE INDEX IF NOT EXISTS idx_session_annotations_session ON session_annotations(session_id);`,
+		`DROP INDEX IF EXISTS idx_session_annotations_session;`},
+	{"create session_pauses",
+		`CREATE TABLE IF NOT EXISTS session_pauses (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			session_id INTEGER NOT NULL REFERENCES pomodoros(id) ON DELETE CASCADE,
+			paused_at TIMESTAMP NOT NULL,
+			resumed_at TIMESTAMP
+		);`,
+		`DROP TABLE IF EXISTS session_pauses;`},
+	{"index pauses by session",
+		`CREATE INDEX IF NOT EXISTS idx_session_pauses_session ON session_pauses(session_id);`,
+		`DROP INDEX IF EXISTS idx_session_pauses_session;`},
+	{"make annotations append-only",
+		`CREATE TRIGGER IF NOT EXISTS session_annotations_append_only
+			BEFORE UPDATE ON session_annotations
+			BEGIN SELECT RAISE(ABORT, 'annotations are append-only'); END;`,
+		`DROP TRIGGER IF EXISTS session_annotations_append_only;`},
+	{"create tasks",
+		`CREATE TABLE IF NOT EXISTS tasks (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			title TEXT NOT NULL,
+			estimate INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP NOT NULL,
+			done_at TIMESTAMP
+		);`,
+		`DROP TABLE IF EXISTS tasks;`},
+	{"add pomodoros.task_id",
+		`ALTER TABLE pomodoros ADD COLUMN task_id INTEGER REFERENCES tasks(id);`,
+		`ALTER TABLE pomodoros DROP COLUMN task_id;`},
+	{"index sessions by task",
+		`CREATE INDEX IF NOT EXISTS idx_pomodoros_task ON pomodoros(task_id);`,
+		`DROP INDEX IF EXISTS idx_pomodoros_task;`},
+	{"add pomodoros.status",
+		`ALTER TABLE pomodoros ADD COLUMN status TEXT;`,
+		`ALTER TABLE pomodoros DROP COLUMN status;`},
+	{"fill in session statuses", settleStatuses, ``},
+	{"create counter_log",
+		`CREATE TABLE IF NOT EXISTS counter_log (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			amount INTEGER NOT NULL,
+			logged_at TIMESTAMP NOT NULL,
+			day TEXT NOT NULL
+		);`,
+		`DROP TABLE IF EXISTS counter_log;`},
+	{"index counter log by day",
+		`CREATE INDEX IF NOT EXISTS idx_counter_log_day ON counter_log(day);`,
+		`DROP INDEX IF EXISTS idx_counter_log_day;`},
+	{"add tasks.source",
+		`ALTER TABLE tasks ADD COLUMN source TEXT;`,
+		`ALTER TABLE tasks DROP COLUMN source;`},
+	{"create api_tokens",
+		`CREATE TABLE IF NOT EXISTS api_tokens (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			hash TEXT NOT NULL UNIQUE,
+			scope TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			last_used_at TIMESTAMP,
+			revoked_at TIMESTAMP
+		);`,
+		`DROP TABLE IF EXISTS api_tokens;`},
+	{"create api_audit",
+		`CREATE TABLE IF NOT EXISTS api_audit (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			at TIMESTAMP NOT NULL,
+			client TEXT NOT NULL,
+			method TEXT NOT NULL,
+			path TEXT NOT NULL,
+			status INTEGER NOT NULL,
+			session_id INTEGER,
+			remote TEXT NOT NULL
+		);`,
+		`DROP TABLE IF EXISTS api_audit;`},
+	{"index api audit by time",
+		`CREATE INDEX IF NOT EXISTS idx_api_audit_at ON api_audit(at);`,
+		`DROP INDEX IF EXISTS idx_api_audit_at;`},
+	{"create tags",
+		`CREATE TABLE IF NOT EXISTS tags (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE COLLATE NOCASE
+		);`,
+		`DROP TABLE IF EXISTS tags;`},
+	{"create session_tags",
+		`CREATE TABLE IF NOT EXISTS session_tags (
+			session_id INTEGER NOT NULL REFERENCES pomodoros(id) ON DELETE CASCADE,
+			tag_id INTEGER NOT NULL REFERENCES tags(id),
+			PRIMARY KEY (session_id, tag_id)
+		);`,
+		`DROP TABLE IF EXISTS session_tags;`},
+	{"index session tags by tag",
+		`CREATE INDEX IF NOT EXISTS idx_session_tags_tag ON session_tags(tag_id);`,
+		`DROP INDEX IF EXISTS idx_session_tags_tag;`},
+	{"fill in session tags", fillSessionTags("id > 0"), ``},
+	{"create template_usage",
+		`CREATE TABLE IF NOT EXISTS template_usage (
+			name TEXT PRIMARY KEY,
+			uses INTEGER NOT NULL DEFAULT 0,
+			last_used TIMESTAMP NOT NULL
+		);`,
+		`DROP TABLE IF EXISTS template_usage;`},
+	{"create daily_summary",
+		`CREATE TABLE IF NOT EXISTS daily_summary (
+			date TEXT PRIMARY KEY,
+			pomodoro_count INTEGER NOT NULL DEFAULT 0,
+			goal_met BOOLEAN NOT NULL DEFAULT 0
+		);`,
+		`DROP TABLE IF EXISTS daily_summary;`},
+	// Whether past days met the goal is not known, so only counts are filled in
+	{"fill in daily summaries", summarizeDays("id > 0"), ``},
+	{"create vacations",
+		`CREATE TABLE IF NOT EXISTS vacations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			from_day TEXT NOT NULL,
+			to_day TEXT NOT NULL
+		);`,
+		`DROP TABLE IF EXISTS vacations;`},
+}
+
+// settleStatuses records how sessions that finished before statuses were
+// recorded, or ran out with nobody watching, ended: cancelled when they
+// stopped short. Besides filling in statuses once as a migration, it runs
+// whenever the database is opened.
+const settleStatuses = `UPDATE pomodoros SET status = CASE WHEN ` + focusSeconds + ` >= duration_secs - 1 THEN 'completed' ELSE 'cancelled' END
+	WHERE status IS NULL AND is_paused = 0 AND julianday(end_time) <= julianday('now');`
+
+// settleSessions settles the statuses of sessions that ran out, and
+// recounts the days of those found cancelled
+func (d *InternalDB) settleSessions() error {
+	rows, err := d.db.Query(`SELECT DISTINCT ` + localDay + ` FROM pomodoros
+		WHERE status IS NULL AND is_paused = 0 AND julianday(end_time) <= julianday('now')`)
+	if err != nil {
+		return fmt.Errorf("error settling session statuses: %v", err)
+	}
+	var days []string
+	for rows.Next() {
+		var day string
+		if err := rows.Scan(&day); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("error settling session statuses: %v", err)
+		}
+		days = append(days, day)
+	}
+	if err := errors.Join(rows.Err(), rows.Close()); err != nil {
+		return fmt.Errorf("error settling session statuses: %v", err)
+	}
+
+	if _, err := d.db.Exec(settleStatuses); err != nil {
+		return fmt.Errorf("error settling session statuses: %v", err)
+	}
+	return refreshSummaries(d.db, days...)
+}
+
+// SchemaVersion is the schema version this build migrates databases to
+var SchemaVersion = len(migrations)
+
+// unloggedMigrations is how many migrations there were before they were
+// logged in schema_migrations. Databases from then counted theirs in PRAGMA
+// user_version and ran every migration on each open, so these may have been
+// applied without being counted and tolerate finding their work done.
+const unloggedMigrations = 28
+
+// keepMigrationBackups is how many pre-migration snapshots are kept per
+// database, the oldest being removed first
+const keepMigrationBackups = 3
+
+// MigrationBackup is a snapshot taken before migrating a database
+type MigrationBackup struct {
+	Path      string
+	Version   int // Schema version of the database when the snapshot was taken
+	CreatedAt time.Time
+}
+
+// backupStamp is the time format in migration backup names
+const backupStamp = "20060102-150405"
+
+// backupName matches migration backup names: <database>-v<version>-<stamp>.db
+var backupName = regexp.MustCompile(`^(.+)-v(\d+)-(\d{8}-\d{6})\.db$`)
+
+// MigrationBackupDir returns the directory holding the pre-migration
+// snapshots of the database at dbPath
+func MigrationBackupDir(dbPath string) string {
+	return filepath.Join(filepath.Dir(dbPath), "migration-backups")
+}
+
+// MigrationStatus describes a schema migration and whether the database
+// has had it applied
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt time.Time // Zero when it is pending
+	// Unknown is set for migrations applied by a newer version of pomodoro
+	Unknown bool
+}
+
+// Pending reports whether the migration is still to be applied
+func (m MigrationStatus) Pending() bool {
+	return m.AppliedAt.IsZero()
+}
+
+// startLog creates schema_migrations when the database has none. A database
+// that predates it has the migrations its PRAGMA user_version counts logged
+// as applied, the only record of them there is.
+func startLog(db *sql.DB) error {
+	var logged int
+	if err := db.QueryRow(`SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`).Scan(&logged); err != nil {
+		return fmt.Errorf("error reading schema: %v", err)
+	}
+	if logged > 0 {
+		return nil
+	}
+
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return fmt.Errorf("error reading schema version: %v", err)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting migration log: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("error starting migration log: %v", err)
+	}
+	now := time.Now()
+	for v := 1; v <= min(version, unloggedMigrations); v++ {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO schema_migrations(version, name, applied_at) VALUES(?, ?, ?)`,
+			v, migrations[v-1].name, now); err != nil {
+			return fmt.Errorf("error starting migration log: %v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error starting migration log: %v", err)
+	}
+	return nil
+}
+
+// Migrations lists every migration this build knows, in order, and
+// any the database has from a newer version of pomodoro
+func (d *InternalDB) Migrations() ([]MigrationStatus, error) {
+	rows, err := d.db.Query(`SELECT version, name, applied_at FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		return nil, fmt.Errorf("error reading migration log: %v", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	statuses := make([]MigrationStatus, len(migrations))
+	for i, m := range migrations {
+		statuses[i] = MigrationStatus{Version: i + 1, Name: m.name}
+	}
+	for rows.Next() {
+		var s MigrationStatus
+		if err := rows.Scan(&s.Version, &s.Name, &s.AppliedAt); err != nil {
+			return nil, fmt.Errorf("error reading migration log: %v", err)
+		}
+		if s.Version < 1 {
+			continue
+		}
+		if s.Version > len(migrations) {
+			s.Unknown = true
+			statuses = append(statuses, s)
+			continue
+		}
+		statuses[s.Version-1].AppliedAt = s.AppliedAt
+	}
+	return statuses, rows.Err()
+}
+
+// AppliedVersion returns the version of the latest migration applied to the
+// database
+func (d *InternalDB) AppliedVersion() (int, error) {
+	var version int
+	if err := d.db.QueryRow(`SELECT coalesce(max(version), 0) FROM schema_migrations`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("error reading migration log: %v", err)
+	}
+	return version, nil
+}
+
+// Migrate applies the migrations the database does not have yet. Any from a
+// newer version of pomodoro are left in place.
+func (d *InternalDB) Migrate() error {
+	return d.migrate(SchemaVersion, false)
+}
+
+// MigrateTo brings the schema to version, applying the migrations up to it
+// and undoing those after it, newest first. A database that existed before
+// it was opened is snapshotted first, for Rollback.
+func (d *InternalDB) MigrateTo(version int) error {
+	if version < 0 || version > SchemaVersion {
+		return fmt.Errorf("no schema version %d: this version of pomodoro knows versions 0 to %d", version, SchemaVersion)
+	}
+	return d.migrate(version, true)
+}
+
+// migrate applies the migrations up to target, and when undo is set undoes
+// those after it
+func (d *InternalDB) migrate(target int, undo bool) error {
+	statuses, err := d.Migrations()
+	if err != nil {
+		return err
+	}
+	current, err := d.AppliedVersion()
+	if err != nil {
+		return err
+	}
+
+	var apply, revert []int
+	for _, s := range statuses {
+		switch {
+		case s.Pending() && s.Version <= target:
+			apply = append(apply, s.Version)
+		case !s.Pending() && s.Version > target && undo:
+			if s.Unknown {
+				return fmt.Errorf("migration %d (%s) is from a newer version of pomodoro, which cannot be undone with this one", s.Version, s.Name)
+			}
+			revert = append(revert, s.Version)
+		}
+	}
+	if len(apply) == 0 && len(revert) == 0 {
+		return nil
+	}
+	if d.existed && (current > 0 || d.hasSessions()) {
+		if err := backupBeforeMigration(d.db, d.path, current); err != nil {
+			return err
+		}
+	}
+
+	for _, v := range apply {
+		if err := d.applyMigration(v); err != nil {
+			return err
+		}
+	}
+	for i := len(revert) - 1; i >= 0; i-- {
+		if err := d.undoMigration(revert[i]); err != nil {
+			return err
+		}
+	}
+
+	// Builds from before schema_migrations go by PRAGMA user_version, which
+	// does not take parameters
+	if current, err = d.AppliedVersion(); err != nil {
+		return err
+	}
+	if _, err := d.db.Exec(`PRAGMA user_version = ` + strconv.Itoa(min(current, SchemaVersion))); err != nil {
+		return fmt.Errorf("error recording schema version: %v", err)
+	}
+	return nil
+}
+
+// applyMigration applies migration version and logs it, together, unless
+// another process opening the database has just done so
+func (d *InternalDB) applyMigration(version int) error {
+	m := migrations[version-1]
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error applying migration %d (%s): %v", version, m.name, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec(`INSERT OR IGNORE INTO schema_migrations(version, name, applied_at) VALUES(?, ?, ?)`, version, m.name, time.Now())
+	if err != nil {
+		return fmt.Errorf("error logging migration %d (%s): %v", version, m.name, err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	if _, err := tx.Exec(m.up); err != nil && !(version <= unloggedMigrations && alreadyApplied(err)) {
+		return fmt.Errorf("error applying migration %d (%s): %v", version, m.name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error applying migration %d (%s): %v", version, m.name, err)
+	}
+	return nil
+}
+
+// undoMigration runs the down step of migration version and removes it from
+// the log, together
+func (d *InternalDB) undoMigration(version int) error {
+	m := migrations[version-1]
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error undoing migration %d (%s): %v", version, m.name, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec(`DELETE FROM schema_migrations WHERE version = ?`, version)
+	if err != nil {
+		return fmt.Errorf("error logging migration %d (%s): %v", version, m.name, err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	if m.down != "" {
+		if _, err := tx.Exec(m.down); err != nil {
+			return fmt.Errorf("error undoing migration %d (%s): %v", version, m.name, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error undoing migration %d (%s): %v", version, m.name, err)
+	}
+	return nil
+}
+
+// alreadyApplied reports whether err is from adding a column a database
+// already has, as databases that predate schema_migrations may
+func alreadyApplied(err error) bool {
+	return strings.Contains(err.Error(), "duplicate column name")
+}
+
+// hasSessions reports whether any session is recorded. A database with no
+// migrations applied and no sessions was only just created, such as by the
+// check every command makes before it runs, and is not worth a snapshot.
+func (d *InternalDB) hasSessions() bool {
+	var found bool
+	err := d.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM pomodoros)`).Scan(&found)
+	return err != nil || found
+}
+
+// backupBeforeMigration snapshots the database at dbPath, at schema version,
+// and removes the oldest snapshots beyond keepMigrationBackups
+func backupBeforeMigration(db *sql.DB, dbPath string, version int) error {
+	dir := MigrationBackupDir(dbPath)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("error creating migration backup dir: %v", err)
+	}
+	name := fmt.Sprintf("%s-v%d-%s.db", filepath.Base(dbPath), version, time.Now().Format(backupStamp))
+	target := filepath.Join(dir, name)
+	if _, err := db.Exec(`VACUUM INTO ?`, target); err != nil {
+		// Another process opening the database took the same snapshot
+		if _, statErr := os.Stat(target); statErr != nil {
+			return fmt.Errorf("error backing up database before migrating: %v", err)
+		}
+	}
+
+	backups, err := MigrationBackups(dbPath)
+	if err != nil {
+		return err
+	}
+	for _, old := range backups[min(len(backups), keepMigrationBackups):] {
+		_ = os.Remove(old.Path)
+	}
+	return nil
+}
+
+// MigrationBackups lists the pre-migration snapshots of the database at
+// dbPath, newest first
+func MigrationBackups(dbPath string) ([]MigrationBackup, error) {
+	entries, err := os.ReadDir(MigrationBackupDir(dbPath))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading migration backups: %v", err)
+	}
+
+	var backups []MigrationBackup
+	for _, e := range entries {
+		m := backupName.FindStringSubmatch(e.Name())
+		if m == nil || m[1] != filepath.Base(dbPath) {
+			continue
+		}
+		version, _ := strconv.Atoi(m[2])
+		created, err := time.ParseInLocation(backupStamp, m[3], time.Local)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, MigrationBackup{
+			Path:      filepath.Join(MigrationBackupDir(dbPath), e.Name()),
+			Version:   version,
+			CreatedAt: created,
+		})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].CreatedAt.After(backups[j].CreatedAt) })
+	return backups, nil
+}
+
+// Rollback replaces the database at dbPath with a pre-migration snapshot.
+// The database being replaced is kept alongside as a .bak copy, whose path
+// is returned. Nothing may have the database open.
+func Rollback(dbPath string, backup MigrationBackup) (string, error) {
+	data, err := os.ReadFile(backup.Path) // #nosec G304 - backups are listed from the database's own directory
+	if err != nil {
+		return "", fmt.Errorf("error reading backup: %v", err)
+	}
+
+	// The WAL goes with the database set aside, as it would corrupt the
+	// snapshot
+	kept := dbPath + ".bak-" + time.Now().Format(backupStamp)
+	if err := os.Rename(dbPath, kept); err != nil {
+		return "", fmt.Errorf("error keeping the current database: %v", err)
+	}
+	for _, ext := range []string{"-wal", "-shm"} {
+		if err := os.Rename(dbPath+ext, kept+ext); err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("error keeping the current database: %v", err)
+		}
+	}
+	if err := os.WriteFile(dbPath, data, 0600); err != nil {
+		return "", fmt.Errorf("error restoring backup: %v", err)
+	}
+	return kept, nil
+}
@@ -0,0 +1,232 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMigrationBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	open := func() *InternalDB {
+		t.Helper()
+		database, err := NewDB(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return database
+	}
+
+	// A new database has nothing worth backing up
+	database := open()
+	start := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	if _, err := database.CreateSession(start, start.Add(25*time.Minute), "Before", 1500, "", false); err != nil {
+		t.Fatal(err)
+	}
+	if backups, err := MigrationBackups(path); err != nil || len(backups) != 0 {
+		t.Fatalf("Expected no backups of a new database, got %+v, %v", backups, err)
+	}
+
+	// As if it were written by a build with fewer migrations
+	if err := database.MigrateTo(2); err != nil {
+		t.Fatal(err)
+	}
+	_ = database.Close()
+	database = open()
+	backups, err := MigrationBackups(path)
+	if err != nil || len(backups) != 1 || backups[0].Version != 2 {
+		t.Fatalf("Expected a backup at version 2, got %+v, %v", backups, err)
+	}
+	if _, err := database.CreateSession(start.Add(time.Hour), start.Add(time.Hour+25*time.Minute), "After", 1500, "", false); err != nil {
+		t.Fatal(err)
+	}
+	_ = database.Close()
+
+	// Once migrated, opening it takes no more backups
+	database = open()
+	_ = database.Close()
+	if backups, _ := MigrationBackups(path); len(backups) != 1 {
+		t.Fatalf("Expected no backup of an up-to-date database, got %+v", backups)
+	}
+
+	kept, err := Rollback(path, backups[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(kept); err != nil {
+		t.Errorf("Expected the replaced database to be kept: %v", err)
+	}
+	database = open()
+	defer func() { _ = database.Close() }()
+	sessions, err := database.GetRecentSessions(10, true)
+	if err != nil || len(sessions) != 1 || sessions[0].Description != "Before" {
+		t.Errorf("Expected only the session from before the migration, got %+v, %v", sessions, err)
+	}
+}
+
+func TestMigrationBackupFirstRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+
+	// Every command's check opens the database without migrating first,
+	// creating it on a first run; there is still nothing worth backing up
+	checked, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = checked.Close()
+	database, err := NewDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = database.Close()
+	if backups, err := MigrationBackups(path); err != nil || len(backups) != 0 {
+		t.Errorf("Expected no backup of a database created empty, got %+v, %v", backups, err)
+	}
+}
+
+func TestMigrationBackupRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	dir := MigrationBackupDir(path)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"history.db-v1-20240101-090000.db", "history.db-v2-20240201-090000.db", "history.db-v3-20240301-090000.db", "other.db-v1-20240101-090000.db"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	database, err := NewDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.MigrateTo(4); err != nil {
+		t.Fatal(err)
+	}
+	_ = database.Close()
+	if database, err = NewDB(path); err != nil {
+		t.Fatal(err)
+	}
+	_ = database.Close()
+
+	backups, err := MigrationBackups(path)
+	if err != nil || len(backups) != keepMigrationBackups || backups[0].Version != 4 || backups[2].Version != 2 {
+		t.Errorf("Expected the oldest backup to be removed, got %+v, %v", backups, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "other.db-v1-20240101-090000.db")); err != nil {
+		t.Errorf("Expected another database's backup to be left alone: %v", err)
+	}
+}
+
+// columns lists the columns of table
+func columns(t *testing.T, database *InternalDB, table string) []string {
+	t.Helper()
+	rows, err := database.db.Query(`SELECT name FROM pragma_table_info(?) ORDER BY cid`, table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = rows.Close() }()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestMigrateDownAndUp(t *testing.T) {
+	database, err := NewDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = database.Close() }()
+	full := columns(t, database, "pomodoros")
+
+	statuses, err := database.Migrations()
+	if err != nil || len(statuses) != SchemaVersion {
+		t.Fatalf("Expected %d migrations, got %d, %v", SchemaVersion, len(statuses), err)
+	}
+	for _, s := range statuses {
+		if s.Pending() {
+			t.Errorf("Expected migration %d (%s) to be applied to a new database", s.Version, s.Name)
+		}
+	}
+
+	if err := database.MigrateTo(0); err != nil {
+		t.Fatal(err)
+	}
+	if got := columns(t, database, "pomodoros"); len(got) != 7 {
+		t.Errorf("Expected only the base columns after undoing every migration, got %v", got)
+	}
+	if got := columns(t, database, "tasks"); len(got) != 0 {
+		t.Errorf("Expected the tasks table to be dropped, got %v", got)
+	}
+	if version, err := database.AppliedVersion(); err != nil || version != 0 {
+		t.Errorf("Expected schema version 0, got %d, %v", version, err)
+	}
+
+	if err := database.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	if got := columns(t, database, "pomodoros"); len(got) != len(full) {
+		t.Errorf("Expected the columns %v back, got %v", full, got)
+	}
+	if version, err := database.AppliedVersion(); err != nil || version != SchemaVersion {
+		t.Errorf("Expected schema version %d, got %d, %v", SchemaVersion, version, err)
+	}
+
+	if err := database.MigrateTo(SchemaVersion + 1); err == nil {
+		t.Error("Expected an error migrating to an unknown version")
+	}
+}
+
+func TestMigrateUnloggedDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	database, err := NewDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	if _, err := database.CreateSession(start, start.Add(25*time.Minute), "Old", 1500, "", false); err != nil {
+		t.Fatal(err)
+	}
+
+	// As a build from before migrations were logged left it: every column
+	// added, but nothing counted in user_version
+	if _, err := database.db.Exec(`DROP TABLE schema_migrations; PRAGMA user_version = 0`); err != nil {
+		t.Fatal(err)
+	}
+	_ = database.Close()
+	if database, err = NewDB(path); err != nil {
+		t.Fatalf("Expected a database from before migrations were logged to open, got %v", err)
+	}
+	if version, err := database.AppliedVersion(); err != nil || version != SchemaVersion {
+		t.Errorf("Expected schema version %d, got %d, %v", SchemaVersion, version, err)
+	}
+
+	// And as one that counted its migrations
+	if _, err := database.db.Exec(`DROP TABLE schema_migrations; PRAGMA user_version = 5`); err != nil {
+		t.Fatal(err)
+	}
+	_ = database.Close()
+	if database, err = Open(path); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = database.Close() }()
+	statuses, err := database.Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statuses[4].Pending() || !statuses[5].Pending() {
+		t.Errorf("Expected the 5 counted migrations to be logged as applied, got %+v", statuses[4:6])
+	}
+	if err := database.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	if sessions, err := database.GetRecentSessions(10, true); err != nil || len(sessions) != 1 || sessions[0].UID == "" {
+		t.Errorf("Expected the old session to survive, got %+v, %v", sessions, err)
+	}
+}
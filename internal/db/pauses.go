@@ -0,0 +1,77 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Pause is one interruption of a session
+type Pause struct {
+	PausedAt  time.Time  `json:"paused_at"`
+	ResumedAt *time.Time `json:"resumed_at,omitempty"` // nil while the session is still paused
+}
+
+// Duration returns how long the pause lasted, up to now if still paused
+func (p Pause) Duration(now time.Time) time.Duration {
+	if p.ResumedAt == nil {
+		return now.Sub(p.PausedAt)
+	}
+	return p.ResumedAt.Sub(p.PausedAt)
+}
+
+// GetPauses retrieves a session's pauses in order. Sessions paused before
+// pauses were logged only have a total in TotalPausedDuration.
+func (d *InternalDB) GetPauses(sessionID int64) ([]Pause, error) {
+	rows, err := d.db.Query(
+		`SELECT paused_at, resumed_at FROM session_pauses WHERE session_id = ? ORDER BY id ASC`,
+		sessionID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying pauses: %v", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing rows: %v\n", err)
+		}
+	}()
+
+	var pauses []Pause
+	for rows.Next() {
+		var p Pause
+		if err := rows.Scan(&p.PausedAt, &p.ResumedAt); err != nil {
+			return nil, fmt.Errorf("error scanning pause: %v", err)
+		}
+		pauses = append(pauses, p)
+	}
+
+	return pauses, rows.Err()
+}
+
+// EffectiveFocus returns the time actually spent in the session so far:
+// elapsed wall time up to the end (or now, if earlier) minus pauses
+func (s PomodoroSession) EffectiveFocus(now time.Time) time.Duration {
+	end := s.EndTime
+	if s.IsPaused && s.PausedAt != nil {
+		end = *s.PausedAt
+	}
+	if now.Before(end) {
+		end = now
+	}
+
+	focus := end.Sub(s.StartTime) - time.Duration(s.TotalPausedDuration)*time.Second
+	if focus < 0 {
+		return 0
+	}
+	return focus
+}
+
+// RemainingAtPause returns how much of a paused session's planned duration
+// was left when it was paused. The end time already accounts for earlier
+// pauses and extensions, so this is simply the time from pause to end.
+func (s PomodoroSession) RemainingAtPause() time.Duration {
+	if s.PausedAt == nil || s.PausedAt.After(s.EndTime) {
+		return 0
+	}
+	return s.EndTime.Sub(*s.PausedAt)
+}
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// shortRefLength is the number of UID characters shown in short references
+const shortRefLength = 6
+
+// minRefPrefixLength is the shortest UID prefix accepted when resolving a reference
+const minRefPrefixLength = 3
+
+// dateOrdinalPattern matches date-ordinal references like 2024-06-01.3
+var dateOrdinalPattern = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})\.(\d+)$`)
+
+// newUID generates a random identifier that stays stable across databases
+func newUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating session uid: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ShortRef returns a short, human-friendly reference to the session such as #a3f9c2
+func (s PomodoroSession) ShortRef() string {
+	if len(s.UID) < shortRefLength {
+		return "#" + strconv.FormatInt(s.ID, 10)
+	}
+	return "#" + s.UID[:shortRefLength]
+}
+
+// ResolveSession finds the session identified by a reference. Accepted forms are
+// a numeric ID (42), a UID prefix (#a3f), or a date ordinal (2024-06-01.3, the
+// third session started that day). It returns nil if no session matches.
+func (d *InternalDB) ResolveSession(ref string) (*PomodoroSession, error) {
+	ref = strings.TrimSpace(ref)
+
+	if strings.HasPrefix(ref, "#") {
+		return d.getSessionByUIDPrefix(strings.ToLower(ref[1:]))
+	}
+
+	if m := dateOrdinalPattern.FindStringSubmatch(ref); m != nil {
+		ordinal, err := strconv.Atoi(m[2])
+		if err != nil || ordinal < 1 {
+			return nil, fmt.Errorf("invalid session reference %q: ordinal must be at least 1", ref)
+		}
+		return d.getSessionByDateOrdinal(m[1], ordinal)
+	}
+
+	id, err := strconv.ParseInt(ref, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session reference %q: use an ID (42), a short ref (#a3f), or a date ordinal (2024-06-01.3)", ref)
+	}
+	return d.GetSessionByID(id)
+}
+
+// getSessionByUIDPrefix finds the single session whose UID starts with prefix
+func (d *InternalDB) getSessionByUIDPrefix(prefix string) (*PomodoroSession, error) {
+	if len(prefix) < minRefPrefixLength {
+		return nil, fmt.Errorf("short reference #%s is too short: use at least %d characters", prefix, minRefPrefixLength)
+	}
+	if _, err := hex.DecodeString(prefix + strings.Repeat("0", len(prefix)%2)); err != nil {
+		return nil, fmt.Errorf("invalid short reference #%s: must be hexadecimal", prefix)
+	}
+
+	rows, err := d.db.Query(
+		`SELECT `+sessionColumns+`
+		FROM pomodoros
+		WHERE uid LIKE ? || '%'
+		LIMIT 2`,
+		prefix,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying session #%s: %v", prefix, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var matches []*PomodoroSession
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning session: %v", err)
+		}
+		matches = append(matches, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying session #%s: %v", prefix, err)
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("short reference #%s is ambiguous: use more characters", prefix)
+	}
+}
+
+// getSessionByDateOrdinal finds the nth session (1-based) started on the given day
+func (d *InternalDB) getSessionByDateOrdinal(day string, ordinal int) (*PomodoroSession, error) {
+	session, err := scanSession(d.db.QueryRow(
+		`SELECT `+sessionColumns+`
+		FROM pomodoros
+		WHERE `+localDay+` = ?
+		ORDER BY julianday(start_time) ASC, id ASC
+		LIMIT 1 OFFSET ?`,
+		day, ordinal-1,
+	))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying session %s.%d: %v", day, ordinal, err)
+	}
+
+	return session, nil
+}
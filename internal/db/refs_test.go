@@ -0,0 +1,80 @@
+package db
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestDB opens a database under a temporary home directory
+func newTestDB(t *testing.T) *InternalDB {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	database, err := NewDB("")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = database.Close()
+	})
+	return database
+}
+
+func TestResolveSession(t *testing.T) {
+	database := newTestDB(t)
+
+	day := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	var ids []int64
+	for i := 0; i < 3; i++ {
+		start := day.Add(time.Duration(i) * time.Hour)
+		id, err := database.CreateSession(start, start.Add(25*time.Minute), "Task", 1500, "", false)
+		if err != nil {
+			t.Fatalf("Failed to create session: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	second, err := database.GetSessionByID(ids[1])
+	if err != nil || second == nil {
+		t.Fatalf("Failed to load session: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		ref      string
+		expected int64
+	}{
+		{name: "Numeric ID", ref: "2", expected: ids[1]},
+		{name: "Short ref", ref: second.ShortRef(), expected: ids[1]},
+		{name: "Short ref prefix", ref: "#" + second.UID[:4], expected: ids[1]},
+		{name: "Uppercase short ref", ref: strings.ToUpper(second.ShortRef()), expected: ids[1]},
+		{name: "Date ordinal", ref: "2024-06-01.3", expected: ids[2]},
+		{name: "Date ordinal out of range", ref: "2024-06-01.4", expected: 0},
+		{name: "Unknown ID", ref: "99", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, err := database.ResolveSession(tt.ref)
+			if err != nil {
+				t.Fatalf("Expected no error for %q, got: %v", tt.ref, err)
+			}
+			if tt.expected == 0 {
+				if session != nil {
+					t.Errorf("Expected no session for %q, got ID %d", tt.ref, session.ID)
+				}
+				return
+			}
+			if session == nil || session.ID != tt.expected {
+				t.Errorf("Expected session %d for %q, got: %+v", tt.expected, tt.ref, session)
+			}
+		})
+	}
+
+	for _, ref := range []string{"#ab", "#xyz1", "2024-06-01.0", "latest"} {
+		if _, err := database.ResolveSession(ref); err == nil {
+			t.Errorf("Expected error for invalid reference %q", ref)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SearchOptions narrows a search of the session history
+type SearchOptions struct {
+	Query             string    // Words that must all be in the description, in any case; "quoted words" must appear together
+	Tags              []string  // Only sessions with any of these tags
+	From              time.Time // First day searched, zero for no limit
+	To                time.Time // Last day searched, zero for no limit
+	IncludeIncomplete bool      // Also find cancelled and abandoned sessions
+	Limit             int       // Most sessions found, 0 for all
+}
+
+// SearchTerms splits a search query into the words and quoted phrases that
+// must each appear in a matching description
+func SearchTerms(query string) []string {
+	var terms []string
+	for i, part := range strings.Split(query, `"`) {
+		if i%2 == 1 {
+			// Inside quotes
+			if phrase := strings.Join(strings.Fields(part), " "); phrase != "" {
+				terms = append(terms, phrase)
+			}
+			continue
+		}
+		terms = append(terms, strings.Fields(part)...)
+	}
+	return terms
+}
+
+// likeEscaper escapes the wildcards of LIKE, with \ as the escape character
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchSessions finds the sessions matching opts, newest first. Case is
+// ignored for ASCII letters only, as with SQLite's LIKE.
+func (d *InternalDB) SearchSessions(opts SearchOptions) ([]PomodoroSession, error) {
+	conditions := []string{"1 = 1"}
+	var params []any
+	for _, term := range SearchTerms(opts.Query) {
+		conditions = append(conditions, `description LIKE ? ESCAPE '\'`)
+		params = append(params, "%"+likeEscaper.Replace(term)+"%")
+	}
+	if len(opts.Tags) > 0 {
+		tagged, tagParams := taggedWith(opts.Tags)
+		conditions = append(conditions, tagged)
+		params = append(params, tagParams...)
+	}
+	if !opts.From.IsZero() {
+		conditions = append(conditions, localDay+` >= ?`)
+		params = append(params, dayParam(opts.From))
+	}
+	if !opts.To.IsZero() {
+		conditions = append(conditions, localDay+` <= ?`)
+		params = append(params, dayParam(opts.To))
+	}
+	if !opts.IncludeIncomplete {
+		conditions = append(conditions, `COALESCE(status, '') NOT IN ('`+StatusCancelled+`', '`+StatusAbandoned+`')`)
+	}
+	limit := ""
+	if opts.Limit > 0 {
+		limit = ` LIMIT ` + strconv.Itoa(opts.Limit)
+	}
+
+	rows, err := d.db.Query(
+		`SELECT `+sessionColumns+`
+		FROM pomodoros
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY julianday(start_time) DESC`+limit,
+		params...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error searching sessions: %v", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing rows: %v\n", err)
+		}
+	}()
+
+	var sessions []PomodoroSession
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning session: %v", err)
+		}
+		sessions = append(sessions, *session)
+	}
+	return sessions, rows.Err()
+}
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSearchTerms(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		want  []string
+	}{
+		{"api refactor", []string{"api", "refactor"}},
+		{`"api  refactor" auth`, []string{"api refactor", "auth"}},
+		{`auth "unclosed phrase`, []string{"auth", "unclosed phrase"}},
+		{`  "" `, nil},
+	} {
+		if got := SearchTerms(tc.query); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("SearchTerms(%q) = %q, want %q", tc.query, got, tc.want)
+		}
+	}
+}
+
+func TestSearchSessions(t *testing.T) {
+	database := newTestDB(t)
+	day := time.Date(2024, 6, 3, 9, 0, 0, 0, time.Local)
+
+	create := func(days int, description, tags string) int64 {
+		t.Helper()
+		start := day.AddDate(0, 0, days)
+		id, err := database.CreateSession(start, start.Add(25*time.Minute), description, 25*60, tags, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	refactor := create(0, "API refactor of the auth layer", "backend")
+	docs := create(1, "Refactor docs for the API", "writing")
+	percent := create(2, "Cut build time by 100%", "backend")
+	create(3, "Cut build time by 1000 seconds", "backend")
+	cancelled := create(4, "api refactor, take two", "backend")
+	if err := database.EndSession(cancelled, day.AddDate(0, 0, 4).Add(5*time.Minute), StatusCancelled); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		opts SearchOptions
+		want []int64
+	}{
+		{"words in any order and case", SearchOptions{Query: "refactor api"}, []int64{docs, refactor}},
+		{"phrase", SearchOptions{Query: `"api refactor"`}, []int64{refactor}},
+		{"tags", SearchOptions{Query: "refactor", Tags: []string{"backend"}}, []int64{refactor}},
+		{"dates", SearchOptions{Query: "api", From: day.AddDate(0, 0, 1), To: day.AddDate(0, 0, 1)}, []int64{docs}},
+		{"wildcards taken literally", SearchOptions{Query: "100%"}, []int64{percent}},
+		{"cancelled", SearchOptions{Query: "refactor", IncludeIncomplete: true}, []int64{cancelled, docs, refactor}},
+		{"limit", SearchOptions{Query: "refactor", Limit: 1}, []int64{docs}},
+		{"tags alone", SearchOptions{Tags: []string{"writing"}}, []int64{docs}},
+	} {
+		sessions, err := database.SearchSessions(tc.opts)
+		if err != nil {
+			t.Fatalf("%s: SearchSessions failed: %v", tc.name, err)
+		}
+		var got []int64
+		for _, s := range sessions {
+			got = append(got, s.ID)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: found %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
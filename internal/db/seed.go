@@ -0,0 +1,154 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"time"
+)
+
+// seedDescriptions and seedTags are the pools synthetic sessions draw from
+var (
+	seedDescriptions = []string{
+		"Write report", "Code review", "Fix flaky tests", "Plan sprint", "Answer email",
+		"Refactor parser", "Read paper", "Update docs", "Design API", "Debug crash",
+		"Prepare slides", "Study Spanish", "Write blog post", "Triage issues", "Pair programming",
+	}
+	seedTags = []string{"work", "coding", "writing", "review", "learning", "admin", "meetings", "research"}
+)
+
+// SeedOptions controls the synthetic history created by Seed
+type SeedOptions struct {
+	Sessions int       // Number of sessions to create, pomodoros and breaks together
+	End      time.Time // The history runs up to the day before End
+	Seed     uint64    // Random seed; the same seed and End give the same history
+}
+
+// Seed fills the database with realistic synthetic history for benchmarks
+// and load testing: working days of 25 or 50 minute pomodoros separated by
+// short and long breaks, quieter weekends, occasional pauses, energy levels,
+// and some days recorded in another time zone. It works backwards from End
+// and returns the number of sessions created.
+func (d *InternalDB) Seed(opts SeedOptions) (int, error) {
+	r := rand.New(rand.NewPCG(opts.Seed, opts.Seed^0x9e3779b97f4a7c15)) // #nosec G404 - synthetic data, not security sensitive
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	before, err := maxSessionID(tx)
+	if err != nil {
+		return 0, err
+	}
+	insertSession, err := tx.Prepare(`INSERT INTO pomodoros(start_time, end_time, description, duration_secs, tags_csv, was_break,
+		total_paused_duration, is_paused, uid, tz_offset, tz_name)
+		VALUES(?, ?, ?, ?, ?, ?, ?, 0, ?, ?, ?)`)
+	if err != nil {
+		return 0, fmt.Errorf("error preparing insert: %v", err)
+	}
+	insertPause, err := tx.Prepare(`INSERT INTO session_pauses(session_id, paused_at, resumed_at) VALUES(?, ?, ?)`)
+	if err != nil {
+		return 0, fmt.Errorf("error preparing insert: %v", err)
+	}
+	insertMetadata, err := tx.Prepare(`INSERT INTO session_metadata(session_id, key, value) VALUES(?, ?, ?)`)
+	if err != nil {
+		return 0, fmt.Errorf("error preparing insert: %v", err)
+	}
+
+	home := opts.End.Location()
+	day := time.Date(opts.End.Year(), opts.End.Month(), opts.End.Day(), 0, 0, 0, 0, home)
+	created := 0
+
+	for created < opts.Sessions {
+		day = day.AddDate(0, 0, -1)
+
+		// A day in twenty is spent travelling in another zone
+		loc := home
+		if r.IntN(20) == 0 {
+			loc = time.FixedZone("TRV", (r.IntN(25)-12)*3600)
+		}
+
+		pomodoros := 6 + r.IntN(9)
+		if wd := day.Weekday(); wd == time.Saturday || wd == time.Sunday {
+			pomodoros = r.IntN(5)
+		}
+
+		t := time.Date(day.Year(), day.Month(), day.Day(), 8+r.IntN(3), r.IntN(60), 0, 0, loc)
+		for i := 0; i < pomodoros && created < opts.Sessions; i++ {
+			length := 25 * time.Minute
+			if r.IntN(5) == 0 {
+				length = 50 * time.Minute
+			}
+
+			var paused time.Duration
+			if r.IntN(10) == 0 {
+				paused = time.Duration(1+r.IntN(10)) * time.Minute
+			}
+
+			tags := seedTags[r.IntN(len(seedTags))]
+			if r.IntN(3) == 0 {
+				tags += "," + seedTags[r.IntN(len(seedTags))]
+			}
+
+			end := t.Add(length + paused)
+			id, err := insertSeedSession(insertSession, r, t, end, seedDescriptions[r.IntN(len(seedDescriptions))], length, tags, false, paused)
+			if err != nil {
+				return 0, err
+			}
+			created++
+
+			if paused > 0 {
+				pausedAt := t.Add(time.Duration(r.Int64N(int64(length))))
+				if _, err := insertPause.Exec(id, pausedAt, pausedAt.Add(paused)); err != nil {
+					return 0, fmt.Errorf("error inserting pause: %v", err)
+				}
+			}
+			if r.IntN(4) == 0 {
+				if _, err := insertMetadata.Exec(id, MetaEnergyStart, strconv.Itoa(1+r.IntN(5))); err != nil {
+					return 0, fmt.Errorf("error inserting metadata: %v", err)
+				}
+			}
+
+			// A short break after each pomodoro, a long one after every fourth
+			t = end
+			if created >= opts.Sessions || i == pomodoros-1 {
+				break
+			}
+			breakLength := 5 * time.Minute
+			if (i+1)%4 == 0 {
+				breakLength = 15 * time.Minute
+			}
+			if _, err := insertSeedSession(insertSession, r, t, t.Add(breakLength), "Break", breakLength, "", true, 0); err != nil {
+				return 0, err
+			}
+			created++
+
+			// People rarely start the next pomodoro the moment a break ends
+			t = t.Add(breakLength + time.Duration(r.IntN(10))*time.Minute)
+		}
+	}
+
+	if _, err := tx.Exec(fillSessionTags("id > " + strconv.FormatInt(before, 10))); err != nil {
+		return 0, fmt.Errorf("error adding tags: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing seed data: %v", err)
+	}
+	return created, nil
+}
+
+// insertSeedSession inserts one synthetic session and returns its ID
+func insertSeedSession(stmt *sql.Stmt, r *rand.Rand, start, end time.Time, description string, length time.Duration, tags string, wasBreak bool, paused time.Duration) (int64, error) {
+	tzName, tzOffset := start.Zone()
+	uid := fmt.Sprintf("%016x%016x", r.Uint64(), r.Uint64())
+
+	res, err := stmt.Exec(start, end, description, int64(length.Seconds()), tags, wasBreak,
+		int64(paused.Seconds()), uid, tzOffset, tzName)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting session: %v", err)
+	}
+	return res.LastInsertId()
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeedCreatesRealisticHistory(t *testing.T) {
+	database := newTestDB(t)
+	end := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+
+	created, err := database.Seed(SeedOptions{Sessions: 500, End: end, Seed: 7})
+	if err != nil {
+		t.Fatalf("Seed failed: %v", err)
+	}
+	if created != 500 {
+		t.Errorf("Expected 500 sessions, got %d", created)
+	}
+
+	sessions, err := database.GetSessionsByDateRange(end.AddDate(-1, 0, 0), end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 500 {
+		t.Fatalf("Expected all 500 sessions within a year, got %d", len(sessions))
+	}
+
+	breaks := 0
+	for _, s := range sessions {
+		if !s.StartTime.Before(end) {
+			t.Errorf("Expected sessions before %s, got one at %s", end, s.StartTime)
+		}
+		if s.WasBreak {
+			breaks++
+		}
+	}
+	if breaks == 0 || breaks >= len(sessions)/2+1 {
+		t.Errorf("Expected fewer breaks than pomodoros, got %d of %d", breaks, len(sessions))
+	}
+}
@@ -0,0 +1,181 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// focusSeconds is the SQL expression for the time a session actually ran,
+// excluding pauses
+const focusSeconds = `((julianday(end_time) - julianday(start_time)) * 86400 - COALESCE(total_paused_duration, 0))`
+
+// completedSession is the SQL condition for a session that ran to its end.
+// Sessions without a status yet count when they ran their full planned
+// length, with a second's slack for rounding in stored timestamps.
+const completedSession = `(status = 'completed' OR (COALESCE(status, '') = '' AND ` + focusSeconds + ` >= duration_secs - 1))`
+
+// finishedInRange restricts a query to sessions in a date range that are no
+// longer running or paused. It takes the start day, end day, and now.
+const finishedInRange = localDay + ` >= ? AND ` + localDay + ` <= ?
+	AND is_paused = 0 AND julianday(end_time) <= julianday(?)`
+
+// SessionStats aggregates the finished sessions in a date range
+type SessionStats struct {
+	Pomodoros int   // Pomodoros that ran to the end or were cancelled
+	Completed int   // Pomodoros that ran their full planned length or were finished early
+	FocusSec  int64 // Time spent in pomodoros, excluding pauses
+	Breaks    int
+	BreakSec  int64
+	Days      int // Days with at least one pomodoro
+}
+
+// CompletionRate returns the share of pomodoros that ran their full length,
+// from 0 to 1
+func (s SessionStats) CompletionRate() float64 {
+	if s.Pomodoros == 0 {
+		return 0
+	}
+	return float64(s.Completed) / float64(s.Pomodoros)
+}
+
+// AverageFocus returns the average time spent in a pomodoro
+func (s SessionStats) AverageFocus() time.Duration {
+	if s.Pomodoros == 0 {
+		return 0
+	}
+	return time.Duration(s.FocusSec/int64(s.Pomodoros)) * time.Second
+}
+
+// TagStats aggregates the finished pomodoros carrying one tag
+type TagStats struct {
+	Tag       string
+	Pomodoros int
+	FocusSec  int64
+}
+
+// HourStats aggregates the finished pomodoros started in one hour of the
+// day, in the time zone where each was recorded
+type HourStats struct {
+	Hour      int
+	Pomodoros int
+	FocusSec  int64
+}
+
+// GetSessionStats aggregates the sessions in the date range that have finished
+func (d *InternalDB) GetSessionStats(startDate, endDate time.Time) (*SessionStats, error) {
+	var stats SessionStats
+	var focus, breaks float64
+	err := d.db.QueryRow(
+		`SELECT
+			COALESCE(SUM(was_break = 0), 0),
+			COALESCE(SUM(was_break = 0 AND `+completedSession+`), 0),
+			COALESCE(SUM(CASE WHEN was_break = 0 THEN `+focusSeconds+` END), 0),
+			COALESCE(SUM(was_break = 1), 0),
+			COALESCE(SUM(CASE WHEN was_break = 1 THEN `+focusSeconds+` END), 0),
+			COUNT(DISTINCT CASE WHEN was_break = 0 THEN `+localDay+` END)
+		FROM pomodoros
+		WHERE `+finishedInRange,
+		dayParam(startDate), dayParam(endDate), time.Now(),
+	).Scan(&stats.Pomodoros, &stats.Completed, &focus, &stats.Breaks, &breaks, &stats.Days)
+	if err != nil {
+		return nil, fmt.Errorf("error querying session stats: %v", err)
+	}
+
+	stats.FocusSec = int64(focus + 0.5)
+	stats.BreakSec = int64(breaks + 0.5)
+	return &stats, nil
+}
+
+// GetCycleCount returns how many pomodoros have been completed since the
+// last long break ended the previous cycle. Pomodoros cancelled early do not
+// count toward a cycle.
+func (d *InternalDB) GetCycleCount() (int, error) {
+	var count int
+	err := d.db.QueryRow(
+		`SELECT COUNT(*) FROM pomodoros
+		WHERE was_break = 0 AND is_paused = 0
+			AND julianday(end_time) <= julianday(?)
+			AND `+completedSession+`
+			AND julianday(start_time) > COALESCE((
+				SELECT MAX(julianday(p.start_time))
+				FROM pomodoros p JOIN session_metadata m ON m.session_id = p.id
+				WHERE m.key = ? AND m.value = ?
+			), 0)`,
+		time.Now(), MetaBreakKind, BreakKindLong,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error counting pomodoros in cycle: %v", err)
+	}
+	return count, nil
+}
+
+// GetTagStats aggregates the finished pomodoros in the date range by tag,
+// most focused first. A pomodoro with several tags counts toward each.
+func (d *InternalDB) GetTagStats(startDate, endDate time.Time) ([]TagStats, error) {
+	rows, err := d.db.Query(
+		`SELECT t.name, COUNT(*), SUM(`+focusSeconds+`) AS focus
+		FROM pomodoros
+		JOIN session_tags st ON st.session_id = pomodoros.id
+		JOIN tags t ON t.id = st.tag_id
+		WHERE was_break = 0 AND `+finishedInRange+`
+		GROUP BY t.id
+		ORDER BY focus DESC, t.name`,
+		dayParam(startDate), dayParam(endDate), time.Now(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying tag stats: %v", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing rows: %v\n", err)
+		}
+	}()
+
+	var tags []TagStats
+	for rows.Next() {
+		var t TagStats
+		var focus float64
+		if err := rows.Scan(&t.Tag, &t.Pomodoros, &focus); err != nil {
+			return nil, fmt.Errorf("error scanning tag stats: %v", err)
+		}
+		t.FocusSec = int64(focus + 0.5)
+		tags = append(tags, t)
+	}
+
+	return tags, rows.Err()
+}
+
+// GetHourlyStats aggregates the finished pomodoros in the date range by the
+// hour they started, for hours with at least one pomodoro
+func (d *InternalDB) GetHourlyStats(startDate, endDate time.Time) ([]HourStats, error) {
+	rows, err := d.db.Query(
+		`SELECT `+localHour+` AS hour, COUNT(*), SUM(`+focusSeconds+`)
+		FROM pomodoros
+		WHERE was_break = 0 AND `+finishedInRange+`
+		GROUP BY hour
+		ORDER BY hour`,
+		dayParam(startDate), dayParam(endDate), time.Now(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying hourly stats: %v", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing rows: %v\n", err)
+		}
+	}()
+
+	var hours []HourStats
+	for rows.Next() {
+		var h HourStats
+		var focus float64
+		if err := rows.Scan(&h.Hour, &h.Pomodoros, &focus); err != nil {
+			return nil, fmt.Errorf("error scanning hourly stats: %v", err)
+		}
+		h.FocusSec = int64(focus + 0.5)
+		hours = append(hours, h)
+	}
+
+	return hours, rows.Err()
+}
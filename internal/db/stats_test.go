@@ -0,0 +1,125 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionStats(t *testing.T) {
+	database := newTestDB(t)
+	day := time.Date(2024, 6, 3, 0, 0, 0, 0, time.FixedZone("CEST", 2*3600))
+	at := func(hour, minute int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+
+	create := func(start, end time.Time, planned time.Duration, tags string, wasBreak bool) int64 {
+		t.Helper()
+		id, err := database.CreateSession(start, end, "Work", int64(planned.Seconds()), tags, wasBreak)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+
+	create(at(9, 0), at(9, 25), 25*time.Minute, "work,coding", false)
+	create(at(9, 25), at(9, 30), 5*time.Minute, "", true)
+	create(at(9, 30), at(9, 55), 25*time.Minute, "work", false)
+	// Cancelled after 10 minutes
+	create(at(14, 0), at(14, 10), 25*time.Minute, "writing", false)
+	// Paused for 5 minutes, so it ends 5 minutes late but still completes
+	paused := create(at(15, 0), at(15, 30), 25*time.Minute, "work", false)
+	if _, err := database.db.Exec(`UPDATE pomodoros SET total_paused_duration = 300 WHERE id = ?`, paused); err != nil {
+		t.Fatal(err)
+	}
+	// Another day, outside the range
+	create(at(33, 0), at(33, 25), 25*time.Minute, "work", false)
+
+	stats, err := database.GetSessionStats(day, day)
+	if err != nil {
+		t.Fatalf("GetSessionStats failed: %v", err)
+	}
+	want := SessionStats{Pomodoros: 4, Completed: 3, FocusSec: 85 * 60, Breaks: 1, BreakSec: 5 * 60, Days: 1}
+	if *stats != want {
+		t.Errorf("Expected %+v, got %+v", want, *stats)
+	}
+	if rate := stats.CompletionRate(); rate != 0.75 {
+		t.Errorf("Expected a completion rate of 0.75, got %v", rate)
+	}
+
+	tags, err := database.GetTagStats(day, day)
+	if err != nil {
+		t.Fatalf("GetTagStats failed: %v", err)
+	}
+	wantTags := []TagStats{{"work", 3, 75 * 60}, {"coding", 1, 25 * 60}, {"writing", 1, 10 * 60}}
+	if len(tags) != len(wantTags) {
+		t.Fatalf("Expected %v, got %v", wantTags, tags)
+	}
+	for i := range tags {
+		if tags[i] != wantTags[i] {
+			t.Errorf("Expected %+v, got %+v", wantTags[i], tags[i])
+		}
+	}
+
+	// Hours are those where each session was recorded, not UTC
+	hours, err := database.GetHourlyStats(day, day)
+	if err != nil {
+		t.Fatalf("GetHourlyStats failed: %v", err)
+	}
+	wantHours := []HourStats{{9, 2, 50 * 60}, {14, 1, 10 * 60}, {15, 1, 25 * 60}}
+	if len(hours) != len(wantHours) {
+		t.Fatalf("Expected %v, got %v", wantHours, hours)
+	}
+	for i := range hours {
+		if hours[i] != wantHours[i] {
+			t.Errorf("Expected %+v, got %+v", wantHours[i], hours[i])
+		}
+	}
+}
+
+func TestCycleCount(t *testing.T) {
+	database := newTestDB(t)
+	start := time.Now().Add(-5 * time.Hour)
+
+	next := func(length, planned time.Duration, wasBreak bool) int64 {
+		t.Helper()
+		id, err := database.CreateSession(start, start.Add(length), "Work", int64(planned.Seconds()), "", wasBreak)
+		if err != nil {
+			t.Fatal(err)
+		}
+		start = start.Add(length)
+		return id
+	}
+	count := func() int {
+		t.Helper()
+		n, err := database.GetCycleCount()
+		if err != nil {
+			t.Fatalf("GetCycleCount failed: %v", err)
+		}
+		return n
+	}
+
+	next(25*time.Minute, 25*time.Minute, false)
+	next(5*time.Minute, 5*time.Minute, true)
+	next(25*time.Minute, 25*time.Minute, false)
+	// Cancelled pomodoros do not count
+	next(10*time.Minute, 25*time.Minute, false)
+	if n := count(); n != 2 {
+		t.Errorf("Expected 2 pomodoros in the cycle, got %d", n)
+	}
+
+	long := next(15*time.Minute, 15*time.Minute, true)
+	if err := database.SetSessionMetadata(long, MetaBreakKind, BreakKindLong); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(); n != 0 {
+		t.Errorf("Expected a long break to start a new cycle, got %d", n)
+	}
+
+	next(25*time.Minute, 25*time.Minute, false)
+	// A pomodoro still running does not count yet
+	start = time.Now().Add(-time.Minute)
+	next(25*time.Minute, 25*time.Minute, false)
+	if n := count(); n != 1 {
+		t.Errorf("Expected 1 pomodoro in the new cycle, got %d", n)
+	}
+}
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSessionStatus(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	database, err := NewDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	create := func(offset, length time.Duration) int64 {
+		t.Helper()
+		id, err := database.CreateSession(start.Add(offset), start.Add(offset+length), "Work", 25*60, "", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	status := func(id int64) string {
+		t.Helper()
+		s, err := database.GetSessionByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s.Status
+	}
+
+	// Sessions from before statuses were recorded
+	full := create(0, 25*time.Minute)
+	short := create(time.Hour, 10*time.Minute)
+	if _, err := database.db.Exec(`UPDATE pomodoros SET status = NULL`); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if database, err = NewDB(path); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = database.Close() }()
+	if status(full) != StatusCompleted || status(short) != StatusCancelled {
+		t.Errorf("Expected old sessions to be completed and cancelled, got %q and %q", status(full), status(short))
+	}
+
+	// Finishing early counts as completed, cancelling does not
+	finished := create(2*time.Hour, 25*time.Minute)
+	if err := database.EndSession(finished, start.Add(2*time.Hour+20*time.Minute), StatusCompleted); err != nil {
+		t.Fatal(err)
+	}
+	cancelled := create(3*time.Hour, 25*time.Minute)
+	if err := database.EndSession(cancelled, start.Add(3*time.Hour+5*time.Minute), StatusCancelled); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := database.GetSessionByID(cancelled); !s.Incomplete() {
+		t.Errorf("Expected the cancelled session to be incomplete, got status %q", s.Status)
+	}
+	if err := database.EndSession(cancelled, start, "skipped"); err == nil {
+		t.Error("Expected an unknown status to be refused")
+	}
+
+	stats, err := database.GetSessionStats(start, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Pomodoros != 4 || stats.Completed != 2 {
+		t.Errorf("Expected 2 of 4 pomodoros completed, got %d of %d", stats.Completed, stats.Pomodoros)
+	}
+}
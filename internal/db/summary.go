@@ -0,0 +1,101 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// DailySummary is what a day added up to, kept in daily_summary so streaks
+// and records cover the whole history, even days since pruned
+type DailySummary struct {
+	Date      string // Local day, YYYY-MM-DD
+	Pomodoros int    // Finished pomodoros that were not cancelled or abandoned
+	GoalMet   bool   // The daily goal was reached that day
+}
+
+// countedPomodoro is the SQL condition for a session that counts toward its
+// day's summary, as it does toward goals
+const countedPomodoro = `was_break = 0 AND COALESCE(status, '') NOT IN ('cancelled', 'abandoned')
+	AND is_paused = 0 AND julianday(end_time) <= julianday('now')`
+
+// summarizeDays returns the statement that recounts the pomodoros of every
+// day with a session matching where, keeping whether the goal was met
+func summarizeDays(where string) string {
+	return `INSERT INTO daily_summary (date, pomodoro_count)
+		SELECT ` + localDay + `, SUM(CASE WHEN ` + countedPomodoro + ` THEN 1 ELSE 0 END)
+		FROM pomodoros WHERE ` + localDay + ` IN (SELECT ` + localDay + ` FROM pomodoros WHERE ` + where + `)
+		GROUP BY 1
+		ON CONFLICT(date) DO UPDATE SET pomodoro_count = excluded.pomodoro_count;`
+}
+
+// summarizeDay recounts the pomodoros of one day, given twice, which may no
+// longer have any sessions
+const summarizeDay = `INSERT INTO daily_summary (date, pomodoro_count)
+	SELECT ?, (SELECT count(*) FROM pomodoros WHERE ` + localDay + ` = ? AND ` + countedPomodoro + `)
+	WHERE true
+	ON CONFLICT(date) DO UPDATE SET pomodoro_count = excluded.pomodoro_count;`
+
+// refreshSummaries recounts the pomodoros of each day given
+func refreshSummaries(e execer, days ...string) error {
+	for _, day := range days {
+		if _, err := e.Exec(summarizeDay, day, day); err != nil {
+			return fmt.Errorf("error updating the summary of %s: %v", day, err)
+		}
+	}
+	return nil
+}
+
+// refreshSessionSummary recounts the pomodoros of the day session id started on
+func refreshSessionSummary(e execer, id int64) error {
+	if _, err := e.Exec(summarizeDays(`id = ?`), id); err != nil {
+		return fmt.Errorf("error updating the daily summary: %v", err)
+	}
+	return nil
+}
+
+// RecordDailySummary recounts the pomodoros of day, as a pomodoro finishes,
+// and records the daily goal as met once the count reaches goal. A goal met
+// stays met, as an achievement would. It returns the day's summary.
+func (d *InternalDB) RecordDailySummary(day time.Time, goal int) (*DailySummary, error) {
+	date := dayParam(day)
+	if err := refreshSummaries(d.db, date); err != nil {
+		return nil, err
+	}
+	if goal > 0 {
+		if _, err := d.db.Exec(`UPDATE daily_summary SET goal_met = 1 WHERE date = ? AND pomodoro_count >= ?`, date, goal); err != nil {
+			return nil, fmt.Errorf("error recording the daily goal: %v", err)
+		}
+	}
+
+	summary := &DailySummary{Date: date}
+	if err := d.db.QueryRow(`SELECT pomodoro_count, goal_met FROM daily_summary WHERE date = ?`, date).
+		Scan(&summary.Pomodoros, &summary.GoalMet); err != nil {
+		return nil, fmt.Errorf("error reading the daily summary: %v", err)
+	}
+	return summary, nil
+}
+
+// GetDailySummaries returns the summary of every day with a pomodoro, oldest
+// first
+func (d *InternalDB) GetDailySummaries() ([]DailySummary, error) {
+	rows, err := d.db.Query(`SELECT date, pomodoro_count, goal_met FROM daily_summary WHERE pomodoro_count > 0 ORDER BY date`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying daily summaries: %v", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing rows: %v\n", err)
+		}
+	}()
+
+	var summaries []DailySummary
+	for rows.Next() {
+		var s DailySummary
+		if err := rows.Scan(&s.Date, &s.Pomodoros, &s.GoalMet); err != nil {
+			return nil, fmt.Errorf("error scanning daily summary: %v", err)
+		}
+		summaries = append(summaries, s)
+	}
+	return summaries, rows.Err()
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDailySummaries(t *testing.T) {
+	database := newTestDB(t)
+	day := time.Date(2024, 6, 3, 9, 0, 0, 0, time.Local)
+	create := func(start time.Time, wasBreak bool) int64 {
+		t.Helper()
+		id, err := database.CreateSession(start, start.Add(25*time.Minute), "Work", 25*60, "", wasBreak)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	summaries := func() []DailySummary {
+		t.Helper()
+		s, err := database.GetDailySummaries()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	first := create(day, false)
+	create(day.Add(time.Hour), false)
+	create(day.Add(2*time.Hour), true)
+	cancelled := create(day.Add(3*time.Hour), false)
+	if err := database.SetSessionStatus(cancelled, StatusCancelled); err != nil {
+		t.Fatal(err)
+	}
+	if got := summaries(); len(got) != 1 || got[0] != (DailySummary{Date: "2024-06-03", Pomodoros: 2}) {
+		t.Errorf("Expected 2 pomodoros on 2024-06-03, got %+v", got)
+	}
+
+	summary, err := database.RecordDailySummary(day, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !summary.GoalMet {
+		t.Errorf("Expected the goal of 2 to be met, got %+v", summary)
+	}
+
+	// Moving a pomodoro to the next day recounts both; the goal stays met
+	next := day.AddDate(0, 0, 1)
+	if err := database.EditSession(first, SessionEdit{Description: "Work", StartTime: next, EndTime: next.Add(25 * time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+	want := []DailySummary{{Date: "2024-06-03", Pomodoros: 1, GoalMet: true}, {Date: "2024-06-04", Pomodoros: 1}}
+	if got := summaries(); len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Expected %+v after the edit, got %+v", want, got)
+	}
+
+	if err := database.DeleteSession(first); err != nil {
+		t.Fatal(err)
+	}
+	if got := summaries(); len(got) != 1 || got[0].Date != "2024-06-03" {
+		t.Errorf("Expected only 2024-06-03 left after the delete, got %+v", got)
+	}
+
+	// Pruning keeps the history the summaries hold
+	if _, err := database.Prune(next.AddDate(0, 0, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if got := summaries(); len(got) != 1 || got[0].Pomodoros != 1 {
+		t.Errorf("Expected the summary to outlast pruning, got %+v", got)
+	}
+}
@@ -0,0 +1,141 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+)
+
+// Sessions keep their tags twice: as written, in tags_csv, which is what is
+// shown and exported, and one row each in session_tags, so filtering matches
+// whole tags and "go" does not find "golang". Tags match regardless of case.
+
+// fillSessionTags returns the SQL adding the tag rows of the sessions
+// matching where, a condition on pomodoros, from their tags_csv. Sessions
+// that already have tag rows must not match.
+func fillSessionTags(where string) string {
+	split := `WITH RECURSIVE split(session_id, tag, rest) AS (
+			SELECT id, '', COALESCE(tags_csv, '') || ',' FROM pomodoros WHERE ` + where + `
+			UNION ALL
+			SELECT session_id, trim(substr(rest, 1, instr(rest, ',') - 1)), substr(rest, instr(rest, ',') + 1)
+			FROM split WHERE rest <> ''
+		),
+		session_tag(session_id, tag) AS (SELECT session_id, tag FROM split WHERE tag <> '')`
+	return split + `
+		INSERT OR IGNORE INTO tags(name) SELECT tag FROM session_tag ORDER BY session_id;
+		` + split + `
+		INSERT OR IGNORE INTO session_tags(session_id, tag_id)
+		SELECT s.session_id, t.id FROM session_tag s JOIN tags t ON t.name = s.tag;`
+}
+
+// maxSessionID returns the ID of the newest session, to fill in the tags of
+// sessions added after it in bulk
+func maxSessionID(tx *sql.Tx) (int64, error) {
+	var id int64
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(id), 0) FROM pomodoros`).Scan(&id); err != nil {
+		return 0, fmt.Errorf("error reading sessions: %v", err)
+	}
+	return id, nil
+}
+
+// TagUse is a tag and how much it has been used
+type TagUse struct {
+	Name     string
+	Sessions int
+	LastUsed time.Time
+}
+
+// execer runs statements on the database or in a transaction
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// splitTags returns the distinct tags in tagsCSV, trimmed, keeping the
+// first spelling of tags differing only in case
+func splitTags(tagsCSV string) []string {
+	var tags []string
+	seen := map[string]bool{}
+	for _, tag := range strings.Split(tagsCSV, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !seen[strings.ToLower(tag)] {
+			seen[strings.ToLower(tag)] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// setSessionTags replaces the tag rows of a session with the tags in
+// tagsCSV, adding tags not seen before
+func setSessionTags(ex execer, sessionID int64, tagsCSV string) error {
+	if _, err := ex.Exec(`DELETE FROM session_tags WHERE session_id = ?`, sessionID); err != nil {
+		return fmt.Errorf("error updating tags of session %d: %v", sessionID, err)
+	}
+	for _, tag := range splitTags(tagsCSV) {
+		if _, err := ex.Exec(`INSERT OR IGNORE INTO tags(name) VALUES(?)`, tag); err != nil {
+			return fmt.Errorf("error adding tag %q: %v", tag, err)
+		}
+		if _, err := ex.Exec(`INSERT OR IGNORE INTO session_tags(session_id, tag_id) SELECT ?, id FROM tags WHERE name = ?`,
+			sessionID, tag); err != nil {
+			return fmt.Errorf("error updating tags of session %d: %v", sessionID, err)
+		}
+	}
+	return nil
+}
+
+// taggedWith returns the SQL condition for a session having any of tags,
+// and its parameters
+func taggedWith(tags []string) (string, []any) {
+	marks := make([]string, len(tags))
+	params := make([]any, len(tags))
+	for i, tag := range tags {
+		marks[i] = "?"
+		params[i] = strings.TrimSpace(tag)
+	}
+	return `id IN (SELECT st.session_id FROM session_tags st JOIN tags t ON t.id = st.tag_id
+		WHERE t.name IN (` + strings.Join(marks, ", ") + `))`, params
+}
+
+// GetAllTags lists the tags of recorded sessions, most recently used first
+func (d *InternalDB) GetAllTags() ([]TagUse, error) {
+	rows, err := d.db.Query(
+		`SELECT t.name, COUNT(*), MAX(julianday(p.start_time)) AS last
+		FROM session_tags st
+		JOIN tags t ON t.id = st.tag_id
+		JOIN pomodoros p ON p.id = st.session_id
+		GROUP BY t.id
+		ORDER BY last DESC, t.name`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying tags: %v", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing rows: %v\n", err)
+		}
+	}()
+
+	var tags []TagUse
+	for rows.Next() {
+		var t TagUse
+		var last float64
+		if err := rows.Scan(&t.Name, &t.Sessions, &last); err != nil {
+			return nil, fmt.Errorf("error scanning tag: %v", err)
+		}
+		t.LastUsed = julianTime(last)
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+// julianTime converts a julian day number, as SQLite's julianday returns,
+// to a time. Julian days are only precise to tens of microseconds, so the
+// time is rounded to the millisecond.
+func julianTime(day float64) time.Time {
+	seconds := (day - 2440587.5) * 86400
+	whole, frac := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(frac*1e9)).Round(time.Millisecond).UTC()
+}
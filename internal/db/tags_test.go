@@ -0,0 +1,108 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTagFiltering(t *testing.T) {
+	database := newTestDB(t)
+	day := time.Date(2024, 6, 3, 9, 0, 0, 0, time.Local)
+
+	create := func(offset time.Duration, tags string) int64 {
+		t.Helper()
+		start := day.Add(offset)
+		id, err := database.CreateSession(start, start.Add(25*time.Minute), "Work", 25*60, tags, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	golang := create(0, "golang")
+	goWork := create(time.Hour, "Go, work")
+	writing := create(2*time.Hour, "writing")
+
+	ids := func(tags ...string) []int64 {
+		t.Helper()
+		sessions, err := database.GetSessionsByDateRange(day, day, tags...)
+		if err != nil {
+			t.Fatalf("GetSessionsByDateRange failed: %v", err)
+		}
+		var ids []int64
+		for _, s := range sessions {
+			ids = append(ids, s.ID)
+		}
+		return ids
+	}
+
+	if got := ids("go"); !reflect.DeepEqual(got, []int64{goWork}) {
+		t.Errorf("sessions tagged go = %v, want %v", got, []int64{goWork})
+	}
+	if got := ids("golang", "writing"); !reflect.DeepEqual(got, []int64{writing, golang}) {
+		t.Errorf("sessions tagged golang or writing = %v, want %v", got, []int64{writing, golang})
+	}
+	if got := ids(); len(got) != 3 {
+		t.Errorf("got %d sessions without a tag filter, want 3", len(got))
+	}
+
+	// Editing the tags moves the session between them
+	if err := database.EditSession(writing, SessionEdit{
+		Description: "Work", TagsCSV: "go", StartTime: day.Add(2 * time.Hour), EndTime: day.Add(2*time.Hour + 25*time.Minute),
+	}); err != nil {
+		t.Fatalf("EditSession failed: %v", err)
+	}
+	if got := ids("go"); !reflect.DeepEqual(got, []int64{writing, goWork}) {
+		t.Errorf("sessions tagged go after editing = %v, want %v", got, []int64{writing, goWork})
+	}
+	if got := ids("writing"); got != nil {
+		t.Errorf("sessions tagged writing after editing = %v, want none", got)
+	}
+
+	tags, err := database.GetAllTags()
+	if err != nil {
+		t.Fatalf("GetAllTags failed: %v", err)
+	}
+	var names []string
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	// writing is no longer used
+	if want := []string{"Go", "work", "golang"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("GetAllTags = %v, want %v", names, want)
+	}
+	if tags[0].Sessions != 2 || !tags[0].LastUsed.Equal(day.Add(2*time.Hour)) {
+		t.Errorf("Go was used %d times, last at %v; want 2 times, last at %v", tags[0].Sessions, tags[0].LastUsed, day.Add(2*time.Hour))
+	}
+}
+
+func TestMigrateFillsSessionTags(t *testing.T) {
+	database := newTestDB(t)
+	if err := database.MigrateTo(unloggedMigrations); err != nil {
+		t.Fatalf("MigrateTo failed: %v", err)
+	}
+	start := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	for _, tags := range []string{" go , Go,golang,", "", "writing"} {
+		if _, err := database.db.Exec(`INSERT INTO pomodoros(start_time, end_time, description, duration_secs, tags_csv, was_break)
+			VALUES(?, ?, 'Work', 1500, ?, 0)`, start, start.Add(25*time.Minute), tags); err != nil {
+			t.Fatal(err)
+		}
+		start = start.Add(time.Hour)
+	}
+	if err := database.Migrate(); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+
+	tags, err := database.GetAllTags()
+	if err != nil {
+		t.Fatalf("GetAllTags failed: %v", err)
+	}
+	want := []TagUse{
+		{Name: "writing", Sessions: 1, LastUsed: time.Date(2024, 6, 3, 11, 0, 0, 0, time.UTC)},
+		{Name: "go", Sessions: 1, LastUsed: time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)},
+		{Name: "golang", Sessions: 1, LastUsed: time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)},
+	}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("GetAllTags = %+v, want %+v", tags, want)
+	}
+}
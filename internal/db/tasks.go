@@ -0,0 +1,195 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Task is a piece of work that pomodoros can be linked to
+type Task struct {
+	ID        int64
+	Title     string
+	Estimate  int // Estimated pomodoros, 0 when not estimated
+	CreatedAt time.Time
+	DoneAt    *time.Time // nil while the task is open
+	Pomodoros int        // Completed pomodoros linked to the task
+	FocusSec  int64      // Focus time in finished pomodoros linked to the task
+	Source    string     // File the task was planned from, "" when added by hand
+}
+
+// TaskStats aggregates the finished pomodoros linked to one task in a date range
+type TaskStats struct {
+	TaskID    int64
+	Title     string
+	Estimate  int
+	Pomodoros int // Pomodoros that ran their full planned length
+	FocusSec  int64
+}
+
+// taskQuery selects tasks with their totals so far; it takes now
+const taskQuery = `SELECT t.id, t.title, t.estimate, t.created_at, t.done_at, COALESCE(t.source, ''),
+		COALESCE(SUM(` + focusSeconds + ` >= duration_secs - 1), 0),
+		COALESCE(SUM(` + focusSeconds + `), 0)
+	FROM tasks t
+	LEFT JOIN pomodoros p ON p.task_id = t.id AND p.was_break = 0
+		AND p.is_paused = 0 AND julianday(p.end_time) <= julianday(?)`
+
+// scanTask reads a row selected with taskQuery into a Task
+func scanTask(row rowScanner) (*Task, error) {
+	var task Task
+	var focus float64
+	err := row.Scan(&task.ID, &task.Title, &task.Estimate, &task.CreatedAt, &task.DoneAt, &task.Source, &task.Pomodoros, &focus)
+	if err != nil {
+		return nil, err
+	}
+	task.FocusSec = int64(focus + 0.5)
+	return &task, nil
+}
+
+// AddTask creates an open task
+func (d *InternalDB) AddTask(title string, estimate int) (int64, error) {
+	res, err := d.db.Exec(
+		`INSERT INTO tasks(title, estimate, created_at) VALUES(?, ?, ?)`,
+		title, estimate, time.Now(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error saving task: %v", err)
+	}
+	return res.LastInsertId()
+}
+
+// GetTask retrieves a task with its totals, or nil if there is none
+func (d *InternalDB) GetTask(id int64) (*Task, error) {
+	task, err := scanTask(d.db.QueryRow(taskQuery+` WHERE t.id = ? GROUP BY t.id`, time.Now(), id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying task: %v", err)
+	}
+	return task, nil
+}
+
+// ListTasks retrieves tasks with their totals, open tasks first and then in
+// the order they were added. Done tasks are left out unless includeDone is set.
+func (d *InternalDB) ListTasks(includeDone bool) ([]Task, error) {
+	rows, err := d.db.Query(
+		taskQuery+` WHERE ? OR t.done_at IS NULL
+		GROUP BY t.id
+		ORDER BY t.done_at IS NOT NULL, t.id`,
+		time.Now(), includeDone,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying tasks: %v", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing rows: %v\n", err)
+		}
+	}()
+
+	var tasks []Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning task: %v", err)
+		}
+		tasks = append(tasks, *task)
+	}
+	return tasks, rows.Err()
+}
+
+// CompleteTask marks a task done
+func (d *InternalDB) CompleteTask(id int64, doneAt time.Time) error {
+	res, err := d.db.Exec(`UPDATE tasks SET done_at = ? WHERE id = ?`, doneAt, id)
+	if err != nil {
+		return fmt.Errorf("error completing task: %v", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("no task with ID %d", id)
+	}
+	return nil
+}
+
+// SetTaskSource records the file a task was planned from
+func (d *InternalDB) SetTaskSource(id int64, source string) error {
+	if _, err := d.db.Exec(`UPDATE tasks SET source = ? WHERE id = ?`, source, id); err != nil {
+		return fmt.Errorf("error saving task source: %v", err)
+	}
+	return nil
+}
+
+// SetSessionTask links a session to a task
+func (d *InternalDB) SetSessionTask(sessionID, taskID int64) error {
+	_, err := d.db.Exec(`UPDATE pomodoros SET task_id = ? WHERE id = ?`, taskID, sessionID)
+	if err != nil {
+		return fmt.Errorf("error linking session to task: %v", err)
+	}
+	return nil
+}
+
+// GetTaskSessions retrieves the sessions linked to a task, newest first
+func (d *InternalDB) GetTaskSessions(taskID int64) ([]PomodoroSession, error) {
+	rows, err := d.db.Query(
+		`SELECT `+sessionColumns+`
+		FROM pomodoros
+		WHERE task_id = ?
+		ORDER BY julianday(start_time) DESC`,
+		taskID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying task sessions: %v", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing rows: %v\n", err)
+		}
+	}()
+
+	var sessions []PomodoroSession
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning session: %v", err)
+		}
+		sessions = append(sessions, *session)
+	}
+	return sessions, rows.Err()
+}
+
+// GetTaskStats aggregates the finished pomodoros in the date range by task,
+// most focused first. Pomodoros without a task are left out.
+func (d *InternalDB) GetTaskStats(startDate, endDate time.Time) ([]TaskStats, error) {
+	rows, err := d.db.Query(
+		`SELECT t.id, t.title, t.estimate,
+			COALESCE(SUM(`+focusSeconds+` >= duration_secs - 1), 0),
+			SUM(`+focusSeconds+`)
+		FROM pomodoros p JOIN tasks t ON t.id = p.task_id
+		WHERE was_break = 0 AND `+finishedInRange+`
+		GROUP BY t.id
+		ORDER BY SUM(`+focusSeconds+`) DESC, t.id`,
+		dayParam(startDate), dayParam(endDate), time.Now(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying task stats: %v", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing rows: %v\n", err)
+		}
+	}()
+
+	var stats []TaskStats
+	for rows.Next() {
+		var s TaskStats
+		var focus float64
+		if err := rows.Scan(&s.TaskID, &s.Title, &s.Estimate, &s.Pomodoros, &focus); err != nil {
+			return nil, fmt.Errorf("error scanning task stats: %v", err)
+		}
+		s.FocusSec = int64(focus + 0.5)
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
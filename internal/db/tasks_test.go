@@ -0,0 +1,96 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTasks(t *testing.T) {
+	database := newTestDB(t)
+	day := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	at := func(hour, minute int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+
+	doc, err := database.AddTask("Write design doc", 2)
+	if err != nil {
+		t.Fatalf("AddTask failed: %v", err)
+	}
+	review, err := database.AddTask("Review", 0)
+	if err != nil {
+		t.Fatalf("AddTask failed: %v", err)
+	}
+
+	link := func(start, end time.Time, wasBreak bool, task int64) {
+		t.Helper()
+		id, err := database.CreateSession(start, end, "Work", 25*60, "", wasBreak)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := database.SetSessionTask(id, task); err != nil {
+			t.Fatalf("SetSessionTask failed: %v", err)
+		}
+	}
+	link(at(9, 0), at(9, 25), false, doc)
+	link(at(9, 25), at(9, 30), true, doc) // Breaks do not count
+	link(at(9, 30), at(9, 55), false, doc)
+	link(at(10, 0), at(10, 25), false, doc)
+	link(at(11, 0), at(11, 10), false, review) // Cancelled early
+	if _, err := database.CreateSession(at(12, 0), at(12, 25), "Other", 25*60, "", false); err != nil {
+		t.Fatal(err)
+	}
+
+	task, err := database.GetTask(doc)
+	if err != nil || task == nil {
+		t.Fatalf("GetTask failed: %v", err)
+	}
+	if task.Pomodoros != 3 || task.FocusSec != 75*60 || task.Estimate != 2 {
+		t.Errorf("Expected 3 pomodoros and 75m against an estimate of 2, got %+v", task)
+	}
+	if missing, err := database.GetTask(99); err != nil || missing != nil {
+		t.Errorf("Expected no task 99, got %v, %v", missing, err)
+	}
+
+	sessions, err := database.GetTaskSessions(doc)
+	if err != nil {
+		t.Fatalf("GetTaskSessions failed: %v", err)
+	}
+	if len(sessions) != 4 || sessions[0].TaskID != doc || !sessions[0].StartTime.Equal(at(10, 0)) {
+		t.Errorf("Expected 4 sessions for the task, newest first, got %+v", sessions)
+	}
+
+	stats, err := database.GetTaskStats(day, day)
+	if err != nil {
+		t.Fatalf("GetTaskStats failed: %v", err)
+	}
+	want := []TaskStats{{doc, "Write design doc", 2, 3, 75 * 60}, {review, "Review", 0, 0, 10 * 60}}
+	if len(stats) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, stats)
+	}
+	for i := range stats {
+		if stats[i] != want[i] {
+			t.Errorf("Expected %+v, got %+v", want[i], stats[i])
+		}
+	}
+
+	if err := database.CompleteTask(doc, at(13, 0)); err != nil {
+		t.Fatalf("CompleteTask failed: %v", err)
+	}
+	if err := database.CompleteTask(99, at(13, 0)); err == nil {
+		t.Error("Expected an error completing a missing task")
+	}
+	open, err := database.ListTasks(false)
+	if err != nil {
+		t.Fatalf("ListTasks failed: %v", err)
+	}
+	if len(open) != 1 || open[0].ID != review {
+		t.Errorf("Expected only the review task to be open, got %+v", open)
+	}
+	all, err := database.ListTasks(true)
+	if err != nil {
+		t.Fatalf("ListTasks failed: %v", err)
+	}
+	if len(all) != 2 || all[0].ID != review || all[1].DoneAt == nil {
+		t.Errorf("Expected open tasks before done ones, got %+v", all)
+	}
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// TemplateUsage is how often a session template has been started
+type TemplateUsage struct {
+	Uses     int
+	LastUsed time.Time
+}
+
+// RecordTemplateUse counts a start of the named template at the given time
+func (d *InternalDB) RecordTemplateUse(name string, at time.Time) error {
+	if _, err := d.db.Exec(
+		`INSERT INTO template_usage(name, uses, last_used) VALUES(?, 1, ?)
+		ON CONFLICT(name) DO UPDATE SET uses = uses + 1, last_used = excluded.last_used`,
+		name, at,
+	); err != nil {
+		return fmt.Errorf("error recording use of template %s: %v", name, err)
+	}
+	return nil
+}
+
+// GetTemplateUsage returns how often each template has been started, by
+// name. Templates never started are missing.
+func (d *InternalDB) GetTemplateUsage() (map[string]TemplateUsage, error) {
+	rows, err := d.db.Query(`SELECT name, uses, last_used FROM template_usage`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying template usage: %v", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing rows: %v\n", err)
+		}
+	}()
+
+	usage := map[string]TemplateUsage{}
+	for rows.Next() {
+		var name string
+		var u TemplateUsage
+		if err := rows.Scan(&name, &u.Uses, &u.LastUsed); err != nil {
+			return nil, fmt.Errorf("error scanning template usage: %v", err)
+		}
+		usage[name] = u
+	}
+	return usage, rows.Err()
+}
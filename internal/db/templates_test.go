@@ -0,0 +1,38 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTemplateUsage(t *testing.T) {
+	database := newTestDB(t)
+	monday := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+
+	for _, use := range []struct {
+		name string
+		at   time.Time
+	}{
+		{"review", monday},
+		{"deep", monday.Add(time.Hour)},
+		{"review", monday.Add(2 * time.Hour)},
+	} {
+		if err := database.RecordTemplateUse(use.name, use.at); err != nil {
+			t.Fatalf("RecordTemplateUse failed: %v", err)
+		}
+	}
+
+	usage, err := database.GetTemplateUsage()
+	if err != nil {
+		t.Fatalf("GetTemplateUsage failed: %v", err)
+	}
+	if len(usage) != 2 {
+		t.Fatalf("Expected 2 templates, got %v", usage)
+	}
+	if u := usage["review"]; u.Uses != 2 || !u.LastUsed.Equal(monday.Add(2*time.Hour)) {
+		t.Errorf("review = %+v, want 2 uses, last at 11:00", u)
+	}
+	if u := usage["deep"]; u.Uses != 1 {
+		t.Errorf("deep = %+v, want 1 use", u)
+	}
+}
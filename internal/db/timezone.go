@@ -0,0 +1,48 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// localDay is the SQL expression for the calendar day a session started on,
+// in the time zone where it was recorded. SQLite's date() converts stored
+// timestamps to UTC, so the recorded offset is added back.
+const localDay = `date(start_time, COALESCE(tz_offset, 0) || ' seconds')`
+
+// localHour is the SQL expression for the hour of the day a session started
+// at, in the time zone where it was recorded
+const localHour = `CAST(strftime('%H', start_time, COALESCE(tz_offset, 0) || ' seconds') AS INTEGER)`
+
+// Timestamps are stored as text with the offset they were recorded in, so
+// comparing or ordering them as strings breaks once offsets differ. Queries
+// compare julianday(column) instead, which normalizes to UTC.
+
+// dayParam formats t as a calendar day for comparison with localDay
+func dayParam(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// Location returns the time zone the session was recorded in
+func (s PomodoroSession) Location() *time.Location {
+	return time.FixedZone(s.TZName, s.TZOffset)
+}
+
+// ZoneLabel formats the session's recorded time zone, e.g. "CEST (UTC+02:00)"
+func (s PomodoroSession) ZoneLabel() string {
+	offset := FormatOffset(s.TZOffset)
+	if s.TZName == "" {
+		return offset
+	}
+	return fmt.Sprintf("%s (%s)", s.TZName, offset)
+}
+
+// FormatOffset formats a UTC offset in seconds as "UTC+02:00"
+func FormatOffset(offset int) string {
+	sign := '+'
+	if offset < 0 {
+		sign = '-'
+		offset = -offset
+	}
+	return fmt.Sprintf("UTC%c%02d:%02d", sign, offset/3600, offset%3600/60)
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionsUseLocalDayWhereRecorded(t *testing.T) {
+	database := newTestDB(t)
+
+	// Just after midnight in Berlin is still the previous day in UTC
+	berlin := time.FixedZone("CEST", 2*3600)
+	start := time.Date(2024, 6, 1, 0, 30, 0, 0, berlin)
+	id, err := database.CreateSession(start, start.Add(25*time.Minute), "Late night", 1500, "", false)
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+
+	session, err := database.GetSessionByID(id)
+	if err != nil || session == nil {
+		t.Fatalf("Failed to load session: %v", err)
+	}
+	if session.TZOffset != 7200 || session.TZName != "CEST" {
+		t.Errorf("Expected CEST/7200, got %s/%d", session.TZName, session.TZOffset)
+	}
+	if got := session.StartTime.In(session.Location()).Format("2006-01-02 15:04"); got != "2024-06-01 00:30" {
+		t.Errorf("Expected local start 2024-06-01 00:30, got %s", got)
+	}
+
+	day := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	sessions, err := database.GetSessionsByDateRange(day, day)
+	if err != nil {
+		t.Fatalf("Failed to query sessions: %v", err)
+	}
+	if len(sessions) != 1 {
+		t.Errorf("Expected the session on 2024-06-01, got %d sessions", len(sessions))
+	}
+
+	resolved, err := database.ResolveSession("2024-06-01.1")
+	if err != nil || resolved == nil || resolved.ID != id {
+		t.Errorf("Expected 2024-06-01.1 to resolve to session %d, got %v (%v)", id, resolved, err)
+	}
+}
+
+func TestFormatOffset(t *testing.T) {
+	tests := map[int]string{
+		0:             "UTC+00:00",
+		7200:          "UTC+02:00",
+		-4 * 3600:     "UTC-04:00",
+		5*3600 + 1800: "UTC+05:30",
+	}
+	for offset, want := range tests {
+		if got := FormatOffset(offset); got != want {
+			t.Errorf("FormatOffset(%d) = %s, want %s", offset, got, want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package db
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+)
+
+// API token scopes
+const (
+	ScopeRead = "read" // Read the status, history, and goals
+	ScopeFull = "full" // Also start, pause, resume, cancel, and annotate
+)
+
+// tokenPrefix starts every API token secret, so a leaked one is easy to
+// recognize in logs and secret scanners
+const tokenPrefix = "pomo_"
+
+// tokenNamePattern is what an API token name may look like
+var tokenNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// APIToken is a token that grants a client access to the REST API. Only a
+// hash of its secret is stored.
+type APIToken struct {
+	ID         int64
+	Name       string
+	Scope      string
+	CreatedAt  time.Time
+	LastUsedAt *time.Time // nil until the token is first used
+	RevokedAt  *time.Time // nil while the token is valid
+}
+
+// ValidScope reports whether scope is an API token scope
+func ValidScope(scope string) bool {
+	return scope == ScopeRead || scope == ScopeFull
+}
+
+// hashToken returns the stored form of a token secret. Secrets are long
+// and random, so a plain SHA-256 is enough to keep them from being read
+// back out of the database.
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIToken creates a token named name with scope and returns its
+// secret, which is not stored and cannot be shown again
+func (d *InternalDB) CreateAPIToken(name, scope string) (string, error) {
+	if !tokenNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid token name %q: use up to 64 letters, digits, '.', '-' and '_'", name)
+	}
+	if !ValidScope(scope) {
+		return "", fmt.Errorf("invalid scope %q: must be %s or %s", scope, ScopeRead, ScopeFull)
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating token: %v", err)
+	}
+	secret := tokenPrefix + hex.EncodeToString(b)
+
+	var taken int
+	if err := d.db.QueryRow(`SELECT COUNT(*) FROM api_tokens WHERE name = ? AND revoked_at IS NULL`, name).Scan(&taken); err != nil {
+		return "", fmt.Errorf("error checking token name: %v", err)
+	}
+	if taken > 0 {
+		return "", fmt.Errorf("a token named %q already exists; revoke it first", name)
+	}
+
+	if _, err := d.db.Exec(
+		`INSERT INTO api_tokens(name, hash, scope, created_at) VALUES(?, ?, ?, ?)`,
+		name, hashToken(secret), scope, time.Now(),
+	); err != nil {
+		return "", fmt.Errorf("error saving token: %v", err)
+	}
+	return secret, nil
+}
+
+// ListAPITokens retrieves the tokens in the order they were created.
+// Revoked tokens are left out unless includeRevoked is set.
+func (d *InternalDB) ListAPITokens(includeRevoked bool) ([]APIToken, error) {
+	rows, err := d.db.Query(
+		`SELECT id, name, scope, created_at, last_used_at, revoked_at FROM api_tokens
+		WHERE ? OR revoked_at IS NULL ORDER BY id`,
+		includeRevoked,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying tokens: %v", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing rows: %v\n", err)
+		}
+	}()
+
+	var tokens []APIToken
+	for rows.Next() {
+		var t APIToken
+		if err := rows.Scan(&t.ID, &t.Name, &t.Scope, &t.CreatedAt, &t.LastUsedAt, &t.RevokedAt); err != nil {
+			return nil, fmt.Errorf("error scanning token: %v", err)
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, rows.Err()
+}
+
+// RevokeAPIToken revokes the valid token named name, which stops working at
+// once
+func (d *InternalDB) RevokeAPIToken(name string) error {
+	res, err := d.db.Exec(`UPDATE api_tokens SET revoked_at = ? WHERE name = ? AND revoked_at IS NULL`, time.Now(), name)
+	if err != nil {
+		return fmt.Errorf("error revoking token: %v", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("no token named %q", name)
+	}
+	return nil
+}
+
+// AuthenticateAPIToken returns the valid token with secret, recording that
+// it was used at usedAt, or nil if there is none
+func (d *InternalDB) AuthenticateAPIToken(secret string, usedAt time.Time) (*APIToken, error) {
+	var t APIToken
+	err := d.db.QueryRow(
+		`SELECT id, name, scope, created_at, last_used_at, revoked_at FROM api_tokens
+		WHERE hash = ? AND revoked_at IS NULL`,
+		hashToken(secret),
+	).Scan(&t.ID, &t.Name, &t.Scope, &t.CreatedAt, &t.LastUsedAt, &t.RevokedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying token: %v", err)
+	}
+
+	if _, err := d.db.Exec(`UPDATE api_tokens SET last_used_at = ? WHERE id = ?`, usedAt, t.ID); err != nil {
+		return nil, fmt.Errorf("error recording token use: %v", err)
+	}
+	t.LastUsedAt = &usedAt
+	return &t, nil
+}
@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/ethan-k/pomodoro-cli/internal/trace"
+)
+
+// tracedDriverName is the SQLite driver that times every statement, used
+// while a trace is being recorded
+const tracedDriverName = "sqlite3-traced"
+
+func init() {
+	sql.Register(tracedDriverName, tracedDriver{&sqlite3.SQLiteDriver{}})
+}
+
+// driverName returns the driver to open databases with
+func driverName() string {
+	if trace.Enabled() {
+		return tracedDriverName
+	}
+	return "sqlite3"
+}
+
+// queryName shortens a statement to one line for a trace
+func queryName(query string) string {
+	name := strings.Join(strings.Fields(query), " ")
+	if len(name) > 120 {
+		name = name[:117] + "..."
+	}
+	return name
+}
+
+// tracedDriver wraps the SQLite driver so its connections are traced
+type tracedDriver struct {
+	driver.Driver
+}
+
+func (t tracedDriver) Open(name string) (driver.Conn, error) {
+	conn, err := t.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedConn{conn}, nil
+}
+
+// tracedConn times the statements run on a SQLite connection. The SQLite
+// connection has every method it calls; were one missing, database/sql
+// would be told to fall back to another way of running the statement.
+type tracedConn struct {
+	driver.Conn
+}
+
+func (c *tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	defer trace.Begin(trace.Query, queryName(query))()
+	return execer.ExecContext(ctx, query, args)
+}
+
+func (c *tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if err != nil {
+		trace.Add(trace.Query, queryName(query), start, time.Since(start))
+		return nil, err
+	}
+	return &tracedRows{Rows: rows, name: queryName(query), start: start, spent: time.Since(start)}, nil
+}
+
+func (c *tracedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *tracedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tracedStmt{stmt, queryName(query)}, nil
+}
+
+func (c *tracedConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *tracedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	beginner, ok := c.Conn.(driver.ConnBeginTx)
+	if !ok {
+		return nil, errors.New("the database driver cannot begin transactions")
+	}
+	tx, err := beginner.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return tracedTx{tx}, nil
+}
+
+// tracedStmt times a prepared statement each time it runs
+type tracedStmt struct {
+	driver.Stmt
+	name string
+}
+
+func (s *tracedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := s.Stmt.(driver.StmtExecContext)
+	if !ok {
+		return nil, errors.New("the database driver cannot run prepared statements")
+	}
+	defer trace.Begin(trace.Query, s.name)()
+	return execer.ExecContext(ctx, args)
+}
+
+func (s *tracedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := s.Stmt.(driver.StmtQueryContext)
+	if !ok {
+		return nil, errors.New("the database driver cannot run prepared statements")
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, args)
+	if err != nil {
+		trace.Add(trace.Query, s.name, start, time.Since(start))
+		return nil, err
+	}
+	return &tracedRows{Rows: rows, name: s.name, start: start, spent: time.Since(start)}, nil
+}
+
+// tracedTx times committing, when SQLite writes the changes out
+type tracedTx struct {
+	driver.Tx
+}
+
+func (t tracedTx) Commit() error {
+	defer trace.Begin(trace.Query, "COMMIT")()
+	return t.Tx.Commit()
+}
+
+// tracedRows adds the time spent stepping through a query's rows, which
+// SQLite does lazily, to the query's span when they are closed
+type tracedRows struct {
+	driver.Rows
+	name  string
+	start time.Time
+	spent time.Duration
+}
+
+func (r *tracedRows) Next(dest []driver.Value) error {
+	start := time.Now()
+	defer func() { r.spent += time.Since(start) }()
+	return r.Rows.Next(dest)
+}
+
+func (r *tracedRows) Close() error {
+	trace.Add(trace.Query, r.name, r.start, r.spent)
+	return r.Rows.Close()
+}
@@ -0,0 +1,41 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/trace"
+)
+
+func TestTracedDriver(t *testing.T) {
+	trace.Start(time.Now())
+	database, err := NewDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = database.Close() }()
+
+	start := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	id, err := database.CreateSession(start, start.Add(25*time.Minute), "Traced", 1500, "work", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := trace.Total(trace.Query)
+	session, err := database.GetSessionByID(id)
+	if err != nil || session == nil || session.Description != "Traced" || !session.StartTime.Equal(start) {
+		t.Fatalf("Expected the session to read back through the traced driver, got %+v, %v", session, err)
+	}
+
+	after := trace.Total(trace.Query)
+	if after.Count <= before.Count || after.Duration <= before.Duration {
+		t.Errorf("Expected the query to be traced, got %+v then %+v", before, after)
+	}
+	var found bool
+	for _, s := range trace.Slowest(trace.Query, after.Count) {
+		found = found || s.Name == "COMMIT"
+	}
+	if !found {
+		t.Error("Expected migrating the database to trace its commits")
+	}
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// userNamePattern is what a local user name may look like, so it is always
+// safe to use as a directory name
+var userNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// ValidateUserName checks a local user name given with --user
+func ValidateUserName(name string) error {
+	if !userNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid user name %q: use up to 32 lowercase letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// usersDir returns the directory holding each local user's data directory
+func usersDir() (string, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "users"), nil
+}
+
+// UserPath returns the session database of a local user, in a data
+// directory of its own next to the default database
+func UserPath(name string) (string, error) {
+	if err := ValidateUserName(name); err != nil {
+		return "", err
+	}
+	dir, err := usersDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name, "history.db"), nil
+}
+
+// LocalUsers returns the names of local users that have a session
+// database, sorted by name
+func LocalUsers() ([]string, error) {
+	dir, err := usersDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading users directory: %v", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() || ValidateUserName(e.Name()) != nil {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, e.Name(), "history.db")); err == nil {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
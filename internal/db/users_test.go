@@ -0,0 +1,40 @@
+package db
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLocalUsers(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	for _, name := range []string{"", "Alice", "../alice", "a b", "-alice"} {
+		if _, err := UserPath(name); err == nil {
+			t.Errorf("Expected %q to be refused as a user name", name)
+		}
+	}
+
+	for _, name := range []string{"bob", "alice"} {
+		path, err := UserPath(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if filepath.Base(filepath.Dir(path)) != name {
+			t.Errorf("Expected %s's database in a directory of its own, got %s", name, path)
+		}
+		database, err := NewDB(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = database.Close()
+	}
+
+	users, err := LocalUsers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"alice", "bob"}; !reflect.DeepEqual(users, want) {
+		t.Errorf("Expected users %v, got %v", want, users)
+	}
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Vacation is a stretch of days off that does not break a streak
+type Vacation struct {
+	ID   int64
+	From string // First day off, YYYY-MM-DD
+	To   string // Last day off, YYYY-MM-DD
+}
+
+// AddVacation records the days from from to to, inclusive, as a vacation
+func (d *InternalDB) AddVacation(from, to time.Time) (int64, error) {
+	res, err := d.db.Exec(`INSERT INTO vacations(from_day, to_day) VALUES(?, ?)`, dayParam(from), dayParam(to))
+	if err != nil {
+		return 0, fmt.Errorf("error adding vacation: %v", err)
+	}
+	return res.LastInsertId()
+}
+
+// GetVacations returns every vacation, earliest first
+func (d *InternalDB) GetVacations() ([]Vacation, error) {
+	rows, err := d.db.Query(`SELECT id, from_day, to_day FROM vacations ORDER BY from_day, id`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying vacations: %v", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing rows: %v\n", err)
+		}
+	}()
+
+	var vacations []Vacation
+	for rows.Next() {
+		var v Vacation
+		if err := rows.Scan(&v.ID, &v.From, &v.To); err != nil {
+			return nil, fmt.Errorf("error scanning vacation: %v", err)
+		}
+		vacations = append(vacations, v)
+	}
+	return vacations, rows.Err()
+}
+
+// DeleteVacation removes the vacation with id
+func (d *InternalDB) DeleteVacation(id int64) error {
+	res, err := d.db.Exec(`DELETE FROM vacations WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("error removing vacation: %v", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("no vacation %d", id)
+	}
+	return nil
+}
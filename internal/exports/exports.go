@@ -0,0 +1,211 @@
+// Package exports writes the session history to files on a schedule, for
+// the daemon to run, keeping the newest few of each format and optionally
+// copying each to an S3 bucket
+package exports
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+// Schedules exports run on
+const (
+	ScheduleOff    = "off"
+	ScheduleDaily  = "daily"
+	ScheduleWeekly = "weekly"
+)
+
+// filePrefix starts the name of every scheduled export, so keeping the
+// newest few never touches other files in the directory, such as the
+// backups 'export --all' writes there
+const filePrefix = "pomodoro-export-"
+
+// stampLayout is how an export's file name records when it was written
+const stampLayout = "20060102-150405"
+
+// Format is a way of writing the history to a file
+type Format struct {
+	Name  string // As given in exports.formats
+	Ext   string // File name extension, with its dot
+	Write func(w io.Writer, sessions []db.PomodoroSession) error
+}
+
+// Uploader copies an export somewhere else, such as a bucket
+type Uploader interface {
+	Upload(name string, data []byte) error
+}
+
+// Job is an export to run on a schedule
+type Job struct {
+	Dir     string   // Where exports are written
+	Formats []Format // Each export writes one file of each
+	Keep    int      // Exports of each format kept in Dir, 0 for all
+	Upload  Uploader // Where exports are copied, nil for nowhere
+}
+
+// Period returns how often schedule runs, 0 when it never does
+func Period(schedule string) (time.Duration, error) {
+	switch schedule {
+	case ScheduleDaily:
+		return 24 * time.Hour, nil
+	case ScheduleWeekly:
+		return 7 * 24 * time.Hour, nil
+	case ScheduleOff, "":
+		return 0, nil
+	}
+	return 0, fmt.Errorf("invalid export schedule %q: must be off, daily, or weekly", schedule)
+}
+
+// Due reports whether an export is due at now, given when the last one was
+// written, for exports every period at the time of day at. An export missed
+// while the machine was off or the daemon was not running is due at once.
+func Due(period, at time.Duration, last, now time.Time) bool {
+	if period <= 0 {
+		return false
+	}
+	if last.IsZero() {
+		return true
+	}
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	slot := midnight.Add(at)
+	if slot.After(now) {
+		slot = slot.AddDate(0, 0, -1)
+	}
+	// Weekly exports wait out the rest of the week after the last one
+	return last.Before(slot.Add(24*time.Hour - period))
+}
+
+// LastRun returns when the newest export in dir was written, zero when
+// there is none
+func LastRun(dir string) (time.Time, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	var last time.Time
+	for _, e := range entries {
+		if at, ok := exportTime(e.Name()); ok && at.After(last) {
+			last = at
+		}
+	}
+	return last, nil
+}
+
+// exportTime returns when the export named name was written
+func exportTime(name string) (time.Time, bool) {
+	rest, ok := strings.CutPrefix(name, filePrefix)
+	if !ok || len(rest) < len(stampLayout) {
+		return time.Time{}, false
+	}
+	at, err := time.ParseInLocation(stampLayout, rest[:len(stampLayout)], time.Local)
+	return at, err == nil
+}
+
+// Run writes every session recorded up to now in each of the job's
+// formats, copies the files to the uploader, and removes the oldest past
+// Keep. It returns the files written. Every file is written before any is
+// uploaded, so a run that fails to write one uploads nothing. The files
+// only take their names once every one is uploaded, so LastRun never counts
+// a run whose upload failed and it is tried again; the error then names the
+// files uploaded before the failure, which stay in the bucket.
+func (j *Job) Run(database db.DB, now time.Time) ([]string, error) {
+	if len(j.Formats) == 0 {
+		return nil, fmt.Errorf("no export formats configured")
+	}
+	sessions, err := database.GetSessionsByDateRange(time.Time{}, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(j.Dir, 0750); err != nil {
+		return nil, fmt.Errorf("error creating export directory: %v", err)
+	}
+
+	var temps []string
+	var contents [][]byte
+	defer func() {
+		for _, tmp := range temps {
+			_ = os.Remove(tmp)
+		}
+	}()
+	for _, f := range j.Formats {
+		var buf bytes.Buffer
+		if err := f.Write(&buf, sessions); err != nil {
+			return nil, fmt.Errorf("error writing %s export: %v", f.Name, err)
+		}
+		name := filePrefix + now.Format(stampLayout) + f.Ext
+		tmp := filepath.Join(j.Dir, name) + ".tmp"
+		temps = append(temps, tmp)
+		contents = append(contents, buf.Bytes())
+		if err := os.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+			return nil, fmt.Errorf("error writing %s: %v", tmp, err)
+		}
+	}
+
+	if j.Upload != nil {
+		var uploaded []string
+		for i, tmp := range temps {
+			name := filepath.Base(strings.TrimSuffix(tmp, ".tmp"))
+			if err := j.Upload.Upload(name, contents[i]); err != nil {
+				if len(uploaded) > 0 {
+					return nil, fmt.Errorf("error uploading %s, after uploading %s: %v", name, strings.Join(uploaded, ", "), err)
+				}
+				return nil, fmt.Errorf("error uploading %s: %v", name, err)
+			}
+			uploaded = append(uploaded, name)
+		}
+	}
+
+	var written []string
+	for i, f := range j.Formats {
+		path := strings.TrimSuffix(temps[i], ".tmp")
+		if err := os.Rename(temps[i], path); err != nil {
+			return written, fmt.Errorf("error writing %s: %v", path, err)
+		}
+		written = append(written, path)
+		if err := prune(j.Dir, f.Ext, j.Keep); err != nil {
+			return written, fmt.Errorf("error removing old exports: %v", err)
+		}
+	}
+	return written, nil
+}
+
+// prune removes the oldest exports with extension ext from dir, keeping
+// keep of them; 0 keeps them all
+func prune(dir, ext string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, e := range entries {
+		// Only the format's own files: ".json" must not match ".opf.json"
+		if _, ok := exportTime(e.Name()); ok && strings.TrimPrefix(e.Name(), filePrefix)[len(stampLayout):] == ext {
+			names = append(names, e.Name())
+		}
+	}
+	if len(names) <= keep {
+		return nil
+	}
+	// The time stamps sort in the order the exports were written
+	sort.Strings(names)
+	for _, name := range names[:len(names)-keep] {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package exports
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// s3Timeout bounds an upload, so a stalled connection does not hold up
+// the next export
+const s3Timeout = 2 * time.Minute
+
+// S3 uploads exports to an S3 bucket, or to storage that speaks its API
+// such as MinIO, signing requests with AWS Signature Version 4
+type S3 struct {
+	Bucket          string
+	Region          string // us-east-1 when empty
+	Endpoint        string // Path-style endpoint for S3-compatible storage, e.g. https://minio.example.com; AWS when empty
+	Prefix          string // Prepended to every key, e.g. pomodoro/
+	AccessKeyID     string
+	SecretAccessKey string
+
+	client *http.Client
+	now    func() time.Time
+}
+
+// Upload puts data in the bucket under the prefix and name
+func (s *S3) Upload(name string, data []byte) error {
+	region := s.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	key := escapePath(s.Prefix + name)
+	url := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.Bucket, region, key)
+	if s.Endpoint != "" {
+		url = fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(s.Endpoint, "/"), escapePath(s.Bucket), key)
+	}
+
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	signV4(req, hex.EncodeToString(sum[:]), region, s.AccessKeyID, s.SecretAccessKey, now())
+
+	client := s.client
+	if client == nil {
+		client = &http.Client{Timeout: s3Timeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// signV4 signs req for S3 in region, as of at, over every header it has
+// and its host. payloadHash is the hex SHA-256 of the body.
+func signV4(req *http.Request, payloadHash, region, keyID, secret string, at time.Time) {
+	stamp := at.UTC().Format("20060102T150405Z")
+	day := stamp[:8]
+	req.Header.Set("X-Amz-Date", stamp)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	values := map[string]string{"host": req.URL.Host}
+	for name, v := range req.Header {
+		values[strings.ToLower(name)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var headers strings.Builder
+	for _, name := range names {
+		headers.WriteString(name + ":" + values[name] + "\n")
+	}
+	signed := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		headers.String(),
+		signed,
+		payloadHash,
+	}, "\n")
+	canonicalSum := sha256.Sum256([]byte(canonical))
+	scope := day + "/" + region + "/s3/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + stamp + "\n" + scope + "\n" + hex.EncodeToString(canonicalSum[:])
+
+	key := []byte("AWS4" + secret)
+	for _, part := range []string{day, region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		keyID, scope, signed, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// escapePath percent-encodes every byte of a key but the unreserved
+// characters and /, as Signature Version 4 expects
+func escapePath(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-._~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
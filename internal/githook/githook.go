@@ -0,0 +1,149 @@
+// Package githook adds the running pomodoro to git commit messages with a
+// prepare-commit-msg hook, and reads it back from the log.
+package githook
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// TrailerKey is the git trailer naming the pomodoro a commit was made in
+const TrailerKey = "Pomodoro"
+
+// hookName is the git hook the trailer is added from
+const hookName = "prepare-commit-msg"
+
+// marker identifies a hook this package installed, so it is never mistaken
+// for, or replaces, one of the user's own
+const marker = "# Installed by pomodoro integrate git"
+
+// ErrForeignHook is returned when the repository already has a
+// prepare-commit-msg hook that this package did not install
+var ErrForeignHook = errors.New("the repository already has a prepare-commit-msg hook")
+
+// HookPath returns where git looks for the prepare-commit-msg hook of the
+// repository containing dir, honouring core.hooksPath
+func HookPath(dir string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--git-path", "hooks")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.New("not in a git repository")
+	}
+	hooks := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(hooks) {
+		hooks = filepath.Join(dir, hooks)
+	}
+	if hooks, err = filepath.Abs(hooks); err != nil {
+		return "", fmt.Errorf("error locating hooks directory: %v", err)
+	}
+	return filepath.Join(hooks, hookName), nil
+}
+
+// script returns the hook, which runs executable to add the trailer. It
+// never fails the commit.
+func script(executable string) string {
+	return "#!/bin/sh\n" +
+		marker + "; remove it with 'pomodoro integrate git --uninstall'\n" +
+		"# Adds a " + TrailerKey + " trailer naming the running pomodoro to the commit message.\n" +
+		shellQuote(executable) + ` integrate git trailer "$1" "$2" >/dev/null 2>&1 || true` + "\n"
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Installed reports whether the hook at path is one this package installed
+func Installed(path string) bool {
+	content, err := os.ReadFile(path) // #nosec G304 - the repository's own hook
+	return err == nil && bytes.Contains(content, []byte(marker))
+}
+
+// Install writes the hook to path, running executable. It replaces a hook
+// installed before, but not one of the user's own unless force is set.
+func Install(path, executable string, force bool) error {
+	if _, err := os.Stat(path); err == nil && !force && !Installed(path) {
+		return ErrForeignHook
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("error creating hooks directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(script(executable)), 0755); err != nil { // #nosec G306 - git hooks must be executable
+		return fmt.Errorf("error writing hook: %v", err)
+	}
+	// WriteFile keeps the mode of a file it replaces
+	if err := os.Chmod(path, 0755); err != nil { // #nosec G302 - git hooks must be executable
+		return fmt.Errorf("error making hook executable: %v", err)
+	}
+	return nil
+}
+
+// Uninstall removes the hook at path if this package installed it, and
+// reports whether there was one to remove
+func Uninstall(path string) (bool, error) {
+	if !Installed(path) {
+		return false, nil
+	}
+	if err := os.Remove(path); err != nil {
+		return false, fmt.Errorf("error removing hook: %v", err)
+	}
+	return true, nil
+}
+
+// Trailer returns the trailer value for a session, its short reference
+// followed by its description on one line
+func Trailer(ref, description string) string {
+	description = strings.Join(strings.Fields(description), " ")
+	if description == "" {
+		return ref
+	}
+	return ref + " " + description
+}
+
+// AddTrailer adds the trailer to the commit message in file, leaving a
+// message that already names a pomodoro, as when amending, as it is
+func AddTrailer(file, value string) error {
+	cmd := exec.Command("git", "interpret-trailers", "--in-place", "--if-exists", "doNothing",
+		"--trailer", TrailerKey+": "+value, file) // #nosec G204 - fixed command, the value is a single argument
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("error adding trailer: %v: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// CommitCounts returns how many commits made from since to until in the
+// repository containing dir name each pomodoro, by its short reference
+func CommitCounts(dir string, since, until time.Time) (map[string]int, error) {
+	cmd := exec.Command("git", "log", "--all",
+		"--since="+since.Format(time.RFC3339), "--until="+until.Format(time.RFC3339),
+		"--format=%x1e%(trailers:key="+TrailerKey+",valueonly)")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error reading the git log: %v", err)
+	}
+	return parseLog(string(out)), nil
+}
+
+// parseLog counts the pomodoros named in git log output, one record per
+// commit, each holding the commit's trailer values
+func parseLog(out string) map[string]int {
+	counts := map[string]int{}
+	for _, record := range strings.Split(out, "\x1e") {
+		// A commit counts once, toward the first pomodoro it names
+		for _, line := range strings.Split(record, "\n") {
+			if fields := strings.Fields(line); len(fields) > 0 && strings.HasPrefix(fields[0], "#") {
+				counts[fields[0]]++
+				break
+			}
+		}
+	}
+	return counts
+}
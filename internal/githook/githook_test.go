@@ -0,0 +1,82 @@
+package githook
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstall(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hooks", hookName)
+	if err := Install(path, "/opt/it's/pomodoro", false); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `'/opt/it'\''s/pomodoro' integrate git trailer "$1" "$2"`) {
+		t.Errorf("Expected the hook to run the quoted executable, got:\n%s", content)
+	}
+
+	// Installing again replaces the hook; one of the user's own needs force
+	if err := Install(path, "/usr/bin/pomodoro", false); err != nil {
+		t.Errorf("Expected to replace our own hook, got %v", err)
+	}
+	if err := os.WriteFile(path, []byte("#!/bin/sh\necho mine\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := Install(path, "/usr/bin/pomodoro", false); !errors.Is(err, ErrForeignHook) {
+		t.Errorf("Expected ErrForeignHook, got %v", err)
+	}
+	if removed, err := Uninstall(path); removed || err != nil {
+		t.Errorf("Expected the user's hook to be left alone, got %v, %v", removed, err)
+	}
+
+	if err := Install(path, "/usr/bin/pomodoro", true); err != nil {
+		t.Fatal(err)
+	}
+	if removed, err := Uninstall(path); !removed || err != nil {
+		t.Errorf("Expected the hook to be removed, got %v, %v", removed, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected no hook left, got %v", err)
+	}
+}
+
+func TestAddTrailer(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	file := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+	if err := os.WriteFile(file, []byte("Fix the parser\n\n# Please enter the commit message\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AddTrailer(file, Trailer("#a3f9c2", "Write\nthe  parser")); err != nil {
+		t.Fatal(err)
+	}
+	// An amended commit keeps the pomodoro it was first made in
+	if err := AddTrailer(file, Trailer("#b4e0d1", "")); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Fix the parser\n\nPomodoro: #a3f9c2 Write the parser\n"
+	if !strings.HasPrefix(string(content), want) || strings.Contains(string(content), "#b4e0d1") {
+		t.Errorf("Expected the message to start with %q, got:\n%s", want, content)
+	}
+}
+
+func TestParseLog(t *testing.T) {
+	out := "\x1e#a3f9c2 Write the parser\n\n\x1e\n\x1e#a3f9c2\n#b4e0d1 Review\n\x1e#b4e0d1 Review\n"
+	counts := parseLog(out)
+	if len(counts) != 2 || counts["#a3f9c2"] != 2 || counts["#b4e0d1"] != 1 {
+		t.Errorf("Expected 2 commits for #a3f9c2 and 1 for #b4e0d1, got %v", counts)
+	}
+}
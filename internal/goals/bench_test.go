@@ -0,0 +1,37 @@
+package goals
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func BenchmarkComputeWorkload(b *testing.B) {
+	b.Setenv("HOME", b.TempDir())
+	database, err := db.NewDB("")
+	if err != nil {
+		b.Fatalf("Failed to open database: %v", err)
+	}
+	b.Cleanup(func() { _ = database.Close() })
+
+	// POMODORO_BENCH_SESSIONS sizes the history, as for the db benchmarks
+	sessions := 10000
+	if n, err := strconv.Atoi(os.Getenv("POMODORO_BENCH_SESSIONS")); err == nil && n >= 0 {
+		sessions = n
+	}
+
+	now := time.Now()
+	if _, err := database.Seed(db.SeedOptions{Sessions: sessions, End: now.AddDate(0, 0, 1), Seed: 1}); err != nil {
+		b.Fatalf("Failed to seed database: %v", err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ComputeWorkload(database, now, 1.5); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
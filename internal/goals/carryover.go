@@ -0,0 +1,57 @@
+package goals
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+// CarryOver tracks a rolling weekly goal, where pomodoros missed in a week
+// are owed the next week and extra ones count as credit
+type CarryOver struct {
+	Goal    int // Weekly goal from the config
+	Weeks   int // Finished weeks counted toward the balance
+	Balance int // Pomodoros ahead of (positive) or behind (negative) the goal at the start of this week
+}
+
+// Target returns this week's goal after the balance, never below zero
+func (c *CarryOver) Target() int {
+	return max(0, c.Goal-c.Balance)
+}
+
+// WeekStart returns midnight on the Monday of t's week
+func WeekStart(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// ComputeCarryOver adds up how far each finished week from the week of
+// since up to last week fell short of or beat the weekly goal. Weeks before
+// since are left out, so turning carry-over on starts with a clean slate.
+func ComputeCarryOver(database db.DB, now time.Time, weeklyGoal int, since time.Time) (*CarryOver, error) {
+	c := &CarryOver{Goal: weeklyGoal}
+	first := WeekStart(since)
+	thisWeek := WeekStart(now)
+	if weeklyGoal <= 0 || !first.Before(thisWeek) {
+		return c, nil
+	}
+
+	sessions, err := database.GetSessionsByDateRange(first, thisWeek.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, fmt.Errorf("error getting sessions: %v", err)
+	}
+
+	counts := make(map[time.Time]int)
+	for _, s := range sessions {
+		if !s.WasBreak && !s.Incomplete() {
+			counts[WeekStart(s.StartTime.In(now.Location()))]++
+		}
+	}
+
+	for week := first; week.Before(thisWeek); week = week.AddDate(0, 0, 7) {
+		c.Weeks++
+		c.Balance += counts[week] - weeklyGoal
+	}
+	return c, nil
+}
@@ -0,0 +1,54 @@
+package goals
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func TestComputeCarryOver(t *testing.T) {
+	now := time.Date(2024, 6, 19, 15, 0, 0, 0, time.UTC)  // Wednesday
+	since := time.Date(2024, 5, 29, 0, 0, 0, 0, time.UTC) // Wednesday three weeks earlier
+
+	var sessions []db.PomodoroSession
+	add := func(day time.Time, pomodoros int) {
+		for i := 0; i < pomodoros; i++ {
+			start := day.Add(time.Duration(i) * time.Hour)
+			sessions = append(sessions, db.PomodoroSession{StartTime: start, EndTime: start.Add(25 * time.Minute)})
+		}
+	}
+	add(time.Date(2024, 5, 20, 9, 0, 0, 0, time.UTC), 9) // Before carry-over started
+	add(time.Date(2024, 5, 28, 9, 0, 0, 0, time.UTC), 3) // Week of May 27: 3 of 10
+	add(time.Date(2024, 6, 4, 9, 0, 0, 0, time.UTC), 8)  // Week of June 3: 12 of 10
+	add(time.Date(2024, 6, 9, 9, 0, 0, 0, time.UTC), 4)
+	add(time.Date(2024, 6, 12, 9, 0, 0, 0, time.UTC), 10) // Week of June 10: 10 of 10
+	add(time.Date(2024, 6, 18, 9, 0, 0, 0, time.UTC), 5)  // This week, not yet counted
+	sessions = append(sessions, db.PomodoroSession{StartTime: time.Date(2024, 6, 4, 18, 0, 0, 0, time.UTC), WasBreak: true})
+	sessions = append(sessions, db.PomodoroSession{StartTime: time.Date(2024, 6, 5, 18, 0, 0, 0, time.UTC), Status: db.StatusCancelled})
+
+	carry, err := ComputeCarryOver(&rangeDB{sessions: sessions}, now, 10, since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if carry.Weeks != 3 || carry.Balance != -5 {
+		t.Errorf("Expected a 5 pomodoro debt over 3 weeks, got %+v", carry)
+	}
+	if target := carry.Target(); target != 15 {
+		t.Errorf("Expected this week's target to be 15, got %d", target)
+	}
+
+	// Carry-over turned on this week has nothing to carry yet
+	carry, err = ComputeCarryOver(&rangeDB{sessions: sessions}, now, 10, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if carry.Weeks != 0 || carry.Target() != 10 {
+		t.Errorf("Expected no carry-over in the first week, got %+v", carry)
+	}
+
+	// Credit never takes the target below zero
+	if target := (&CarryOver{Goal: 10, Balance: 14}).Target(); target != 0 {
+		t.Errorf("Expected a target of 0, got %d", target)
+	}
+}
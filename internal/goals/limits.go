@@ -0,0 +1,38 @@
+package goals
+
+import (
+	"fmt"
+	"time"
+)
+
+// Limits cap a day's work, the opposite of goals, for those more prone to
+// overwork than to slacking
+type Limits struct {
+	DailyMax    int           // Pomodoros a day, 0 for no cap
+	LatestStart time.Duration // Time of day, since midnight, after which no pomodoro starts
+	HasLatest   bool          // LatestStart is set
+}
+
+// ParseTimeOfDay parses a 24-hour time of day such as "18:30" into the
+// time since midnight
+func ParseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q: use HH:MM, e.g. 18:30", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Exceeded returns why the day's work is over at now, with done pomodoros
+// completed today, or "" when it is not. Starting a pomodoro then breaks
+// the limits, and finishing one is the time to stop.
+func (l Limits) Exceeded(done int, now time.Time) string {
+	if l.DailyMax > 0 && done >= l.DailyMax {
+		return fmt.Sprintf("you have done %d pomodoros today, your daily limit is %d", done, l.DailyMax)
+	}
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if l.HasLatest && now.Sub(midnight) >= l.LatestStart {
+		return fmt.Sprintf("it is past %s, your latest start time", midnight.Add(l.LatestStart).Format("15:04"))
+	}
+	return ""
+}
@@ -0,0 +1,39 @@
+package goals
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimitsExceeded(t *testing.T) {
+	latest, err := ParseTimeOfDay("18:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseTimeOfDay("6pm"); err == nil {
+		t.Error("Expected 6pm to be refused")
+	}
+
+	l := Limits{DailyMax: 8, LatestStart: latest, HasLatest: true}
+	day := func(hour, minute int) time.Time { return time.Date(2024, 6, 3, hour, minute, 0, 0, time.Local) }
+
+	for _, tc := range []struct {
+		done     int
+		now      time.Time
+		exceeded bool
+	}{
+		{7, day(10, 0), false},
+		{8, day(10, 0), true},
+		{0, day(18, 29), false},
+		{0, day(18, 30), true},
+		{0, day(0, 15), false},
+	} {
+		if got := l.Exceeded(tc.done, tc.now); (got != "") != tc.exceeded {
+			t.Errorf("Exceeded(%d, %s) = %q, want exceeded %v", tc.done, tc.now.Format("15:04"), got, tc.exceeded)
+		}
+	}
+
+	if got := (Limits{}).Exceeded(100, day(23, 59)); got != "" {
+		t.Errorf("Expected no limits to never be exceeded, got %q", got)
+	}
+}
@@ -0,0 +1,101 @@
+// Package goals tracks progress toward Pomodoro goals and announces achievements
+package goals
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/notify"
+)
+
+// Achievement IDs, also used as keys for per-achievement sounds
+const (
+	AchievementDailyGoal  = "daily_goal"
+	AchievementWeeklyGoal = "weekly_goal"
+	AchievementTagGoal    = "tag_goal"    // A daily or weekly goal for a tag
+	AchievementBestDay    = "best_day"    // More pomodoros in a day than ever
+	AchievementBestStreak = "best_streak" // More days in a row than ever
+)
+
+// Achievement describes a milestone reached by the user
+type Achievement struct {
+	ID      string
+	Title   string
+	Message string
+}
+
+// NotificationManager announces goal achievements through a notifier
+type NotificationManager struct {
+	notifier notify.Notifier
+}
+
+// NewNotificationManager creates a notification manager using the given notifier
+func NewNotificationManager(notifier notify.Notifier) *NotificationManager {
+	return &NotificationManager{notifier: notifier}
+}
+
+// NewAchievements returns the achievements unlocked by the pomodoro that
+// brought the completed counts to their current values. Tag goals are only
+// reached by a pomodoro with the tag, so tags are the finished pomodoro's.
+func NewAchievements(status *config.GoalStatus, tags []string) []Achievement {
+	var achievements []Achievement
+
+	if status.DailyGoal > 0 && status.DailyCompleted == status.DailyGoal {
+		achievements = append(achievements, Achievement{
+			ID:      AchievementDailyGoal,
+			Title:   "Daily Goal Reached",
+			Message: fmt.Sprintf("You completed %d pomodoros today.", status.DailyCompleted),
+		})
+	}
+
+	if status.WeeklyGoal > 0 && status.WeeklyCompleted == status.WeeklyGoal {
+		achievements = append(achievements, Achievement{
+			ID:      AchievementWeeklyGoal,
+			Title:   "Weekly Goal Reached",
+			Message: fmt.Sprintf("You completed %d pomodoros this week.", status.WeeklyCompleted),
+		})
+	}
+
+	for _, goal := range status.Tags {
+		if !slices.Contains(tags, goal.Tag) {
+			continue
+		}
+		if goal.DailyGoal > 0 && goal.DailyCompleted == goal.DailyGoal {
+			achievements = append(achievements, Achievement{
+				ID:      AchievementTagGoal,
+				Title:   fmt.Sprintf("Daily %s Goal Reached", goal.Tag),
+				Message: fmt.Sprintf("You completed %d %s pomodoros today.", goal.DailyCompleted, goal.Tag),
+			})
+		}
+		if goal.WeeklyGoal > 0 && goal.WeeklyCompleted == goal.WeeklyGoal {
+			achievements = append(achievements, Achievement{
+				ID:      AchievementTagGoal,
+				Title:   fmt.Sprintf("Weekly %s Goal Reached", goal.Tag),
+				Message: fmt.Sprintf("You completed %d %s pomodoros this week.", goal.WeeklyCompleted, goal.Tag),
+			})
+		}
+	}
+
+	return achievements
+}
+
+// NotifyAchievement announces a single achievement with its sound
+func (m *NotificationManager) NotifyAchievement(a Achievement, silent bool) error {
+	if err := m.notifier.Send(a.Title, a.Message); err != nil {
+		return err
+	}
+	notify.PlayAchievementSound(a.ID, silent)
+	return nil
+}
+
+// NotifyGoalProgress announces every achievement unlocked for the given goal
+// status by a pomodoro with tags
+func (m *NotificationManager) NotifyGoalProgress(status *config.GoalStatus, tags []string, silent bool) error {
+	for _, a := range NewAchievements(status, tags) {
+		if err := m.NotifyAchievement(a, silent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package goals
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/notify"
+)
+
+// recordingNotifier records the titles of the notifications sent through it,
+// failing once it has sent failAfter when that is set
+type recordingNotifier struct {
+	titles    []string
+	failAfter int
+}
+
+func (n *recordingNotifier) Send(title, _ string) error {
+	if n.failAfter > 0 && len(n.titles) == n.failAfter {
+		return errors.New("notifier unavailable")
+	}
+	n.titles = append(n.titles, title)
+	return nil
+}
+
+func (n *recordingNotifier) SendWithActions(title, message string, _ []notify.Action) (string, error) {
+	return "", n.Send(title, message)
+}
+
+func TestTagGoalAchievements(t *testing.T) {
+	today := []db.PomodoroSession{
+		{TagsCSV: "writing"},
+		{TagsCSV: "code,Writing"},
+		{TagsCSV: "writing,review"},
+		{TagsCSV: "code"},
+	}
+	week := append(today, db.PomodoroSession{TagsCSV: "review"})
+	tags := config.GetTagGoalProgress(map[string]config.TagGoal{
+		"Writing": {Daily: 3, Weekly: 10},
+		"review":  {Weekly: 2},
+		"idle":    {},
+	}, today, week)
+
+	if len(tags) != 2 || tags[0].Tag != "review" || tags[1].Tag != "writing" {
+		t.Fatalf("Expected progress for review and writing, got %+v", tags)
+	}
+	if got := tags[1]; got.DailyCompleted != 3 || got.WeeklyCompleted != 3 {
+		t.Errorf("Expected 3 writing pomodoros today and this week, got %+v", got)
+	}
+	if got := tags[0]; got.DailyGoal != 0 || got.WeeklyCompleted != 2 {
+		t.Errorf("Expected 2 review pomodoros against a weekly goal only, got %+v", got)
+	}
+
+	status := &config.GoalStatus{DailyGoal: 8, DailyCompleted: 4, Tags: tags}
+	got := NewAchievements(status, []string{"writing", "review"})
+	if len(got) != 2 || got[0].Title != "Weekly review Goal Reached" || got[1].Title != "Daily writing Goal Reached" {
+		t.Errorf("Expected the weekly review and daily writing goals, got %+v", got)
+	}
+	for _, a := range got {
+		if a.ID != AchievementTagGoal {
+			t.Errorf("Expected ID %s, got %s", AchievementTagGoal, a.ID)
+		}
+	}
+
+	// A pomodoro without the tag did not reach its goal
+	if got := NewAchievements(status, []string{"code"}); len(got) != 0 {
+		t.Errorf("Expected no achievements for a code pomodoro, got %+v", got)
+	}
+}
+
+func TestNewAchievements(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		status config.GoalStatus
+		want   []string
+	}{
+		{"daily goal reached", config.GoalStatus{DailyGoal: 4, DailyCompleted: 4, WeeklyGoal: 20, WeeklyCompleted: 9}, []string{AchievementDailyGoal}},
+		{"both reached", config.GoalStatus{DailyGoal: 4, DailyCompleted: 4, WeeklyGoal: 20, WeeklyCompleted: 20}, []string{AchievementDailyGoal, AchievementWeeklyGoal}},
+		{"already past the goal", config.GoalStatus{DailyGoal: 4, DailyCompleted: 5}, nil},
+		{"no goals", config.GoalStatus{DailyCompleted: 3, WeeklyCompleted: 3}, nil},
+	} {
+		var got []string
+		for _, a := range NewAchievements(&tc.status, nil) {
+			got = append(got, a.ID)
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestNotifyGoalProgress(t *testing.T) {
+	status := &config.GoalStatus{
+		DailyGoal: 4, DailyCompleted: 4,
+		WeeklyGoal: 20, WeeklyCompleted: 20,
+		Tags: []config.TagGoalStatus{{Tag: "writing", DailyGoal: 2, DailyCompleted: 2}},
+	}
+
+	notifier := &recordingNotifier{}
+	if err := NewNotificationManager(notifier).NotifyGoalProgress(status, []string{"writing"}, true); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	want := []string{"Daily Goal Reached", "Weekly Goal Reached", "Daily writing Goal Reached"}
+	if !slices.Equal(notifier.titles, want) {
+		t.Errorf("Expected notifications %v, got %v", want, notifier.titles)
+	}
+
+	// Nothing is announced past the pomodoro that reached a goal
+	notifier = &recordingNotifier{}
+	status.DailyCompleted, status.WeeklyCompleted, status.Tags[0].DailyCompleted = 5, 21, 3
+	if err := NewNotificationManager(notifier).NotifyGoalProgress(status, []string{"writing"}, true); err != nil || len(notifier.titles) != 0 {
+		t.Errorf("Expected no notifications, got %v, %v", notifier.titles, err)
+	}
+
+	// A failure stops the announcements and is returned
+	notifier = &recordingNotifier{failAfter: 1}
+	status.DailyCompleted, status.WeeklyCompleted = 4, 20
+	if err := NewNotificationManager(notifier).NotifyGoalProgress(status, nil, true); err == nil || len(notifier.titles) != 1 {
+		t.Errorf("Expected an error after the first notification, got %v, %v", notifier.titles, err)
+	}
+}
@@ -0,0 +1,163 @@
+package goals
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+// minRecordStreak is the shortest streak announced as a new longest one
+const minRecordStreak = 3
+
+// Records are the streaks and best day over the whole history, kept in the
+// daily summaries
+type Records struct {
+	Streak     int             // Days in a row up to now
+	BestStreak int             // Most days in a row ever
+	BestDay    db.DailySummary // Day with the most pomodoros, the earliest on a tie
+}
+
+// OffDays are days without pomodoros that do not break a streak: rest
+// days of the week and vacations
+type OffDays struct {
+	Weekdays  []time.Weekday
+	Vacations []db.Vacation
+}
+
+// Off reports whether day is a rest day or falls in a vacation
+func (o OffDays) Off(day time.Time) bool {
+	if slices.Contains(o.Weekdays, day.Weekday()) {
+		return true
+	}
+	date := day.Format("2006-01-02")
+	for _, v := range o.Vacations {
+		if v.From <= date && date <= v.To {
+			return true
+		}
+	}
+	return false
+}
+
+// bridged reports whether every day after from and before to is off, so a
+// streak runs on from one to the other
+func (o OffDays) bridged(from, to time.Time) bool {
+	for day := from.AddDate(0, 0, 1); day.Before(to); day = day.AddDate(0, 0, 1) {
+		if !o.Off(day) {
+			return false
+		}
+	}
+	return true
+}
+
+// LoadOffDays returns goals.rest_days from the config, passing over names
+// that are not weekdays, and the vacations logged
+func LoadOffDays(database db.DB) (OffDays, error) {
+	var off OffDays
+	if cfg, err := config.LoadConfig(); err == nil {
+		for _, name := range cfg.Goals.RestDays {
+			if days, err := utils.ParseWeekdays([]string{name}); err == nil {
+				off.Weekdays = append(off.Weekdays, days...)
+			}
+		}
+	}
+	vacations, err := database.GetVacations()
+	if err != nil {
+		return OffDays{}, err
+	}
+	off.Vacations = vacations
+	return off, nil
+}
+
+// Streak returns how many days in a row, up to now, have at least one
+// finished pomodoro that was not cancelled or abandoned. A day with none
+// yet does not break the streak until it is over, so the streak counts back
+// from yesterday then, and neither do rest days and vacations.
+func Streak(database db.DB, now time.Time) (int, error) {
+	records, err := GetRecords(database, now)
+	return records.Streak, err
+}
+
+// GetRecords returns the streaks and best day up to now
+func GetRecords(database db.DB, now time.Time) (Records, error) {
+	summaries, err := database.GetDailySummaries()
+	if err != nil {
+		return Records{}, err
+	}
+	off, err := LoadOffDays(database)
+	if err != nil {
+		return Records{}, err
+	}
+	return ComputeRecords(summaries, now, off), nil
+}
+
+// ComputeRecords works out the records from daily summaries, oldest first.
+// Streaks count the days with pomodoros, carrying on over off days.
+func ComputeRecords(summaries []db.DailySummary, now time.Time, off OffDays) Records {
+	var records Records
+	run := 0
+	var last time.Time
+	for _, s := range summaries {
+		day, err := time.ParseInLocation("2006-01-02", s.Date, now.Location())
+		if err != nil || s.Pomodoros <= 0 {
+			continue
+		}
+		if s.Pomodoros > records.BestDay.Pomodoros {
+			records.BestDay = s
+		}
+		if run > 0 && off.bridged(last, day) {
+			run++
+		} else {
+			run = 1
+		}
+		last = day
+		records.BestStreak = max(records.BestStreak, run)
+	}
+
+	// The last run is the current streak if only off days lie between it and
+	// today
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if run > 0 && (!last.Before(today) || off.bridged(last, today)) {
+		records.Streak = run
+	}
+	return records
+}
+
+// NewRecordAchievements returns the records broken by the pomodoro that
+// brought the count of day (YYYY-MM-DD) to what summaries hold: a new best
+// day, and, with the day's first pomodoro, a new longest streak
+func NewRecordAchievements(summaries []db.DailySummary, day string, now time.Time, off OffDays) []Achievement {
+	var others []db.DailySummary
+	count := 0
+	for _, s := range summaries {
+		if s.Date == day {
+			count = s.Pomodoros
+		} else {
+			others = append(others, s)
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+
+	var achievements []Achievement
+	before := ComputeRecords(others, now, off)
+	if best := before.BestDay; best.Pomodoros > 0 && count == best.Pomodoros+1 {
+		achievements = append(achievements, Achievement{
+			ID:      AchievementBestDay,
+			Title:   "New Personal Best",
+			Message: fmt.Sprintf("%d pomodoros in a day, beating %d on %s.", count, best.Pomodoros, best.Date),
+		})
+	}
+	if streak := ComputeRecords(summaries, now, off).Streak; count == 1 && streak >= minRecordStreak && streak > before.BestStreak {
+		achievements = append(achievements, Achievement{
+			ID:      AchievementBestStreak,
+			Title:   "Longest Streak",
+			Message: fmt.Sprintf("%d days in a row, your longest yet.", streak),
+		})
+	}
+	return achievements
+}
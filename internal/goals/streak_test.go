@@ -0,0 +1,130 @@
+package goals
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func TestStreak(t *testing.T) {
+	database, err := db.NewDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = database.Close() }()
+
+	now := time.Date(2024, 6, 19, 8, 0, 0, 0, time.Local)
+	add := func(daysAgo int, wasBreak bool, status string) {
+		t.Helper()
+		start := time.Date(now.Year(), now.Month(), now.Day()-daysAgo, 7, 0, 0, 0, time.Local)
+		id, err := database.CreateSession(start, start.Add(25*time.Minute), "Work", 25*60, "", wasBreak)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status != "" {
+			if err := database.SetSessionStatus(id, status); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	streak := func() int {
+		t.Helper()
+		n, err := Streak(database, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	if n := streak(); n != 0 {
+		t.Errorf("Streak with no sessions = %d, want 0", n)
+	}
+
+	// Seventy days in a row up to yesterday, longer than one window
+	for day := 1; day <= 70; day++ {
+		add(day, false, db.StatusCompleted)
+	}
+	add(72, false, db.StatusCompleted) // Before the gap
+	if n := streak(); n != 70 {
+		t.Errorf("Streak before working today = %d, want 70", n)
+	}
+
+	add(0, true, "")                  // A break does not count
+	add(0, false, db.StatusCancelled) // Nor does a cancelled pomodoro
+	if n := streak(); n != 70 {
+		t.Errorf("Streak with only a break and a cancelled pomodoro today = %d, want 70", n)
+	}
+	add(0, false, db.StatusCompleted)
+	if n := streak(); n != 71 {
+		t.Errorf("Streak after working today = %d, want 71", n)
+	}
+}
+
+func TestNewRecordAchievements(t *testing.T) {
+	now := time.Date(2024, 6, 19, 12, 0, 0, 0, time.Local)
+	history := []db.DailySummary{
+		{Date: "2024-06-10", Pomodoros: 6},
+		{Date: "2024-06-11", Pomodoros: 2},
+		{Date: "2024-06-16", Pomodoros: 3},
+		{Date: "2024-06-17", Pomodoros: 1},
+		{Date: "2024-06-18", Pomodoros: 4},
+	}
+	with := func(today int) []db.DailySummary {
+		return append(append([]db.DailySummary{}, history...), db.DailySummary{Date: "2024-06-19", Pomodoros: today})
+	}
+	ids := func(achievements []Achievement) []string {
+		var out []string
+		for _, a := range achievements {
+			out = append(out, a.ID)
+		}
+		return out
+	}
+
+	records := ComputeRecords(with(1), now, OffDays{})
+	if records.Streak != 4 || records.BestStreak != 4 || records.BestDay.Date != "2024-06-10" {
+		t.Errorf("Expected a 4-day streak and 2024-06-10 as the best day, got %+v", records)
+	}
+
+	// The first pomodoro today makes the longest streak yet
+	if got := ids(NewRecordAchievements(with(1), "2024-06-19", now, OffDays{})); !slices.Equal(got, []string{AchievementBestStreak}) {
+		t.Errorf("Expected only best_streak with the first pomodoro, got %v", got)
+	}
+	if got := ids(NewRecordAchievements(with(6), "2024-06-19", now, OffDays{})); got != nil {
+		t.Errorf("Expected nothing for tying the best day, got %v", got)
+	}
+	if got := ids(NewRecordAchievements(with(7), "2024-06-19", now, OffDays{})); !slices.Equal(got, []string{AchievementBestDay}) {
+		t.Errorf("Expected best_day with the 7th pomodoro, got %v", got)
+	}
+	if got := ids(NewRecordAchievements(with(8), "2024-06-19", now, OffDays{})); got != nil {
+		t.Errorf("Expected best_day only once, got %v", got)
+	}
+}
+
+func TestComputeRecordsOffDays(t *testing.T) {
+	// Monday 2024-06-17; weekends are off and the 12th to the 13th a vacation
+	now := time.Date(2024, 6, 17, 8, 0, 0, 0, time.Local)
+	summaries := []db.DailySummary{
+		{Date: "2024-06-10", Pomodoros: 4},
+		{Date: "2024-06-11", Pomodoros: 3},
+		{Date: "2024-06-14", Pomodoros: 2},
+	}
+	off := OffDays{
+		Weekdays:  []time.Weekday{time.Saturday, time.Sunday},
+		Vacations: []db.Vacation{{From: "2024-06-12", To: "2024-06-13"}},
+	}
+
+	if records := ComputeRecords(summaries, now, off); records.Streak != 3 || records.BestStreak != 3 {
+		t.Errorf("Expected a 3-day streak over the vacation and weekend, got %+v", records)
+	}
+	if records := ComputeRecords(summaries, now, OffDays{}); records.Streak != 0 || records.BestStreak != 2 {
+		t.Errorf("Expected the streak broken without off days, got %+v", records)
+	}
+
+	// Tuesday with nothing on Monday breaks it
+	if records := ComputeRecords(summaries, now.AddDate(0, 0, 2), off); records.Streak != 0 {
+		t.Errorf("Expected a missed workday to break the streak, got %+v", records)
+	}
+}
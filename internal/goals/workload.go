@@ -0,0 +1,66 @@
+package goals
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+// workloadWindowDays is the number of days in the rolling average
+const workloadWindowDays = 7
+
+// Workload compares today's pomodoro count against the rolling average
+type Workload struct {
+	Today     int     // Pomodoros completed or planned today
+	Average   float64 // Average pomodoros per day over the previous 7 days
+	Threshold float64 // Ratio of the average above which today is considered overloaded
+}
+
+// ComputeWorkload counts today's pomodoros and averages the previous seven
+// days. Days without sessions count as zero so the average reflects real capacity.
+// Cancelled and abandoned pomodoros are left out, as that work never
+// happened; the one running today counts.
+func ComputeWorkload(database db.DB, now time.Time, threshold float64) (*Workload, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	windowStart := today.AddDate(0, 0, -workloadWindowDays)
+
+	sessions, err := database.GetSessionsByDateRange(windowStart, today)
+	if err != nil {
+		return nil, fmt.Errorf("error getting recent sessions: %v", err)
+	}
+
+	w := &Workload{Threshold: threshold}
+	past := 0
+	for _, s := range sessions {
+		if s.WasBreak || s.Incomplete() {
+			continue
+		}
+		if s.StartTime.Before(today) {
+			past++
+		} else {
+			w.Today++
+		}
+	}
+	w.Average = float64(past) / workloadWindowDays
+
+	return w, nil
+}
+
+// Exceeds reports whether count is above the threshold share of the average.
+// Without any history there is no baseline, so it never warns.
+func (w *Workload) Exceeds(count int) bool {
+	if w.Threshold <= 0 || w.Average == 0 {
+		return false
+	}
+	return float64(count) > w.Average*w.Threshold
+}
+
+// Warning returns a capacity warning for the given count, or "" if it is within capacity
+func (w *Workload) Warning(count int) string {
+	if !w.Exceeds(count) {
+		return ""
+	}
+	return fmt.Sprintf("You're scheduling %d pomodoros today; your 7-day average is %.1f — consider trimming.",
+		count, w.Average)
+}
@@ -0,0 +1,75 @@
+package goals
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+// rangeDB serves a fixed set of sessions for date range queries
+type rangeDB struct {
+	db.DB
+	sessions []db.PomodoroSession
+}
+
+func (r *rangeDB) GetSessionsByDateRange(_, _ time.Time, _ ...string) ([]db.PomodoroSession, error) {
+	return r.sessions, nil
+}
+
+func TestComputeWorkload(t *testing.T) {
+	now := time.Date(2024, 6, 10, 15, 0, 0, 0, time.UTC)
+
+	var sessions []db.PomodoroSession
+	// 7 pomodoros per day for the previous week, plus a break each day
+	for day := 1; day <= 7; day++ {
+		start := now.AddDate(0, 0, -day)
+		for i := 0; i < 7; i++ {
+			sessions = append(sessions, db.PomodoroSession{StartTime: start})
+		}
+		sessions = append(sessions, db.PomodoroSession{StartTime: start, WasBreak: true})
+	}
+	// 3 pomodoros so far today
+	for i := 0; i < 3; i++ {
+		sessions = append(sessions, db.PomodoroSession{StartTime: now.Add(-time.Hour)})
+	}
+	// Work that never happened, in the window and today
+	for _, status := range []string{db.StatusCancelled, db.StatusAbandoned} {
+		sessions = append(sessions,
+			db.PomodoroSession{StartTime: now.AddDate(0, 0, -2), Status: status},
+			db.PomodoroSession{StartTime: now.Add(-30 * time.Minute), Status: status})
+	}
+
+	workload, err := ComputeWorkload(&rangeDB{sessions: sessions}, now, 1.5)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if workload.Today != 3 {
+		t.Errorf("Expected 3 pomodoros today, got %d", workload.Today)
+	}
+	if workload.Average != 7 {
+		t.Errorf("Expected average of 7, got %.1f", workload.Average)
+	}
+
+	if workload.Warning(10) != "" {
+		t.Error("Expected no warning at 10 pomodoros (limit 10.5)")
+	}
+	warning := workload.Warning(14)
+	if !strings.Contains(warning, "14 pomodoros") || !strings.Contains(warning, "7.0") {
+		t.Errorf("Unexpected warning: %q", warning)
+	}
+}
+
+func TestWorkload_NoBaseline(t *testing.T) {
+	w := &Workload{Average: 0, Threshold: 1.5}
+	if w.Exceeds(20) {
+		t.Error("Expected no warning without history")
+	}
+
+	w = &Workload{Average: 4, Threshold: 0}
+	if w.Exceeds(20) {
+		t.Error("Expected no warning when threshold is disabled")
+	}
+}
@@ -0,0 +1,112 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Env returns the payload as environment variables, for hooks and commands
+// that would rather not parse JSON
+func (p Payload) Env() []string {
+	return []string{
+		"POMODORO_EVENT=" + p.Event,
+		"POMODORO_SESSION_ID=" + strconv.FormatInt(p.SessionID, 10),
+		"POMODORO_REF=" + p.Ref,
+		"POMODORO_DESCRIPTION=" + p.Description,
+		"POMODORO_TAGS=" + strings.Join(p.Tags, ","),
+		"POMODORO_WAS_BREAK=" + strconv.FormatBool(p.WasBreak),
+		"POMODORO_START_TIME=" + p.StartTime.Format(time.RFC3339),
+		"POMODORO_END_TIME=" + p.EndTime.Format(time.RFC3339),
+		"POMODORO_DURATION=" + p.Duration,
+	}
+}
+
+// RunCommands runs the shell command lines of the config list called name,
+// such as on_start, one after another, each with the payload in its
+// environment and limited to timeout (DefaultTimeout when zero) and to what
+// policy grants name. They run from the home directory, so paths such as
+// ~/project work as they would in a terminal. All failures are returned
+// together.
+func RunCommands(ctx context.Context, name string, commands []string, payload Payload, timeout time.Duration, policy *Policy) error {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	env, ok := policy.env(name, append(os.Environ(), payload.Env()...))
+	if !ok {
+		return fmt.Errorf("%s commands not run: %s has no entry in hooks.permissions", name, name)
+	}
+	var errs []error
+	for _, line := range commands {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if err := runCommand(ctx, line, env, policy.offline(name), timeout); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runCommand runs a single command line, cut off from the network when
+// offline
+func runCommand(ctx context.Context, line string, env []string, offline bool, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, line)
+	if offline {
+		if err := cutOffNetwork(cmd); err != nil {
+			return fmt.Errorf("command %q not run: %v", line, err)
+		}
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		cmd.Dir = home
+	}
+	cmd.Env = env
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	// Do not wait on pipes held open by background children once killed
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Start(); err != nil {
+		if offline {
+			return fmt.Errorf("command %q not run: %v", line, offlineError(err))
+		}
+		return fmt.Errorf("command %q failed: %v", line, err)
+	}
+	err := cmd.Wait()
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		return fmt.Errorf("command %q timed out after %s", line, timeout)
+	case err != nil:
+		if out := lastLine(output.String()); out != "" {
+			return fmt.Errorf("command %q failed: %v: %s", line, err, out)
+		}
+		return fmt.Errorf("command %q failed: %v", line, err)
+	}
+	return nil
+}
+
+// offlineError explains err, from starting a hook or command cut off from
+// the network. That fails where the system does not let users create
+// namespaces, and it is then refused rather than run with the network.
+func offlineError(err error) error {
+	return fmt.Errorf("could not cut it off the network (%v); grant it network, or allow unprivileged user namespaces", err)
+}
+
+// shellCommand builds the command that runs line in the user's shell
+func shellCommand(ctx context.Context, line string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", line) // #nosec G204 - commands come from the user's own config
+	}
+	return exec.CommandContext(ctx, "/bin/sh", "-c", line) // #nosec G204 - commands come from the user's own config
+}
@@ -0,0 +1,293 @@
+// Package hooks runs user executables from the hooks directory, and command
+// lines from the config, when sessions start, finish, pause, resume, or are
+// cancelled
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+// Events that run hooks
+const (
+	SessionStart    = "session_start"
+	SessionComplete = "session_complete"
+	BreakStart      = "break_start"
+	BreakComplete   = "break_complete"
+	Pause           = "pause"
+	Resume          = "resume"
+	Cancel          = "cancel"
+)
+
+// Events lists every event, in the order they are documented
+var Events = []string{SessionStart, SessionComplete, BreakStart, BreakComplete, Pause, Resume, Cancel}
+
+// DefaultTimeout is how long a hook may run before it is killed
+const DefaultTimeout = 10 * time.Second
+
+// maxErrorOutput caps how much of a failing hook's output is reported
+const maxErrorOutput = 200
+
+// Payload is the JSON document a hook receives on stdin
+type Payload struct {
+	Event       string    `json:"event"`
+	Time        time.Time `json:"time"`
+	SessionID   int64     `json:"session_id"`
+	Ref         string    `json:"ref"`
+	Description string    `json:"description"`
+	Tags        []string  `json:"tags"`
+	WasBreak    bool      `json:"was_break"`
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time"`
+	Duration    string    `json:"duration"` // Planned length, e.g. "25m0s"
+}
+
+// NewPayload describes an event for a session
+func NewPayload(event string, session *db.PomodoroSession, now time.Time) Payload {
+	tags := []string{}
+	for _, tag := range strings.Split(session.TagsCSV, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return Payload{
+		Event:       event,
+		Time:        now,
+		SessionID:   session.ID,
+		Ref:         session.ShortRef(),
+		Description: session.Description,
+		Tags:        tags,
+		WasBreak:    session.WasBreak,
+		StartTime:   session.StartTime,
+		EndTime:     session.EndTime,
+		Duration:    (time.Duration(session.DurationSec) * time.Second).String(),
+	}
+}
+
+// StartEvent returns the event for starting a session
+func StartEvent(wasBreak bool) string {
+	if wasBreak {
+		return BreakStart
+	}
+	return SessionStart
+}
+
+// CompleteEvent returns the event for a session running to its end
+func CompleteEvent(wasBreak bool) string {
+	if wasBreak {
+		return BreakComplete
+	}
+	return SessionComplete
+}
+
+// Runner runs the hooks in a directory
+type Runner struct {
+	Dir     string
+	Timeout time.Duration
+	Policy  *Policy // What each hook may do, nil for anything
+}
+
+// Hook is a hook found in the hooks directory
+type Hook struct {
+	Event      string
+	Name       string // Path within the hooks directory, e.g. session_start.d/10-slack
+	Path       string
+	Executable bool // Hooks that are not are skipped
+}
+
+// New creates a runner for the hooks in dir. A zero timeout uses DefaultTimeout.
+func New(dir string, timeout time.Duration) *Runner {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Runner{Dir: dir, Timeout: timeout}
+}
+
+// Find returns the hooks for an event in the order they run: a file named
+// after the event, with or without an extension (session_start,
+// session_start.sh), then the files in a directory named after the event
+// with a .d suffix (session_start.d/), sorted by name
+func (r *Runner) Find(event string) ([]string, error) {
+	entries, err := os.ReadDir(r.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading hooks directory: %v", err)
+	}
+
+	var found []string
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() && strings.TrimSuffix(name, filepath.Ext(name)) == event {
+			found = append(found, filepath.Join(r.Dir, name))
+		}
+	}
+	sort.Strings(found)
+
+	dir := filepath.Join(r.Dir, event+".d")
+	entries, err = os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading hooks directory: %v", err)
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			found = append(found, filepath.Join(dir, e.Name()))
+		}
+	}
+
+	return found, nil
+}
+
+// All returns the hooks for every event, in the order Events lists them and
+// each event's in the order they run
+func (r *Runner) All() ([]Hook, error) {
+	var all []Hook
+	for _, event := range Events {
+		paths, err := r.Find(event)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			all = append(all, Hook{Event: event, Name: r.Name(path), Path: path, Executable: executable(path)})
+		}
+	}
+	return all, nil
+}
+
+// Name returns how hooks.permissions refers to the hook at path: its path
+// within the hooks directory, with forward slashes
+func (r *Runner) Name(path string) string {
+	if rel, err := filepath.Rel(r.Dir, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.Base(path)
+}
+
+// Run runs every hook for the payload's event one after another, each with
+// the payload as JSON on stdin and limited to the runner's timeout and
+// permissions. Hooks that are not executable are skipped. All failures are
+// returned together.
+func (r *Runner) Run(ctx context.Context, payload Payload) error {
+	paths, err := r.Find(payload.Event)
+	if err != nil || len(paths) == 0 {
+		return err
+	}
+
+	input, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error encoding hook payload: %v", err)
+	}
+	// End with a newline so line-oriented tools such as read see the payload
+	input = append(input, '\n')
+
+	var errs []error
+	for _, path := range paths {
+		if !executable(path) {
+			continue
+		}
+		env, ok := r.Policy.env(r.Name(path), append(os.Environ(), payload.Env()...))
+		if !ok {
+			errs = append(errs, fmt.Errorf("hook %s not run: it has no entry in hooks.permissions", r.Name(path)))
+			continue
+		}
+		if err := r.runOne(ctx, path, env, r.Policy.offline(r.Name(path)), input); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runOne runs a single hook, cut off from the network when offline
+func (r *Runner) runOne(ctx context.Context, path string, env []string, offline bool, input []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	name := filepath.Base(path)
+	cmd := command(ctx, path)
+	if offline {
+		if err := cutOffNetwork(cmd); err != nil {
+			return fmt.Errorf("hook %s not run: %v", name, err)
+		}
+	}
+	cmd.Dir = r.Dir
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Env = env
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	// Do not wait on pipes held open by background children once killed
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Start(); err != nil {
+		if offline {
+			return fmt.Errorf("hook %s not run: %v", name, offlineError(err))
+		}
+		return fmt.Errorf("hook %s failed: %v", name, err)
+	}
+	err := cmd.Wait()
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		return fmt.Errorf("hook %s timed out after %s", name, r.Timeout)
+	case err != nil:
+		if out := lastLine(output.String()); out != "" {
+			return fmt.Errorf("hook %s failed: %v: %s", name, err, out)
+		}
+		return fmt.Errorf("hook %s failed: %v", name, err)
+	}
+	return nil
+}
+
+// command builds the command for a hook. Windows has no executable bit, so
+// scripts are run by the interpreter their extension names.
+func command(ctx context.Context, path string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".ps1":
+			return exec.CommandContext(ctx, "powershell", "-NoProfile", "-ExecutionPolicy", "Bypass", "-File", path) // #nosec G204 - hooks are the user's own scripts
+		case ".bat", ".cmd":
+			return exec.CommandContext(ctx, "cmd", "/C", path) // #nosec G204 - hooks are the user's own scripts
+		}
+	}
+	return exec.CommandContext(ctx, path) // #nosec G204 - hooks are the user's own scripts
+}
+
+// executable reports whether path can be run as a hook. On Windows this is
+// decided by the extension, elsewhere by the executable bit.
+func executable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".exe", ".bat", ".cmd", ".ps1":
+			return true
+		}
+		return false
+	}
+	return info.Mode()&0111 != 0
+}
+
+// lastLine returns the last non-empty line of a hook's output, shortened
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	line := strings.TrimSpace(lines[len(lines)-1])
+	if len(line) > maxErrorOutput {
+		line = line[:maxErrorOutput] + "..."
+	}
+	return line
+}
@@ -0,0 +1,232 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+// writeHook creates a shell script hook
+func writeHook(t *testing.T, path, script string, mode os.FileMode) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunPassesPayload(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script hooks")
+	}
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+
+	writeHook(t, filepath.Join(dir, "session_start.sh"), `cat > "`+out+`.1"; echo "$POMODORO_EVENT" >> "`+out+`.1"`, 0700)
+	writeHook(t, filepath.Join(dir, "session_start.d", "10-second"), `cat > "`+out+`.2"`, 0700)
+	writeHook(t, filepath.Join(dir, "session_start.d", "20-disabled"), `touch "`+out+`.3"`, 0600)
+	writeHook(t, filepath.Join(dir, "pause"), `touch "`+out+`.4"`, 0700)
+
+	session := &db.PomodoroSession{
+		ID: 7, UID: "abcdef012345", Description: "Write", TagsCSV: "work, writing",
+		StartTime: time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC), DurationSec: 1500,
+	}
+	r := New(dir, 0)
+	if err := r.Run(context.Background(), NewPayload(SessionStart, session, session.StartTime)); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	data, err := os.ReadFile(out + ".1")
+	if err != nil {
+		t.Fatalf("Expected the first hook to run: %v", err)
+	}
+	lines := strings.SplitN(string(data), "\n", 2)
+	var payload Payload
+	if err := json.Unmarshal([]byte(lines[0]), &payload); err != nil {
+		t.Fatalf("Invalid payload %q: %v", lines[0], err)
+	}
+	if payload.SessionID != 7 || payload.Ref != "#abcdef" || len(payload.Tags) != 2 || payload.Duration != "25m0s" {
+		t.Errorf("Unexpected payload %+v", payload)
+	}
+	if strings.TrimSpace(lines[1]) != SessionStart {
+		t.Errorf("Expected POMODORO_EVENT in the environment, got %q", lines[1])
+	}
+
+	if _, err := os.Stat(out + ".2"); err != nil {
+		t.Error("Expected hooks in session_start.d to run")
+	}
+	if _, err := os.Stat(out + ".3"); err == nil {
+		t.Error("Expected non-executable hooks to be skipped")
+	}
+	if _, err := os.Stat(out + ".4"); err == nil {
+		t.Error("Expected hooks for other events not to run")
+	}
+}
+
+func TestRunReportsFailuresAndTimeouts(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script hooks")
+	}
+	dir := t.TempDir()
+	writeHook(t, filepath.Join(dir, "cancel.d", "1-fail"), `echo "no server" >&2; exit 3`, 0700)
+	writeHook(t, filepath.Join(dir, "cancel.d", "2-slow"), `sleep 5`, 0700)
+
+	r := New(dir, 200*time.Millisecond)
+	err := r.Run(context.Background(), Payload{Event: Cancel})
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	for _, want := range []string{"1-fail failed", "no server", "2-slow timed out"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q in %q", want, err)
+		}
+	}
+}
+
+func TestFindWithoutHooksDir(t *testing.T) {
+	r := New(filepath.Join(t.TempDir(), "missing"), 0)
+	if paths, err := r.Find(SessionStart); err != nil || len(paths) != 0 {
+		t.Errorf("Expected no hooks and no error, got %v, %v", paths, err)
+	}
+}
+
+func TestRunCommands(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell commands")
+	}
+	out := filepath.Join(t.TempDir(), "out")
+	payload := NewPayload(SessionComplete, &db.PomodoroSession{
+		ID: 7, UID: "abcdef012345", Description: "Write", TagsCSV: "work,writing", DurationSec: 1500,
+	}, time.Now())
+
+	commands := []string{
+		`echo "$POMODORO_EVENT $POMODORO_SESSION_ID $POMODORO_DESCRIPTION $POMODORO_TAGS" > "` + out + `"`,
+		"",
+		`echo "no editor" >&2; exit 1`,
+	}
+	err := RunCommands(context.Background(), "on_complete", commands, payload, 0, nil)
+	if err == nil || !strings.Contains(err.Error(), "no editor") {
+		t.Errorf("Expected the failing command to be reported, got %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Expected the first command to run: %v", err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "session_complete 7 Write work,writing" {
+		t.Errorf("Expected the session in the environment, got %q", got)
+	}
+}
+
+func TestRunEnforcesPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script hooks")
+	}
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "unix:path=/run/user/1000/bus")
+
+	env := `echo "$POMODORO_HOOK|$POMODORO_HOOK_PERMISSIONS|$DBUS_SESSION_BUS_ADDRESS" > "` + out + `.`
+	writeHook(t, filepath.Join(dir, "session_start.d", "10-status"), env+`1"`, 0700)
+	writeHook(t, filepath.Join(dir, "session_start.d", "20-sync"), env+`2"`, 0700)
+	writeHook(t, filepath.Join(dir, "session_start.d", "30-unknown"), `touch "`+out+`.3"`, 0700)
+
+	r := New(dir, 0)
+	r.Policy = &Policy{Grants: map[string][]string{
+		"session_start.d/10-status": {PermNetwork, PermNotify},
+		"session_start.d/20-sync":   {PermNetwork, PermModifySessions},
+	}}
+	payload := NewPayload(SessionStart, &db.PomodoroSession{ID: 1}, time.Now())
+	if err := r.Run(context.Background(), payload); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	for i, want := range []string{
+		"session_start.d/10-status|network,notify|unix:path=/run/user/1000/bus",
+		"session_start.d/20-sync|network,modify_sessions|",
+	} {
+		data, err := os.ReadFile(fmt.Sprintf("%s.%d", out, i+1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.TrimSpace(string(data)); got != want {
+			t.Errorf("Expected the environment %q, got %q", want, got)
+		}
+	}
+	if _, err := os.Stat(out + ".3"); err != nil {
+		t.Errorf("Expected a hook without declared permissions to run: %v", err)
+	}
+
+	if err := os.Remove(out + ".3"); err != nil {
+		t.Fatal(err)
+	}
+	r.Policy.Require = true
+	err := r.Run(context.Background(), payload)
+	if err == nil || !strings.Contains(err.Error(), "session_start.d/30-unknown not run") {
+		t.Errorf("Expected the undeclared hook refused, got %v", err)
+	}
+	if _, err := os.Stat(out + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Expected the undeclared hook not to run")
+	}
+}
+
+func TestRunCutsOffNetwork(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script hooks")
+	}
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	// A process cut off from the network is in a network namespace of its own
+	own, _ := os.Readlink("/proc/self/ns/net")
+	writeHook(t, filepath.Join(dir, "session_start.d", "10-offline"), `readlink /proc/self/ns/net > "`+out+`.1"`, 0700)
+	writeHook(t, filepath.Join(dir, "session_start.d", "20-online"), `readlink /proc/self/ns/net > "`+out+`.2"`, 0700)
+
+	r := New(dir, 0)
+	r.Policy = &Policy{Grants: map[string][]string{
+		"session_start.d/10-offline": {PermNotify},
+		"session_start.d/20-online":  {PermNetwork},
+	}}
+	err := r.Run(context.Background(), NewPayload(SessionStart, &db.PomodoroSession{ID: 1}, time.Now()))
+
+	// Where it cannot be cut off, the hook is refused rather than run
+	offline, readErr := os.ReadFile(out + ".1")
+	switch {
+	case err != nil:
+		if !strings.Contains(err.Error(), "hook 10-offline not run") || !os.IsNotExist(readErr) {
+			t.Errorf("Expected only the hook without network refused, got %v", err)
+		}
+		if NetworkIsolation {
+			t.Logf("Hooks cannot be cut off from the network here: %v", err)
+		}
+	case !NetworkIsolation:
+		t.Error("Expected the hook without network refused where it cannot be cut off")
+	case strings.TrimSpace(string(offline)) == own:
+		t.Errorf("Expected the hook without network in a namespace of its own, got %s", offline)
+	}
+	if online, err := os.ReadFile(out + ".2"); err != nil || runtime.GOOS == "linux" && strings.TrimSpace(string(online)) != own {
+		t.Errorf("Expected the hook with network to run as usual, got %q, %v", online, err)
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	if err := Allowed(PermModifySessions); err != nil {
+		t.Errorf("Expected anything allowed outside a hook, got %v", err)
+	}
+	t.Setenv(NameEnv, "session_complete.sh")
+	t.Setenv(PermissionsEnv, "notify")
+	if err := Allowed(PermNotify); err != nil {
+		t.Errorf("Expected a granted permission allowed, got %v", err)
+	}
+	if err := Allowed(PermModifySessions); err == nil || !strings.Contains(err.Error(), "hook session_complete.sh may not change sessions") {
+		t.Errorf("Expected modify_sessions refused, got %v", err)
+	}
+}
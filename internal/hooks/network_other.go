@@ -0,0 +1,18 @@
+//go:build !linux
+
+package hooks
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// NetworkIsolation reports whether hooks without the network permission can
+// be run here, cut off from the network
+const NetworkIsolation = false
+
+// cutOffNetwork refuses, as only Linux can keep a process off the network
+// without privileges
+func cutOffNetwork(*exec.Cmd) error {
+	return errors.New("it cannot be kept off the network on this system; grant it network to run it")
+}
@@ -0,0 +1,117 @@
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Permissions a hook or configured command may be granted
+const (
+	PermNetwork        = "network"         // Reach the network, the local API included
+	PermNotify         = "notify"          // Show desktop notifications
+	PermModifySessions = "modify_sessions" // Run pomodoro commands that change sessions
+)
+
+// Permissions lists every permission, in the order they are documented
+var Permissions = []string{PermNetwork, PermNotify, PermModifySessions}
+
+// Environment variables that tell pomodoro it runs under a restricted hook
+const (
+	NameEnv        = "POMODORO_HOOK"
+	PermissionsEnv = "POMODORO_HOOK_PERMISSIONS"
+)
+
+// Policy is what hooks and configured commands may do. A nil policy, or one
+// without grants for a hook, lets it do anything, as before permissions were
+// declared, unless Require is set.
+type Policy struct {
+	Grants  map[string][]string // Hook name, as Name gives it, or on_start and on_complete → permissions
+	Require bool                // Refuse to run hooks with no grants
+}
+
+// Lookup returns what the hook called name may do, and whether that was
+// declared at all
+func (p *Policy) Lookup(name string) ([]string, bool) {
+	if p == nil {
+		return nil, false
+	}
+	perms, ok := p.Grants[name]
+	return perms, ok
+}
+
+// Check returns the permissions declared for name that do not exist
+func (p *Policy) Check(name string) []string {
+	perms, _ := p.Lookup(name)
+	var unknown []string
+	for _, perm := range perms {
+		if !slices.Contains(Permissions, perm) {
+			unknown = append(unknown, perm)
+		}
+	}
+	return unknown
+}
+
+// offline reports whether the hook called name must be kept off the
+// network: it has declared permissions, and network is not among them
+func (p *Policy) offline(name string) bool {
+	perms, declared := p.Lookup(name)
+	return declared && !slices.Contains(perms, PermNetwork)
+}
+
+// env returns the environment the hook called name runs with, on top of
+// base, and false when the policy does not let it run at all. A hook
+// without declared permissions keeps base as it is.
+func (p *Policy) env(name string, base []string) ([]string, bool) {
+	perms, declared := p.Lookup(name)
+	if !declared {
+		return base, p == nil || !p.Require
+	}
+	return Restrict(base, name, perms), true
+}
+
+// Restrict returns env, a list of KEY=value pairs, changed to hold the hook
+// called name to perms: pomodoro refuses commands needing anything else,
+// and the desktop session bus is hidden without notify. Network access is
+// not a matter of the environment; hooks without it are cut off from the
+// network when they start.
+func Restrict(env []string, name string, perms []string) []string {
+	drop := []string{NameEnv, PermissionsEnv}
+	if !slices.Contains(perms, PermNotify) {
+		drop = append(drop, "DBUS_SESSION_BUS_ADDRESS")
+	}
+
+	restricted := make([]string, 0, len(env)+2)
+	for _, kv := range env {
+		key, _, _ := strings.Cut(kv, "=")
+		if !slices.Contains(drop, key) {
+			restricted = append(restricted, kv)
+		}
+	}
+	return append(restricted, NameEnv+"="+name, PermissionsEnv+"="+strings.Join(perms, ","))
+}
+
+// Allowed returns an error when pomodoro runs under a hook that was not
+// granted perm, and nil otherwise
+func Allowed(perm string) error {
+	granted, restricted := os.LookupEnv(PermissionsEnv)
+	if !restricted || slices.Contains(strings.Split(granted, ","), perm) {
+		return nil
+	}
+	return fmt.Errorf("hook %s may not %s: add %s to its entry in hooks.permissions",
+		os.Getenv(NameEnv), describe(perm), perm)
+}
+
+// describe says what a permission allows, to complete "may not ..."
+func describe(perm string) string {
+	switch perm {
+	case PermNetwork:
+		return "use the network"
+	case PermNotify:
+		return "send notifications"
+	case PermModifySessions:
+		return "change sessions"
+	}
+	return perm
+}
@@ -0,0 +1,33 @@
+package idle
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// hidIdleTime matches the HID system's idle time, in nanoseconds
+var hidIdleTime = regexp.MustCompile(`"HIDIdleTime" = (\d+)`)
+
+// platformActivityDetector reads the HID system's idle time from the
+// IORegistry, as IOKit reports it
+type platformActivityDetector struct{}
+
+// IdleTime returns the time since the last keyboard or mouse input
+func (platformActivityDetector) IdleTime() (time.Duration, error) {
+	out, err := exec.Command("ioreg", "-c", "IOHIDSystem", "-d", "4").Output()
+	if err != nil {
+		return 0, fmt.Errorf("error reading idle time: %v", err)
+	}
+	m := hidIdleTime.FindSubmatch(out)
+	if m == nil {
+		return 0, fmt.Errorf("error reading idle time: HIDIdleTime not found")
+	}
+	ns, err := strconv.ParseInt(string(m[1]), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("error reading idle time: %v", err)
+	}
+	return time.Duration(ns), nil
+}
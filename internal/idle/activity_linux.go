@@ -0,0 +1,54 @@
+package idle
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// platformActivityDetector asks xprintidle for the X server's idle time,
+// falling back to systemd-logind's idle hint, which Wayland desktops set
+type platformActivityDetector struct{}
+
+// IdleTime returns the time since the last keyboard or mouse input
+func (platformActivityDetector) IdleTime() (time.Duration, error) {
+	if out, err := exec.Command("xprintidle").Output(); err == nil {
+		ms, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("error reading idle time: %v", err)
+		}
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+	return logindIdleTime()
+}
+
+// logindIdleTime reads the session's idle hint, which only says the user
+// has been idle since some time once the desktop decides they are idle
+func logindIdleTime() (time.Duration, error) {
+	session, err := graphicalSession()
+	if err != nil {
+		return 0, err
+	}
+
+	out, err := exec.Command("loginctl", "show-session", session, "--property=IdleHint", "--property=IdleSinceHint").Output() // #nosec G204 - session ID comes from logind
+	if err != nil {
+		return 0, fmt.Errorf("error reading idle time: %v", err)
+	}
+	props := make(map[string]string)
+	for _, line := range strings.Split(string(out), "\n") {
+		if key, value, ok := strings.Cut(strings.TrimSpace(line), "="); ok {
+			props[key] = value
+		}
+	}
+	if props["IdleHint"] != "yes" {
+		return 0, nil
+	}
+	// IdleSinceHint is in microseconds since the epoch
+	usec, err := strconv.ParseInt(props["IdleSinceHint"], 10, 64)
+	if err != nil || usec == 0 {
+		return 0, fmt.Errorf("error reading idle time: no IdleSinceHint")
+	}
+	return time.Since(time.UnixMicro(usec)), nil
+}
@@ -0,0 +1,17 @@
+//go:build !darwin && !linux && !windows
+
+package idle
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// platformActivityDetector is a placeholder for platforms without idle detection
+type platformActivityDetector struct{}
+
+// IdleTime always fails on platforms without idle detection
+func (platformActivityDetector) IdleTime() (time.Duration, error) {
+	return 0, fmt.Errorf("idle detection is not supported on %s", runtime.GOOS)
+}
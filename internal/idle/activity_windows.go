@@ -0,0 +1,36 @@
+package idle
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var (
+	user32               = syscall.NewLazyDLL("user32.dll")
+	kernel32             = syscall.NewLazyDLL("kernel32.dll")
+	procGetLastInputInfo = user32.NewProc("GetLastInputInfo")
+	procGetTickCount     = kernel32.NewProc("GetTickCount")
+)
+
+// lastInputInfo is the LASTINPUTINFO structure
+type lastInputInfo struct {
+	cbSize uint32
+	dwTime uint32
+}
+
+// platformActivityDetector compares the tick count of the last input event
+// with the current one
+type platformActivityDetector struct{}
+
+// IdleTime returns the time since the last keyboard or mouse input
+func (platformActivityDetector) IdleTime() (time.Duration, error) {
+	info := lastInputInfo{cbSize: uint32(unsafe.Sizeof(lastInputInfo{}))}
+	if ok, _, err := procGetLastInputInfo.Call(uintptr(unsafe.Pointer(&info))); ok == 0 {
+		return 0, fmt.Errorf("error reading idle time: %v", err)
+	}
+	now, _, _ := procGetTickCount.Call()
+	// Both wrap around after 49.7 days; the difference survives that
+	return time.Duration(uint32(now)-info.dwTime) * time.Millisecond, nil
+}
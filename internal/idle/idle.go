@@ -0,0 +1,37 @@
+// Package idle detects when the user has stepped away from the machine,
+// such as when the screen is locked or there has been no input for a while,
+// and can send them away by locking the screen
+package idle
+
+import "time"
+
+// LockDetector reports whether the screen is currently locked
+type LockDetector interface {
+	Locked() (bool, error)
+}
+
+// NewLockDetector returns the lock detector for this platform
+func NewLockDetector() LockDetector {
+	return platformLockDetector{}
+}
+
+// ActivityDetector reports how long the user has been idle, with no
+// keyboard or mouse input
+type ActivityDetector interface {
+	IdleTime() (time.Duration, error)
+}
+
+// NewActivityDetector returns the activity detector for this platform
+func NewActivityDetector() ActivityDetector {
+	return platformActivityDetector{}
+}
+
+// LockScreen locks the screen, so the user has to sign in again
+func LockScreen() error {
+	return lockScreen()
+}
+
+// SleepDisplay puts the display to sleep until there is input
+func SleepDisplay() error {
+	return sleepDisplay()
+}
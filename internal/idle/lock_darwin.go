@@ -0,0 +1,19 @@
+package idle
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// platformLockDetector reads the session's lock flag from the IORegistry
+type platformLockDetector struct{}
+
+// Locked reports whether the login session's screen is locked
+func (platformLockDetector) Locked() (bool, error) {
+	out, err := exec.Command("ioreg", "-n", "Root", "-d1").Output()
+	if err != nil {
+		return false, fmt.Errorf("error reading lock state: %v", err)
+	}
+	return strings.Contains(string(out), `"CGSSessionScreenIsLocked"=Yes`), nil
+}
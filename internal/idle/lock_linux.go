@@ -0,0 +1,47 @@
+package idle
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// platformLockDetector asks systemd-logind for the session's lock hint,
+// which GNOME, KDE, and most screen lockers keep up to date
+type platformLockDetector struct{}
+
+// Locked reports whether the login session's screen is locked
+func (platformLockDetector) Locked() (bool, error) {
+	session, err := graphicalSession()
+	if err != nil {
+		return false, err
+	}
+
+	out, err := exec.Command("loginctl", "show-session", session, "--property=LockedHint", "--value").Output() // #nosec G204 - session ID comes from logind
+	if err != nil {
+		return false, fmt.Errorf("error reading lock state: %v", err)
+	}
+	return strings.TrimSpace(string(out)) == "yes", nil
+}
+
+// graphicalSession returns the logind session to query. Services started by
+// the user's systemd instance have no XDG_SESSION_ID, so fall back to the
+// user's primary graphical session.
+func graphicalSession() (string, error) {
+	if session := os.Getenv("XDG_SESSION_ID"); session != "" {
+		return session, nil
+	}
+
+	uid := strconv.Itoa(os.Getuid())
+	out, err := exec.Command("loginctl", "show-user", uid, "--property=Display", "--value").Output() // #nosec G204 - uid is numeric
+	if err != nil {
+		return "", fmt.Errorf("error finding login session: %v", err)
+	}
+	session := strings.TrimSpace(string(out))
+	if session == "" {
+		return "", fmt.Errorf("no graphical login session found")
+	}
+	return session, nil
+}
@@ -0,0 +1,16 @@
+//go:build !darwin && !linux && !windows
+
+package idle
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// platformLockDetector is a placeholder for platforms without lock detection
+type platformLockDetector struct{}
+
+// Locked always fails on platforms without lock detection
+func (platformLockDetector) Locked() (bool, error) {
+	return false, fmt.Errorf("screen lock detection is not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,19 @@
+package idle
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// platformLockDetector treats a running LogonUI.exe as a locked workstation
+type platformLockDetector struct{}
+
+// Locked reports whether the workstation is locked
+func (platformLockDetector) Locked() (bool, error) {
+	out, err := exec.Command("tasklist", "/FI", "IMAGENAME eq LogonUI.exe", "/NH").Output()
+	if err != nil {
+		return false, fmt.Errorf("error reading lock state: %v", err)
+	}
+	return strings.Contains(strings.ToLower(string(out)), "logonui.exe"), nil
+}
@@ -0,0 +1,20 @@
+package idle
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// lockScreen sleeps the display, which locks the screen when macOS asks for
+// the password immediately after sleep, as it does by default
+func lockScreen() error {
+	return sleepDisplay()
+}
+
+// sleepDisplay puts the display to sleep at once
+func sleepDisplay() error {
+	if err := exec.Command("pmset", "displaysleepnow").Run(); err != nil {
+		return fmt.Errorf("error putting the display to sleep: %v", err)
+	}
+	return nil
+}
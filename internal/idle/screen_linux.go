@@ -0,0 +1,32 @@
+package idle
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+)
+
+// lockScreen asks systemd-logind to lock the login session, which the
+// desktop's screen locker carries out
+func lockScreen() error {
+	session, err := graphicalSession()
+	if err != nil {
+		return err
+	}
+	if err := exec.Command("loginctl", "lock-session", session).Run(); err != nil { // #nosec G204 - session ID comes from logind
+		return fmt.Errorf("error locking the screen: %v", err)
+	}
+	return nil
+}
+
+// sleepDisplay turns the display off through X11 power management
+func sleepDisplay() error {
+	err := exec.Command("xset", "dpms", "force", "off").Run()
+	if errors.Is(err, exec.ErrNotFound) {
+		return fmt.Errorf("putting the display to sleep needs xset (X11)")
+	}
+	if err != nil {
+		return fmt.Errorf("error putting the display to sleep: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+//go:build !darwin && !linux && !windows
+
+package idle
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// lockScreen always fails on platforms without screen locking
+func lockScreen() error {
+	return fmt.Errorf("locking the screen is not supported on %s", runtime.GOOS)
+}
+
+// sleepDisplay always fails on platforms without display sleep
+func sleepDisplay() error {
+	return fmt.Errorf("putting the display to sleep is not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,25 @@
+package idle
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// monitorOff broadcasts SC_MONITORPOWER with 2, turning the monitors off
+const monitorOff = `(Add-Type -Name Monitor -Namespace Pomodoro -PassThru -MemberDefinition '[DllImport("user32.dll")] public static extern int SendMessage(int hWnd, int msg, int wParam, int lParam);')::SendMessage(0xffff, 0x0112, 0xf170, 2)`
+
+// lockScreen locks the workstation, as Windows+L does
+func lockScreen() error {
+	if err := exec.Command("rundll32.exe", "user32.dll,LockWorkStation").Run(); err != nil {
+		return fmt.Errorf("error locking the screen: %v", err)
+	}
+	return nil
+}
+
+// sleepDisplay turns the monitors off until there is input
+func sleepDisplay() error {
+	if err := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", monitorOff).Run(); err != nil {
+		return fmt.Errorf("error putting the display to sleep: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+// Package location works out where the machine is from the Wi-Fi network
+// it is on, so sessions can record where they ran. Nothing leaves the
+// machine: the network name is read with the platform's own tools.
+package location
+
+import (
+	"bufio"
+	"strings"
+)
+
+// SSID returns the name of the Wi-Fi network the machine is connected to,
+// or "" when it is on none
+func SSID() (string, error) {
+	return platformSSID()
+}
+
+// Label returns where a session on the network ssid ran: the label networks
+// gives it, matched ignoring case, or else ssid itself when unlabelled is
+// set. It returns "" when there is no network or it is not to be recorded.
+func Label(ssid string, networks map[string]string, unlabelled bool) string {
+	if ssid == "" {
+		return ""
+	}
+	for network, label := range networks {
+		if strings.EqualFold(network, ssid) {
+			return strings.TrimSpace(label)
+		}
+	}
+	if unlabelled {
+		return ssid
+	}
+	return ""
+}
+
+// field returns the value of the first "key: value" or "key : value" line
+// in out whose key is key, ignoring case
+func field(out, key string) string {
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		k, v, ok := strings.Cut(scanner.Text(), ":")
+		if ok && strings.EqualFold(strings.TrimSpace(k), key) {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
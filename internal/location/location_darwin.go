@@ -0,0 +1,16 @@
+package location
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// platformSSID reads the Wi-Fi network from the summary ipconfig keeps for
+// the Wi-Fi interface
+func platformSSID() (string, error) {
+	out, err := exec.Command("ipconfig", "getsummary", "en0").Output()
+	if err != nil {
+		return "", fmt.Errorf("error reading Wi-Fi network: %v", err)
+	}
+	return field(string(out), "SSID"), nil
+}
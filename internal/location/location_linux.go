@@ -0,0 +1,43 @@
+package location
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// platformSSID asks NetworkManager for the active Wi-Fi network, falling
+// back to iwgetid on systems without it
+func platformSSID() (string, error) {
+	out, err := exec.Command("nmcli", "-t", "-f", "active,ssid", "device", "wifi").Output()
+	if err == nil {
+		return parseNmcli(string(out)), nil
+	}
+	if !errors.Is(err, exec.ErrNotFound) {
+		return "", fmt.Errorf("error reading Wi-Fi network: %v", err)
+	}
+
+	out, err = exec.Command("iwgetid", "--raw").Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return "", nil // Not connected to a wireless network
+	}
+	if err != nil {
+		return "", fmt.Errorf("error reading Wi-Fi network: %v", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// parseNmcli returns the network marked active in nmcli's terse listing,
+// where colons in names are escaped with a backslash
+func parseNmcli(out string) string {
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		if ssid, ok := strings.CutPrefix(scanner.Text(), "yes:"); ok {
+			return strings.ReplaceAll(ssid, `\:`, ":")
+		}
+	}
+	return ""
+}
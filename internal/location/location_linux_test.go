@@ -0,0 +1,13 @@
+package location
+
+import "testing"
+
+func TestParseNmcli(t *testing.T) {
+	out := "no:Neighbour\nyes:Office\\: 2nd floor\nno:Cafe\n"
+	if got := parseNmcli(out); got != "Office: 2nd floor" {
+		t.Errorf("Expected the active network, got %q", got)
+	}
+	if got := parseNmcli("no:Cafe\n"); got != "" {
+		t.Errorf("Expected no network when none is active, got %q", got)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !darwin && !linux && !windows
+
+package location
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// platformSSID always fails on platforms without Wi-Fi detection
+func platformSSID() (string, error) {
+	return "", fmt.Errorf("Wi-Fi network detection is not supported on %s", runtime.GOOS)
+}
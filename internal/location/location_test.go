@@ -0,0 +1,38 @@
+package location
+
+import "testing"
+
+func TestLabel(t *testing.T) {
+	networks := map[string]string{"CorpWiFi": "office", "Home 5G": "home"}
+	for _, tc := range []struct {
+		ssid       string
+		unlabelled bool
+		want       string
+	}{
+		{"corpwifi", false, "office"},
+		{"Home 5G", false, "home"},
+		{"Cafe", false, ""},
+		{"Cafe", true, "Cafe"},
+		{"", true, ""},
+	} {
+		if got := Label(tc.ssid, networks, tc.unlabelled); got != tc.want {
+			t.Errorf("Label(%q, unlabelled %v) = %q, want %q", tc.ssid, tc.unlabelled, got, tc.want)
+		}
+	}
+}
+
+func TestField(t *testing.T) {
+	// As netsh lists wireless interfaces on Windows
+	netsh := `
+    Name                   : Wi-Fi
+    State                  : connected
+    SSID                   : Office: 2nd floor
+    AP BSSID               : 12:34:56:78:9a:bc
+`
+	if got := field(netsh, "SSID"); got != "Office: 2nd floor" {
+		t.Errorf("Expected the SSID, got %q", got)
+	}
+	if got := field("State : disconnected\n", "SSID"); got != "" {
+		t.Errorf("Expected no SSID while disconnected, got %q", got)
+	}
+}
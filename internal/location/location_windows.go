@@ -0,0 +1,16 @@
+package location
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// platformSSID reads the Wi-Fi network from the wireless interfaces netsh
+// lists
+func platformSSID() (string, error) {
+	out, err := exec.Command("netsh", "wlan", "show", "interfaces").Output()
+	if err != nil {
+		return "", fmt.Errorf("error reading Wi-Fi network: %v", err)
+	}
+	return field(string(out), "SSID"), nil
+}
@@ -0,0 +1,113 @@
+// Package metrics exposes session metrics in the Prometheus text format
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/goals"
+)
+
+// ContentType is the Prometheus text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Snapshot holds the metrics at one moment
+type Snapshot struct {
+	Active         bool    // A session is running or paused
+	Paused         bool    // The active session is paused
+	Break          bool    // The active session is a break
+	RemainingSec   float64 // Time left in the active session, 0 when none
+	CompletedToday int     // Pomodoros completed today
+	DailyGoal      int     // Pomodoros aimed for each day, 0 for none
+	StreakDays     int     // Days in a row with a completed pomodoro
+	CompletedTotal int     // Pomodoros completed ever
+	FocusSecTotal  int64   // Time spent in pomodoros ever, excluding pauses
+}
+
+// Collect reads the metrics from database as of now
+func Collect(database db.DB, now time.Time, dailyGoal int) (*Snapshot, error) {
+	s := &Snapshot{DailyGoal: dailyGoal}
+
+	active, err := database.GetPausedSession()
+	if err != nil {
+		return nil, err
+	}
+	if active == nil {
+		if active, err = database.GetActiveSession(); err != nil {
+			return nil, err
+		}
+	}
+	if active != nil {
+		s.Active = true
+		s.Break = active.WasBreak
+		remaining := active.EndTime.Sub(now)
+		if active.IsPaused && active.PausedAt != nil {
+			s.Paused = true
+			remaining = active.EndTime.Sub(*active.PausedAt)
+		}
+		s.RemainingSec = max(0, remaining.Seconds())
+	}
+
+	today, err := database.GetSessionStats(now, now)
+	if err != nil {
+		return nil, err
+	}
+	s.CompletedToday = today.Completed
+
+	total, err := database.GetSessionStats(time.Time{}, now)
+	if err != nil {
+		return nil, err
+	}
+	s.CompletedTotal = total.Completed
+	s.FocusSecTotal = total.FocusSec
+
+	if s.StreakDays, err = goals.Streak(database, now); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// WriteTo writes the metrics in the Prometheus text format
+func (s *Snapshot) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	metric := func(name, kind, help string, value any) {
+		fmt.Fprintf(&buf, "# HELP %s %s\n# TYPE %s %s\n%s %v\n", name, help, name, kind, name, value)
+	}
+	metric("pomodoro_session_active", "gauge", "Whether a session is running or paused (1) or not (0).", boolValue(s.Active))
+	metric("pomodoro_session_paused", "gauge", "Whether the active session is paused.", boolValue(s.Paused))
+	metric("pomodoro_session_break", "gauge", "Whether the active session is a break.", boolValue(s.Break))
+	metric("pomodoro_session_remaining_seconds", "gauge", "Time left in the active session, 0 when none is active.", s.RemainingSec)
+	metric("pomodoro_completed_today", "gauge", "Pomodoros completed today.", s.CompletedToday)
+	metric("pomodoro_daily_goal", "gauge", "Pomodoros aimed for each day, 0 when no goal is set.", s.DailyGoal)
+	metric("pomodoro_streak_days", "gauge", "Days in a row with at least one completed pomodoro.", s.StreakDays)
+	metric("pomodoro_completed_total", "counter", "Pomodoros completed.", s.CompletedTotal)
+	metric("pomodoro_focus_seconds_total", "counter", "Time spent in pomodoros, excluding pauses.", s.FocusSecTotal)
+
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+func boolValue(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Handler serves the metrics of database, read afresh on every scrape.
+// dailyGoal is asked for on every scrape too, so a changed config shows.
+func Handler(database db.DB, dailyGoal func() int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		s, err := Collect(database, time.Now(), dailyGoal())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", ContentType)
+		_, _ = s.WriteTo(w)
+	})
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func TestHandler(t *testing.T) {
+	database, err := db.NewDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = database.Close() }()
+
+	now := time.Now()
+	for _, start := range []time.Time{now.Add(-26 * time.Hour), now.Add(-time.Hour)} {
+		id, err := database.CreateSession(start, start.Add(25*time.Minute), "Work", 25*60, "", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := database.SetSessionStatus(id, db.StatusCompleted); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := database.CreateSession(now.Add(-5*time.Minute), now.Add(20*time.Minute), "Running", 25*60, "", false); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	Handler(database, func() int { return 8 }).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("Content-Type = %q, want %q", ct, ContentType)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE pomodoro_session_active gauge\npomodoro_session_active 1\n",
+		"pomodoro_session_paused 0\n",
+		"pomodoro_daily_goal 8\n",
+		"# TYPE pomodoro_completed_total counter\npomodoro_completed_total 2\n",
+		"pomodoro_focus_seconds_total 3000\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected %q in the metrics, got:\n%s", want, body)
+		}
+	}
+	// The session an hour ago may fall on yesterday just after midnight
+	if !strings.Contains(body, "pomodoro_completed_today 1\n") && !strings.Contains(body, "pomodoro_completed_today 0\n") {
+		t.Errorf("Expected today's count in the metrics, got:\n%s", body)
+	}
+
+	var remaining float64
+	line := body[strings.Index(body, "\npomodoro_session_remaining_seconds ")+1:]
+	if _, err := fmt.Sscanf(line, "pomodoro_session_remaining_seconds %g", &remaining); err != nil || remaining < 19*60 || remaining > 20*60 {
+		t.Errorf("Expected about 20 minutes remaining, got %v (%v)", remaining, err)
+	}
+}
@@ -0,0 +1,113 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// breathingWidth is the width of the breathing bar at full lungs
+const breathingWidth = 30
+
+// breathingTick is how often the timer redraws while guiding breathing, so
+// the bar grows and shrinks smoothly
+const breathingTick = 200 * time.Millisecond
+
+// BreathPhase is one step of a breathing exercise. The bar moves from From
+// to To over the phase, where 0 is empty lungs and 1 is full.
+type BreathPhase struct {
+	Prompt string
+	Length time.Duration
+	From   float64
+	To     float64
+}
+
+// BreathingPattern is a breathing exercise, repeated for the whole break
+type BreathingPattern struct {
+	Name   string
+	Phases []BreathPhase
+}
+
+// breathingPatterns are the exercises break --breathe offers, by name
+var breathingPatterns = map[string]BreathingPattern{
+	"box": {Name: "box", Phases: []BreathPhase{
+		{Prompt: "Breathe in", Length: 4 * time.Second, From: 0, To: 1},
+		{Prompt: "Hold", Length: 4 * time.Second, From: 1, To: 1},
+		{Prompt: "Breathe out", Length: 4 * time.Second, From: 1, To: 0},
+		{Prompt: "Hold", Length: 4 * time.Second, From: 0, To: 0},
+	}},
+	"4-7-8": {Name: "4-7-8", Phases: []BreathPhase{
+		{Prompt: "Breathe in through your nose", Length: 4 * time.Second, From: 0, To: 1},
+		{Prompt: "Hold", Length: 7 * time.Second, From: 1, To: 1},
+		{Prompt: "Breathe out through your mouth", Length: 8 * time.Second, From: 1, To: 0},
+	}},
+}
+
+// ParseBreathingPattern returns the breathing exercise with a name, also
+// accepting 478 for 4-7-8
+func ParseBreathingPattern(name string) (BreathingPattern, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "478" {
+		name = "4-7-8"
+	}
+	if p, ok := breathingPatterns[name]; ok {
+		return p, nil
+	}
+
+	names := make([]string, 0, len(breathingPatterns))
+	for n := range breathingPatterns {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return BreathingPattern{}, fmt.Errorf("unknown breathing exercise %q (choose from %s)", name, strings.Join(names, ", "))
+}
+
+// cycle returns the length of one round of the exercise
+func (p BreathingPattern) cycle() time.Duration {
+	var total time.Duration
+	for _, phase := range p.Phases {
+		total += phase.Length
+	}
+	return total
+}
+
+// At returns the phase of the exercise after elapsed, how full the lungs
+// are from 0 to 1, and the time left in the phase
+func (p BreathingPattern) At(elapsed time.Duration) (BreathPhase, float64, time.Duration) {
+	cycle := p.cycle()
+	if cycle <= 0 {
+		return BreathPhase{}, 0, 0
+	}
+	into := max(0, elapsed) % cycle
+	for _, phase := range p.Phases {
+		if into < phase.Length {
+			done := float64(into) / float64(phase.Length)
+			return phase, phase.From + (phase.To-phase.From)*done, phase.Length - into
+		}
+		into -= phase.Length
+	}
+	last := p.Phases[len(p.Phases)-1]
+	return last, last.To, 0
+}
+
+// WithBreathing returns the model guiding a breathing exercise below the
+// progress bar, which pauses along with the session
+func (m PomodoroModel) WithBreathing(p BreathingPattern) PomodoroModel {
+	m.breathing = &p
+	m.tick = min(m.tick, breathingTick)
+	return m
+}
+
+// breathingView draws the breathing bar, growing from the middle as the
+// lungs fill, with the current prompt and a countdown
+func (m PomodoroModel) breathingView() string {
+	phase, level, left := m.breathing.At(m.Duration - m.remaining())
+
+	// Keep the filled part centered by growing it two cells at a time
+	filled := int(level*breathingWidth/2+0.5) * 2
+	side := (breathingWidth - filled) / 2
+	bar := strings.Repeat(" ", side) + strings.Repeat("█", filled) + strings.Repeat(" ", side)
+
+	return fmt.Sprintf("[%s]  %s %d", bar, phase.Prompt, int((left+time.Second-1)/time.Second))
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBreathingPattern(t *testing.T) {
+	box, err := ParseBreathingPattern("box")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		elapsed time.Duration
+		prompt  string
+		level   float64
+		left    time.Duration
+	}{
+		{0, "Breathe in", 0, 4 * time.Second},
+		{2 * time.Second, "Breathe in", 0.5, 2 * time.Second},
+		{5 * time.Second, "Hold", 1, 3 * time.Second},
+		{11 * time.Second, "Breathe out", 0.25, time.Second},
+		{17 * time.Second, "Breathe in", 0.25, 3 * time.Second}, // The next round
+	}
+	for _, tt := range tests {
+		phase, level, left := box.At(tt.elapsed)
+		if phase.Prompt != tt.prompt || level != tt.level || left != tt.left {
+			t.Errorf("At %s: expected %q at %.2f with %s left, got %q at %.2f with %s left",
+				tt.elapsed, tt.prompt, tt.level, tt.left, phase.Prompt, level, left)
+		}
+	}
+
+	if p, err := ParseBreathingPattern("478"); err != nil || p.Name != "4-7-8" {
+		t.Errorf("Expected 478 to name the 4-7-8 exercise, got %v, %v", p.Name, err)
+	}
+	if _, err := ParseBreathingPattern("yoga"); err == nil {
+		t.Error("Expected an unknown exercise to be refused")
+	}
+
+	// The guide follows the break's clock
+	clock := &stepClock{now: time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)}
+	m := NewPomodoroModel(1, "Break", clock.now, 5*time.Minute, true).
+		WithClock(clock, time.Second).
+		WithBreathing(box)
+	clock.now = clock.now.Add(6 * time.Second)
+	if view := m.View(); !strings.Contains(view, "Hold 2") {
+		t.Errorf("Expected the view to show the hold with 2 seconds left, got:\n%s", view)
+	}
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// Clock tells a timer model the time. Timers use the real clock unless
+// given another one, as demo and replay do to run faster than real time.
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock is the wall clock
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+// FastClock is a clock that starts at a chosen time and runs Speed times
+// faster than real time
+type FastClock struct {
+	start time.Time
+	began time.Time
+	speed float64
+}
+
+// NewFastClock returns a clock that reads start now and then advances speed
+// times faster than the wall clock
+func NewFastClock(start time.Time, speed float64) *FastClock {
+	return &FastClock{start: start, began: time.Now(), speed: speed}
+}
+
+// Now returns the clock's current time
+func (c *FastClock) Now() time.Time {
+	return c.start.Add(time.Duration(float64(time.Since(c.began)) * c.speed))
+}
@@ -0,0 +1,177 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+// adjustStep is how much the + and - keys add to or take off a session
+const adjustStep = 5 * time.Minute
+
+// SessionStore is the part of the database the timer keys change. The timer
+// also reads the session back on every tick, so changes made from other
+// commands show up while it runs.
+type SessionStore interface {
+	GetSessionByID(id int64) (*db.PomodoroSession, error)
+	PauseSession(id int64, pausedAt time.Time) error
+	ResumeSession(id int64, resumedAt time.Time) error
+	ExtendSession(id int64, by time.Duration) error
+	EndSession(id int64, endedAt time.Time, status string) error
+}
+
+// Action is a change made to the running session from the keyboard
+type Action string
+
+// Actions the timer keys perform
+const (
+	ActionPause   Action = "pause"
+	ActionResume  Action = "resume"
+	ActionExtend  Action = "extend"
+	ActionShorten Action = "shorten"
+	ActionSkip    Action = "skip"
+	ActionCancel  Action = "cancel"
+)
+
+// controlsHelp lists the timer keys below the progress bar
+const controlsHelp = "p pause · r resume · +/- 5m · s finish now · c cancel"
+
+// WithControls returns the model with keys to pause, resume, extend,
+// shorten, finish, and cancel the session, each saved to store as it
+// happens. onAction, if set, is called after each change is saved.
+func (m PomodoroModel) WithControls(store SessionStore, onAction func(Action)) PomodoroModel {
+	m.store = store
+	m.onAction = onAction
+	return m
+}
+
+// Cancelled reports whether the session was cancelled from the keyboard
+func (m PomodoroModel) Cancelled() bool {
+	return m.cancelled
+}
+
+// Paused reports whether the session is paused
+func (m PomodoroModel) Paused() bool {
+	return m.paused
+}
+
+// handleControlKey performs the action bound to a key, if there is one and
+// the model has a store to save it to
+func (m PomodoroModel) handleControlKey(key string) (PomodoroModel, tea.Cmd, bool) {
+	if m.store == nil {
+		return m, nil, false
+	}
+
+	now := m.clock.Now()
+	var action Action
+	var err error
+
+	switch key {
+	case "p", " ":
+		if m.paused {
+			return m, nil, true
+		}
+		action = ActionPause
+		if err = m.store.PauseSession(m.ID, now); err == nil {
+			m.paused, m.pausedAt = true, now
+		}
+
+	case "r":
+		if !m.paused {
+			return m, nil, true
+		}
+		action = ActionResume
+		if err = m.store.ResumeSession(m.ID, now); err == nil {
+			// The database shifts the end by whole seconds of pause
+			m.EndTime = m.EndTime.Add(now.Sub(m.pausedAt).Truncate(time.Second))
+			m.paused = false
+		}
+
+	case "+", "=":
+		if m.paused {
+			m.setNotice("Resume the session before changing its length")
+			return m, nil, true
+		}
+		action = ActionExtend
+		if err = m.store.ExtendSession(m.ID, adjustStep); err == nil {
+			m.EndTime = m.EndTime.Add(adjustStep)
+			m.Duration += adjustStep
+		}
+
+	case "-", "_":
+		if m.paused {
+			m.setNotice("Resume the session before changing its length")
+			return m, nil, true
+		}
+		if m.EndTime.Add(-adjustStep).Before(now) {
+			m.setNotice(fmt.Sprintf("Less than %s left; press s to finish now", adjustStep))
+			return m, nil, true
+		}
+		action = ActionShorten
+		if err = m.store.ExtendSession(m.ID, -adjustStep); err == nil {
+			m.EndTime = m.EndTime.Add(-adjustStep)
+			m.Duration -= adjustStep
+		}
+
+	case "s", "c":
+		action, status := ActionSkip, db.StatusCompleted
+		if key == "c" {
+			action, status = ActionCancel, db.StatusCancelled
+		}
+		if err = m.store.EndSession(m.ID, now, status); err == nil {
+			m.quitting = true
+			m.cancelled = action == ActionCancel
+			if m.onAction != nil {
+				m.onAction(action)
+			}
+			return m, tea.Quit, true
+		}
+
+	default:
+		return m, nil, false
+	}
+
+	if err != nil {
+		m.setNotice(fmt.Sprintf("Could not %s: %v", action, err))
+		return m, nil, true
+	}
+	if m.onAction != nil {
+		m.onAction(action)
+	}
+	return m, m.updateProgress(), true
+}
+
+// sync picks up changes made to the session outside the timer, such as
+// 'pomodoro extend' or 'pomodoro pause'. A session whose end was moved to
+// before the old one and has passed was ended elsewhere, and counts as
+// cancelled.
+func (m *PomodoroModel) sync() {
+	if m.store == nil {
+		return
+	}
+	s, err := m.store.GetSessionByID(m.ID)
+	if err != nil || s == nil {
+		return
+	}
+
+	if s.EndTime.Before(m.EndTime) && !s.IsPaused && !m.clock.Now().Before(s.EndTime) {
+		m.cancelled = true
+	}
+	m.EndTime = s.EndTime
+	if s.DurationSec > 0 {
+		m.Duration = time.Duration(s.DurationSec) * time.Second
+	}
+	m.paused = s.IsPaused && s.PausedAt != nil
+	if m.paused {
+		m.pausedAt = *s.PausedAt
+	}
+}
+
+// setNotice shows a message below the progress bar for a few seconds
+func (m *PomodoroModel) setNotice(notice string) {
+	m.notice = notice
+	m.noticeUntil = time.Now().Add(noticeDuration)
+}
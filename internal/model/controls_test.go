@@ -0,0 +1,147 @@
+package model
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+// stepClock is a clock the test moves by hand
+type stepClock struct{ now time.Time }
+
+func (c *stepClock) Now() time.Time { return c.now }
+
+func TestTimerControls(t *testing.T) {
+	database, err := db.NewDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = database.Close() }()
+
+	clock := &stepClock{now: time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)}
+	id, err := database.CreateSession(clock.now, clock.now.Add(25*time.Minute), "Work", 25*60, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var actions []Action
+	m := NewPomodoroModel(id, "Work", clock.now, 25*time.Minute, false).
+		WithClock(clock, time.Second).
+		WithControls(database, func(a Action) { actions = append(actions, a) })
+	press := func(key string) {
+		t.Helper()
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = next.(PomodoroModel)
+	}
+	tick := func() {
+		t.Helper()
+		next, _ := m.Update(TickMsg(clock.now))
+		m = next.(PomodoroModel)
+	}
+	session := func() *db.PomodoroSession {
+		t.Helper()
+		s, err := database.GetSessionByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	clock.now = clock.now.Add(10 * time.Minute)
+	press("p")
+	if !m.Paused() || !session().IsPaused {
+		t.Fatal("Expected p to pause the session")
+	}
+	press("+")
+	if !session().EndTime.Equal(m.EndTime) || m.EndTime.Sub(m.StartTime) != 25*time.Minute {
+		t.Error("Expected + to be refused while paused")
+	}
+
+	clock.now = clock.now.Add(3 * time.Minute)
+	press("r")
+	if m.Paused() || session().IsPaused {
+		t.Fatal("Expected r to resume the session")
+	}
+	if !session().EndTime.Equal(m.EndTime) || m.remaining() != 15*time.Minute {
+		t.Errorf("Expected 15m left after resuming, got %s (ends %s in the database)", m.remaining(), session().EndTime)
+	}
+
+	press("+")
+	press("+")
+	press("-")
+	if !session().EndTime.Equal(m.EndTime) || m.remaining() != 20*time.Minute {
+		t.Errorf("Expected 20m left after +10m and -5m, got %s", m.remaining())
+	}
+
+	if session().DurationSec != 30*60 {
+		t.Errorf("Expected the planned length to follow the keys, got %ds", session().DurationSec)
+	}
+
+	// Changes made by other commands show up on the next tick
+	if err := database.ExtendSession(id, 10*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	tick()
+	if m.remaining() != 30*time.Minute || m.Duration != 40*time.Minute {
+		t.Errorf("Expected the extension to reach the timer, got %s left of %s", m.remaining(), m.Duration)
+	}
+	if err := database.ExtendSession(id, -10*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	tick()
+
+	clock.now = clock.now.Add(17 * time.Minute)
+	press("-")
+	if m.remaining() != 3*time.Minute {
+		t.Errorf("Expected - to be refused with 3m left, got %s left", m.remaining())
+	}
+
+	press("c")
+	if !m.Cancelled() {
+		t.Error("Expected c to cancel the session")
+	}
+	if focus := session().EffectiveFocus(clock.now.Add(time.Hour)); focus != 27*time.Minute {
+		t.Errorf("Expected 27m of focus, got %s", focus)
+	}
+
+	want := []Action{ActionPause, ActionResume, ActionExtend, ActionExtend, ActionShorten, ActionCancel}
+	if len(actions) != len(want) {
+		t.Fatalf("Expected actions %v, got %v", want, actions)
+	}
+	for i := range want {
+		if actions[i] != want[i] {
+			t.Errorf("Expected actions %v, got %v", want, actions)
+			break
+		}
+	}
+}
+
+func TestTimerSeesSessionEndedElsewhere(t *testing.T) {
+	database, err := db.NewDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = database.Close() }()
+
+	clock := &stepClock{now: time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)}
+	id, err := database.CreateSession(clock.now, clock.now.Add(25*time.Minute), "Work", 25*60, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewPomodoroModel(id, "Work", clock.now, 25*time.Minute, false).
+		WithClock(clock, time.Second).
+		WithControls(database, nil)
+
+	clock.now = clock.now.Add(5 * time.Minute)
+	if err := database.EndSession(id, clock.now, db.StatusCancelled); err != nil {
+		t.Fatal(err)
+	}
+	next, _ := m.Update(TickMsg(clock.now))
+	if m = next.(PomodoroModel); !m.Cancelled() {
+		t.Error("Expected a session cancelled by another command to stop the timer as cancelled")
+	}
+}
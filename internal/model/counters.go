@@ -0,0 +1,51 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxCounterKeys is how many counters get a key, 1 through 9
+const maxCounterKeys = 9
+
+// CounterLogger adds one to a wellness counter and returns its total today
+type CounterLogger func(name string) (int, error)
+
+// WithCounters returns the model with keys 1-9 logging the named wellness
+// counters, such as glasses of water, in order. Counters past the ninth
+// have no key.
+func (m PomodoroModel) WithCounters(names []string, log CounterLogger) PomodoroModel {
+	if len(names) > maxCounterKeys {
+		names = names[:maxCounterKeys]
+	}
+	m.counters = names
+	m.logCounter = log
+	return m
+}
+
+// handleCounterKey logs the counter bound to a digit key, if there is one
+func (m PomodoroModel) handleCounterKey(key string) (PomodoroModel, bool) {
+	n, err := strconv.Atoi(key)
+	if err != nil || n < 1 || n > len(m.counters) || m.logCounter == nil {
+		return m, false
+	}
+
+	name := m.counters[n-1]
+	total, err := m.logCounter(name)
+	if err != nil {
+		m.setNotice(fmt.Sprintf("Could not log %s: %v", name, err))
+	} else {
+		m.setNotice(fmt.Sprintf("+1 %s (%d today)", name, total))
+	}
+	return m, true
+}
+
+// countersHelp lists the counter keys below the progress bar
+func (m PomodoroModel) countersHelp() string {
+	keys := make([]string, len(m.counters))
+	for i, name := range m.counters {
+		keys[i] = fmt.Sprintf("%d %s", i+1, name)
+	}
+	return strings.Join(keys, " · ")
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCounterKeys(t *testing.T) {
+	logged := map[string]int{}
+	m := NewPomodoroModel(1, "Break Time", time.Now(), 5*time.Minute, true).
+		WithCounters([]string{"water", "stretch"}, func(name string) (int, error) {
+			logged[name]++
+			return logged[name], nil
+		})
+	press := func(key string) {
+		t.Helper()
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = next.(PomodoroModel)
+	}
+
+	if view := m.View(); !strings.Contains(view, "1 water · 2 stretch") {
+		t.Errorf("Expected the counter keys in the help line, got %q", view)
+	}
+
+	press("1")
+	press("1")
+	press("2")
+	press("3") // No third counter
+	if logged["water"] != 2 || logged["stretch"] != 1 || len(logged) != 2 {
+		t.Errorf("logged = %v, want water 2 and stretch 1", logged)
+	}
+	if view := m.View(); !strings.Contains(view, "+1 stretch (1 today)") {
+		t.Errorf("Expected a notice for the last count, got %q", view)
+	}
+}
@@ -0,0 +1,167 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/term"
+)
+
+// dashboardRefresh is how often the full view reloads what it shows below
+// the progress bar
+const dashboardRefresh = 15 * time.Second
+
+// Rows the full view lists at most of today's sessions and of the queue
+const (
+	dashboardSessions = 8
+	dashboardQueue    = 5
+)
+
+// dashboardBarWidth is the width of the goal bars in the full view
+const dashboardBarWidth = 12
+
+// Dashboard is what the full view shows below the progress bar
+type Dashboard struct {
+	Sessions []db.PomodoroSession // Today's sessions, newest first
+	Goals    []DashboardGoal
+	Queue    []db.Task // Open tasks, next first
+}
+
+// DashboardGoal is progress toward one goal
+type DashboardGoal struct {
+	Label string
+	Done  int
+	Goal  int
+}
+
+// DashboardLoader reads what the full view shows; it is called away from
+// the UI, so a slow read does not hold up the timer
+type DashboardLoader func() (Dashboard, error)
+
+// dashboardMsg carries what a DashboardLoader read
+type dashboardMsg struct {
+	dashboard Dashboard
+	err       error
+}
+
+// WithDashboard returns the model with the full view: below the progress
+// bar, today's finished sessions, goal progress, and the task queue, read
+// with load at the start and every dashboardRefresh after
+func (m PomodoroModel) WithDashboard(load DashboardLoader) PomodoroModel {
+	m.loadDashboard = load
+	return m
+}
+
+// refreshDashboard returns a command that reads the dashboard after wait
+func (m PomodoroModel) refreshDashboard(wait time.Duration) tea.Cmd {
+	if m.loadDashboard == nil {
+		return nil
+	}
+	load := func() tea.Msg {
+		dashboard, err := m.loadDashboard()
+		return dashboardMsg{dashboard: dashboard, err: err}
+	}
+	if wait <= 0 {
+		return load
+	}
+	return tea.Tick(wait, func(time.Time) tea.Msg { return load() })
+}
+
+// handleDashboard keeps what was read, or the previous dashboard with a
+// notice when reading failed, and schedules the next read
+func (m PomodoroModel) handleDashboard(msg dashboardMsg) (PomodoroModel, tea.Cmd) {
+	if msg.err != nil {
+		m.setNotice(fmt.Sprintf("Could not refresh: %v", msg.err))
+	} else {
+		m.dashboard = &msg.dashboard
+	}
+	return m, m.refreshDashboard(dashboardRefresh)
+}
+
+// dashboardView renders the full view's panels, indented by pad
+func (m PomodoroModel) dashboardView(pad string) string {
+	if m.dashboard == nil {
+		return pad + "Loading today…\n"
+	}
+	var b strings.Builder
+
+	// The running session is the one above; the rest are done
+	var done []db.PomodoroSession
+	for _, s := range m.dashboard.Sessions {
+		if s.ID != m.ID && !s.IsPaused && !s.EndTime.After(m.clock.Now()) {
+			done = append(done, s)
+		}
+	}
+	b.WriteString(pad + "Today\n")
+	if len(done) == 0 {
+		b.WriteString(pad + "  Nothing finished yet\n")
+	}
+	for i, s := range done {
+		if i == dashboardSessions {
+			fmt.Fprintf(&b, "%s  … and %d earlier\n", pad, len(done)-i)
+			break
+		}
+		fmt.Fprintf(&b, "%s  %s  %s %3dm  %s\n", pad, s.StartTime.In(time.Local).Format("15:04"), sessionMark(s),
+			int(s.EffectiveFocus(s.EndTime).Round(time.Minute).Minutes()), sessionLabel(s))
+	}
+
+	if len(m.dashboard.Goals) > 0 {
+		b.WriteString("\n" + pad + "Goals\n")
+		for _, g := range m.dashboard.Goals {
+			fmt.Fprintf(&b, "%s  %-11s %3d/%-3d %s\n", pad, g.Label, g.Done, g.Goal, dashboardBar(g.Done, g.Goal))
+		}
+	}
+
+	if len(m.dashboard.Queue) > 0 {
+		b.WriteString("\n" + pad + "Up next\n")
+		for i, t := range m.dashboard.Queue {
+			if i == dashboardQueue {
+				fmt.Fprintf(&b, "%s  … and %d more\n", pad, len(m.dashboard.Queue)-i)
+				break
+			}
+			progress := fmt.Sprintf("%d", t.Pomodoros)
+			if t.Estimate > 0 {
+				progress += fmt.Sprintf("/%d", t.Estimate)
+			}
+			fmt.Fprintf(&b, "%s  %3d  %s (%s)\n", pad, t.ID, t.Title, progress)
+		}
+	}
+	return b.String()
+}
+
+// sessionMark shows how a session in today's list ended
+func sessionMark(s db.PomodoroSession) string {
+	switch {
+	case s.WasBreak:
+		return term.Emoji("☕", "b")
+	case s.Incomplete():
+		return term.Emoji("✗", "x")
+	default:
+		return term.Emoji("✓", "+")
+	}
+}
+
+// sessionLabel names a session in today's list
+func sessionLabel(s db.PomodoroSession) string {
+	switch {
+	case s.Description != "":
+		return s.Description
+	case s.WasBreak:
+		return "Break"
+	default:
+		return "Pomodoro"
+	}
+}
+
+// dashboardBar draws progress toward a goal
+func dashboardBar(done, goal int) string {
+	if goal <= 0 {
+		return ""
+	}
+	filled := min(dashboardBarWidth, done*dashboardBarWidth/goal)
+	return strings.Repeat("█", filled) + strings.Repeat("░", dashboardBarWidth-filled)
+}
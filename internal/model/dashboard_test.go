@@ -0,0 +1,70 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func TestDashboard(t *testing.T) {
+	clock := &stepClock{now: time.Date(2024, 6, 3, 11, 0, 0, 0, time.Local)}
+	at := func(h, m int) time.Time { return time.Date(2024, 6, 3, h, m, 0, 0, time.Local) }
+	loads := 0
+	fail := false
+	m := NewPomodoroModel(3, "Work", clock.now, 25*time.Minute, false).
+		WithClock(clock, time.Second).
+		WithDashboard(func() (Dashboard, error) {
+			loads++
+			if fail {
+				return Dashboard{}, errors.New("database is locked")
+			}
+			return Dashboard{
+				Sessions: []db.PomodoroSession{
+					{ID: 3, StartTime: clock.now, EndTime: clock.now.Add(25 * time.Minute), Description: "Work"},
+					{ID: 2, StartTime: at(9, 30), EndTime: at(9, 40), Description: "Inbox", Status: db.StatusCancelled},
+					{ID: 1, StartTime: at(9, 0), EndTime: at(9, 25), Description: "Write report", Status: db.StatusCompleted},
+				},
+				Goals: []DashboardGoal{{Label: "Today", Done: 1, Goal: 4}},
+				Queue: []db.Task{{ID: 7, Title: "Review PR", Estimate: 2, Pomodoros: 1}},
+			}, nil
+		})
+
+	if view := m.View(); !strings.Contains(view, "Loading today") {
+		t.Errorf("Expected a loading line before the first read, got:\n%s", view)
+	}
+
+	// Init reads at once, alongside the first tick
+	load := func() {
+		t.Helper()
+		msg := m.refreshDashboard(0)()
+		next, cmd := m.Update(msg)
+		m = next.(PomodoroModel)
+		if cmd == nil {
+			t.Error("Expected the next read to be scheduled")
+		}
+	}
+	load()
+
+	view := m.View()
+	for _, want := range []string{"09:00", "25m  Write report", "10m  Inbox", "Today         1/4", "7  Review PR (1/2)"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the view, got:\n%s", want, view)
+		}
+	}
+	if strings.Count(view, "Work") != 1 {
+		t.Errorf("Expected the running session only above the panels, got:\n%s", view)
+	}
+
+	// A failed read keeps what was shown
+	fail = true
+	load()
+	if view := m.View(); !strings.Contains(view, "Could not refresh: database is locked") || !strings.Contains(view, "Review PR") {
+		t.Errorf("Expected a notice over the previous panels, got:\n%s", view)
+	}
+	if loads != 2 {
+		t.Errorf("Expected 2 reads, got %d", loads)
+	}
+}
@@ -0,0 +1,145 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// goalFormHelp lists the form keys below the fields
+const goalFormHelp = "tab/↑/↓ move · enter save · esc cancel"
+
+// goalFormLimit is the most digits a target takes
+const goalFormLimit = 6
+
+// GoalField is one target the goal form edits
+type GoalField struct {
+	Label string
+	Value int
+}
+
+// GoalSaver saves the targets entered, in the order of the fields
+type GoalSaver func(values []int) error
+
+// GoalFormModel edits goal targets, each a whole number with 0 for no goal,
+// and saves them all on enter. A target that is not a whole number, or a
+// failed save, keeps the form open with the error shown.
+type GoalFormModel struct {
+	fields []GoalField
+	inputs []textinput.Model
+	focus  int
+	save   GoalSaver
+	err    string
+	saved  bool
+	done   bool
+}
+
+// NewGoalFormModel creates a form editing fields, saved with save
+func NewGoalFormModel(fields []GoalField, save GoalSaver) GoalFormModel {
+	m := GoalFormModel{fields: fields, save: save}
+	for i, f := range fields {
+		input := textinput.New()
+		input.Prompt = ""
+		input.CharLimit = goalFormLimit
+		input.Width = goalFormLimit
+		input.SetValue(strconv.Itoa(f.Value))
+		if i == 0 {
+			input.Focus()
+		}
+		m.inputs = append(m.inputs, input)
+	}
+	return m
+}
+
+// Saved reports whether the targets were saved before the form closed
+func (m GoalFormModel) Saved() bool {
+	return m.saved
+}
+
+// Init initializes the model
+func (m GoalFormModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update handles messages and updates the model
+func (m GoalFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			m.done = true
+			return m, tea.Quit
+		case tea.KeyEnter:
+			return m.submit()
+		case tea.KeyTab, tea.KeyDown:
+			return m, m.moveFocus(m.focus + 1)
+		case tea.KeyShiftTab, tea.KeyUp:
+			return m, m.moveFocus(m.focus - 1)
+		}
+	}
+	if len(m.inputs) == 0 {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.inputs[m.focus], cmd = m.inputs[m.focus].Update(msg)
+	return m, cmd
+}
+
+// moveFocus focuses field i, wrapping around at either end
+func (m *GoalFormModel) moveFocus(i int) tea.Cmd {
+	if len(m.inputs) == 0 {
+		return nil
+	}
+	m.inputs[m.focus].Blur()
+	m.focus = (i + len(m.inputs)) % len(m.inputs)
+	return m.inputs[m.focus].Focus()
+}
+
+// submit checks every target and saves them, or shows what is wrong
+func (m GoalFormModel) submit() (tea.Model, tea.Cmd) {
+	values := make([]int, len(m.inputs))
+	for i, input := range m.inputs {
+		v, err := strconv.Atoi(strings.TrimSpace(input.Value()))
+		if err != nil || v < 0 {
+			m.err = fmt.Sprintf("%s must be a whole number, 0 for no goal", m.fields[i].Label)
+			return m, m.moveFocus(i)
+		}
+		values[i] = v
+	}
+	if err := m.save(values); err != nil {
+		m.err = fmt.Sprintf("Could not save: %v", err)
+		return m, nil
+	}
+	m.saved = true
+	m.done = true
+	return m, tea.Quit
+}
+
+// View renders the model
+func (m GoalFormModel) View() string {
+	if m.done {
+		return ""
+	}
+
+	pad := strings.Repeat(" ", padding)
+	width := 0
+	for _, f := range m.fields {
+		width = max(width, len(f.Label))
+	}
+	var b strings.Builder
+	b.WriteString("\n" + pad + "Goals\n\n")
+	for i, f := range m.fields {
+		marker := "  "
+		if i == m.focus {
+			marker = "› "
+		}
+		fmt.Fprintf(&b, "%s%s%-*s  %s\n", pad, marker, width, f.Label, m.inputs[i].View())
+	}
+	if m.err != "" {
+		b.WriteString("\n" + pad + m.err + "\n")
+	}
+	b.WriteString("\n" + pad + goalFormHelp + "\n")
+	return b.String()
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestGoalForm(t *testing.T) {
+	var saved []int
+	fail := false
+	m := NewGoalFormModel([]GoalField{{Label: "Pomodoros a day", Value: 8}, {Label: "Pomodoros a week", Value: 40}}, func(values []int) error {
+		if fail {
+			return errors.New("read-only file system")
+		}
+		saved = values
+		return nil
+	})
+	send := func(msg tea.KeyMsg) {
+		t.Helper()
+		next, _ := m.Update(msg)
+		m = next.(GoalFormModel)
+	}
+	typeText := func(s string) {
+		t.Helper()
+		send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)})
+	}
+
+	// Replace the weekly target with something that is not a number
+	send(tea.KeyMsg{Type: tea.KeyTab})
+	send(tea.KeyMsg{Type: tea.KeyCtrlU})
+	typeText("4x")
+	send(tea.KeyMsg{Type: tea.KeyEnter})
+	if saved != nil || !strings.Contains(m.View(), "Pomodoros a week must be a whole number") {
+		t.Errorf("Expected the form to stay open with an error, got:\n%s", m.View())
+	}
+
+	send(tea.KeyMsg{Type: tea.KeyBackspace})
+	typeText("5")
+	fail = true
+	send(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.Saved() || !strings.Contains(m.View(), "Could not save: read-only file system") {
+		t.Errorf("Expected the save error shown, got:\n%s", m.View())
+	}
+
+	fail = false
+	send(tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.Saved() || !slices.Equal(saved, []int{8, 45}) {
+		t.Errorf("Expected 8 and 45 saved, got %v (saved %v)", saved, m.Saved())
+	}
+}
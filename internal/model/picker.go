@@ -0,0 +1,170 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pickerHeight is the most choices a picker shows at once
+const pickerHeight = 10
+
+// pickerHelp lists the picker keys below the choices
+const pickerHelp = "type to search · ↑/↓ move · enter choose · esc cancel"
+
+// PickerItem is one choice offered by a picker
+type PickerItem struct {
+	Name   string // What the search matches against
+	Detail string // Shown after the name
+}
+
+// PickerModel lets the user choose from a list, narrowing it with a fuzzy
+// search as they type. Items keep the order they are given in until the
+// search ranks them.
+type PickerModel struct {
+	title   string
+	items   []PickerItem
+	query   []rune
+	matches []int // Indexes into items, best match first
+	cursor  int
+	chosen  int
+	done    bool
+}
+
+// NewPickerModel creates a picker titled title offering items
+func NewPickerModel(title string, items []PickerItem) PickerModel {
+	m := PickerModel{title: title, items: items, chosen: -1}
+	m.filter()
+	return m
+}
+
+// Chosen returns the index of the item chosen, or false when the picker was
+// left without choosing one
+func (m PickerModel) Chosen() (int, bool) {
+	return m.chosen, m.chosen >= 0
+}
+
+// Init initializes the model
+func (m PickerModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages and updates the model
+func (m PickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch key.Type {
+	case tea.KeyCtrlC, tea.KeyEsc:
+		m.done = true
+		return m, tea.Quit
+	case tea.KeyEnter:
+		if len(m.matches) == 0 {
+			return m, nil
+		}
+		m.chosen = m.matches[m.cursor]
+		m.done = true
+		return m, tea.Quit
+	case tea.KeyUp, tea.KeyCtrlP:
+		m.cursor = max(0, m.cursor-1)
+	case tea.KeyDown, tea.KeyCtrlN:
+		m.cursor = max(0, min(len(m.matches)-1, m.cursor+1))
+	case tea.KeyBackspace:
+		if len(m.query) > 0 {
+			m.query = m.query[:len(m.query)-1]
+			m.filter()
+		}
+	case tea.KeyCtrlU:
+		m.query = nil
+		m.filter()
+	case tea.KeyRunes, tea.KeySpace:
+		m.query = append(m.query, key.Runes...)
+		m.filter()
+	}
+	return m, nil
+}
+
+// filter ranks the items against the query and moves the cursor to the best
+// match
+func (m *PickerModel) filter() {
+	type match struct{ index, score int }
+	var found []match
+	for i, item := range m.items {
+		if score, ok := FuzzyScore(string(m.query), item.Name); ok {
+			found = append(found, match{i, score})
+		}
+	}
+	slices.SortStableFunc(found, func(a, b match) int { return b.score - a.score })
+
+	m.matches = m.matches[:0]
+	for _, f := range found {
+		m.matches = append(m.matches, f.index)
+	}
+	m.cursor = 0
+}
+
+// FuzzyScore reports whether every character of query appears in text in
+// order, ignoring case, and scores the match: characters next to each other
+// or starting a word score more, so "cr" ranks "Code review" above
+// "Refactor". An empty query matches everything equally.
+func FuzzyScore(query, text string) (int, bool) {
+	q := []rune(strings.ToLower(query))
+	if len(q) == 0 {
+		return 0, true
+	}
+
+	score, next, last := 0, 0, -2
+	prev := ' '
+	for i, r := range []rune(text) {
+		lower := unicode.ToLower(r)
+		if next < len(q) && lower == q[next] {
+			score++
+			if last == i-1 {
+				score += 2
+			}
+			if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+				score += 3
+			}
+			last = i
+			next++
+		}
+		prev = r
+	}
+	return score, next == len(q)
+}
+
+// View renders the model
+func (m PickerModel) View() string {
+	if m.done {
+		return ""
+	}
+
+	pad := strings.Repeat(" ", padding)
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n%s%s\n%s> %s\n\n", pad, m.title, pad, string(m.query))
+
+	if len(m.matches) == 0 {
+		b.WriteString(pad + "  No matches\n")
+	}
+	// Scroll so the cursor stays in view
+	first := max(0, m.cursor-pickerHeight+1)
+	for i := first; i < len(m.matches) && i < first+pickerHeight; i++ {
+		item := m.items[m.matches[i]]
+		marker := "  "
+		if i == m.cursor {
+			marker = "› "
+		}
+		fmt.Fprintf(&b, "%s%s%s", pad, marker, item.Name)
+		if item.Detail != "" {
+			b.WriteString("  " + item.Detail)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n" + pad + pickerHelp + "\n")
+	return b.String()
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	for _, tc := range []struct {
+		query, text string
+		ok          bool
+	}{
+		{"", "anything", true},
+		{"cr", "Code review", true},
+		{"CR", "code review", true},
+		{"rvw", "Code review", true},
+		{"wr", "Code review", false}, // Out of order
+		{"deep", "Deep work", true},
+		{"deeper", "Deep work", false},
+	} {
+		if _, ok := FuzzyScore(tc.query, tc.text); ok != tc.ok {
+			t.Errorf("FuzzyScore(%q, %q) matched = %v, want %v", tc.query, tc.text, ok, tc.ok)
+		}
+	}
+
+	review, _ := FuzzyScore("cr", "Code review")
+	refactor, _ := FuzzyScore("cr", "Refactor")
+	if review <= refactor {
+		t.Errorf("Expected word starts to score higher: %d for Code review, %d for Refactor", review, refactor)
+	}
+}
+
+func TestPicker(t *testing.T) {
+	items := []PickerItem{{Name: "refactor"}, {Name: "code-review"}, {Name: "deep"}}
+	m := NewPickerModel("Start a template", items)
+	send := func(msg tea.KeyMsg) {
+		t.Helper()
+		next, _ := m.Update(msg)
+		m = next.(PickerModel)
+	}
+	typed := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	if len(m.matches) != 3 || m.matches[0] != 0 {
+		t.Fatalf("Expected every item in the given order, got %v", m.matches)
+	}
+
+	send(typed("c"))
+	send(typed("r"))
+	if len(m.matches) != 2 || m.matches[0] != 1 {
+		t.Fatalf("Expected code-review ranked first for cr, got %v", m.matches)
+	}
+	send(tea.KeyMsg{Type: tea.KeyDown})
+	send(tea.KeyMsg{Type: tea.KeyDown}) // Stays on the last match
+	send(tea.KeyMsg{Type: tea.KeyEnter})
+	if chosen, ok := m.Chosen(); !ok || chosen != 0 {
+		t.Errorf("Chosen() = %d, %v, want refactor", chosen, ok)
+	}
+
+	m = NewPickerModel("Start a template", items)
+	send(typed("zz"))
+	send(tea.KeyMsg{Type: tea.KeyEnter}) // Nothing to choose
+	send(tea.KeyMsg{Type: tea.KeyBackspace})
+	send(tea.KeyMsg{Type: tea.KeyBackspace})
+	if len(m.matches) != 3 {
+		t.Errorf("Expected every item back once the query is cleared, got %v", m.matches)
+	}
+	send(tea.KeyMsg{Type: tea.KeyEsc})
+	if _, ok := m.Chosen(); ok {
+		t.Error("Expected Esc to leave without a choice")
+	}
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/charmbracelet/bubbles/progress"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/term"
 	"github.com/ethan-k/pomodoro-cli/internal/utils"
 )
 
@@ -16,9 +18,21 @@ const (
 	maxWidth = 80
 )
 
+// noticeDuration is how long a notice stays below the progress bar
+const noticeDuration = 3 * time.Second
+
 // TickMsg is sent when the timer ticks
 type TickMsg time.Time
 
+// ConfigReloadedMsg is sent after the config file changed, with the new
+// config, or Err when the new file could not be used. Its display settings
+// are applied as the message is handled, so they never change while a view
+// is being rendered.
+type ConfigReloadedMsg struct {
+	Config *config.Config
+	Err    error
+}
+
 // PomodoroModel represents a Pomodoro timer model for bubbletea
 type PomodoroModel struct {
 	ID          int64
@@ -29,28 +43,41 @@ type PomodoroModel struct {
 	IsBreak     bool
 	progress    progress.Model
 	quitting    bool
+	interrupted bool
+	notice      string
+	noticeUntil time.Time
+	clock       Clock
+	tick        time.Duration
+
+	// Keyboard controls, set with WithControls
+	store     SessionStore
+	onAction  func(Action)
+	paused    bool
+	pausedAt  time.Time
+	cancelled bool
+
+	// Breathing exercise guided during a break, set with WithBreathing
+	breathing *BreathingPattern
+
+	// Wellness counters logged from the keyboard, set with WithCounters
+	counters   []string
+	logCounter CounterLogger
+
+	// Today's sessions, goals, and queue below the bar, set with WithDashboard
+	loadDashboard DashboardLoader
+	dashboard     *Dashboard
+
+	// Counting down a warm-up rather than a session, set with WithWarmup
+	warmup bool
+
+	// What to do when the machine sleeps, set with WithSleep
+	onSleep   SleepAction
+	lastTick  time.Time
+	abandoned bool
 }
 
 // NewPomodoroModel creates a new Pomodoro timer model
 func NewPomodoroModel(id int64, description string, startTime time.Time, duration time.Duration, isBreak bool) PomodoroModel {
-	var p progress.Model
-
-	if isBreak {
-		// Green colors for break
-		p = progress.New(
-			progress.WithGradient("#5A8A20", "#98D44A"),
-			progress.WithWidth(40),
-			progress.WithoutPercentage(),
-		)
-	} else {
-		// Default gradient for pomodoro (usually pinkish)
-		p = progress.New(
-			progress.WithDefaultGradient(),
-			progress.WithWidth(40),
-			progress.WithoutPercentage(),
-		)
-	}
-
 	return PomodoroModel{
 		ID:          id,
 		Description: description,
@@ -58,14 +85,55 @@ func NewPomodoroModel(id int64, description string, startTime time.Time, duratio
 		EndTime:     startTime.Add(duration),
 		Duration:    duration,
 		IsBreak:     isBreak,
-		progress:    p,
+		progress:    newProgressBar(isBreak),
+		clock:       realClock{},
+		tick:        time.Second,
+	}
+}
+
+// WithClock returns the model timed by clock, redrawing every tick of real
+// time. A fast clock needs a short tick for the countdown to run smoothly.
+func (m PomodoroModel) WithClock(clock Clock, tick time.Duration) PomodoroModel {
+	m.clock = clock
+	m.tick = tick
+	return m
+}
+
+// Interrupted reports whether the user quit with Ctrl+C before the session ended
+func (m PomodoroModel) Interrupted() bool {
+	return m.interrupted
+}
+
+// newProgressBar creates the progress bar for a session. Gradients blend
+// poorly on 16-color terminals, so those get a solid ANSI color instead.
+func newProgressBar(isBreak bool) progress.Model {
+	opts := []progress.Option{
+		progress.WithWidth(40),
+		progress.WithoutPercentage(),
+		progress.WithColorProfile(term.ColorProfile()),
+	}
+
+	switch {
+	case !term.SupportsGradients() && isBreak:
+		opts = append(opts, progress.WithSolidFill("2")) // ANSI green
+	case !term.SupportsGradients():
+		opts = append(opts, progress.WithSolidFill("5")) // ANSI magenta
+	case isBreak:
+		// Green colors for break
+		opts = append(opts, progress.WithGradient("#5A8A20", "#98D44A"))
+	default:
+		// Default gradient for pomodoro (usually pinkish)
+		opts = append(opts, progress.WithDefaultGradient())
 	}
+
+	return progress.New(opts...)
 }
 
 // Init initializes the model
 func (m PomodoroModel) Init() tea.Cmd {
 	return tea.Batch(
-		tickEvery(time.Second),
+		tickEvery(m.tick),
+		m.refreshDashboard(0),
 	)
 }
 
@@ -75,19 +143,51 @@ func (m PomodoroModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		if msg.Type == tea.KeyCtrlC {
 			m.quitting = true
+			m.interrupted = true
+			return m, tea.Quit
+		}
+		if m, ok := m.handleWarmupKey(msg.String()); ok {
 			return m, tea.Quit
 		}
+		if m, cmd, ok := m.handleControlKey(msg.String()); ok {
+			return m, cmd
+		}
+		if m, ok := m.handleCounterKey(msg.String()); ok {
+			return m, nil
+		}
 	case TickMsg:
-		if time.Now().After(m.EndTime) {
+		m.handleSleep(time.Time(msg))
+		m.sync()
+		if m.abandoned || m.cancelled || (!m.paused && m.clock.Now().After(m.EndTime)) {
 			m.quitting = true
 			return m, tea.Quit
 		}
-		return m, tickEvery(time.Second)
+		return m, tickEvery(m.tick)
 	case tea.WindowSizeMsg:
 		m.progress.Width = msg.Width - padding*2 - 20
 		if m.progress.Width > maxWidth {
 			m.progress.Width = maxWidth
 		}
+	case dashboardMsg:
+		return m.handleDashboard(msg)
+	case ConfigReloadedMsg:
+		if msg.Err == nil {
+			if err := term.SetColorMode(msg.Config.Display.Color); err != nil {
+				msg.Err = err
+			}
+			term.SetTagColors(msg.Config.Display.TagColors)
+		}
+
+		// Rebuild the bar so color changes take effect
+		width := m.progress.Width
+		m.progress = newProgressBar(m.IsBreak)
+		m.progress.Width = width
+
+		if msg.Err != nil {
+			m.setNotice(fmt.Sprintf("Config not reloaded: %v", msg.Err))
+		} else {
+			m.setNotice("Config reloaded")
+		}
 	case progress.FrameMsg:
 		// Handle animation frames
 		progressModel, cmd := m.progress.Update(msg)
@@ -101,46 +201,82 @@ func (m PomodoroModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m *PomodoroModel) updateProgress() tea.Cmd {
-	now := time.Now()
-	elapsed := now.Sub(m.StartTime)
-
-	// Ensure progress doesn't exceed 1.0
-	var percent float64
-	if elapsed >= m.Duration {
-		percent = 1.0
-	} else {
-		percent = float64(elapsed) / float64(m.Duration)
-	}
+	// Progress is the share of the planned time used, so time spent
+	// paused does not move the bar
+	percent := 1 - float64(m.remaining())/float64(m.Duration)
+	percent = max(0, min(1, percent))
 
 	// Set the progress percentage (this will animate smoothly)
 	return m.progress.SetPercent(percent)
 }
 
+// remaining returns the time left in the session, which stands still while
+// it is paused
+func (m PomodoroModel) remaining() time.Duration {
+	if m.paused {
+		return m.EndTime.Sub(m.pausedAt)
+	}
+	return m.EndTime.Sub(m.clock.Now())
+}
+
 // View renders the model
 func (m PomodoroModel) View() string {
-	now := time.Now()
-
-	if m.quitting || now.After(m.EndTime) {
+	switch {
+	case m.abandoned:
+		return "Interrupted: the computer slept.\n"
+	case m.cancelled:
+		return "Cancelled.\n"
+	case m.warmup && m.interrupted:
+		return "Warm-up abandoned.\n"
+	case m.warmup && m.quitting:
+		return "Warm-up done.\n"
+	case m.quitting || (!m.paused && m.remaining() < 0):
 		return "Completed!\n"
 	}
 
-	remaining := m.EndTime.Sub(now).Round(time.Second)
-	remainingStr := utils.FormatDuration(remaining)
+	remainingStr := utils.FormatDuration(m.remaining().Round(time.Second))
+	if m.paused {
+		remainingStr += " (paused)"
+	}
 
-	emoji := "🍅"
-	if m.IsBreak {
-		emoji = "☕"
+	emoji := term.Emoji("🍅", "[work]")
+	switch {
+	case m.warmup:
+		emoji = term.Emoji("🔥", "[warm-up]")
+	case m.IsBreak:
+		emoji = term.Emoji("☕", "[break]")
 	}
 
 	pad := strings.Repeat(" ", padding)
 	progressBar := m.progress.View()
 
-	return fmt.Sprintf("\n%s%s  %s %s  %s\n",
+	view := fmt.Sprintf("\n%s%s  %s %s  %s\n",
 		pad,
 		progressBar,
 		remainingStr,
 		emoji,
 		m.Description)
+	if m.breathing != nil && !m.paused {
+		view += "\n" + pad + m.breathingView() + "\n\n"
+	}
+	if m.loadDashboard != nil {
+		view += "\n" + m.dashboardView(pad) + "\n"
+	}
+	// Notices stay up for real seconds, whatever the clock
+	if m.notice != "" && time.Now().Before(m.noticeUntil) {
+		view += pad + m.notice + "\n"
+	} else {
+		if m.warmup {
+			view += pad + warmupHelp + "\n"
+		}
+		if m.store != nil {
+			view += pad + controlsHelp + "\n"
+		}
+		if len(m.counters) > 0 {
+			view += pad + m.countersHelp() + "\n"
+		}
+	}
+	return view
 }
 
 // tickEvery returns a command that ticks at the specified interval
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/ethan-k/pomodoro-cli/internal/term"
+)
+
+func TestConfigReloaded(t *testing.T) {
+	t.Cleanup(func() { _ = term.SetColorMode(term.ColorAuto) })
+	var m tea.Model = NewPomodoroModel(1, "Work", time.Now(), 25*time.Minute, false)
+	reload := func(color string) PomodoroModel {
+		t.Helper()
+		cfg := config.DefaultConfig()
+		cfg.Display.Color = color
+		m, _ = m.Update(ConfigReloadedMsg{Config: cfg})
+		return m.(PomodoroModel)
+	}
+
+	if got := reload(term.ColorAlways); !term.ColorEnabled() || got.notice != "Config reloaded" {
+		t.Errorf("Expected color forced on by the reloaded config, got notice %q", got.notice)
+	}
+	if reload(term.ColorNever); term.ColorEnabled() {
+		t.Error("Expected color turned off by the reloaded config")
+	}
+	if got := reload("sepia"); !strings.HasPrefix(got.notice, "Config not reloaded: invalid color mode") {
+		t.Errorf("Expected an invalid color mode to be reported, got %q", got.notice)
+	}
+}
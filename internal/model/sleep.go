@@ -0,0 +1,85 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+// SleepGap is the longest a tick may run late before the timer takes it
+// that the machine slept
+const SleepGap = 30 * time.Second
+
+// SleepAction is what the timer does with a pomodoro the machine slept
+// through
+type SleepAction string
+
+// Sleep actions
+const (
+	SleepIgnore    SleepAction = "ignore"    // Count the slept time as focus
+	SleepPause     SleepAction = "pause"     // Pause the pomodoro for the slept time
+	SleepInterrupt SleepAction = "interrupt" // End the pomodoro as abandoned when the machine went to sleep
+)
+
+// WithSleep returns the model handling the machine sleeping during a
+// pomodoro with action. Breaks slept through simply run out.
+func (m PomodoroModel) WithSleep(action SleepAction) PomodoroModel {
+	m.onSleep = action
+	return m
+}
+
+// Abandoned reports whether the pomodoro was ended because the machine slept
+func (m PomodoroModel) Abandoned() bool {
+	return m.abandoned
+}
+
+// handleSleep looks for a tick that came much later than asked, which means
+// the machine slept since the last one, and applies the sleep action to the
+// running pomodoro. Ticks are compared by the wall clock, as the monotonic
+// clock stops during sleep on some systems.
+func (m *PomodoroModel) handleSleep(at time.Time) {
+	at = at.Round(0)
+	last := m.lastTick
+	m.lastTick = at
+	if m.onSleep == "" || m.onSleep == SleepIgnore || m.IsBreak || m.warmup || m.paused || last.IsZero() {
+		return
+	}
+	slept := at.Sub(last) - m.tick
+	if slept < SleepGap {
+		return
+	}
+
+	now := m.clock.Now()
+	asleep := now.Add(-slept)
+	if asleep.After(m.EndTime) {
+		return // Finished before the machine slept
+	}
+
+	switch m.onSleep {
+	case SleepInterrupt:
+		if m.store != nil {
+			if err := m.store.EndSession(m.ID, asleep, db.StatusAbandoned); err != nil {
+				m.setNotice(fmt.Sprintf("Could not end the session after sleep: %v", err))
+				return
+			}
+		}
+		m.abandoned = true
+
+	case SleepPause:
+		if m.store != nil {
+			if err := m.store.PauseSession(m.ID, asleep); err != nil {
+				m.setNotice(fmt.Sprintf("Could not pause for the time asleep: %v", err))
+				return
+			}
+			if err := m.store.ResumeSession(m.ID, now); err != nil {
+				m.setNotice(fmt.Sprintf("Could not resume after sleep: %v", err))
+				return
+			}
+		}
+		// The database shifts the end by whole seconds of pause
+		m.EndTime = m.EndTime.Add(slept.Truncate(time.Second))
+		m.setNotice(fmt.Sprintf("Paused for %s while asleep", utils.FormatDuration(slept.Round(time.Second))))
+	}
+}
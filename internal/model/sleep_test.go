@@ -0,0 +1,72 @@
+package model
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func TestTimerHandlesSleep(t *testing.T) {
+	database, err := db.NewDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = database.Close() }()
+
+	start := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	run := func(action SleepAction) (PomodoroModel, *db.PomodoroSession) {
+		t.Helper()
+		clock := &stepClock{now: start}
+		id, err := database.CreateSession(start, start.Add(25*time.Minute), "Work", 25*60, "", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := NewPomodoroModel(id, "Work", start, 25*time.Minute, false).
+			WithClock(clock, time.Second).
+			WithControls(database, nil).
+			WithSleep(action)
+		tick := func() {
+			next, _ := m.Update(TickMsg(clock.now))
+			m = next.(PomodoroModel)
+		}
+
+		clock.now = clock.now.Add(10 * time.Minute)
+		tick()
+		// Asleep for an hour; the next tick is due a second after the last
+		clock.now = clock.now.Add(time.Hour + time.Second)
+		tick()
+
+		s, err := database.GetSessionByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m, s
+	}
+
+	m, s := run(SleepPause)
+	if m.Abandoned() || m.Cancelled() || s.IsPaused {
+		t.Fatal("Expected the pomodoro to carry on after waking")
+	}
+	if want := start.Add(25*time.Minute + time.Hour); !s.EndTime.Equal(want) || !m.EndTime.Equal(want) {
+		t.Errorf("Expected the end to move by the hour asleep to %s, got %s (%s in the timer)", want, s.EndTime, m.EndTime)
+	}
+	// The second the tick was due counts as focus
+	if m.remaining() != 15*time.Minute-time.Second {
+		t.Errorf("Expected 14m59s left after waking, got %s", m.remaining())
+	}
+
+	m, s = run(SleepInterrupt)
+	if !m.Abandoned() || s.Status != db.StatusAbandoned {
+		t.Fatalf("Expected the pomodoro to be abandoned, got status %q", s.Status)
+	}
+	if want := start.Add(10*time.Minute + time.Second); !s.EndTime.Equal(want) {
+		t.Errorf("Expected it to end when the machine went to sleep at %s, got %s", want, s.EndTime)
+	}
+
+	m, s = run(SleepIgnore)
+	if m.Abandoned() || s.Status == db.StatusAbandoned || !s.EndTime.Equal(start.Add(25*time.Minute)) {
+		t.Error("Expected ignore to leave the pomodoro alone")
+	}
+}
@@ -0,0 +1,199 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// PracticeLength is how long the tutorial's practice session and break run
+const PracticeLength = 10 * time.Second
+
+// tutorialStep is one page of the tutorial
+type tutorialStep int
+
+const (
+	stepWelcome tutorialStep = iota
+	stepSession
+	stepSessionDone
+	stepBreak
+	stepBreakDone
+	stepDailyGoal
+	stepWeeklyGoal
+	stepFinished
+)
+
+// TutorialModel walks a new user through a practice session, a practice
+// break, and setting goals. The practice timers are not recorded anywhere.
+type TutorialModel struct {
+	step        tutorialStep
+	timer       PomodoroModel
+	daily       int
+	weekly      int
+	width       int
+	interrupted bool
+}
+
+// NewTutorialModel creates a tutorial whose goal steps start at the given
+// daily and weekly targets
+func NewTutorialModel(daily, weekly int) TutorialModel {
+	return TutorialModel{daily: daily, weekly: weekly}
+}
+
+// Goals returns the daily and weekly targets chosen in the tutorial
+func (m TutorialModel) Goals() (daily, weekly int) {
+	return m.daily, m.weekly
+}
+
+// Completed reports whether the user reached the end of the tutorial
+func (m TutorialModel) Completed() bool {
+	return m.step == stepFinished && !m.interrupted
+}
+
+// Init initializes the model
+func (m TutorialModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages and updates the model
+func (m TutorialModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			m.interrupted = true
+			return m, tea.Quit
+		}
+	}
+
+	if m.step == stepSession || m.step == stepBreak {
+		return m.updateTimer(msg)
+	}
+
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		if size, ok := msg.(tea.WindowSizeMsg); ok {
+			m.width = size.Width
+		}
+		return m, nil
+	}
+
+	switch m.step {
+	case stepDailyGoal:
+		m.daily = adjustGoal(m.daily, key.String(), 1, 50)
+	case stepWeeklyGoal:
+		m.weekly = adjustGoal(m.weekly, key.String(), 1, 350)
+	}
+
+	if key.Type != tea.KeyEnter {
+		return m, nil
+	}
+	switch m.step {
+	case stepWelcome:
+		return m.startTimer(stepSession, "Practice session", false)
+	case stepSessionDone:
+		return m.startTimer(stepBreak, "Practice break", true)
+	case stepFinished:
+		return m, tea.Quit
+	default:
+		m.step++
+		if m.step == stepFinished {
+			// Leave the summary on screen and finish
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+// startTimer moves to a practice timer step
+func (m TutorialModel) startTimer(step tutorialStep, description string, isBreak bool) (tea.Model, tea.Cmd) {
+	m.step = step
+	m.timer = NewPomodoroModel(0, description, time.Now(), PracticeLength, isBreak)
+	if m.width > 0 {
+		t, _ := m.timer.Update(tea.WindowSizeMsg{Width: m.width})
+		m.timer = t.(PomodoroModel)
+	}
+	return m, m.timer.Init()
+}
+
+// updateTimer passes a message to the running practice timer and moves on
+// once it finishes
+func (m TutorialModel) updateTimer(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if size, ok := msg.(tea.WindowSizeMsg); ok {
+		m.width = size.Width
+	}
+
+	t, cmd := m.timer.Update(msg)
+	m.timer = t.(PomodoroModel)
+	if m.timer.quitting {
+		// The timer asks to quit when it ends; the tutorial carries on
+		m.step++
+		return m, nil
+	}
+	return m, cmd
+}
+
+// adjustGoal changes a goal with the arrow keys, keeping it within limits
+func adjustGoal(value int, key string, lo, hi int) int {
+	switch key {
+	case "up", "right", "+", "k", "l":
+		value++
+	case "down", "left", "-", "j", "h":
+		value--
+	}
+	return max(lo, min(hi, value))
+}
+
+// View renders the model
+func (m TutorialModel) View() string {
+	pad := strings.Repeat(" ", padding)
+	var b strings.Builder
+	line := func(format string, a ...any) {
+		b.WriteString(pad + fmt.Sprintf(format, a...) + "\n")
+	}
+
+	b.WriteString("\n")
+	switch m.step {
+	case stepWelcome:
+		line("Welcome to pomodoro!")
+		line("")
+		line("A pomodoro is a focused stretch of work, 25 minutes by default,")
+		line("followed by a short break. Let's try one that lasts %s.", PracticeLength)
+		line("Practice timers are not saved to your history.")
+		line("")
+		line("Press Enter to start a practice session, Esc to leave.")
+	case stepSession, stepBreak:
+		b.WriteString(m.timer.View())
+	case stepSessionDone:
+		line("Session complete! Normally you would get a notification now.")
+		line("You would start it with: pomodoro start \"Write report\"")
+		line("")
+		line("Press Enter to take a practice break.")
+	case stepBreakDone:
+		line("Break over. Start one with: pomodoro break")
+		line("Every fourth pomodoro earns a longer break.")
+		line("")
+		line("Press Enter to set your goals.")
+	case stepDailyGoal:
+		line("How many pomodoros would you like to do each day?")
+		line("")
+		line("  ◀ %d ▶", m.daily)
+		line("")
+		line("Use the arrow keys to change it, Enter to confirm.")
+	case stepWeeklyGoal:
+		line("And each week?")
+		line("")
+		line("  ◀ %d ▶", m.weekly)
+		line("")
+		line("Use the arrow keys to change it, Enter to confirm.")
+	case stepFinished:
+		line("All set: %d pomodoros a day, %d a week.", m.daily, m.weekly)
+		line("")
+		line("  pomodoro start \"Task\"   start a pomodoro")
+		line("  pomodoro status         see what is running")
+		line("  pomodoro history        review your sessions")
+		line("  pomodoro --help         everything else")
+	}
+	return b.String()
+}
@@ -0,0 +1,22 @@
+package model
+
+// warmupHelp lists the warm-up keys below the progress bar
+const warmupHelp = "enter start now · ctrl+c abort"
+
+// WithWarmup returns the model counting down a warm-up before a session
+// rather than the session itself: Enter or s ends it early, and no session
+// is saved or changed while it runs.
+func (m PomodoroModel) WithWarmup() PomodoroModel {
+	m.warmup = true
+	m.store = nil
+	return m
+}
+
+// handleWarmupKey ends a warm-up early on Enter or s
+func (m PomodoroModel) handleWarmupKey(key string) (PomodoroModel, bool) {
+	if !m.warmup || (key != "enter" && key != "s") {
+		return m, false
+	}
+	m.quitting = true
+	return m, true
+}
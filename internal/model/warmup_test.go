@@ -0,0 +1,41 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestWarmup(t *testing.T) {
+	clock := &stepClock{now: time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)}
+	m := NewPomodoroModel(0, "Write report", clock.now, 2*time.Minute, false).
+		WithClock(clock, time.Second).
+		WithWarmup()
+
+	if view := m.View(); !strings.Contains(view, warmupHelp) || strings.Contains(view, controlsHelp) {
+		t.Errorf("Expected the warm-up keys and no timer controls, got %q", view)
+	}
+
+	// Timer keys do nothing during a warm-up
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	if m = next.(PomodoroModel); m.Paused() {
+		t.Error("Expected p not to pause a warm-up")
+	}
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(PomodoroModel)
+	if cmd == nil || m.Interrupted() {
+		t.Fatal("Expected Enter to end the warm-up early")
+	}
+	if view := m.View(); view != "Warm-up done.\n" {
+		t.Errorf("Expected the warm-up to be done, got %q", view)
+	}
+
+	m = NewPomodoroModel(0, "Write report", clock.now, 2*time.Minute, false).WithWarmup()
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if m = next.(PomodoroModel); !m.Interrupted() || m.View() != "Warm-up abandoned.\n" {
+		t.Errorf("Expected Ctrl+C to abandon the warm-up, got %q", m.View())
+	}
+}
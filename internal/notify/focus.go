@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+)
+
+// macOS has no command to switch Focus, so focus mode runs two shortcuts the
+// user creates in the Shortcuts app with its "Set Focus" action
+const (
+	FocusOnShortcut  = "Pomodoro Focus On"
+	FocusOffShortcut = "Pomodoro Focus Off"
+)
+
+// focusSupported reports whether Focus can be switched on this platform
+var focusSupported = runtime.GOOS == "darwin"
+
+// runShortcut runs a shortcut from the Shortcuts app by name
+var runShortcut = func(name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "shortcuts", "run", name).CombinedOutput() // #nosec G204 - the shortcut names are constants
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%v: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// SetFocus turns macOS Focus on or off when focus_mode is set in the
+// notifications config. Elsewhere it does nothing.
+func SetFocus(cfg config.NotificationsConfig, on bool) error {
+	if !cfg.FocusMode || !focusSupported {
+		return nil
+	}
+	name, state := FocusOffShortcut, "off"
+	if on {
+		name, state = FocusOnShortcut, "on"
+	}
+	if err := runShortcut(name); err != nil {
+		return fmt.Errorf("error turning Focus %s with the %q shortcut: %v", state, name, err)
+	}
+	return nil
+}
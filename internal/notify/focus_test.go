@@ -0,0 +1,46 @@
+package notify
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+)
+
+func TestSetFocus(t *testing.T) {
+	savedSupported, savedRun := focusSupported, runShortcut
+	defer func() { focusSupported, runShortcut = savedSupported, savedRun }()
+
+	var ran []string
+	runShortcut = func(name string) error {
+		ran = append(ran, name)
+		return nil
+	}
+	focusSupported = true
+
+	if err := SetFocus(config.NotificationsConfig{}, true); err != nil || len(ran) != 0 {
+		t.Fatalf("Expected nothing to run without focus_mode, got %v, %v", ran, err)
+	}
+
+	cfg := config.NotificationsConfig{FocusMode: true}
+	if err := SetFocus(cfg, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetFocus(cfg, false); err != nil {
+		t.Fatal(err)
+	}
+	if len(ran) != 2 || ran[0] != FocusOnShortcut || ran[1] != FocusOffShortcut {
+		t.Errorf("Expected the on and off shortcuts to run, got %v", ran)
+	}
+
+	runShortcut = func(string) error { return errors.New("shortcut not found") }
+	if err := SetFocus(cfg, true); err == nil || !strings.Contains(err.Error(), FocusOnShortcut) {
+		t.Errorf("Expected an error naming the shortcut, got %v", err)
+	}
+
+	focusSupported = false
+	if err := SetFocus(cfg, true); err != nil {
+		t.Errorf("Expected nothing to happen off macOS, got %v", err)
+	}
+}
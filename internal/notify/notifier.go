@@ -0,0 +1,217 @@
+package notify
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/config"
+	"github.com/gen2brain/beeep"
+)
+
+// Action is a choice offered to the user alongside a notification
+type Action struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+// Notifier delivers notifications to the user
+type Notifier interface {
+	// Send delivers a plain notification
+	Send(title, message string) error
+	// SendWithActions delivers a notification offering the given actions and
+	// returns the ID of the chosen action, or "" if none was chosen or the
+	// notifier cannot collect a response
+	SendWithActions(title, message string, actions []Action) (string, error)
+}
+
+var (
+	_ Notifier = (*DesktopNotifier)(nil)
+	_ Notifier = (*TerminalNotifier)(nil)
+	_ Notifier = (*WebhookNotifier)(nil)
+	_ Notifier = (MultiNotifier)(nil)
+)
+
+// NewNotifier builds a notifier from the notifications configuration
+func NewNotifier(cfg config.NotificationsConfig) Notifier {
+	var notifiers MultiNotifier
+
+	if cfg.Desktop {
+		notifiers = append(notifiers, &DesktopNotifier{})
+	}
+	if cfg.Terminal {
+		notifiers = append(notifiers, NewTerminalNotifier(os.Stderr, os.Stdin))
+	}
+	if cfg.WebhookURL != "" {
+		notifiers = append(notifiers, NewWebhookNotifier(cfg.WebhookURL))
+	}
+
+	return notifiers
+}
+
+// DefaultNotifier builds a notifier from the user's configuration,
+// falling back to desktop notifications if the config cannot be loaded
+func DefaultNotifier() Notifier {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return &DesktopNotifier{}
+	}
+	return NewNotifier(cfg.Notifications)
+}
+
+// DesktopNotifier sends native desktop notifications
+type DesktopNotifier struct{}
+
+// Send sends a desktop notification
+func (n *DesktopNotifier) Send(title, message string) error {
+	return beeep.Notify(title, message, "")
+}
+
+// SendWithActions sends a desktop notification. Native notifications cannot
+// collect a response, so no action is ever chosen.
+func (n *DesktopNotifier) SendWithActions(title, message string, _ []Action) (string, error) {
+	return "", n.Send(title, message)
+}
+
+// TerminalNotifier writes notifications to a terminal
+type TerminalNotifier struct {
+	out io.Writer
+	in  io.Reader
+}
+
+// NewTerminalNotifier creates a notifier writing to out and reading action choices from in
+func NewTerminalNotifier(out io.Writer, in io.Reader) *TerminalNotifier {
+	return &TerminalNotifier{out: out, in: in}
+}
+
+// Send writes the notification with a terminal bell
+func (n *TerminalNotifier) Send(title, message string) error {
+	_, err := fmt.Fprintf(n.out, "\a🔔 %s: %s\n", title, message)
+	return err
+}
+
+// SendWithActions writes the notification and prompts for one of the actions
+func (n *TerminalNotifier) SendWithActions(title, message string, actions []Action) (string, error) {
+	if err := n.Send(title, message); err != nil {
+		return "", err
+	}
+	if len(actions) == 0 || n.in == nil {
+		return "", nil
+	}
+
+	for i, action := range actions {
+		if _, err := fmt.Fprintf(n.out, "%d. %s (%s)\n", i+1, action.Label, action.ID); err != nil {
+			return "", err
+		}
+	}
+	if _, err := fmt.Fprint(n.out, "Choose an option: "); err != nil {
+		return "", err
+	}
+
+	line, err := bufio.NewReader(n.in).ReadString('\n')
+	if err != nil && line == "" {
+		return "", nil
+	}
+	choice := strings.ToLower(strings.TrimSpace(line))
+
+	for i, action := range actions {
+		if choice == fmt.Sprint(i+1) || choice == strings.ToLower(action.ID) {
+			return action.ID, nil
+		}
+	}
+
+	return "", nil
+}
+
+// WebhookNotifier posts notifications as JSON to an HTTP endpoint
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a notifier posting to the given URL
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// webhookPayload is the JSON body posted by WebhookNotifier
+type webhookPayload struct {
+	Title   string   `json:"title"`
+	Message string   `json:"message"`
+	Actions []Action `json:"actions,omitempty"`
+	SentAt  string   `json:"sent_at"`
+}
+
+// Send posts the notification to the webhook
+func (n *WebhookNotifier) Send(title, message string) error {
+	return n.post(webhookPayload{Title: title, Message: message})
+}
+
+// SendWithActions posts the notification including the offered actions.
+// Webhooks are fire-and-forget, so no action is ever chosen.
+func (n *WebhookNotifier) SendWithActions(title, message string, actions []Action) (string, error) {
+	return "", n.post(webhookPayload{Title: title, Message: message, Actions: actions})
+}
+
+func (n *WebhookNotifier) post(payload webhookPayload) error {
+	payload.SentAt = time.Now().Format(time.RFC3339)
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error encoding webhook payload: %w", err)
+	}
+
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error sending webhook: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %s", resp.Status)
+	}
+
+	return nil
+}
+
+// MultiNotifier fans notifications out to several notifiers
+type MultiNotifier []Notifier
+
+// Send delivers the notification through every notifier, returning the first error
+func (m MultiNotifier) Send(title, message string) error {
+	var firstErr error
+	for _, n := range m {
+		if err := n.Send(title, message); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// SendWithActions delivers the notification through every notifier and
+// returns the first action chosen
+func (m MultiNotifier) SendWithActions(title, message string, actions []Action) (string, error) {
+	var chosen string
+	var firstErr error
+	for _, n := range m {
+		id, err := n.SendWithActions(title, message, actions)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if chosen == "" {
+			chosen = id
+		}
+	}
+	return chosen, firstErr
+}
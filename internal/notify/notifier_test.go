@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTerminalNotifier_SendWithActions(t *testing.T) {
+	actions := []Action{
+		{ID: "break", Label: "Start a break"},
+		{ID: "skip", Label: "Skip"},
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "By number", input: "2\n", expected: "skip"},
+		{name: "By ID", input: "Break\n", expected: "break"},
+		{name: "Unknown choice", input: "x\n", expected: ""},
+		{name: "No input", input: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			n := NewTerminalNotifier(&out, strings.NewReader(tt.input))
+
+			chosen, err := n.SendWithActions("Pomodoro Complete", "Done", actions)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if chosen != tt.expected {
+				t.Errorf("Expected action %q, got %q", tt.expected, chosen)
+			}
+			if !strings.Contains(out.String(), "Pomodoro Complete: Done") {
+				t.Errorf("Expected notification in output, got: %q", out.String())
+			}
+		})
+	}
+}
+
+func TestWebhookNotifier_Send(t *testing.T) {
+	var received webhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Expected JSON body, got error: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	if err := NewWebhookNotifier(server.URL).Send("Break Complete", "Back to work"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if received.Title != "Break Complete" || received.Message != "Back to work" {
+		t.Errorf("Unexpected payload: %+v", received)
+	}
+}
+
+func TestWebhookNotifier_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	if err := NewWebhookNotifier(server.URL).Send("t", "m"); err == nil {
+		t.Error("Expected error for non-2xx webhook response")
+	}
+}
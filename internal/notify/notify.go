@@ -6,14 +6,26 @@ import (
 
 	"github.com/ethan-k/pomodoro-cli/internal/audio"
 	"github.com/ethan-k/pomodoro-cli/internal/config"
-	"github.com/gen2brain/beeep"
 )
 
+// Titles and messages of the completion notifications
+const (
+	PomodoroCompleteTitle = "Pomodoro Complete"
+	BreakCompleteTitle    = "Break Complete"
+	BreakCompleteMessage  = "Break time is over. Resume work."
+)
+
+// PomodoroCompleteMessage returns the message of the notification for a
+// completed pomodoro
+func PomodoroCompleteMessage(description string) string {
+	return fmt.Sprintf("Task completed: %s", description)
+}
+
 // NotifyComplete sends a notification when a Pomodoro or break is complete
 //
 //nolint:revive // keeping existing API naming convention
 func NotifyComplete(title, message string) error {
-	return beeep.Notify(title, message, "")
+	return DefaultNotifier().Send(title, message)
 }
 
 // NotifyWithAudio sends both visual and audio notifications
@@ -43,34 +55,59 @@ func NotifyWithAudio(title, message string, soundType audio.SoundType, silentMod
 //
 //nolint:revive // keeping existing API naming convention
 func NotifyPomodoroComplete(description string) error {
-	title := "Pomodoro Complete"
-	message := fmt.Sprintf("Task completed: %s", description)
-	return NotifyWithAudio(title, message, audio.PomodoroComplete, false)
+	return NotifyWithAudio(PomodoroCompleteTitle, PomodoroCompleteMessage(description), audio.PomodoroComplete, false)
 }
 
 // NotifyPomodoroCompleteWithOptions sends a notification with audio options
 //
 //nolint:revive // keeping existing API naming convention
 func NotifyPomodoroCompleteWithOptions(description string, silentMode bool) error {
-	title := "Pomodoro Complete"
-	message := fmt.Sprintf("Task completed: %s", description)
-	return NotifyWithAudio(title, message, audio.PomodoroComplete, silentMode)
+	return NotifyWithAudio(PomodoroCompleteTitle, PomodoroCompleteMessage(description), audio.PomodoroComplete, silentMode)
 }
 
 // NotifyBreakComplete sends a notification when a break is complete
 //
 //nolint:revive // keeping existing API naming convention
 func NotifyBreakComplete() error {
-	title := "Break Complete"
-	message := "Break time is over. Resume work."
-	return NotifyWithAudio(title, message, audio.BreakComplete, false)
+	return NotifyWithAudio(BreakCompleteTitle, BreakCompleteMessage, audio.BreakComplete, false)
 }
 
 // NotifyBreakCompleteWithOptions sends a notification with audio options
 //
 //nolint:revive // keeping existing API naming convention
 func NotifyBreakCompleteWithOptions(silentMode bool) error {
-	title := "Break Complete"
-	message := "Break time is over. Resume work."
-	return NotifyWithAudio(title, message, audio.BreakComplete, silentMode)
+	return NotifyWithAudio(BreakCompleteTitle, BreakCompleteMessage, audio.BreakComplete, silentMode)
+}
+
+// PlayAchievementSound plays the sound for an unlocked achievement.
+// The sound is resolved through the audio config, using the per-achievement
+// sound from the achievements config when one is set.
+func PlayAchievementSound(id string, silentMode bool) {
+	if silentMode {
+		return
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+
+	if !cfg.Achievements.Enabled || !cfg.Achievements.Audio || cfg.Audio == nil {
+		return
+	}
+
+	// Copy the audio config so per-achievement sounds don't leak into the saved config
+	audioCfg := *cfg.Audio
+	audioCfg.Sounds = make(map[string]string, len(cfg.Audio.Sounds)+len(cfg.Achievements.Sounds))
+	for k, v := range cfg.Audio.Sounds {
+		audioCfg.Sounds[k] = v
+	}
+	for achievementID, filename := range cfg.Achievements.Sounds {
+		audioCfg.Sounds[string(audio.AchievementSound(achievementID))] = filename
+	}
+
+	player, err := audio.NewPlayer(&audioCfg)
+	if err == nil {
+		audio.PlayAsync(player, audio.AchievementSound(id))
+	}
 }
@@ -1,8 +1,11 @@
-// Package opf provides Open Pomodoro Format (OPF) export functionality
+// Package opf provides Open Pomodoro Format (OPF) export and parsing for import
 package opf
 
 import (
+	"crypto/sha1" // #nosec G505 - UUID version 5 is defined with SHA-1; it only names sessions
+	"encoding/hex"
 	"encoding/json"
+	"strconv"
 	"time"
 
 	"github.com/ethan-k/pomodoro-cli/internal/db"
@@ -15,7 +18,8 @@ type Pomodoro struct {
 	Duration    int      `json:"duration"`
 	Description string   `json:"description,omitempty"`
 	Tags        []string `json:"tags,omitempty"`
-	Type        string   `json:"type"` // "pomodoro" or "break"
+	Type        string   `json:"type"`             // "pomodoro" or "break"
+	Status      string   `json:"status,omitempty"` // "completed", "cancelled", or "abandoned"; absent while running
 }
 
 // Export represents the root object for Open Pomodoro Format export
@@ -25,9 +29,9 @@ type Export struct {
 
 // ConvertToOPF converts a PomodoroSession to OPF format
 func ConvertToOPF(session *db.PomodoroSession) Pomodoro {
-	pomType := "pomodoro"
+	pomType := TypePomodoro
 	if session.WasBreak {
-		pomType = "break"
+		pomType = TypeBreak
 	}
 
 	// Convert tags CSV to slice
@@ -37,12 +41,13 @@ func ConvertToOPF(session *db.PomodoroSession) Pomodoro {
 	}
 
 	return Pomodoro{
-		ID:          formatID(session.ID),
+		ID:          formatID(session.ID, session.StartTime),
 		StartedAt:   formatTime(session.StartTime),
 		Duration:    int(session.DurationSec / 60), // Convert to minutes
 		Description: session.Description,
 		Tags:        tags,
 		Type:        pomType,
+		Status:      session.Status,
 	}
 }
 
@@ -65,9 +70,23 @@ func ExportToJSON(sessions []db.PomodoroSession) ([]byte, error) {
 	return json.MarshalIndent(opfExport, "", "  ")
 }
 
-// Helper functions
-func formatID(_ int64) string {
-	return time.Now().Format("20060102") + "-" + time.Now().Format("150405") + "-" + time.Now().Format("000")
+// idNamespace is the UUID namespace OPF IDs are derived in
+var idNamespace = [16]byte{0x6f, 0x1c, 0x2d, 0x4e, 0x8a, 0x3b, 0x4f, 0x52, 0x9c, 0x07, 0x5e, 0x21, 0xd3, 0x48, 0xa1, 0x90}
+
+// formatID returns a session's OPF ID: a version 5 UUID of its ID and start
+// time, so exporting the same session always gives the same ID and sessions
+// from different databases that share an ID still differ
+func formatID(id int64, start time.Time) string {
+	h := sha1.New() // #nosec G401 - see the import
+	h.Write(idNamespace[:])
+	h.Write([]byte(strconv.FormatInt(id, 10) + "@" + start.UTC().Format(time.RFC3339Nano)))
+	var u [16]byte
+	copy(u[:], h.Sum(nil))
+	u[6] = u[6]&0x0f | 0x50 // Version 5
+	u[8] = u[8]&0x3f | 0x80 // RFC 4122 variant
+
+	s := hex.EncodeToString(u[:])
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
 }
 
 func formatTime(t time.Time) string {
@@ -0,0 +1,90 @@
+package opf
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+// uuidV5 matches a version 5, RFC 4122 variant UUID
+var uuidV5 = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-5[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestExportRoundTrip(t *testing.T) {
+	cest := time.FixedZone("CEST", 2*3600)
+	start := time.Date(2024, 6, 1, 9, 0, 0, 0, cest)
+	sessions := []db.PomodoroSession{
+		{ID: 1, StartTime: start, DurationSec: 1500, Description: "Write report", TagsCSV: "work,writing", Status: db.StatusCompleted},
+		{ID: 2, StartTime: start.Add(25 * time.Minute), DurationSec: 300, Description: "Break", WasBreak: true, Status: db.StatusCompleted},
+		{ID: 3, StartTime: start.Add(30 * time.Minute), DurationSec: 1500, Description: "Review", Status: db.StatusCancelled},
+		{ID: 4, StartTime: start.Add(time.Hour), DurationSec: 3000, Description: "Plan"}, // Still running
+	}
+
+	data, err := ExportToJSON(sessions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every entry has the fields the format requires
+	var raw struct {
+		Pomodoros []map[string]any `json:"pomodoros"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range raw.Pomodoros {
+		for _, field := range []string{"id", "started_at", "duration", "type"} {
+			if _, ok := p[field]; !ok {
+				t.Errorf("Pomodoro %d has no %s: %v", i+1, field, p)
+			}
+		}
+	}
+
+	export, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse rejected an export: %v", err)
+	}
+	if len(export.Pomodoros) != len(sessions) {
+		t.Fatalf("Expected %d pomodoros, got %d", len(sessions), len(export.Pomodoros))
+	}
+	ids := map[string]bool{}
+	for i, p := range export.Pomodoros {
+		s := sessions[i]
+		if !uuidV5.MatchString(p.ID) {
+			t.Errorf("Expected a version 5 UUID, got %q", p.ID)
+		}
+		if ids[p.ID] {
+			t.Errorf("Duplicate ID %q", p.ID)
+		}
+		ids[p.ID] = true
+
+		if !p.StartTime().Equal(s.StartTime) || p.Duration != int(s.DurationSec/60) ||
+			p.Description != s.Description || (p.Type == TypeBreak) != s.WasBreak || p.Status != s.Status {
+			t.Errorf("Round trip changed session %d into %+v", s.ID, p)
+		}
+	}
+
+	// The same session always gets the same ID, and another database's
+	// session with the same ID but another start gets a different one
+	again, err := ExportToJSON(sessions[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	reparsed, err := Parse(again)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reparsed.Pomodoros[0].ID != export.Pomodoros[0].ID {
+		t.Errorf("Expected a stable ID, got %q and %q", export.Pomodoros[0].ID, reparsed.Pomodoros[0].ID)
+	}
+	other := ConvertToOPF(&db.PomodoroSession{ID: 1, StartTime: start.Add(24 * time.Hour), DurationSec: 1500})
+	if other.ID == export.Pomodoros[0].ID {
+		t.Error("Expected sessions with the same ID and different starts to differ")
+	}
+
+	if _, err := Parse([]byte(`{"pomodoros":[{"started_at":"2024-06-01T09:00:00Z","duration":25,"status":"paused"}]}`)); err == nil {
+		t.Error("Expected an unknown status to be refused")
+	}
+}
@@ -0,0 +1,91 @@
+package opf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+// MaxImportSize bounds the OPF documents Parse accepts
+const MaxImportSize = 64 << 20
+
+// maxDurationMinutes matches the 24 hour limit on session durations
+const maxDurationMinutes = 24 * 60
+
+// Session types in OPF documents
+const (
+	TypePomodoro = "pomodoro"
+	TypeBreak    = "break"
+)
+
+// Parse reads and validates an OPF document. Every pomodoro must have a
+// valid start time, a duration between one minute and 24 hours, and a known
+// type and status, if it has one; descriptions and tags are checked like
+// those typed on the command line. The first invalid entry fails the whole
+// document.
+func Parse(data []byte) (*Export, error) {
+	if len(data) > MaxImportSize {
+		return nil, fmt.Errorf("OPF document is larger than %d MB", MaxImportSize>>20)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var export Export
+	if err := dec.Decode(&export); err != nil {
+		return nil, fmt.Errorf("error parsing OPF document: %v", err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("error parsing OPF document: unexpected data after the root object")
+	}
+
+	for i := range export.Pomodoros {
+		if err := export.Pomodoros[i].normalize(); err != nil {
+			return nil, fmt.Errorf("pomodoro %d: %v", i+1, err)
+		}
+	}
+	return &export, nil
+}
+
+// StartTime returns when the pomodoro started. It is only valid for
+// pomodoros returned by Parse.
+func (p Pomodoro) StartTime() time.Time {
+	t, _ := time.Parse(time.RFC3339, p.StartedAt)
+	return t
+}
+
+// normalize validates a parsed pomodoro and tidies its text fields
+func (p *Pomodoro) normalize() error {
+	if _, err := time.Parse(time.RFC3339, p.StartedAt); err != nil {
+		return fmt.Errorf("invalid started_at %.40q: must be an RFC 3339 time", p.StartedAt)
+	}
+	// Compare minutes directly, since converting a huge count could overflow
+	if p.Duration < 1 || p.Duration > maxDurationMinutes {
+		return fmt.Errorf("invalid duration %d: must be between 1 and %d minutes", p.Duration, maxDurationMinutes)
+	}
+
+	p.Type = strings.ToLower(strings.TrimSpace(p.Type))
+	if p.Type == "" {
+		p.Type = TypePomodoro
+	}
+	if p.Type != TypePomodoro && p.Type != TypeBreak {
+		return fmt.Errorf("invalid type %.20q: must be %s or %s", p.Type, TypePomodoro, TypeBreak)
+	}
+
+	p.Status = strings.ToLower(strings.TrimSpace(p.Status))
+	switch p.Status {
+	case "", db.StatusCompleted, db.StatusCancelled, db.StatusAbandoned:
+	default:
+		return fmt.Errorf("invalid status %.20q: must be %s, %s, or %s", p.Status, db.StatusCompleted, db.StatusCancelled, db.StatusAbandoned)
+	}
+
+	p.Description = utils.SanitizeDescription(p.Description)
+	if err := utils.ValidateDescription(p.Description, false); err != nil {
+		return err
+	}
+	p.Tags = utils.SanitizeTags(p.Tags)
+	return utils.ValidateTags(p.Tags)
+}
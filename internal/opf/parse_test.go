@@ -0,0 +1,83 @@
+package opf
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func TestParse(t *testing.T) {
+	export, err := Parse([]byte(`{"pomodoros":[
+		{"id":"a","started_at":"2024-06-01T09:00:00+02:00","duration":25,"description":"  Write   report ","tags":["Work"," work","writing"],"type":"pomodoro"},
+		{"id":"b","started_at":"2024-06-01T09:25:00+02:00","duration":5,"type":"BREAK"},
+		{"id":"c","started_at":"2024-06-01T09:30:00Z","duration":25}
+	]}`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(export.Pomodoros) != 3 {
+		t.Fatalf("Expected 3 pomodoros, got %d", len(export.Pomodoros))
+	}
+	p := export.Pomodoros[0]
+	if p.Description != "Write report" || !reflect.DeepEqual(p.Tags, []string{"work", "writing"}) {
+		t.Errorf("Expected a tidied description and tags, got %q %v", p.Description, p.Tags)
+	}
+	if !p.StartTime().Equal(time.Date(2024, 6, 1, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected start time %v", p.StartTime())
+	}
+	if export.Pomodoros[1].Type != TypeBreak || export.Pomodoros[2].Type != TypePomodoro {
+		t.Errorf("Expected types to be normalized, got %q and %q", export.Pomodoros[1].Type, export.Pomodoros[2].Type)
+	}
+
+	for _, doc := range []string{
+		``,
+		`[]`,
+		`{"pomodoros":[{"started_at":"yesterday","duration":25}]}`,
+		`{"pomodoros":[{"started_at":"2024-06-01T09:00:00Z","duration":0}]}`,
+		`{"pomodoros":[{"started_at":"2024-06-01T09:00:00Z","duration":9223372036854775807}]}`,
+		`{"pomodoros":[{"started_at":"2024-06-01T09:00:00Z","duration":25,"type":"nap"}]}`,
+		`{"pomodoros":[{"started_at":"2024-06-01T09:00:00Z","duration":25,"description":"` + strings.Repeat("x", 300) + `"}]}`,
+		`{"pomodoros":[]} {}`,
+	} {
+		if _, err := Parse([]byte(doc)); err == nil {
+			t.Errorf("Expected an error parsing %.60q", doc)
+		}
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	start := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	exported, err := ExportToJSON([]db.PomodoroSession{
+		{ID: 1, StartTime: start, DurationSec: 1500, Description: "Write", TagsCSV: "work,writing"},
+		{ID: 2, StartTime: start.Add(25 * time.Minute), DurationSec: 300, Description: "Break", WasBreak: true},
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(exported)
+	f.Add([]byte(`{"pomodoros":[{"started_at":"2024-06-01T09:00:00Z","duration":25,"tags":["a,b"]}]}`))
+	f.Add([]byte(`{"pomodoros":null}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		export, err := Parse(data)
+		if err != nil {
+			return
+		}
+		// Whatever Parse accepts survives a round trip unchanged
+		again, err := json.Marshal(export)
+		if err != nil {
+			t.Fatalf("Failed to marshal parsed document: %v", err)
+		}
+		reparsed, err := Parse(again)
+		if err != nil {
+			t.Fatalf("Parse rejected its own output %s: %v", again, err)
+		}
+		if !reflect.DeepEqual(export, reparsed) {
+			t.Errorf("Round trip changed %+v into %+v", export, reparsed)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+// Package org exports sessions as Emacs org-mode CLOCK entries
+package org
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+// timestampLayout is an org inactive timestamp without its brackets
+const timestampLayout = "2006-01-02 Mon 15:04"
+
+// headingWidth is the column org aligns heading tags to by default
+const headingWidth = 77
+
+// tagChars matches the characters org does not allow in a tag
+var tagChars = regexp.MustCompile(`[^\p{L}\p{N}_@#%]+`)
+
+// Clock is one CLOCK line: a stretch of focus between two minutes
+type Clock struct {
+	Start time.Time
+	End   time.Time
+}
+
+// String formats the clock as org writes it, with the time it adds up to
+func (c Clock) String() string {
+	minutes := int(c.End.Sub(c.Start) / time.Minute)
+	return fmt.Sprintf("CLOCK: [%s]--[%s] => %2d:%02d",
+		c.Start.Format(timestampLayout), c.End.Format(timestampLayout), minutes/60, minutes%60)
+}
+
+// Clocks splits a finished session into the stretches between its pauses,
+// in the zone it was recorded in. Times are cut to the minute, as org
+// timestamps are, and stretches that round to nothing are left out.
+func Clocks(s db.PomodoroSession, pauses []db.Pause) []Clock {
+	loc := s.Location()
+	var clocks []Clock
+	add := func(start, end time.Time) {
+		start, end = start.In(loc).Truncate(time.Minute), end.In(loc).Truncate(time.Minute)
+		if end.After(start) {
+			clocks = append(clocks, Clock{Start: start, End: end})
+		}
+	}
+
+	from := s.StartTime
+	for _, p := range pauses {
+		add(from, p.PausedAt)
+		if p.ResumedAt == nil {
+			return clocks
+		}
+		from = *p.ResumedAt
+	}
+	add(from, s.EndTime)
+	return clocks
+}
+
+// heading is an org heading with the clocks of every session sharing its
+// title and tags
+type heading struct {
+	title  string
+	tags   []string
+	clocks []Clock
+}
+
+// Export writes finished pomodoros as an org document: a heading for each
+// distinct description and tags, with its time in CLOCK lines in a LOGBOOK
+// drawer, newest first, and a clocktable block that sums them when updated
+// in Emacs. Breaks and sessions still running are left out. pauses holds
+// each session's pauses by ID.
+func Export(sessions []db.PomodoroSession, pauses map[int64][]db.Pause) []byte {
+	var headings []*heading
+	byKey := map[string]*heading{}
+	for _, s := range sessions {
+		if s.WasBreak || s.Status == "" {
+			continue
+		}
+		clocks := Clocks(s, pauses[s.ID])
+		if len(clocks) == 0 {
+			continue
+		}
+
+		title := strings.TrimSpace(s.Description)
+		if title == "" {
+			title = "Pomodoro"
+		}
+		tags := orgTags(s.TagsCSV)
+		key := title + "\x00" + strings.Join(tags, ":")
+		h, ok := byKey[key]
+		if !ok {
+			h = &heading{title: title, tags: tags}
+			byKey[key] = h
+			headings = append(headings, h)
+		}
+		h.clocks = append(h.clocks, clocks...)
+	}
+
+	for _, h := range headings {
+		sort.Slice(h.clocks, func(i, j int) bool { return h.clocks[i].Start.After(h.clocks[j].Start) })
+	}
+	// Most recently worked on first, like the clocks within a heading
+	sort.SliceStable(headings, func(i, j int) bool {
+		return headings[i].clocks[0].Start.After(headings[j].clocks[0].Start)
+	})
+
+	var buf bytes.Buffer
+	buf.WriteString("#+TITLE: Pomodoro sessions\n\n")
+	buf.WriteString("#+BEGIN: clocktable :scope file :maxlevel 1\n#+END:\n")
+	for _, h := range headings {
+		buf.WriteString("\n" + headingLine(h.title, h.tags) + "\n")
+		buf.WriteString(":LOGBOOK:\n")
+		for _, c := range h.clocks {
+			buf.WriteString(c.String() + "\n")
+		}
+		buf.WriteString(":END:\n")
+	}
+	return buf.Bytes()
+}
+
+// headingLine formats a top-level heading, with its tags aligned right
+func headingLine(title string, tags []string) string {
+	line := "* " + title
+	if len(tags) == 0 {
+		return line
+	}
+	tagText := ":" + strings.Join(tags, ":") + ":"
+	gap := max(1, headingWidth-len([]rune(line))-len([]rune(tagText)))
+	return line + strings.Repeat(" ", gap) + tagText
+}
+
+// orgTags turns session tags into org tags, which allow only letters,
+// digits, and _@#%
+func orgTags(tagsCSV string) []string {
+	var tags []string
+	for _, tag := range strings.Split(tagsCSV, ",") {
+		if tag = tagChars.ReplaceAllString(strings.TrimSpace(tag), "_"); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
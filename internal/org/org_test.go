@@ -0,0 +1,60 @@
+package org
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func TestExport(t *testing.T) {
+	cest := time.FixedZone("CEST", 2*60*60)
+	at := func(day, hour, minute, second int) time.Time {
+		return time.Date(2024, 6, day, hour, minute, second, 0, cest)
+	}
+	session := func(id int64, start time.Time, length time.Duration, desc, tags string) db.PomodoroSession {
+		return db.PomodoroSession{
+			ID: id, StartTime: start, EndTime: start.Add(length), Description: desc, TagsCSV: tags,
+			TZOffset: 2 * 60 * 60, TZName: "CEST", Status: db.StatusCompleted,
+		}
+	}
+
+	report := session(1, at(3, 9, 0, 30), 25*time.Minute, "Write report", "writing,q2 report")
+	again := session(2, at(4, 14, 0, 0), 30*time.Minute, "Write report", "writing,q2 report")
+	brk := session(3, at(4, 14, 30, 0), 5*time.Minute, "Break", "")
+	brk.WasBreak = true
+	running := session(4, at(4, 15, 0, 0), 25*time.Minute, "Email", "")
+	running.Status = ""
+	untagged := session(5, at(3, 11, 0, 0), 25*time.Minute, "", "")
+
+	resumed := at(4, 14, 15, 0)
+	pauses := map[int64][]db.Pause{
+		2: {{PausedAt: at(4, 14, 10, 0), ResumedAt: &resumed}},
+	}
+
+	got := string(Export([]db.PomodoroSession{report, again, brk, running, untagged}, pauses))
+	want := `#+TITLE: Pomodoro sessions
+
+#+BEGIN: clocktable :scope file :maxlevel 1
+#+END:
+
+* Write report                                            :writing:q2_report:
+:LOGBOOK:
+CLOCK: [2024-06-04 Tue 14:15]--[2024-06-04 Tue 14:30] =>  0:15
+CLOCK: [2024-06-04 Tue 14:00]--[2024-06-04 Tue 14:10] =>  0:10
+CLOCK: [2024-06-03 Mon 09:00]--[2024-06-03 Mon 09:25] =>  0:25
+:END:
+
+* Pomodoro
+:LOGBOOK:
+CLOCK: [2024-06-03 Mon 11:00]--[2024-06-03 Mon 11:25] =>  0:25
+:END:
+`
+	if got != want {
+		t.Errorf("Export =\n%s\nwant\n%s", got, want)
+	}
+	if strings.Contains(got, "Break") || strings.Contains(got, "Email") {
+		t.Error("Expected breaks and running sessions to be left out")
+	}
+}
@@ -0,0 +1,67 @@
+// Package output writes what commands print with --json
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// SchemaVersion is the version of the JSON commands print, given as the
+// schema_version field of every JSON object they print. It goes up when a
+// field is removed or renamed or changes meaning; new fields are added
+// without changing it. Lists are printed as they are, their entries
+// following the same version.
+//
+// Version 2 gave sessions their tags as a list, under tags, and whether
+// they are breaks as was_break, in status as in history.
+const SchemaVersion = 2
+
+// JSON prints v to stdout as indented JSON
+func JSON(v any) error {
+	return Write(os.Stdout, v, true)
+}
+
+// JSONLine prints v to stdout as JSON on a single line, for output read a
+// line at a time
+func JSONLine(v any) error {
+	return Write(os.Stdout, v, false)
+}
+
+// Write writes v to w as JSON followed by a newline, indented if indent is
+// set. When v is an object, schema_version comes first in it.
+func Write(w io.Writer, v any, indent bool) error {
+	data, err := Marshal(v)
+	if err != nil {
+		return err
+	}
+	if indent {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, data, "", "  "); err != nil {
+			return err
+		}
+		data = buf.Bytes()
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// Marshal returns v as compact JSON, with schema_version first when v is an
+// object
+func Marshal(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 || data[0] != '{' {
+		return data, nil
+	}
+
+	version := fmt.Sprintf(`{"schema_version":%d`, SchemaVersion)
+	if string(data) == "{}" {
+		return []byte(version + "}"), nil
+	}
+	return append([]byte(version+","), data[1:]...), nil
+}
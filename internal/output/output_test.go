@@ -0,0 +1,37 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	session := struct {
+		ID          int64  `json:"id"`
+		Description string `json:"description"`
+	}{7, `Fix "quoted" \ bugs`}
+
+	for _, tc := range []struct {
+		name   string
+		v      any
+		indent bool
+		want   string
+	}{
+		{"object", session, false, `{"schema_version":2,"id":7,"description":"Fix \"quoted\" \\ bugs"}` + "\n"},
+		{"empty object", struct{}{}, false, `{"schema_version":2}` + "\n"},
+		{"list", []int{1, 2}, false, "[1,2]\n"},
+		{"indented", map[string]bool{"active": false}, true, "{\n  \"schema_version\": 2,\n  \"active\": false\n}\n"},
+	} {
+		var buf bytes.Buffer
+		if err := Write(&buf, tc.v, tc.indent); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if buf.String() != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, buf.String(), tc.want)
+		}
+		if !json.Valid(buf.Bytes()) {
+			t.Errorf("%s: invalid JSON %q", tc.name, buf.String())
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Package plan turns Markdown checklists into tasks and ticks their items
+// off as the work gets done
+package plan
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+	"github.com/ethan-k/pomodoro-cli/internal/utils"
+)
+
+// checklistItem matches a Markdown task list item: its bullet and box, the
+// mark in the box, and the text
+var checklistItem = regexp.MustCompile(`^(\s*[-*+]\s+\[)([ xX])(\]\s+)(.*?)\s*$`)
+
+// itemEstimate matches an estimate in pomodoros at the end of an item,
+// such as "Write report (3)"
+var itemEstimate = regexp.MustCompile(`\s*\((\d+)\)$`)
+
+// Item is a checklist item in a Markdown file
+type Item struct {
+	Line     int // 1-based line number
+	Title    string
+	Estimate int // Estimated pomodoros from a trailing "(N)", 0 when not given
+	Done     bool
+}
+
+// parseItem reads a checklist item from a line, reporting false for any
+// other line
+func parseItem(line string) (Item, bool) {
+	m := checklistItem.FindStringSubmatch(line)
+	if m == nil || m[4] == "" {
+		return Item{}, false
+	}
+	item := Item{Title: m[4], Done: m[2] != " "}
+	if e := itemEstimate.FindStringSubmatch(item.Title); e != nil {
+		item.Estimate, _ = strconv.Atoi(e[1])
+		item.Title = item.Title[:len(item.Title)-len(e[0])]
+	}
+	item.Title = utils.SanitizeDescription(item.Title)
+	return item, item.Title != ""
+}
+
+// ParseFile returns the checklist items in a Markdown file, in order
+func ParseFile(path string) ([]Item, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - the user names the file to plan from
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+
+	var items []Item
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		if item, ok := parseItem(scanner.Text()); ok {
+			item.Line = n
+			items = append(items, item)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	return items, nil
+}
+
+// CheckOff ticks the first open item with a title in a Markdown file,
+// leaving the rest of the file as it was. It reports whether an item was
+// found; one already ticked, or edited away, is not an error.
+func CheckOff(path, title string) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	data, err := os.ReadFile(path) // #nosec G304 - the path was planned from by the user
+	if err != nil {
+		return false, fmt.Errorf("error reading %s: %v", path, err)
+	}
+
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	for i, line := range lines {
+		text := strings.TrimRight(string(line), "\r\n")
+		item, ok := parseItem(text)
+		if !ok || item.Done || item.Title != title {
+			continue
+		}
+		m := checklistItem.FindStringSubmatchIndex(text)
+		lines[i] = append([]byte(text[:m[4]]+"x"+text[m[5]:]), line[len(text):]...)
+		if err := os.WriteFile(path, bytes.Join(lines, nil), info.Mode().Perm()); err != nil {
+			return false, fmt.Errorf("error writing %s: %v", path, err)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// SessionCompleted ticks off the planned task a completed session counts
+// toward once the task has all the pomodoros it was estimated at, one when
+// it has no estimate, and marks the task done. Sessions without a planned
+// task are left alone.
+func SessionCompleted(database db.DB, sessionID int64, now time.Time) error {
+	session, err := database.GetSessionByID(sessionID)
+	if err != nil || session == nil || session.TaskID == 0 {
+		return err
+	}
+	task, err := database.GetTask(session.TaskID)
+	if err != nil || task == nil || task.Source == "" || task.DoneAt != nil {
+		return err
+	}
+	if task.Pomodoros < max(1, task.Estimate) {
+		return nil
+	}
+
+	if _, err := CheckOff(task.Source, task.Title); err != nil {
+		return err
+	}
+	return database.CompleteTask(task.ID, now)
+}
@@ -0,0 +1,127 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+const todo = "# This week\r\n" +
+	"\r\n" +
+	"- [ ] Write report (3)\r\n" +
+	"- [x] Book flights\r\n" +
+	"  * [ ]   Review   PR #12\r\n" +
+	"- [ ] Write report (3)\r\n" +
+	"- not a task\r\n" +
+	"- [ ] \r\n"
+
+func writeTodo(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "TODO.md")
+	if err := os.WriteFile(path, []byte(todo), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseFile(t *testing.T) {
+	items, err := ParseFile(writeTodo(t))
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	want := []Item{
+		{Line: 3, Title: "Write report", Estimate: 3},
+		{Line: 4, Title: "Book flights", Done: true},
+		{Line: 5, Title: "Review PR #12"},
+		{Line: 6, Title: "Write report", Estimate: 3},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("got %d items %v, want %v", len(items), items, want)
+	}
+	for i := range want {
+		if items[i] != want[i] {
+			t.Errorf("item %d = %+v, want %+v", i, items[i], want[i])
+		}
+	}
+}
+
+func TestCheckOff(t *testing.T) {
+	path := writeTodo(t)
+
+	for _, want := range []string{
+		"- [x] Write report (3)\r\n- [x] Book flights\r\n",
+		"- [x] Write report (3)\r\n- not a task",
+	} {
+		found, err := CheckOff(path, "Write report")
+		if err != nil || !found {
+			t.Fatalf("CheckOff = %v, %v, want an item ticked", found, err)
+		}
+		data, _ := os.ReadFile(path)
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %q in the file, got %q", want, data)
+		}
+	}
+	if found, err := CheckOff(path, "Write report"); err != nil || found {
+		t.Errorf("CheckOff with every item ticked = %v, %v, want nothing found", found, err)
+	}
+
+	if found, _ := CheckOff(path, "Review PR #12"); !found {
+		t.Error("Expected the indented item to be ticked")
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "  * [x]   Review   PR #12\r\n") {
+		t.Errorf("Expected only the box to change, got %q", data)
+	}
+}
+
+func TestSessionCompleted(t *testing.T) {
+	database, err := db.NewDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = database.Close() }()
+	path := writeTodo(t)
+
+	task, err := database.AddTask("Write report", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.SetTaskSource(task, path); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	pomodoro := func(start time.Time) int64 {
+		t.Helper()
+		id, err := database.CreateSession(start, start.Add(25*time.Minute), "Write report", 25*60, "", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := database.SetSessionTask(id, task); err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+
+	if err := SessionCompleted(database, pomodoro(now.Add(-2*time.Hour)), now); err != nil {
+		t.Fatalf("SessionCompleted failed: %v", err)
+	}
+	if got, _ := database.GetTask(task); got.DoneAt != nil {
+		t.Fatal("Expected the task to stay open short of its estimate")
+	}
+
+	if err := SessionCompleted(database, pomodoro(now.Add(-time.Hour)), now); err != nil {
+		t.Fatalf("SessionCompleted failed: %v", err)
+	}
+	if got, _ := database.GetTask(task); got.DoneAt == nil {
+		t.Error("Expected the task to be done once it had its estimate")
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "- [x] Write report (3)\r\n- [x] Book flights") {
+		t.Errorf("Expected the item to be ticked, got %q", data)
+	}
+}
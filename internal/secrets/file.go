@@ -0,0 +1,239 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// PassphraseEnv names the environment variable whose passphrase, when set,
+// encrypts the secrets file instead of a key file beside it
+const PassphraseEnv = "POMODORO_SECRETS_PASSPHRASE"
+
+// Key derivation for the secrets file
+const (
+	kdfKeyFile    = "keyfile"       // A random key kept in path + ".key"
+	kdfPassphrase = "pbkdf2-sha256" // A key derived from PassphraseEnv
+	pbkdf2Rounds  = 600000
+	keySize       = 32 // AES-256
+)
+
+// fileFormat is the secrets file layout written by save
+const fileFormat = 1
+
+// encryptedFile is the secrets file: the secrets, as a JSON object of names
+// to values, sealed with AES-GCM
+type encryptedFile struct {
+	Format int    `json:"format"`
+	KDF    string `json:"kdf"`
+	Salt   []byte `json:"salt,omitempty"`
+	Nonce  []byte `json:"nonce"`
+	Data   []byte `json:"data"`
+}
+
+// fileStore keeps secrets in an encrypted file, for systems without a
+// keychain. Its key is derived from PassphraseEnv when set, and is
+// otherwise a random key in a file only the user can read, which keeps the
+// secrets out of the config file, backups of it, and dotfile repositories.
+type fileStore struct {
+	path string
+}
+
+func (f *fileStore) Set(name, value string) error {
+	secrets, kdf, err := f.load()
+	if err != nil {
+		return err
+	}
+	secrets[name] = value
+	return f.save(secrets, kdf)
+}
+
+func (f *fileStore) Get(name string) (string, error) {
+	secrets, _, err := f.load()
+	if err != nil {
+		return "", err
+	}
+	value, ok := secrets[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func (f *fileStore) Delete(name string) error {
+	secrets, kdf, err := f.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[name]; !ok {
+		return ErrNotFound
+	}
+	delete(secrets, name)
+	return f.save(secrets, kdf)
+}
+
+func (f *fileStore) Backend() string {
+	return BackendFile
+}
+
+// load decrypts the secrets file, returning no secrets when there is none
+// yet, and how its key is derived
+func (f *fileStore) load() (map[string]string, string, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		kdf := kdfKeyFile
+		if os.Getenv(PassphraseEnv) != "" {
+			kdf = kdfPassphrase
+		}
+		return map[string]string{}, kdf, nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("error reading secrets file: %v", err)
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, "", fmt.Errorf("invalid secrets file %s: %v", f.path, err)
+	}
+	if file.Format > fileFormat {
+		return nil, "", fmt.Errorf("secrets file %s was written by a newer version of pomodoro", f.path)
+	}
+	key, err := f.key(file.KDF, file.Salt, false)
+	if err != nil {
+		return nil, "", err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, "", err
+	}
+	plain, err := gcm.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		if file.KDF == kdfPassphrase {
+			return nil, "", fmt.Errorf("cannot decrypt %s: wrong %s", f.path, PassphraseEnv)
+		}
+		return nil, "", fmt.Errorf("cannot decrypt %s: its key file does not match", f.path)
+	}
+	secrets := map[string]string{}
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, "", fmt.Errorf("invalid secrets file %s: %v", f.path, err)
+	}
+	return secrets, file.KDF, nil
+}
+
+// save encrypts secrets into the secrets file with a fresh nonce, and for
+// a passphrase a fresh salt
+func (f *fileStore) save(secrets map[string]string, kdf string) error {
+	file := encryptedFile{Format: fileFormat, KDF: kdf}
+	if kdf == kdfPassphrase {
+		file.Salt = make([]byte, 16)
+		if _, err := rand.Read(file.Salt); err != nil {
+			return fmt.Errorf("error generating salt: %v", err)
+		}
+	}
+	key, err := f.key(kdf, file.Salt, true)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return fmt.Errorf("error encoding secrets: %v", err)
+	}
+	file.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return fmt.Errorf("error generating nonce: %v", err)
+	}
+	file.Data = gcm.Seal(nil, file.Nonce, plain, nil)
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding secrets file: %v", err)
+	}
+	return writePrivate(f.path, data)
+}
+
+// key returns the file's encryption key, creating the key file when create
+// is set and there is none
+func (f *fileStore) key(kdf string, salt []byte, create bool) ([]byte, error) {
+	switch kdf {
+	case kdfPassphrase:
+		passphrase := os.Getenv(PassphraseEnv)
+		if passphrase == "" {
+			return nil, fmt.Errorf("secrets file %s is protected by a passphrase; set %s", f.path, PassphraseEnv)
+		}
+		key, err := pbkdf2.Key(sha256.New, passphrase, salt, pbkdf2Rounds, keySize)
+		if err != nil {
+			return nil, fmt.Errorf("error deriving key: %v", err)
+		}
+		return key, nil
+	case kdfKeyFile:
+		keyPath := f.path + ".key"
+		key, err := os.ReadFile(keyPath)
+		if errors.Is(err, os.ErrNotExist) && create {
+			key = make([]byte, keySize)
+			if _, err := rand.Read(key); err != nil {
+				return nil, fmt.Errorf("error generating key: %v", err)
+			}
+			return key, writePrivate(keyPath, key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading secrets key: %v", err)
+		}
+		if len(key) != keySize {
+			return nil, fmt.Errorf("invalid secrets key %s", keyPath)
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("secrets file %s uses an unknown key derivation %q", f.path, kdf)
+}
+
+// newGCM returns AES-GCM with key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %v", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %v", err)
+	}
+	return gcm, nil
+}
+
+// writePrivate replaces path with data, readable only by the user, so a
+// crash cannot leave it half-written
+func writePrivate(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("error creating directory for %s: %v", path, err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-")
+	if err != nil {
+		return fmt.Errorf("error writing %s: %v", path, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if err := tmp.Chmod(0600); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("error writing %s: %v", path, err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("error writing %s: %v", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing %s: %v", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error writing %s: %v", path, err)
+	}
+	return nil
+}
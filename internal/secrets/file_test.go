@@ -0,0 +1,103 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	t.Setenv(PassphraseEnv, "")
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+	store, err := Open(BackendFile, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Get("slack.token"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected no secret yet, got %v", err)
+	}
+	if err := store.Set("slack.token", "xoxb-123"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("toggl.token", "abc"); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := store.Get("slack.token"); err != nil || value != "xoxb-123" {
+		t.Errorf("Expected the stored secret, got %q %v", value, err)
+	}
+
+	// The file holds no secret in plaintext, and only the user can read it
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "xoxb-123") || strings.Contains(string(data), "slack") {
+		t.Errorf("Expected the secrets file to be encrypted, got %s", data)
+	}
+	for _, p := range []string{path, path + ".key"} {
+		if info, err := os.Stat(p); err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("Expected %s to be private, got %v %v", p, info.Mode(), err)
+		}
+	}
+
+	if err := store.Delete("slack.token"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete("slack.token"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected a second delete to find nothing, got %v", err)
+	}
+	if value, err := store.Get("toggl.token"); err != nil || value != "abc" {
+		t.Errorf("Expected the other secret to be kept, got %q %v", value, err)
+	}
+
+	// Another key cannot decrypt the file
+	if err := os.WriteFile(path+".key", make([]byte, keySize), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get("toggl.token"); err == nil {
+		t.Error("Expected a wrong key to be refused")
+	}
+}
+
+func TestFileStorePassphrase(t *testing.T) {
+	t.Setenv(PassphraseEnv, "correct horse")
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+	store, err := Open(BackendFile, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("jira.token", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + ".key"); !os.IsNotExist(err) {
+		t.Errorf("Expected no key file with a passphrase, got %v", err)
+	}
+	if value, err := store.Get("jira.token"); err != nil || value != "secret" {
+		t.Errorf("Expected the stored secret, got %q %v", value, err)
+	}
+
+	t.Setenv(PassphraseEnv, "wrong")
+	if _, err := store.Get("jira.token"); err == nil || !strings.Contains(err.Error(), PassphraseEnv) {
+		t.Errorf("Expected a wrong passphrase to be refused, got %v", err)
+	}
+	t.Setenv(PassphraseEnv, "")
+	if _, err := store.Get("jira.token"); err == nil {
+		t.Error("Expected a missing passphrase to be refused")
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"slack.token", "toggl", "calendar-work_2"} {
+		if err := ValidateName(name); err != nil {
+			t.Errorf("ValidateName(%q) = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "Slack", probeName, "../x", strings.Repeat("a", 65)} {
+		if ValidateName(name) == nil {
+			t.Errorf("ValidateName(%q) accepted an invalid name", name)
+		}
+	}
+}
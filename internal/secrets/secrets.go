@@ -0,0 +1,121 @@
+// Package secrets keeps integration credentials, such as API tokens for
+// Slack, Toggl, Jira, or a calendar, out of the plaintext config file: in the
+// OS keychain where there is one, and otherwise in an encrypted file.
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/zalando/go-keyring"
+)
+
+// service is the name secrets are filed under in the OS keychain
+const service = "pomodoro-cli"
+
+// Backends a Store can use
+const (
+	BackendAuto    = "auto"    // The keychain when it works, else the file
+	BackendKeyring = "keyring" // The OS keychain only
+	BackendFile    = "file"    // The encrypted file only
+)
+
+// ErrNotFound is returned when no secret has the name asked for
+var ErrNotFound = errors.New("secret not found")
+
+// namePattern is what a secret name may look like, e.g. slack.token
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
+// Store holds named secrets
+type Store interface {
+	Set(name, value string) error
+	Get(name string) (string, error)
+	Delete(name string) error
+	// Backend names where the secrets are kept
+	Backend() string
+}
+
+// ValidBackend reports whether backend is one Open accepts
+func ValidBackend(backend string) bool {
+	return backend == BackendAuto || backend == BackendKeyring || backend == BackendFile
+}
+
+// ValidateName checks that name can name a secret
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q: use up to 64 lowercase letters, digits, '.', '-' and '_', such as slack.token", name)
+	}
+	return nil
+}
+
+// Open returns the store for backend. With BackendAuto the OS keychain is
+// used when one answers, as on a desktop, and the encrypted file at
+// filePath otherwise, as on a headless server.
+func Open(backend, filePath string) (Store, error) {
+	switch backend {
+	case BackendKeyring:
+		if err := probeKeyring(); err != nil {
+			return nil, fmt.Errorf("the OS keychain is not available: %v", err)
+		}
+		return keyringStore{}, nil
+	case BackendFile:
+		return &fileStore{path: filePath}, nil
+	case BackendAuto, "":
+		if probeKeyring() == nil {
+			return keyringStore{}, nil
+		}
+		return &fileStore{path: filePath}, nil
+	}
+	return nil, fmt.Errorf("invalid secrets backend %q: must be auto, keyring, or file", backend)
+}
+
+// probeName is looked up to check the keychain; no secret can have it
+const probeName = "(probe)"
+
+// probeKeyring checks that the OS keychain answers, by looking up a secret
+// that is never stored
+func probeKeyring() error {
+	_, err := keyring.Get(service, probeName)
+	if err == nil || errors.Is(err, keyring.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// keyringStore keeps secrets in the OS keychain: the macOS Keychain, the
+// Windows Credential Manager, or the Secret Service on Linux
+type keyringStore struct{}
+
+func (keyringStore) Set(name, value string) error {
+	if err := keyring.Set(service, name, value); err != nil {
+		return fmt.Errorf("error saving secret to the keychain: %v", err)
+	}
+	return nil
+}
+
+func (keyringStore) Get(name string) (string, error) {
+	value, err := keyring.Get(service, name)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("error reading secret from the keychain: %v", err)
+	}
+	return value, nil
+}
+
+func (keyringStore) Delete(name string) error {
+	err := keyring.Delete(service, name)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error deleting secret from the keychain: %v", err)
+	}
+	return nil
+}
+
+func (keyringStore) Backend() string {
+	return BackendKeyring
+}
@@ -0,0 +1,158 @@
+// Package shellinit writes the snippet shells source to print a one-line
+// daily summary when they open, and caches that line so opening a shell
+// does not wait on the database.
+package shellinit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Shells are the shells a snippet is written for
+var Shells = []string{"bash", "zsh", "fish"}
+
+// Function is a shorthand the snippet defines, running a pomodoro
+// subcommand with the arguments given
+type Function struct {
+	Name    string
+	Command string
+}
+
+// Functions are the shorthands the snippet defines
+var Functions = []Function{
+	{"pstart", "start"},
+	{"pbreak", "break"},
+	{"pstatus", "status"},
+	{"ppause", "pause"},
+	{"presume", "resume"},
+	{"pcancel", "cancel"},
+}
+
+// rcFiles are the files each shell loads the snippet from
+var rcFiles = map[string]string{
+	"bash": "~/.bashrc",
+	"zsh":  "~/.zshrc",
+	"fish": "~/.config/fish/config.fish",
+}
+
+// Script returns the snippet for shell, running executable. Sourced from an
+// interactive shell it prints the summary; it defines the functions either
+// way.
+func Script(shell, executable string) (string, error) {
+	var quoted string
+	switch shell {
+	case "bash", "zsh":
+		quoted = posixQuote(executable)
+	case "fish":
+		quoted = fishQuote(executable)
+	default:
+		return "", fmt.Errorf("unsupported shell %q: use %s", shell, strings.Join(Shells, ", "))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# pomodoro shell integration, loaded from %s with:\n", rcFiles[shell])
+	if shell == "fish" {
+		b.WriteString("#   pomodoro shell-init fish | source\n")
+	} else {
+		fmt.Fprintf(&b, "#   eval \"$(pomodoro shell-init %s)\"\n", shell)
+	}
+	for _, f := range Functions {
+		if shell == "fish" {
+			fmt.Fprintf(&b, "function %s; %s %s $argv; end\n", f.Name, quoted, f.Command)
+		} else {
+			fmt.Fprintf(&b, "%s() { %s %s \"$@\"; }\n", f.Name, quoted, f.Command)
+		}
+	}
+	if shell == "fish" {
+		fmt.Fprintf(&b, "if status is-interactive\n    %s shell-init --summary 2>/dev/null\nend\n", quoted)
+	} else {
+		fmt.Fprintf(&b, "case $- in *i*) %s shell-init --summary 2>/dev/null ;; esac\n", quoted)
+	}
+	return b.String(), nil
+}
+
+// posixQuote quotes s for bash and zsh
+func posixQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// fishQuote quotes s for fish, where a backslash escapes a quote or itself
+// inside single quotes
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// Summary is the cached summary line and what it was worked out from
+type Summary struct {
+	Line     string    `json:"line"`
+	Database string    `json:"database"`          // Session database the line counts
+	Day      string    `json:"day"`               // Local date (YYYY-MM-DD) the line is for
+	Until    time.Time `json:"until,omitzero"`    // End of the active session the line names
+	Modified time.Time `json:"modified,omitzero"` // Latest change to the files it was read from
+}
+
+// Fresh reports whether the summary still holds at now for database, whose
+// files were last changed at modified: the day has not turned, the session
+// it names has not ended, and nothing has been written since
+func (s Summary) Fresh(now time.Time, database string, modified time.Time) bool {
+	return s.Database == database &&
+		s.Day == now.Format("2006-01-02") &&
+		(s.Until.IsZero() || now.Before(s.Until)) &&
+		s.Modified.Equal(modified)
+}
+
+// CachePath returns the file the summary is cached in
+func CachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("error getting cache dir: %v", err)
+	}
+	return filepath.Join(dir, "pomodoro", "shell-summary.json"), nil
+}
+
+// Load reads the summary cached at path
+func Load(path string) (Summary, error) {
+	var s Summary
+	data, err := os.ReadFile(path) // #nosec G304 - our own cache file
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	return s, nil
+}
+
+// Save caches s at path, replacing what was there in one step so a shell
+// opening meanwhile never reads half a file
+func Save(path string, s Summary) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("error creating cache dir: %v", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("error writing %s: %v", tmp, err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Modified returns when the newest of files was last changed, passing over
+// those that do not exist. A SQLite database in WAL mode is written through
+// its -wal file, so both are given for it.
+func Modified(files ...string) time.Time {
+	var latest time.Time
+	for _, f := range files {
+		if info, err := os.Stat(f); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest
+}
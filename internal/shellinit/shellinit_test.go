@@ -0,0 +1,89 @@
+package shellinit
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScript(t *testing.T) {
+	for shell, want := range map[string]string{
+		"bash": `pstart() { '/opt/it'\''s/pomodoro' start "$@"; }`,
+		"zsh":  `case $- in *i*) '/opt/it'\''s/pomodoro' shell-init --summary 2>/dev/null ;; esac`,
+		"fish": `function pstart; '/opt/it\'s/pomodoro' start $argv; end`,
+	} {
+		script, err := Script(shell, "/opt/it's/pomodoro")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(script, want+"\n") {
+			t.Errorf("Expected the %s snippet to contain %q, got:\n%s", shell, want, script)
+		}
+		if path, err := exec.LookPath(shell); err == nil {
+			if out, err := exec.Command(path, "-n", "-c", script).CombinedOutput(); err != nil {
+				t.Errorf("Expected valid %s, got %v: %s", shell, err, out)
+			}
+		}
+	}
+
+	if _, err := Script("tcsh", "pomodoro"); err == nil {
+		t.Error("Expected an error for an unsupported shell")
+	}
+}
+
+func TestSummaryCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pomodoro", "shell-summary.json")
+	if _, err := Load(path); !os.IsNotExist(err) {
+		t.Errorf("Expected no cache yet, got %v", err)
+	}
+
+	now := time.Date(2026, time.March, 4, 14, 0, 0, 0, time.Local)
+	modified := now.Add(-time.Minute)
+	if err := Save(path, Summary{
+		Line:     "3/8 pomodoros today",
+		Database: "/data/history.db",
+		Day:      "2026-03-04",
+		Until:    now.Add(10 * time.Minute),
+		Modified: modified,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Line != "3/8 pomodoros today" || !s.Fresh(now, "/data/history.db", modified) {
+		t.Errorf("Expected the cached summary to be fresh, got %+v", s)
+	}
+
+	for name, fresh := range map[string]bool{
+		"session ended":    s.Fresh(now.Add(10*time.Minute), "/data/history.db", modified),
+		"database changed": s.Fresh(now, "/data/history.db", now),
+		"another database": s.Fresh(now, "/data/other.db", modified),
+		"another day":      s.Fresh(now.Add(-15*time.Hour), "/data/history.db", modified),
+	} {
+		if fresh {
+			t.Errorf("Expected the summary to be stale once %s", name)
+		}
+	}
+}
+
+func TestModified(t *testing.T) {
+	dir := t.TempDir()
+	older, newer := filepath.Join(dir, "history.db"), filepath.Join(dir, "history.db-wal")
+	for _, f := range []string{older, newer} {
+		if err := os.WriteFile(f, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := time.Now().Add(time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(newer, want, want); err != nil {
+		t.Fatal(err)
+	}
+	if got := Modified(older, newer, filepath.Join(dir, "missing")); !got.Equal(want) {
+		t.Errorf("Expected the newest change at %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,81 @@
+package stats
+
+import (
+	"slices"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+// stretchGap is how long after a pomodoro the next one may start and still
+// be part of the same stretch of work. A pomodoro followed by a longer gap
+// ended the stretch, and no break was expected of it.
+const stretchGap = time.Hour
+
+// BreakPolicy is the break the pomodoro cycle expects after each completed
+// pomodoro: a long one once LongInterval pomodoros have been completed
+// since the last long break (never when 0), and a short one otherwise
+type BreakPolicy struct {
+	Short        time.Duration
+	Long         time.Duration
+	LongInterval int
+}
+
+// BreakAdherence counts the breaks expected after completed pomodoros and
+// how many of them were skipped
+type BreakAdherence struct {
+	Expected int // Completed pomodoros followed by more work
+	Skipped  int // Of those, followed by another pomodoro before a break's length had passed
+}
+
+// SkipRate returns the share of expected breaks that were skipped
+func (a BreakAdherence) SkipRate() float64 {
+	if a.Expected == 0 {
+		return 0
+	}
+	return float64(a.Skipped) / float64(a.Expected)
+}
+
+// BreakSkips works through sessions in order, following the cycle as the
+// break command does, and counts the breaks expected after completed
+// pomodoros and those skipped. A break counts as taken when one was logged
+// or when the next pomodoro started at least the expected break's length
+// later. breakKinds holds the kind of each break, keyed by session ID, as
+// recorded in db.MetaBreakKind.
+func BreakSkips(sessions []db.PomodoroSession, breakKinds map[int64]string, p BreakPolicy) BreakAdherence {
+	ordered := slices.Clone(sessions)
+	slices.SortFunc(ordered, func(a, b db.PomodoroSession) int { return a.StartTime.Compare(b.StartTime) })
+
+	var a BreakAdherence
+	cycle := 0
+	for i, s := range ordered {
+		if s.WasBreak {
+			if breakKinds[s.ID] == db.BreakKindLong {
+				cycle = 0
+			}
+			continue
+		}
+		if s.IsPaused || s.Incomplete() {
+			continue
+		}
+		cycle++
+		if i+1 == len(ordered) {
+			break
+		}
+
+		expected := p.Short
+		if p.LongInterval > 0 && cycle >= p.LongInterval {
+			expected = p.Long
+		}
+		next := ordered[i+1]
+		gap := next.StartTime.Sub(s.EndTime)
+		if !next.WasBreak && gap > stretchGap {
+			continue
+		}
+		a.Expected++
+		if !next.WasBreak && gap < expected {
+			a.Skipped++
+		}
+	}
+	return a
+}
@@ -0,0 +1,48 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func TestBreakSkips(t *testing.T) {
+	start := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	var sessions []db.PomodoroSession
+	at := start
+	add := func(wasBreak bool, length time.Duration, status string) int64 {
+		id := int64(len(sessions) + 1)
+		sessions = append(sessions, db.PomodoroSession{ID: id, StartTime: at, EndTime: at.Add(length), WasBreak: wasBreak, Status: status})
+		at = at.Add(length)
+		return id
+	}
+	pomodoro := func() { add(false, 25*time.Minute, db.StatusCompleted) }
+	rest := func(d time.Duration) { at = at.Add(d) }
+
+	pomodoro()
+	add(true, 5*time.Minute, db.StatusCompleted) // Taken
+	pomodoro()
+	rest(time.Minute) // Skipped
+	pomodoro()
+	rest(6 * time.Minute) // Taken without a timer
+	pomodoro()
+	rest(6 * time.Minute) // A long break was due, so skipped
+	pomodoro()
+	long := add(true, 15*time.Minute, db.StatusCompleted) // Taken, and the cycle starts over
+	pomodoro()
+	rest(2 * time.Hour) // The end of a stretch of work, not a skipped break
+	add(false, 10*time.Minute, db.StatusCancelled)
+	pomodoro() // Cancelled pomodoros expect no break
+	rest(time.Minute)
+	pomodoro() // Skipped, then the last one expects nothing yet
+
+	policy := BreakPolicy{Short: 5 * time.Minute, Long: 15 * time.Minute, LongInterval: 4}
+	got := BreakSkips(sessions, map[int64]string{long: db.BreakKindLong}, policy)
+	if want := (BreakAdherence{Expected: 6, Skipped: 3}); got != want {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+	if rate := got.SkipRate(); rate != 0.5 {
+		t.Errorf("Expected half the breaks skipped, got %v", rate)
+	}
+}
@@ -0,0 +1,126 @@
+package stats
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+// Taxonomy is the set of categories a session can belong to, which of them
+// count as deep work, and the tag rules that pick a category for sessions
+// that were not given one
+type Taxonomy struct {
+	Names    []string
+	Deep     []string
+	TagRules map[string]string // Tag → category
+}
+
+// Valid reports whether name is one of the categories
+func (t Taxonomy) Valid(name string) bool {
+	return slices.Contains(t.Names, name)
+}
+
+// IsDeep reports whether a category counts as deep work
+func (t Taxonomy) IsDeep(name string) bool {
+	return slices.Contains(t.Deep, name)
+}
+
+// Categorize returns a session's category: the one it was given, if that is
+// still a category, or else the rule for the first of its tags that has one.
+// It returns "" for sessions neither route places.
+func (t Taxonomy) Categorize(s db.PomodoroSession, given string) string {
+	if t.Valid(given) {
+		return given
+	}
+	for _, tag := range strings.Split(s.TagsCSV, ",") {
+		if category := t.TagRules[strings.TrimSpace(tag)]; t.Valid(category) {
+			return category
+		}
+	}
+	return ""
+}
+
+// CategoryTotal is the focus time spent in one category
+type CategoryTotal struct {
+	Category  string
+	Deep      bool
+	Pomodoros int
+	Focus     time.Duration
+}
+
+// DeepWorkWeek splits a week's focus time into deep work, other categorized
+// work, and work with no category
+type DeepWorkWeek struct {
+	Start         time.Time // Monday the week starts on
+	Deep          time.Duration
+	Shallow       time.Duration
+	Uncategorized time.Duration
+}
+
+// Ratio returns the share of categorized focus time that was deep work
+func (w DeepWorkWeek) Ratio() float64 {
+	if w.Deep+w.Shallow == 0 {
+		return 0
+	}
+	return float64(w.Deep) / float64(w.Deep+w.Shallow)
+}
+
+// ByCategory totals the focus time of pomodoros by category, in taxonomy
+// order, and splits it into deep and shallow work week by week, oldest
+// first. given holds the categories sessions were started with, keyed by
+// session ID.
+func ByCategory(sessions []db.PomodoroSession, given map[int64]string, t Taxonomy, now time.Time) ([]CategoryTotal, []DeepWorkWeek) {
+	totals := make(map[string]*CategoryTotal)
+	weeks := make(map[time.Time]*DeepWorkWeek)
+
+	for _, s := range sessions {
+		if s.WasBreak {
+			continue
+		}
+		focus := s.EffectiveFocus(now)
+		category := t.Categorize(s, given[s.ID])
+
+		start := s.StartTime.In(s.Location())
+		day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+		monday := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		week := weeks[monday]
+		if week == nil {
+			week = &DeepWorkWeek{Start: monday}
+			weeks[monday] = week
+		}
+
+		switch {
+		case category == "":
+			week.Uncategorized += focus
+			continue
+		case t.IsDeep(category):
+			week.Deep += focus
+		default:
+			week.Shallow += focus
+		}
+
+		total := totals[category]
+		if total == nil {
+			total = &CategoryTotal{Category: category, Deep: t.IsDeep(category)}
+			totals[category] = total
+		}
+		total.Pomodoros++
+		total.Focus += focus
+	}
+
+	var byCategory []CategoryTotal
+	for _, name := range t.Names {
+		if total := totals[name]; total != nil {
+			byCategory = append(byCategory, *total)
+		}
+	}
+	byWeek := make([]DeepWorkWeek, 0, len(weeks))
+	for _, week := range weeks {
+		byWeek = append(byWeek, *week)
+	}
+	slices.SortFunc(byWeek, func(a, b DeepWorkWeek) int { return a.Start.Compare(b.Start) })
+
+	return byCategory, byWeek
+}
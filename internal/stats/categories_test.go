@@ -0,0 +1,59 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func TestByCategory(t *testing.T) {
+	taxonomy := Taxonomy{
+		Names:    []string{"deep", "shallow", "admin"},
+		Deep:     []string{"deep"},
+		TagRules: map[string]string{"coding": "deep", "email": "admin", "retired": "gone"},
+	}
+	monday := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	session := func(id int64, start time.Time, tags string, wasBreak bool) db.PomodoroSession {
+		return db.PomodoroSession{ID: id, StartTime: start, EndTime: start.Add(25 * time.Minute), TagsCSV: tags, WasBreak: wasBreak}
+	}
+	sessions := []db.PomodoroSession{
+		session(1, monday, "coding", false),
+		session(2, monday.Add(time.Hour), "email,coding", false), // First tag with a rule wins
+		session(3, monday.Add(2*time.Hour), "coding", false),     // Given a category
+		session(4, monday.Add(3*time.Hour), "retired", false),    // Rule for a category that no longer exists
+		session(5, monday.Add(4*time.Hour), "coding", true),      // Breaks do not count
+		session(6, monday.AddDate(0, 0, 8), "coding", false),     // The next week
+	}
+	given := map[int64]string{3: "shallow"}
+
+	categories, weeks := ByCategory(sessions, given, taxonomy, monday.AddDate(0, 1, 0))
+
+	want := []CategoryTotal{
+		{Category: "deep", Deep: true, Pomodoros: 2, Focus: 50 * time.Minute},
+		{Category: "shallow", Pomodoros: 1, Focus: 25 * time.Minute},
+		{Category: "admin", Pomodoros: 1, Focus: 25 * time.Minute},
+	}
+	if len(categories) != len(want) {
+		t.Fatalf("Expected %+v, got %+v", want, categories)
+	}
+	for i := range want {
+		if categories[i] != want[i] {
+			t.Errorf("Expected %+v, got %+v", want[i], categories[i])
+		}
+	}
+
+	if len(weeks) != 2 {
+		t.Fatalf("Expected 2 weeks, got %+v", weeks)
+	}
+	first := weeks[0]
+	if first.Deep != 25*time.Minute || first.Shallow != 50*time.Minute || first.Uncategorized != 25*time.Minute {
+		t.Errorf("Unexpected first week split: %+v", first)
+	}
+	if ratio := first.Ratio(); ratio < 0.333 || ratio > 0.334 {
+		t.Errorf("Expected a deep ratio of 1/3, got %v", ratio)
+	}
+	if !weeks[1].Start.Equal(time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)) || weeks[1].Ratio() != 1 {
+		t.Errorf("Unexpected second week: %+v", weeks[1])
+	}
+}
@@ -0,0 +1,153 @@
+// Package stats computes aggregate analytics over Pomodoro session history
+package stats
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+// MinEnergy and MaxEnergy bound the energy level scale
+const (
+	MinEnergy = 1
+	MaxEnergy = 5
+)
+
+// timeOfDay describes a bucket of hours used to group sessions
+type timeOfDay struct {
+	label     string
+	startHour int // inclusive
+	endHour   int // exclusive
+}
+
+// timesOfDay are the buckets sessions are grouped into, in display order
+var timesOfDay = []timeOfDay{
+	{label: "Morning", startHour: 5, endHour: 12},
+	{label: "Afternoon", startHour: 12, endHour: 17},
+	{label: "Evening", startHour: 17, endHour: 22},
+	{label: "Night", startHour: 22, endHour: 29}, // wraps past midnight to 05:00
+}
+
+// EnergyBucket summarizes energy and productivity for one time of day
+type EnergyBucket struct {
+	Label        string        `json:"label"`
+	Pomodoros    int           `json:"pomodoros"`
+	Rated        int           `json:"rated"`
+	AvgStart     float64       `json:"avg_energy_start"`
+	AvgEnd       float64       `json:"avg_energy_end"`
+	AvgFocus     time.Duration `json:"-"`
+	AvgFocusMins float64       `json:"avg_focus_minutes"`
+}
+
+// ValidEnergy reports whether level is on the energy scale
+func ValidEnergy(level int) bool {
+	return level >= MinEnergy && level <= MaxEnergy
+}
+
+// ParseEnergy parses and validates an energy level
+func ParseEnergy(s string) (int, bool) {
+	level, err := strconv.Atoi(s)
+	if err != nil || !ValidEnergy(level) {
+		return 0, false
+	}
+	return level, true
+}
+
+// EnergyByTimeOfDay groups pomodoros by time of day and averages the logged
+// energy levels and focus time in each group. Sessions without any energy
+// reading still count toward focus time so productivity stays comparable.
+func EnergyByTimeOfDay(sessions []db.PomodoroSession, startEnergy, endEnergy map[int64]string) []EnergyBucket {
+	type accumulator struct {
+		pomodoros, rated     int
+		startSum, startCount int
+		endSum, endCount     int
+		focus                time.Duration
+	}
+	acc := make([]accumulator, len(timesOfDay))
+
+	for _, s := range sessions {
+		if s.WasBreak {
+			continue
+		}
+		i := bucketIndex(s.StartTime.Hour())
+		a := &acc[i]
+		a.pomodoros++
+		a.focus += s.EndTime.Sub(s.StartTime) - time.Duration(s.TotalPausedDuration)*time.Second
+
+		rated := false
+		if level, ok := ParseEnergy(startEnergy[s.ID]); ok {
+			a.startSum += level
+			a.startCount++
+			rated = true
+		}
+		if level, ok := ParseEnergy(endEnergy[s.ID]); ok {
+			a.endSum += level
+			a.endCount++
+			rated = true
+		}
+		if rated {
+			a.rated++
+		}
+	}
+
+	buckets := make([]EnergyBucket, 0, len(timesOfDay))
+	for i, tod := range timesOfDay {
+		a := acc[i]
+		b := EnergyBucket{Label: tod.label, Pomodoros: a.pomodoros, Rated: a.rated}
+		if a.startCount > 0 {
+			b.AvgStart = float64(a.startSum) / float64(a.startCount)
+		}
+		if a.endCount > 0 {
+			b.AvgEnd = float64(a.endSum) / float64(a.endCount)
+		}
+		if a.pomodoros > 0 {
+			b.AvgFocus = a.focus / time.Duration(a.pomodoros)
+			b.AvgFocusMins = b.AvgFocus.Minutes()
+		}
+		buckets = append(buckets, b)
+	}
+
+	return buckets
+}
+
+// Energy is the mean of the starting and ending energy averages that were
+// logged, or 0 when neither was
+func (b EnergyBucket) Energy() float64 {
+	switch {
+	case b.AvgStart > 0 && b.AvgEnd > 0:
+		return (b.AvgStart + b.AvgEnd) / 2
+	case b.AvgStart > 0:
+		return b.AvgStart
+	default:
+		return b.AvgEnd
+	}
+}
+
+// PeakEnergy returns the time of day with the highest average energy, from
+// the starting and ending levels logged, or nil if no energy has been logged
+func PeakEnergy(buckets []EnergyBucket) *EnergyBucket {
+	var peak *EnergyBucket
+	for i := range buckets {
+		if buckets[i].Energy() == 0 {
+			continue
+		}
+		if peak == nil || buckets[i].Energy() > peak.Energy() {
+			peak = &buckets[i]
+		}
+	}
+	return peak
+}
+
+// bucketIndex maps an hour of the day to its time-of-day bucket
+func bucketIndex(hour int) int {
+	if hour < timesOfDay[0].startHour {
+		hour += 24
+	}
+	for i, tod := range timesOfDay {
+		if hour >= tod.startHour && hour < tod.endHour {
+			return i
+		}
+	}
+	return len(timesOfDay) - 1
+}
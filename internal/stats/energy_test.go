@@ -0,0 +1,34 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func TestPeakEnergy(t *testing.T) {
+	day := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	session := func(id int64, hour int) db.PomodoroSession {
+		start := day.Add(time.Duration(hour) * time.Hour)
+		return db.PomodoroSession{ID: id, StartTime: start, EndTime: start.Add(25 * time.Minute)}
+	}
+	sessions := []db.PomodoroSession{session(1, 9), session(2, 10), session(3, 14), session(4, 19)}
+
+	// Only end energy, as 'pomodoro energy N' logs it
+	buckets := EnergyByTimeOfDay(sessions, nil, map[int64]string{1: "2", 2: "3", 3: "4"})
+	peak := PeakEnergy(buckets)
+	if peak == nil || peak.Label != "Afternoon" {
+		t.Fatalf("Expected the afternoon to peak on end energy alone, got %+v", peak)
+	}
+
+	// Start and end levels are averaged together
+	buckets = EnergyByTimeOfDay(sessions, map[int64]string{1: "5", 4: "4"}, map[int64]string{1: "3", 3: "3"})
+	if peak := PeakEnergy(buckets); peak == nil || peak.Label != "Morning" || peak.Energy() != 4 {
+		t.Errorf("Expected the morning to peak at 4, got %+v", peak)
+	}
+
+	if peak := PeakEnergy(EnergyByTimeOfDay(sessions, nil, nil)); peak != nil {
+		t.Errorf("Expected no peak without energy logged, got %+v", peak)
+	}
+}
@@ -0,0 +1,145 @@
+package stats
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+// Ways history can total sessions
+const (
+	GroupDay     = "day"
+	GroupWeek    = "week"
+	GroupTag     = "tag"
+	GroupProject = "project" // The task a pomodoro counts toward
+)
+
+// Groupings lists every way to group, in the order they are documented
+var Groupings = []string{GroupDay, GroupWeek, GroupTag, GroupProject}
+
+// NoProject stands in for the project of pomodoros linked to no task
+const NoProject = "(no project)"
+
+// GroupTotal totals the finished pomodoros in one group
+type GroupTotal struct {
+	Key       string        // The day, or the Monday of the week, as YYYY-MM-DD; the tag; or the project
+	TaskID    int64         // The task a project group stands for, 0 for NoProject
+	Pomodoros int           // Pomodoros that ran to the end, were cancelled, or were abandoned
+	Completed int           // Pomodoros that ran their full planned length or were finished early
+	Focus     time.Duration // Time spent in the pomodoros, excluding pauses
+}
+
+// CompletionRate returns the share of the group's pomodoros that were
+// completed, from 0 to 1
+func (g GroupTotal) CompletionRate() float64 {
+	if g.Pomodoros == 0 {
+		return 0
+	}
+	return float64(g.Completed) / float64(g.Pomodoros)
+}
+
+// add counts a finished pomodoro toward the group
+func (g *GroupTotal) add(s db.PomodoroSession, now time.Time) {
+	focus := s.EffectiveFocus(now)
+	g.Pomodoros++
+	g.Focus += focus
+	if completed(s, focus) {
+		g.Completed++
+	}
+}
+
+// completed reports whether a pomodoro that ran for focus counts as
+// completed, as the stats queries decide it: by its status, or for sessions
+// recorded before statuses were, by having run its full planned length
+func completed(s db.PomodoroSession, focus time.Duration) bool {
+	if s.Status != "" {
+		return s.Status == db.StatusCompleted
+	}
+	return focus >= time.Duration(s.DurationSec-1)*time.Second
+}
+
+// finished reports whether a session is no longer running or paused
+func finished(s db.PomodoroSession, now time.Time) bool {
+	return !s.IsPaused && !s.EndTime.After(now)
+}
+
+// Total totals the finished pomodoros in sessions, leaving breaks and
+// sessions still running or paused out
+func Total(sessions []db.PomodoroSession, now time.Time) GroupTotal {
+	var total GroupTotal
+	for _, s := range sessions {
+		if !s.WasBreak && finished(s, now) {
+			total.add(s, now)
+		}
+	}
+	return total
+}
+
+// Group totals the finished pomodoros in sessions by day, week, tag, or
+// project. Days and weeks are those of each pomodoro's start as at gives
+// it, in the zone to report in, and come oldest first; tags and projects
+// come most focus time first. A pomodoro with several tags counts toward
+// each, and one with none toward Untagged.
+func Group(sessions []db.PomodoroSession, by string, at func(db.PomodoroSession) time.Time, now time.Time) ([]GroupTotal, error) {
+	if !slices.Contains(Groupings, by) {
+		return nil, fmt.Errorf("invalid grouping %q: must be day, week, tag, or project", by)
+	}
+
+	groups := make(map[string]*GroupTotal)
+	var keys []string
+	count := func(key string, taskID int64, s db.PomodoroSession) {
+		g := groups[key]
+		if g == nil {
+			g = &GroupTotal{Key: key, TaskID: taskID}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		g.add(s, now)
+	}
+
+	for _, s := range sessions {
+		if s.WasBreak || !finished(s, now) {
+			continue
+		}
+		switch by {
+		case GroupDay, GroupWeek:
+			start := at(s)
+			day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+			if by == GroupWeek {
+				day = day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+			}
+			count(day.Format("2006-01-02"), 0, s)
+		case GroupTag:
+			tags := sessionTags(s)
+			if len(tags) == 0 {
+				tags = []string{Untagged}
+			}
+			for _, tag := range tags {
+				count(tag, 0, s)
+			}
+		case GroupProject:
+			key := NoProject
+			if s.TaskID != 0 {
+				key = fmt.Sprintf("task %d", s.TaskID)
+			}
+			count(key, s.TaskID, s)
+		}
+	}
+
+	totals := make([]GroupTotal, 0, len(keys))
+	for _, key := range keys {
+		totals = append(totals, *groups[key])
+	}
+	if by == GroupDay || by == GroupWeek {
+		// The keys are dates, which sort as strings
+		slices.SortFunc(totals, func(a, b GroupTotal) int { return cmp.Compare(a.Key, b.Key) })
+	} else {
+		slices.SortStableFunc(totals, func(a, b GroupTotal) int {
+			return cmp.Or(cmp.Compare(b.Focus, a.Focus), cmp.Compare(a.Key, b.Key))
+		})
+	}
+	return totals, nil
+}
@@ -0,0 +1,69 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func TestGroup(t *testing.T) {
+	sunday := time.Date(2024, 6, 2, 23, 30, 0, 0, time.UTC)
+	session := func(days int, tags string, task int64, status string, focus time.Duration) db.PomodoroSession {
+		start := sunday.AddDate(0, 0, days)
+		return db.PomodoroSession{StartTime: start, EndTime: start.Add(focus), DurationSec: 25 * 60, TagsCSV: tags, TaskID: task, Status: status}
+	}
+	sessions := []db.PomodoroSession{
+		session(0, "coding", 3, db.StatusCompleted, 25*time.Minute),
+		session(1, "coding,Review", 3, db.StatusCancelled, 10*time.Minute),
+		session(1, "", 0, "", 25*time.Minute), // Before statuses: ran its full length
+		session(8, "review", 0, "", 20*time.Minute),
+		{StartTime: sunday, EndTime: sunday.Add(5 * time.Minute), WasBreak: true},
+		session(9, "coding", 3, "", 25*time.Minute), // Still running
+	}
+	now := sunday.AddDate(0, 0, 9)
+	// Report in a zone an hour ahead, which moves Sunday 23:30 to Monday
+	ahead := time.FixedZone("ahead", 3600)
+	at := func(s db.PomodoroSession) time.Time { return s.StartTime.In(ahead) }
+
+	for _, tc := range []struct {
+		by   string
+		want []GroupTotal
+	}{
+		{GroupDay, []GroupTotal{
+			{Key: "2024-06-03", Pomodoros: 1, Completed: 1, Focus: 25 * time.Minute},
+			{Key: "2024-06-04", Pomodoros: 2, Completed: 1, Focus: 35 * time.Minute},
+			{Key: "2024-06-11", Pomodoros: 1, Focus: 20 * time.Minute},
+		}},
+		{GroupWeek, []GroupTotal{
+			{Key: "2024-06-03", Pomodoros: 3, Completed: 2, Focus: 60 * time.Minute},
+			{Key: "2024-06-10", Pomodoros: 1, Focus: 20 * time.Minute},
+		}},
+		{GroupTag, []GroupTotal{
+			{Key: "coding", Pomodoros: 2, Completed: 1, Focus: 35 * time.Minute},
+			{Key: "review", Pomodoros: 2, Focus: 30 * time.Minute},
+			{Key: Untagged, Pomodoros: 1, Completed: 1, Focus: 25 * time.Minute},
+		}},
+		{GroupProject, []GroupTotal{
+			{Key: NoProject, Pomodoros: 2, Completed: 1, Focus: 45 * time.Minute},
+			{Key: "task 3", TaskID: 3, Pomodoros: 2, Completed: 1, Focus: 35 * time.Minute},
+		}},
+	} {
+		got, err := Group(sessions, tc.by, at, now)
+		if err != nil {
+			t.Fatalf("Group(%s) failed: %v", tc.by, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Group(%s) = %+v, want %+v", tc.by, got, tc.want)
+		}
+	}
+
+	if _, err := Group(sessions, "month", at, now); err == nil {
+		t.Error("Expected an invalid grouping to be refused")
+	}
+	total := Total(sessions, now)
+	if total.Pomodoros != 4 || total.Completed != 2 || total.CompletionRate() != 0.5 || total.Focus != 80*time.Minute {
+		t.Errorf("Unexpected total %+v", total)
+	}
+}
@@ -0,0 +1,60 @@
+package stats
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+// LocationTotal is the focus time spent at one location
+type LocationTotal struct {
+	Location  string
+	Pomodoros int
+	Completed int // Not cancelled or abandoned
+	Focus     time.Duration
+}
+
+// CompletionRate returns the share of the pomodoros not cancelled or abandoned
+func (l LocationTotal) CompletionRate() float64 {
+	if l.Pomodoros == 0 {
+		return 0
+	}
+	return float64(l.Completed) / float64(l.Pomodoros)
+}
+
+// ByLocation totals the focus time of pomodoros by where they ran, most
+// focus time first. locations holds the location recorded for each session,
+// keyed by session ID; pomodoros without one are left out.
+func ByLocation(sessions []db.PomodoroSession, locations map[int64]string, now time.Time) []LocationTotal {
+	totals := map[string]*LocationTotal{}
+	for _, s := range sessions {
+		location := locations[s.ID]
+		if s.WasBreak || location == "" {
+			continue
+		}
+		total := totals[location]
+		if total == nil {
+			total = &LocationTotal{Location: location}
+			totals[location] = total
+		}
+		total.Pomodoros++
+		if !s.Incomplete() {
+			total.Completed++
+		}
+		total.Focus += s.EffectiveFocus(now)
+	}
+
+	byLocation := make([]LocationTotal, 0, len(totals))
+	for _, total := range totals {
+		byLocation = append(byLocation, *total)
+	}
+	slices.SortFunc(byLocation, func(a, b LocationTotal) int {
+		if c := cmp.Compare(b.Focus, a.Focus); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Location, b.Location)
+	})
+	return byLocation
+}
@@ -0,0 +1,41 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func TestByLocation(t *testing.T) {
+	start := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	session := func(id int64, minutes int, status string, wasBreak bool) db.PomodoroSession {
+		s := start.Add(time.Duration(id) * time.Hour)
+		return db.PomodoroSession{ID: id, StartTime: s, EndTime: s.Add(time.Duration(minutes) * time.Minute), Status: status, WasBreak: wasBreak}
+	}
+	sessions := []db.PomodoroSession{
+		session(1, 25, db.StatusCompleted, false),
+		session(2, 10, db.StatusCancelled, false),
+		session(3, 50, db.StatusCompleted, false),
+		session(4, 25, db.StatusCompleted, false), // No location recorded
+		session(5, 5, db.StatusCompleted, true),   // Breaks do not count
+	}
+	locations := map[int64]string{1: "home", 2: "home", 3: "office", 5: "office"}
+
+	got := ByLocation(sessions, locations, start.AddDate(0, 0, 1))
+	want := []LocationTotal{
+		{Location: "office", Pomodoros: 1, Completed: 1, Focus: 50 * time.Minute},
+		{Location: "home", Pomodoros: 2, Completed: 1, Focus: 35 * time.Minute},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %+v, got %+v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected %+v, got %+v", want[i], got[i])
+		}
+	}
+	if rate := got[1].CompletionRate(); rate != 0.5 {
+		t.Errorf("Expected half the home pomodoros completed, got %.2f", rate)
+	}
+}
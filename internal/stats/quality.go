@@ -0,0 +1,179 @@
+package stats
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+// MinQuality and MaxQuality bound the focus quality scale
+const (
+	MinQuality = 1
+	MaxQuality = 5
+)
+
+// lengthBand is a range of planned pomodoro lengths ratings are grouped by
+type lengthBand struct {
+	label string
+	under time.Duration // exclusive upper bound, 0 for none
+}
+
+// lengthBands are the session lengths compared, in display order
+var lengthBands = []lengthBand{
+	{label: "under 20m", under: 20 * time.Minute},
+	{label: "20-29m", under: 30 * time.Minute},
+	{label: "30-44m", under: 45 * time.Minute},
+	{label: "45-59m", under: time.Hour},
+	{label: "60m+"},
+}
+
+// QualityGroup is the average focus quality of the rated pomodoros in a
+// group, such as a time of day
+type QualityGroup struct {
+	Label   string  `json:"label"`
+	Rated   int     `json:"rated"`
+	Average float64 `json:"average"` // 0 when none were rated
+}
+
+// QualityReport compares how pomodoros were rated by when they ran, how
+// long they were planned to be, their tags, and where they ran
+type QualityReport struct {
+	Rated       int            `json:"rated"`
+	Average     float64        `json:"average"`
+	ByTimeOfDay []QualityGroup `json:"by_time_of_day"`
+	ByLength    []QualityGroup `json:"by_length"`
+	ByTag       []QualityGroup `json:"by_tag"`      // Best rated first
+	ByLocation  []QualityGroup `json:"by_location"` // Best rated first; only pomodoros with a recorded location
+}
+
+// ParseQuality parses and validates a focus quality rating
+func ParseQuality(s string) (int, bool) {
+	rating, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || rating < MinQuality || rating > MaxQuality {
+		return 0, false
+	}
+	return rating, true
+}
+
+// qualitySum accumulates ratings for one group
+type qualitySum struct {
+	sum, count int
+}
+
+func (q *qualitySum) add(rating int) {
+	q.sum += rating
+	q.count++
+}
+
+func (q qualitySum) group(label string) QualityGroup {
+	g := QualityGroup{Label: label, Rated: q.count}
+	if q.count > 0 {
+		g.Average = float64(q.sum) / float64(q.count)
+	}
+	return g
+}
+
+// FocusQuality groups the rated pomodoros among sessions by time of day, in
+// the zone each was recorded in, by planned length, by tag, and by the
+// location recorded for them in locations, keyed by session ID. A pomodoro
+// counts toward each of its tags.
+func FocusQuality(sessions []db.PomodoroSession, ratings, locations map[int64]string) QualityReport {
+	var all qualitySum
+	byTime := make([]qualitySum, len(timesOfDay))
+	byLength := make([]qualitySum, len(lengthBands))
+	byTag := map[string]*qualitySum{}
+	byLocation := map[string]*qualitySum{}
+
+	for _, s := range sessions {
+		if s.WasBreak {
+			continue
+		}
+		rating, ok := ParseQuality(ratings[s.ID])
+		if !ok {
+			continue
+		}
+		all.add(rating)
+		byTime[bucketIndex(s.StartTime.In(s.Location()).Hour())].add(rating)
+		byLength[lengthBandIndex(time.Duration(s.DurationSec)*time.Second)].add(rating)
+
+		tags := sessionTags(s)
+		if len(tags) == 0 {
+			tags = []string{Untagged}
+		}
+		for _, tag := range tags {
+			if byTag[tag] == nil {
+				byTag[tag] = &qualitySum{}
+			}
+			byTag[tag].add(rating)
+		}
+
+		if location := locations[s.ID]; location != "" {
+			if byLocation[location] == nil {
+				byLocation[location] = &qualitySum{}
+			}
+			byLocation[location].add(rating)
+		}
+	}
+
+	report := QualityReport{Rated: all.count, Average: all.group("").Average}
+	for i, tod := range timesOfDay {
+		report.ByTimeOfDay = append(report.ByTimeOfDay, byTime[i].group(tod.label))
+	}
+	for i, band := range lengthBands {
+		report.ByLength = append(report.ByLength, byLength[i].group(band.label))
+	}
+	report.ByTag = bestRated(byTag)
+	report.ByLocation = bestRated(byLocation)
+	return report
+}
+
+// bestRated returns the groups in sums, keyed by label, best rated first
+func bestRated(sums map[string]*qualitySum) []QualityGroup {
+	groups := make([]QualityGroup, 0, len(sums))
+	for label, sum := range sums {
+		groups = append(groups, sum.group(label))
+	}
+	slices.SortFunc(groups, func(a, b QualityGroup) int {
+		if c := cmp.Compare(b.Average, a.Average); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Label, b.Label)
+	})
+	return groups
+}
+
+// BestLength returns the length band rated highest on average among those
+// with at least minRated ratings, or nil when fewer than two have enough
+// to compare
+func (r QualityReport) BestLength(minRated int) *QualityGroup {
+	var best *QualityGroup
+	compared := 0
+	for i := range r.ByLength {
+		g := &r.ByLength[i]
+		if g.Rated < minRated {
+			continue
+		}
+		compared++
+		if best == nil || g.Average > best.Average {
+			best = g
+		}
+	}
+	if compared < 2 {
+		return nil
+	}
+	return best
+}
+
+// lengthBandIndex maps a planned length to its length band
+func lengthBandIndex(d time.Duration) int {
+	for i, band := range lengthBands {
+		if band.under == 0 || d < band.under {
+			return i
+		}
+	}
+	return len(lengthBands) - 1
+}
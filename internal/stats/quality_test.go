@@ -0,0 +1,59 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func TestFocusQuality(t *testing.T) {
+	day := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	session := func(id int64, hour int, minutes int64, tags string) db.PomodoroSession {
+		start := day.Add(time.Duration(hour) * time.Hour)
+		return db.PomodoroSession{ID: id, StartTime: start, EndTime: start.Add(time.Duration(minutes) * time.Minute), DurationSec: minutes * 60, TagsCSV: tags}
+	}
+	sessions := []db.PomodoroSession{
+		session(1, 9, 25, "writing"),
+		session(2, 10, 50, "writing,coding"),
+		session(3, 14, 25, "coding"),
+		session(4, 15, 50, ""),
+		session(5, 16, 25, "coding"), // Not rated
+		{ID: 6, StartTime: day.Add(11 * time.Hour), DurationSec: 300, WasBreak: true},
+	}
+	ratings := map[int64]string{1: "4", 2: "5", 3: "2", 4: "3", 6: "5", 5: "nine"}
+
+	locations := map[int64]string{1: "office", 2: "home", 3: "office", 5: "home"}
+
+	r := FocusQuality(sessions, ratings, locations)
+	if r.Rated != 4 || r.Average != 3.5 {
+		t.Errorf("Expected 4 rated averaging 3.5, got %d averaging %.2f", r.Rated, r.Average)
+	}
+	if morning := r.ByTimeOfDay[0]; morning.Rated != 2 || morning.Average != 4.5 {
+		t.Errorf("Expected the morning to average 4.5 over 2, got %+v", morning)
+	}
+	if afternoon := r.ByTimeOfDay[1]; afternoon.Rated != 2 || afternoon.Average != 2.5 {
+		t.Errorf("Expected the afternoon to average 2.5 over 2, got %+v", afternoon)
+	}
+	if best := r.BestLength(2); best == nil || best.Label != "45-59m" || best.Average != 4 {
+		t.Errorf("Expected 45-59m pomodoros to be rated best, got %+v", best)
+	}
+	if best := r.BestLength(3); best != nil {
+		t.Errorf("Expected no length with 3 ratings, got %+v", best)
+	}
+
+	want := []QualityGroup{{"writing", 2, 4.5}, {"coding", 2, 3.5}, {Untagged, 1, 3}}
+	if len(r.ByTag) != len(want) {
+		t.Fatalf("Expected tags %+v, got %+v", want, r.ByTag)
+	}
+	for i := range want {
+		if r.ByTag[i] != want[i] {
+			t.Errorf("Expected tags %+v, got %+v", want, r.ByTag)
+			break
+		}
+	}
+
+	if want := []QualityGroup{{"home", 1, 5}, {"office", 2, 3}}; len(r.ByLocation) != 2 || r.ByLocation[0] != want[0] || r.ByLocation[1] != want[1] {
+		t.Errorf("Expected locations %+v, got %+v", want, r.ByLocation)
+	}
+}
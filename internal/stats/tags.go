@@ -0,0 +1,160 @@
+package stats
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+// Untagged stands in for the tags of pomodoros that have none
+const Untagged = "(untagged)"
+
+// TagPair is two tags that were used on the same pomodoros
+type TagPair struct {
+	Tags      [2]string // In alphabetical order
+	Pomodoros int       // Pomodoros with both tags
+	Share     float64   // Of the pomodoros with either tag, the share with both
+}
+
+// TagMonth is a month's focus time split by tag. A pomodoro with several
+// tags counts its focus time evenly toward each, so the shares add up to 1.
+type TagMonth struct {
+	Start time.Time // First day of the month
+	Focus map[string]time.Duration
+	Total time.Duration
+}
+
+// Share returns the share of the month's focus time spent on a tag
+func (m TagMonth) Share(tag string) float64 {
+	if m.Total == 0 {
+		return 0
+	}
+	return float64(m.Focus[tag]) / float64(m.Total)
+}
+
+// TagShift is how a tag's share of focus time changed between two months
+type TagShift struct {
+	Tag  string
+	From float64
+	To   float64
+}
+
+// sessionTags returns a pomodoro's distinct tags, lowercased
+func sessionTags(s db.PomodoroSession) []string {
+	var tags []string
+	for _, tag := range strings.Split(s.TagsCSV, ",") {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// TagPairs counts the pairs of tags used together on pomodoros, most
+// frequent first
+func TagPairs(sessions []db.PomodoroSession) []TagPair {
+	together := make(map[[2]string]int)
+	used := make(map[string]int)
+	for _, s := range sessions {
+		if s.WasBreak {
+			continue
+		}
+		tags := sessionTags(s)
+		slices.Sort(tags)
+		for i, a := range tags {
+			used[a]++
+			for _, b := range tags[i+1:] {
+				together[[2]string{a, b}]++
+			}
+		}
+	}
+
+	pairs := make([]TagPair, 0, len(together))
+	for tags, n := range together {
+		// Pomodoros with either tag: those with each, less those counted twice
+		either := used[tags[0]] + used[tags[1]] - n
+		pairs = append(pairs, TagPair{Tags: tags, Pomodoros: n, Share: float64(n) / float64(either)})
+	}
+	slices.SortFunc(pairs, func(a, b TagPair) int {
+		if c := cmp.Compare(b.Pomodoros, a.Pomodoros); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(b.Share, a.Share); c != 0 {
+			return c
+		}
+		return cmp.Or(strings.Compare(a.Tags[0], b.Tags[0]), strings.Compare(a.Tags[1], b.Tags[1]))
+	})
+	return pairs
+}
+
+// TagMixByMonth splits the focus time of pomodoros by tag month by month,
+// oldest first, counting pomodoros without tags as Untagged. Months without
+// pomodoros are left out.
+func TagMixByMonth(sessions []db.PomodoroSession, now time.Time) []TagMonth {
+	months := make(map[time.Time]*TagMonth)
+	for _, s := range sessions {
+		if s.WasBreak {
+			continue
+		}
+		focus := s.EffectiveFocus(now)
+		if focus <= 0 {
+			continue
+		}
+
+		start := s.StartTime.In(s.Location())
+		first := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+		month := months[first]
+		if month == nil {
+			month = &TagMonth{Start: first, Focus: make(map[string]time.Duration)}
+			months[first] = month
+		}
+
+		tags := sessionTags(s)
+		if len(tags) == 0 {
+			tags = []string{Untagged}
+		}
+		for _, tag := range tags {
+			month.Focus[tag] += focus / time.Duration(len(tags))
+		}
+		month.Total += focus
+	}
+
+	byMonth := make([]TagMonth, 0, len(months))
+	for _, month := range months {
+		byMonth = append(byMonth, *month)
+	}
+	slices.SortFunc(byMonth, func(a, b TagMonth) int { return a.Start.Compare(b.Start) })
+	return byMonth
+}
+
+// TagDrift compares each tag's share of focus time in the first and last of
+// months, largest change first. Tags whose share did not change are left
+// out, as is everything when there are fewer than two months.
+func TagDrift(months []TagMonth) []TagShift {
+	if len(months) < 2 {
+		return nil
+	}
+	first, last := months[0], months[len(months)-1]
+
+	var shifts []TagShift
+	seen := make(map[string]bool)
+	for _, month := range []TagMonth{first, last} {
+		for tag := range month.Focus {
+			if seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			if shift := (TagShift{Tag: tag, From: first.Share(tag), To: last.Share(tag)}); shift.From != shift.To {
+				shifts = append(shifts, shift)
+			}
+		}
+	}
+	slices.SortFunc(shifts, func(a, b TagShift) int {
+		return cmp.Or(cmp.Compare(math.Abs(b.To-b.From), math.Abs(a.To-a.From)), strings.Compare(a.Tag, b.Tag))
+	})
+	return shifts
+}
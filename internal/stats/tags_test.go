@@ -0,0 +1,60 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethan-k/pomodoro-cli/internal/db"
+)
+
+func TestTagAnalysis(t *testing.T) {
+	may := time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC)
+	june := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	session := func(start time.Time, tags string, wasBreak bool) db.PomodoroSession {
+		return db.PomodoroSession{StartTime: start, EndTime: start.Add(25 * time.Minute), DurationSec: 1500, TagsCSV: tags, WasBreak: wasBreak}
+	}
+	sessions := []db.PomodoroSession{
+		session(may, "project,backend", false),
+		session(may.Add(time.Hour), "project", false),
+		session(may.Add(2*time.Hour), "maintenance", false),
+		session(may.Add(3*time.Hour), "", false),
+		session(june, "maintenance,backend", false),
+		session(june.Add(time.Hour), "Maintenance", false),
+		session(june.Add(2*time.Hour), "project,backend", false),
+		session(june.Add(3*time.Hour), "maintenance,backend", false),
+		session(june.Add(4*time.Hour), "maintenance,backend", true), // Breaks do not count
+	}
+
+	pairs := TagPairs(sessions)
+	if len(pairs) != 2 {
+		t.Fatalf("Expected 2 pairs, got %+v", pairs)
+	}
+	// backend (4) and project (3) share 2 of 5 pomodoros; backend and
+	// maintenance (4) share 2 of 6
+	if p := pairs[0]; p.Tags != [2]string{"backend", "project"} || p.Pomodoros != 2 || p.Share != 0.4 {
+		t.Errorf("Expected backend and project first, got %+v", p)
+	}
+	if p := pairs[1]; p.Tags != [2]string{"backend", "maintenance"} || p.Pomodoros != 2 {
+		t.Errorf("Expected backend and maintenance second, got %+v", p)
+	}
+
+	months := TagMixByMonth(sessions, june.AddDate(0, 1, 0))
+	if len(months) != 2 || !months[0].Start.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Expected May and June, got %+v", months)
+	}
+	if months[0].Total != 100*time.Minute || months[0].Focus["project"] != 37*time.Minute+30*time.Second || months[0].Focus[Untagged] != 25*time.Minute {
+		t.Errorf("Expected May's focus split by tag, got %+v", months[0])
+	}
+	if share := months[1].Share("maintenance"); share != 0.5 {
+		t.Errorf("Expected maintenance to be half of June, got %v", share)
+	}
+
+	// Every tag moved by a quarter, so they come in alphabetical order
+	drift := TagDrift(months)
+	if len(drift) != 4 || drift[2].Tag != "maintenance" || drift[2].From != 0.25 || drift[2].To != 0.5 {
+		t.Errorf("Expected maintenance to grow from a quarter to half, got %+v", drift)
+	}
+	if TagDrift(months[:1]) != nil {
+		t.Error("Expected no drift within a single month")
+	}
+}
@@ -0,0 +1,72 @@
+package term
+
+import (
+	"hash/fnv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// tagPalette holds distinguishable colors for tags, with hand-picked
+// fallbacks for 256- and 16-color terminals so tags stay distinct there too
+var tagPalette = []lipgloss.CompleteColor{
+	{TrueColor: "#E06C75", ANSI256: "168", ANSI: "9"},  // red
+	{TrueColor: "#98C379", ANSI256: "114", ANSI: "10"}, // green
+	{TrueColor: "#E5C07B", ANSI256: "180", ANSI: "11"}, // yellow
+	{TrueColor: "#61AFEF", ANSI256: "75", ANSI: "12"},  // blue
+	{TrueColor: "#C678DD", ANSI256: "176", ANSI: "13"}, // magenta
+	{TrueColor: "#56B6C2", ANSI256: "73", ANSI: "14"},  // cyan
+	{TrueColor: "#D19A66", ANSI256: "173", ANSI: "3"},  // orange
+	{TrueColor: "#7EC8A0", ANSI256: "79", ANSI: "2"},   // teal
+	{TrueColor: "#9DA5F4", ANSI256: "147", ANSI: "4"},  // periwinkle
+	{TrueColor: "#F49AC2", ANSI256: "218", ANSI: "5"},  // pink
+	{TrueColor: "#B5CEA8", ANSI256: "151", ANSI: "6"},  // sage
+	{TrueColor: "#DCDFE4", ANSI256: "253", ANSI: "7"},  // light gray
+}
+
+// tagColors holds colors configured for specific tags, keyed by lowercase tag
+var tagColors = map[string]lipgloss.TerminalColor{}
+
+// SetTagColors overrides the colors of specific tags. Values are hex colors
+// ("#ff8800") or ANSI color numbers ("208").
+func SetTagColors(colors map[string]string) {
+	tagColors = make(map[string]lipgloss.TerminalColor, len(colors))
+	for tag, color := range colors {
+		tagColors[strings.ToLower(tag)] = lipgloss.Color(color)
+	}
+}
+
+// TagColor returns the color for a tag: the configured override, or a color
+// picked from the palette by hashing the tag so it is stable across runs
+func TagColor(tag string) lipgloss.TerminalColor {
+	key := strings.ToLower(strings.TrimSpace(tag))
+	if color, ok := tagColors[key]; ok {
+		return color
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return tagPalette[h.Sum32()%uint32(len(tagPalette))]
+}
+
+// Tag renders a tag in its color
+func Tag(tag string) string {
+	return lipgloss.NewStyle().Foreground(TagColor(tag)).Render(tag)
+}
+
+// Tags renders a comma-separated tag list with each tag in its color
+func Tags(tagsCSV string) string {
+	if tagsCSV == "" {
+		return ""
+	}
+	tags := strings.Split(tagsCSV, ",")
+	for i, tag := range tags {
+		tags[i] = Tag(strings.TrimSpace(tag))
+	}
+	return strings.Join(tags, ",")
+}
+
+// TagSwatch renders a colored block followed by the tag, for legends
+func TagSwatch(tag string) string {
+	return lipgloss.NewStyle().Foreground(TagColor(tag)).Render("■") + " " + tag
+}
@@ -0,0 +1,20 @@
+package term
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestTagColor(t *testing.T) {
+	SetTagColors(nil)
+	if TagColor("coding") != TagColor("Coding ") {
+		t.Error("Expected tag colors to ignore case and surrounding space")
+	}
+
+	SetTagColors(map[string]string{"Coding": "#ff8800"})
+	defer SetTagColors(nil)
+	if got := TagColor("coding"); got != lipgloss.Color("#ff8800") {
+		t.Errorf("Expected configured color #ff8800, got %v", got)
+	}
+}
@@ -0,0 +1,156 @@
+// Package term detects terminal capabilities so output can adapt to pipes,
+// redirects, and user preferences such as NO_COLOR
+package term
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/charmbracelet/lipgloss"
+	xterm "github.com/charmbracelet/x/term"
+	"github.com/muesli/termenv"
+)
+
+// Color modes accepted by the display.color setting
+const (
+	ColorAuto      = "auto"      // Detect from the terminal and environment
+	ColorAlways    = "always"    // Color even when output is not a terminal
+	ColorNever     = "never"     // Plain output
+	Color16        = "16"        // Basic ANSI palette
+	Color256       = "256"       // Extended 256-color palette
+	ColorTrueColor = "truecolor" // 24-bit color
+)
+
+// colorMode is the active color mode, set from configuration at startup
+var colorMode = ColorAuto
+
+// SetColorMode sets the color mode and applies it to lipgloss styles.
+// An empty mode is treated as auto.
+func SetColorMode(mode string) error {
+	switch mode {
+	case "":
+		mode = ColorAuto
+	case ColorAuto, ColorAlways, ColorNever, Color16, Color256, ColorTrueColor:
+	default:
+		return fmt.Errorf("invalid color mode %q: must be auto, always, never, 16, 256, or truecolor", mode)
+	}
+
+	colorMode = mode
+	lipgloss.SetColorProfile(ColorProfile())
+	return nil
+}
+
+// IsTerminal reports whether f is connected to a terminal
+func IsTerminal(f *os.File) bool {
+	stat, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return (stat.Mode() & os.ModeCharDevice) != 0
+}
+
+// ReadPassword reads a line from the terminal f without echoing it
+func ReadPassword(f *os.File) (string, error) {
+	b, err := xterm.ReadPassword(f.Fd())
+	return string(b), err
+}
+
+// NoColor reports whether the user disabled color via NO_COLOR (https://no-color.org/)
+func NoColor() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// ForceColor reports whether the user forced color via CLICOLOR_FORCE or FORCE_COLOR
+func ForceColor() bool {
+	for _, key := range []string{"CLICOLOR_FORCE", "FORCE_COLOR"} {
+		if v := os.Getenv(key); v != "" && v != "0" {
+			return true
+		}
+	}
+	return false
+}
+
+// ColorEnabled reports whether colored output should be written to stdout.
+// An explicit display.color setting wins; in auto mode NO_COLOR disables
+// color, CLICOLOR_FORCE enables it, and otherwise stdout must be a terminal.
+func ColorEnabled() bool {
+	switch colorMode {
+	case ColorNever:
+		return false
+	case ColorAlways, Color16, Color256, ColorTrueColor:
+		return true
+	}
+
+	if NoColor() {
+		return false
+	}
+	return ForceColor() || IsTerminal(os.Stdout)
+}
+
+// EmojiEnabled reports whether emoji should be written to stdout.
+// Emoji are plain output for pipes, NO_COLOR, and consoles that cannot
+// render them, regardless of forced color.
+func EmojiEnabled() bool {
+	return !NoColor() && IsTerminal(os.Stdout) && unicodeConsole()
+}
+
+// unicodeConsole reports whether the console can render emoji. The legacy
+// Windows console host cannot; Windows Terminal, VS Code, and ConEmu can.
+func unicodeConsole() bool {
+	if runtime.GOOS != "windows" {
+		return true
+	}
+	return os.Getenv("WT_SESSION") != "" ||
+		os.Getenv("TERM_PROGRAM") != "" ||
+		os.Getenv("ConEmuANSI") == "ON"
+}
+
+// EnableVirtualTerminal turns on ANSI escape processing for stdout so colors
+// and the progress bar render in Windows consoles. It is a no-op elsewhere.
+func EnableVirtualTerminal() {
+	if !IsTerminal(os.Stdout) {
+		return
+	}
+	// Leave the mode enabled for the lifetime of the process, since styled
+	// output can be written at any point until exit
+	_, _ = termenv.EnableVirtualTerminalProcessing(termenv.NewOutput(os.Stdout))
+}
+
+// Emoji returns emoji when emoji are enabled, otherwise the plain-text fallback
+func Emoji(emoji, fallback string) string {
+	if EmojiEnabled() {
+		return emoji
+	}
+	return fallback
+}
+
+// ColorProfile returns the color profile to use for styled output on stdout
+func ColorProfile() termenv.Profile {
+	if !ColorEnabled() {
+		return termenv.Ascii
+	}
+
+	switch colorMode {
+	case Color16:
+		return termenv.ANSI
+	case Color256:
+		return termenv.ANSI256
+	case ColorTrueColor:
+		return termenv.TrueColor
+	}
+
+	// Forced color on a pipe detects as Ascii; fall back to the basic palette
+	profile := termenv.NewOutput(os.Stdout).EnvColorProfile()
+	if profile == termenv.Ascii {
+		return termenv.ANSI
+	}
+	return profile
+}
+
+// SupportsGradients reports whether the color profile can render smooth
+// gradients; 16-color terminals should use solid colors instead
+func SupportsGradients() bool {
+	profile := ColorProfile()
+	return profile == termenv.ANSI256 || profile == termenv.TrueColor
+}
@@ -0,0 +1,154 @@
+// Package trace times what a command spends its time on, such as database
+// queries and rendering output, for --profile-cmd and the slow log. Until
+// Start is called nothing is recorded and spans cost next to nothing.
+package trace
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Kinds of span
+const (
+	Query  = "query"  // A database statement, including stepping through its rows
+	Render = "render" // Formatting and printing a command's output
+	Wait   = "wait"   // Waiting on the user, as in the timer UI or a prompt
+)
+
+// maxSpans bounds how many spans are kept, so tracing a bulk import or a
+// timer left running for hours stays small; later ones are only counted
+const maxSpans = 10000
+
+// Span is one timed piece of work
+type Span struct {
+	Kind     string
+	Name     string
+	Start    time.Duration // Since the trace started
+	Duration time.Duration
+}
+
+// Totals sums the spans of one kind
+type Totals struct {
+	Count    int
+	Duration time.Duration
+}
+
+var (
+	mu      sync.Mutex
+	enabled bool
+	began   time.Time
+	spans   []Span
+	totals  map[string]Totals
+)
+
+// Start begins recording spans, timed from at, discarding any recorded
+// before
+func Start(at time.Time) {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = true
+	began = at
+	spans = nil
+	totals = map[string]Totals{}
+}
+
+// Enabled reports whether spans are being recorded
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return enabled
+}
+
+// Begin starts a span and returns the function that ends it. Ending it more
+// than once records it once, so it can be both deferred and ended early.
+func Begin(kind, name string) func() {
+	if !Enabled() {
+		return func() {}
+	}
+	start := time.Now()
+	var once sync.Once
+	return func() {
+		once.Do(func() { Add(kind, name, start, time.Since(start)) })
+	}
+}
+
+// Add records a span that started at start and took d
+func Add(kind, name string, start time.Time, d time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	if !enabled {
+		return
+	}
+	t := totals[kind]
+	t.Count++
+	t.Duration += d
+	totals[kind] = t
+	if len(spans) < maxSpans {
+		spans = append(spans, Span{Kind: kind, Name: name, Start: start.Sub(began), Duration: d})
+	}
+}
+
+// Spans returns the spans recorded, in the order they ended
+func Spans() []Span {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]Span(nil), spans...)
+}
+
+// Total returns the count and time of the spans of kind, including any past
+// those kept
+func Total(kind string) Totals {
+	mu.Lock()
+	defer mu.Unlock()
+	return totals[kind]
+}
+
+// Slowest returns up to n of the slowest spans of kind, slowest first
+func Slowest(kind string, n int) []Span {
+	var found []Span
+	for _, s := range Spans() {
+		if s.Kind == kind {
+			found = append(found, s)
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Duration > found[j].Duration })
+	return found[:min(n, len(found))]
+}
+
+// chromeEvent is a complete event in the Chrome trace format, which
+// chrome://tracing and ui.perfetto.dev open
+type chromeEvent struct {
+	Name     string `json:"name"`
+	Category string `json:"cat"`
+	Phase    string `json:"ph"`
+	Start    int64  `json:"ts"`  // Microseconds
+	Duration int64  `json:"dur"` // Microseconds
+	PID      int    `json:"pid"`
+	TID      int    `json:"tid"`
+}
+
+// WriteChrome writes the spans, under a span named command covering total,
+// in the Chrome trace format
+func WriteChrome(w io.Writer, command string, total time.Duration) error {
+	events := []chromeEvent{{Name: command, Category: "command", Phase: "X", Duration: total.Microseconds(), PID: 1, TID: 1}}
+	for _, s := range Spans() {
+		events = append(events, chromeEvent{
+			Name:     s.Name,
+			Category: s.Kind,
+			Phase:    "X",
+			Start:    s.Start.Microseconds(),
+			Duration: s.Duration.Microseconds(),
+			PID:      1,
+			TID:      1,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		TraceEvents     []chromeEvent `json:"traceEvents"`
+		DisplayTimeUnit string        `json:"displayTimeUnit"`
+	}{events, "ms"})
+}
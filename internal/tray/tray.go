@@ -0,0 +1,40 @@
+// Package tray shows the timer in the system tray: the time left in the
+// tooltip, with a menu to start, pause, resume, or cancel
+package tray
+
+import "errors"
+
+// ErrUnsupported is returned by New on platforms without a tray
+// implementation
+var ErrUnsupported = errors.New("the system tray is not supported on this platform")
+
+// MenuItem is one entry in the tray menu
+type MenuItem struct {
+	ID      string // Sent on Clicked when the entry is chosen
+	Label   string
+	Enabled bool
+}
+
+// State is what the tray shows
+type State struct {
+	Icon    string // Freedesktop icon name
+	Tooltip string // First line of the tooltip
+	Detail  string // Rest of the tooltip, such as the time left
+	Menu    []MenuItem
+}
+
+// Tray is an icon in the system tray
+type Tray interface {
+	// Update shows state, telling the tray host only about what changed
+	Update(state State) error
+	// Clicked delivers the ID of each menu entry chosen
+	Clicked() <-chan string
+	// Close removes the icon
+	Close() error
+}
+
+// New shows an icon named id in the system tray. Where the platform has no
+// tray implementation it returns ErrUnsupported.
+func New(id string) (Tray, error) {
+	return newTray(id)
+}
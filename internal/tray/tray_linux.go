@@ -0,0 +1,289 @@
+package tray
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/godbus/dbus/v5/prop"
+)
+
+// D-Bus names of a StatusNotifierItem, the tray icon KDE, GNOME with the
+// AppIndicator extension, and most panels show, and of its dbusmenu
+const (
+	itemInterface    = "org.kde.StatusNotifierItem"
+	itemPath         = dbus.ObjectPath("/StatusNotifierItem")
+	watcherName      = "org.kde.StatusNotifierWatcher"
+	watcherPath      = dbus.ObjectPath("/StatusNotifierWatcher")
+	menuInterface    = "com.canonical.dbusmenu"
+	menuPath         = dbus.ObjectPath("/MenuBar")
+	menuVersion      = uint32(3)
+	menuRootID       = int32(0)
+	clickedEventName = "clicked"
+)
+
+// toolTip is the StatusNotifierItem ToolTip property, (sa(iiay)ss)
+type toolTip struct {
+	IconName   string
+	IconPixmap []iconPixmap
+	Title      string
+	Text       string
+}
+
+// iconPixmap is an ARGB icon, unused as icons are given by name
+type iconPixmap struct {
+	Width  int32
+	Height int32
+	Data   []byte
+}
+
+// menuLayout is a dbusmenu entry with its children, (ia{sv}av)
+type menuLayout struct {
+	ID         int32
+	Properties map[string]dbus.Variant
+	Children   []dbus.Variant
+}
+
+// menuProperties is one entry's properties, (ia{sv})
+type menuProperties struct {
+	ID         int32
+	Properties map[string]dbus.Variant
+}
+
+// menuEvent is one event sent with EventGroup, (isvu)
+type menuEvent struct {
+	ID        int32
+	EventID   string
+	Data      dbus.Variant
+	Timestamp uint32
+}
+
+// sniTray shows the timer as a StatusNotifierItem on the session bus
+type sniTray struct {
+	conn    *dbus.Conn
+	props   *prop.Properties
+	clicked chan string
+
+	mu       sync.Mutex
+	state    State
+	revision uint32
+}
+
+func newTray(id string) (Tray, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to the session bus: %v", err)
+	}
+	t := &sniTray{conn: conn, clicked: make(chan string, 1)}
+	if err := t.export(id); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	// The watcher tells the panel about the item; without one no tray runs
+	name := fmt.Sprintf("org.kde.StatusNotifierItem-%d-1", os.Getpid())
+	if _, err := conn.RequestName(name, dbus.NameFlagDoNotQueue); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("error claiming %s: %v", name, err)
+	}
+	call := conn.Object(watcherName, watcherPath).Call(watcherName+".RegisterStatusNotifierItem", 0, name)
+	if call.Err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("no system tray found (is a panel with StatusNotifierItem support running?): %v", call.Err)
+	}
+	return t, nil
+}
+
+// export puts the item and its menu on the bus
+func (t *sniTray) export(id string) error {
+	props, err := prop.Export(t.conn, itemPath, prop.Map{
+		itemInterface: {
+			"Category":   {Value: "ApplicationStatus", Emit: prop.EmitConst},
+			"Id":         {Value: id, Emit: prop.EmitConst},
+			"Title":      {Value: id, Emit: prop.EmitConst},
+			"Status":     {Value: "Active", Emit: prop.EmitConst},
+			"IconName":   {Value: "", Emit: prop.EmitFalse},
+			"ToolTip":    {Value: toolTip{Title: id}, Emit: prop.EmitFalse},
+			"ItemIsMenu": {Value: true, Emit: prop.EmitConst},
+			"Menu":       {Value: menuPath, Emit: prop.EmitConst},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error exporting the tray item: %v", err)
+	}
+	t.props = props
+	if _, err := prop.Export(t.conn, menuPath, prop.Map{
+		menuInterface: {
+			"Version":       {Value: menuVersion, Emit: prop.EmitConst},
+			"TextDirection": {Value: "ltr", Emit: prop.EmitConst},
+			"Status":        {Value: "normal", Emit: prop.EmitConst},
+			"IconThemePath": {Value: []string{}, Emit: prop.EmitConst},
+		},
+	}); err != nil {
+		return fmt.Errorf("error exporting the tray menu: %v", err)
+	}
+
+	if err := t.conn.Export((*sniItem)(t), itemPath, itemInterface); err != nil {
+		return fmt.Errorf("error exporting the tray item: %v", err)
+	}
+	if err := t.conn.Export((*dbusMenu)(t), menuPath, menuInterface); err != nil {
+		return fmt.Errorf("error exporting the tray menu: %v", err)
+	}
+	for path, node := range map[dbus.ObjectPath]*introspect.Node{
+		itemPath: {Name: string(itemPath), Interfaces: []introspect.Interface{
+			introspect.IntrospectData, prop.IntrospectData,
+			{Name: itemInterface, Methods: introspect.Methods((*sniItem)(t)), Properties: props.Introspection(itemInterface)},
+		}},
+		menuPath: {Name: string(menuPath), Interfaces: []introspect.Interface{
+			introspect.IntrospectData, prop.IntrospectData,
+			{Name: menuInterface, Methods: introspect.Methods((*dbusMenu)(t))},
+		}},
+	} {
+		if err := t.conn.Export(introspect.NewIntrospectable(node), path, "org.freedesktop.DBus.Introspectable"); err != nil {
+			return fmt.Errorf("error exporting introspection data: %v", err)
+		}
+	}
+	return nil
+}
+
+// Update shows state, signalling the panel for what changed
+func (t *sniTray) Update(state State) error {
+	t.mu.Lock()
+	prev := t.state
+	t.state = state
+	t.state.Menu = slices.Clone(state.Menu)
+	menuChanged := !slices.Equal(prev.Menu, state.Menu)
+	if menuChanged {
+		t.revision++
+	}
+	revision := t.revision
+	t.mu.Unlock()
+
+	if state.Icon != prev.Icon {
+		t.props.SetMust(itemInterface, "IconName", state.Icon)
+		if err := t.conn.Emit(itemPath, itemInterface+".NewIcon"); err != nil {
+			return err
+		}
+	}
+	if state.Tooltip != prev.Tooltip || state.Detail != prev.Detail {
+		t.props.SetMust(itemInterface, "ToolTip", toolTip{IconName: state.Icon, Title: state.Tooltip, Text: state.Detail})
+		if err := t.conn.Emit(itemPath, itemInterface+".NewToolTip"); err != nil {
+			return err
+		}
+	}
+	if menuChanged {
+		return t.conn.Emit(menuPath, menuInterface+".LayoutUpdated", revision, menuRootID)
+	}
+	return nil
+}
+
+// Clicked delivers the IDs of menu entries chosen
+func (t *sniTray) Clicked() <-chan string {
+	return t.clicked
+}
+
+// Close removes the icon by leaving the bus
+func (t *sniTray) Close() error {
+	return t.conn.Close()
+}
+
+// click passes on a chosen entry, dropping it if the last is not yet handled
+func (t *sniTray) click(id int32) {
+	t.mu.Lock()
+	chosen := ""
+	if i := int(id) - 1; i >= 0 && i < len(t.state.Menu) && t.state.Menu[i].Enabled {
+		chosen = t.state.Menu[i].ID
+	}
+	t.mu.Unlock()
+	if chosen == "" {
+		return
+	}
+	select {
+	case t.clicked <- chosen:
+	default:
+	}
+}
+
+// menuEntry returns the layout of entry id, 1 for the first menu item and
+// the root with its children otherwise
+func (t *sniTray) menuEntry(id int32) menuLayout {
+	if i := int(id) - 1; i >= 0 && i < len(t.state.Menu) {
+		item := t.state.Menu[i]
+		return menuLayout{ID: id, Properties: map[string]dbus.Variant{
+			"label":   dbus.MakeVariant(item.Label),
+			"enabled": dbus.MakeVariant(item.Enabled),
+		}, Children: []dbus.Variant{}}
+	}
+	root := menuLayout{ID: menuRootID, Properties: map[string]dbus.Variant{
+		"children-display": dbus.MakeVariant("submenu"),
+	}}
+	root.Children = []dbus.Variant{}
+	for i := range t.state.Menu {
+		root.Children = append(root.Children, dbus.MakeVariant(t.menuEntry(int32(i+1))))
+	}
+	return root
+}
+
+// sniItem holds the StatusNotifierItem methods; the menu does the work, so
+// clicks on the icon itself are ignored
+type sniItem sniTray
+
+func (*sniItem) Activate(int32, int32) *dbus.Error          { return nil }
+func (*sniItem) SecondaryActivate(int32, int32) *dbus.Error { return nil }
+func (*sniItem) ContextMenu(int32, int32) *dbus.Error       { return nil }
+func (*sniItem) Scroll(int32, string) *dbus.Error           { return nil }
+
+// dbusMenu holds the com.canonical.dbusmenu methods
+type dbusMenu sniTray
+
+func (m *dbusMenu) GetLayout(parentID int32, _ int32, _ []string) (uint32, menuLayout, *dbus.Error) {
+	t := (*sniTray)(m)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.revision, t.menuEntry(parentID), nil
+}
+
+func (m *dbusMenu) GetGroupProperties(ids []int32, _ []string) ([]menuProperties, *dbus.Error) {
+	t := (*sniTray)(m)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := []menuProperties{}
+	for _, id := range ids {
+		entry := t.menuEntry(id)
+		out = append(out, menuProperties{ID: entry.ID, Properties: entry.Properties})
+	}
+	return out, nil
+}
+
+func (m *dbusMenu) GetProperty(id int32, name string) (dbus.Variant, *dbus.Error) {
+	t := (*sniTray)(m)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if v, ok := t.menuEntry(id).Properties[name]; ok {
+		return v, nil
+	}
+	return dbus.MakeVariant(""), nil
+}
+
+func (m *dbusMenu) Event(id int32, eventID string, _ dbus.Variant, _ uint32) *dbus.Error {
+	if eventID == clickedEventName {
+		(*sniTray)(m).click(id)
+	}
+	return nil
+}
+
+func (m *dbusMenu) EventGroup(events []menuEvent) ([]int32, *dbus.Error) {
+	for _, e := range events {
+		_ = m.Event(e.ID, e.EventID, e.Data, e.Timestamp)
+	}
+	return []int32{}, nil
+}
+
+func (*dbusMenu) AboutToShow(int32) (bool, *dbus.Error) { return false, nil }
+
+func (*dbusMenu) AboutToShowGroup([]int32) ([]int32, []int32, *dbus.Error) {
+	return []int32{}, []int32{}, nil
+}
@@ -0,0 +1,119 @@
+package tray
+
+import (
+	"bufio"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// fakeWatcher stands in for the panel's StatusNotifierWatcher
+type fakeWatcher struct {
+	registered chan string
+}
+
+func (w *fakeWatcher) RegisterStatusNotifierItem(service string) *dbus.Error {
+	w.registered <- service
+	return nil
+}
+
+// sessionBus starts a private session bus for the test
+func sessionBus(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("dbus-daemon"); err != nil {
+		t.Skip("dbus-daemon is not installed")
+	}
+	cmd := exec.Command("dbus-daemon", "--session", "--nofork", "--print-address")
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+	address, err := bufio.NewReader(out).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", strings.TrimSpace(address))
+}
+
+func TestTray(t *testing.T) {
+	sessionBus(t)
+	if _, err := New("pomodoro"); err == nil || !strings.Contains(err.Error(), "no system tray found") {
+		t.Errorf("Expected an error without a watcher, got %v", err)
+	}
+
+	panel, err := dbus.ConnectSessionBus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = panel.Close() }()
+	watcher := &fakeWatcher{registered: make(chan string, 1)}
+	if err := panel.Export(watcher, watcherPath, watcherName); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := panel.RequestName(watcherName, dbus.NameFlagDoNotQueue); err != nil {
+		t.Fatal(err)
+	}
+
+	tr, err := New("pomodoro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = tr.Close() }()
+	service := <-watcher.registered
+
+	if err := tr.Update(State{
+		Icon:    "appointment-soon",
+		Tooltip: "Write report",
+		Detail:  "12:34 left",
+		Menu: []MenuItem{
+			{ID: "start", Label: "Start pomodoro"},
+			{ID: "pause", Label: "Pause", Enabled: true},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	item := panel.Object(service, itemPath)
+	var tip toolTip
+	if err := item.StoreProperty(itemInterface+".ToolTip", &tip); err != nil {
+		t.Fatal(err)
+	}
+	if tip.Title != "Write report" || tip.Text != "12:34 left" {
+		t.Errorf("Expected the time left in the tooltip, got %+v", tip)
+	}
+
+	menu := panel.Object(service, menuPath)
+	var revision uint32
+	var layout menuLayout
+	if err := menu.Call(menuInterface+".GetLayout", 0, int32(0), int32(-1), []string{}).Store(&revision, &layout); err != nil {
+		t.Fatal(err)
+	}
+	if len(layout.Children) != 2 {
+		t.Fatalf("Expected 2 menu entries, got %+v", layout)
+	}
+
+	// A disabled entry does nothing; an enabled one is delivered
+	for _, id := range []int32{1, 2} {
+		if err := menu.Call(menuInterface+".Event", 0, id, "clicked", dbus.MakeVariant(""), uint32(0)).Err; err != nil {
+			t.Fatal(err)
+		}
+	}
+	select {
+	case got := <-tr.Clicked():
+		if got != "pause" {
+			t.Errorf("Expected pause to be clicked, got %q", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a click")
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package tray
+
+// newTray always fails on platforms without a tray implementation
+func newTray(string) (Tray, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,35 @@
+package utils
+
+import "strings"
+
+// ExpandFormat replaces the placeholders in a user format string in a
+// single pass: %x becomes verbs['x'] and %% becomes %. Placeholders without
+// a verb, and a trailing %, are kept as typed. Values are never expanded
+// again, so a description containing "%r" prints as is.
+func ExpandFormat(format string, verbs map[byte]string) string {
+	var b strings.Builder
+	b.Grow(len(format))
+
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' || i+1 == len(format) {
+			b.WriteByte(c)
+			continue
+		}
+
+		i++
+		verb := format[i]
+		if verb == '%' {
+			b.WriteByte('%')
+			continue
+		}
+		if value, ok := verbs[verb]; ok {
+			b.WriteString(value)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(verb)
+	}
+
+	return b.String()
+}
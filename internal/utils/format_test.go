@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpandFormat(t *testing.T) {
+	verbs := map[byte]string{'d': "Fix %r bug", 'r': "12:00"}
+	tests := []struct {
+		in, want string
+	}{
+		{"%r remaining for %d", "12:00 remaining for Fix %r bug"},
+		{"100%% done", "100% done"},
+		{"%x stays", "%x stays"},
+		{"trailing %", "trailing %"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := ExpandFormat(tt.in, verbs); got != tt.want {
+			t.Errorf("ExpandFormat(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func FuzzExpandFormat(f *testing.F) {
+	for _, seed := range []string{"%r remaining for %d", "%%", "%", "%%%d", "plain", "%\xff"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, format string) {
+		got := ExpandFormat(format, map[byte]string{'d': "%d%%", 'r': ""})
+		// Text outside placeholders passes through untouched
+		if !strings.Contains(format, "%") && got != format {
+			t.Errorf("ExpandFormat(%q) = %q; want it unchanged", format, got)
+		}
+		// Without verbs, only %% changes
+		if plain := ExpandFormat(format, nil); len(plain) > len(format) {
+			t.Errorf("ExpandFormat(%q, nil) = %q; grew without verbs", format, plain)
+		}
+	})
+}
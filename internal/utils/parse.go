@@ -0,0 +1,266 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// maxInputLength bounds the user input the parsers look at, so a huge
+// argument fails fast instead of being scanned
+const maxInputLength = 64
+
+// durationUnits maps the unit words ParseHumanDuration accepts to their length
+var durationUnits = map[string]time.Duration{
+	"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+}
+
+// ParseHumanDuration parses a duration as people type it: Go durations
+// ("25m", "1h30m"), a bare number of minutes ("25"), a trailing number of
+// minutes after hours ("1h30"), and unit words with optional spaces
+// ("25 min", "1.5 hours", "1 hour 15 minutes"). Negative durations and
+// durations that overflow are rejected.
+func ParseHumanDuration(s string) (time.Duration, error) {
+	input := strings.ToLower(strings.TrimSpace(s))
+	if input == "" {
+		return 0, errors.New("empty duration")
+	}
+	if len(input) > maxInputLength {
+		return 0, fmt.Errorf("duration %.20q... is too long", s)
+	}
+
+	var total time.Duration
+	rest := input
+	lastUnit := time.Duration(0)
+	for rest != "" {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+
+		numEnd := strings.IndexFunc(rest, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if numEnd == -1 {
+			numEnd = len(rest)
+		}
+		if numEnd == 0 {
+			return 0, fmt.Errorf("invalid duration %q: expected a number", s)
+		}
+		value, err := strconv.ParseFloat(rest[:numEnd], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: %q is not a number", s, rest[:numEnd])
+		}
+		rest = strings.TrimLeftFunc(rest[numEnd:], unicode.IsSpace)
+
+		unitEnd := strings.IndexFunc(rest, func(r rune) bool { return !unicode.IsLetter(r) })
+		if unitEnd == -1 {
+			unitEnd = len(rest)
+		}
+		word := rest[:unitEnd]
+		rest = rest[unitEnd:]
+
+		unit, ok := durationUnits[word]
+		switch {
+		case word == "" && lastUnit == time.Hour:
+			// "1h30" means 1h30m
+			unit = time.Minute
+		case word == "" && lastUnit == 0 && rest == "":
+			// A bare number is minutes
+			unit = time.Minute
+		case word == "":
+			return 0, fmt.Errorf("invalid duration %q: missing unit after %v", s, value)
+		case !ok:
+			return 0, fmt.Errorf("invalid duration %q: unknown unit %q", s, word)
+		}
+		lastUnit = unit
+
+		part := value * float64(unit)
+		if part >= math.MaxInt64-float64(total) {
+			return 0, fmt.Errorf("invalid duration %q: too long", s)
+		}
+		total += time.Duration(part)
+	}
+
+	return total, nil
+}
+
+// ParseDate parses a calendar day given as YYYY-MM-DD, "today",
+// "yesterday", a weekday name for its most recent occurrence ("monday"), or
+// a number of days, weeks, months, or years ago ("3d", "2w", "6mo", "2y",
+// "10 days ago"). The result is midnight at the start of that day in now's
+// location.
+func ParseDate(s string, now time.Time) (time.Time, error) {
+	input := strings.ToLower(strings.TrimSpace(s))
+	if len(input) > maxInputLength {
+		return time.Time{}, fmt.Errorf("date %.20q... is too long", s)
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch input {
+	case "":
+		return time.Time{}, errors.New("empty date")
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", input, now.Location()); err == nil {
+		return t, nil
+	}
+
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		if input == strings.ToLower(wd.String()) {
+			daysAgo := (int(today.Weekday()) - int(wd) + 7) % 7
+			return today.AddDate(0, 0, -daysAgo), nil
+		}
+	}
+
+	if years, months, days, ok := parseAgo(input); ok {
+		return today.AddDate(-years, -months, -days), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD, today, yesterday, a weekday, or e.g. 3d, 2w, 6mo, or 2y", s)
+}
+
+// parseAgo parses "3d", "2w", "6mo", "2y", "3 days ago", or "2 years ago"
+// into a number of years, months, or days. Counts beyond about a hundred
+// years are rejected.
+func parseAgo(input string) (years, months, days int, ok bool) {
+	input = strings.TrimSpace(strings.TrimSuffix(input, "ago"))
+
+	numEnd := strings.IndexFunc(input, func(r rune) bool { return r < '0' || r > '9' })
+	if numEnd <= 0 {
+		return 0, 0, 0, false
+	}
+	n, err := strconv.Atoi(input[:numEnd])
+	if err != nil || n > 36500 {
+		return 0, 0, 0, false
+	}
+
+	switch strings.TrimSpace(input[numEnd:]) {
+	case "d", "day", "days":
+		return 0, 0, n, true
+	case "w", "week", "weeks":
+		if n > 36500/7 {
+			return 0, 0, 0, false
+		}
+		return 0, 0, n * 7, true
+	case "mo", "month", "months":
+		if n > 1200 {
+			return 0, 0, 0, false
+		}
+		return 0, n, 0, true
+	case "y", "year", "years":
+		if n > 100 {
+			return 0, 0, 0, false
+		}
+		return n, 0, 0, true
+	}
+	return 0, 0, 0, false
+}
+
+// dateTimeLayouts are the forms ParseDateTime accepts with a date
+var dateTimeLayouts = []string{"2006-01-02 15:04", "2006-01-02 15:04:05", "2006-01-02T15:04", "2006-01-02T15:04:05"}
+
+// ParseDateTime parses a point in time as HH:MM (or HH:MM:SS) on the day of
+// day, as "YYYY-MM-DD HH:MM", or as RFC 3339. Times without an offset are
+// in day's location.
+func ParseDateTime(s string, day time.Time) (time.Time, error) {
+	input := strings.TrimSpace(s)
+	if len(input) > maxInputLength {
+		return time.Time{}, fmt.Errorf("time %.20q... is too long", s)
+	}
+	if input == "" {
+		return time.Time{}, errors.New("empty time")
+	}
+
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.ParseInLocation(layout, input, day.Location()); err == nil {
+			return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), t.Second(), 0, day.Location()), nil
+		}
+	}
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.ParseInLocation(layout, input, day.Location()); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, input); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q: use HH:MM, \"YYYY-MM-DD HH:MM\", or RFC 3339", s)
+}
+
+// SplitArgs splits a command line into arguments as a POSIX shell would,
+// without expanding anything: whitespace separates arguments, single quotes
+// keep what they enclose as it is, double quotes keep whitespace, and a
+// backslash outside single quotes takes the next character literally.
+func SplitArgs(s string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			arg.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	switch {
+	case escaped:
+		return nil, fmt.Errorf("%q ends with a backslash", s)
+	case quote != 0:
+		return nil, fmt.Errorf("%q has an unclosed %c quote", s, quote)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// ParseWeekdays parses days given by name, as in mon or Monday
+func ParseWeekdays(names []string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, name := range names {
+		day, ok := weekdayNamed(name)
+		if !ok {
+			return nil, fmt.Errorf("invalid day %q: use a weekday such as mon or monday", name)
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// weekdayNamed returns the weekday with name, or the first three letters of it
+func weekdayNamed(name string) (time.Weekday, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		full := strings.ToLower(wd.String())
+		if name == full || name == full[:3] {
+			return wd, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseHumanDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"25m", 25 * time.Minute},
+		{"1h30m", 90 * time.Minute},
+		{"25", 25 * time.Minute},
+		{"1h30", 90 * time.Minute},
+		{"25 min", 25 * time.Minute},
+		{"1.5 hours", 90 * time.Minute},
+		{"1 hour 15 minutes", 75 * time.Minute},
+		{" 90S ", 90 * time.Second},
+	}
+	for _, tt := range tests {
+		got, err := ParseHumanDuration(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseHumanDuration(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "-5m", "5 fortnights", "m", "1.2.3m", "25m 5", "9999999999999h", strings.Repeat("1", 100)} {
+		if got, err := ParseHumanDuration(in); err == nil {
+			t.Errorf("ParseHumanDuration(%q) = %v; want an error", in, got)
+		}
+	}
+}
+
+func FuzzParseHumanDuration(f *testing.F) {
+	for _, seed := range []string{"25m", "1h30m", "25", "1h30", "1.5 hours", "90 s", "-5m", "1e9h", "..", "1h 2m 3s"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		d, err := ParseHumanDuration(s)
+		if err != nil {
+			return
+		}
+		if d < 0 {
+			t.Errorf("ParseHumanDuration(%q) = %v; want a non-negative duration", s, d)
+		}
+		// Go durations must mean the same, give or take float rounding
+		if goD, goErr := time.ParseDuration(strings.TrimSpace(s)); goErr == nil && (goD-d > time.Millisecond || d-goD > time.Millisecond) {
+			t.Errorf("ParseHumanDuration(%q) = %v; time.ParseDuration gives %v", s, d, goD)
+		}
+	})
+}
+
+func TestParseDate(t *testing.T) {
+	now := time.Date(2024, 6, 5, 15, 4, 0, 0, time.UTC) // A Wednesday
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"2024-01-31", "2024-01-31"},
+		{"today", "2024-06-05"},
+		{"Yesterday", "2024-06-04"},
+		{"monday", "2024-06-03"},
+		{"wednesday", "2024-06-05"},
+		{"thursday", "2024-05-30"},
+		{"3d", "2024-06-02"},
+		{"2w", "2024-05-22"},
+		{"10 days ago", "2024-05-26"},
+		{"6mo", "2023-12-05"},
+		{"2y", "2022-06-05"},
+		{"1 year ago", "2023-06-05"},
+	}
+	for _, tt := range tests {
+		got, err := ParseDate(tt.in, now)
+		if err != nil || got.Format("2006-01-02") != tt.want {
+			t.Errorf("ParseDate(%q) = %v, %v; want %s", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "2024-13-01", "someday", "-3d", "3 fortnights ago", "99999999999d", "500y"} {
+		if got, err := ParseDate(in, now); err == nil {
+			t.Errorf("ParseDate(%q) = %v; want an error", in, got)
+		}
+	}
+}
+
+func FuzzParseDate(f *testing.F) {
+	for _, seed := range []string{"2024-01-31", "today", "yesterday", "friday", "3d", "2w", "10 days ago", "0d", "2024-02-30"} {
+		f.Add(seed)
+	}
+	now := time.Date(2024, 6, 5, 15, 4, 0, 0, time.FixedZone("X", 5*3600))
+	f.Fuzz(func(t *testing.T, s string) {
+		d, err := ParseDate(s, now)
+		if err != nil {
+			return
+		}
+		if d.Location() != now.Location() || d.Hour() != 0 || d.Minute() != 0 || d.Second() != 0 || d.Nanosecond() != 0 {
+			t.Errorf("ParseDate(%q) = %v; want midnight in %v", s, d, now.Location())
+		}
+	})
+}
+
+func TestParseDateTime(t *testing.T) {
+	zone := time.FixedZone("CEST", 2*3600)
+	day := time.Date(2024, 6, 5, 15, 4, 0, 0, zone)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"09:30", time.Date(2024, 6, 5, 9, 30, 0, 0, zone)},
+		{"9:30:15", time.Date(2024, 6, 5, 9, 30, 15, 0, zone)},
+		{"2024-06-04 23:55", time.Date(2024, 6, 4, 23, 55, 0, 0, zone)},
+		{"2024-06-04T08:00", time.Date(2024, 6, 4, 8, 0, 0, 0, zone)},
+		{"2024-06-04T08:00:00Z", time.Date(2024, 6, 4, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseDateTime(tt.in, day)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("ParseDateTime(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "25:00", "noon", "2024-06-04", strings.Repeat("1", 100)} {
+		if got, err := ParseDateTime(in, day); err == nil {
+			t.Errorf("ParseDateTime(%q) = %v; want an error", in, got)
+		}
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"start --duration 50m -t deep-work", []string{"start", "--duration", "50m", "-t", "deep-work"}},
+		{`  start   "Write report"  `, []string{"start", "Write report"}},
+		{`start 'It''s "done"'`, []string{"start", `Its "done"`}},
+		{`start Don\'t\ stop`, []string{"start", "Don't stop"}},
+		{`start "say \"hi\"" ''`, []string{"start", `say "hi"`, ""}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		got, err := SplitArgs(tt.in)
+		if err != nil || strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+			t.Errorf("SplitArgs(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{`start "Write`, `start 'x`, `start x\`} {
+		if got, err := SplitArgs(in); err == nil {
+			t.Errorf("SplitArgs(%q) = %q; want an error", in, got)
+		}
+	}
+}
+
+func TestParseWeekdays(t *testing.T) {
+	days, err := ParseWeekdays([]string{"mon", "Friday", " sun "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []time.Weekday{time.Monday, time.Friday, time.Sunday}; len(days) != 3 || days[0] != want[0] || days[1] != want[1] || days[2] != want[2] {
+		t.Errorf("Expected %v, got %v", want, days)
+	}
+	if _, err := ParseWeekdays([]string{"mo"}); err == nil {
+		t.Error("Expected mo to be refused")
+	}
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// windowsEnvVar matches %VAR% references as written in Windows paths
+var windowsEnvVar = regexp.MustCompile(`%([A-Za-z_][A-Za-z0-9_()]*)%`)
+
+// ExpandPath expands a leading ~ to the home directory (%USERPROFILE% on
+// Windows) and substitutes $VAR, ${VAR}, and %VAR% environment references,
+// so the same config file works from a Unix shell and from PowerShell
+func ExpandPath(path string) string {
+	if path == "" {
+		return path
+	}
+
+	path = windowsEnvVar.ReplaceAllStringFunc(path, func(ref string) string {
+		if value, ok := os.LookupEnv(strings.Trim(ref, "%")); ok {
+			return value
+		}
+		return ref
+	})
+	path = os.ExpandEnv(path)
+
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+
+	return filepath.Clean(path)
+}
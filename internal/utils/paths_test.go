@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestExpandPath(t *testing.T) {
+	t.Setenv("HOME", "/home/tester")
+	t.Setenv("USERPROFILE", "/home/tester")
+	t.Setenv("POMODORO_DATA", "/data")
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"~", "/home/tester"},
+		{"~/sounds", "/home/tester/sounds"},
+		{"%USERPROFILE%/sounds", "/home/tester/sounds"},
+		{"$POMODORO_DATA/history.db", "/data/history.db"},
+		{"${POMODORO_DATA}/exports", "/data/exports"},
+		{"%POMODORO_UNSET_VAR%/x", "%POMODORO_UNSET_VAR%/x"},
+		{"/abs/path/../file", "/abs/file"},
+	}
+
+	for _, tt := range tests {
+		if got := ExpandPath(tt.in); got != filepath.FromSlash(tt.want) {
+			t.Errorf("ExpandPath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
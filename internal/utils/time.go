@@ -34,7 +34,7 @@ func ParseDurationWithDefaults(s string, defaultDuration time.Duration) time.Dur
 		return defaultDuration
 	}
 
-	duration, err := time.ParseDuration(s)
+	duration, err := ParseHumanDuration(s)
 	if err != nil {
 		return defaultDuration
 	}
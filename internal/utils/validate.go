@@ -33,6 +33,18 @@ func ValidateDescription(desc string, required bool) error {
 	return nil
 }
 
+// ValidateAnnotation validates the text of a session annotation
+func ValidateAnnotation(text string) error {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return errors.New("annotation cannot be empty")
+	}
+	if len(trimmed) > 1000 {
+		return errors.New("annotation cannot exceed 1000 characters")
+	}
+	return nil
+}
+
 // ValidateTags validates session tags
 func ValidateTags(tags []string) error {
 	if len(tags) > 10 {